@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// AlertEvent is what the agent pushes to Sheldon when a rule starts or stops firing
+type AlertEvent struct {
+	Host      string    `json:"host"`
+	Rule      string    `json:"rule"`    // disk, cpu or restarts
+	Subject   string    `json:"subject"` // mount path or container name
+	Message   string    `json:"message"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Resolved  bool      `json:"resolved"`
+	Time      time.Time `json:"time"`
+}
+
+// AlertRules are the thresholds, read from ALERT_* env vars
+type AlertRules struct {
+	DiskPercent   float64       // alert when a path is fuller than this
+	DiskPaths     []string      // mounts to watch
+	CPUPercent    float64       // alert when CPU stays above this...
+	CPUSustain    time.Duration // ...for this long
+	Restarts      int           // alert when a container restarts this many times...
+	RestartWindow time.Duration // ...within this window
+}
+
+// Sample is one reading of everything the rules look at
+type Sample struct {
+	Time     time.Time
+	Disk     map[string]float64 // path -> used percent
+	CPU      float64
+	Restarts map[string]int  // container -> docker's RestartCount
+	Looping  map[string]bool // containers docker reports as "restarting"
+}
+
+type restartReading struct {
+	at    time.Time
+	count int
+}
+
+// Alerter samples the host on an interval and posts events to a webhook
+// when a rule starts firing and again when it resolves
+type Alerter struct {
+	logger   *slog.Logger
+	rules    AlertRules
+	url      string
+	token    string
+	host     string
+	interval time.Duration
+	client   *http.Client
+
+	firing    map[string]AlertEvent // rule/subject -> event that fired
+	cpuSince  time.Time             // when CPU first went over the threshold
+	restarts  map[string][]restartReading
+	sampleFor func() Sample
+}
+
+func newAlerter(logger *slog.Logger) *Alerter {
+	url := os.Getenv("ALERT_WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+
+	token := os.Getenv("ALERT_WEBHOOK_TOKEN")
+	if token == "" {
+		token = os.Getenv("AGENT_TOKEN")
+	}
+
+	host := os.Getenv("AGENT_NAME")
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	a := &Alerter{
+		logger:   logger,
+		rules:    loadAlertRules(),
+		url:      url,
+		token:    token,
+		host:     host,
+		interval: envDuration("ALERT_INTERVAL", time.Minute),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		firing:   make(map[string]AlertEvent),
+		restarts: make(map[string][]restartReading),
+	}
+	a.sampleFor = a.sample
+	return a
+}
+
+func loadAlertRules() AlertRules {
+	paths := []string{"/"}
+	if v := os.Getenv("ALERT_DISK_PATHS"); v != "" {
+		paths = nil
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+
+	return AlertRules{
+		DiskPercent:   envFloat("ALERT_DISK_PERCENT", 90),
+		DiskPaths:     paths,
+		CPUPercent:    envFloat("ALERT_CPU_PERCENT", 95),
+		CPUSustain:    envDuration("ALERT_CPU_DURATION", 10*time.Minute),
+		Restarts:      int(envFloat("ALERT_RESTART_COUNT", 3)),
+		RestartWindow: envDuration("ALERT_RESTART_WINDOW", 15*time.Minute),
+	}
+}
+
+func envFloat(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
+
+// Run checks the rules every interval until ctx is cancelled
+func (a *Alerter) Run(ctx context.Context) {
+	a.logger.Info("alerting enabled", "webhook", a.url, "interval", a.interval,
+		"disk", a.rules.DiskPercent, "cpu", a.rules.CPUPercent, "restarts", a.rules.Restarts)
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			events := a.evaluate(a.sampleFor())
+			if len(events) == 0 {
+				continue
+			}
+			if err := a.send(ctx, events); err != nil {
+				a.logger.Error("failed to send alerts", "events", len(events), "error", err)
+				// forget what fired so the next check retries
+				for _, e := range events {
+					if !e.Resolved {
+						delete(a.firing, alertKey(e.Rule, e.Subject))
+					}
+				}
+			}
+		}
+	}
+}
+
+func (a *Alerter) sample() Sample {
+	s := Sample{
+		Time:     time.Now(),
+		Disk:     make(map[string]float64),
+		Restarts: make(map[string]int),
+		Looping:  make(map[string]bool),
+	}
+
+	for _, path := range a.rules.DiskPaths {
+		usage, err := disk.Usage(path)
+		if err != nil {
+			a.logger.Warn("disk usage failed", "path", path, "error", err)
+			continue
+		}
+		s.Disk[path] = usage.UsedPercent
+	}
+
+	if percent, err := cpu.Percent(time.Second, false); err == nil && len(percent) > 0 {
+		s.CPU = percent[0]
+	}
+
+	ids, err := exec.Command("docker", "ps", "-aq").Output()
+	if err != nil || len(bytes.TrimSpace(ids)) == 0 {
+		return s
+	}
+	args := append([]string{"inspect", "-f", "{{.Name}}\t{{.RestartCount}}\t{{.State.Status}}"}, strings.Fields(string(ids))...)
+	output, err := exec.Command("docker", args...).Output()
+	if err != nil {
+		a.logger.Warn("docker inspect failed", "error", err)
+		return s
+	}
+	parseRestarts(string(output), s.Restarts, s.Looping)
+
+	return s
+}
+
+// parseRestarts reads "name\trestarts\tstatus" lines from docker inspect
+func parseRestarts(output string, restarts map[string]int, looping map[string]bool) {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 3 {
+			continue
+		}
+		name := strings.TrimPrefix(parts[0], "/")
+		count, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		restarts[name] = count
+		if parts[2] == "restarting" {
+			looping[name] = true
+		}
+	}
+}
+
+// evaluate applies the rules to a sample and returns the events whose state changed
+func (a *Alerter) evaluate(s Sample) []AlertEvent {
+	active := make(map[string]AlertEvent)
+
+	for path, used := range s.Disk {
+		if used >= a.rules.DiskPercent {
+			active[alertKey("disk", path)] = AlertEvent{
+				Rule:      "disk",
+				Subject:   path,
+				Message:   fmt.Sprintf("%s is %.0f%% full", path, used),
+				Value:     used,
+				Threshold: a.rules.DiskPercent,
+			}
+		}
+	}
+
+	if s.CPU >= a.rules.CPUPercent {
+		if a.cpuSince.IsZero() {
+			a.cpuSince = s.Time
+		}
+		if held := s.Time.Sub(a.cpuSince); held >= a.rules.CPUSustain {
+			active[alertKey("cpu", "")] = AlertEvent{
+				Rule:      "cpu",
+				Message:   fmt.Sprintf("CPU at %.0f%% for %s", s.CPU, held.Round(time.Minute)),
+				Value:     s.CPU,
+				Threshold: a.rules.CPUPercent,
+			}
+		}
+	} else {
+		a.cpuSince = time.Time{}
+	}
+
+	for name, count := range s.Restarts {
+		history := append(a.restarts[name], restartReading{at: s.Time, count: count})
+		cutoff := s.Time.Add(-a.rules.RestartWindow)
+		for len(history) > 1 && history[0].at.Before(cutoff) {
+			history = history[1:]
+		}
+		a.restarts[name] = history
+
+		recent := count - history[0].count
+		if recent >= a.rules.Restarts || (s.Looping[name] && recent > 0) {
+			active[alertKey("restarts", name)] = AlertEvent{
+				Rule:      "restarts",
+				Subject:   name,
+				Message:   fmt.Sprintf("container %s restarted %d times in the last %s", name, recent, a.rules.RestartWindow),
+				Value:     float64(recent),
+				Threshold: float64(a.rules.Restarts),
+			}
+		}
+	}
+	for name := range a.restarts {
+		if _, ok := s.Restarts[name]; !ok {
+			delete(a.restarts, name)
+		}
+	}
+
+	var events []AlertEvent
+	for key, e := range active {
+		if _, ok := a.firing[key]; ok {
+			continue
+		}
+		e.Host, e.Time = a.host, s.Time
+		a.firing[key] = e
+		events = append(events, e)
+	}
+	for key, e := range a.firing {
+		if _, ok := active[key]; ok {
+			continue
+		}
+		delete(a.firing, key)
+		e.Resolved, e.Time = true, s.Time
+		e.Message = "resolved: " + e.Message
+		events = append(events, e)
+	}
+
+	return events
+}
+
+func alertKey(rule, subject string) string {
+	return rule + "/" + subject
+}
+
+func (a *Alerter) send(ctx context.Context, events []AlertEvent) error {
+	body, err := json.Marshal(map[string]any{"host": a.host, "events": events})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	a.logger.Info("alerts sent", "events", len(events))
+	return nil
+}
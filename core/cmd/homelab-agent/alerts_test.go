@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testAlerter() *Alerter {
+	return &Alerter{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		rules: AlertRules{
+			DiskPercent:   90,
+			CPUPercent:    95,
+			CPUSustain:    5 * time.Minute,
+			Restarts:      3,
+			RestartWindow: 15 * time.Minute,
+		},
+		host:     "nas",
+		firing:   make(map[string]AlertEvent),
+		restarts: make(map[string][]restartReading),
+	}
+}
+
+func TestEvaluateDiskFiresOnceAndResolves(t *testing.T) {
+	a := testAlerter()
+	now := time.Now()
+
+	events := a.evaluate(Sample{Time: now, Disk: map[string]float64{"/mnt/nas": 93, "/": 40}})
+	if len(events) != 1 || events[0].Rule != "disk" || events[0].Subject != "/mnt/nas" || events[0].Host != "nas" {
+		t.Fatalf("events = %+v, want one disk alert for /mnt/nas", events)
+	}
+
+	if events := a.evaluate(Sample{Time: now.Add(time.Minute), Disk: map[string]float64{"/mnt/nas": 95}}); len(events) != 0 {
+		t.Errorf("still-firing alert sent again: %+v", events)
+	}
+
+	events = a.evaluate(Sample{Time: now.Add(2 * time.Minute), Disk: map[string]float64{"/mnt/nas": 70}})
+	if len(events) != 1 || !events[0].Resolved {
+		t.Fatalf("events = %+v, want a resolved disk alert", events)
+	}
+}
+
+func TestEvaluateCPUNeedsSustainedLoad(t *testing.T) {
+	a := testAlerter()
+	now := time.Now()
+
+	for i, cpu := range []float64{99, 98, 50, 99, 99} {
+		if events := a.evaluate(Sample{Time: now.Add(time.Duration(i) * 2 * time.Minute), CPU: cpu}); len(events) != 0 {
+			t.Fatalf("sample %d: unexpected events %+v", i, events)
+		}
+	}
+
+	events := a.evaluate(Sample{Time: now.Add(14 * time.Minute), CPU: 97})
+	if len(events) != 1 || events[0].Rule != "cpu" {
+		t.Fatalf("events = %+v, want a cpu alert after 8m over threshold", events)
+	}
+}
+
+func TestEvaluateRestartLoop(t *testing.T) {
+	a := testAlerter()
+	now := time.Now()
+
+	counts := []int{4, 5, 6}
+	for i, c := range counts {
+		s := Sample{Time: now.Add(time.Duration(i) * time.Minute), Restarts: map[string]int{"jellyfin": c, "plex": 2}}
+		if events := a.evaluate(s); len(events) != 0 {
+			t.Fatalf("sample %d: unexpected events %+v", i, events)
+		}
+	}
+
+	s := Sample{Time: now.Add(3 * time.Minute), Restarts: map[string]int{"jellyfin": 7, "plex": 2}}
+	events := a.evaluate(s)
+	if len(events) != 1 || events[0].Subject != "jellyfin" || events[0].Value != 3 {
+		t.Fatalf("events = %+v, want jellyfin restarting 3 times", events)
+	}
+
+	// old restarts age out of the window
+	s = Sample{Time: now.Add(30 * time.Minute), Restarts: map[string]int{"jellyfin": 7, "plex": 2}}
+	events = a.evaluate(s)
+	if len(events) != 1 || !events[0].Resolved {
+		t.Fatalf("events = %+v, want jellyfin resolved", events)
+	}
+}
+
+func TestParseRestarts(t *testing.T) {
+	restarts, looping := make(map[string]int), make(map[string]bool)
+	parseRestarts("/jellyfin\t12\trestarting\n/plex\t0\trunning\nbad line\n", restarts, looping)
+
+	if restarts["jellyfin"] != 12 || restarts["plex"] != 0 || len(restarts) != 2 {
+		t.Errorf("restarts = %v", restarts)
+	}
+	if !looping["jellyfin"] || looping["plex"] {
+		t.Errorf("looping = %v", looping)
+	}
+}
+
+func TestSendPostsEventsWithToken(t *testing.T) {
+	var got struct {
+		Host   string       `json:"host"`
+		Events []AlertEvent `json:"events"`
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	a := testAlerter()
+	a.url, a.token, a.client = srv.URL, "secret", srv.Client()
+
+	err := a.send(t.Context(), []AlertEvent{{Host: "nas", Rule: "disk", Subject: "/", Value: 92}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.Host != "nas" || len(got.Events) != 1 || got.Events[0].Rule != "disk" {
+		t.Errorf("payload = %+v", got)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// scope is what a token may do; routes declare the scope they need
+type scope int
+
+const (
+	scopeRead    scope = iota // status, lists and logs
+	scopeControl              // start, stop and restart
+)
+
+// Auth checks bearer tokens: AGENT_TOKEN may do everything, AGENT_READ_TOKEN only read.
+// With neither set the agent is open, as it was before tokens existed.
+type Auth struct {
+	tokens []grant
+}
+
+type grant struct {
+	token []byte
+	scope scope
+}
+
+func newAuth() *Auth {
+	a := &Auth{}
+	if token := os.Getenv("AGENT_TOKEN"); token != "" {
+		a.tokens = append(a.tokens, grant{token: []byte(token), scope: scopeControl})
+	}
+	if token := os.Getenv("AGENT_READ_TOKEN"); token != "" {
+		a.tokens = append(a.tokens, grant{token: []byte(token), scope: scopeRead})
+	}
+	return a
+}
+
+func (a *Auth) enabled() bool {
+	return len(a.tokens) > 0
+}
+
+// lookup returns the scope of a token, comparing against every token in constant time
+func (a *Auth) lookup(token string) (scope, bool) {
+	granted, found := scopeRead, false
+	for _, g := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), g.token) == 1 {
+			granted, found = g.scope, true
+		}
+	}
+	return granted, found
+}
+
+// require wraps a handler so it only runs for tokens with at least the given scope
+func (a *Agent) require(need scope, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.auth.enabled() {
+			handler(w, r)
+			return
+		}
+
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		granted, ok := a.auth.lookup(token)
+		if !ok {
+			a.logger.Warn("rejected request", "path", r.URL.Path, "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="homelab-agent"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if granted < need {
+			http.Error(w, "token is read-only", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// loadTLSConfig serves HTTPS when AGENT_TLS_CERT and AGENT_TLS_KEY are set, and
+// requires client certs signed by AGENT_TLS_CLIENT_CA when that is set too
+func loadTLSConfig() (*tls.Config, error) {
+	certFile, keyFile := os.Getenv("AGENT_TLS_CERT"), os.Getenv("AGENT_TLS_KEY")
+	clientCA := os.Getenv("AGENT_TLS_CLIENT_CA")
+	if certFile == "" && keyFile == "" {
+		if clientCA != "" {
+			return nil, fmt.Errorf("AGENT_TLS_CLIENT_CA needs AGENT_TLS_CERT and AGENT_TLS_KEY")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS cert: %w", err)
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if clientCA != "" {
+		pem, err := os.ReadFile(clientCA)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", clientCA)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireScopes(t *testing.T) {
+	agent := &Agent{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		auth: &Auth{tokens: []grant{
+			{token: []byte("control-token"), scope: scopeControl},
+			{token: []byte("read-token"), scope: scopeRead},
+		}},
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	tests := []struct {
+		name  string
+		need  scope
+		token string
+		want  int
+	}{
+		{"no token", scopeRead, "", http.StatusUnauthorized},
+		{"wrong token", scopeRead, "nope", http.StatusUnauthorized},
+		{"read token reads", scopeRead, "read-token", http.StatusOK},
+		{"read token can't control", scopeControl, "read-token", http.StatusForbidden},
+		{"control token controls", scopeControl, "control-token", http.StatusOK},
+		{"control token reads", scopeRead, "control-token", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/containers", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			agent.require(tt.need, ok)(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireOpenWithoutTokens(t *testing.T) {
+	agent := &Agent{auth: &Auth{}}
+	rec := httptest.NewRecorder()
+	agent.require(scopeControl, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})(rec, httptest.NewRequest("POST", "/containers/x/stop", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d", rec.Code)
+	}
+}
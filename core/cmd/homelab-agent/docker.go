@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiskUsage is one row of docker system df
+type DiskUsage struct {
+	Type        string `json:"type"`
+	Total       int    `json:"total"`
+	Active      int    `json:"active"`
+	Size        string `json:"size"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Reclaimable string `json:"reclaimable"`
+}
+
+type ImageInfo struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	ID         string `json:"id"`
+	Size       string `json:"size"`
+	SizeBytes  int64  `json:"size_bytes"`
+	Created    string `json:"created"`
+}
+
+// PruneResult reports what a prune removed
+type PruneResult struct {
+	Kind      string `json:"kind"` // images or volumes
+	Deleted   int    `json:"deleted"`
+	Reclaimed string `json:"reclaimed"`
+}
+
+func (a *Agent) handleDockerDF(w http.ResponseWriter, r *http.Request) {
+	output, err := exec.Command("docker", "system", "df", "--format", "{{json .}}").Output()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("docker system df failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	usage, err := parseSystemDF(string(output))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+func (a *Agent) handleDockerImages(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	output, err := exec.Command("docker", "images", "--format", "{{json .}}").Output()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("docker images failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	images, err := parseImages(string(output))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sort.SliceStable(images, func(i, j int) bool { return images[i].SizeBytes > images[j].SizeBytes })
+	if len(images) > limit {
+		images = images[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(images)
+}
+
+// handleDockerPrune removes dangling images and, when asked, unused volumes
+func (a *Agent) handleDockerPrune(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Images  bool `json:"images"`
+		Volumes bool `json:"volumes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !req.Images && !req.Volumes {
+		http.Error(w, "nothing to prune, set images and/or volumes", http.StatusBadRequest)
+		return
+	}
+
+	var results []PruneResult
+	for _, kind := range []string{"images", "volumes"} {
+		if (kind == "images" && !req.Images) || (kind == "volumes" && !req.Volumes) {
+			continue
+		}
+
+		a.logger.Info("pruning docker", "kind", kind)
+		cmd := exec.Command("docker", strings.TrimSuffix(kind, "s"), "prune", "-f")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			a.logger.Error("docker prune failed", "kind", kind, "error", err)
+			http.Error(w, fmt.Sprintf("%s prune failed: %s", kind, stderr.String()), http.StatusInternalServerError)
+			return
+		}
+
+		result := parsePrune(string(output))
+		result.Kind = kind
+		a.logger.Info("docker pruned", "kind", kind, "deleted", result.Deleted, "reclaimed", result.Reclaimed)
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func parseSystemDF(output string) ([]DiskUsage, error) {
+	var usage []DiskUsage
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		var row struct {
+			Type        string
+			TotalCount  string
+			Active      string
+			Size        string
+			Reclaimable string
+		}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			return nil, fmt.Errorf("parse docker system df: %w", err)
+		}
+		total, _ := strconv.Atoi(row.TotalCount)
+		active, _ := strconv.Atoi(row.Active)
+		usage = append(usage, DiskUsage{
+			Type:        row.Type,
+			Total:       total,
+			Active:      active,
+			Size:        row.Size,
+			SizeBytes:   parseSize(row.Size),
+			Reclaimable: row.Reclaimable,
+		})
+	}
+	return usage, nil
+}
+
+func parseImages(output string) ([]ImageInfo, error) {
+	var images []ImageInfo
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		var row struct {
+			Repository   string
+			Tag          string
+			ID           string
+			Size         string
+			CreatedSince string
+		}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			return nil, fmt.Errorf("parse docker images: %w", err)
+		}
+		images = append(images, ImageInfo{
+			Repository: row.Repository,
+			Tag:        row.Tag,
+			ID:         row.ID,
+			Size:       row.Size,
+			SizeBytes:  parseSize(row.Size),
+			Created:    row.CreatedSince,
+		})
+	}
+	return images, nil
+}
+
+// parsePrune counts the deleted items and reads the reclaimed space from docker's prune output:
+//
+//	Deleted Images:
+//	deleted: sha256:...
+//
+//	Total reclaimed space: 1.2GB
+func parsePrune(output string) PruneResult {
+	result := PruneResult{Reclaimed: "0B"}
+	listing := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Deleted "):
+			listing = true
+		case strings.HasPrefix(line, "Total reclaimed space:"):
+			result.Reclaimed = strings.TrimSpace(strings.TrimPrefix(line, "Total reclaimed space:"))
+		case line == "":
+			listing = false
+		case listing && !strings.HasPrefix(line, "untagged:"):
+			result.Deleted++
+		}
+	}
+	return result
+}
+
+// parseSize converts docker's human sizes ("1.2GB", "512kB", "0B") to bytes; docker uses powers of 1000
+func parseSize(s string) int64 {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		s = s[:i] // "1.2GB (50%)"
+	}
+
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"kB", 1e3}, {"KB", 1e3}, {"B", 1},
+	}
+	for _, u := range units {
+		if number, ok := strings.CutSuffix(s, u.suffix); ok {
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0
+			}
+			return int64(value * u.scale)
+		}
+	}
+	return 0
+}
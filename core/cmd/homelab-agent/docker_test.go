@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"0B":          0,
+		"512kB":       512_000,
+		"12.5MB":      12_500_000,
+		"1.2GB":       1_200_000_000,
+		"2TB":         2_000_000_000_000,
+		"3.4GB (75%)": 3_400_000_000,
+		"garbage":     0,
+	}
+	for in, want := range tests {
+		if got := parseSize(in); got != want {
+			t.Errorf("parseSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestParseSystemDF(t *testing.T) {
+	output := `{"Active":"3","Reclaimable":"4.1GB (62%)","Size":"6.6GB","TotalCount":"9","Type":"Images"}
+{"Active":"1","Reclaimable":"120MB (40%)","Size":"300MB","TotalCount":"4","Type":"Local Volumes"}
+`
+	usage, err := parseSystemDF(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("got %d rows", len(usage))
+	}
+	if u := usage[0]; u.Type != "Images" || u.Total != 9 || u.Active != 3 || u.SizeBytes != 6_600_000_000 || u.Reclaimable != "4.1GB (62%)" {
+		t.Errorf("images row = %+v", u)
+	}
+}
+
+func TestParseImages(t *testing.T) {
+	output := `{"CreatedSince":"2 weeks ago","ID":"a1b2c3","Repository":"ollama/ollama","Size":"3.2GB","Tag":"latest"}
+{"CreatedSince":"3 days ago","ID":"d4e5f6","Repository":"<none>","Size":"850MB","Tag":"<none>"}
+`
+	images, err := parseImages(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 2 || images[0].Repository != "ollama/ollama" || images[0].SizeBytes != 3_200_000_000 {
+		t.Errorf("images = %+v", images)
+	}
+	if images[1].Repository != "<none>" || images[1].Created != "3 days ago" {
+		t.Errorf("dangling image = %+v", images[1])
+	}
+}
+
+func TestParsePrune(t *testing.T) {
+	images := `Deleted Images:
+untagged: myapp@sha256:1234
+deleted: sha256:aaaa
+deleted: sha256:bbbb
+
+Total reclaimed space: 1.2GB
+`
+	if r := parsePrune(images); r.Deleted != 2 || r.Reclaimed != "1.2GB" {
+		t.Errorf("images prune = %+v", r)
+	}
+
+	volumes := `Deleted Volumes:
+3f1c0d
+pgdata_old
+
+Total reclaimed space: 512MB
+`
+	if r := parsePrune(volumes); r.Deleted != 2 || r.Reclaimed != "512MB" {
+		t.Errorf("volumes prune = %+v", r)
+	}
+
+	if r := parsePrune("Total reclaimed space: 0B\n"); r.Deleted != 0 || r.Reclaimed != "0B" {
+		t.Errorf("empty prune = %+v", r)
+	}
+}
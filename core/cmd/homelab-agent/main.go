@@ -33,40 +33,81 @@ func main() {
 		port = "8080"
 	}
 
+	tlsConfig, err := loadTLSConfig()
+	if err != nil {
+		logger.Error("invalid TLS config", "error", err)
+		os.Exit(1)
+	}
+
 	agent := &Agent{
-		logger: logger,
+		logger:   logger,
+		services: newServiceManager(),
+		auth:     newAuth(),
+	}
+	if !agent.auth.enabled() {
+		logger.Warn("AGENT_TOKEN not set, anyone who can reach this port can control containers")
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /health", agent.handleHealth)
-	mux.HandleFunc("GET /status", agent.handleStatus)
+	mux.HandleFunc("GET /status", agent.require(scopeRead, agent.handleStatus))
 
 	// generic container management
-	mux.HandleFunc("GET /containers", agent.handleListContainers)
-	mux.HandleFunc("GET /containers/{name}", agent.handleContainerStatus)
-	mux.HandleFunc("POST /containers/{name}/restart", agent.handleContainerRestart)
-	mux.HandleFunc("POST /containers/{name}/stop", agent.handleContainerStop)
-	mux.HandleFunc("POST /containers/{name}/start", agent.handleContainerStart)
-	mux.HandleFunc("GET /containers/{name}/logs", agent.handleContainerLogs)
+	mux.HandleFunc("GET /containers", agent.require(scopeRead, agent.handleListContainers))
+	mux.HandleFunc("GET /containers/{name}", agent.require(scopeRead, agent.handleContainerStatus))
+	mux.HandleFunc("POST /containers/{name}/restart", agent.require(scopeControl, agent.handleContainerRestart))
+	mux.HandleFunc("POST /containers/{name}/stop", agent.require(scopeControl, agent.handleContainerStop))
+	mux.HandleFunc("POST /containers/{name}/start", agent.require(scopeControl, agent.handleContainerStart))
+	mux.HandleFunc("GET /containers/{name}/logs", agent.require(scopeRead, agent.handleContainerLogs))
+
+	// what's using the box
+	mux.HandleFunc("GET /processes", agent.require(scopeRead, agent.handleProcesses))
+	mux.HandleFunc("GET /ports", agent.require(scopeRead, agent.handlePorts))
+
+	// docker disk housekeeping
+	mux.HandleFunc("GET /docker/df", agent.require(scopeRead, agent.handleDockerDF))
+	mux.HandleFunc("GET /docker/images", agent.require(scopeRead, agent.handleDockerImages))
+	mux.HandleFunc("POST /docker/prune", agent.require(scopeControl, agent.handleDockerPrune))
+
+	// systemd units, limited to SERVICES_ALLOWLIST
+	mux.HandleFunc("GET /services", agent.require(scopeRead, agent.handleListServices))
+	mux.HandleFunc("GET /services/{name}", agent.require(scopeRead, agent.handleServiceStatus))
+	mux.HandleFunc("POST /services/{name}/restart", agent.require(scopeControl, agent.handleServiceRestart))
+	mux.HandleFunc("GET /services/{name}/journal", agent.require(scopeRead, agent.handleServiceJournal))
 
 	server := &http.Server{
 		Addr:         ":" + port,
 		Handler:      mux,
+		TLSConfig:    tlsConfig,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
 
 	go func() {
-		logger.Info("homelab-agent starting", "port", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Info("homelab-agent starting", "port", port, "services", len(agent.services.allowed),
+			"auth", agent.auth.enabled(), "tls", tlsConfig != nil, "mtls", tlsConfig != nil && tlsConfig.ClientCAs != nil)
+		var err error
+		if tlsConfig != nil {
+			// certificates come from TLSConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("server failed", "error", err)
 			os.Exit(1)
 		}
 	}()
 
+	alertCtx, stopAlerts := context.WithCancel(context.Background())
+	if alerter := newAlerter(logger); alerter != nil {
+		go alerter.Run(alertCtx)
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	<-sigCh
+	stopAlerts()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -76,7 +117,9 @@ func main() {
 }
 
 type Agent struct {
-	logger *slog.Logger
+	logger   *slog.Logger
+	services *ServiceManager
+	auth     *Auth
 }
 
 type StatusResponse struct {
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+type ProcessInfo struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	User       string  `json:"user,omitempty"`
+	CPU        float64 `json:"cpu_percent"` // of one core, like top
+	Memory     float32 `json:"mem_percent"`
+	RSS        uint64  `json:"rss_bytes"`
+	Command    string  `json:"command,omitempty"`
+	cpuSeconds float64
+}
+
+type PortInfo struct {
+	Proto   string `json:"proto"`
+	Address string `json:"address"`
+	Port    uint32 `json:"port"`
+	PID     int32  `json:"pid,omitempty"`
+	Process string `json:"process,omitempty"`
+}
+
+// cpuSampleWindow is how long CPU time is measured for; processes' lifetime averages hide what's busy now
+const cpuSampleWindow = time.Second
+
+func (a *Agent) handleProcesses(w http.ResponseWriter, r *http.Request) {
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy != "memory" {
+		sortBy = "cpu"
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 15
+	}
+
+	before, err := snapshotProcesses()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("list processes failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	time.Sleep(cpuSampleWindow)
+	after, err := snapshotProcesses()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("list processes failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(topProcesses(before, after, cpuSampleWindow, sortBy, limit))
+}
+
+// snapshotProcesses reads every process it can; ones that exit mid-read are skipped
+func snapshotProcesses() (map[int32]ProcessInfo, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make(map[int32]ProcessInfo, len(procs))
+	for _, p := range procs {
+		times, err := p.Times()
+		if err != nil {
+			continue
+		}
+		info := ProcessInfo{PID: p.Pid, cpuSeconds: times.User + times.System}
+		info.Name, _ = p.Name()
+		info.User, _ = p.Username()
+		info.Memory, _ = p.MemoryPercent()
+		if mem, err := p.MemoryInfo(); err == nil {
+			info.RSS = mem.RSS
+		}
+		if cmd, err := p.Cmdline(); err == nil {
+			info.Command = truncateCommand(cmd)
+		}
+		snapshot[p.Pid] = info
+	}
+	return snapshot, nil
+}
+
+// topProcesses computes CPU use between two snapshots and returns the busiest processes
+func topProcesses(before, after map[int32]ProcessInfo, elapsed time.Duration, sortBy string, limit int) []ProcessInfo {
+	procs := make([]ProcessInfo, 0, len(after))
+	for pid, p := range after {
+		if prev, ok := before[pid]; ok && p.cpuSeconds >= prev.cpuSeconds {
+			p.CPU = (p.cpuSeconds - prev.cpuSeconds) / elapsed.Seconds() * 100
+		}
+		procs = append(procs, p)
+	}
+
+	sort.Slice(procs, func(i, j int) bool {
+		if sortBy == "memory" && procs[i].RSS != procs[j].RSS {
+			return procs[i].RSS > procs[j].RSS
+		}
+		if procs[i].CPU != procs[j].CPU {
+			return procs[i].CPU > procs[j].CPU
+		}
+		return procs[i].PID < procs[j].PID
+	})
+	if len(procs) > limit {
+		procs = procs[:limit]
+	}
+	return procs
+}
+
+func truncateCommand(cmd string) string {
+	if len(cmd) > 200 {
+		return cmd[:200] + "..."
+	}
+	return cmd
+}
+
+func (a *Agent) handlePorts(w http.ResponseWriter, r *http.Request) {
+	conns, err := net.Connections("inet")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("list sockets failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	names := make(map[int32]string)
+	name := func(pid int32) string {
+		if pid == 0 {
+			return ""
+		}
+		if n, ok := names[pid]; ok {
+			return n
+		}
+		if p, err := process.NewProcess(pid); err == nil {
+			names[pid], _ = p.Name()
+		}
+		return names[pid]
+	}
+
+	ports := listeningPorts(conns)
+	for i := range ports {
+		ports[i].Process = name(ports[i].PID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ports)
+}
+
+// listeningPorts keeps listening TCP sockets and unconnected UDP ones, sorted by port
+func listeningPorts(conns []net.ConnectionStat) []PortInfo {
+	seen := make(map[string]bool)
+	var ports []PortInfo
+	for _, c := range conns {
+		var proto string
+		switch {
+		case c.Type == syscall.SOCK_STREAM && c.Status == "LISTEN":
+			proto = "tcp"
+		case c.Type == syscall.SOCK_DGRAM && c.Raddr.Port == 0:
+			proto = "udp"
+		default:
+			continue
+		}
+		if c.Family == syscall.AF_INET6 {
+			proto += "6"
+		}
+
+		key := fmt.Sprintf("%s/%s/%d", proto, c.Laddr.IP, c.Laddr.Port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ports = append(ports, PortInfo{Proto: proto, Address: c.Laddr.IP, Port: c.Laddr.Port, PID: c.Pid})
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Proto < ports[j].Proto
+	})
+	return ports
+}
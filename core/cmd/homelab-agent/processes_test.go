@@ -0,0 +1,56 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestTopProcesses(t *testing.T) {
+	before := map[int32]ProcessInfo{
+		1:  {PID: 1, Name: "init", cpuSeconds: 10},
+		42: {PID: 42, Name: "ollama", cpuSeconds: 100, RSS: 8 << 30},
+		99: {PID: 99, Name: "jellyfin", cpuSeconds: 50, RSS: 1 << 30},
+	}
+	after := map[int32]ProcessInfo{
+		1:   {PID: 1, Name: "init", cpuSeconds: 10},
+		42:  {PID: 42, Name: "ollama", cpuSeconds: 100.5, RSS: 8 << 30},
+		99:  {PID: 99, Name: "jellyfin", cpuSeconds: 51.5, RSS: 1 << 30},
+		300: {PID: 300, Name: "new", cpuSeconds: 5, RSS: 1 << 20},
+	}
+
+	byCPU := topProcesses(before, after, time.Second, "cpu", 2)
+	if len(byCPU) != 2 || byCPU[0].Name != "jellyfin" || byCPU[0].CPU != 150 || byCPU[1].Name != "ollama" || byCPU[1].CPU != 50 {
+		t.Errorf("by cpu = %+v", byCPU)
+	}
+
+	byMem := topProcesses(before, after, time.Second, "memory", 10)
+	if len(byMem) != 4 || byMem[0].Name != "ollama" || byMem[1].Name != "jellyfin" {
+		t.Errorf("by memory = %+v", byMem)
+	}
+	for _, p := range byMem {
+		if p.Name == "new" && p.CPU != 0 {
+			t.Errorf("process without a previous sample got cpu %v", p.CPU)
+		}
+	}
+}
+
+func TestListeningPorts(t *testing.T) {
+	conns := []net.ConnectionStat{
+		{Type: syscall.SOCK_STREAM, Family: syscall.AF_INET, Status: "LISTEN", Laddr: net.Addr{IP: "0.0.0.0", Port: 8080}, Pid: 7},
+		{Type: syscall.SOCK_STREAM, Family: syscall.AF_INET, Status: "ESTABLISHED", Laddr: net.Addr{IP: "10.0.0.2", Port: 8080}, Raddr: net.Addr{IP: "10.0.0.9", Port: 5000}},
+		{Type: syscall.SOCK_STREAM, Family: syscall.AF_INET6, Status: "LISTEN", Laddr: net.Addr{IP: "::", Port: 22}, Pid: 1},
+		{Type: syscall.SOCK_DGRAM, Family: syscall.AF_INET, Laddr: net.Addr{IP: "0.0.0.0", Port: 53}},
+		{Type: syscall.SOCK_STREAM, Family: syscall.AF_INET, Status: "LISTEN", Laddr: net.Addr{IP: "0.0.0.0", Port: 8080}, Pid: 7},
+	}
+
+	ports := listeningPorts(conns)
+	if len(ports) != 3 {
+		t.Fatalf("ports = %+v", ports)
+	}
+	if ports[0].Proto != "tcp6" || ports[0].Port != 22 || ports[1].Proto != "udp" || ports[1].Port != 53 || ports[2].Port != 8080 || ports[2].PID != 7 {
+		t.Errorf("ports = %+v", ports)
+	}
+}
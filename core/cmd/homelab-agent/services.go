@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var validUnitName = regexp.MustCompile(`^[a-zA-Z0-9@_.:-]+$`)
+
+// ServiceManager controls the systemd units listed in SERVICES_ALLOWLIST; nothing
+// else can be inspected or restarted
+type ServiceManager struct {
+	allowed []string // unit names or globs, e.g. nginx.service, media-*
+	nsenter bool     // run systemctl in the host's namespaces when the agent is containerized
+}
+
+type ServiceInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	LoadState   string `json:"load_state"`
+	ActiveState string `json:"active_state"`
+	SubState    string `json:"sub_state"`
+	Running     bool   `json:"running"`
+	MainPID     int    `json:"main_pid,omitempty"`
+	Since       string `json:"since,omitempty"`
+	Restarts    int    `json:"restarts,omitempty"`
+}
+
+func newServiceManager() *ServiceManager {
+	s := &ServiceManager{nsenter: os.Getenv("SERVICES_NSENTER") == "true"}
+	for _, unit := range strings.Split(os.Getenv("SERVICES_ALLOWLIST"), ",") {
+		if unit = strings.TrimSpace(unit); unit != "" {
+			s.allowed = append(s.allowed, unitName(unit))
+		}
+	}
+	return s
+}
+
+// unitName adds the .service suffix when a name has no unit type
+func unitName(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return name + ".service"
+}
+
+func (s *ServiceManager) isAllowed(unit string) bool {
+	for _, pattern := range s.allowed {
+		if ok, _ := path.Match(pattern, unit); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ServiceManager) command(name string, args ...string) *exec.Cmd {
+	if s.nsenter {
+		// enter PID 1's namespaces so the host's systemd answers, not the container's
+		return exec.Command("nsenter", append([]string{"-t", "1", "-m", "-u", "-i", "-n", "-p", "--", name}, args...)...)
+	}
+	return exec.Command(name, args...)
+}
+
+// unit validates the {name} path value against the allowlist and returns the unit name
+func (s *ServiceManager) unit(r *http.Request) (string, int, error) {
+	name := r.PathValue("name")
+	if name == "" || !validUnitName.MatchString(name) {
+		return "", http.StatusBadRequest, fmt.Errorf("invalid service name")
+	}
+	unit := unitName(name)
+	if !s.isAllowed(unit) {
+		return "", http.StatusForbidden, fmt.Errorf("service %s is not in SERVICES_ALLOWLIST", unit)
+	}
+	return unit, 0, nil
+}
+
+func (a *Agent) handleListServices(w http.ResponseWriter, r *http.Request) {
+	if len(a.services.allowed) == 0 {
+		http.Error(w, "service control is disabled, set SERVICES_ALLOWLIST", http.StatusForbidden)
+		return
+	}
+
+	cmd := a.services.command("systemctl", "list-units", "--type=service", "--all", "--plain", "--no-legend", "--no-pager")
+	output, err := cmd.Output()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("systemctl list-units failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	services := []ServiceInfo{}
+	for _, svc := range parseListUnits(string(output)) {
+		if a.services.isAllowed(svc.Name) {
+			services = append(services, svc)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services)
+}
+
+func (a *Agent) handleServiceStatus(w http.ResponseWriter, r *http.Request) {
+	unit, code, err := a.services.unit(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	cmd := a.services.command("systemctl", "show", unit, "--no-pager",
+		"--property=Id,Description,LoadState,ActiveState,SubState,MainPID,ActiveEnterTimestamp,NRestarts")
+	output, err := cmd.Output()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("systemctl show failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(parseShow(string(output)))
+}
+
+func (a *Agent) handleServiceRestart(w http.ResponseWriter, r *http.Request) {
+	unit, code, err := a.services.unit(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	a.logger.Info("restarting service", "service", unit)
+
+	cmd := a.services.command("systemctl", "restart", unit)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		a.logger.Error("failed to restart service", "service", unit, "error", err)
+		http.Error(w, fmt.Sprintf("restart failed: %s", stderr.String()), http.StatusInternalServerError)
+		return
+	}
+
+	a.logger.Info("service restarted", "service", unit)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "restarted",
+		"service": unit,
+	})
+}
+
+func (a *Agent) handleServiceJournal(w http.ResponseWriter, r *http.Request) {
+	unit, code, err := a.services.unit(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	lines := r.URL.Query().Get("lines")
+	if n, err := strconv.Atoi(lines); err != nil || n < 1 || n > 10000 {
+		lines = "100"
+	}
+
+	cmd := a.services.command("journalctl", "-u", unit, "-n", lines, "--no-pager", "-o", "short-iso")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("journal failed: %s", string(output)), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write(output)
+}
+
+// parseListUnits reads `systemctl list-units --plain --no-legend` output
+func parseListUnits(output string) []ServiceInfo {
+	var services []ServiceInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		services = append(services, ServiceInfo{
+			Name:        fields[0],
+			LoadState:   fields[1],
+			ActiveState: fields[2],
+			SubState:    fields[3],
+			Description: strings.Join(fields[4:], " "),
+			Running:     fields[3] == "running",
+		})
+	}
+	return services
+}
+
+// parseShow reads the key=value output of `systemctl show`
+func parseShow(output string) ServiceInfo {
+	var svc ServiceInfo
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Id":
+			svc.Name = value
+		case "Description":
+			svc.Description = value
+		case "LoadState":
+			svc.LoadState = value
+		case "ActiveState":
+			svc.ActiveState = value
+		case "SubState":
+			svc.SubState = value
+		case "MainPID":
+			svc.MainPID, _ = strconv.Atoi(value)
+		case "ActiveEnterTimestamp":
+			svc.Since = value
+		case "NRestarts":
+			svc.Restarts, _ = strconv.Atoi(value)
+		}
+	}
+	svc.Running = svc.SubState == "running"
+	return svc
+}
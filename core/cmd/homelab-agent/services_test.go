@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestServiceAllowlist(t *testing.T) {
+	s := &ServiceManager{allowed: []string{unitName("nginx"), unitName("media-*"), "backup.timer"}}
+
+	for unit, want := range map[string]bool{
+		"nginx.service":    true,
+		"media-jf.service": true,
+		"backup.timer":     true,
+		"sshd.service":     false,
+		"nginx.socket":     false,
+	} {
+		if got := s.isAllowed(unit); got != want {
+			t.Errorf("isAllowed(%q) = %v, want %v", unit, got, want)
+		}
+	}
+}
+
+func TestParseListUnits(t *testing.T) {
+	output := "nginx.service loaded active running A high performance web server\n" +
+		"jellyfin.service loaded failed failed Jellyfin Media Server\n\n"
+
+	services := parseListUnits(output)
+	if len(services) != 2 {
+		t.Fatalf("got %d services", len(services))
+	}
+	if services[0].Name != "nginx.service" || !services[0].Running || services[0].Description != "A high performance web server" {
+		t.Errorf("nginx = %+v", services[0])
+	}
+	if services[1].ActiveState != "failed" || services[1].Running {
+		t.Errorf("jellyfin = %+v", services[1])
+	}
+}
+
+func TestParseShow(t *testing.T) {
+	output := "Id=nginx.service\nDescription=nginx\nLoadState=loaded\nActiveState=active\nSubState=running\n" +
+		"MainPID=812\nActiveEnterTimestamp=Mon 2026-10-12 09:00:01 UTC\nNRestarts=2\n"
+
+	svc := parseShow(output)
+	if svc.Name != "nginx.service" || !svc.Running || svc.MainPID != 812 || svc.Restarts != 2 || svc.Since == "" {
+		t.Errorf("parsed %+v", svc)
+	}
+}
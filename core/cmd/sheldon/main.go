@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -9,31 +10,52 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/bowerhall/sheldon/internal/admin"
 	"github.com/bowerhall/sheldon/internal/agent"
 	"github.com/bowerhall/sheldon/internal/alerts"
 	"github.com/bowerhall/sheldon/internal/approval"
+	"github.com/bowerhall/sheldon/internal/autoupdate"
+	"github.com/bowerhall/sheldon/internal/backup"
 	"github.com/bowerhall/sheldon/internal/bot"
 	"github.com/bowerhall/sheldon/internal/browser"
 	"github.com/bowerhall/sheldon/internal/budget"
+	"github.com/bowerhall/sheldon/internal/calendar"
 	"github.com/bowerhall/sheldon/internal/coder"
 	"github.com/bowerhall/sheldon/internal/config"
 	"github.com/bowerhall/sheldon/internal/conversation"
 	"github.com/bowerhall/sheldon/internal/cron"
 	"github.com/bowerhall/sheldon/internal/deployer"
 	"github.com/bowerhall/sheldon/internal/embedder"
+	"github.com/bowerhall/sheldon/internal/encryption"
+	"github.com/bowerhall/sheldon/internal/feeds"
+	"github.com/bowerhall/sheldon/internal/geo"
 	"github.com/bowerhall/sheldon/internal/health"
+	"github.com/bowerhall/sheldon/internal/hotreload"
+	"github.com/bowerhall/sheldon/internal/isolation"
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/llmlog"
 	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/mailbox"
+	"github.com/bowerhall/sheldon/internal/notify"
 	"github.com/bowerhall/sheldon/internal/operational"
 	"github.com/bowerhall/sheldon/internal/pinchtab"
+	"github.com/bowerhall/sheldon/internal/quality"
+	"github.com/bowerhall/sheldon/internal/secrets"
+	"github.com/bowerhall/sheldon/internal/setup"
+	"github.com/bowerhall/sheldon/internal/speech"
+	"github.com/bowerhall/sheldon/internal/sshexec"
 	"github.com/bowerhall/sheldon/internal/storage"
 	"github.com/bowerhall/sheldon/internal/telemetry"
 	"github.com/bowerhall/sheldon/internal/tools"
+	"github.com/bowerhall/sheldon/internal/tracing"
+	"github.com/bowerhall/sheldon/internal/watch"
 	"github.com/bowerhall/sheldonmem"
 	"github.com/joho/godotenv"
 )
@@ -46,7 +68,7 @@ func healthCheck(memory *sheldonmem.Store, essencePath string) error {
 	soulPath := filepath.Join(essencePath, "SOUL.md")
 
 	if _, err := os.Stat(soulPath); err != nil {
-		return fmt.Errorf("SOUL.md not found at %s", soulPath)
+		return fmt.Errorf("SOUL.md not found at %s (run `sheldon init` to create one)", soulPath)
 	}
 
 	logger.Debug("health check", "component", "soul", "status", "ok")
@@ -68,16 +90,87 @@ func healthCheck(memory *sheldonmem.Store, essencePath string) error {
 	return nil
 }
 
+// decryptBackup turns an encrypted .zip.enc backup back into a zip using MEMORY_ENCRYPTION_KEY
+func decryptBackup(in, out string) error {
+	secret := os.Getenv("MEMORY_ENCRYPTION_KEY")
+	if secret == "" {
+		return fmt.Errorf("MEMORY_ENCRYPTION_KEY is not set")
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	plain, err := encryption.OpenExport(secret, data)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(out, plain, 0600)
+}
+
 func main() {
+	if len(os.Args) == 4 && os.Args[1] == "decrypt-backup" {
+		if err := decryptBackup(os.Args[2], os.Args[3]); err != nil {
+			logger.Fatal("failed to decrypt backup", "error", err)
+		}
+		return
+	}
+
+	if len(os.Args) >= 2 && os.Args[1] == "init" {
+		envPath := ".env"
+		if len(os.Args) == 3 {
+			envPath = os.Args[2]
+		}
+		wizard := setup.New(os.Stdin, os.Stdout, setup.VerifyProvider)
+		if err := wizard.Run(context.Background(), envPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) >= 2 && os.Args[1] == "validate-config" {
+		if len(os.Args) == 3 {
+			os.Setenv("SHELDON_CONFIG", os.Args[2])
+		}
+		if err := validateConfig(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		logger.Fatal("failed to load config", "error", err)
 	}
 
+	// optional log of every LLM request, set before any client is created
+	var llmRedactor *llmlog.Redactor
+	if cfg.LLM.LogFile != "" {
+		llmRedactor = llmlog.NewRedactor(providerAPIKeys(cfg)...)
+		llmLog, err := llmlog.New(llmlog.Config{
+			Path:     cfg.LLM.LogFile,
+			MaxBytes: int64(cfg.LLM.LogMaxMB) << 20,
+			Session:  tools.SessionIDFromContext,
+		}, llmRedactor)
+		if err != nil {
+			logger.Fatal("failed to open llm log", "error", err)
+		}
+		defer llmLog.Close()
+		llm.SetRecorder(llmLog)
+		logger.Info("llm request log enabled", "path", cfg.LLM.LogFile)
+	}
+
 	model, err := llm.New(llm.Config{
-		Provider: cfg.LLM.Provider,
-		APIKey:   cfg.LLM.APIKey,
-		Model:    cfg.LLM.Model,
+		Provider:    cfg.LLM.Provider,
+		APIKey:      cfg.LLM.APIKey,
+		Model:       cfg.LLM.Model,
+		BaseURL:     cfg.LLM.BaseURL,
+		APIVersion:  cfg.LLM.APIVersion,
+		MaxAttempts: cfg.LLM.MaxAttempts,
 	})
 	if err != nil {
 		logger.Fatal("failed to create llm", "error", err)
@@ -89,6 +182,30 @@ func main() {
 	}
 	defer memory.Close()
 
+	var memoryKey *encryption.Key
+	if cfg.MemoryKey != "" {
+		salt, err := memory.Salt()
+		if err != nil {
+			logger.Fatal("failed to read memory salt", "error", err)
+		}
+		memoryKey = encryption.DeriveKey(cfg.MemoryKey, salt)
+		if err := memory.SetSealer(memoryKey); err != nil {
+			logger.Fatal("failed to enable memory encryption", "error", err)
+		}
+		// values sealed before the database had its own salt
+		resealed, err := memory.ResealLegacy(encryption.LegacyKey(cfg.MemoryKey))
+		if err != nil {
+			logger.Fatal("failed to re-seal memory values", "error", err)
+		}
+		if resealed > 0 {
+			logger.Info("re-sealed memory values under the salted key", "count", resealed)
+		}
+		logger.Info("memory encryption enabled")
+	}
+	if llmRedactor != nil {
+		llmRedactor.SetSource(memory.SensitiveValues)
+	}
+
 	// operational database for ephemeral data (usage, conversation buffer)
 	opsDBPath := filepath.Join(filepath.Dir(cfg.MemoryPath), "operational.db")
 	opsStore, err := operational.Open(opsDBPath)
@@ -118,7 +235,44 @@ func main() {
 
 	sheldon := agent.New(model, memory, cfg.EssencePath, cfg.Timezone)
 
+	transcriber, err := speech.New(speech.Config{
+		Provider: cfg.Speech.Provider,
+		APIKey:   cfg.Speech.APIKey,
+		BaseURL:  cfg.Speech.BaseURL,
+		Model:    cfg.Speech.Model,
+		Language: cfg.Speech.Language,
+	})
+	if err != nil {
+		logger.Fatal("failed to create transcriber", "error", err)
+	}
+
+	if transcriber != nil {
+		sheldon.SetTranscriber(transcriber)
+		logger.Info("voice transcription enabled", "provider", transcriber.Provider())
+	}
+
+	tracer := tracing.New(tracing.Config{
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		Headers:     cfg.Tracing.Headers,
+	})
+	if tracer != nil {
+		sheldon.SetTracer(tracer)
+		logger.Info("tracing enabled", "endpoint", cfg.Tracing.Endpoint)
+	}
+
+	// extra isolation for containers running generated code or untrusted pages
+	sandboxHardening := isolation.Options{
+		Runtime:         cfg.Sandbox.Runtime,
+		SeccompProfile:  cfg.Sandbox.SeccompProfile,
+		NoNewPrivileges: cfg.Sandbox.NoNewPrivileges,
+	}
+	if sandboxHardening.RuntimeName() != "" {
+		logger.Info("sandbox runtime", "runtime", sandboxHardening.RuntimeName())
+	}
+
 	var coderBridge *coder.Bridge
+	var appUpdater *autoupdate.Updater
 	if cfg.Coder.Enabled {
 		bridgeCfg := coder.BridgeConfig{
 			SandboxDir:     cfg.Coder.SandboxDir,
@@ -128,11 +282,30 @@ func main() {
 			SkillsDir:      cfg.Coder.SkillsDir,
 			Isolated:       cfg.Coder.Isolated,
 			Image:          cfg.Coder.Image,
+			Verify:         cfg.Coder.Verify,
+			FixAttempts:    cfg.Coder.FixAttempts,
 			GitEnabled:     cfg.Coder.Git.Enabled,
 			GitUserName:    cfg.Coder.Git.UserName,
 			GitUserEmail:   cfg.Coder.Git.UserEmail,
 			GitOrgURL:      cfg.Coder.Git.OrgURL,
 			GitToken:       cfg.Coder.Git.Token,
+			Runtime: coder.RuntimeConfig{
+				Binary:   cfg.Coder.Runtime.Binary,
+				Network:  cfg.Coder.Runtime.Network,
+				UID:      cfg.Coder.Runtime.UID,
+				GID:      cfg.Coder.Runtime.GID,
+				Rootless: cfg.Coder.Runtime.Rootless,
+				Harden:   sandboxHardening,
+			},
+			Limits: coder.ResourceLimits{
+				CPUs:   cfg.Coder.Limits.CPUs,
+				Memory: cfg.Coder.Limits.Memory,
+				Pids:   cfg.Coder.Limits.Pids,
+				Disk:   cfg.Coder.Limits.Disk,
+			},
+			WorkspaceQuota:   int64(cfg.Coder.Limits.WorkspaceQuotaMB) * 1024 * 1024,
+			ReviewPushes:     cfg.Coder.Git.ReviewPushes,
+			AutoApproveLines: cfg.Coder.Git.AutoApproveLines,
 		}
 
 		var err error
@@ -149,21 +322,75 @@ func main() {
 		}
 
 		// register deployer tools
-		composeDeploy := deployer.NewComposeDeployer(deployer.ComposeDeployerConfig{
-			AppsFile:     cfg.Deployer.AppsFile,
-			HostAppsFile: cfg.Deployer.HostAppsFile,
-			PathPrefix:   cfg.Deployer.PathPrefix,
-			HostPrefix:   cfg.Deployer.HostPrefix,
-			Network:      cfg.Deployer.Network,
-		})
+		var appDeployer deployer.Deployer
+		switch cfg.Deployer.Target {
+		case "kubernetes":
+			appDeployer = deployer.NewK8sDeployer(deployer.K8sDeployerConfig{
+				Namespace:    cfg.Deployer.Kubernetes.Namespace,
+				Registry:     cfg.Deployer.Kubernetes.Registry,
+				IngressClass: cfg.Deployer.Kubernetes.IngressClass,
+				CertIssuer:   cfg.Deployer.Kubernetes.CertIssuer,
+			})
+		case "compose":
+			appDeployer = deployer.NewComposeDeployer(deployer.ComposeDeployerConfig{
+				AppsFile:     cfg.Deployer.AppsFile,
+				HostAppsFile: cfg.Deployer.HostAppsFile,
+				PathPrefix:   cfg.Deployer.PathPrefix,
+				HostPrefix:   cfg.Deployer.HostPrefix,
+				Network:      cfg.Deployer.Network,
+			})
+		default:
+			logger.Fatal("unknown deployer target", "target", cfg.Deployer.Target)
+		}
+		deployHistory, err := deployer.NewHistory(memory.DB())
+		if err != nil {
+			logger.Fatal("failed to create deployment history", "error", err)
+		}
+		appDeployer.SetHistory(deployHistory)
 		domain := os.Getenv("DOMAIN")
 		if domain == "" {
 			// No domain configured - use server's public IP for links
 			domain = getPublicIP()
 			logger.Info("no domain configured, using IP for app URLs", "ip", domain)
 		}
-		tools.RegisterComposeDeployerTools(sheldon.Registry(), builder, composeDeploy, domain)
-		logger.Info("deployer enabled", "apps_file", cfg.Deployer.AppsFile)
+		tools.RegisterComposeDeployerTools(sheldon.Registry(), builder, appDeployer, domain)
+		// traefik routes live in compose labels, so only the compose target can edit them
+		if routes, ok := appDeployer.(deployer.RouteManager); ok {
+			tools.RegisterRouteTools(sheldon.Registry(), routes)
+		}
+		logger.Info("deployer enabled", "target", cfg.Deployer.Target, "apps_file", cfg.Deployer.AppsFile)
+
+		// app secrets are sealed with the memory key and scrubbed from logs and tool output
+		if memoryKey != nil {
+			appSecrets, err := secrets.NewStore(memory.DB(), memoryKey, encryption.LegacyKey(cfg.MemoryKey))
+			if err != nil {
+				logger.Fatal("failed to load app secrets", "error", err)
+			}
+			appDeployer.SetSecrets(appSecrets)
+			sheldon.Registry().SetRedactor(appSecrets.Redact)
+			logger.SetRedactor(appSecrets.Redact)
+			tools.RegisterSecretTools(sheldon.Registry(), appSecrets)
+			logger.Info("app secrets enabled")
+		} else {
+			logger.Info("app secrets disabled, set MEMORY_ENCRYPTION_KEY to enable")
+		}
+
+		// auto-update redeploys apps from their repos, so it needs git access
+		if cfg.Coder.Git.Enabled {
+			updateStore, err := autoupdate.NewStore(memory.DB())
+			if err != nil {
+				logger.Fatal("failed to create auto-update store", "error", err)
+			}
+			gitOps := coder.NewGitOps(coder.GitConfig{
+				UserName:  cfg.Coder.Git.UserName,
+				UserEmail: cfg.Coder.Git.UserEmail,
+				OrgURL:    cfg.Coder.Git.OrgURL,
+				Token:     cfg.Coder.Git.Token,
+			})
+			appUpdater = autoupdate.NewUpdater(updateStore, gitOps, appDeployer, domain,
+				cfg.Coder.SandboxDir+"/autoupdate", cfg.Deployer.UpdateInterval)
+			tools.RegisterAutoUpdateTools(sheldon.Registry(), appUpdater)
+		}
 
 		mode := "subprocess"
 		if cfg.Coder.Isolated {
@@ -179,15 +406,18 @@ func main() {
 	if err != nil {
 		logger.Fatal("failed to create skills manager", "error", err)
 	}
+	skillsManager.SetIndexes(cfg.SkillIndexes)
 	tools.RegisterSkillsTools(sheldon.Registry(), skillsManager)
 	sheldon.SetSkillsDir(skillsDir)
+	tools.RegisterPromptTools(sheldon.Registry(), memory, sheldon.ReloadPrompt)
 	logger.Info("skills enabled", "dir", skillsDir)
 
 	// browser tools - prefer sandbox with JS rendering, fallback to HTTP
 	var browserRunner *browser.Runner
 	if cfg.Browser.SandboxEnabled {
 		browserRunner = browser.NewRunner(browser.Config{
-			Image: cfg.Browser.Image,
+			Image:  cfg.Browser.Image,
+			Harden: sandboxHardening,
 		})
 		logger.Info("browser sandbox enabled", "image", cfg.Browser.Image)
 	}
@@ -213,20 +443,77 @@ func main() {
 	if err != nil {
 		logger.Fatal("failed to create cron store", "error", err)
 	}
-	tools.RegisterCronTools(sheldon.Registry(), cronStore, cronTz)
+	tools.RegisterCronTools(sheldon.Registry(), cronStore, skillsManager, cronTz)
+	tools.RegisterTimezoneTool(sheldon.Registry(), cronStore, sheldon.SetTimezone)
 	logger.Info("cron tools enabled", "timezone", cfg.Timezone)
 
-	// conversation buffer for recent message continuity
-	convoBufferSize := 12 // default
-	if size, err := strconv.Atoi(os.Getenv("CONVERSATION_BUFFER_SIZE")); err == nil && size > 0 {
-		convoBufferSize = size
+	// calendar tools (CalDAV or Google)
+	cal, err := calendar.New(calendar.Config{
+		Provider:     cfg.Calendar.Provider,
+		Timezone:     cronTz,
+		URL:          cfg.Calendar.URL,
+		Username:     cfg.Calendar.Username,
+		Password:     cfg.Calendar.Password,
+		CalendarID:   cfg.Calendar.CalendarID,
+		ClientID:     cfg.Calendar.ClientID,
+		ClientSecret: cfg.Calendar.ClientSecret,
+		RefreshToken: cfg.Calendar.RefreshToken,
+	})
+	if err != nil {
+		logger.Fatal("failed to create calendar", "error", err)
+	}
+	if cal != nil {
+		tools.RegisterCalendarTools(sheldon.Registry(), cal, cronStore, cronTz)
+		logger.Info("calendar tools enabled", "provider", cfg.Calendar.Provider)
+	}
+
+	// email tools for the user's own mailbox
+	if cfg.Inbox.Enabled {
+		tools.RegisterEmailTools(sheldon.Registry(), &mailbox.Account{
+			IMAPAddr:       cfg.Inbox.IMAPAddr,
+			SMTPAddr:       cfg.Inbox.SMTPAddr,
+			Username:       cfg.Inbox.Username,
+			Password:       cfg.Inbox.Password,
+			From:           cfg.Inbox.From,
+			AllowedSenders: cfg.Inbox.AllowedSenders,
+			AuthservID:     cfg.Inbox.AuthservID,
+		}, cronTz)
+		logger.Info("email tools enabled", "imap", cfg.Inbox.IMAPAddr, "allowlist", len(cfg.Inbox.AllowedSenders))
+		if len(cfg.Inbox.AllowedSenders) > 0 && cfg.Inbox.AuthservID == "" {
+			logger.Warn("INBOX_AUTHSERV_ID not set, no mail passes the sender allowlist")
+		}
+	}
+
+	// geocoding and travel time (Nominatim + OSRM)
+	if cfg.Geo.Enabled {
+		geoClient := geo.New(cfg.Geo.NominatimURL, cfg.Geo.OSRMURL, "")
+		tools.RegisterLocationTools(sheldon.Registry(), geoClient, cronStore, cronTz)
+		logger.Info("location tools enabled")
+	}
+
+	// RSS/Atom subscriptions, delivered by the feed-digest cron
+	feedStore, err := feeds.NewStore(memory.DB())
+	if err != nil {
+		logger.Fatal("failed to create feed store", "error", err)
 	}
-	convoStore, err := conversation.NewStore(opsStore.DB(), convoBufferSize)
+	feedPoller := feeds.NewPoller(feedStore, cfg.Feeds.PollInterval)
+	tools.RegisterFeedTools(sheldon.Registry(), feedStore, feedPoller, cronStore)
+
+	// page watches, each checked by its own watch-page cron
+	watchStore, err := watch.NewStore(memory.DB())
+	if err != nil {
+		logger.Fatal("failed to create watch store", "error", err)
+	}
+	watchFetcher := watch.NewFetcher()
+	tools.RegisterWatchTools(sheldon.Registry(), watchStore, watchFetcher, cronStore)
+
+	// conversation buffer for recent message continuity
+	convoStore, err := conversation.NewStore(opsStore.DB(), cfg.Sessions.BufferSize)
 	if err != nil {
 		logger.Fatal("failed to create conversation store", "error", err)
 	}
 	sheldon.SetConversationStore(convoStore)
-	logger.Info("conversation buffer enabled", "max_messages", convoBufferSize)
+	logger.Info("conversation buffer enabled", "max_messages", cfg.Sessions.BufferSize)
 
 	// minio storage (optional)
 	var storageClient *storage.Client
@@ -250,6 +537,8 @@ func main() {
 			SecretKey:      cfg.Storage.SecretKey,
 			UseSSL:         cfg.Storage.UseSSL,
 			PublicUseSSL:   publicUseSSL,
+			UserQuota:      storage.Quota{MaxBytes: cfg.Storage.UserQuota, PruneOldest: cfg.Storage.UserPrune},
+			AgentQuota:     storage.Quota{MaxBytes: cfg.Storage.AgentQuota, PruneOldest: cfg.Storage.AgentPrune},
 		})
 		if err != nil {
 			logger.Error("failed to create storage client", "error", err)
@@ -260,10 +549,14 @@ func main() {
 				storageClient = nil
 			} else {
 				tools.RegisterStorageTools(sheldon.Registry(), storageClient)
+				tools.RegisterTabularTools(sheldon.Registry(), storageClient)
 				if coderBridge != nil {
 					tools.RegisterCoderStorageTools(sheldon.Registry(), coderBridge, storageClient)
 					logger.Info("coder storage tools enabled")
 				}
+				if browserRunner != nil {
+					tools.RegisterBrowserDownloadTool(sheldon.Registry(), browserRunner, storageClient)
+				}
 				logger.Info("storage enabled", "endpoint", cfg.Storage.Endpoint, "publicEndpoint", publicEndpoint, "publicSSL", publicUseSSL)
 			}
 			cancel()
@@ -290,26 +583,65 @@ func main() {
 	modelRegistry := config.NewModelRegistry(runtimeCfg)
 
 	// LLM factory for dynamic model switching
-	llmFactory := func() (llm.LLM, error) {
-		provider := runtimeCfg.Get("llm_provider")
+	llmFactory := func(provider, model string) (llm.LLM, error) {
 		if provider == "" {
 			provider = cfg.LLM.Provider
 		}
-		model := runtimeCfg.Get("llm_model")
 		if model == "" {
 			model = cfg.LLM.Model
 		}
 		apiKey := getAPIKeyForProvider(provider, cfg)
+		baseURL, apiVersion := getEndpointForProvider(provider)
 		return llm.New(llm.Config{
-			Provider: provider,
-			APIKey:   apiKey,
-			Model:    model,
+			Provider:    provider,
+			APIKey:      apiKey,
+			Model:       model,
+			BaseURL:     baseURL,
+			APIVersion:  apiVersion,
+			MaxAttempts: cfg.LLM.MaxAttempts,
 		})
 	}
 
 	sheldon.SetLLMFactory(llmFactory, runtimeCfg)
-	tools.RegisterModelTools(sheldon.Registry(), runtimeCfg, modelRegistry)
-	tools.RegisterRemoteTools(sheldon.Registry(), runtimeCfg)
+
+	// optional second model that scores replies so quality can be compared across models
+	if cfg.LLM.EvalModel != "" {
+		evalModel, err := llmFactory(cfg.LLM.EvalProvider, cfg.LLM.EvalModel)
+		if err != nil {
+			logger.Warn("failed to create evaluation model", "error", err)
+		} else if scoreStore, err := quality.NewStore(opsStore.DB(), cronTz); err != nil {
+			logger.Warn("failed to create quality store", "error", err)
+		} else {
+			sheldon.SetEvaluator(quality.NewEvaluator(evalModel, scoreStore), cfg.LLM.EvalSample)
+			tools.RegisterQualityTools(sheldon.Registry(), scoreStore, cronTz)
+			logger.Info("response evaluation enabled", "model", cfg.LLM.EvalProvider+"/"+cfg.LLM.EvalModel, "sample", cfg.LLM.EvalSample)
+		}
+	}
+	tools.RegisterModelTools(sheldon.Registry(), runtimeCfg, modelRegistry, llmFactory)
+	tools.RegisterPersonaTools(sheldon.Registry(), runtimeCfg, cfg.EssencePath)
+	convoStore.SetLimitFunc(runtimeCfg.SessionBufferSize)
+	tools.RegisterContextTools(sheldon.Registry(), runtimeCfg, convoStore, sheldon.ClearContext)
+	tools.RegisterProactiveTools(sheldon.Registry(), runtimeCfg)
+	tools.RegisterQuietTools(sheldon.Registry(), runtimeCfg, cronTz)
+	if scriptRuntime, err := tools.NewScriptRuntime(context.Background()); err != nil {
+		logger.Error("failed to start skill script runtime", "error", err)
+	} else {
+		defer scriptRuntime.Close(context.Background())
+		tools.RegisterSkillScriptTools(sheldon.Registry(), skillsManager, scriptRuntime, runtimeCfg)
+	}
+	tools.RegisterRemoteTools(sheldon.Registry(), runtimeCfg, cfg.Agent)
+	if cfg.SSHHosts != "" {
+		sshCfg, err := sshexec.LoadConfig(cfg.SSHHosts)
+		if err != nil {
+			logger.Fatal("failed to load ssh hosts", "error", err)
+		}
+		executor, err := sshexec.New(sshCfg)
+		if err != nil {
+			logger.Fatal("invalid ssh hosts", "error", err)
+		}
+		tools.RegisterSSHTools(sheldon.Registry(), executor)
+		logger.Info("ssh commands enabled", "hosts", executor.Hosts())
+	}
 	tools.RegisterSystemTools(sheldon.Registry(), cfg.MemoryPath, storageClient)
 	tools.RegisterExtractionTool(sheldon.Registry(), sheldon.ProcessEndOfDay)
 	logger.Info("model management enabled", "ollama", runtimeCfg.Get("ollama_host"))
@@ -350,30 +682,177 @@ func main() {
 		go b.Start(ctx)
 	}
 
+	if cfg.Bots.Web.Enabled {
+		b, err := bot.NewWeb(cfg.Bots.Web.Addr, cfg.Bots.Web.Token, sheldon)
+		if err != nil {
+			logger.Fatal("failed to create web chat", "error", err)
+		}
+
+		bots = append(bots, b)
+		enabledProviders = append(enabledProviders, "web")
+		logger.Info("web chat enabled", "addr", cfg.Bots.Web.Addr)
+
+		go b.Start(ctx)
+	}
+
+	if cfg.Bots.Email.Enabled {
+		b, err := bot.NewEmail(bot.EmailConfig{
+			IMAPAddr:       cfg.Bots.Email.IMAPAddr,
+			SMTPAddr:       cfg.Bots.Email.SMTPAddr,
+			Username:       cfg.Bots.Email.Username,
+			Password:       cfg.Bots.Email.Password,
+			From:           cfg.Bots.Email.From,
+			AllowedSenders: cfg.Bots.Email.AllowedSenders,
+			OwnerAddress:   cfg.Bots.Email.OwnerAddress,
+			AuthservID:     cfg.Bots.Email.AuthservID,
+			PollInterval:   cfg.Bots.Email.PollInterval,
+		}, sheldon)
+		if err != nil {
+			logger.Fatal("failed to create email channel", "error", err)
+		}
+
+		bots = append(bots, b)
+		enabledProviders = append(enabledProviders, "email")
+		logger.Info("email channel enabled", "from", cfg.Bots.Email.From, "owner", cfg.Bots.Email.OwnerAddress)
+		if cfg.Bots.Email.AuthservID == "" {
+			logger.Warn("EMAIL_AUTHSERV_ID not set, the owner gets limited access and email approvals are ignored")
+		}
+
+		go b.Start(ctx)
+	}
+
 	if len(bots) == 0 {
-		logger.Fatal("no bot providers enabled, set TELEGRAM_TOKEN or DISCORD_TOKEN")
+		logger.Fatal("no bot providers enabled, set TELEGRAM_TOKEN, DISCORD_TOKEN, WEB_CHAT_TOKEN or EMAIL_USERNAME")
 	}
 
-	notifyBot := bots[0]
+	// keep slash command autocomplete in step with installed skills
+	skillsManager.OnChange(func(string) {
+		for _, b := range bots {
+			if p, ok := b.(bot.CommandPublisher); ok {
+				if err := p.PublishCommands(); err != nil {
+					logger.Warn("failed to publish commands", "error", err)
+				}
+			}
+		}
+	})
+
+	// route notifications to whichever bot the chat belongs to, falling back to the first one
+	notifyBot := bot.NewRouter(convoStore.FindSession)
+	for i, b := range bots {
+		notifyBot.Add(enabledProviders[i], b)
+	}
+	sheldon.SetSessionTracker(notifyBot.Track)
+
+	// schedules run on each chat's own clock once its user has set a timezone
+	cronStore.SetTimezoneResolver(func(chatID int64) *time.Location {
+		return sheldon.Timezone(notifyBot.SessionID(chatID))
+	})
+
+	// budget, cron, alert and backup notifications go to chat and/or push services
+	notifier := notify.New(cfg.Notify, notify.SinkFunc(func(ctx context.Context, m notify.Message) error {
+		chatID := m.ChatID
+		if chatID == 0 {
+			chatID = cfg.Alert.ChatID
+		}
+		if chatID == 0 {
+			return nil
+		}
+		return notifyBot.Send(chatID, m.Text)
+	}))
+
 	sheldon.SetNotifyFunc(func(chatID int64, message string) {
 		if err := notifyBot.Send(chatID, message); err != nil {
 			logger.Error("notification failed", "error", err, "chatID", chatID)
 		}
 	})
 
+	if appUpdater != nil {
+		appUpdater.SetNotify(func(chatID int64, message string) {
+			if err := notifyBot.Send(chatID, message); err != nil {
+				logger.Error("auto-update notification failed", "error", err, "chatID", chatID)
+			}
+		})
+		go appUpdater.Run(ctx)
+		logger.Info("app auto-update started", "interval", cfg.Deployer.UpdateInterval)
+	}
+
 	// approval system for dangerous tools
 	approvalMgr := approval.NewManager(2 * time.Minute)
+	// review and contradiction buttons only work for the user they were sent to
+	prompts := approval.NewPrompts()
 	sheldon.SetApprovalManager(approvalMgr)
-	sheldon.SetApprovalSender(func(chatID int64, message string, approvalID string) error {
-		buttons := []bot.Button{
-			{Label: "Approve", CallbackID: approvalID + ":approve"},
-			{Label: "Deny", CallbackID: approvalID + ":deny"},
-		}
-		_, err := notifyBot.SendWithButtons(chatID, message, buttons)
+	sheldon.SetConflictSender(func(chatID, userID int64, owner, message string, conflictID int64) error {
+		prompts.Track(fmt.Sprintf("conflict-%d", conflictID), approval.Prompt{ChatID: chatID, UserID: userID, Owner: owner})
+		_, err := notifyBot.SendWithButtons(chatID, message, bot.ConflictButtons(conflictID))
 		return err
 	})
+	sendApproval := func(chatID int64, message string, approvalID string) error {
+		pending, err := approvalMgr.Get(approvalID)
+		if err != nil {
+			return err
+		}
+		return bot.RunApprovalCountdown(notifyBot, chatID, message, approvalID, approvalMgr.Timeout(), pending.Done(), pending.Expired)
+	}
+	sheldon.SetApprovalSender(sendApproval)
+	if coderBridge != nil {
+		// coder diffs are shown to the user before anything is pushed
+		coderBridge.SetPushReviewer(func(ctx context.Context, review coder.PushReview) (bool, error) {
+			chatID := tools.ChatIDFromContext(ctx)
+			if chatID == 0 {
+				return false, fmt.Errorf("no chat to ask for push approval")
+			}
+
+			desc := fmt.Sprintf("[Approval Required]\nPush to %s (branch %s)\n%d files, +%d -%d\n\n```\n%s\n```",
+				review.Repo, review.Branch, review.Diff.FilesChanged, review.Diff.Additions, review.Diff.Deletions, review.Diff.Stat)
+			if review.Diff.Patch != "" {
+				desc += "\n```\n" + review.Diff.Patch + "\n```"
+			}
+
+			approvalID := approvalMgr.Start(chatID, tools.UserIDFromContext(ctx), "git_push", review.TaskID, desc)
+			if err := sendApproval(chatID, desc, approvalID); err != nil {
+				approvalMgr.Cancel(approvalID)
+				return false, err
+			}
+			return approvalMgr.Wait(ctx, approvalID)
+		})
+	}
 	for _, b := range bots {
 		b.SetApprovalCallback(func(approvalID string, approved bool, userID int64) {
+			if conflictID, keep, ok := bot.ParseConflictID(approvalID); ok {
+				resolution := sheldonmem.KeepBoth
+				if approved && keep == "a" {
+					resolution = sheldonmem.KeepA
+				} else if approved && keep == "b" {
+					resolution = sheldonmem.KeepB
+				}
+				prompt, err := prompts.Claim(fmt.Sprintf("conflict-%d", conflictID), userID)
+				if err != nil {
+					logger.Warn("contradiction button rejected", "error", err, "conflict", conflictID, "userID", userID)
+					return
+				}
+				if err := tools.ResolveReviewedConflict(memory, prompt.Owner, conflictID, resolution); err != nil {
+					logger.Warn("resolving contradiction failed", "error", err, "conflict", conflictID)
+				} else {
+					logger.Info("contradiction resolved", "conflict", conflictID, "keep", keep, "userID", userID)
+				}
+				return
+			}
+			if factID, ok := bot.ParseReviewID(approvalID); ok {
+				if !approved {
+					return
+				}
+				prompt, err := prompts.Claim(approvalID, userID)
+				if err != nil {
+					logger.Warn("review button rejected", "error", err, "factID", factID, "userID", userID)
+					return
+				}
+				if err := tools.ForgetReviewed(memory, prompt.Owner, factID); err != nil {
+					logger.Warn("forget from review failed", "error", err, "factID", factID)
+				} else {
+					logger.Info("fact forgotten from review", "factID", factID, "userID", userID)
+				}
+				return
+			}
 			if err := approvalMgr.Resolve(approvalID, approved, userID); err != nil {
 				logger.Warn("approval resolve failed", "error", err, "approvalID", approvalID)
 			}
@@ -381,10 +860,47 @@ func main() {
 	}
 	logger.Info("approval system enabled", "timeout", approvalMgr.Timeout())
 
+	tools.RegisterGraphTool(sheldon.Registry(), memory, browserRunner, notifyBot)
+	tools.RegisterChartTool(sheldon.Registry(), notifyBot)
+	if browserRunner != nil {
+		tools.RegisterScreenshotTool(sheldon.Registry(), browserRunner, notifyBot)
+	}
+	tools.RegisterDocumentTools(sheldon.Registry(), memory, storageClient)
+	tools.RegisterContactTools(sheldon.Registry(), memory, cronStore, cronTz)
+	tools.RegisterConversationTools(sheldon.Registry(), memory)
+	tools.RegisterReviewTools(sheldon.Registry(), memory, func(ctx context.Context, chatID int64, factID int64, text string) error {
+		buttons := bot.ReviewButtons(factID)
+		id, _, _ := strings.Cut(buttons[0].CallbackID, ":")
+		prompts.Track(id, approval.Prompt{ChatID: chatID, UserID: tools.UserIDFromContext(ctx), Owner: tools.UserEntityName(ctx)})
+		_, err := notifyBot.SendWithButtons(chatID, text, buttons)
+		return err
+	})
+
 	// media tools for sending images/videos/documents to users
 	if storageClient != nil {
 		tools.RegisterMediaTools(sheldon.Registry(), notifyBot, storageClient)
-		tools.RegisterBackupTool(sheldon.Registry(), storageClient, cfg.MemoryPath, notifyBot)
+		tools.RegisterMediaLibraryTools(sheldon.Registry(), memory, runtimeCfg, storageClient, notifyBot)
+		notifyBot.SetOriginalStore(storageClient.KeepOriginal)
+		notifyBot.SetLongTextStore(storageClient.ShareText)
+		sheldon.SetMediaArchiver(func(ctx context.Context, name string, data []byte, contentType string) error {
+			return storageClient.Upload(ctx, storageClient.UserBucket(), name, data, contentType)
+		})
+		tools.RegisterBackupTool(sheldon.Registry(), storageClient, cfg.MemoryPath, memoryKey, notifyBot)
+
+		if cfg.Backup.Interval > 0 {
+			if memoryKey == nil {
+				logger.Warn("scheduled backups are stored unencrypted, set MEMORY_ENCRYPTION_KEY to encrypt them")
+			}
+			scheduler := backup.New(memory.Backup, storageClient, memoryKey, cfg.Backup, func(title, text string, failed bool) {
+				priority := notify.PriorityNormal
+				if failed {
+					priority = notify.PriorityHigh
+				}
+				notifier.Send(ctx, notify.Message{Kind: notify.KindBackup, Title: title, Text: text, Priority: priority})
+			})
+			go scheduler.Run(ctx)
+			logger.Info("scheduled backups enabled", "interval", cfg.Backup.Interval, "keep", cfg.Backup.Keep)
+		}
 		logger.Info("media tools enabled")
 	}
 
@@ -400,20 +916,14 @@ func main() {
 
 			func(used, limit int) {
 				msg := fmt.Sprintf("Budget warning: %d/%d tokens used (%.0f%%). Approaching daily limit.", used, limit, float64(used)/float64(limit)*100)
-
-				if cfg.Alert.ChatID != 0 {
-					notifyBot.Send(cfg.Alert.ChatID, msg)
-				}
+				notifier.Send(ctx, notify.Message{Kind: notify.KindBudget, Title: "Sheldon budget warning", Text: msg})
 
 				logger.Warn("budget warning", "used", used, "limit", limit)
 			},
 
 			func(used, limit int) {
 				msg := fmt.Sprintf("Budget exceeded: %d/%d tokens. Responses disabled until tomorrow.", used, limit)
-
-				if cfg.Alert.ChatID != 0 {
-					notifyBot.Send(cfg.Alert.ChatID, msg)
-				}
+				notifier.Send(ctx, notify.Message{Kind: notify.KindBudget, Title: "Sheldon budget exceeded", Text: msg, Priority: notify.PriorityHigh})
 
 				logger.Error("budget exceeded", "used", used, "limit", limit)
 			},
@@ -430,18 +940,23 @@ func main() {
 		}
 
 		sheldon.SetBudget(tracker)
+		if coderBridge != nil {
+			coderBridge.SetBudget(tracker)
+		}
 		logger.Info("budget tracking enabled", "limit", cfg.Budget.DailyLimit, "warnAt", cfg.Budget.WarnAt)
 	}
+	tools.RegisterCompareTool(sheldon.Registry(), llmFactory, modelRegistry, sheldon.Budget())
+	tools.RegisterProviderHealthTool(sheldon.Registry(), sheldon.Health(), sheldon.ActiveModel)
 
-	if cfg.Alert.ChatID != 0 {
+	if cfg.Alert.ChatID != 0 || notifier.External(notify.KindAlerts) {
 		alerter := alerts.New(
 			func(message string) {
-				notifyBot.Send(cfg.Alert.ChatID, message)
+				notifier.Send(ctx, notify.Message{Kind: notify.KindAlerts, Title: "Sheldon alert", Text: message, Priority: notify.PriorityHigh})
 			},
 			time.Hour,
 		)
 		sheldon.SetAlerter(alerter)
-		logger.Info("error alerting enabled", "chatID", cfg.Alert.ChatID)
+		logger.Info("error alerting enabled", "chatID", cfg.Alert.ChatID, "sinks", cfg.Notify.Routes["alerts"])
 	}
 
 	go func() {
@@ -452,6 +967,18 @@ func main() {
 			} else if deleted > 0 {
 				logger.Info("decay completed", "deleted", deleted)
 			}
+
+			purged, err := memory.PurgeForgotten(tools.ForgetWindow)
+			if err != nil {
+				logger.Error("purging forgotten facts failed", "error", err)
+			} else if purged > 0 {
+				logger.Info("forgotten facts purged", "purged", purged)
+			}
+
+			// digests look back a week at most, so a month of run history is plenty
+			if _, err := cronStore.DeleteRunsBefore(time.Now().Add(-30 * 24 * time.Hour)); err != nil {
+				logger.Error("pruning cron history failed", "error", err)
+			}
 		}
 	}()
 
@@ -464,20 +991,41 @@ func main() {
 			cronStore,
 			memory,
 			// TriggerFunc: injects into agent loop
-			func(chatID int64, sessionID string, prompt string) (string, error) {
+			func(ctx context.Context, chatID int64, sessionID string, prompt string) (string, error) {
 				return sheldon.ProcessSystemTrigger(ctx, sessionID, prompt)
 			},
-			// NotifyFunc: sends response to chat
+			// NotifyFunc: sends response to chat and any push services routed for crons
 			func(chatID int64, msg string) {
-				notifyBot.Send(chatID, msg)
+				notifier.Send(ctx, notify.Message{Kind: notify.KindCron, ChatID: chatID, Title: "Sheldon", Text: msg})
 			},
 			tz,
 		)
 		cronRunner.SetAgent(sheldon)
+		cronRunner.SetSessionResolver(notifyBot.SessionID)
+		cronRunner.SetSkillLoader(skillsManager.Read)
+		cronRunner.EnableFeeds(feedStore)
+		cronRunner.EnableWatches(watchStore, watchFetcher)
+
+		if cfg.Digest.ChatID != 0 {
+			var period time.Duration
+			if cfg.Digest.Frequency != "" {
+				period = cfg.Digest.Period()
+			}
+			if err := cronRunner.EnableDigest(cfg.Digest.ChatID, cfg.Digest.Schedule(), period); err != nil {
+				logger.Error("failed to schedule memory digest", "error", err)
+			} else if period > 0 {
+				logger.Info("memory digest scheduled", "frequency", cfg.Digest.Frequency, "schedule", cfg.Digest.Schedule(), "chatID", cfg.Digest.ChatID)
+			}
+		}
 		go cronRunner.Run(ctx)
+		go feedPoller.Run(ctx)
+		logger.Info("feed poller started", "interval", cfg.Feeds.PollInterval)
 		logger.Info("cron runner started", "provider", provider)
 	}
 
+	go sheldon.RunSessionEviction(ctx, cfg.Sessions.IdleTTL, cfg.Sessions.MaxSessions)
+	logger.Info("session eviction enabled", "ttl", cfg.Sessions.IdleTTL, "max", cfg.Sessions.MaxSessions)
+
 	embedderProvider := cfg.Embedder.Provider
 	if embedderProvider == "" {
 		embedderProvider = "none"
@@ -495,6 +1043,35 @@ func main() {
 	healthServer.Start()
 	logger.Debug("health server started", "port", healthPort)
 
+	if cfg.Admin.Token != "" {
+		adminServer, err := admin.New(cfg.Admin.Addr, cfg.Admin.Token, sheldon)
+		if err != nil {
+			logger.Fatal("failed to create admin API", "error", err)
+		}
+		adminServer.AddChecker("memory", memory)
+		adminServer.SetRuntimeConfig(runtimeCfg)
+		adminServer.SetEmbedderConfig(cfg.Embedder)
+		go adminServer.Start(ctx)
+	}
+
+	// homelab-agent threshold alerts become system triggers in the alert chat
+	if cfg.Agent.AlertAddr != "" && cfg.Alert.ChatID != 0 {
+		receiver, err := alerts.NewReceiver(cfg.Agent.AlertAddr, cfg.Agent.Token,
+			func(ctx context.Context, prompt string) (string, error) {
+				return sheldon.ProcessSystemTrigger(ctx, notifyBot.SessionID(cfg.Alert.ChatID), prompt)
+			},
+			func(message string) {
+				notifier.Send(ctx, notify.Message{Kind: notify.KindAlerts, Title: "Homelab alert", Text: message, Priority: notify.PriorityHigh})
+			},
+		)
+		if err != nil {
+			logger.Fatal("failed to create alert webhook", "error", err)
+		}
+		go receiver.Start(ctx)
+	} else if cfg.Agent.AlertAddr != "" {
+		logger.Warn("HOMELAB_ALERT_ADDR set without ALERT_CHAT_ID, homelab alerts disabled")
+	}
+
 	// show actual active model (runtime config overrides env var)
 	activeLLM := cfg.LLM.Provider
 	activeModel := cfg.LLM.Model
@@ -515,6 +1092,9 @@ func main() {
 		"memory", cfg.MemoryPath,
 	)
 
+	sheldon.Registry().SetDisabled(cfg.DisabledTools)
+	startHotReload(ctx, cfg, sheldon, runtimeCfg)
+
 	// Anonymous telemetry - disable with TELEMETRY_DISABLED=true
 	telemetry.Heartbeat("1.0.0", cfg.MemoryPath)
 
@@ -522,8 +1102,140 @@ func main() {
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	<-sigCh
 
-	logger.Info("shutting down")
+	// stop taking new messages and let in-flight agent loops and coder jobs finish
+	shutdownTimeout := 90 * time.Second
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			shutdownTimeout = d
+		}
+	}
+	logger.Info("shutting down, draining in-flight requests", "active", sheldon.InFlight(), "timeout", shutdownTimeout)
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	if err := sheldon.Drain(drainCtx); err != nil {
+		logger.Warn("drain timed out, cancelling remaining requests", "active", sheldon.InFlight())
+	}
+	drainCancel()
+
 	cancel()
+
+	// cancelled loops save the interrupted message to the conversation buffer on the way out
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+	sheldon.Drain(flushCtx)
+	tracer.Shutdown(flushCtx)
+
+	logger.Info("shutdown complete")
+}
+
+// validateConfig loads the config file and environment the way startup does
+// and reports every problem found
+func validateConfig() error {
+	path := config.ConfigFile()
+	settings, err := config.ReadFile(path)
+	switch {
+	case err == nil:
+		fmt.Printf("%s: %d settings\n", path, len(settings))
+	case errors.Is(err, os.ErrNotExist) && os.Getenv("SHELDON_CONFIG") == "":
+		fmt.Printf("%s: not found, using environment only\n", path)
+	default:
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config invalid:\n%w", err)
+	}
+
+	fmt.Printf("config ok: %s/%s, %s bot\n", cfg.LLM.Provider, cfg.LLM.Model, cfg.Bot.Provider)
+	return nil
+}
+
+// startHotReload applies edits to the essence directory, .env and the runtime
+// config without a restart. Settings only read at startup are logged instead.
+func startHotReload(ctx context.Context, cfg *config.Config, sheldon *agent.Agent, rc *config.RuntimeConfig) {
+	watcher, err := hotreload.New()
+	if err != nil {
+		logger.Warn("hot reload disabled", "error", err)
+		return
+	}
+
+	if err := watcher.WatchDir(cfg.EssencePath, func() { sheldon.ReloadPrompt() }); err != nil {
+		logger.Warn("not watching essence directory", "path", cfg.EssencePath, "error", err)
+	}
+
+	if err := watcher.WatchFile(rc.Path(), func() {
+		changed, err := rc.Reload()
+		if err != nil {
+			logger.Warn("failed to reload runtime config", "error", err)
+		} else if changed {
+			logger.Info("runtime config reloaded")
+		}
+	}); err != nil {
+		logger.Warn("not watching runtime config", "path", rc.Path(), "error", err)
+	}
+
+	env := hotreload.NewEnvFile(".env")
+	current := *cfg
+	if err := watcher.WatchFile(env.Path(), func() {
+		keys, err := env.Reload()
+		if err != nil || len(keys) == 0 {
+			if err != nil {
+				logger.Warn("failed to reload .env", "error", err)
+			}
+			return
+		}
+		logger.Info(".env reloaded", "changed", keys)
+
+		next, err := config.Load()
+		if err != nil {
+			logger.Warn("config invalid after .env change, keeping current settings", "error", err)
+			return
+		}
+		applyConfig(&current, next, sheldon)
+	}); err != nil {
+		logger.Warn("not watching .env", "error", err)
+	}
+
+	go watcher.Run(ctx)
+	logger.Info("hot reload enabled", "essence", cfg.EssencePath)
+}
+
+// applyConfig applies what can change while running. The LLM provider, model
+// and keys need nothing here, they are read from the environment per request.
+func applyConfig(current *config.Config, next *config.Config, sheldon *agent.Agent) {
+	if tracker := sheldon.Budget(); tracker != nil && next.Budget != current.Budget {
+		tracker.SetLimits(next.Budget.DailyLimit, next.Budget.WarnAt)
+		logger.Info("budget limits updated", "limit", next.Budget.DailyLimit, "warnAt", next.Budget.WarnAt)
+	}
+
+	if !slices.Equal(next.DisabledTools, current.DisabledTools) {
+		sheldon.Registry().SetDisabled(next.DisabledTools)
+		logger.Info("disabled tools updated", "tools", next.DisabledTools)
+	}
+
+	// compare everything else with the live parts blanked out
+	before, after := *current, *next
+	for _, c := range []*config.Config{&before, &after} {
+		c.Budget.DailyLimit, c.Budget.WarnAt = 0, 0
+		c.DisabledTools = nil
+		c.LLM.Provider, c.LLM.Model, c.LLM.APIKey = "", "", ""
+	}
+	var restart []string
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := range b.NumField() {
+		if !reflect.DeepEqual(b.Field(i).Interface(), a.Field(i).Interface()) {
+			restart = append(restart, b.Type().Field(i).Name)
+		}
+	}
+	if len(restart) > 0 {
+		logger.Warn("config changed that only applies after a restart", "sections", restart)
+	}
+
+	*current = *next
 }
 
 func getAPIKeyForProvider(provider string, cfg *config.Config) string {
@@ -534,6 +1246,8 @@ func getAPIKeyForProvider(provider string, cfg *config.Config) string {
 		return os.Getenv("OPENAI_API_KEY")
 	case "kimi":
 		return os.Getenv("KIMI_API_KEY")
+	case "azure":
+		return os.Getenv("AZURE_OPENAI_API_KEY")
 	case "ollama":
 		return "ollama"
 	default:
@@ -546,6 +1260,28 @@ func getAPIKeyForProvider(provider string, cfg *config.Config) string {
 	}
 }
 
+// providerAPIKeys lists the configured provider keys so the llm log can redact them
+func providerAPIKeys(cfg *config.Config) []string {
+	var keys []string
+	for _, provider := range llm.KnownProviders() {
+		if key := getAPIKeyForProvider(provider, cfg); key != "" && key != "ollama" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// getEndpointForProvider returns the base URL and API version for providers
+// that can't be reached at a fixed address
+func getEndpointForProvider(provider string) (string, string) {
+	switch provider {
+	case "azure":
+		return os.Getenv("AZURE_OPENAI_ENDPOINT"), os.Getenv("AZURE_OPENAI_API_VERSION")
+	default:
+		return "", ""
+	}
+}
+
 // getPublicIP returns the server's public IP address.
 // Falls back to local IP if public IP detection fails.
 func getPublicIP() string {
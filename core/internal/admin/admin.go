@@ -0,0 +1,206 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/agent"
+	"github.com/bowerhall/sheldon/internal/budget"
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/health"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// New creates an admin server on addr. Every endpoint requires the token.
+func New(addr, token string, a *agent.Agent) (*Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("admin API requires a token")
+	}
+
+	s := &Server{
+		token:   token,
+		agent:   a,
+		started: time.Now(),
+		ctx:     context.Background(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.authorized(s.handleHealth))
+	mux.HandleFunc("GET /sessions", s.authorized(s.handleSessions))
+	mux.HandleFunc("GET /queue", s.authorized(s.handleQueue))
+	mux.HandleFunc("GET /budget", s.authorized(s.handleBudget))
+	mux.HandleFunc("GET /tools", s.authorized(s.handleTools))
+	mux.HandleFunc("GET /config", s.authorized(s.handleConfig))
+	mux.HandleFunc("GET /memory/reindex", s.authorized(s.handleReindexStatus))
+	mux.HandleFunc("POST /memory/reindex", s.authorized(s.handleReindex))
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+
+	return s, nil
+}
+
+// AddChecker adds a component reported by /health
+func (s *Server) AddChecker(name string, c health.Checker) {
+	s.checkers = append(s.checkers, namedChecker{name: name, checker: c})
+}
+
+func (s *Server) SetRuntimeConfig(rc *config.RuntimeConfig) {
+	s.runtime = rc
+}
+
+// SetEmbedderConfig sets the configured embedder, used as defaults for reindexing
+func (s *Server) SetEmbedderConfig(cfg config.EmbedderConfig) {
+	s.embedder = cfg
+}
+
+// Start serves until ctx is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	s.ctx = ctx
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("admin API listening", "addr", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		logger.Error("admin API failed", "error", err)
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(shutdownCtx)
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			logger.Warn("rejecting unauthorized admin request", "path", r.URL.Path, "remote", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	resp := healthResponse{
+		Status: "ok",
+		Uptime: time.Since(s.started).Round(time.Second).String(),
+		Checks: make(map[string]string),
+	}
+
+	status := http.StatusOK
+	for _, c := range s.checkers {
+		if err := c.checker.HealthCheck(ctx); err != nil {
+			resp.Status = "unhealthy"
+			resp.Checks[c.name] = err.Error()
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[c.name] = "ok"
+	}
+
+	writeJSON(w, status, resp)
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.agent.Sessions().List())
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	resp := queueResponse{Sessions: []queueEntry{}}
+	for _, info := range s.agent.Sessions().List() {
+		if info.Queued == 0 {
+			continue
+		}
+		resp.Total += info.Queued
+		resp.Sessions = append(resp.Sessions, queueEntry{ID: info.ID, Queued: info.Queued})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleBudget(w http.ResponseWriter, r *http.Request) {
+	tracker := s.agent.Budget()
+	if tracker == nil {
+		http.Error(w, "budget tracking disabled", http.StatusNotFound)
+		return
+	}
+
+	var resp budgetResponse
+	resp.TokensUsed, resp.TokensLimit = tracker.Usage()
+
+	if store := tracker.Store(); store != nil {
+		resp.Today = spend(store.Today())
+		resp.Week = spend(store.ThisWeek())
+		resp.Month = spend(store.ThisMonth())
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func spend(summary *budget.Summary, err error) *spendReport {
+	if err != nil {
+		logger.Warn("admin: failed to load usage summary", "error", err)
+		return nil
+	}
+	return &spendReport{
+		Requests:     summary.TotalRequests,
+		InputTokens:  summary.TotalInputTokens,
+		OutputTokens: summary.TotalOutputTokens,
+		CostUSD:      summary.TotalCostUSD,
+	}
+}
+
+func (s *Server) handleTools(w http.ResponseWriter, r *http.Request) {
+	registered := s.agent.Registry().Tools()
+
+	result := make([]toolInfo, 0, len(registered))
+	for _, t := range registered {
+		result = append(result, toolInfo{Name: t.Name, Description: t.Description})
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	var resp configResponse
+	resp.ActiveProvider, resp.ActiveModel = s.agent.ActiveModel()
+
+	// runtime config only ever holds non-secret values
+	if s.runtime != nil {
+		resp.Values = s.runtime.All()
+		resp.Overrides = s.runtime.Overrides()
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("admin: failed to encode response", "error", err)
+	}
+}
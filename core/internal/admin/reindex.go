@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/embedder"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// handleReindex starts re-embedding memory with a new embedder in the background.
+// Progress is reported by GET /memory/reindex.
+func (s *Server) handleReindex(w http.ResponseWriter, r *http.Request) {
+	var req reindexRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Provider == "" {
+		req.Provider = s.embedder.Provider
+	}
+	if req.BaseURL == "" {
+		req.BaseURL = s.embedder.BaseURL
+	}
+
+	emb, err := embedder.New(embedder.Config{Provider: req.Provider, BaseURL: req.BaseURL, Model: req.Model})
+	if err != nil || emb == nil {
+		http.Error(w, "unknown embedder provider", http.StatusBadRequest)
+		return
+	}
+
+	job := &s.reindex
+	job.mu.Lock()
+	if job.running {
+		job.mu.Unlock()
+		http.Error(w, "a reindex is already running", http.StatusConflict)
+		return
+	}
+	job.running = true
+	job.target = req.Provider + "/" + req.Model
+	job.stage, job.done, job.total, job.err = "", 0, 0, ""
+	job.started, job.finished = time.Now(), time.Time{}
+	job.mu.Unlock()
+
+	go s.runReindex(s.agent.Memory(), emb)
+
+	writeJSON(w, http.StatusAccepted, job.status())
+}
+
+func (s *Server) handleReindexStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.reindex.status())
+}
+
+func (s *Server) runReindex(memory *sheldonmem.Store, emb sheldonmem.Embedder) {
+	job := &s.reindex
+	logger.Info("memory reindex started", "embedder", job.target)
+
+	err := memory.Reindex(s.ctx, emb, func(p sheldonmem.ReindexProgress) {
+		job.mu.Lock()
+		job.stage, job.done, job.total = p.Stage, p.Done, p.Total
+		job.mu.Unlock()
+	})
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.running = false
+	job.finished = time.Now()
+
+	if err != nil {
+		job.err = err.Error()
+		logger.Error("memory reindex failed", "embedder", job.target, "error", err)
+		return
+	}
+
+	// the new embedder only lasts until restart unless the env is updated too
+	logger.Info("memory reindex complete, update EMBEDDER_PROVIDER/EMBEDDER_MODEL before restarting",
+		"embedder", job.target, "duration", job.finished.Sub(job.started).Round(time.Second))
+}
+
+func (j *reindexJob) status() reindexStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := reindexStatus{
+		Running:  j.running,
+		Embedder: j.target,
+		Stage:    j.stage,
+		Done:     j.done,
+		Total:    j.total,
+		Error:    j.err,
+	}
+	if !j.started.IsZero() {
+		status.Started = j.started.Format(time.RFC3339)
+	}
+	if !j.finished.IsZero() {
+		status.Finished = j.finished.Format(time.RFC3339)
+	}
+	return status
+}
@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/agent"
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/health"
+)
+
+// Server is a token-protected HTTP API for observing a running instance.
+// Apart from memory reindexing every endpoint is read-only.
+type Server struct {
+	token    string
+	server   *http.Server
+	started  time.Time
+	agent    *agent.Agent
+	runtime  *config.RuntimeConfig
+	embedder config.EmbedderConfig
+	checkers []namedChecker
+
+	ctx     context.Context
+	reindex reindexJob
+}
+
+// reindexJob tracks the single background memory reindex
+type reindexJob struct {
+	mu       sync.Mutex
+	running  bool
+	target   string
+	stage    string
+	done     int
+	total    int
+	started  time.Time
+	finished time.Time
+	err      string
+}
+
+type namedChecker struct {
+	name    string
+	checker health.Checker
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Uptime string            `json:"uptime"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type queueResponse struct {
+	Total    int          `json:"total"`
+	Sessions []queueEntry `json:"sessions"`
+}
+
+type queueEntry struct {
+	ID     string `json:"id"`
+	Queued int    `json:"queued"`
+}
+
+type budgetResponse struct {
+	TokensUsed  int          `json:"tokens_used"`
+	TokensLimit int          `json:"tokens_limit"`
+	Today       *spendReport `json:"today,omitempty"`
+	Week        *spendReport `json:"week,omitempty"`
+	Month       *spendReport `json:"month,omitempty"`
+}
+
+type spendReport struct {
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+type toolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type configResponse struct {
+	ActiveProvider string            `json:"active_provider"`
+	ActiveModel    string            `json:"active_model"`
+	Values         map[string]string `json:"values"`
+	Overrides      map[string]string `json:"overrides"`
+}
+
+type reindexRequest struct {
+	Provider string `json:"provider"`
+	BaseURL  string `json:"base_url,omitempty"`
+	Model    string `json:"model"`
+}
+
+type reindexStatus struct {
+	Running  bool   `json:"running"`
+	Embedder string `json:"embedder,omitempty"`
+	Stage    string `json:"stage,omitempty"`
+	Done     int    `json:"done"`
+	Total    int    `json:"total"`
+	Started  string `json:"started,omitempty"`
+	Finished string `json:"finished,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
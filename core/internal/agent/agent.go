@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bowerhall/sheldon/internal/alerts"
@@ -27,21 +29,52 @@ var fallbackProviders = []string{"kimi", "claude", "openai"}
 const defaultMaxToolIterations = 20
 const maxToolFailures = 3
 const maxSameToolRepeats = 3 // detect spinning on same tool
+const maxParallelTools = 4   // concurrent tool calls from a single response
+const toolTimeout = 2 * time.Minute
+
+// slowToolDelay is how long a tool runs before the user is told it's still working
+const slowToolDelay = 5 * time.Second
+
+// longRunningTools are exempt from toolTimeout; they manage their own deadlines
+var longRunningTools = map[string]bool{
+	"write_code":       true,
+	"continue_task":    true,
+	"build_image":      true,
+	"deploy_app":       true,
+	"pull_model":       true,
+	"force_extraction": true,
+	"fetch_url":        true, // large files stream for up to fetchHTTPClient's timeout
+}
 
 // maxToolIterations is configurable via AGENT_MAX_ITERATIONS env var
 var maxToolIterations = defaultMaxToolIterations
 
+// queueBatchWindow is how long messages queued behind a busy session wait
+// for more to arrive before they are answered together (AGENT_BATCH_WINDOW)
+var queueBatchWindow = 2 * time.Second
+
+// contextWindowTokens overrides the model's context window (AGENT_CONTEXT_TOKENS), 0 = model default
+var contextWindowTokens = 0
+
 func init() {
 	if v := os.Getenv("AGENT_MAX_ITERATIONS"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			maxToolIterations = n
 		}
 	}
+	if v := os.Getenv("AGENT_BATCH_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			queueBatchWindow = d
+		}
+	}
+	if v := os.Getenv("AGENT_CONTEXT_TOKENS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			contextWindowTokens = n
+		}
+	}
 }
 
 func New(model llm.LLM, memory *sheldonmem.Store, essencePath, timezone string) *Agent {
-	systemPrompt := loadSystemPrompt(essencePath)
-
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
 		logger.Warn("invalid timezone, using UTC", "timezone", timezone, "error", err)
@@ -58,8 +91,11 @@ func New(model llm.LLM, memory *sheldonmem.Store, essencePath, timezone string)
 		memory:       memory,
 		sessions:     session.NewStore(),
 		tools:        registry,
-		systemPrompt: systemPrompt,
+		essencePath:  essencePath,
+		promptLayers: loadPromptLayers(essencePath),
+		personas:     loadPersonas(essencePath),
 		timezone:     loc,
+		health:       llm.NewHealthTracker(),
 	}
 }
 
@@ -106,7 +142,7 @@ func (a *Agent) refreshLLMIfNeeded() error {
 		return nil
 	}
 
-	newLLM, err := a.llmFactory()
+	newLLM, err := a.llmFactory(a.runtimeConfig.Get("llm_provider"), a.runtimeConfig.Get("llm_model"))
 	if err != nil {
 		logger.Error("failed to create new LLM instance", "error", err)
 		return err
@@ -131,6 +167,96 @@ func (a *Agent) setLLM(model llm.LLM) {
 	a.llm = model
 }
 
+// llmFor returns the model a session switched to with switch_model, or the
+// global one when it has no override or the override can't be created
+func (a *Agent) llmFor(sessionID string) llm.LLM {
+	if a.llmFactory == nil || a.runtimeConfig == nil || sessionID == "" {
+		return a.getLLM()
+	}
+
+	override, ok := a.runtimeConfig.SessionModel(sessionID)
+	if !ok {
+		a.mu.Lock()
+		delete(a.sessionLLMs, sessionID)
+		a.mu.Unlock()
+		return a.getLLM()
+	}
+
+	key := override.Provider + ":" + override.Model
+	a.mu.RLock()
+	cached := a.sessionLLMs[sessionID]
+	a.mu.RUnlock()
+	if cached != nil && cached.key == key {
+		return cached.llm
+	}
+
+	model, err := a.llmFactory(override.Provider, override.Model)
+	if err != nil {
+		logger.Warn("failed to create session model, using global model", "session", sessionID, "config", key, "error", err)
+		return a.getLLM()
+	}
+
+	a.mu.Lock()
+	if a.sessionLLMs == nil {
+		a.sessionLLMs = make(map[string]*sessionLLM)
+	}
+	a.sessionLLMs[sessionID] = &sessionLLM{key: key, llm: model}
+	a.mu.Unlock()
+	logger.Info("session model active", "session", sessionID, "config", key)
+
+	return model
+}
+
+// swapLLM swaps in a fallback provider or restores the preferred one. A
+// session with its own model switches on its own so other chats keep the
+// global model.
+func (a *Agent) swapLLM(sessionID string, model llm.LLM) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.sessionLLMs[sessionID]; ok {
+		cached.llm = model
+		return
+	}
+	a.llm = model
+}
+
+// preferredLLM switches back from a fallback to the configured model once its
+// provider's cooldown has passed. If it fails again, the loop falls back again
+// and the cooldown grows.
+func (a *Agent) preferredLLM(sessionID string, current llm.LLM) llm.LLM {
+	if a.llmFactory == nil || a.runtimeConfig == nil {
+		return current
+	}
+
+	provider, model := a.runtimeConfig.Get("llm_provider"), a.runtimeConfig.Get("llm_model")
+	if override, ok := a.runtimeConfig.SessionModel(sessionID); ok {
+		provider, model = override.Provider, override.Model
+	}
+	// a fallback is always another provider
+	if provider == "" || current.Provider() == provider || !a.health.Available(provider) {
+		return current
+	}
+
+	preferred, err := a.llmFactory(provider, model)
+	if err != nil {
+		logger.Warn("failed to recreate preferred LLM", "provider", provider, "model", model, "error", err)
+		return current
+	}
+
+	a.swapLLM(sessionID, preferred)
+	logger.Info("retrying preferred provider", "from", current.Provider(), "to", provider)
+
+	return preferred
+}
+
+// Transcribe converts a voice message to text so bots can feed it into Process
+func (a *Agent) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	if a.transcriber == nil {
+		return "", fmt.Errorf("voice transcription not configured (set SPEECH_PROVIDER)")
+	}
+	return a.transcriber.Transcribe(ctx, audio, mimeType)
+}
+
 func (a *Agent) Registry() *tools.Registry {
 	return a.tools
 }
@@ -139,19 +265,41 @@ func (a *Agent) Memory() *sheldonmem.Store {
 	return a.memory
 }
 
-func loadSystemPrompt(essencePath string) string {
-	soulPath := filepath.Join(essencePath, "SOUL.md")
-	soul, err := os.ReadFile(soulPath)
-	if err != nil {
-		return ""
-	}
+func (a *Agent) Sessions() *session.Store {
+	return a.sessions
+}
+
+func (a *Agent) Budget() *budget.Tracker {
+	return a.budget
+}
 
-	return string(soul)
+// Health returns the provider failure tracker used for failover
+func (a *Agent) Health() *llm.HealthTracker {
+	return a.health
 }
 
-// buildDynamicPrompt adds dynamic context (like active notes) to the system prompt
-func (a *Agent) buildDynamicPrompt() string {
-	prompt := a.systemPrompt
+// ActiveModel returns the provider and model currently answering messages,
+// which differs from config while a fallback provider is in use
+func (a *Agent) ActiveModel() (provider, model string) {
+	current := a.getLLM()
+	return current.Provider(), current.Model()
+}
+
+// buildDynamicPrompt adds the user's standing instructions and dynamic
+// context (like active notes) to the essence layers
+func (a *Agent) buildDynamicPrompt(ctx context.Context) string {
+	prompt := a.staticPrompt(tools.SessionIDFromContext(ctx))
+
+	if addendum := a.userAddendum(ctx); addendum != "" {
+		prompt += "\n\n" + addendum
+	}
+
+	if language := languagePrompt(tools.LanguageFromContext(ctx)); language != "" {
+		prompt += "\n\n" + language
+	}
+	if units := unitsPrompt(tools.PrefsFromContext(ctx)); units != "" {
+		prompt += "\n\n" + units
+	}
 
 	// Add active notes with age to context
 	notes, err := a.memory.ListNotesWithAge()
@@ -210,17 +358,32 @@ func (a *Agent) ProcessWithMedia(ctx context.Context, sessionID string, userMess
 
 func (a *Agent) ProcessWithOptions(ctx context.Context, sessionID string, userMessage string, opts ProcessOptions) (string, error) {
 	media := opts.Media
+	incoming := userMessage
 	logger.Debug("message received", "session", sessionID, "media", len(media))
 
+	if !a.begin() {
+		logger.Info("rejecting message during shutdown", "session", sessionID)
+		return shutdownReply, nil
+	}
+	defer a.end()
+
+	if a.trackSession != nil {
+		a.trackSession(sessionID)
+	}
+	if !opts.Group && opts.UserID != 0 {
+		a.rememberSessionUser(sessionID, opts.UserID)
+	}
+
 	if err := a.refreshLLMIfNeeded(); err != nil {
 		logger.Warn("failed to refresh LLM, using existing instance", "error", err)
 	}
 
 	// Check model capabilities for media
-	caps := a.getLLM().Capabilities()
+	caps := a.llmFor(sessionID).Capabilities()
 	hasImage := false
 	hasVideo := false
 	hasPDF := false
+	hasDocument := false
 	for _, m := range media {
 		if m.Type == llm.MediaTypeImage {
 			hasImage = true
@@ -231,6 +394,9 @@ func (a *Agent) ProcessWithOptions(ctx context.Context, sessionID string, userMe
 		if m.Type == llm.MediaTypePDF {
 			hasPDF = true
 		}
+		if m.Type == llm.MediaTypeDocument {
+			hasDocument = true
+		}
 	}
 
 	// Keep original media for tools, but filter for LLM based on capabilities
@@ -247,9 +413,16 @@ func (a *Agent) ProcessWithOptions(ctx context.Context, sessionID string, userMe
 		limitations = append(limitations, "PDF")
 	}
 
-	if len(limitations) > 0 {
-		note := fmt.Sprintf("[%s received but current model doesn't support %s analysis. I can still save it for you.]",
-			strings.Join(limitations, " and "), strings.Join(limitations, "/"))
+	if len(limitations) > 0 || hasDocument {
+		var note string
+		if len(limitations) > 0 {
+			note = fmt.Sprintf("[%s received but current model doesn't support %s analysis. I can still save it for you.]",
+				strings.Join(limitations, " and "), strings.Join(limitations, "/"))
+		}
+		// documents never go to the model as-is, their text is read with a tool
+		if hasPDF && !caps.PDFInput || hasDocument {
+			note = strings.TrimSpace(note + " [Use read_document to read the attached document.]")
+		}
 		if userMessage == "" {
 			userMessage = note
 		} else {
@@ -271,13 +444,27 @@ func (a *Agent) ProcessWithOptions(ctx context.Context, sessionID string, userMe
 		}
 	}
 
-	sess := a.sessions.Get(sessionID)
-	chatID := a.parseChatID(sessionID)
+	var speakerEntity string
+	if opts.Group {
+		if opts.UserID != 0 {
+			speakerEntity = a.getOrCreateSpeakerEntity(sessionID, opts.UserID, opts.Speaker)
+		}
+		userMessage = attributeSpeaker(opts.Speaker, userMessage)
+	}
 
-	// prevent concurrent processing of same session
-	if !sess.TryAcquire() {
-		logger.Debug("session busy, queueing message", "session", sessionID)
-		sess.Queue(userMessage, media, opts.Trusted)
+	// prevent concurrent processing of same session. If it's busy the raw
+	// message is queued, so it is attributed and annotated once, when it's processed.
+	sess, acquired := a.sessions.Acquire(sessionID, session.QueuedMessage{
+		Content: incoming,
+		Media:   media,
+		Trusted: opts.Trusted,
+		UserID:  opts.UserID,
+		Group:   opts.Group,
+		Speaker: opts.Speaker,
+	})
+	chatID := a.parseChatID(sessionID)
+	if !acquired {
+		logger.Debug("session busy, queued message", "session", sessionID)
 		return "", nil // no response - typing indicator shows we're busy
 	}
 	defer func() {
@@ -286,8 +473,30 @@ func (a *Agent) ProcessWithOptions(ctx context.Context, sessionID string, userMe
 		a.processQueue(ctx, sessionID, sess, chatID)
 	}()
 
+	// uploads are indexed and archived once, by the turn that answers them
+	if len(media) > 0 {
+		ownerID := a.getOrCreateUserEntity(sessionID)
+		if speakerEntity != "" {
+			ownerID = a.getOrCreateUserEntityNamed(speakerEntity)
+		}
+		text := userMessage
+		if names := a.indexUploads(ownerID, media); len(names) > 0 {
+			userMessage = strings.TrimSpace(userMessage + fmt.Sprintf("\n\n[Added to the user's document library: %s. Use search_documents to look things up in it later.]", strings.Join(names, ", ")))
+		}
+		if paths := a.archiveUploads(sessionID, ownerID, text, media); len(paths) > 0 {
+			userMessage = strings.TrimSpace(userMessage + fmt.Sprintf("\n\n[Archived to the user's media library: %s. Use find_media to look it up later.]", strings.Join(paths, ", ")))
+		}
+	}
+
 	// load recent conversation history for continuity
 	if len(sess.Messages()) == 0 && a.convo != nil {
+		// history compacted in earlier sessions comes first
+		if summary, err := a.convo.GetSummary(sessionID); err != nil {
+			logger.Warn("failed to load conversation summary", "error", err)
+		} else if summary != "" {
+			sess.AddMessage("system", summaryPrefix+summary, nil, "")
+		}
+
 		recent, err := a.convo.GetRecent(sessionID)
 		if err != nil {
 			logger.Warn("failed to load recent messages", "error", err)
@@ -320,44 +529,89 @@ func (a *Agent) ProcessWithOptions(ctx context.Context, sessionID string, userMe
 		logger.Warn("conversation store not configured")
 	}
 
-	if len(sess.Messages()) == 0 && a.isNewUser(sessionID) {
+	if len(sess.Messages()) == 0 && !opts.Group && a.isNewUser(sessionID) {
 		logger.Info("new user detected, triggering interview", "session", sessionID)
 		sess.AddMessage("system", "[This is a new user with no stored memory. Start with a warm welcome and begin the setup interview to get to know them. Follow the interview guide in your instructions.]", nil, "")
 	}
 
+	turnStart := len(sess.Messages())
 	sess.AddMessageWithMedia("user", userMessage, mediaForLLM, nil, "")
 
 	// check for skill command (e.g., /apartment-hunter)
+	ctx = tools.WithToolScope(ctx)
 	if skill := a.detectSkillCommand(userMessage); skill != "" {
 		skillContent := a.loadSkill(skill)
 		if skillContent != "" {
-			sess.AddMessage("system", fmt.Sprintf("[Skill activated: %s]\n\n%s", skill, skillContent), nil, "")
+			// key=value pairs after the command are the skill's arguments
+			_, rest, _ := strings.Cut(strings.TrimSpace(userMessage), " ")
+			skillArgs, _ := tools.SplitSkillArgs(rest)
+			sess.AddMessage("system", fmt.Sprintf("[Skill activated: %s]\n\n%s", skill, tools.ActivateSkill(skill, skillContent, skillArgs)), nil, "")
 			logger.Debug("skill activated", "skill", skill)
+
+			// a skill that declares its tools gets only those for this turn
+			if allowed := tools.SkillToolAllowlist(skillContent); allowed != nil {
+				tools.RestrictTools(ctx, allowed)
+				logger.Info("tools restricted by skill", "skill", skill, "tools", allowed)
+			}
 		}
+	} else if hint := coreCommandHint(userMessage); hint != "" {
+		sess.AddMessage("system", hint, nil, "")
 	}
 
 	// add session info to context for tools
 	ctx = context.WithValue(ctx, tools.ChatIDKey, chatID)
 	ctx = context.WithValue(ctx, tools.SessionIDKey, sessionID)
+	a.noteLanguage(ctx, sessionID, userMessage)
+	if lang := a.sessionLanguage(sessionID); lang != "" {
+		ctx = context.WithValue(ctx, tools.LanguageKey, lang)
+	}
+	ctx = context.WithValue(ctx, tools.TimezoneKey, a.Timezone(sessionID))
+	ctx = context.WithValue(ctx, tools.PrefsKey, a.sessionPrefs(sessionID))
 	if opts.UserID != 0 {
 		ctx = context.WithValue(ctx, tools.UserIDKey, opts.UserID)
 	}
+	if speakerEntity != "" {
+		ctx = context.WithValue(ctx, tools.UserEntityKey, speakerEntity)
+	}
 	if len(media) > 0 {
 		ctx = context.WithValue(ctx, tools.MediaKey, media)
 	}
+	if opts.OnProgress != nil {
+		ctx = context.WithValue(ctx, tools.ProgressKey, opts.OnProgress)
+	}
 	// SafeMode excludes sensitive facts - enabled when not trusted
 	if !opts.Trusted {
 		ctx = context.WithValue(ctx, tools.SafeModeKey, true)
 	}
 
-	response, err := a.runAgentLoop(ctx, sess)
+	response, err := a.runAgentLoop(ctx, sess, opts.OnStream)
 	if err != nil {
 		logger.Error("agent loop failed", "error", err)
+
+		// cut off by shutdown: keep the user's message so the conversation
+		// picks up where it left off after the restart
+		if a.isDraining() && a.convo != nil {
+			if _, saveErr := a.convo.Add(sessionID, "user", userMessage); saveErr != nil {
+				logger.Warn("failed to save interrupted message", "error", saveErr)
+			}
+			if _, saveErr := a.convo.Add(sessionID, "assistant", "[Interrupted by a restart before I could finish.]"); saveErr != nil {
+				logger.Warn("failed to save interrupted message", "error", saveErr)
+			}
+		}
 		return "", err
 	}
 
-	// save to recent conversation buffer (FIFO for LLM context)
-	if a.convo != nil {
+	// clear_context ran: drop this chat's history once the turn is done so
+	// the next message starts fresh
+	if sess.ResetIfMarked() {
+		if a.convo != nil {
+			if err := a.convo.Clear(sessionID); err != nil {
+				logger.Warn("failed to clear conversation buffer", "error", err)
+			}
+		}
+		logger.Info("conversation context cleared", "session", sessionID)
+	} else if a.convo != nil {
+		// save to recent conversation buffer (FIFO for LLM context)
 		if _, err := a.convo.Add(sessionID, "user", userMessage); err != nil {
 			logger.Warn("failed to save user message to conversation buffer", "error", err)
 		}
@@ -374,34 +628,95 @@ func (a *Agent) ProcessWithOptions(ctx context.Context, sessionID string, userMe
 		logger.Warn("failed to save assistant message to daily storage", "error", err)
 	}
 
+	if messages := sess.Messages(); turnStart < len(messages) {
+		a.evaluateTurn(sessionID, userMessage, response, messages[turnStart:])
+	}
+
 	return response, nil
 }
 
-// processQueue handles any messages that were queued while we were busy
+// processQueue answers messages that were queued while we were busy. After a
+// short window for stragglers, each burst from one sender is combined into one
+// turn so it gets one answer instead of several contradicting ones.
 func (a *Agent) processQueue(ctx context.Context, sessionID string, sess *session.Session, chatID int64) {
-	msg := sess.Dequeue()
-	if msg == nil {
+	if sess.QueueLen() == 0 {
 		return
 	}
 
-	logger.Info("processing queued message", "session", sessionID, "remaining", sess.QueueLen())
-
 	// process in background so we don't block
 	go func() {
-		response, err := a.ProcessWithOptions(ctx, sessionID, msg.Content, ProcessOptions{
-			Media:   msg.Media,
-			Trusted: msg.Trusted,
-		})
-		if err != nil {
-			logger.Error("failed to process queued message", "error", err)
+		select {
+		case <-ctx.Done():
 			return
+		case <-time.After(queueBatchWindow):
 		}
-		if response != "" && a.notify != nil {
-			a.notify(chatID, response)
+
+		queued := sess.DequeueAll()
+		if len(queued) == 0 {
+			return
+		}
+		batches := combineQueued(queued)
+		logger.Info("processing queued messages", "session", sessionID, "messages", len(queued), "turns", len(batches))
+
+		for _, msg := range batches {
+			response, err := a.ProcessWithOptions(ctx, sessionID, msg.Content, ProcessOptions{
+				Media:   msg.Media,
+				Trusted: msg.Trusted,
+				UserID:  msg.UserID,
+				Group:   msg.Group,
+				Speaker: msg.Speaker,
+			})
+			if err != nil {
+				logger.Error("failed to process queued message", "error", err)
+				return
+			}
+			if response != "" && a.notify != nil {
+				a.notify(chatID, response)
+			}
 		}
 	}()
 }
 
+// combineQueued merges runs of consecutive messages from the same sender, so
+// in a group each member's messages stay attributed to them. A merged message
+// is only trusted if every part was.
+func combineQueued(queued []session.QueuedMessage) []session.QueuedMessage {
+	var batches []session.QueuedMessage
+	for start := 0; start < len(queued); {
+		end := start + 1
+		for end < len(queued) && sameSender(queued[start], queued[end]) {
+			end++
+		}
+		batches = append(batches, combineRun(queued[start:end]))
+		start = end
+	}
+	return batches
+}
+
+func sameSender(a, b session.QueuedMessage) bool {
+	return a.UserID == b.UserID && a.Group == b.Group && a.Speaker == b.Speaker
+}
+
+// combineRun merges messages from one sender into one
+func combineRun(run []session.QueuedMessage) session.QueuedMessage {
+	if len(run) == 1 {
+		return run[0]
+	}
+
+	first := run[0]
+	combined := session.QueuedMessage{Trusted: true, UserID: first.UserID, Group: first.Group, Speaker: first.Speaker}
+	parts := make([]string, 0, len(run))
+	for _, m := range run {
+		if m.Content != "" {
+			parts = append(parts, m.Content)
+		}
+		combined.Media = append(combined.Media, m.Media...)
+		combined.Trusted = combined.Trusted && m.Trusted
+	}
+	combined.Content = fmt.Sprintf("[%d messages sent in quick succession, answer them together]\n\n%s", len(run), strings.Join(parts, "\n\n"))
+	return combined
+}
+
 func (a *Agent) parseChatID(sessionID string) int64 {
 	// format: "telegram:123456" or "discord:123456"
 	parts := strings.Split(sessionID, ":")
@@ -434,7 +749,7 @@ func (a *Agent) detectSkillCommand(message string) string {
 		return ""
 	}
 
-	cmd := strings.TrimPrefix(parts[0], "/")
+	cmd := commandName(parts[0])
 	if cmd == "" {
 		return ""
 	}
@@ -444,9 +759,12 @@ func (a *Agent) detectSkillCommand(message string) string {
 		return ""
 	}
 
-	skillPath := filepath.Join(a.skillsDir, strings.ToUpper(cmd)+".md")
-	if _, err := os.Stat(skillPath); err == nil {
-		return cmd
+	// Telegram commands can't contain hyphens, so /apartment_hunter is /apartment-hunter
+	for _, name := range []string{cmd, strings.ReplaceAll(cmd, "_", "-")} {
+		skillPath := filepath.Join(a.skillsDir, strings.ToUpper(name)+".md")
+		if _, err := os.Stat(skillPath); err == nil {
+			return name
+		}
 	}
 
 	return ""
@@ -475,10 +793,27 @@ var browserTools = map[string]bool{
 	"browse_click": true,
 	"browse_fill":  true,
 	"search_web":   true,
+	"watch_page":   true,
+}
+
+// emailTools also trigger isolated mode: mail is written by whoever sent it
+var emailTools = map[string]bool{
+	"read_inbox":      true,
+	"summarize_email": true,
 }
 
-func (a *Agent) runAgentLoop(ctx context.Context, sess *session.Session) (string, error) {
-	availableTools := a.tools.Tools()
+func (a *Agent) runAgentLoop(ctx context.Context, sess *session.Session, onStream llm.StreamFunc) (reply string, err error) {
+	sessionID := tools.SessionIDFromContext(ctx)
+	ctx, span := a.tracer.Start(ctx, "agent.run", "session.id", sessionID, "streaming", onStream != nil)
+	iterations := 0
+	defer func() {
+		span.SetAttributes("iterations", iterations)
+		span.RecordError(err)
+		span.End()
+	}()
+
+	ctx = tools.WithToolScope(ctx)
+	availableTools := filterAllowedTools(ctx, a.tools.Tools())
 	toolFailures := make(map[string]int)     // track consecutive failures per tool
 	failedProviders := make(map[string]bool) // track providers that failed this request
 	isolatedMode := false                    // restrict tools after browse/code to prevent prompt injection
@@ -486,24 +821,58 @@ func (a *Agent) runAgentLoop(ctx context.Context, sess *session.Session) (string
 	sameToolCount := 0                       // count consecutive calls to same tool
 
 	for i := range maxToolIterations {
-		// filter tools based on mode
-		loopTools := availableTools
+		iterations = i + 1
+
+		// filter tools based on mode; an activated skill may have narrowed them
+		loopTools := filterAllowedTools(ctx, availableTools)
 		if isolatedMode {
-			loopTools = filterIsolatedTools(availableTools)
+			loopTools = filterIsolatedTools(loopTools)
 		}
 
 		// get current LLM (may change during fallback)
-		currentLLM := a.getLLM()
+		currentLLM := a.llmFor(sessionID)
+		if !failedProviders[currentLLM.Provider()] {
+			currentLLM = a.preferredLLM(sessionID, currentLLM)
+		}
 
 		logger.Debug("agent loop iteration", "iteration", i, "messages", len(sess.Messages()), "isolatedMode", isolatedMode)
 
-		resp, err := currentLLM.ChatWithTools(ctx, a.buildDynamicPrompt(), sess.Messages(), loopTools)
+		systemPrompt := a.buildDynamicPrompt(ctx)
+		a.fitContext(ctx, sess, currentLLM, systemPrompt, loopTools)
+
+		llmCtx, llmSpan := a.tracer.Start(ctx, "llm.chat",
+			"llm.provider", currentLLM.Provider(),
+			"llm.model", currentLLM.Model(),
+			"iteration", i,
+			"messages", len(sess.Messages()),
+			"tools", len(loopTools),
+		)
+		var resp *llm.ChatResponse
+		var err error
+		if onStream != nil {
+			resp, err = currentLLM.ChatWithToolsStream(llmCtx, systemPrompt, sess.Messages(), loopTools, onStream)
+		} else {
+			resp, err = currentLLM.ChatWithTools(llmCtx, systemPrompt, sess.Messages(), loopTools)
+		}
+		if err == nil {
+			llmSpan.SetAttributes("tool_calls", len(resp.ToolCalls), "stop_reason", resp.StopReason)
+			if resp.Usage != nil {
+				llmSpan.SetAttributes(
+					"llm.input_tokens", resp.Usage.PromptTokens,
+					"llm.output_tokens", resp.Usage.CompletionTokens,
+					"llm.cache_read_tokens", resp.Usage.CacheReadTokens,
+				)
+			}
+		}
+		llmSpan.RecordError(err)
+		llmSpan.End()
 		if err != nil {
 			// try fallback provider if quota exhausted
 			if shouldFallback(err) {
 				currentProvider := currentLLM.Provider()
 				failedProviders[currentProvider] = true
-				logger.Warn("provider unavailable, trying fallback", "provider", currentProvider, "error", err, "failedProviders", failedProviders)
+				cooldown := a.health.RecordFailure(currentProvider, err)
+				logger.Warn("provider unavailable, trying fallback", "provider", currentProvider, "error", err, "cooldown", cooldown, "failedProviders", failedProviders)
 
 				newLLM, newProvider, fallbackErr := a.tryFallbackProvider(ctx, failedProviders)
 				if fallbackErr != nil {
@@ -516,7 +885,7 @@ func (a *Agent) runAgentLoop(ctx context.Context, sess *session.Session) (string
 				}
 
 				// switch to fallback cloud provider
-				a.setLLM(newLLM)
+				a.swapLLM(sessionID, newLLM)
 				logger.Info("switched to fallback provider", "from", currentProvider, "to", newProvider)
 				continue // retry with new provider
 			}
@@ -527,9 +896,20 @@ func (a *Agent) runAgentLoop(ctx context.Context, sess *session.Session) (string
 			return "", err
 		}
 
+		if a.health.RecordSuccess(currentLLM.Provider()) {
+			logger.Info("provider recovered", "provider", currentLLM.Provider())
+		}
+
 		if resp.Usage != nil && a.budget != nil {
-			logger.Info("recording usage", "provider", currentLLM.Provider(), "model", currentLLM.Model(), "input", resp.Usage.PromptTokens, "output", resp.Usage.CompletionTokens)
-			if !a.budget.Record(currentLLM.Provider(), currentLLM.Model(), resp.Usage.PromptTokens, resp.Usage.CompletionTokens) {
+			tokens := budget.Tokens{
+				Input:      resp.Usage.PromptTokens,
+				Output:     resp.Usage.CompletionTokens,
+				CacheWrite: resp.Usage.CacheWriteTokens,
+				CacheRead:  resp.Usage.CacheReadTokens,
+			}
+			cost := budget.Cost(currentLLM.Provider(), currentLLM.Model(), tokens)
+			logger.Info("recording usage", "provider", currentLLM.Provider(), "model", currentLLM.Model(), "input", tokens.Input, "output", tokens.Output, "cacheWrite", tokens.CacheWrite, "cacheRead", tokens.CacheRead, "costUSD", fmt.Sprintf("%.4f", cost))
+			if !a.budget.RecordTokens(currentLLM.Provider(), currentLLM.Model(), tokens) {
 				return "I've reached my daily API limit. Please try again tomorrow!", nil
 			}
 		} else {
@@ -545,55 +925,38 @@ func (a *Agent) runAgentLoop(ctx context.Context, sess *session.Session) (string
 		logger.Info("llm requested tools", "count", len(resp.ToolCalls))
 		sess.AddMessage("assistant", resp.Content, resp.ToolCalls, "")
 
-		for _, tc := range resp.ToolCalls {
-			logger.Info("executing tool", "name", tc.Name, "isolatedMode", isolatedMode)
-
-			// detect spinning - same tool called repeatedly without progress
+		// spinning detection runs in call order before anything executes
+		calls := resp.ToolCalls
+		spinning := -1
+		for idx, tc := range calls {
 			if tc.Name == lastTool {
 				sameToolCount++
 				if sameToolCount >= maxSameToolRepeats {
-					logger.Warn("spinning detected", "tool", tc.Name, "count", sameToolCount)
-					sess.AddMessage("tool", fmt.Sprintf("[SPINNING] Called %s %d times in a row without progress. Stopping.", tc.Name, sameToolCount), nil, tc.ID)
-					return "I got stuck in a loop and had to stop. Let me try a different approach - what would you like me to do?", nil
+					spinning = idx
+					calls = calls[:idx]
+					break
 				}
 			} else {
 				lastTool = tc.Name
 				sameToolCount = 1
 			}
+		}
 
-			var result string
-			var err error
-
-			// check if tool requires approval
-			if tools.RequiresApproval(tc.Name) && a.approvals != nil && a.approvalSender != nil {
-				chatID := tools.ChatIDFromContext(ctx)
-				userID := tools.UserIDFromContext(ctx)
-
-				desc := a.describeToolCall(tc.Name, tc.Arguments)
-				approvalID := a.approvals.Start(chatID, userID, tc.Name, tc.Arguments, desc)
-
-				sendErr := a.approvalSender(chatID, desc, approvalID)
-				if sendErr != nil {
-					a.approvals.Cancel(approvalID)
-					result = fmt.Sprintf("Failed to request approval: %s", sendErr.Error())
-				} else {
-					approved, approvalErr := a.approvals.Wait(ctx, approvalID)
-					if approvalErr != nil {
-						result = fmt.Sprintf("Approval request failed: %s", approvalErr.Error())
-					} else if !approved {
-						result = fmt.Sprintf("User denied %s (approval %s)", tc.Name, approvalID)
-						logger.Info("tool denied by user", "tool", tc.Name, "approvalID", approvalID)
-					} else {
-						logger.Info("tool approved by user", "tool", tc.Name, "approvalID", approvalID)
-						result, err = a.tools.Execute(ctx, tc.Name, tc.Arguments)
-					}
-				}
-			} else {
-				result, err = a.tools.Execute(ctx, tc.Name, tc.Arguments)
-			}
+		toolsCtx, toolsSpan := a.tracer.Start(ctx, "agent.tools", "iteration", i, "count", len(calls))
+		// tools can hand images to the model (e.g. PDF pages) when it can see them
+		var attachments *tools.Attachments
+		if currentLLM.Capabilities().Vision {
+			toolsCtx, attachments = tools.WithAttachments(toolsCtx)
+		}
+		results := a.executeTools(toolsCtx, calls)
+		toolsSpan.End()
+
+		// apply results in call order so the session matches the LLM's tool_use sequence
+		for idx, tc := range calls {
+			result, err := results[idx].output, results[idx].err
 
-			// enter isolated mode after browser tools to prevent prompt injection
-			if browserTools[tc.Name] {
+			// enter isolated mode after browser or email tools to prevent prompt injection
+			if browserTools[tc.Name] || emailTools[tc.Name] {
 				isolatedMode = true
 				logger.Info("entered isolated mode", "trigger", tc.Name)
 			}
@@ -619,25 +982,170 @@ func (a *Agent) runAgentLoop(ctx context.Context, sess *session.Session) (string
 			logger.Debug("tool result", "name", tc.Name, "chars", len(result))
 			sess.AddMessage("tool", result, nil, tc.ID)
 		}
+
+		// detect spinning - same tool called repeatedly without progress
+		if spinning >= 0 {
+			tc := resp.ToolCalls[spinning]
+			logger.Warn("spinning detected", "tool", tc.Name, "count", sameToolCount)
+			sess.AddMessage("tool", fmt.Sprintf("[SPINNING] Called %s %d times in a row without progress. Stopping.", tc.Name, sameToolCount), nil, tc.ID)
+			return "I got stuck in a loop and had to stop. Let me try a different approach - what would you like me to do?", nil
+		}
+
+		// attached images follow the tool results as a user turn, since tool results are text only
+		if attachments != nil {
+			if media := attachments.Media(); len(media) > 0 {
+				logger.Debug("tool attachments", "count", len(media))
+				sess.AddMessageWithMedia("user", fmt.Sprintf("[%d image(s) attached by the tools above]", len(media)), media, nil, "")
+			}
+		}
 	}
 
 	logger.Warn("agent loop hit max iterations", "max", maxToolIterations)
 	return "I apologize, but I'm having trouble completing this request. Please try again.", nil
 }
 
+// executeTools runs a response's tool calls concurrently on a bounded pool.
+// Browser tools drive one shared page, so they run one after another in call order.
+// Results are returned in call order regardless of completion order.
+func (a *Agent) executeTools(ctx context.Context, calls []llm.ToolCall) []toolResult {
+	results := make([]toolResult, len(calls))
+	sem := make(chan struct{}, maxParallelTools)
+	var wg sync.WaitGroup
+
+	run := func(idx int) {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+
+		tc := calls[idx]
+		logger.Info("executing tool", "name", tc.Name)
+		output, err := a.executeTool(ctx, tc)
+		results[idx] = toolResult{output: output, err: err}
+	}
+
+	var sequential []int
+	for idx, tc := range calls {
+		if browserTools[tc.Name] {
+			sequential = append(sequential, idx)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(idx)
+		}()
+	}
+
+	if len(sequential) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, idx := range sequential {
+				run(idx)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// executeTool runs a single tool call, requesting user approval first if the tool requires it
+func (a *Agent) executeTool(ctx context.Context, tc llm.ToolCall) (result string, err error) {
+	ctx, span := a.tracer.Start(ctx, "tool."+tc.Name, "tool.name", tc.Name)
+	defer func() {
+		span.SetAttributes("result_chars", len(result))
+		span.RecordError(err)
+		span.End()
+	}()
+
+	// defaults are resolved first so the user approves exactly what will run
+	args, err := a.tools.Prepare(ctx, tc.Name, tc.Arguments)
+	if err != nil {
+		return "", err
+	}
+	tc.Arguments = args
+
+	if a.tools.RequiresApproval(tc.Name, tc.Arguments) && a.approvals != nil && a.approvalSender != nil {
+		chatID := tools.ChatIDFromContext(ctx)
+		userID := tools.UserIDFromContext(ctx)
+
+		desc := a.describeToolCall(tc.Name, tc.Arguments)
+		approvalID := a.approvals.Start(chatID, userID, tc.Name, tc.Arguments, desc)
+
+		if err := a.approvalSender(chatID, desc, approvalID); err != nil {
+			a.approvals.Cancel(approvalID)
+			return fmt.Sprintf("Failed to request approval: %s", err.Error()), nil
+		}
+
+		approved, err := a.approvals.Wait(ctx, approvalID)
+		if err != nil {
+			return fmt.Sprintf("Approval request failed: %s", err.Error()), nil
+		}
+		if !approved {
+			logger.Info("tool denied by user", "tool", tc.Name, "approvalID", approvalID)
+			return fmt.Sprintf("User denied %s (approval %s)", tc.Name, approvalID), nil
+		}
+		logger.Info("tool approved by user", "tool", tc.Name, "approvalID", approvalID)
+	}
+
+	// long-running tools enforce their own deadlines
+	if !longRunningTools[tc.Name] {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, toolTimeout)
+		defer cancel()
+	}
+
+	// tools that report their own progress know better than a generic notice
+	if report, ok := ctx.Value(tools.ProgressKey).(tools.ProgressFunc); ok && report != nil {
+		var reported atomic.Bool
+		ctx = context.WithValue(ctx, tools.ProgressKey, tools.ProgressFunc(func(status string) {
+			reported.Store(true)
+			report(status)
+		}))
+		slow := time.AfterFunc(slowToolDelay, func() {
+			if !reported.Load() {
+				report("still working: " + strings.ReplaceAll(tc.Name, "_", " "))
+			}
+		})
+		defer slow.Stop()
+	}
+
+	result, err = a.tools.Execute(ctx, tc.Name, tc.Arguments)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return result, fmt.Errorf("timed out after %s: %w", toolTimeout, err)
+	}
+	return result, err
+}
+
 // tools disabled during isolated operations (browse/code) to prevent prompt injection attacks
 // isolated mode is read-only: no state changes allowed after processing untrusted content
 var disabledDuringIsolation = map[string]bool{
 	// data extraction
-	"recall_memory": true,
+	"recall_memory":        true,
+	"show_memory_graph":    true,
+	"review_memory":        true,
+	"search_documents":     true,
+	"find_media":           true,
+	"search_conversations": true,
+	"list_events":          true,
+	"get_contact":          true,
+	"upcoming_birthdays":   true,
 
 	// data poisoning
-	"save_memory":    true,
-	"mark_sensitive": true,
-	"save_note":      true,
-	"delete_note":    true,
-	"archive_note":   true,
-	"restore_note":   true,
+	"save_memory":     true,
+	"mark_sensitive":  true,
+	"forget_fact":     true,
+	"restore_fact":    true,
+	"index_document":  true,
+	"delete_document": true,
+	"create_event":    true,
+	"update_event":    true,
+	"save_contact":    true,
+	"save_note":       true,
+	"delete_note":     true,
+	"archive_note":    true,
+	"restore_note":    true,
 
 	// config changes
 	"set_config":    true,
@@ -646,22 +1154,57 @@ var disabledDuringIsolation = map[string]bool{
 	"pull_model":    true,
 	"remove_model":  true,
 
+	// paid model calls
+	"compare_models": true,
+	"probe_model":    true,
+
+	// prompt changes outlive the session
+	"set_prompt_addendum": true,
+	"reload_prompt":       true,
+	"switch_persona":      true,
+
+	// wiping or resizing chat history
+	"clear_context":    true,
+	"set_context_size": true,
+
 	// scheduled tasks
-	"set_cron":    true,
-	"delete_cron": true,
-	"pause_cron":  true,
-	"resume_cron": true,
+	"set_cron":          true,
+	"delete_cron":       true,
+	"pause_cron":        true,
+	"resume_cron":       true,
+	"subscribe_feed":    true,
+	"unsubscribe_feed":  true,
+	"set_proactivity":   true,
+	"set_media_archive": true,
+	"set_quiet_hours":   true,
+	"vacation_until":    true,
+	"set_timezone":      true,
+	"travel_time":       true,
+	"watch_page":        true,
+	"unwatch_page":      true,
 
 	// code & deployment
-	"write_code":  true,
-	"deploy_app":  true,
-	"remove_app":  true,
-	"build_image": true,
+	"write_code":          true,
+	"continue_task":       true,
+	"deploy_app":          true,
+	"remove_app":          true,
+	"rollback_app":        true,
+	"build_image":         true,
+	"set_app_secret":      true,
+	"delete_app_secret":   true,
+	"set_auto_update":     true,
+	"disable_auto_update": true,
+	"add_app_domain":      true,
+	"remove_app_domain":   true,
+	"set_app_basic_auth":  true,
 
 	// skills
-	"install_skill": true,
-	"save_skill":    true,
-	"remove_skill":  true,
+	"install_skill":     true,
+	"update_skill":      true,
+	"update_all_skills": true,
+	"save_skill":        true,
+	"remove_skill":      true,
+	"run_skill_script":  true, // scripts granted network access could send data out
 
 	// file operations
 	"upload_file": true,
@@ -669,19 +1212,30 @@ var disabledDuringIsolation = map[string]bool{
 	"save_media":  true,
 
 	// external actions
-	"open_pr":     true,
-	"create_repo": true,
-	"send_image":  true,
-	"send_video":  true,
+	"open_pr":        true,
+	"create_repo":    true,
+	"open_issue":     true,
+	"comment_issue":  true,
+	"rerun_workflow": true,
+	"send_image":     true,
+	"send_video":     true,
+	"send_email":     true,
 
 	// container management
-	"start_container":   true,
-	"stop_container":    true,
-	"restart_container": true,
+	"start_container":    true,
+	"stop_container":     true,
+	"restart_container":  true,
+	"restart_service":    true,
+	"add_remote_host":    true,
+	"remove_remote_host": true,
+	"prune_docker":       true,
+	"run_remote_command": true,
 
 	// potential exfiltration channels
-	"download_file": true,
-	"fetch_url":     true,
+	"download_file":   true,
+	"fetch_url":       true,
+	"browse_download": true,
+	"share_link":      true,
 }
 
 func filterIsolatedTools(tools []llm.Tool) []llm.Tool {
@@ -694,12 +1248,28 @@ func filterIsolatedTools(tools []llm.Tool) []llm.Tool {
 	return filtered
 }
 
+// filterAllowedTools drops tools the turn was not allowed to use
+func filterAllowedTools(ctx context.Context, all []llm.Tool) []llm.Tool {
+	var filtered []llm.Tool
+	for _, t := range all {
+		if tools.ToolAllowed(ctx, t.Name) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
 // ProcessSystemTrigger handles a scheduled trigger (cron-based). Unlike user messages,
 // system triggers don't wait for session locks - they run in their own context.
 // This allows crons to fire even when a conversation is in progress.
 func (a *Agent) ProcessSystemTrigger(ctx context.Context, sessionID string, triggerPrompt string) (string, error) {
 	logger.Debug("system trigger received", "session", sessionID)
 
+	if !a.begin() {
+		return "", errShuttingDown
+	}
+	defer a.end()
+
 	sess := a.sessions.Get(sessionID)
 
 	// Add trigger as a system message so the agent knows this isn't a user speaking
@@ -708,8 +1278,14 @@ func (a *Agent) ProcessSystemTrigger(ctx context.Context, sessionID string, trig
 	// Add chatID to context for tool access
 	chatID := a.parseChatID(sessionID)
 	ctx = context.WithValue(ctx, tools.ChatIDKey, chatID)
+	ctx = context.WithValue(ctx, tools.SessionIDKey, sessionID)
+	if lang := a.sessionLanguage(sessionID); lang != "" {
+		ctx = context.WithValue(ctx, tools.LanguageKey, lang)
+	}
+	ctx = context.WithValue(ctx, tools.TimezoneKey, a.Timezone(sessionID))
+	ctx = context.WithValue(ctx, tools.PrefsKey, a.sessionPrefs(sessionID))
 
-	response, err := a.runAgentLoop(ctx, sess)
+	response, err := a.runAgentLoop(ctx, sess, nil)
 	if err != nil {
 		logger.Error("system trigger processing failed", "error", err)
 		return "", err
@@ -756,7 +1332,7 @@ func (a *Agent) tryFallbackProvider(ctx context.Context, failedProviders map[str
 	}
 
 	for _, provider := range fallbackProviders {
-		if failedProviders[provider] {
+		if failedProviders[provider] || !a.health.Available(provider) {
 			continue
 		}
 
@@ -779,8 +1355,8 @@ func (a *Agent) tryFallbackProvider(ctx context.Context, failedProviders map[str
 			continue
 		}
 
-		// Don't persist fallback to runtime config - each message should try
-		// the configured provider first, then fall back if needed
+		// Don't persist fallback to runtime config - preferredLLM switches
+		// back once the configured provider's cooldown has passed
 		logger.Info("switched to fallback provider", "provider", provider, "model", model)
 		return newLLM, provider, nil
 	}
@@ -820,6 +1396,73 @@ func (a *Agent) describeToolCall(toolName, args string) string {
 			name = "unknown"
 		}
 		return fmt.Sprintf("[Approval Required]\nTool: remove_app\nAction: Remove \"%s\" from production", name)
+	case "rollback_app":
+		name, _ := parsed["name"].(string)
+		if name == "" {
+			name = "unknown"
+		}
+		target := "the previous deployment"
+		if id, _ := parsed["deployment_id"].(float64); id > 0 {
+			target = fmt.Sprintf("deployment #%d", int64(id))
+		}
+		return fmt.Sprintf("[Approval Required]\nTool: rollback_app\nAction: Roll \"%s\" back to %s", name, target)
+	case "set_auto_update":
+		app, _ := parsed["app"].(string)
+		repo, _ := parsed["repo"].(string)
+		branch, _ := parsed["branch"].(string)
+		if branch == "" {
+			branch = "default branch"
+		}
+		return fmt.Sprintf("[Approval Required]\nTool: set_auto_update\nAction: Automatically redeploy \"%s\" to production from %s (%s)", app, repo, branch)
+	case "set_app_basic_auth":
+		// never echo the password into the approval prompt
+		app, _ := parsed["app"].(string)
+		if enabled, _ := parsed["enabled"].(bool); !enabled {
+			return fmt.Sprintf("[Approval Required]\nTool: set_app_basic_auth\nAction: Remove the login from \"%s\", making it public", app)
+		}
+		username, _ := parsed["username"].(string)
+		return fmt.Sprintf("[Approval Required]\nTool: set_app_basic_auth\nAction: Require a login as \"%s\" for \"%s\"", username, app)
+	case "add_remote_host":
+		name, _ := parsed["name"].(string)
+		address, _ := parsed["address"].(string)
+		return fmt.Sprintf("[Approval Required]\nTool: add_remote_host\nAction: Manage \"%s\" at %s and send it the homelab-agent token", name, address)
+	case "prune_docker":
+		host, _ := parsed["host"].(string)
+		if host == "" {
+			host = "the default remote host"
+		}
+		what := "dangling images"
+		if images, ok := parsed["images"].(bool); ok && !images {
+			what = ""
+		}
+		if volumes, _ := parsed["volumes"].(bool); volumes {
+			what = strings.TrimPrefix(what+" and unused volumes (their data is lost)", " and ")
+		}
+		return fmt.Sprintf("[Approval Required]\nTool: prune_docker\nAction: Delete %s on %s", what, host)
+	case "grant_skill_access":
+		skill, _ := parsed["skill"].(string)
+		var caps []string
+		if list, ok := parsed["capabilities"].([]any); ok {
+			for _, c := range list {
+				if s, ok := c.(string); ok {
+					caps = append(caps, s)
+				}
+			}
+		}
+		if len(caps) == 0 {
+			return fmt.Sprintf("[Approval Required]\nTool: grant_skill_access\nAction: Revoke all access from skill \"%s\"'s scripts", skill)
+		}
+		return fmt.Sprintf("[Approval Required]\nTool: grant_skill_access\nAction: Let skill \"%s\"'s scripts use %s", skill, strings.Join(caps, " and "))
+	case "run_remote_command":
+		host, _ := parsed["host"].(string)
+		command, _ := parsed["command"].(string)
+		return fmt.Sprintf("[Approval Required]\nTool: run_remote_command\nAction: Run `%s` on %s over SSH", command, host)
+	case "send_email":
+		// replies have their recipient filled in by Prepare, so this is the real address
+		to, _ := parsed["to"].(string)
+		subject, _ := parsed["subject"].(string)
+		body, _ := parsed["body"].(string)
+		return fmt.Sprintf("[Approval Required]\nTool: send_email\nTo: %s\nSubject: %s\n\n%s", to, subject, truncate(body, 500))
 	default:
 		return fmt.Sprintf("[Approval Required]\nTool: %s", toolName)
 	}
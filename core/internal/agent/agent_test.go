@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+func TestFilterIsolatedTools(t *testing.T) {
+	// each of these pulls in remote content or changes state that outlives the session
+	blocked := []string{
+		"install_skill",
+		"update_skill",
+		"update_all_skills",
+		"run_skill_script",
+		"watch_page",
+		"unwatch_page",
+		"compare_models",
+		"probe_model",
+		"share_link",
+	}
+
+	all := []llm.Tool{{Name: "browse"}, {Name: "current_time"}}
+	for _, name := range blocked {
+		all = append(all, llm.Tool{Name: name})
+	}
+
+	filtered := filterIsolatedTools(all)
+	if len(filtered) != 2 || filtered[0].Name != "browse" || filtered[1].Name != "current_time" {
+		t.Errorf("expected only browse and current_time to survive isolation, got %v", filtered)
+	}
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/tools"
+)
+
+// coreCommands are offered in every chat ahead of the installed skills.
+// /cancel never reaches the agent, the bots treat it as a stop word.
+var coreCommands = []Command{
+	{Name: "backup", Description: "Back up memory now"},
+	{Name: "usage", Description: "Show token usage and costs"},
+	{Name: "cancel", Description: "Stop what I'm working on"},
+}
+
+// coreCommandHints tell the model what a core command asks for
+var coreCommandHints = map[string]string{
+	"backup": "[The user ran /backup. Back up memory now with backup_memory and confirm when it's done.]",
+	"usage":  "[The user ran /usage. Summarize token usage and costs with usage_summary.]",
+}
+
+// Commands lists the slash commands chat apps should offer: the core commands
+// and every single-file skill, which is what detectSkillCommand accepts
+func (a *Agent) Commands() []Command {
+	commands := append([]Command(nil), coreCommands...)
+	if a.skillsDir == "" {
+		return commands
+	}
+
+	entries, err := os.ReadDir(a.skillsDir)
+	if err != nil {
+		logger.Warn("failed to list skills for commands", "error", err)
+		return commands
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(strings.ToLower(name), ".md") {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(name, ".md"), ".MD"))
+
+		content, err := os.ReadFile(filepath.Join(a.skillsDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		desc := tools.ParseSkillMeta(string(content)).Description
+		if desc == "" {
+			desc = fmt.Sprintf("Run the %s skill", name)
+		}
+		commands = append(commands, Command{Name: name, Description: desc, Skill: true})
+	}
+	return commands
+}
+
+// commandName is the command in the first word of a message, without the
+// slash or the @botname Telegram adds in groups
+func commandName(word string) string {
+	if !strings.HasPrefix(word, "/") {
+		return ""
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(word, "/"), "@")
+	return strings.ToLower(name)
+}
+
+// coreCommandHint returns the instruction for a core command message, empty
+// for anything else
+func coreCommandHint(message string) string {
+	parts := strings.Fields(message)
+	if len(parts) == 0 {
+		return ""
+	}
+	return coreCommandHints[commandName(parts[0])]
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/budget"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/session"
+	"github.com/bowerhall/sheldon/internal/tools"
+)
+
+const (
+	defaultContextWindow = 32000
+	responseReserve      = 4096 // tokens kept free for the model's reply
+	compactThreshold     = 0.8  // compact once history uses this share of the budget
+	compactTarget        = 0.5  // and shrink it to this share
+	maxSummaryInputChars = 2000 // per message, tool output is often huge
+	summaryReserve       = 1024 // tokens kept free for the summary itself
+)
+
+const summaryPrefix = "[Summary of earlier conversation]\n"
+
+const summaryPrompt = `You compress conversation history for an AI assistant whose context window is full.
+Summarize the conversation below so the assistant can continue seamlessly. Keep:
+- facts the user shared and decisions that were made
+- open tasks, promises and pending questions
+- results of tool calls that are still relevant
+Drop greetings, small talk and anything already resolved. If an earlier summary is included, merge it.
+Write plain prose or short bullets, under 400 words.`
+
+// fitContext compacts the oldest turns of a session into a summary when the
+// estimated prompt approaches the model's context window
+func (a *Agent) fitContext(ctx context.Context, sess *session.Session, model llm.LLM, systemPrompt string, loopTools []llm.Tool) {
+	window := contextWindow(model)
+	available := window - responseReserve - llm.EstimateTokens(systemPrompt) - llm.EstimateToolTokens(loopTools)
+	used := sess.Tokens()
+	if float64(used) <= float64(available)*compactThreshold {
+		return
+	}
+
+	cut := sess.CompactionPoint(int(float64(available) * compactTarget))
+	if cut == 0 {
+		logger.Warn("context nearly full but nothing to compact", "used", used, "available", available)
+		return
+	}
+
+	ctx, span := a.tracer.Start(ctx, "agent.compact", "tokens", used, "removed", cut)
+	defer span.End()
+
+	old := sess.Messages()[:cut]
+	summary, err := a.summarizeHistory(ctx, model, old)
+	span.RecordError(err)
+	if err != nil {
+		// dropping history is better than the provider rejecting the request
+		logger.Warn("history summarization failed, trimming instead", "error", err)
+		summary = ""
+	}
+
+	if summary != "" {
+		sess.Compact(cut, summaryPrefix+summary)
+	} else {
+		sess.Compact(cut, "")
+	}
+	logger.Info("compacted session history", "removed", cut, "before", used, "after", sess.Tokens(), "window", window)
+
+	sessionID := tools.SessionIDFromContext(ctx)
+	if summary != "" && a.convo != nil && sessionID != "" {
+		if err := a.convo.SaveSummary(sessionID, summary); err != nil {
+			logger.Warn("failed to save conversation summary", "error", err)
+		}
+	}
+}
+
+// contextWindow is the model's context size in tokens, or AGENT_CONTEXT_TOKENS if set
+func contextWindow(model llm.LLM) int {
+	window := contextWindowTokens
+	if window == 0 {
+		window = model.Capabilities().ContextWindow
+	}
+	if window == 0 {
+		window = defaultContextWindow
+	}
+	return window
+}
+
+// summarizeHistory asks the model to summarize messages. The transcript has
+// to fit the context window too: an earlier summary always goes in, then as
+// many of the newest messages as fit. The call counts against the daily budget.
+func (a *Agent) summarizeHistory(ctx context.Context, model llm.LLM, messages []llm.Message) (string, error) {
+	maxTokens := contextWindow(model) - summaryReserve - llm.EstimateTokens(summaryPrompt)
+	transcript := summaryTranscript(messages, maxTokens)
+
+	resp, err := model.ChatWithTools(ctx, summaryPrompt, []llm.Message{{Role: "user", Content: transcript}}, nil)
+	if err != nil {
+		return "", fmt.Errorf("summarize: %w", err)
+	}
+
+	if resp.Usage != nil && a.budget != nil {
+		tokens := budget.Tokens{
+			Input:      resp.Usage.PromptTokens,
+			Output:     resp.Usage.CompletionTokens,
+			CacheWrite: resp.Usage.CacheWriteTokens,
+			CacheRead:  resp.Usage.CacheReadTokens,
+		}
+		if !a.budget.RecordTokens(model.Provider(), model.Model(), tokens) {
+			logger.Warn("daily API limit reached during summarization")
+		}
+	}
+
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// summaryTranscript writes messages as "role: content" lines, each cut to
+// maxSummaryInputChars runes, dropping the oldest once maxTokens is reached
+func summaryTranscript(messages []llm.Message, maxTokens int) string {
+	lines := make([]string, 0, len(messages))
+	for _, m := range messages {
+		content := truncate(strings.TrimPrefix(m.Content, summaryPrefix), maxSummaryInputChars)
+
+		switch {
+		case len(m.ToolCalls) > 0:
+			var names []string
+			for _, tc := range m.ToolCalls {
+				names = append(names, tc.Name)
+			}
+			lines = append(lines, fmt.Sprintf("assistant (called %s): %s\n\n", strings.Join(names, ", "), content))
+		case content != "":
+			lines = append(lines, fmt.Sprintf("%s: %s\n\n", m.Role, content))
+		default:
+			lines = append(lines, "")
+		}
+	}
+
+	// an earlier summary stays, it already stands for everything before it
+	var head string
+	if len(messages) > 0 && strings.HasPrefix(messages[0].Content, summaryPrefix) {
+		head, lines = lines[0], lines[1:]
+	}
+
+	used := llm.EstimateTokens(head)
+	start := len(lines)
+	for start > 0 && used+llm.EstimateTokens(lines[start-1]) <= maxTokens {
+		start--
+		used += llm.EstimateTokens(lines[start])
+	}
+
+	var transcript strings.Builder
+	transcript.WriteString(head)
+	if start > 0 {
+		fmt.Fprintf(&transcript, "[%d earlier messages omitted]\n\n", start)
+	}
+	for _, line := range lines[start:] {
+		transcript.WriteString(line)
+	}
+	return transcript.String()
+}
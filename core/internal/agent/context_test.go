@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+func TestSummaryTranscript(t *testing.T) {
+	// a multi-byte rune straddles the per-message cut
+	long := strings.Repeat("a", maxSummaryInputChars-1) + strings.Repeat("ü", 10)
+	transcript := summaryTranscript([]llm.Message{{Role: "tool", Content: long}}, 10000)
+	if !utf8.ValidString(transcript) {
+		t.Error("transcript cut a rune in half")
+	}
+	if !strings.Contains(transcript, "ü...") {
+		t.Errorf("expected the message cut after a whole rune, got %q", transcript[len(transcript)-20:])
+	}
+
+	messages := []llm.Message{{Role: "system", Content: summaryPrefix + "the user is moving to Lisbon"}}
+	for range 50 {
+		messages = append(messages, llm.Message{Role: "user", Content: strings.Repeat("x", 400)})
+	}
+	messages = append(messages, llm.Message{Role: "user", Content: "latest question"})
+
+	transcript = summaryTranscript(messages, 1000)
+	if tokens := llm.EstimateTokens(transcript); tokens > 1050 {
+		t.Errorf("transcript is %d tokens, expected about 1000", tokens)
+	}
+	for _, want := range []string{"moving to Lisbon", "earlier messages omitted", "latest question"} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("transcript is missing %q", want)
+		}
+	}
+}
@@ -8,7 +8,11 @@ import (
 	"time"
 
 	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/feeds"
+	"github.com/bowerhall/sheldon/internal/locale"
 	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/tools"
+	"github.com/bowerhall/sheldon/internal/watch"
 	"github.com/bowerhall/sheldonmem"
 )
 
@@ -20,8 +24,16 @@ type CronRunner struct {
 	notify             NotifyFunc  // sends messages to chat
 	timezone           *time.Location
 	agent              *Agent    // for system crons
+	resolveSession     func(chatID int64) string
+	loadSkill          func(name string) (string, error)
 	mu                 sync.Mutex
 	lastExtractionRun  time.Time // track last extraction run (every 6 hours)
+	lastReconcileRun   time.Time // track last contradiction check (daily)
+	lastCheckinPlan    time.Time // track last proactive check-in planning (daily)
+	digestPeriod       time.Duration
+	feeds              *feeds.Store
+	watches            *watch.Store
+	fetcher            *watch.Fetcher
 }
 
 // NewCronRunner creates a new CronRunner
@@ -40,6 +52,28 @@ func (r *CronRunner) SetAgent(agent *Agent) {
 	r.agent = agent
 }
 
+// SetSessionResolver sets how a cron's chat ID maps to a session ID,
+// so triggers run in the session of the provider the chat belongs to
+func (r *CronRunner) SetSessionResolver(fn func(chatID int64) string) {
+	r.resolveSession = fn
+}
+
+// SetSkillLoader sets how crons that run a skill read its instructions
+func (r *CronRunner) SetSkillLoader(fn func(name string) (string, error)) {
+	r.loadSkill = fn
+}
+
+// currentTime is now in the chat's language, clock and timezone, for trigger
+// prompts
+func (r *CronRunner) currentTime(sessionID string) string {
+	var prefs locale.Prefs
+	loc := r.timezone
+	if r.agent != nil {
+		prefs, loc = r.agent.sessionPrefs(sessionID), r.agent.Timezone(sessionID)
+	}
+	return prefs.LongDateTime(time.Now().In(loc))
+}
+
 // Run starts the cron checker loop
 func (r *CronRunner) Run(ctx context.Context) {
 	// check every 10 seconds to support sub-minute schedules
@@ -84,7 +118,13 @@ func (r *CronRunner) checkDueCrons(ctx context.Context) {
 		return
 	}
 
+	now := time.Now()
 	for _, c := range crons {
+		// quiet hours follow each chat's own clock
+		if quiet, isQuiet := r.quietPeriod(now, r.crons.Location(c.ChatID)); isQuiet {
+			r.hold(c, quiet)
+			continue
+		}
 		r.fireCron(ctx, c)
 	}
 }
@@ -96,12 +136,29 @@ func (r *CronRunner) checkSystemCrons(ctx context.Context) {
 	}
 
 	now := time.Now()
+	_, isQuiet := r.quietPeriod(now, r.timezone)
 
 	r.mu.Lock()
 	shouldRun := now.Sub(r.lastExtractionRun) >= 6*time.Hour
 	if shouldRun {
 		r.lastExtractionRun = now
 	}
+	// first check waits a day so startup doesn't trigger LLM calls on every restart
+	if r.lastReconcileRun.IsZero() {
+		r.lastReconcileRun = now
+	}
+	// contradiction questions are messages too, they wait out quiet periods
+	shouldReconcile := !isQuiet && now.Sub(r.lastReconcileRun) >= 24*time.Hour
+	if shouldReconcile {
+		r.lastReconcileRun = now
+	}
+	if r.lastCheckinPlan.IsZero() {
+		r.lastCheckinPlan = now
+	}
+	shouldPlan := now.Sub(r.lastCheckinPlan) >= 24*time.Hour
+	if shouldPlan {
+		r.lastCheckinPlan = now
+	}
 	r.mu.Unlock()
 
 	// Memory extraction: runs every 6 hours, processes messages older than 6 hours
@@ -115,22 +172,94 @@ func (r *CronRunner) checkSystemCrons(ctx context.Context) {
 			}
 		}()
 	}
+
+	// Contradiction check: compares facts stored under different fields and asks the user
+	if shouldReconcile {
+		logger.Info("running contradiction check")
+		reconcileCtx := context.WithoutCancel(ctx)
+		go func() {
+			if err := r.agent.ReconcileMemory(reconcileCtx); err != nil {
+				logger.Error("contradiction check failed", "error", err)
+			}
+		}()
+	}
+
+	// Check-in planning: schedules one-time check-ins around upcoming events and goals
+	if shouldPlan {
+		logger.Info("planning proactive check-ins")
+		planCtx := context.WithoutCancel(ctx)
+		go func() {
+			if err := r.PlanCheckins(planCtx); err != nil {
+				logger.Error("check-in planning failed", "error", err)
+			}
+		}()
+	}
 }
 
 func (r *CronRunner) fireCron(ctx context.Context, c cron.Cron) {
 	sessionID := fmt.Sprintf("telegram:%d", c.ChatID)
+	if r.resolveSession != nil {
+		sessionID = r.resolveSession(c.ChatID)
+	}
+
+	if c.Keyword == DigestKeyword {
+		r.sendDigest(c, sessionID)
+		r.reschedule(c)
+		return
+	}
+
+	if c.Keyword == feeds.DigestKeyword {
+		r.sendFeedDigest(c)
+		r.reschedule(c)
+		return
+	}
+
+	if id, ok := watch.ParseKeyword(c.Keyword); ok {
+		r.checkWatch(ctx, c, id)
+		r.reschedule(c)
+		return
+	}
+
+	if c.Skill != "" {
+		r.runSkill(ctx, c, sessionID)
+		r.reschedule(c)
+		return
+	}
+
+	if c.Prompt != "" {
+		r.runTrigger(ctx, c, sessionID, fmt.Sprintf(`[SCHEDULED TRIGGER]
+Keyword: %s
+Current time: %s
+
+This is a scheduled trigger you set up earlier with these instructions:
+%s
+
+Follow them and respond naturally - the user will see your message.`, c.Keyword, r.currentTime(sessionID), c.Prompt))
+		r.reschedule(c)
+		return
+	}
 
 	// HYBRID SEARCH: semantic on embedded facts + keyword on recent messages
 	// This ensures same-day context (not yet embedded) is still found
 
+	// self-scheduled check-ins recall by their topic
+	query := c.Keyword
+	if topic, ok := strings.CutPrefix(c.Keyword, ProactiveKeywordPrefix); ok {
+		query = strings.ReplaceAll(topic, "-", " ")
+	}
+
 	// 1. Semantic search on embedded facts
-	result, err := r.memory.Recall(ctx, c.Keyword, nil, 10)
+	opts := sheldonmem.RecallOptions{Depth: 1}
+	if r.agent != nil {
+		opts.OwnerID = r.agent.getOrCreateUserEntity(sessionID)
+	}
+	result, err := r.memory.RecallWithOptions(ctx, query, nil, 10, opts)
 	if err != nil {
 		logger.Error("cron memory recall failed", "keyword", c.Keyword, "error", err)
 	}
 
 	// 2. Keyword search on recent daily messages (catches same-day context)
-	recentMsgs, err := r.memory.SearchRecentByKeyword(sessionID, c.Keyword, 2)
+	recentMsgs, err := r.memory.SearchRecentByKeyword(sessionID, query, 2)
 	if err != nil {
 		logger.Error("cron daily search failed", "keyword", c.Keyword, "error", err)
 	}
@@ -168,7 +297,7 @@ func (r *CronRunner) fireCron(ctx context.Context, c cron.Cron) {
 	}
 
 	// format current time
-	currentTime := time.Now().In(r.timezone).Format("Monday, January 2, 2006 3:04 PM")
+	currentTime := r.currentTime(sessionID)
 
 	// build the trigger prompt
 	prompt := fmt.Sprintf(`[SCHEDULED TRIGGER]
@@ -179,13 +308,58 @@ Recalled context:
 %s
 This is a scheduled trigger you set up earlier. Take appropriate action based on the keyword and context:
 - If keyword is "checkin" or similar: Send a brief, natural check-in message
+- If keyword starts with "checkin-": You planned this yourself from what you know; bring up the topic briefly and naturally, without announcing that it was scheduled
 - If keyword relates to a reminder (meds, water, stretch, etc.): Send a friendly reminder
 - If keyword relates to a task (build-*, deploy-*, etc.): Start working on the task and report progress
 
 Respond naturally - the user will see your message.`, c.Keyword, currentTime, factsContext.String())
 
-	// inject into agent loop
-	response, err := r.trigger(c.ChatID, sessionID, prompt)
+	r.runTrigger(ctx, c, sessionID, prompt)
+	r.reschedule(c)
+}
+
+// runSkill fires a cron that runs a skill: the skill's instructions become the
+// trigger prompt and the tools it declares limit the turn
+func (r *CronRunner) runSkill(ctx context.Context, c cron.Cron, sessionID string) {
+	if r.loadSkill == nil {
+		logger.Warn("cron runs a skill but skills are not enabled", "keyword", c.Keyword, "skill", c.Skill)
+		return
+	}
+	content, err := r.loadSkill(c.Skill)
+	if err != nil {
+		logger.Error("scheduled skill unavailable", "keyword", c.Keyword, "skill", c.Skill, "error", err)
+		if r.notify != nil {
+			r.notify(c.ChatID, fmt.Sprintf("Scheduled trigger '%s' couldn't run skill '%s': it isn't installed anymore.", c.Keyword, c.Skill))
+		}
+		return
+	}
+
+	ctx = tools.WithToolScope(ctx)
+	if allowed := tools.SkillToolAllowlist(content); allowed != nil {
+		tools.RestrictTools(ctx, allowed)
+	}
+
+	extra := ""
+	if c.Prompt != "" {
+		extra = fmt.Sprintf("\nFor this run also:\n%s\n", c.Prompt)
+	}
+
+	r.runTrigger(ctx, c, sessionID, fmt.Sprintf(`[SCHEDULED SKILL]
+Keyword: %s
+Skill: %s
+Current time: %s
+
+This is a scheduled run of the %s skill you set up earlier. Its instructions:
+
+%s
+%s
+Follow them and respond naturally - the user will see your message.`, c.Keyword, c.Skill, r.currentTime(sessionID), c.Skill, content, extra))
+}
+
+// runTrigger injects a cron's prompt into the agent loop and sends the reply,
+// limited to the cron's tools if it lists any
+func (r *CronRunner) runTrigger(ctx context.Context, c cron.Cron, sessionID, prompt string) {
+	response, err := r.trigger(tools.WithAllowedTools(ctx, c.Tools), c.ChatID, sessionID, prompt)
 	if err != nil {
 		logger.Error("cron trigger failed", "keyword", c.Keyword, "error", err)
 		// still update next_run so we don't keep failing
@@ -195,18 +369,23 @@ Respond naturally - the user will see your message.`, c.Keyword, currentTime, fa
 			r.notify(c.ChatID, response)
 		}
 		logger.Debug("cron fired", "keyword", c.Keyword, "chat", c.ChatID)
+		if err := r.crons.RecordRun(c.Keyword, c.ChatID); err != nil {
+			logger.Warn("failed to record cron run", "keyword", c.Keyword, "error", err)
+		}
 	}
+}
 
+// reschedule moves a fired cron to its next run, or deletes it if it was one-time
+func (r *CronRunner) reschedule(c cron.Cron) {
 	// calculate next run
-	nextRun, err := r.crons.ComputeNextRun(c.Schedule)
+	nextRun, err := r.crons.ComputeNextRun(c.Schedule, c.ChatID)
 	if err != nil {
 		logger.Error("failed to compute next run", "schedule", c.Schedule, "error", err)
 		return
 	}
 
 	// one-time crons: delete after firing instead of rescheduling
-	// detected by expiry being set and before the next computed run
-	if c.ExpiresAt != nil && c.ExpiresAt.Before(nextRun) {
+	if c.OneTime(nextRun) {
 		if err := r.crons.Delete(c.ID); err != nil {
 			logger.Error("failed to delete one-time cron", "id", c.ID, "error", err)
 		} else {
@@ -222,9 +401,11 @@ Respond naturally - the user will see your message.`, c.Keyword, currentTime, fa
 	logger.Debug("cron next run scheduled", "keyword", c.Keyword, "next", nextRun)
 }
 
+// truncate shortens s to maxLen characters, never splitting a multi-byte one
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen] + "..."
+	return string(runes[:maxLen]) + "..."
 }
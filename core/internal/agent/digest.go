@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// DigestKeyword is the reserved cron keyword that delivers the memory digest
+// instead of triggering the agent loop
+const DigestKeyword = "memory-digest"
+
+// digestFactLimit keeps a busy week's digest readable
+const digestFactLimit = 20
+
+// EnableDigest schedules the memory digest for a chat, replacing any earlier
+// schedule. A zero period removes it.
+func (r *CronRunner) EnableDigest(chatID int64, schedule string, period time.Duration) error {
+	r.digestPeriod = period
+
+	existing, err := r.crons.GetByKeyword(DigestKeyword, chatID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Schedule == schedule && period > 0 {
+		return nil
+	}
+	if existing != nil {
+		if err := r.crons.Delete(existing.ID); err != nil {
+			return err
+		}
+	}
+	if period == 0 {
+		return nil
+	}
+
+	_, err = r.crons.Create(DigestKeyword, schedule, chatID, nil)
+	return err
+}
+
+// sendDigest summarizes what was added to memory over the digest period
+func (r *CronRunner) sendDigest(c cron.Cron, sessionID string) {
+	if r.agent == nil || r.notify == nil || r.digestPeriod == 0 {
+		return
+	}
+
+	now := time.Now().In(r.crons.Location(c.ChatID))
+	since := now.Add(-r.digestPeriod)
+	ownerID := r.agent.getOrCreateUserEntity(sessionID)
+
+	digest, err := r.memory.Digest(sessionID, ownerID, since)
+	if err != nil {
+		logger.Error("digest failed", "error", err, "session", sessionID)
+		return
+	}
+
+	runs, err := r.crons.RunsSince(c.ChatID, since)
+	if err != nil {
+		logger.Warn("failed to load cron runs for digest", "error", err)
+	}
+
+	if digest.Empty() && len(runs) == 0 {
+		logger.Debug("digest skipped, nothing new", "session", sessionID)
+		return
+	}
+
+	r.notify(c.ChatID, r.formatDigest(digest, runs, now))
+	logger.Info("digest sent", "chat", c.ChatID, "facts", len(digest.Facts), "runs", len(runs))
+}
+
+func (r *CronRunner) formatDigest(d *sheldonmem.Digest, runs []cron.RunSummary, now time.Time) string {
+	var b strings.Builder
+
+	title := "Daily"
+	if r.digestPeriod > 24*time.Hour {
+		title = "Weekly"
+	}
+	fmt.Fprintf(&b, "%s digest (%s - %s)\n", title, d.Since.In(now.Location()).Format("Jan 2"), now.Format("Jan 2"))
+
+	if len(d.Facts) > 0 {
+		fmt.Fprintf(&b, "\nNew things I learned (%d):\n", len(d.Facts))
+		for i, f := range d.Facts {
+			if i == digestFactLimit {
+				fmt.Fprintf(&b, "- and %d more\n", len(d.Facts)-digestFactLimit)
+				break
+			}
+			fmt.Fprintf(&b, "- %s: %s\n", f.Field, truncate(f.Value, 120))
+		}
+	}
+
+	if len(d.Relationships) > 0 {
+		b.WriteString("\nConnections:\n")
+		for _, rel := range d.Relationships {
+			fmt.Fprintf(&b, "- %s %s %s\n", digestName(rel.Source), strings.ReplaceAll(rel.Relation, "_", " "), digestName(rel.Target))
+		}
+	}
+
+	if len(d.Notes) > 0 {
+		b.WriteString("\nNotes updated:\n")
+		for _, n := range d.Notes {
+			fmt.Fprintf(&b, "- %s\n", n.Key)
+		}
+	}
+
+	if len(runs) > 0 {
+		b.WriteString("\nReminders and tasks that ran:\n")
+		for _, run := range runs {
+			if run.Count > 1 {
+				fmt.Fprintf(&b, "- %s (%d times)\n", run.Keyword, run.Count)
+			} else {
+				fmt.Fprintf(&b, "- %s\n", run.Keyword)
+			}
+		}
+	}
+
+	if len(d.Summaries) > 0 {
+		b.WriteString("\nConversations:\n")
+		for _, s := range d.Summaries {
+			fmt.Fprintf(&b, "- %s: %s\n", s.SummaryDate.Format("Jan 2"), truncate(s.Summary, 300))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// digestName shows user entities as "You" rather than their internal name
+func digestName(name string) string {
+	if strings.HasPrefix(name, "user_") {
+		return "You"
+	}
+	return name
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/documents"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// documentIndexTimeout bounds text extraction and embedding of one upload
+const documentIndexTimeout = 2 * time.Minute
+
+// indexUploads adds documents sent in chat to the user's searchable library.
+// It runs in the background so a long PDF doesn't hold up the reply, and
+// returns the names of the documents being indexed.
+func (a *Agent) indexUploads(ownerID int64, media []llm.MediaContent) []string {
+	var names []string
+	for _, m := range media {
+		if (m.Type != llm.MediaTypePDF && m.Type != llm.MediaTypeDocument) || ownerID == 0 {
+			continue
+		}
+
+		name := m.Filename
+		if name == "" {
+			ext := ".pdf"
+			if m.Type == llm.MediaTypeDocument {
+				ext = ".docx"
+			}
+			name = fmt.Sprintf("upload-%s%s", time.Now().Format("2006-01-02-150405"), ext)
+		}
+
+		if !a.begin() {
+			return names
+		}
+		names = append(names, name)
+		go func(m llm.MediaContent, name string) {
+			defer a.end()
+
+			ctx, cancel := context.WithTimeout(context.Background(), documentIndexTimeout)
+			defer cancel()
+
+			text, err := documents.ExtractText(ctx, m.Data, m.MimeType, name)
+			if err != nil {
+				logger.Warn("document text extraction failed", "error", err, "name", name)
+				return
+			}
+
+			chunks := documents.Chunk(text)
+			if len(chunks) == 0 {
+				logger.Debug("document has no text to index", "name", name)
+				return
+			}
+
+			doc, err := a.memory.AddDocument(ctx, ownerID, name, "chat", chunks)
+			if err != nil {
+				logger.Warn("document indexing failed", "error", err, "name", name)
+				return
+			}
+			// searches cached before the upload finished indexing would miss it
+			a.tools.Invalidate("search_documents")
+			logger.Info("document indexed", "id", doc.ID, "name", name, "chunks", doc.ChunkCount)
+		}(m, name)
+	}
+	return names
+}
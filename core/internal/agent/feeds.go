@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/feeds"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// feedDigestPerFeed keeps one busy feed from drowning out the rest
+const feedDigestPerFeed = 10
+
+// EnableFeeds lets feed-digest crons deliver new items from subscribed feeds
+func (r *CronRunner) EnableFeeds(store *feeds.Store) {
+	r.feeds = store
+}
+
+// sendFeedDigest lists new feed items as headlines with links. Feed content is
+// untrusted, so it's sent as-is rather than passed through the agent loop.
+func (r *CronRunner) sendFeedDigest(c cron.Cron) {
+	if r.feeds == nil || r.notify == nil {
+		return
+	}
+
+	items, err := r.feeds.Pending(c.ChatID)
+	if err != nil {
+		logger.Error("feed digest failed", "chat", c.ChatID, "error", err)
+		return
+	}
+	if len(items) == 0 {
+		logger.Debug("feed digest skipped, nothing new", "chat", c.ChatID)
+		return
+	}
+
+	r.notify(c.ChatID, formatFeedDigest(items))
+
+	if err := r.feeds.MarkDelivered(items); err != nil {
+		logger.Error("failed to mark feed items delivered", "chat", c.ChatID, "error", err)
+	}
+	logger.Info("feed digest sent", "chat", c.ChatID, "items", len(items))
+}
+
+func formatFeedDigest(items []feeds.Item) string {
+	var b strings.Builder
+	b.WriteString("News from your feeds")
+
+	var feedID int64
+	shown, skipped := 0, 0
+	for _, item := range items {
+		if item.FeedID != feedID {
+			if skipped > 0 {
+				fmt.Fprintf(&b, "...and %d more\n", skipped)
+			}
+			feedID, shown, skipped = item.FeedID, 0, 0
+			fmt.Fprintf(&b, "\n\n%s\n", item.FeedTitle)
+		}
+		if shown == feedDigestPerFeed {
+			skipped++
+			continue
+		}
+		shown++
+
+		title := item.Title
+		if title == "" {
+			title = item.Link
+		}
+		fmt.Fprintf(&b, "- %s", title)
+		if item.Link != "" && item.Link != title {
+			fmt.Fprintf(&b, "\n  %s", item.Link)
+		}
+		b.WriteString("\n")
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&b, "...and %d more\n", skipped)
+	}
+
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// memberRelation links a speaker's entity to the group chat they talk in.
+// The edge metadata holds their display name so extraction can attribute facts.
+const memberRelation = "member_of"
+
+// attributeSpeaker prefixes a group message with who said it
+func attributeSpeaker(speaker, text string) string {
+	if speaker == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s]: %s", speaker, text)
+}
+
+// speakerEntityName is the memory entity for a participant in a group chat.
+// On Telegram a private chat ID equals the user ID, so this is the same entity as their DM.
+func speakerEntityName(sessionID string, userID int64) string {
+	provider, _, _ := strings.Cut(sessionID, ":")
+	return fmt.Sprintf("user_%s_%d", provider, userID)
+}
+
+// getOrCreateSpeakerEntity ensures a group participant has their own user entity
+// and is recorded as a member of the group under their display name
+func (a *Agent) getOrCreateSpeakerEntity(sessionID string, userID int64, speaker string) string {
+	entityName := speakerEntityName(sessionID, userID)
+	speakerID := a.getOrCreateUserEntityNamed(entityName)
+	groupID := a.getOrCreateUserEntity(sessionID)
+	if speakerID == 0 || groupID == 0 || speaker == "" {
+		return entityName
+	}
+
+	edges, err := a.memory.GetEdgesTo(groupID)
+	if err != nil {
+		logger.Warn("failed to load group members", "error", err, "session", sessionID)
+		return entityName
+	}
+	for _, e := range edges {
+		if e.Relation == memberRelation && e.SourceID == speakerID && e.Metadata == speaker {
+			return entityName
+		}
+	}
+
+	if _, err := a.memory.AddEdge(speakerID, groupID, memberRelation, 1.0, speaker); err != nil {
+		logger.Warn("failed to record group member", "error", err, "session", sessionID)
+	} else {
+		logger.Info("group member recorded", "session", sessionID, "speaker", speaker, "entity", entityName)
+	}
+	return entityName
+}
+
+// resolveSpeaker finds the entity of a group member by the display name used in the transcript
+func (a *Agent) resolveSpeaker(sessionID, name string) int64 {
+	groupID := a.getOrCreateUserEntity(sessionID)
+	if groupID == 0 {
+		return 0
+	}
+
+	edges, err := a.memory.GetEdgesTo(groupID)
+	if err != nil {
+		return 0
+	}
+	for _, e := range edges {
+		if e.Relation == memberRelation && strings.EqualFold(e.Metadata, name) {
+			return e.SourceID
+		}
+	}
+	return 0
+}
+
+// Observe records a group message Sheldon wasn't addressed in, so later replies
+// and end-of-day extraction still see what was said and by whom
+func (a *Agent) Observe(sessionID string, userID int64, speaker, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if !a.begin() {
+		return
+	}
+	defer a.end()
+
+	if userID != 0 {
+		a.getOrCreateSpeakerEntity(sessionID, userID, speaker)
+	}
+	line := attributeSpeaker(speaker, text)
+
+	if a.convo != nil {
+		if _, err := a.convo.Add(sessionID, "user", line); err != nil {
+			logger.Warn("failed to save observed message", "error", err)
+		}
+	}
+	if err := a.memory.AddDailyMessage(sessionID, "user", line); err != nil {
+		logger.Warn("failed to save observed message to daily storage", "error", err)
+	}
+
+	// keep a live session in step; a busy one picks it up from history next time
+	a.sessions.Get(sessionID).AddIdleMessage("user", line)
+}
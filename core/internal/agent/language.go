@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/locale"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// languageField is the fact a chat's preferred language is kept in, as an
+// English name like "German"
+const languageField = "preferred_language"
+
+// languageTTL is how long a chat's language is cached before it's read from
+// memory again, which picks up preferences saved with save_memory
+const languageTTL = 10 * time.Minute
+
+// chatLanguage is what's known about the language a chat is written in, and
+// how it likes units, times and money written
+type chatLanguage struct {
+	code      string // preferred language, empty until one is detected or saved
+	candidate string // a different language seen once, adopted if it's seen again
+	prefs     locale.Prefs
+	loaded    time.Time
+}
+
+// sessionLanguage returns the chat's preferred language code, empty when
+// none is known yet
+func (a *Agent) sessionLanguage(sessionID string) string {
+	a.langMu.Lock()
+	defer a.langMu.Unlock()
+	return a.chatLanguage(sessionID).code
+}
+
+// chatLanguage returns the cached state for a chat, reading the preference
+// from memory when it's missing or stale. Callers hold langMu.
+func (a *Agent) chatLanguage(sessionID string) *chatLanguage {
+	if a.languages == nil {
+		a.languages = make(map[string]*chatLanguage)
+	}
+	state, ok := a.languages[sessionID]
+	if ok && time.Since(state.loaded) < languageTTL {
+		return state
+	}
+	if !ok {
+		state = &chatLanguage{}
+		a.languages[sessionID] = state
+	}
+	state.loaded = time.Now()
+
+	entityID := a.getOrCreateUserEntity(sessionID)
+	if entityID == 0 {
+		return state
+	}
+	facts, err := a.memory.GetFactsByEntity(entityID)
+	if err != nil {
+		logger.Warn("failed to read language preference", "session", sessionID, "error", err)
+		return state
+	}
+	// units, clock and currency saved with save_memory, e.g. units: imperial
+	state.prefs = locale.Prefs{}
+	for _, f := range facts {
+		state.prefs.Set(f.Field, f.Value)
+	}
+
+	// save_memory may have named the field differently ("language",
+	// "language_preference"); any language fact with a known value counts
+	for _, f := range facts {
+		if !strings.Contains(f.Field, "language") {
+			continue
+		}
+		if code := locale.Code(f.Value); code != "" {
+			state.code = code
+			if f.Field == languageField {
+				break
+			}
+		}
+	}
+	return state
+}
+
+// sessionPrefs returns how a chat likes dates, units and money written, from
+// the preferences saved in memory
+func (a *Agent) sessionPrefs(sessionID string) locale.Prefs {
+	a.langMu.Lock()
+	defer a.langMu.Unlock()
+	state := a.chatLanguage(sessionID)
+	prefs := state.prefs
+	prefs.Lang = state.code
+	return prefs
+}
+
+// noteLanguage detects the language of a user message and saves it as the
+// chat's preference. One message in another language isn't enough to switch;
+// quoting a foreign phrase or pasting an error shouldn't change the replies.
+func (a *Agent) noteLanguage(ctx context.Context, sessionID, message string) {
+	lang, confident := locale.Detect(ownWords(message))
+	if !confident {
+		return
+	}
+
+	a.langMu.Lock()
+	state := a.chatLanguage(sessionID)
+	switch {
+	case lang == state.code:
+		state.candidate = ""
+		a.langMu.Unlock()
+		return
+	case state.code != "" && state.candidate != lang:
+		state.candidate = lang
+		a.langMu.Unlock()
+		return
+	}
+	previous := state.code
+	state.code, state.candidate = lang, ""
+	a.langMu.Unlock()
+
+	entityID := a.getOrCreateUserEntity(sessionID)
+	if entityID == 0 {
+		return
+	}
+	if _, err := a.memory.AddFactWithContext(ctx, &entityID, sheldonmem.DomainSlugToID["preferences"], languageField, locale.Name(lang), 0.9, false); err != nil {
+		logger.Warn("failed to save language preference", "session", sessionID, "error", err)
+		return
+	}
+	logger.Info("chat language detected", "session", sessionID, "language", lang, "previous", previous)
+}
+
+// ownWords drops the quoted and bracketed context the bots put around a
+// message, leaving what the user actually typed
+func ownWords(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "[") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// languagePrompt tells the model which language to answer in, empty for
+// English so the default prompt stays as it was
+func languagePrompt(lang string) string {
+	if lang == "" || lang == locale.English {
+		return ""
+	}
+	name := locale.Name(lang)
+	return fmt.Sprintf("## Language\nThe user writes in %s. Reply in the language of their latest message, and in %s when there isn't one, as with reminders and check-ins. Write dates, times and numbers the way %s speakers do.", name, name, name)
+}
+
+// unitsPrompt passes on the units, clock and currency the user asked for, so
+// replies the model writes itself, like a weather summary, follow them too.
+// Empty when nothing was set.
+func unitsPrompt(prefs locale.Prefs) string {
+	var wants []string
+	if prefs.Imperial {
+		wants = append(wants, "imperial units (miles, °F, pounds)")
+	}
+	switch prefs.Clock {
+	case 12:
+		wants = append(wants, "a 12-hour clock")
+	case 24:
+		wants = append(wants, "a 24-hour clock")
+	}
+	if prefs.Currency != "" {
+		wants = append(wants, "prices in "+prefs.Currency+" (convert only with a current exchange rate, otherwise keep the original currency)")
+	}
+	if len(wants) == 0 {
+		return ""
+	}
+	return "## Units\nThe user prefers " + strings.Join(wants, ", ") + ". Use these in replies, converting from what tools and websites give you."
+}
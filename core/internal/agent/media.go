@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// mediaArchiveTimeout bounds uploading, tagging and recording one file
+const mediaArchiveTimeout = 2 * time.Minute
+
+const mediaTagPrompt = `You tag photos for a personal archive so they can be found later by searching.
+Reply with only a JSON object:
+{"caption": "one sentence saying what the image shows, including any names, dates, places, flight or order numbers visible in it", "tags": ["3 to 8 short lowercase tags, e.g. receipt, boarding pass, berlin, dog"]}`
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// archiveUploads stores photos and files sent in chat in the user's bucket
+// and records them in the media library, for sessions that turned archiving
+// on with set_media_archive. Images are captioned and tagged by the model
+// when it has vision. It runs in the background and returns the paths being
+// archived.
+func (a *Agent) archiveUploads(sessionID string, ownerID int64, text string, media []llm.MediaContent) []string {
+	if a.archiver == nil || a.runtimeConfig == nil || ownerID == 0 || !a.runtimeConfig.SessionMediaArchive(sessionID) {
+		return nil
+	}
+
+	model := a.llmFor(sessionID)
+	now := time.Now()
+
+	var paths []string
+	for i, m := range media {
+		name := archivePath(m, now, i)
+		if !a.begin() {
+			return paths
+		}
+		paths = append(paths, name)
+		go func(m llm.MediaContent, name string) {
+			defer a.end()
+
+			ctx, cancel := context.WithTimeout(context.Background(), mediaArchiveTimeout)
+			defer cancel()
+
+			if err := a.archiver(ctx, name, m.Data, m.MimeType); err != nil {
+				logger.Warn("media archive upload failed", "error", err, "path", name)
+				return
+			}
+
+			caption, tags := tagMedia(ctx, model, m, text)
+			item, err := a.memory.AddMedia(ctx, ownerID, name, m.Filename, m.MimeType, caption, tags)
+			if err != nil {
+				logger.Warn("media archive indexing failed", "error", err, "path", name)
+				return
+			}
+			logger.Info("media archived", "id", item.ID, "path", name, "tags", len(item.Tags))
+		}(m, name)
+	}
+	return paths
+}
+
+// archivePath files uploads by month, e.g. media/2026/03/20260314-091502-pass.jpg
+func archivePath(m llm.MediaContent, now time.Time, i int) string {
+	name := unsafeNameChars.ReplaceAllString(path.Base(m.Filename), "_")
+	if m.Filename == "" || name == "." || name == "_" {
+		ext := ".jpg"
+		switch m.Type {
+		case llm.MediaTypeVideo:
+			ext = ".mp4"
+		case llm.MediaTypePDF:
+			ext = ".pdf"
+		case llm.MediaTypeDocument:
+			ext = ".docx"
+		}
+		name = fmt.Sprintf("%s-%d%s", m.Type, i+1, ext)
+	}
+	return fmt.Sprintf("media/%s/%s-%s", now.Format("2006/01"), now.Format("20060102-150405"), name)
+}
+
+// tagMedia captions an upload. The user's message usually says what it is;
+// images also get a description from the model when it can see them.
+func tagMedia(ctx context.Context, model llm.LLM, m llm.MediaContent, text string) (string, []string) {
+	caption := strings.TrimSpace(text)
+	if caption == "" {
+		caption = m.Filename
+	}
+	tags := []string{string(m.Type)}
+
+	if m.Type != llm.MediaTypeImage || model == nil || !model.Capabilities().Vision {
+		return caption, tags
+	}
+
+	response, err := model.Chat(ctx, mediaTagPrompt, []llm.Message{{Role: "user", Content: "Tag this image.", Media: []llm.MediaContent{m}}})
+	if err != nil {
+		logger.Warn("media tagging failed", "error", err)
+		return caption, tags
+	}
+
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return caption, tags
+	}
+	var result struct {
+		Caption string   `json:"caption"`
+		Tags    []string `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &result); err != nil {
+		logger.Debug("media tags unparseable", "error", err)
+		return caption, tags
+	}
+
+	if result.Caption != "" {
+		if caption != "" && caption != m.Filename {
+			caption = result.Caption + " (" + caption + ")"
+		} else {
+			caption = result.Caption
+		}
+	}
+	return caption, append(tags, result.Tags...)
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// Pin saves a message the user pinned as a fact, word for word, instead of
+// leaving it to end-of-day extraction to decide what was worth keeping.
+// author is who wrote the message, empty when it was the user themselves.
+func (a *Agent) Pin(ctx context.Context, sessionID, author, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("message has no text to pin")
+	}
+	if !a.begin() {
+		return fmt.Errorf("shutting down")
+	}
+	defer a.end()
+
+	entityID := a.getOrCreateUserEntity(sessionID)
+	if entityID == 0 {
+		return fmt.Errorf("no memory for session %s", sessionID)
+	}
+
+	field := "pinned_" + time.Now().Format("2006-01-02_15:04:05")
+	fact, err := a.memory.PinFact(ctx, entityID, sheldonmem.DomainSlugToID["knowledge"], field, attributeSpeaker(author, text))
+	if err != nil {
+		return fmt.Errorf("pin message: %w", err)
+	}
+
+	logger.Info("message pinned", "session", sessionID, "fact", fact.ID, "chars", len(text))
+	return nil
+}
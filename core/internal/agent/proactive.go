@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// ProactiveKeywordPrefix marks one-time check-ins Sheldon scheduled itself
+// from what it knows, as opposed to crons the user asked for
+const ProactiveKeywordPrefix = "checkin-"
+
+// proactivity is how far ahead a level looks and how many of its own
+// check-ins it keeps pending at once
+type proactivity struct {
+	horizonDays int
+	maxPending  int
+	goals       bool // also nudge on goals that have no date
+}
+
+var proactivityLevels = map[string]proactivity{
+	"low":    {horizonDays: 3, maxPending: 1},
+	"normal": {horizonDays: 7, maxPending: 3, goals: true},
+	"high":   {horizonDays: 14, maxPending: 6, goals: true},
+}
+
+// domains that hold deadlines, plans and dates worth checking in about
+var proactiveDomains = []string{"events", "goals", "career", "health", "finances", "relationships"}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+const checkinPrompt = `You plan proactive check-ins for a personal assistant. You get today's date, what the assistant knows about the user, and the check-ins already planned.
+
+Pick moments where a short, unprompted message would genuinely help: the day before or morning of a deadline, an appointment, an exam, a trip, a birthday, or progress on a goal. Skip anything already in the past, already covered by a planned check-in, or too vague to act on. Only use times within the horizon, during waking hours. Fewer is better; return an empty list when nothing stands out.
+
+Respond with JSON only:
+{"checkins": [{"topic": "thesis deadline", "at": "2026-03-14 09:00", "reason": "thesis is due on the 15th"}]}`
+
+// PlanCheckins reads upcoming events, deadlines and goals from each user's
+// memory and schedules one-time check-ins for them, as many as the user's
+// proactivity level allows
+func (r *CronRunner) PlanCheckins(ctx context.Context) error {
+	if r.agent == nil {
+		return nil
+	}
+	if !r.agent.begin() {
+		return errShuttingDown
+	}
+	defer r.agent.end()
+
+	users, err := r.memory.FindEntitiesByType("user")
+	if err != nil {
+		return fmt.Errorf("load users: %w", err)
+	}
+
+	for _, user := range users {
+		chatID, ok := userChatID(user.Name)
+		if !ok {
+			continue
+		}
+		if err := r.planUserCheckins(ctx, user.ID, chatID); err != nil {
+			logger.Warn("check-in planning failed", "error", err, "entity", user.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r *CronRunner) planUserCheckins(ctx context.Context, ownerID, chatID int64) error {
+	sessionID := fmt.Sprintf("telegram:%d", chatID)
+	if r.resolveSession != nil {
+		sessionID = r.resolveSession(chatID)
+	}
+
+	levelName := config.DefaultProactivity
+	if r.agent.runtimeConfig != nil {
+		levelName = r.agent.runtimeConfig.SessionProactivity(sessionID)
+	}
+	level, ok := proactivityLevels[levelName]
+	if !ok {
+		return nil // off
+	}
+
+	// check-ins are planned on the chat's clock; Create reads the dated
+	// expression in the same timezone
+	loc := r.crons.Location(chatID)
+
+	existing, err := r.crons.GetByChat(chatID)
+	if err != nil {
+		return err
+	}
+	var planned []string
+	for _, c := range existing {
+		if strings.HasPrefix(c.Keyword, ProactiveKeywordPrefix) {
+			planned = append(planned, fmt.Sprintf("- %s at %s", c.Keyword, c.NextRun.In(loc).Format("2006-01-02 15:04")))
+		}
+	}
+	slots := level.maxPending - len(planned)
+	if slots <= 0 {
+		return nil
+	}
+
+	now := time.Now().In(loc)
+	known := r.checkinContext(ownerID, now, level)
+	if known == "" {
+		return nil
+	}
+
+	if len(planned) == 0 {
+		planned = append(planned, "(none)")
+	}
+	goals := "Only dated things count; skip goals without a date."
+	if level.goals {
+		goals = "Goals without a date may get an occasional progress check-in."
+	}
+	input := fmt.Sprintf("Today: %s\nHorizon: the next %d days\nMost new check-ins: %d\n%s\n\nAlready planned:\n%s\n\nKnown about the user:\n%s",
+		now.Format("Monday, 2006-01-02 15:04"), level.horizonDays, slots, goals, strings.Join(planned, "\n"), known)
+
+	response, err := r.agent.getLLM().Chat(ctx, checkinPrompt, []llm.Message{{Role: "user", Content: input}})
+	if err != nil {
+		return err
+	}
+
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return fmt.Errorf("no JSON object found")
+	}
+	var result struct {
+		Checkins []struct {
+			Topic  string `json:"topic"`
+			At     string `json:"at"`
+			Reason string `json:"reason"`
+		} `json:"checkins"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &result); err != nil {
+		return err
+	}
+
+	horizon := now.AddDate(0, 0, level.horizonDays)
+	for _, c := range result.Checkins {
+		if slots == 0 {
+			break
+		}
+
+		at, err := time.ParseInLocation("2006-01-02 15:04", c.At, loc)
+		if err != nil || !at.After(now) || at.After(horizon) {
+			continue
+		}
+		slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(c.Topic), "-"), "-")
+		if slug == "" {
+			continue
+		}
+		keyword := ProactiveKeywordPrefix + slug
+		if dup, err := r.crons.GetByKeyword(keyword, chatID); err != nil || dup != nil {
+			continue
+		}
+
+		// a dated cron expression fires once, the expiry deletes it afterwards
+		schedule := fmt.Sprintf("0 %d %d %d %d *", at.Minute(), at.Hour(), at.Day(), int(at.Month()))
+		expiry := at.Add(time.Hour)
+		if _, err := r.crons.Create(keyword, schedule, chatID, &expiry); err != nil {
+			logger.Warn("failed to schedule check-in", "error", err, "keyword", keyword)
+			continue
+		}
+		slots--
+		logger.Info("check-in scheduled", "keyword", keyword, "chat", chatID, "at", at, "reason", c.Reason)
+	}
+
+	return nil
+}
+
+// checkinContext lists the facts and birthdays a check-in could be about
+func (r *CronRunner) checkinContext(ownerID int64, now time.Time, level proactivity) string {
+	var sb strings.Builder
+
+	for _, slug := range proactiveDomains {
+		if slug == "goals" && !level.goals {
+			continue
+		}
+		facts, err := r.memory.GetOwnedFacts(ownerID, sheldonmem.DomainSlugToID[slug])
+		if err != nil {
+			logger.Warn("failed to load facts for check-ins", "error", err, "domain", slug)
+			continue
+		}
+		for _, f := range facts {
+			if f.Sensitive {
+				continue
+			}
+			fmt.Fprintf(&sb, "- [%s] %s: %s (noted %s)\n", slug, f.Field, f.Value, f.CreatedAt.In(now.Location()).Format("2006-01-02"))
+		}
+	}
+
+	birthdays, err := r.memory.UpcomingBirthdays(ownerID, now, level.horizonDays)
+	if err != nil {
+		logger.Warn("failed to load birthdays for check-ins", "error", err)
+	}
+	for _, b := range birthdays {
+		fmt.Fprintf(&sb, "- [birthday] %s on %s\n", b.Contact.Name, b.Date.Format("2006-01-02"))
+	}
+
+	return sb.String()
+}
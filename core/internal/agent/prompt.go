@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/persona"
+	"github.com/bowerhall/sheldon/internal/tools"
+)
+
+// essence layout, assembled in this order
+const (
+	soulFile       = "SOUL.md"  // baseline personality, required; a session's persona replaces it
+	toolsFile      = "TOOLS.md" // tool guidance, optional
+	fragmentsDir   = "prompt.d" // extra fragments, ordered by file name (10-style.md, 20-work.md)
+	skillPromptDir = "skills"   // <skill>.md, included while that skill is installed
+)
+
+// loadPromptLayers reads the essence directory. A missing SOUL.md yields an
+// empty baseline; healthCheck refuses to start in that case.
+func loadPromptLayers(essencePath string) []promptLayer {
+	var layers []promptLayer
+
+	for _, name := range []string{soulFile, toolsFile} {
+		if layer, ok := readPromptLayer(filepath.Join(essencePath, name), name, ""); ok {
+			layers = append(layers, layer)
+		}
+	}
+
+	for _, path := range promptFiles(filepath.Join(essencePath, fragmentsDir)) {
+		name := filepath.Join(fragmentsDir, filepath.Base(path))
+		if layer, ok := readPromptLayer(path, name, ""); ok {
+			layers = append(layers, layer)
+		}
+	}
+
+	for _, path := range promptFiles(filepath.Join(essencePath, skillPromptDir)) {
+		skill := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		name := filepath.Join(skillPromptDir, filepath.Base(path))
+		if layer, ok := readPromptLayer(path, name, skill); ok {
+			layers = append(layers, layer)
+		}
+	}
+
+	return layers
+}
+
+// promptFiles lists the markdown files in dir sorted by name
+func promptFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("failed to read prompt fragments", "dir", dir, "error", err)
+		}
+		return nil
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func readPromptLayer(path, name, skill string) (promptLayer, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("failed to read prompt layer", "path", path, "error", err)
+		}
+		return promptLayer{}, false
+	}
+
+	text := strings.TrimSpace(string(content))
+	if text == "" {
+		return promptLayer{}, false
+	}
+	return promptLayer{name: name, skill: skill, content: text}, true
+}
+
+// ReloadPrompt re-reads the essence directory so edits apply without a
+// restart, and returns the layers in use
+func (a *Agent) ReloadPrompt() []string {
+	layers := loadPromptLayers(a.essencePath)
+	personas := loadPersonas(a.essencePath)
+
+	a.mu.Lock()
+	a.promptLayers = layers
+	a.personas = personas
+	a.mu.Unlock()
+
+	var names []string
+	for _, l := range layers {
+		if l.skill == "" || a.skillInstalled(l.skill) {
+			names = append(names, l.name)
+		}
+	}
+	logger.Info("system prompt reloaded", "layers", names)
+
+	return names
+}
+
+// staticPrompt joins the essence layers, with the session's persona in place
+// of SOUL.md. Skill layers are skipped for skills that aren't installed so
+// installing or removing one applies immediately.
+func (a *Agent) staticPrompt(sessionID string) string {
+	a.mu.RLock()
+	layers := a.promptLayers
+	personas := a.personas
+	a.mu.RUnlock()
+
+	var soul string
+	if a.runtimeConfig != nil && sessionID != "" {
+		if name, ok := a.runtimeConfig.SessionPersona(sessionID); ok {
+			if p, ok := personas[name]; ok {
+				soul = p.Prompt()
+			} else {
+				logger.Warn("session persona not found, using SOUL.md", "session", sessionID, "persona", name)
+			}
+		}
+	}
+
+	parts := make([]string, 0, len(layers))
+	for _, l := range layers {
+		if l.skill != "" && !a.skillInstalled(l.skill) {
+			continue
+		}
+		if l.name == soulFile && soul != "" {
+			parts = append(parts, soul)
+			continue
+		}
+		parts = append(parts, l.content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// loadPersonas keeps going without personas when a file is broken, the
+// error is logged and SOUL.md still works
+func loadPersonas(essencePath string) map[string]*persona.Persona {
+	personas, err := persona.Load(essencePath)
+	if err != nil {
+		logger.Warn("failed to load personas", "error", err)
+		return nil
+	}
+	return personas
+}
+
+// skillInstalled matches both layouts: a single skill file or a directory
+func (a *Agent) skillInstalled(name string) bool {
+	if a.skillsDir == "" {
+		return false
+	}
+	for _, candidate := range []string{name, name + ".md", strings.ToUpper(name) + ".md"} {
+		if _, err := os.Stat(filepath.Join(a.skillsDir, candidate)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// userAddendum is the current user's standing instructions, saved with set_prompt_addendum
+func (a *Agent) userAddendum(ctx context.Context) string {
+	entity, err := a.memory.FindEntityByName(tools.UserEntityName(ctx))
+	if err != nil || entity == nil {
+		return ""
+	}
+
+	facts, err := a.memory.GetFactsByEntity(entity.ID)
+	if err != nil {
+		logger.Warn("failed to load prompt addendum", "entity", entity.Name, "error", err)
+		return ""
+	}
+	for _, f := range facts {
+		if f.Field == tools.PromptAddendumField {
+			return fmt.Sprintf("## Standing Instructions From This User\n%s", f.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/quality"
+)
+
+// evaluationTimeout bounds scoring one reply
+const evaluationTimeout = time.Minute
+
+// evaluateTurn has the evaluator score a reply in the background, with the
+// tool results from the turn as the evidence it should be grounded in
+func (a *Agent) evaluateTurn(sessionID, request, response string, messages []llm.Message) {
+	if a.evaluator == nil || strings.TrimSpace(response) == "" {
+		return
+	}
+	if a.evalSample < 1 && rand.Float64() >= a.evalSample {
+		return
+	}
+
+	model := a.llmFor(sessionID)
+	turn := quality.Turn{
+		SessionID: sessionID,
+		Provider:  model.Provider(),
+		Model:     model.Model(),
+		Request:   request,
+		Response:  response,
+	}
+	names := make(map[string]string)
+	for _, m := range messages {
+		for _, tc := range m.ToolCalls {
+			names[tc.ID] = tc.Name
+		}
+		if m.Role == "tool" {
+			turn.Tools = append(turn.Tools, quality.ToolResult{Name: names[m.ToolCallID], Output: m.Content})
+		}
+	}
+
+	if !a.begin() {
+		return
+	}
+	go func() {
+		defer a.end()
+
+		ctx, cancel := context.WithTimeout(context.Background(), evaluationTimeout)
+		defer cancel()
+
+		score, err := a.evaluator.Evaluate(ctx, turn)
+		if err != nil {
+			logger.Warn("response evaluation failed", "session", sessionID, "error", err)
+			return
+		}
+		logger.Info("response evaluated", "session", sessionID, "model", turn.Provider+"/"+turn.Model,
+			"instruction_following", score.InstructionFollowing, "hallucination_risk", score.HallucinationRisk, "flagged", score.Flagged())
+	}()
+}
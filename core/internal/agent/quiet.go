@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// quietPeriod is a stretch when nothing should be sent unprompted
+type quietPeriod struct {
+	until    time.Time
+	vacation bool
+}
+
+// quietPeriod reports whether now falls in vacation mode or the daily quiet
+// hours in loc, and when that ends. Vacation wins when both apply.
+func (r *CronRunner) quietPeriod(now time.Time, loc *time.Location) (quietPeriod, bool) {
+	if r.agent == nil || r.agent.runtimeConfig == nil {
+		return quietPeriod{}, false
+	}
+	rc := r.agent.runtimeConfig
+
+	if until, ok := rc.VacationUntil(); ok {
+		return quietPeriod{until: until, vacation: true}, true
+	}
+	if quiet, ok := rc.QuietHours(); ok {
+		if until, ok := quiet.Until(now.In(loc)); ok {
+			return quietPeriod{until: until}, true
+		}
+	}
+	return quietPeriod{}, false
+}
+
+// hold keeps a due cron from firing during a quiet period. Quiet hours defer it
+// to the end of the window. Vacation defers one-time crons to the return date
+// and skips recurring ones ahead to their first run after it.
+func (r *CronRunner) hold(c cron.Cron, q quietPeriod) {
+	oneTime := r.isOneTime(c)
+
+	if q.vacation && !oneTime {
+		next, err := r.crons.NextRunAfter(c.Schedule, c.ChatID, q.until)
+		if err != nil {
+			logger.Error("failed to compute next run", "schedule", c.Schedule, "error", err)
+			return
+		}
+		if err := r.crons.UpdateNextRun(c.ID, next); err != nil {
+			logger.Error("failed to skip cron during vacation", "id", c.ID, "error", err)
+			return
+		}
+		logger.Info("cron skipped for vacation", "keyword", c.Keyword, "next", next)
+		return
+	}
+
+	if err := r.crons.Postpone(c.ID, q.until, oneTime); err != nil {
+		logger.Error("failed to postpone cron", "id", c.ID, "error", err)
+		return
+	}
+	logger.Info("cron postponed for quiet period", "keyword", c.Keyword, "until", q.until, "vacation", q.vacation)
+}
+
+// isOneTime detects one-time crons the way reschedule does, by an expiry that
+// comes before the next computed run
+func (r *CronRunner) isOneTime(c cron.Cron) bool {
+	if c.ExpiresAt == nil {
+		return false
+	}
+	next, err := r.crons.ComputeNextRun(c.Schedule, c.ChatID)
+	return err == nil && c.OneTime(next)
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// ReconcileMemory looks for facts that contradict each other under different
+// fields and asks each user which one is current
+func (a *Agent) ReconcileMemory(ctx context.Context) error {
+	if a.conflictSender == nil {
+		return nil
+	}
+	if !a.begin() {
+		return errShuttingDown
+	}
+	defer a.end()
+
+	users, err := a.memory.FindEntitiesByType("user")
+	if err != nil {
+		return fmt.Errorf("load users: %w", err)
+	}
+
+	adapter := &llmAdapter{llm: a.getLLM()}
+	for _, user := range users {
+		chatID, ok := userChatID(user.Name)
+		if !ok {
+			continue
+		}
+
+		conflicts, err := a.memory.FindContradictions(ctx, adapter, user.ID)
+		if err != nil {
+			logger.Warn("contradiction check failed", "error", err, "entity", user.Name)
+		}
+
+		for _, c := range conflicts {
+			message := fmt.Sprintf("I have two things on record that don't seem to agree (%s):\n\n1. %s: %s\n2. %s: %s\n\nWhich one is current?",
+				c.Reason, c.A.Field, c.A.Value, c.B.Field, c.B.Value)
+			if err := a.conflictSender(chatID, a.entityUser(user.Name, chatID), user.Name, message, c.ID); err != nil {
+				logger.Warn("failed to ask about contradiction", "error", err, "conflict", c.ID)
+				continue
+			}
+			logger.Info("contradiction found", "conflict", c.ID, "entity", user.Name, "a", c.A.Field, "b", c.B.Field)
+		}
+	}
+
+	return nil
+}
+
+// userChatID extracts the chat ID from a user entity name ("user_<provider>_<chatID>")
+func userChatID(entityName string) (int64, bool) {
+	rest, ok := strings.CutPrefix(entityName, "user_")
+	if !ok {
+		return 0, false
+	}
+	_, id, ok := strings.Cut(rest, "_")
+	if !ok {
+		return 0, false
+	}
+	chatID, err := strconv.ParseInt(id, 10, 64)
+	return chatID, err == nil
+}
+
+// rememberSessionUser records who a private session belongs to. Chat and user
+// IDs differ on some providers (a Discord DM is a channel), so this is how a
+// prompt sent to a session knows whose button presses to accept.
+func (a *Agent) rememberSessionUser(sessionID string, userID int64) {
+	a.usersMu.Lock()
+	defer a.usersMu.Unlock()
+
+	if a.sessionUsers == nil {
+		a.sessionUsers = make(map[string]int64)
+	}
+	a.sessionUsers[sessionID] = userID
+}
+
+// entityUser returns the user a user entity belongs to. Speaker entities are
+// named after the user ID; session entities use the user last seen in that
+// session and otherwise the chat ID, which is the user ID in private chats.
+func (a *Agent) entityUser(entityName string, chatID int64) int64 {
+	rest, _ := strings.CutPrefix(entityName, "user_")
+	provider, _, _ := strings.Cut(rest, "_")
+
+	a.usersMu.Lock()
+	defer a.usersMu.Unlock()
+	if userID, ok := a.sessionUsers[provider+":"+strconv.FormatInt(chatID, 10)]; ok {
+		return userID
+	}
+	return chatID
+}
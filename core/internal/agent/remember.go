@@ -23,8 +23,12 @@ func (r *entityResolver) GetSheldonEntityID() int64 {
 	return r.agent.getSheldonEntityID()
 }
 
-func (r *entityResolver) GetOrCreateNamedEntity(name, entityType string) int64 {
-	return r.agent.getOrCreateNamedEntity(name, entityType)
+func (r *entityResolver) GetOrCreateNamedEntity(name, entityType string, ownerID int64) int64 {
+	return r.agent.getOrCreateNamedEntity(name, entityType, ownerID)
+}
+
+func (r *entityResolver) ResolveSpeaker(sessionID, name string) int64 {
+	return r.agent.resolveSpeaker(sessionID, name)
 }
 
 func (r *entityResolver) ResolveEntityID(name, sessionID string, userID, sheldonID int64) int64 {
@@ -59,7 +63,10 @@ func (a *Agent) getOrCreateUserEntity(sessionID string) int64 {
 	if len(parts) == 2 {
 		entityName = fmt.Sprintf("user_%s_%s", parts[0], parts[1])
 	}
+	return a.getOrCreateUserEntityNamed(entityName)
+}
 
+func (a *Agent) getOrCreateUserEntityNamed(entityName string) int64 {
 	entity, err := a.memory.FindEntityByName(entityName)
 	if err == nil && entity != nil {
 		return entity.ID
@@ -71,6 +78,11 @@ func (a *Agent) getOrCreateUserEntity(sessionID string) int64 {
 		return 0
 	}
 
+	// a user owns their own entity so their facts stay out of other users' recall
+	if err := a.memory.SetEntityOwner(entity.ID, entity.ID); err != nil {
+		logger.Error("failed to scope user entity", "error", err, "id", entity.ID)
+	}
+
 	logger.Info("user entity created", "name", entityName, "id", entity.ID)
 	return entity.ID
 }
@@ -83,11 +95,16 @@ func (a *Agent) resolveEntityID(name, sessionID string, userID, sheldonID int64)
 	if lower == "sheldon" || lower == "assistant" {
 		return sheldonID
 	}
-	return a.getOrCreateNamedEntity(name, "person")
+	if id := a.resolveSpeaker(sessionID, name); id != 0 {
+		return id
+	}
+	return a.getOrCreateNamedEntity(name, "person", userID)
 }
 
-func (a *Agent) getOrCreateNamedEntity(name, entityType string) int64 {
-	entity, err := a.memory.FindEntityByName(name)
+// getOrCreateNamedEntity resolves a person, place or organization within the
+// owner's namespace, so two users' "Mom" don't end up as the same entity
+func (a *Agent) getOrCreateNamedEntity(name, entityType string, ownerID int64) int64 {
+	entity, err := a.memory.FindOwnedEntityByName(name, ownerID)
 	if err == nil && entity != nil {
 		return entity.ID
 	}
@@ -99,7 +116,7 @@ func (a *Agent) getOrCreateNamedEntity(name, entityType string) int64 {
 		domainID = 7 // career domain
 	}
 
-	entity, err = a.memory.CreateEntity(name, entityType, domainID, "")
+	entity, err = a.memory.CreateOwnedEntity(name, entityType, domainID, "", ownerID)
 	if err != nil || entity == nil {
 		logger.Error("failed to create entity", "error", err, "name", name)
 		return 0
@@ -113,6 +130,11 @@ func (a *Agent) getOrCreateNamedEntity(name, entityType string) int64 {
 // This is triggered by a system cron at ~3am, or manually via force_extraction tool
 // If includeToday is true, also processes today's messages (for manual triggers)
 func (a *Agent) ProcessEndOfDay(ctx context.Context, includeToday bool) error {
+	if !a.begin() {
+		return errShuttingDown
+	}
+	defer a.end()
+
 	resolver := &entityResolver{agent: a}
 	adapter := &llmAdapter{llm: a.getLLM()}
 
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/session"
+)
+
+const sessionSweepInterval = 5 * time.Minute
+
+// RunSessionEviction drops sessions idle for longer than ttl, and the least
+// recently used ones beyond max, until ctx is done. History the conversation
+// buffer can't restore is summarized first, so an evicted chat picks up where
+// it left off on its next message.
+func (a *Agent) RunSessionEviction(ctx context.Context, ttl time.Duration, max int) {
+	if ttl <= 0 && max <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(sessionSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.evictSessions(ctx, ttl, max)
+		}
+	}
+}
+
+func (a *Agent) evictSessions(ctx context.Context, ttl time.Duration, max int) {
+	evicted := a.sessions.Sweep(ttl, max)
+	if len(evicted) == 0 {
+		return
+	}
+
+	for _, e := range evicted {
+		a.flushSession(ctx, e.ID, e.Session)
+
+		a.mu.Lock()
+		delete(a.sessionLLMs, e.ID)
+		a.mu.Unlock()
+	}
+
+	logger.Info("evicted idle sessions", "count", len(evicted), "remaining", len(a.sessions.List()))
+}
+
+// ClearContext forgets a session's conversation history after the current
+// turn: the in-memory session, the recent-message buffer and its summary.
+// Long-term memory is not touched.
+func (a *Agent) ClearContext(sessionID string) {
+	a.sessions.Get(sessionID).MarkForReset()
+}
+
+// flushSession saves a summary of a session that holds more than the
+// conversation buffer reloads. Shorter sessions are already fully buffered.
+func (a *Agent) flushSession(ctx context.Context, sessionID string, sess *session.Session) {
+	if a.convo == nil {
+		return
+	}
+
+	messages := sess.Messages()
+	if len(messages) <= a.convo.MaxMessages(sessionID) {
+		return
+	}
+
+	summary, err := a.summarizeHistory(ctx, a.getLLM(), messages)
+	if err != nil {
+		logger.Warn("failed to summarize evicted session", "session", sessionID, "error", err)
+		return
+	}
+	if summary == "" {
+		return
+	}
+	if err := a.convo.SaveSummary(sessionID, summary); err != nil {
+		logger.Warn("failed to save evicted session summary", "session", sessionID, "error", err)
+	}
+}
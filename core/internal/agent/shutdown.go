@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"errors"
+)
+
+// errShuttingDown is returned for work that arrives after Drain has started
+var errShuttingDown = errors.New("agent is shutting down")
+
+const shutdownReply = "I'm restarting right now. Please send that again in a minute."
+
+// begin registers in-flight work. Returns false once draining has started.
+func (a *Agent) begin() bool {
+	a.drainMu.Lock()
+	defer a.drainMu.Unlock()
+
+	if a.draining {
+		return false
+	}
+	a.inflight.Add(1)
+	a.active.Add(1)
+	return true
+}
+
+func (a *Agent) end() {
+	a.active.Add(-1)
+	a.inflight.Done()
+}
+
+// InFlight returns the number of requests currently being processed
+func (a *Agent) InFlight() int {
+	return int(a.active.Load())
+}
+
+// Drain stops accepting new messages and waits for in-flight agent loops,
+// including coder jobs running inside them, to finish. Returns ctx.Err()
+// if they are still running when ctx expires.
+func (a *Agent) Drain(ctx context.Context) error {
+	a.drainMu.Lock()
+	a.draining = true
+	a.drainMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *Agent) isDraining() bool {
+	a.drainMu.Lock()
+	defer a.drainMu.Unlock()
+	return a.draining
+}
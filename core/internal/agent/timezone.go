@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// timezoneField is the fact a chat's timezone is kept in, as an IANA name
+// like "Europe/Berlin"
+const timezoneField = "timezone"
+
+// timezoneTTL is how long a chat's timezone is cached before it's read from
+// memory again
+const timezoneTTL = 10 * time.Minute
+
+// chatTimezone is a chat's own timezone, nil when it uses the configured one
+type chatTimezone struct {
+	loc    *time.Location
+	loaded time.Time
+}
+
+// Timezone returns the timezone of a session's user, falling back to the
+// configured one. Members of a household can live in different places, so
+// reminders and check-ins follow each chat's own clock.
+func (a *Agent) Timezone(sessionID string) *time.Location {
+	a.tzMu.Lock()
+	defer a.tzMu.Unlock()
+
+	if a.timezones == nil {
+		a.timezones = make(map[string]*chatTimezone)
+	}
+	state, ok := a.timezones[sessionID]
+	if !ok || time.Since(state.loaded) >= timezoneTTL {
+		state = &chatTimezone{loc: a.loadTimezone(sessionID), loaded: time.Now()}
+		a.timezones[sessionID] = state
+	}
+	if state.loc != nil {
+		return state.loc
+	}
+	return a.timezone
+}
+
+// loadTimezone reads a chat's timezone from memory. Facts saved with
+// save_memory count too if their value is a zone name Go knows.
+func (a *Agent) loadTimezone(sessionID string) *time.Location {
+	entityID := a.getOrCreateUserEntity(sessionID)
+	if entityID == 0 {
+		return nil
+	}
+	facts, err := a.memory.GetFactsByEntity(entityID)
+	if err != nil {
+		logger.Warn("failed to read timezone", "session", sessionID, "error", err)
+		return nil
+	}
+
+	var found *time.Location
+	for _, f := range facts {
+		field := strings.ReplaceAll(f.Field, "_", "")
+		if !strings.Contains(field, "timezone") {
+			continue
+		}
+		if loc, err := time.LoadLocation(strings.TrimSpace(f.Value)); err == nil {
+			found = loc
+			if f.Field == timezoneField {
+				break
+			}
+		}
+	}
+	return found
+}
+
+// SetTimezone saves the timezone a session's user lives in and uses it from
+// now on
+func (a *Agent) SetTimezone(ctx context.Context, sessionID string, loc *time.Location) error {
+	entityID := a.getOrCreateUserEntity(sessionID)
+	if entityID == 0 {
+		return fmt.Errorf("no memory for session %s", sessionID)
+	}
+	if _, err := a.memory.AddFactWithContext(ctx, &entityID, sheldonmem.DomainSlugToID["preferences"], timezoneField, loc.String(), 1.0, false); err != nil {
+		return fmt.Errorf("save timezone: %w", err)
+	}
+
+	a.tzMu.Lock()
+	if a.timezones == nil {
+		a.timezones = make(map[string]*chatTimezone)
+	}
+	a.timezones[sessionID] = &chatTimezone{loc: loc, loaded: time.Now()}
+	a.tzMu.Unlock()
+
+	logger.Info("timezone set", "session", sessionID, "timezone", loc.String())
+	return nil
+}
@@ -1,7 +1,9 @@
 package agent
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bowerhall/sheldon/internal/alerts"
@@ -10,8 +12,12 @@ import (
 	"github.com/bowerhall/sheldon/internal/config"
 	"github.com/bowerhall/sheldon/internal/conversation"
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/persona"
+	"github.com/bowerhall/sheldon/internal/quality"
 	"github.com/bowerhall/sheldon/internal/session"
+	"github.com/bowerhall/sheldon/internal/speech"
 	"github.com/bowerhall/sheldon/internal/tools"
+	"github.com/bowerhall/sheldon/internal/tracing"
 	"github.com/bowerhall/sheldonmem"
 )
 
@@ -22,17 +28,68 @@ type ProcessOptions struct {
 	Media   []llm.MediaContent
 	Trusted bool  // if true, sensitive facts are accessible; if false, SafeMode is enabled
 	UserID  int64 // ID of the user who sent the message (for approval verification)
+
+	// Group marks a chat shared by several people. Messages are attributed to
+	// Speaker and memory is read and written on the speaker's own entity.
+	Group   bool
+	Speaker string
+
+	// OnStream receives partial response text as the model generates it.
+	// Text restarts on each LLM call, so a preamble before tool use is replaced by the next turn.
+	OnStream llm.StreamFunc
+
+	// OnProgress receives status lines during long tool runs, e.g.
+	// "still working: deploy app"
+	OnProgress tools.ProgressFunc
 }
 
 // TriggerFunc processes a system trigger through the agent loop and returns the response
-type TriggerFunc func(chatID int64, sessionID string, prompt string) (string, error)
+type TriggerFunc func(ctx context.Context, chatID int64, sessionID string, prompt string) (string, error)
+
+// LLMFactory creates an LLM instance for a provider and model from runtime config
+type LLMFactory func(provider, model string) (llm.LLM, error)
 
-// LLMFactory creates a new LLM instance based on current runtime config
-type LLMFactory func() (llm.LLM, error)
+// sessionLLM is the model a session switched to, keyed by provider:model so
+// a new switch_model call replaces it
+type sessionLLM struct {
+	key string
+	llm llm.LLM
+}
+
+// SessionFunc is notified of every session that sends a message
+type SessionFunc func(sessionID string)
 
 // ApprovalSender sends approval request buttons to the user
 type ApprovalSender func(chatID int64, message string, approvalID string) error
 
+// ConflictSender asks the user to resolve a contradiction between two remembered
+// facts of the owner entity. userID is who may answer it.
+type ConflictSender func(chatID, userID int64, owner, message string, conflictID int64) error
+
+// MediaArchiver stores an uploaded file under name in the user's storage bucket
+type MediaArchiver func(ctx context.Context, name string, data []byte, contentType string) error
+
+// Command is a slash command chat apps offer for autocomplete
+type Command struct {
+	Name        string
+	Description string
+	Skill       bool // skills take key=value arguments after the name
+}
+
+// promptLayer is one essence file in the system prompt. Skill layers only
+// apply while that skill is installed.
+type promptLayer struct {
+	name    string // path relative to the essence dir
+	skill   string
+	content string
+}
+
+// toolResult is the outcome of a single tool call in a parallel batch
+type toolResult struct {
+	output string
+	err    error
+}
+
 type Agent struct {
 	mu           sync.RWMutex
 	llm          llm.LLM
@@ -40,25 +97,66 @@ type Agent struct {
 	convo        *conversation.Store
 	sessions     *session.Store
 	tools        *tools.Registry
-	systemPrompt string
+	essencePath  string
+	promptLayers []promptLayer // guarded by mu, replaced by ReloadPrompt
+	personas     map[string]*persona.Persona
 	timezone     *time.Location
 	notify       NotifyFunc
 	budget       *budget.Tracker
+	health       *llm.HealthTracker
 	alerts       *alerts.Alerter
 	skillsDir    string
+	transcriber  speech.Transcriber
+	trackSession SessionFunc
+	tracer       *tracing.Tracer
 
 	llmFactory    LLMFactory
 	runtimeConfig *config.RuntimeConfig
 	lastLLMHash   string
+	sessionLLMs   map[string]*sessionLLM // per-session model overrides, built on first use
 
 	approvals      *approval.Manager
 	approvalSender ApprovalSender
+	conflictSender ConflictSender
+	archiver       MediaArchiver
+
+	evaluator  *quality.Evaluator
+	evalSample float64 // share of turns scored, 0-1
+
+	langMu    sync.Mutex
+	languages map[string]*chatLanguage // per-session language, built on first use
+
+	tzMu      sync.Mutex
+	timezones map[string]*chatTimezone // per-session timezone, built on first use
+
+	usersMu      sync.Mutex
+	sessionUsers map[string]int64 // who each private session belongs to
+
+	// graceful shutdown: in-flight requests are tracked so Drain can wait for them
+	drainMu  sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+	active   atomic.Int32
 }
 
 func (a *Agent) SetSkillsDir(dir string) {
 	a.skillsDir = dir
 }
 
+// SetSessionTracker registers a callback used to route replies back to the originating provider
+func (a *Agent) SetSessionTracker(fn SessionFunc) {
+	a.trackSession = fn
+}
+
+func (a *Agent) SetTranscriber(t speech.Transcriber) {
+	a.transcriber = t
+}
+
+// SetTracer enables OpenTelemetry spans for the agent loop, LLM calls and tools
+func (a *Agent) SetTracer(t *tracing.Tracer) {
+	a.tracer = t
+}
+
 func (a *Agent) SetConversationStore(store *conversation.Store) {
 	a.convo = store
 }
@@ -70,3 +168,19 @@ func (a *Agent) SetApprovalManager(mgr *approval.Manager) {
 func (a *Agent) SetApprovalSender(sender ApprovalSender) {
 	a.approvalSender = sender
 }
+
+func (a *Agent) SetConflictSender(sender ConflictSender) {
+	a.conflictSender = sender
+}
+
+// SetMediaArchiver enables archiving uploads for sessions that opt in
+func (a *Agent) SetMediaArchiver(archiver MediaArchiver) {
+	a.archiver = archiver
+}
+
+// SetEvaluator scores a share of replies (0-1, where 1 is every reply) for
+// quality once they're sent
+func (a *Agent) SetEvaluator(e *quality.Evaluator, sample float64) {
+	a.evaluator = e
+	a.evalSample = sample
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/watch"
+)
+
+// watchChangeLines keeps a redesigned page from flooding the chat
+const watchChangeLines = 8
+
+// EnableWatches lets watch-page crons check pages and report changes
+func (r *CronRunner) EnableWatches(store *watch.Store, fetcher *watch.Fetcher) {
+	r.watches = store
+	r.fetcher = fetcher
+}
+
+// checkWatch fetches a watched page and tells the user what changed. Page text is
+// untrusted, so like the feed digest it's sent as-is rather than through the agent loop.
+func (r *CronRunner) checkWatch(ctx context.Context, c cron.Cron, id int64) {
+	if r.watches == nil || r.fetcher == nil {
+		return
+	}
+
+	w, err := r.watches.Get(id)
+	if errors.Is(err, watch.ErrWatchNotFound) {
+		// the watch is gone, so is its cron
+		if err := r.crons.Delete(c.ID); err != nil {
+			logger.Error("failed to delete orphaned watch cron", "keyword", c.Keyword, "error", err)
+		}
+		return
+	}
+	if err != nil {
+		logger.Error("failed to load watch", "watch", id, "error", err)
+		return
+	}
+
+	text, err := r.fetcher.Fetch(ctx, w.URL, w.Selector)
+	if err != nil {
+		logger.Warn("watch check failed", "watch", w.ID, "url", w.URL, "error", err)
+		// only the first failure in a row is worth a message
+		if w.LastError == "" && r.notify != nil {
+			r.notify(w.ChatID, fmt.Sprintf("⚠️ Couldn't check %s: %s\nI'll keep trying.", w.Name(), err))
+		}
+		if err := r.watches.RecordCheck(w.ID, "", false, err); err != nil {
+			logger.Warn("failed to record watch check", "watch", w.ID, "error", err)
+		}
+		return
+	}
+
+	change := watch.Diff(w.Snapshot, text)
+	if !change.Empty() && r.notify != nil {
+		r.notify(w.ChatID, formatWatchChange(w, change))
+		logger.Info("watched page changed", "watch", w.ID, "added", len(change.Added), "removed", len(change.Removed))
+	}
+	if w.LastError != "" && change.Empty() && r.notify != nil {
+		r.notify(w.ChatID, fmt.Sprintf("✅ %s is reachable again, no changes.", w.Name()))
+	}
+
+	if err := r.watches.RecordCheck(w.ID, text, !change.Empty(), nil); err != nil {
+		logger.Warn("failed to record watch check", "watch", w.ID, "error", err)
+	}
+}
+
+func formatWatchChange(w *watch.Watch, change watch.Change) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔔 %s changed\n%s\n", w.Name(), w.URL)
+
+	writeLines := func(prefix string, lines []string) {
+		for i, line := range lines {
+			if i == watchChangeLines {
+				fmt.Fprintf(&b, "...and %d more\n", len(lines)-i)
+				break
+			}
+			fmt.Fprintf(&b, "%s %s\n", prefix, truncate(line, 200))
+		}
+	}
+
+	if len(change.Removed) > 0 {
+		b.WriteString("\nBefore:\n")
+		writeLines("-", change.Removed)
+	}
+	if len(change.Added) > 0 {
+		b.WriteString("\nNow:\n")
+		writeLines("+", change.Added)
+	}
+
+	return strings.TrimSpace(b.String())
+}
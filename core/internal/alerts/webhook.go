@@ -0,0 +1,164 @@
+package alerts
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// Event is a threshold breach (or its recovery) pushed by a homelab-agent
+type Event struct {
+	Host      string    `json:"host"`
+	Rule      string    `json:"rule"`
+	Subject   string    `json:"subject"`
+	Message   string    `json:"message"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Resolved  bool      `json:"resolved"`
+	Time      time.Time `json:"time"`
+}
+
+// TriggerFunc runs a prompt through the agent loop and returns what to tell the user
+type TriggerFunc func(ctx context.Context, prompt string) (string, error)
+
+// Receiver accepts agent alert webhooks and turns them into system triggers
+type Receiver struct {
+	token   string
+	trigger TriggerFunc
+	notify  NotifyFunc
+	server  *http.Server
+	ctx     context.Context
+}
+
+type webhookPayload struct {
+	Host   string  `json:"host"`
+	Events []Event `json:"events"`
+}
+
+// NewReceiver creates the webhook server on addr. Agents authenticate with the shared token.
+func NewReceiver(addr, token string, trigger TriggerFunc, notify NotifyFunc) (*Receiver, error) {
+	if token == "" {
+		return nil, fmt.Errorf("alert webhook requires HOMELAB_AGENT_TOKEN")
+	}
+
+	r := &Receiver{
+		token:   token,
+		trigger: trigger,
+		notify:  notify,
+		ctx:     context.Background(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /homelab/alerts", r.handleAlerts)
+
+	r.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+
+	return r, nil
+}
+
+// Start serves until ctx is cancelled
+func (r *Receiver) Start(ctx context.Context) error {
+	r.ctx = ctx
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("alert webhook listening", "addr", r.server.Addr)
+		if err := r.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		logger.Error("alert webhook failed", "error", err)
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return r.server.Shutdown(shutdownCtx)
+}
+
+func (r *Receiver) handleAlerts(w http.ResponseWriter, req *http.Request) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(r.token)) != 1 {
+		logger.Warn("rejecting unauthorized alert webhook", "remote", req.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var payload webhookPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64<<10)).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if len(payload.Events) == 0 {
+		http.Error(w, "no events", http.StatusBadRequest)
+		return
+	}
+	for i := range payload.Events {
+		if payload.Events[i].Host == "" {
+			payload.Events[i].Host = payload.Host
+		}
+	}
+
+	logger.Info("homelab alerts received", "host", payload.Host, "events", len(payload.Events))
+
+	// the agent loop can take a while, don't make the agent wait on it
+	go r.deliver(payload.Events)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// deliver asks the agent to explain the alerts, falling back to the raw text if that fails
+func (r *Receiver) deliver(events []Event) {
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Minute)
+	defer cancel()
+
+	response, err := r.trigger(ctx, AlertPrompt(events))
+	if err != nil {
+		logger.Error("alert trigger failed", "error", err)
+		response = FormatEvents(events)
+	}
+	if response != "" && r.notify != nil {
+		r.notify(response)
+	}
+}
+
+// FormatEvents renders events one per line
+func FormatEvents(events []Event) string {
+	var sb strings.Builder
+	for _, e := range events {
+		icon := "🚨"
+		if e.Resolved {
+			icon = "✅"
+		}
+		fmt.Fprintf(&sb, "%s [%s] %s\n", icon, e.Host, e.Message)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// AlertPrompt is the system trigger for a batch of homelab alerts
+func AlertPrompt(events []Event) string {
+	return fmt.Sprintf(`[HOMELAB ALERT]
+The homelab-agent reported threshold changes:
+%s
+
+Tell the user about this proactively and briefly. For new alerts, say what is wrong and what they could do about it;
+you may use remote_status, remote_processes, docker_disk_usage, list_containers, container_logs or service_logs to look closer first.
+Don't restart, stop or delete anything without asking. For resolved alerts, a one-line all-clear is enough.
+
+Respond naturally - the user will see your message.`, FormatEvents(events))
+}
@@ -0,0 +1,89 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReceiverRequiresToken(t *testing.T) {
+	if _, err := NewReceiver(":0", "", nil, nil); err == nil {
+		t.Fatal("expected an error without a token")
+	}
+}
+
+func TestReceiverHandleAlerts(t *testing.T) {
+	prompts := make(chan string, 1)
+	sent := make(chan string, 1)
+	r, err := NewReceiver(":0", "secret",
+		func(ctx context.Context, prompt string) (string, error) {
+			prompts <- prompt
+			return "your NAS is filling up", nil
+		},
+		func(message string) { sent <- message },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"host":"nas","events":[{"rule":"disk","subject":"/mnt/nas","message":"/mnt/nas is 93% full","value":93,"threshold":90}]}`
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{"wrong token", "nope", body, http.StatusUnauthorized},
+		{"bad json", "secret", "{", http.StatusBadRequest},
+		{"no events", "secret", `{"host":"nas","events":[]}`, http.StatusBadRequest},
+		{"accepted", "secret", body, http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/homelab/alerts", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			r.server.Handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	select {
+	case prompt := <-prompts:
+		if !strings.Contains(prompt, "[HOMELAB ALERT]") || !strings.Contains(prompt, "[nas] /mnt/nas is 93% full") {
+			t.Errorf("prompt = %q", prompt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("trigger not called")
+	}
+	if msg := <-sent; msg != "your NAS is filling up" {
+		t.Errorf("sent %q", msg)
+	}
+}
+
+func TestReceiverFallsBackToRawAlerts(t *testing.T) {
+	sent := make(chan string, 1)
+	r := &Receiver{
+		ctx:     context.Background(),
+		trigger: func(context.Context, string) (string, error) { return "", errors.New("llm down") },
+		notify:  func(message string) { sent <- message },
+	}
+
+	r.deliver([]Event{
+		{Host: "nas", Message: "container jellyfin restarted 4 times in the last 15m0s"},
+		{Host: "nas", Message: "resolved: / is 91% full", Resolved: true},
+	})
+
+	want := "🚨 [nas] container jellyfin restarted 4 times in the last 15m0s\n✅ [nas] resolved: / is 91% full"
+	if msg := <-sent; msg != want {
+		t.Errorf("sent %q, want %q", msg, want)
+	}
+}
@@ -31,7 +31,19 @@ type PendingApproval struct {
 	Description string
 	CreatedAt   time.Time
 	resultCh    chan ApprovalResult
+	done        chan struct{}
 	resolved    bool
+	expired     bool
+}
+
+// Done is closed once the approval is answered, cancelled or times out
+func (p *PendingApproval) Done() <-chan struct{} {
+	return p.done
+}
+
+// Expired reports whether the approval timed out unanswered. Only valid after Done is closed.
+func (p *PendingApproval) Expired() bool {
+	return p.expired
 }
 
 type Manager struct {
@@ -59,6 +71,7 @@ func (m *Manager) Start(chatID, userID int64, toolName, toolArgs, description st
 		Description: description,
 		CreatedAt:   time.Now(),
 		resultCh:    make(chan ApprovalResult, 1),
+		done:        make(chan struct{}),
 	}
 
 	m.mu.Lock()
@@ -78,17 +91,14 @@ func (m *Manager) Wait(ctx context.Context, approvalID string) (bool, error) {
 		return false, ErrNotFound
 	}
 
-	defer func() {
-		m.mu.Lock()
-		delete(m.pending, approvalID)
-		m.mu.Unlock()
-	}()
+	defer m.finish(approval)
 
 	select {
 	case <-ctx.Done():
 		return false, ctx.Err()
 	case <-time.After(m.timeout):
 		logger.Info("approval timed out", "id", approvalID)
+		approval.expired = true
 		return false, fmt.Errorf("approval timed out after %s", m.timeout)
 	case result := <-approval.resultCh:
 		return result.Approved, nil
@@ -114,8 +124,24 @@ func (m *Manager) Get(approvalID string) (*PendingApproval, error) {
 
 func (m *Manager) Cancel(approvalID string) {
 	m.mu.Lock()
-	delete(m.pending, approvalID)
+	approval, ok := m.pending[approvalID]
 	m.mu.Unlock()
+
+	if ok {
+		m.finish(approval)
+	}
+}
+
+// finish removes the approval and signals anyone watching it, at most once
+func (m *Manager) finish(approval *PendingApproval) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.pending[approval.ID]; !ok {
+		return
+	}
+	delete(m.pending, approval.ID)
+	close(approval.done)
 }
 
 func (m *Manager) Resolve(approvalID string, approved bool, userID int64) error {
@@ -102,3 +102,59 @@ func TestApprovalContextCancel(t *testing.T) {
 		t.Errorf("expected context.Canceled, got %v", err)
 	}
 }
+
+func TestApprovalDoneAfterTimeout(t *testing.T) {
+	mgr := NewManager(20 * time.Millisecond)
+
+	id := mgr.Start(123, 456, "deploy_app", `{}`, "Deploy")
+	pending, err := mgr.Get(id)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+
+	mgr.Wait(context.Background(), id)
+
+	select {
+	case <-pending.Done():
+	default:
+		t.Fatal("expected done to be closed after timeout")
+	}
+	if !pending.Expired() {
+		t.Error("expected expired=true after timeout")
+	}
+}
+
+func TestApprovalDoneAfterResolve(t *testing.T) {
+	mgr := NewManager(time.Second)
+
+	id := mgr.Start(123, 456, "deploy_app", `{}`, "Deploy")
+	pending, _ := mgr.Get(id)
+
+	go mgr.Resolve(id, true, 456)
+	mgr.Wait(context.Background(), id)
+
+	<-pending.Done()
+	if pending.Expired() {
+		t.Error("expected expired=false after resolve")
+	}
+}
+
+func TestPromptClaim(t *testing.T) {
+	prompts := NewPrompts()
+	prompts.Track("forget-7", Prompt{ChatID: -100, UserID: 456, Owner: "user_telegram_456"})
+
+	if _, err := prompts.Claim("forget-7", 999); err != ErrUserMismatch {
+		t.Errorf("other user: expected ErrUserMismatch, got %v", err)
+	}
+	if _, err := prompts.Claim("forget-8", 456); err != ErrNotFound {
+		t.Errorf("unknown prompt: expected ErrNotFound, got %v", err)
+	}
+
+	p, err := prompts.Claim("forget-7", 456)
+	if err != nil || p.Owner != "user_telegram_456" || p.ChatID != -100 {
+		t.Fatalf("Claim = %+v, %v", p, err)
+	}
+	if _, err := prompts.Claim("forget-7", 456); err != ErrNotFound {
+		t.Errorf("second claim: expected ErrNotFound, got %v", err)
+	}
+}
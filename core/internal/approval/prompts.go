@@ -0,0 +1,62 @@
+package approval
+
+import (
+	"sync"
+	"time"
+)
+
+// promptTTL is how long a button prompt can still be answered. Review and
+// contradiction prompts don't block anything, so they can wait for days.
+const promptTTL = 7 * 24 * time.Hour
+
+// Prompt is a message with buttons sent to one user, e.g. a fact under
+// review. Owner is the memory entity the prompt is about.
+type Prompt struct {
+	ChatID int64
+	UserID int64
+	Owner  string
+	sentAt time.Time
+}
+
+// Prompts remembers who button prompts were sent to, so a press is only
+// acted on when it comes from that user
+type Prompts struct {
+	mu      sync.Mutex
+	prompts map[string]Prompt
+}
+
+func NewPrompts() *Prompts {
+	return &Prompts{prompts: make(map[string]Prompt)}
+}
+
+// Track records that the prompt with this ID was sent to p.UserID in p.ChatID
+func (ps *Prompts) Track(id string, p Prompt) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	now := time.Now()
+	for key, old := range ps.prompts {
+		if now.Sub(old.sentAt) > promptTTL {
+			delete(ps.prompts, key)
+		}
+	}
+	p.sentAt = now
+	ps.prompts[id] = p
+}
+
+// Claim returns the prompt a button belongs to if userID is who it was sent
+// to. A claimed prompt can't be answered again.
+func (ps *Prompts) Claim(id string, userID int64) (Prompt, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p, ok := ps.prompts[id]
+	if !ok || time.Since(p.sentAt) > promptTTL {
+		return Prompt{}, ErrNotFound
+	}
+	if p.UserID != userID {
+		return Prompt{}, ErrUserMismatch
+	}
+	delete(ps.prompts, id)
+	return p, nil
+}
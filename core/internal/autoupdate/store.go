@@ -0,0 +1,160 @@
+package autoupdate
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("auto-update not enabled for this app")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS app_auto_updates (
+    app TEXT PRIMARY KEY,
+    repo TEXT NOT NULL,
+    branch TEXT NOT NULL,
+    on_push INTEGER NOT NULL DEFAULT 1,
+    every_hours INTEGER NOT NULL DEFAULT 0,
+    chat_id INTEGER NOT NULL DEFAULT 0,
+    last_commit TEXT,
+    failed_commit TEXT,
+    last_deployed DATETIME,
+    last_run DATETIME,
+    last_error TEXT,
+    created_at DATETIME DEFAULT (datetime('now'))
+);
+`
+
+const sqliteTime = "2006-01-02 15:04:05"
+
+// NewStore creates an auto-update store using the provided database connection
+func NewStore(db *sql.DB) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Save enables auto-update for an app, replacing any earlier settings
+func (s *Store) Save(w *Watch) error {
+	var lastRun any
+	if w.LastRun != nil {
+		lastRun = w.LastRun.UTC().Format(sqliteTime)
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO app_auto_updates (app, repo, branch, on_push, every_hours, chat_id, last_commit, last_run)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(app) DO UPDATE SET
+			repo = excluded.repo,
+			branch = excluded.branch,
+			on_push = excluded.on_push,
+			every_hours = excluded.every_hours,
+			chat_id = excluded.chat_id,
+			last_commit = excluded.last_commit,
+			last_run = excluded.last_run,
+			failed_commit = NULL,
+			last_error = NULL`,
+		w.App, w.Repo, w.Branch, w.OnPush, w.EveryHours, w.ChatID, w.LastCommit, lastRun)
+	return err
+}
+
+// Delete turns auto-update off for an app
+func (s *Store) Delete(app string) error {
+	result, err := s.db.Exec(`DELETE FROM app_auto_updates WHERE app = ?`, app)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get returns the auto-update settings of one app
+func (s *Store) Get(app string) (*Watch, error) {
+	watches, err := s.query(`WHERE app = ?`, app)
+	if err != nil {
+		return nil, err
+	}
+	if len(watches) == 0 {
+		return nil, ErrNotFound
+	}
+	return &watches[0], nil
+}
+
+// All returns every app with auto-update enabled
+func (s *Store) All() ([]Watch, error) {
+	return s.query(`ORDER BY app`)
+}
+
+// RecordDeploy marks a commit as deployed and clears any earlier failure
+func (s *Store) RecordDeploy(app, commit string, at time.Time) error {
+	_, err := s.db.Exec(`
+		UPDATE app_auto_updates
+		SET last_commit = ?, last_deployed = ?, last_run = ?, failed_commit = NULL, last_error = NULL
+		WHERE app = ?`,
+		commit, at.UTC().Format(sqliteTime), at.UTC().Format(sqliteTime), app)
+	return err
+}
+
+// RecordFailure remembers a commit that failed to deploy so it isn't retried every check
+func (s *Store) RecordFailure(app, commit string, deployErr error, at time.Time) error {
+	_, err := s.db.Exec(`
+		UPDATE app_auto_updates SET failed_commit = ?, last_error = ?, last_run = ? WHERE app = ?`,
+		commit, deployErr.Error(), at.UTC().Format(sqliteTime), app)
+	return err
+}
+
+func (s *Store) query(where string, args ...any) ([]Watch, error) {
+	rows, err := s.db.Query(`
+		SELECT app, repo, branch, on_push, every_hours, chat_id,
+			COALESCE(last_commit, ''), COALESCE(failed_commit, ''), last_deployed, last_run,
+			COALESCE(last_error, ''), created_at
+		FROM app_auto_updates `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watches []Watch
+	for rows.Next() {
+		var w Watch
+		var lastDeployed, lastRun sql.NullString
+		var createdAt string
+		if err := rows.Scan(&w.App, &w.Repo, &w.Branch, &w.OnPush, &w.EveryHours, &w.ChatID,
+			&w.LastCommit, &w.FailedCommit, &lastDeployed, &lastRun, &w.LastError, &createdAt); err != nil {
+			return nil, err
+		}
+		w.LastDeployed = parseOptionalTime(lastDeployed)
+		w.LastRun = parseOptionalTime(lastRun)
+		w.CreatedAt = parseTime(createdAt)
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
+
+func parseTime(s string) time.Time {
+	formats := []string{
+		time.RFC3339,
+		sqliteTime,
+		"2006-01-02T15:04:05",
+	}
+	for _, f := range formats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func parseOptionalTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
+	}
+	t := parseTime(s.String)
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,54 @@
+package autoupdate
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/deployer"
+)
+
+// Watch is an app that is rebuilt and redeployed from a git branch without being asked
+type Watch struct {
+	App          string
+	Repo         string
+	Branch       string
+	OnPush       bool // redeploy when the branch gets new commits
+	EveryHours   int  // also rebuild on this schedule, 0 = never
+	ChatID       int64
+	LastCommit   string     // commit the app was last deployed from
+	FailedCommit string     // commit whose deploy last failed, not retried until the branch moves
+	LastDeployed *time.Time // last successful automatic deploy
+	LastRun      *time.Time // last automatic deploy attempt, or when the watch was created
+	LastError    string
+	CreatedAt    time.Time
+}
+
+// Source reads and checks out the branches apps are deployed from
+type Source interface {
+	RemoteHead(ctx context.Context, repo, branch string) (string, error)
+	DefaultBranch(ctx context.Context, repo string) (string, error)
+	SyncBranch(ctx context.Context, repo, branch, dir string) error
+	CommitLog(ctx context.Context, dir, from, to string, max int) []string
+}
+
+// NotifyFunc delivers an update summary to a chat
+type NotifyFunc func(chatID int64, message string)
+
+// Store persists which apps auto-update and what they were last deployed from
+type Store struct {
+	db *sql.DB
+}
+
+// Updater checks every watched app on an interval and redeploys the ones that are due
+type Updater struct {
+	store    *Store
+	source   Source
+	deployer deployer.Deployer
+	domain   string
+	workDir  string
+	interval time.Duration
+	notify   NotifyFunc
+	mu       sync.Mutex // one check or deploy at a time
+}
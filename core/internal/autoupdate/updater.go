@@ -0,0 +1,229 @@
+package autoupdate
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/deployer"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+var (
+	validApp    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	validRepo   = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,99}$`)
+	validBranch = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._/-]{0,199}$`)
+)
+
+const (
+	maxChangelog = 10
+	// a single build and deploy may not hold up the other apps for longer than this
+	deployTimeout = 20 * time.Minute
+)
+
+// NewUpdater creates an updater that checks watched apps once per interval,
+// keeping a checkout of each app's branch under workDir
+func NewUpdater(store *Store, source Source, d deployer.Deployer, domain, workDir string, interval time.Duration) *Updater {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	return &Updater{
+		store:    store,
+		source:   source,
+		deployer: d,
+		domain:   domain,
+		workDir:  workDir,
+		interval: interval,
+	}
+}
+
+// SetNotify sets where update summaries are sent
+func (u *Updater) SetNotify(fn NotifyFunc) {
+	u.notify = fn
+}
+
+// Store returns the store holding the watched apps
+func (u *Updater) Store() *Store {
+	return u.store
+}
+
+// Enable starts watching a repo branch for an app. An empty branch means the repo's
+// default branch; the current head counts as deployed so only later commits trigger
+func (u *Updater) Enable(ctx context.Context, w Watch) (*Watch, error) {
+	if !validApp.MatchString(w.App) {
+		return nil, fmt.Errorf("invalid app name %q", w.App)
+	}
+	if !validRepo.MatchString(w.Repo) {
+		return nil, fmt.Errorf("invalid repo name %q", w.Repo)
+	}
+	if w.Branch != "" && (!validBranch.MatchString(w.Branch) || strings.Contains(w.Branch, "..")) {
+		return nil, fmt.Errorf("invalid branch name %q", w.Branch)
+	}
+	if !w.OnPush && w.EveryHours <= 0 {
+		return nil, fmt.Errorf("enable on_push or set every_hours")
+	}
+
+	if w.Branch == "" {
+		branch, err := u.source.DefaultBranch(ctx, w.Repo)
+		if err != nil {
+			return nil, err
+		}
+		w.Branch = branch
+	}
+
+	head, err := u.source.RemoteHead(ctx, w.Repo, w.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	w.LastCommit = head
+	w.LastRun = &now
+	if err := u.store.Save(&w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Run checks all watched apps until the context is cancelled
+func (u *Updater) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debug("auto-updater stopping")
+			return
+		case <-ticker.C:
+			u.checkAll(ctx)
+		}
+	}
+}
+
+func (u *Updater) checkAll(ctx context.Context) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	watches, err := u.store.All()
+	if err != nil {
+		logger.Error("failed to list auto-update apps", "error", err)
+		return
+	}
+
+	for _, w := range watches {
+		if ctx.Err() != nil {
+			return
+		}
+
+		head, err := u.source.RemoteHead(ctx, w.Repo, w.Branch)
+		if err != nil {
+			logger.Warn("auto-update check failed", "app", w.App, "repo", w.Repo, "error", err)
+			continue
+		}
+
+		reason := w.due(head, time.Now())
+		if reason == "" {
+			continue
+		}
+
+		u.update(ctx, w, head, reason)
+	}
+}
+
+// due reports why the app should be redeployed at head, or "" when it shouldn't
+func (w Watch) due(head string, now time.Time) string {
+	if w.OnPush && head != w.LastCommit && head != w.FailedCommit {
+		return "push"
+	}
+	if w.EveryHours > 0 && (w.LastRun == nil || now.Sub(*w.LastRun) >= time.Duration(w.EveryHours)*time.Hour) {
+		return "schedule"
+	}
+	return ""
+}
+
+func (u *Updater) update(ctx context.Context, w Watch, head, reason string) {
+	ctx, cancel := context.WithTimeout(ctx, deployTimeout)
+	defer cancel()
+
+	logger.Info("auto-updating app", "app", w.App, "repo", w.Repo, "branch", w.Branch, "commit", shortSHA(head), "reason", reason)
+
+	dir := filepath.Join(u.workDir, w.App)
+	result, err := u.deploy(ctx, w, dir)
+	if err != nil {
+		logger.Error("auto-update failed", "app", w.App, "commit", shortSHA(head), "error", err)
+		if err := u.store.RecordFailure(w.App, head, err, time.Now()); err != nil {
+			logger.Warn("failed to record auto-update failure", "app", w.App, "error", err)
+		}
+		u.send(w.ChatID, fmt.Sprintf("❌ Auto-update of %s failed at %s@%s: %v", w.App, w.Branch, shortSHA(head), err))
+		return
+	}
+
+	if err := u.store.RecordDeploy(w.App, head, time.Now()); err != nil {
+		logger.Warn("failed to record auto-update", "app", w.App, "error", err)
+	}
+
+	var changes []string
+	if head != w.LastCommit {
+		changes = u.source.CommitLog(ctx, dir, w.LastCommit, head, maxChangelog)
+	}
+	u.send(w.ChatID, summary(w, head, result, changes))
+}
+
+func (u *Updater) deploy(ctx context.Context, w Watch, dir string) (*deployer.DeployResult, error) {
+	if err := u.source.SyncBranch(ctx, w.Repo, w.Branch, dir); err != nil {
+		return nil, err
+	}
+	// empty options keep the env, port, volumes and replicas the app was deployed with
+	return u.deployer.Deploy(ctx, dir, w.App, u.domain, deployer.DeployOptions{})
+}
+
+func (u *Updater) send(chatID int64, message string) {
+	if u.notify == nil || chatID == 0 {
+		return
+	}
+	u.notify(chatID, message)
+}
+
+// summary describes a finished auto-update for the chat that enabled it
+func summary(w Watch, head string, result *deployer.DeployResult, changes []string) string {
+	var sb strings.Builder
+	if head == w.LastCommit {
+		sb.WriteString(fmt.Sprintf("🔄 Rebuilt %s on schedule from %s@%s (no new commits)", w.App, w.Branch, shortSHA(head)))
+	} else {
+		sb.WriteString(fmt.Sprintf("🔄 Auto-updated %s to %s@%s", w.App, w.Branch, shortSHA(head)))
+	}
+	if result.DeploymentID > 0 {
+		sb.WriteString(fmt.Sprintf(" (deployment #%d)", result.DeploymentID))
+	}
+	sb.WriteString("\n")
+
+	if len(changes) > 0 {
+		sb.WriteString("\nChanges:\n")
+		for _, c := range changes {
+			sb.WriteString(fmt.Sprintf("- %s\n", c))
+		}
+		if len(changes) == maxChangelog {
+			sb.WriteString("- ...\n")
+		}
+	}
+
+	if result.URL != "" {
+		sb.WriteString(fmt.Sprintf("\nURL: %s\n", result.URL))
+	}
+	if result.DeploymentID > 0 {
+		sb.WriteString("Undo with rollback_app if something broke.\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,178 @@
+package autoupdate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/deployer"
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+type fakeSource struct {
+	heads   map[string]string
+	synced  []string
+	syncErr error
+}
+
+func (f *fakeSource) RemoteHead(ctx context.Context, repo, branch string) (string, error) {
+	head, ok := f.heads[repo+"@"+branch]
+	if !ok {
+		return "", errors.New("branch not found")
+	}
+	return head, nil
+}
+
+func (f *fakeSource) DefaultBranch(ctx context.Context, repo string) (string, error) {
+	return "main", nil
+}
+
+func (f *fakeSource) SyncBranch(ctx context.Context, repo, branch, dir string) error {
+	f.synced = append(f.synced, dir)
+	return f.syncErr
+}
+
+func (f *fakeSource) CommitLog(ctx context.Context, dir, from, to string, max int) []string {
+	return []string{to[:7] + " fix the thing"}
+}
+
+type fakeDeployer struct {
+	deployer.Deployer
+	deployed []string
+}
+
+func (f *fakeDeployer) Deploy(ctx context.Context, appDir, name, domain string, opts deployer.DeployOptions) (*deployer.DeployResult, error) {
+	f.deployed = append(f.deployed, name)
+	return &deployer.DeployResult{URL: "https://" + name + "." + domain, DeploymentID: int64(len(f.deployed))}, nil
+}
+
+func newTestUpdater(t *testing.T) (*Updater, *fakeSource, *fakeDeployer, *[]string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "autoupdate.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := &fakeSource{heads: map[string]string{"weather@main": "aaaaaaaaaa"}}
+	d := &fakeDeployer{}
+	u := NewUpdater(store, source, d, "example.com", t.TempDir(), time.Minute)
+
+	var sent []string
+	u.SetNotify(func(chatID int64, message string) { sent = append(sent, message) })
+	return u, source, d, &sent
+}
+
+func TestWatchDue(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Hour)
+	old := now.Add(-25 * time.Hour)
+
+	tests := []struct {
+		name  string
+		watch Watch
+		head  string
+		want  string
+	}{
+		{"unchanged", Watch{OnPush: true, LastCommit: "a", LastRun: &recent}, "a", ""},
+		{"new commit", Watch{OnPush: true, LastCommit: "a", LastRun: &recent}, "b", "push"},
+		{"failed commit not retried", Watch{OnPush: true, LastCommit: "a", FailedCommit: "b", LastRun: &recent}, "b", ""},
+		{"push ignored when off", Watch{EveryHours: 24, LastCommit: "a", LastRun: &recent}, "b", ""},
+		{"schedule due", Watch{EveryHours: 24, LastCommit: "a", LastRun: &old}, "a", "schedule"},
+		{"schedule retries failures", Watch{EveryHours: 24, LastCommit: "a", FailedCommit: "b", LastRun: &old}, "b", "schedule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.watch.due(tt.head, now); got != tt.want {
+				t.Errorf("due = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdaterRedeploysOnPush(t *testing.T) {
+	ctx := context.Background()
+	u, source, d, sent := newTestUpdater(t)
+
+	w, err := u.Enable(ctx, Watch{App: "weather", Repo: "weather", OnPush: true, ChatID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Branch != "main" || w.LastCommit != "aaaaaaaaaa" {
+		t.Fatalf("enable resolved %s@%s", w.Branch, w.LastCommit)
+	}
+
+	u.checkAll(ctx)
+	if len(d.deployed) != 0 {
+		t.Fatalf("deployed without new commits: %v", d.deployed)
+	}
+
+	source.heads["weather@main"] = "bbbbbbbbbb"
+	u.checkAll(ctx)
+	if len(d.deployed) != 1 {
+		t.Fatalf("expected one deploy, got %v", d.deployed)
+	}
+	if len(*sent) != 1 || !strings.Contains((*sent)[0], "weather to main@bbbbbbb") || !strings.Contains((*sent)[0], "bbbbbbb fix the thing") {
+		t.Fatalf("unexpected notification: %v", *sent)
+	}
+
+	got, err := u.Store().Get("weather")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastCommit != "bbbbbbbbbb" || got.LastDeployed == nil {
+		t.Errorf("deploy not recorded: %+v", got)
+	}
+
+	u.checkAll(ctx)
+	if len(d.deployed) != 1 {
+		t.Errorf("redeployed the same commit: %v", d.deployed)
+	}
+}
+
+func TestUpdaterRecordsFailure(t *testing.T) {
+	ctx := context.Background()
+	u, source, d, sent := newTestUpdater(t)
+
+	if _, err := u.Enable(ctx, Watch{App: "weather", Repo: "weather", Branch: "main", OnPush: true, ChatID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	source.heads["weather@main"] = "cccccccccc"
+	source.syncErr = errors.New("git fetch: connection refused")
+	u.checkAll(ctx)
+	u.checkAll(ctx)
+
+	if len(source.synced) != 1 || len(d.deployed) != 0 {
+		t.Fatalf("failed commit retried: synced %v", source.synced)
+	}
+	if len(*sent) != 1 || !strings.HasPrefix((*sent)[0], "❌") {
+		t.Fatalf("unexpected notification: %v", *sent)
+	}
+
+	got, _ := u.Store().Get("weather")
+	if got.FailedCommit != "cccccccccc" || got.LastCommit != "aaaaaaaaaa" || got.LastError == "" {
+		t.Errorf("failure not recorded: %+v", got)
+	}
+}
+
+func TestEnableRequiresTrigger(t *testing.T) {
+	u, _, _, _ := newTestUpdater(t)
+	if _, err := u.Enable(context.Background(), Watch{App: "weather", Repo: "weather"}); err == nil {
+		t.Error("expected error without on_push or every_hours")
+	}
+	if err := u.Store().Delete("weather"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,334 @@
+// Package backup snapshots the memory database on a schedule, encrypts it
+// and keeps the last few copies in the backups bucket
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/encryption"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/storage"
+)
+
+// prefix keeps scheduled backups apart from the ones backup_memory stores,
+// so rotation never deletes a backup the user asked for
+const prefix = "scheduled/"
+
+// namePrefix and timeLayout make names sort oldest first
+const (
+	namePrefix = "sheldon_backup_"
+	timeLayout = "2006-01-02_15-04-05"
+)
+
+// reportInterval is how often a summary of recent backups is sent
+const reportInterval = 7 * 24 * time.Hour
+
+// SnapshotFunc writes a consistent copy of the database to path
+type SnapshotFunc func(ctx context.Context, path string) error
+
+// NotifyFunc tells the user about a failed backup or the weekly report
+type NotifyFunc func(title, text string, failed bool)
+
+// Store is where backups are kept, the storage client in production
+type Store interface {
+	InitBackupBucket(ctx context.Context) error
+	BackupBucket() string
+	Upload(ctx context.Context, bucket, name string, data []byte, contentType string) error
+	UploadFile(ctx context.Context, bucket, name, path, contentType string) (int64, error)
+	List(ctx context.Context, bucket, prefix string) ([]storage.FileInfo, error)
+	Delete(ctx context.Context, bucket, name string) error
+}
+
+// Result is the outcome of one scheduled backup
+type Result struct {
+	Time time.Time
+	Name string
+	Size int64
+	Err  error
+}
+
+// Scheduler backs up memory every interval and rotates old backups
+type Scheduler struct {
+	snapshot SnapshotFunc
+	store    Store
+	key      *encryption.Key // nil stores backups unencrypted
+	interval time.Duration
+	keep     int
+	notify   NotifyFunc
+
+	mu         sync.Mutex
+	results    []Result // since the last report
+	lastReport time.Time
+}
+
+func New(snapshot SnapshotFunc, store Store, key *encryption.Key, cfg config.BackupConfig, notify NotifyFunc) *Scheduler {
+	return &Scheduler{
+		snapshot:   snapshot,
+		store:      store,
+		key:        key,
+		interval:   cfg.Interval,
+		keep:       cfg.Keep,
+		notify:     notify,
+		lastReport: time.Now(),
+	}
+}
+
+// Run backs up whenever the newest backup is an interval old, so restarts
+// don't reset the schedule, until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	if err := s.store.InitBackupBucket(ctx); err != nil {
+		logger.Error("scheduled backups disabled", "error", err)
+		return
+	}
+
+	timer := time.NewTimer(s.untilDue(ctx))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		result := s.Backup(ctx)
+		if result.Err != nil {
+			logger.Error("scheduled backup failed", "error", result.Err)
+			s.notify("Sheldon backup failed", fmt.Sprintf("Scheduled memory backup failed: %v", result.Err), true)
+		} else {
+			logger.Info("scheduled backup stored", "name", result.Name, "bytes", result.Size)
+			if err := s.rotate(ctx); err != nil {
+				logger.Warn("backup rotation failed", "error", err)
+			}
+		}
+
+		if report := s.report(time.Now()); report != "" {
+			s.notify("Sheldon backups", report, false)
+		}
+		timer.Reset(s.interval)
+	}
+}
+
+// untilDue is how long until the next backup, counted from the newest one
+// in the bucket
+func (s *Scheduler) untilDue(ctx context.Context) time.Duration {
+	// leave startup alone for a minute before the first backup
+	const minWait = time.Minute
+
+	names, err := s.list(ctx)
+	if err != nil || len(names) == 0 {
+		return minWait
+	}
+	last, ok := backupTime(names[len(names)-1])
+	if !ok {
+		return minWait
+	}
+	if wait := s.interval - time.Since(last); wait > minWait {
+		return wait
+	}
+	return minWait
+}
+
+// Backup snapshots memory, zips and encrypts it and uploads it
+func (s *Scheduler) Backup(ctx context.Context) Result {
+	now := time.Now().UTC()
+	result := Result{Time: now}
+	defer s.record(&result)
+
+	dir, err := os.MkdirTemp("", "sheldon-backup-")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "memory.db")
+	if err := s.snapshot(ctx, path); err != nil {
+		result.Err = fmt.Errorf("snapshot: %w", err)
+		return result
+	}
+
+	stamp := now.Format(timeLayout)
+	zipPath := filepath.Join(dir, "backup.zip")
+	if err := zipDatabase(path, zipPath, fmt.Sprintf("memory_%s.db", stamp)); err != nil {
+		result.Err = fmt.Errorf("zip: %w", err)
+		return result
+	}
+
+	// same format as backup_memory, so sheldon decrypt-backup reads both
+	name := prefix + namePrefix + stamp + ".zip"
+	if s.key == nil {
+		// streamed from disk, so a large database is never held in memory
+		size, err := s.store.UploadFile(ctx, s.store.BackupBucket(), name, zipPath, "application/zip")
+		if err != nil {
+			result.Err = fmt.Errorf("upload: %w", err)
+			return result
+		}
+		result.Name = name
+		result.Size = size
+		return result
+	}
+
+	// sealing is one AEAD over the whole zip, so encrypted backups are read in
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if data, err = s.key.SealExport(data); err != nil {
+		result.Err = fmt.Errorf("encrypt: %w", err)
+		return result
+	}
+	name += ".enc"
+
+	if err := s.store.Upload(ctx, s.store.BackupBucket(), name, data, "application/octet-stream"); err != nil {
+		result.Err = fmt.Errorf("upload: %w", err)
+		return result
+	}
+
+	result.Name = name
+	result.Size = int64(len(data))
+	return result
+}
+
+func (s *Scheduler) record(result *Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, *result)
+}
+
+// list returns the scheduled backups in the bucket, oldest first
+func (s *Scheduler) list(ctx context.Context) ([]string, error) {
+	files, err := s.store.List(ctx, s.store.BackupBucket(), prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if _, ok := backupTime(f.Name); ok {
+			names = append(names, f.Name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// rotate deletes all but the newest keep backups
+func (s *Scheduler) rotate(ctx context.Context) error {
+	names, err := s.list(ctx)
+	if err != nil {
+		return err
+	}
+	if len(names) <= s.keep {
+		return nil
+	}
+
+	for _, name := range names[:len(names)-s.keep] {
+		if err := s.store.Delete(ctx, s.store.BackupBucket(), name); err != nil {
+			return err
+		}
+		logger.Info("old backup deleted", "name", name)
+	}
+	return nil
+}
+
+// report summarizes the backups since the last report once a week has
+// passed, empty until then
+func (s *Scheduler) report(now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastReport) < reportInterval || len(s.results) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	var failed []Result
+	var last *Result
+	for i, r := range s.results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		} else {
+			last = &s.results[i]
+		}
+	}
+
+	fmt.Fprintf(&sb, "Backups this week: %d of %d succeeded.", len(s.results)-len(failed), len(s.results))
+	if last != nil {
+		fmt.Fprintf(&sb, "\nLatest: %s (%s, %s)", strings.TrimPrefix(last.Name, prefix), formatBytes(last.Size), last.Time.Format("Jan 2 15:04 MST"))
+	}
+	for _, r := range failed {
+		fmt.Fprintf(&sb, "\nFailed %s: %v", r.Time.Format("Jan 2 15:04 MST"), r.Err)
+	}
+	fmt.Fprintf(&sb, "\nKeeping the last %d", s.keep)
+	if s.key == nil {
+		sb.WriteString(", unencrypted (set MEMORY_ENCRYPTION_KEY to encrypt them)")
+	}
+	sb.WriteString(".")
+
+	s.results = nil
+	s.lastReport = now
+	return sb.String()
+}
+
+// backupTime parses the time out of a scheduled backup's name
+func backupTime(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, prefix+namePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp, _, ok = strings.Cut(stamp, ".zip")
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(timeLayout, stamp)
+	return t, err == nil
+}
+
+// zipDatabase compresses the snapshot at src into a zip at dst, streaming
+// both so the database never has to fit in memory
+func zipDatabase(src, dst, name string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+	f, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, in); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func formatBytes(n int64) string {
+	const mb = 1024 * 1024
+	if n >= mb {
+		return fmt.Sprintf("%.1f MB", float64(n)/mb)
+	}
+	return fmt.Sprintf("%d KB", n/1024)
+}
@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/encryption"
+	"github.com/bowerhall/sheldon/internal/storage"
+)
+
+type memStore struct {
+	files map[string][]byte
+}
+
+func (m *memStore) InitBackupBucket(ctx context.Context) error { return nil }
+func (m *memStore) BackupBucket() string                       { return "sheldon-backups" }
+
+func (m *memStore) Upload(ctx context.Context, bucket, name string, data []byte, contentType string) error {
+	m.files[name] = data
+	return nil
+}
+
+func (m *memStore) UploadFile(ctx context.Context, bucket, name, path, contentType string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	m.files[name] = data
+	return int64(len(data)), nil
+}
+
+func (m *memStore) List(ctx context.Context, bucket, prefix string) ([]storage.FileInfo, error) {
+	var files []storage.FileInfo
+	for name, data := range m.files {
+		if strings.HasPrefix(name, prefix) {
+			files = append(files, storage.FileInfo{Name: name, Size: int64(len(data))})
+		}
+	}
+	return files, nil
+}
+
+func (m *memStore) Delete(ctx context.Context, bucket, name string) error {
+	delete(m.files, name)
+	return nil
+}
+
+func TestBackupEncryptsAndRotates(t *testing.T) {
+	ctx := context.Background()
+	store := &memStore{files: map[string][]byte{
+		"sheldon_backup_2020-01-01_00-00-00.zip":              []byte("manual"),
+		prefix + "sheldon_backup_2020-01-01_00-00-00.zip.enc": []byte("oldest"),
+		prefix + "sheldon_backup_2020-01-02_00-00-00.zip.enc": []byte("old"),
+	}}
+	salt, _ := encryption.NewSalt()
+	key := encryption.DeriveKey("test-secret", salt)
+	snapshot := func(ctx context.Context, path string) error {
+		return os.WriteFile(path, []byte("sqlite"), 0644)
+	}
+
+	s := New(snapshot, store, key, config.BackupConfig{Interval: 24 * time.Hour, Keep: 2}, nil)
+	result := s.Backup(ctx)
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if err := s.rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.files["sheldon_backup_2020-01-01_00-00-00.zip"]; !ok {
+		t.Error("rotation deleted a manual backup")
+	}
+	if _, ok := store.files[prefix+"sheldon_backup_2020-01-01_00-00-00.zip.enc"]; ok {
+		t.Error("oldest scheduled backup was kept")
+	}
+	if len(store.files) != 3 {
+		t.Errorf("files = %d, want 3", len(store.files))
+	}
+
+	plain, err := encryption.OpenExport("test-secret", store.files[result.Name])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(plain), int64(len(plain)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := r.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != "sqlite" {
+		t.Errorf("backup holds %q, want the snapshot", data)
+	}
+
+	// the next one is due an interval after the newest backup
+	if wait := s.untilDue(ctx); wait < 23*time.Hour || wait > 24*time.Hour {
+		t.Errorf("untilDue = %s, want about 24h", wait)
+	}
+}
+
+func TestBackupUnencrypted(t *testing.T) {
+	store := &memStore{files: map[string][]byte{}}
+	snapshot := func(ctx context.Context, path string) error {
+		return os.WriteFile(path, []byte("sqlite"), 0644)
+	}
+
+	result := New(snapshot, store, nil, config.BackupConfig{Interval: 24 * time.Hour, Keep: 2}, nil).Backup(context.Background())
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if !strings.HasSuffix(result.Name, ".zip") || result.Size != int64(len(store.files[result.Name])) {
+		t.Errorf("result = %+v, want a plain zip of the stored size", result)
+	}
+
+	data := store.files[result.Name]
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.File) != 1 || r.File[0].UncompressedSize64 != uint64(len("sqlite")) {
+		t.Errorf("zip holds %d files, want the snapshot", len(r.File))
+	}
+}
+
+func TestReport(t *testing.T) {
+	s := New(nil, &memStore{}, nil, config.BackupConfig{Interval: 24 * time.Hour, Keep: 7}, nil)
+	start := s.lastReport
+
+	s.record(&Result{Time: start, Name: prefix + "sheldon_backup_a.zip", Size: 3 << 20})
+	s.record(&Result{Time: start, Err: errors.New("disk full")})
+
+	if got := s.report(start.Add(24 * time.Hour)); got != "" {
+		t.Errorf("report after a day = %q, want none", got)
+	}
+
+	got := s.report(start.Add(reportInterval))
+	for _, want := range []string{"1 of 2 succeeded", "sheldon_backup_a.zip (3.0 MB", "disk full", "unencrypted"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report %q doesn't mention %q", got, want)
+		}
+	}
+	if got := s.report(start.Add(2 * reportInterval)); got != "" {
+		t.Errorf("report with nothing new = %q, want none", got)
+	}
+}
@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// approvalCountdownInterval is how often the time left on an approval prompt is refreshed
+const approvalCountdownInterval = 15 * time.Second
+
+// countdownPattern matches the trailing "(expires in ...)" line added to approval prompts
+var countdownPattern = regexp.MustCompile(`\n\n\(expires in [^)]*\)$`)
+
+// ApprovalButtons returns the approve/deny buttons for an approval, with its ID in the callback data
+func ApprovalButtons(approvalID string) []Button {
+	return []Button{
+		{Label: "Approve", CallbackID: approvalID + ":approve"},
+		{Label: "Deny", CallbackID: approvalID + ":deny"},
+	}
+}
+
+// reviewPrefix marks memory review buttons, which resolve through the approval
+// callback: approve forgets the fact, deny keeps it
+const reviewPrefix = "forget-"
+
+// ReviewButtons returns the forget/keep buttons for a fact under review
+func ReviewButtons(factID int64) []Button {
+	id := reviewPrefix + strconv.FormatInt(factID, 10)
+	return []Button{
+		{Label: "Forget", CallbackID: id + ":approve"},
+		{Label: "Keep", CallbackID: id + ":deny"},
+	}
+}
+
+// ParseReviewID returns the fact ID if a callback ID belongs to a review button
+func ParseReviewID(id string) (int64, bool) {
+	rest, ok := strings.CutPrefix(id, reviewPrefix)
+	if !ok {
+		return 0, false
+	}
+	factID, err := strconv.ParseInt(rest, 10, 64)
+	return factID, err == nil
+}
+
+// conflictPrefix marks contradiction prompts: approving "-a" or "-b" keeps that
+// fact, deny keeps both
+const conflictPrefix = "conflict-"
+
+// ConflictButtons returns the choices for resolving a contradiction between two facts
+func ConflictButtons(conflictID int64) []Button {
+	id := conflictPrefix + strconv.FormatInt(conflictID, 10)
+	return []Button{
+		{Label: "Keep first", CallbackID: id + "-a:approve"},
+		{Label: "Keep second", CallbackID: id + "-b:approve"},
+		{Label: "Both are true", CallbackID: id + ":deny"},
+	}
+}
+
+// ParseConflictID returns the conflict ID and chosen side ("a", "b" or "" for both)
+// if a callback ID belongs to a contradiction prompt
+func ParseConflictID(id string) (conflictID int64, keep string, ok bool) {
+	rest, found := strings.CutPrefix(id, conflictPrefix)
+	if !found {
+		return 0, "", false
+	}
+	if r, side, cut := strings.Cut(rest, "-"); cut {
+		rest, keep = r, side
+	}
+	conflictID, err := strconv.ParseInt(rest, 10, 64)
+	return conflictID, keep, err == nil
+}
+
+// callbackResult is the outcome shown on a message after one of its buttons is pressed
+func callbackResult(id string, approved bool) string {
+	if _, _, ok := ParseConflictID(id); ok {
+		if approved {
+			return "Updated"
+		}
+		return "Kept both"
+	}
+	if _, ok := ParseReviewID(id); ok {
+		if approved {
+			return "Forgotten"
+		}
+		return "Kept"
+	}
+	if approved {
+		return "Approved"
+	}
+	return "Denied"
+}
+
+// parseApprovalCallback splits "<id>:approve" or "<id>:deny" callback data
+func parseApprovalCallback(data string) (approvalID string, approved bool, ok bool) {
+	if id, found := strings.CutSuffix(data, ":approve"); found && id != "" {
+		return id, true, true
+	}
+	if id, found := strings.CutSuffix(data, ":deny"); found && id != "" {
+		return id, false, true
+	}
+	return "", false, false
+}
+
+// withCountdown appends the time left to an approval prompt
+func withCountdown(message string, left time.Duration) string {
+	return fmt.Sprintf("%s\n\n(expires in %s)", message, left.Round(time.Second))
+}
+
+// stripCountdown removes the countdown line so a resolved prompt shows only its outcome
+func stripCountdown(message string) string {
+	return countdownPattern.ReplaceAllString(message, "")
+}
+
+// RunApprovalCountdown sends an approval prompt and keeps its remaining time up to date
+// until done is closed. If the approval expired unanswered the buttons are removed.
+func RunApprovalCountdown(b ButtonSender, chatID int64, message, approvalID string, timeout time.Duration, done <-chan struct{}, expired func() bool) error {
+	buttons := ApprovalButtons(approvalID)
+	deadline := time.Now().Add(timeout)
+
+	messageID, err := b.SendWithButtons(chatID, withCountdown(message, timeout), buttons)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(approvalCountdownInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				if expired() {
+					if err := b.EditWithButtons(chatID, messageID, message+"\n\nExpired", nil); err != nil {
+						logger.Warn("approval expiry edit failed", "error", err, "approvalID", approvalID)
+					}
+				}
+				return
+			case <-ticker.C:
+				left := time.Until(deadline)
+				if left <= 0 {
+					continue
+				}
+				if err := b.EditWithButtons(chatID, messageID, withCountdown(message, left), buttons); err != nil {
+					logger.Debug("approval countdown edit failed", "error", err, "approvalID", approvalID)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
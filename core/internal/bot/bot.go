@@ -12,6 +12,8 @@ func New(cfg Config, agent *agent.Agent) (Bot, error) {
 		return NewTelegram(cfg.Token, agent, cfg.OwnerChatID)
 	case "discord":
 		return NewDiscord(cfg.Token, agent, cfg.GuildID, cfg.OwnerID, cfg.TrustedChannel)
+	case "web":
+		return NewWeb(cfg.Addr, cfg.Token, agent)
 	default:
 		return nil, fmt.Errorf("unknown bot provider: %s", cfg.Provider)
 	}
@@ -24,3 +26,11 @@ func NewTelegram(token string, agent *agent.Agent, ownerChatID int64) (Bot, erro
 func NewDiscord(token string, agent *agent.Agent, guildID, ownerID, trustedChannel string) (Bot, error) {
 	return newDiscord(token, agent, guildID, ownerID, trustedChannel)
 }
+
+func NewWeb(addr, token string, agent *agent.Agent) (Bot, error) {
+	return newWeb(addr, token, agent)
+}
+
+func NewEmail(cfg EmailConfig, agent *agent.Agent) (Bot, error) {
+	return newEmail(cfg, agent)
+}
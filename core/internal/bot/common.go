@@ -1,8 +1,13 @@
 package bot
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 )
 
 // sessionMu protects active session maps across all bot implementations.
@@ -18,6 +23,9 @@ var stopWords = []string{"stop", "cancel", "abort", "nevermind", "never mind", "
 // This is a shared utility for all bot implementations (Telegram, Discord, future voice).
 func isStopCommand(text string) bool {
 	lower := strings.ToLower(strings.TrimSpace(text))
+	if lower == "/cancel" || strings.HasPrefix(lower, "/cancel@") {
+		return true
+	}
 	for _, word := range stopWords {
 		if lower == word {
 			return true
@@ -26,5 +34,99 @@ func isStopCommand(text string) bool {
 	return false
 }
 
+// pinEmoji is the reaction that pins a message to memory, where the chat
+// delivers reactions to bots
+const pinEmoji = "📌"
+
+// isPinCommand checks if the message is /pin, which saves the message it
+// replies to in memory word for word
+func isPinCommand(text string) bool {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	return lower == "/pin" || strings.HasPrefix(lower, "/pin@")
+}
+
+// pinReply is the confirmation sent after pinning a message
+func pinReply(err error) string {
+	if err != nil {
+		return "Couldn't pin that: " + err.Error()
+	}
+	return pinEmoji + " Pinned to memory."
+}
+
+// maxCommands is how many commands Telegram and Discord accept per bot
+const maxCommands = 100
+
+// commandDescription fits a command description into a chat app's limit
+func commandDescription(desc string, limit int) string {
+	desc = strings.Join(strings.Fields(desc), " ")
+	if runes := []rune(desc); len(runes) > limit {
+		desc = string(runes[:limit-3]) + "..."
+	}
+	return desc
+}
+
+// ErrFileTooLarge is returned when a file is bigger than the chat's provider
+// accepts
+var ErrFileTooLarge = errors.New("file too large for this chat")
+
+// mediaFilename names a photo or video after its sniffed type, since Discord
+// and browsers choose how to preview an attachment from its extension
+func mediaFilename(base string, data []byte, fallbackExt string) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return base + ".jpg"
+	case "image/png":
+		return base + ".png"
+	case "image/gif":
+		return base + ".gif"
+	case "image/webp":
+		return base + ".webp"
+	case "video/mp4":
+		return base + ".mp4"
+	case "video/webm":
+		return base + ".webm"
+	}
+	return base + fallbackExt
+}
+
+// maxPhotoDimension is the longest edge a photo is sent at; bigger ones are
+// scaled down whatever the provider allows
+const maxPhotoDimension = 4096
+
+// photoFitTimeout bounds compressing one photo
+const photoFitTimeout = 30 * time.Second
+
 // maxMediaSize is the maximum size for media attachments (20MB).
 const maxMediaSize = 20 * 1024 * 1024
+
+// isAudio reports whether a mime type is a voice note or audio clip.
+// application/ogg is what http.DetectContentType returns for opus voice notes.
+func isAudio(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "audio/") || mimeType == "application/ogg"
+}
+
+// nameTrigger matches Sheldon being addressed by name in a group chat, without an @mention
+var nameTrigger = regexp.MustCompile(`(?i)\bsheldon\b`)
+
+// maxQuotedChars caps how much of a replied-to message is quoted into the turn
+const maxQuotedChars = 1500
+
+// withReplyContext puts the message being replied to in front of the user's
+// text, so "this" and "that" resolve even after it left the recent buffer
+func withReplyContext(text, author, quoted string) string {
+	quoted = strings.TrimSpace(quoted)
+	if quoted == "" {
+		return text
+	}
+	if runes := []rune(quoted); len(runes) > maxQuotedChars {
+		quoted = string(runes[:maxQuotedChars]) + "..."
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[In reply to %s:]\n", author)
+	for _, line := range strings.Split(quoted, "\n") {
+		sb.WriteString("> " + line + "\n")
+	}
+	sb.WriteString("\n" + text)
+	return sb.String()
+}
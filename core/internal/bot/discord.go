@@ -6,17 +6,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bowerhall/sheldon/internal/agent"
+	"github.com/bowerhall/sheldon/internal/documents"
 	"github.com/bowerhall/sheldon/internal/llm"
 	"github.com/bowerhall/sheldon/internal/logger"
 	"github.com/bwmarrin/discordgo"
 )
 
 type discord struct {
+	pager
 	session          *discordgo.Session
 	agent            *agent.Agent
 	guildID          string
@@ -24,9 +27,13 @@ type discord struct {
 	trustedChannel   string
 	ctx              context.Context
 	activeSessions   map[string]context.CancelFunc
+	threadsSeen      map[string]bool // threads whose starter message was already quoted
 	approvalCallback ApprovalCallback
 }
 
+// discordMaxMessageLength is Discord's limit on characters per message
+const discordMaxMessageLength = 2000
+
 func newDiscord(token string, agent *agent.Agent, guildID, ownerID, trustedChannel string) (Bot, error) {
 	session, err := discordgo.New("Bot " + token)
 	if err != nil {
@@ -40,10 +47,12 @@ func newDiscord(token string, agent *agent.Agent, guildID, ownerID, trustedChann
 		ownerID:        ownerID,
 		trustedChannel: trustedChannel,
 		activeSessions: make(map[string]context.CancelFunc),
+		threadsSeen:    make(map[string]bool),
 	}
 
 	session.AddHandler(d.handleMessage)
 	session.AddHandler(d.handleInteraction)
+	session.AddHandler(d.handleReaction)
 
 	return d, nil
 }
@@ -54,6 +63,9 @@ func (d *discord) Start(ctx context.Context) error {
 	if err := d.session.Open(); err != nil {
 		return err
 	}
+	if err := d.PublishCommands(); err != nil {
+		logger.Warn("failed to publish discord commands", "error", err)
+	}
 
 	<-ctx.Done()
 	return d.session.Close()
@@ -61,13 +73,14 @@ func (d *discord) Start(ctx context.Context) error {
 
 func (d *discord) Send(chatID int64, message string) error {
 	channelID := fmt.Sprintf("%d", chatID)
-	_, err := d.session.ChannelMessageSend(channelID, message)
-	if err != nil {
-		logger.Error("discord send failed", "error", err, "channelID", channelID)
-	} else {
-		logger.Info("discord message sent", "channelID", channelID, "chars", len(message))
+	for _, chunk := range d.chunks(message) {
+		if _, err := d.session.ChannelMessageSend(channelID, chunk); err != nil {
+			logger.Error("discord send failed", "error", err, "channelID", channelID)
+			return err
+		}
 	}
-	return err
+	logger.Info("discord message sent", "channelID", channelID, "chars", len(message))
+	return nil
 }
 
 func (d *discord) SendTyping(chatID int64) error {
@@ -82,7 +95,7 @@ func (d *discord) SendPhoto(chatID int64, data []byte, caption string) error {
 		Content: caption,
 		Files: []*discordgo.File{
 			{
-				Name:   "image.png",
+				Name:   mediaFilename("image", data, ".png"),
 				Reader: reader,
 			},
 		},
@@ -102,7 +115,7 @@ func (d *discord) SendVideo(chatID int64, data []byte, caption string) error {
 		Content: caption,
 		Files: []*discordgo.File{
 			{
-				Name:   "video.mp4",
+				Name:   mediaFilename("video", data, ".mp4"),
 				Reader: reader,
 			},
 		},
@@ -135,6 +148,17 @@ func (d *discord) SendDocument(chatID int64, data []byte, filename, caption stri
 	return err
 }
 
+// chunks renders a reply for Discord and splits it into messages
+func (d *discord) chunks(text string) []string {
+	return d.paginate(markdownToDiscord(text), discordMaxMessageLength)
+}
+
+// Capabilities reports Discord's limits: 10 MB per file on servers without
+// boosts, and captions go in the message body
+func (d *discord) Capabilities() Capabilities {
+	return Capabilities{MaxFileSize: 10 << 20, MaxCaption: discordMaxMessageLength, WebP: true}
+}
+
 func (d *discord) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID {
 		return
@@ -155,6 +179,28 @@ func (d *discord) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 func (d *discord) processMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 	channelID := m.ChannelID
 	sessionID := fmt.Sprintf("discord:%s", channelID)
+	userID, _ := strconv.ParseInt(m.Author.ID, 10, 64)
+
+	// guild channels are shared, so only answer when addressed (the trusted channel stays one-on-one)
+	group := m.GuildID != "" && channelID != d.trustedChannel
+	speaker := discordSpeaker(m)
+	if isPinCommand(m.Content) {
+		if m.ReferencedMessage == nil {
+			s.ChannelMessageSend(channelID, "Reply to a message with /pin, or react to it with "+pinEmoji+", to save it to memory.")
+			return
+		}
+		d.pin(s, m.ReferencedMessage, m.GuildID)
+		return
+	}
+	if group {
+		if !d.addressed(s, m) {
+			d.agent.Observe(sessionID, userID, speaker, m.Content)
+			return
+		}
+		botID := s.State.User.ID
+		m.Content = strings.NewReplacer("<@"+botID+">", "", "<@!"+botID+">", "").Replace(m.Content)
+		m.Content = strings.TrimSpace(m.Content)
+	}
 
 	// Check for stop command
 	if isStopCommand(m.Content) {
@@ -207,6 +253,21 @@ func (d *discord) processMessage(s *discordgo.Session, m *discordgo.MessageCreat
 			continue
 		}
 
+		// voice messages and audio clips become text via the transcriber
+		if isAudio(att.ContentType) || isAudio(mimeType) {
+			if att.ContentType != "" {
+				mimeType = att.ContentType
+			}
+			transcription, err := d.agent.Transcribe(opCtx, data, mimeType)
+			if err != nil {
+				logger.Error("failed to transcribe voice", "error", err)
+				transcription = "[Voice message - transcription failed]"
+			}
+			text = strings.TrimSpace(text + "\n\n" + transcription)
+			logger.Info("voice transcribed", "session", sessionID, "chars", len(transcription))
+			continue
+		}
+
 		var mediaType llm.MediaType
 		switch {
 		case strings.HasPrefix(mimeType, "image/"):
@@ -215,6 +276,8 @@ func (d *discord) processMessage(s *discordgo.Session, m *discordgo.MessageCreat
 			mediaType = llm.MediaTypeVideo
 		case mimeType == "application/pdf":
 			mediaType = llm.MediaTypePDF
+		case documents.IsDOCX(mimeType, att.Filename):
+			mediaType = llm.MediaTypeDocument
 		default:
 			logger.Warn("unsupported attachment type", "mimeType", mimeType)
 			continue
@@ -224,10 +287,13 @@ func (d *discord) processMessage(s *discordgo.Session, m *discordgo.MessageCreat
 			Type:     mediaType,
 			Data:     data,
 			MimeType: mimeType,
+			Filename: att.Filename,
 		})
 		logger.Info("attachment received", "type", mediaType, "size", len(data))
 	}
 
+	text = d.withReplyContext(s, m, text)
+
 	// Determine if this is a trusted context (can access sensitive facts)
 	trusted := d.isTrusted(m)
 
@@ -256,11 +322,25 @@ func (d *discord) processMessage(s *discordgo.Session, m *discordgo.MessageCreat
 		}
 	}()
 
-	userID, _ := strconv.ParseInt(m.Author.ID, 10, 64)
+	stream := newMessageStream(discordMaxMessageLength, func(text string) (string, error) {
+		sent, err := s.ChannelMessageSendReply(m.ChannelID, text, m.Reference())
+		if err != nil {
+			return "", err
+		}
+		return sent.ID, nil
+	}, func(id, text string) error {
+		_, err := s.ChannelMessageEdit(m.ChannelID, id, text)
+		return err
+	})
+
 	response, err := d.agent.ProcessWithOptions(opCtx, sessionID, text, agent.ProcessOptions{
-		Media:   media,
-		Trusted: trusted,
-		UserID:  userID,
+		Media:      media,
+		Trusted:    trusted,
+		UserID:     userID,
+		Group:      group,
+		Speaker:    speaker,
+		OnStream:   stream.Update,
+		OnProgress: stream.Status,
 	})
 	close(typingDone)
 	if err != nil {
@@ -272,14 +352,148 @@ func (d *discord) processMessage(s *discordgo.Session, m *discordgo.MessageCreat
 		response = "Something went wrong."
 	}
 
-	if _, err := s.ChannelMessageSendReply(m.ChannelID, response, m.Reference()); err != nil {
-		logger.Error("discord reply failed", "error", err)
-	} else {
-		logger.Info("reply sent", "chars", len(response))
+	// replace the streamed preview with the first part of the final
+	// response and send the rest after it
+	chunks := d.chunks(response)
+	streamed := false
+	if id := stream.MessageID(); id != "" {
+		if _, err := s.ChannelMessageEdit(m.ChannelID, id, chunks[0]); err != nil {
+			logger.Warn("failed to finalize streamed reply", "error", err)
+		} else {
+			chunks = chunks[1:]
+			streamed = true
+		}
+	}
+
+	for i, chunk := range chunks {
+		var err error
+		if i == 0 && !streamed {
+			_, err = s.ChannelMessageSendReply(m.ChannelID, chunk, m.Reference())
+		} else {
+			_, err = s.ChannelMessageSend(m.ChannelID, chunk)
+		}
+		if err != nil {
+			logger.Error("discord reply failed", "error", err)
+			return
+		}
+	}
+	logger.Info("reply sent", "chars", len(response), "streamed", streamed, "messages", len(chunks))
+}
+
+// addressed reports whether a guild message is meant for Sheldon: an @mention,
+// a reply to one of its messages, or calling it by name
+func (d *discord) addressed(s *discordgo.Session, m *discordgo.MessageCreate) bool {
+	botID := s.State.User.ID
+	for _, u := range m.Mentions {
+		if u.ID == botID {
+			return true
+		}
+	}
+	if ref := m.ReferencedMessage; ref != nil && ref.Author != nil && ref.Author.ID == botID {
+		return true
+	}
+	return nameTrigger.MatchString(m.Content)
+}
+
+// handleReaction pins a message to memory when someone reacts to it with 📌
+func (d *discord) handleReaction(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	if r.Emoji.Name != pinEmoji || r.UserID == s.State.User.ID {
+		return
+	}
+
+	// same restrictions as messages: the configured guild, or the owner's DMs
+	isOwnerDM := r.GuildID == "" && d.ownerID != "" && r.UserID == d.ownerID
+	if !isOwnerDM && d.guildID != "" && r.GuildID != d.guildID {
+		return
+	}
+
+	msg, err := s.ChannelMessage(r.ChannelID, r.MessageID)
+	if err != nil {
+		logger.Warn("failed to fetch reacted message", "error", err, "channelID", r.ChannelID)
+		return
+	}
+	d.pin(s, msg, r.GuildID)
+}
+
+// pin saves a message in memory word for word and confirms in a reply to it.
+// Fetched and referenced messages come without their guild, so it's passed in.
+func (d *discord) pin(s *discordgo.Session, msg *discordgo.Message, guildID string) {
+	msg.GuildID = guildID
+	sessionID := fmt.Sprintf("discord:%s", msg.ChannelID)
+	group := guildID != "" && msg.ChannelID != d.trustedChannel
+
+	author := ""
+	switch {
+	case msg.Author != nil && msg.Author.ID == s.State.User.ID:
+		author = "Sheldon"
+	case group && msg.Author != nil:
+		author = discordSpeaker(&discordgo.MessageCreate{Message: msg})
+	}
+
+	err := d.agent.Pin(d.ctx, sessionID, author, msg.Content)
+	if err != nil {
+		logger.Warn("failed to pin message", "session", sessionID, "error", err)
 	}
+	s.ChannelMessageSendReply(msg.ChannelID, pinReply(err), msg.Reference())
+}
+
+// discordSpeaker is the name a guild member is attributed by, preferring their server nickname
+func discordSpeaker(m *discordgo.MessageCreate) string {
+	if m.Member != nil && m.Member.Nick != "" {
+		return m.Member.Nick
+	}
+	if m.Author.GlobalName != "" {
+		return m.Author.GlobalName
+	}
+	return m.Author.Username
 }
 
 // isTrusted returns true if the message is from a trusted source (owner DM or trusted channel)
+// withReplyContext quotes the message being replied to or, for the first
+// message in a thread, the message the thread was started from
+func (d *discord) withReplyContext(s *discordgo.Session, m *discordgo.MessageCreate, text string) string {
+	quoted := m.ReferencedMessage
+	if quoted == nil {
+		quoted = d.threadStarter(s, m.ChannelID)
+	}
+	if quoted == nil || quoted.Author == nil {
+		return text
+	}
+
+	author := quoted.Author.Username
+	if quoted.Author.ID == s.State.User.ID {
+		author = "you (Sheldon)"
+	}
+	return withReplyContext(text, author, quoted.Content)
+}
+
+func (d *discord) threadStarter(s *discordgo.Session, channelID string) *discordgo.Message {
+	sessionMu.Lock()
+	seen := d.threadsSeen[channelID]
+	d.threadsSeen[channelID] = true
+	sessionMu.Unlock()
+	if seen {
+		return nil
+	}
+
+	ch, err := s.State.Channel(channelID)
+	if err != nil {
+		if ch, err = s.Channel(channelID); err != nil {
+			return nil
+		}
+	}
+	if !ch.IsThread() {
+		return nil
+	}
+
+	// threads started from a message share its ID
+	starter, err := s.ChannelMessage(ch.ParentID, ch.ID)
+	if err != nil {
+		return nil
+	}
+	return starter
+}
+
 func (d *discord) isTrusted(m *discordgo.MessageCreate) bool {
 	// Owner DM: no guild ID means DM, and author matches owner
 	if d.ownerID != "" && m.GuildID == "" && m.Author.ID == d.ownerID {
@@ -320,31 +534,22 @@ func (d *discord) downloadAttachment(url string) ([]byte, string, error) {
 	return data, mimeType, nil
 }
 
+// SendWithButtons puts the buttons on the last message when the text has to
+// be split
 func (d *discord) SendWithButtons(chatID int64, message string, buttons []Button) (int64, error) {
 	channelID := fmt.Sprintf("%d", chatID)
 
-	var components []discordgo.MessageComponent
-	var actionRowButtons []discordgo.MessageComponent
-
-	for _, b := range buttons {
-		style := discordgo.SuccessButton
-		if strings.HasSuffix(b.CallbackID, ":deny") {
-			style = discordgo.DangerButton
+	chunks := d.chunks(message)
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if _, err := d.session.ChannelMessageSend(channelID, chunk); err != nil {
+			logger.Error("discord send with buttons failed", "error", err, "channelID", channelID)
+			return 0, err
 		}
-		actionRowButtons = append(actionRowButtons, discordgo.Button{
-			Label:    b.Label,
-			Style:    style,
-			CustomID: b.CallbackID,
-		})
 	}
 
-	components = append(components, discordgo.ActionsRow{
-		Components: actionRowButtons,
-	})
-
 	msg, err := d.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
-		Content:    message,
-		Components: components,
+		Content:    chunks[len(chunks)-1],
+		Components: discordComponents(buttons),
 	})
 	if err != nil {
 		logger.Error("discord send with buttons failed", "error", err, "channelID", channelID)
@@ -356,11 +561,136 @@ func (d *discord) SendWithButtons(chatID int64, message string, buttons []Button
 	return msgID, nil
 }
 
+// EditWithButtons replaces the content and buttons of a message; nil buttons removes them
+func (d *discord) EditWithButtons(chatID, messageID int64, message string, buttons []Button) error {
+	components := discordComponents(buttons)
+	message = markdownToDiscord(message)
+	_, err := d.session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    fmt.Sprintf("%d", chatID),
+		ID:         strconv.FormatInt(messageID, 10),
+		Content:    &message,
+		Components: &components,
+	})
+	return err
+}
+
+func discordComponents(buttons []Button) []discordgo.MessageComponent {
+	if len(buttons) == 0 {
+		return []discordgo.MessageComponent{}
+	}
+
+	var actionRowButtons []discordgo.MessageComponent
+	for _, b := range buttons {
+		// destructive choices are red: deny on approvals, forget on memory review
+		destructive := strings.HasSuffix(b.CallbackID, ":deny")
+		if strings.HasPrefix(b.CallbackID, reviewPrefix) {
+			destructive = !destructive
+		}
+		style := discordgo.SuccessButton
+		if destructive {
+			style = discordgo.DangerButton
+		}
+		actionRowButtons = append(actionRowButtons, discordgo.Button{
+			Label:    b.Label,
+			Style:    style,
+			CustomID: b.CallbackID,
+		})
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: actionRowButtons},
+	}
+}
+
 func (d *discord) SetApprovalCallback(fn ApprovalCallback) {
 	d.approvalCallback = fn
 }
 
+// discordCommandName matches what Discord accepts as a slash command name
+var discordCommandName = regexp.MustCompile(`^[-_a-z0-9]{1,32}$`)
+
+// PublishCommands registers the core commands and installed skills as slash
+// commands, in the configured guild if there is one (those update instantly)
+// or globally. Skills take their key=value arguments as one text option.
+func (d *discord) PublishCommands() error {
+	if d.session.State == nil || d.session.State.User == nil {
+		return fmt.Errorf("discord session not open")
+	}
+
+	var commands []*discordgo.ApplicationCommand
+	for _, cmd := range d.agent.Commands() {
+		if !discordCommandName.MatchString(cmd.Name) {
+			logger.Debug("skipping command discord can't register", "command", cmd.Name)
+			continue
+		}
+		if len(commands) == maxCommands {
+			logger.Warn("too many commands for discord, skipping the rest", "max", maxCommands)
+			break
+		}
+		ac := &discordgo.ApplicationCommand{
+			Name:        cmd.Name,
+			Description: commandDescription(cmd.Description, 100),
+		}
+		if cmd.Skill {
+			ac.Options = []*discordgo.ApplicationCommandOption{{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "args",
+				Description: "Arguments, e.g. city=Berlin budget=1500",
+			}}
+		}
+		commands = append(commands, ac)
+	}
+
+	if _, err := d.session.ApplicationCommandBulkOverwrite(d.session.State.User.ID, d.guildID, commands); err != nil {
+		return err
+	}
+	logger.Info("discord commands published", "count", len(commands))
+	return nil
+}
+
+// handleCommand runs a slash command as if the user had typed it
+func (d *discord) handleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	text := "/" + data.Name
+	for _, opt := range data.Options {
+		if opt.Name == "args" {
+			text += " " + opt.StringValue()
+		}
+	}
+
+	author := i.User
+	if i.Member != nil && i.Member.User != nil {
+		author = i.Member.User
+	}
+	if author == nil {
+		return
+	}
+
+	// slash commands need an answer within 3 seconds; echo the command and
+	// reply to it like any other message
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: text},
+	}); err != nil {
+		logger.Error("failed to respond to discord command", "error", err, "command", data.Name)
+		return
+	}
+
+	d.handleMessage(s, &discordgo.MessageCreate{Message: &discordgo.Message{
+		ChannelID: i.ChannelID,
+		GuildID:   i.GuildID,
+		Author:    author,
+		Member:    i.Member,
+		Content:   text,
+		Mentions:  []*discordgo.User{s.State.User}, // a slash command is addressed to us
+	}})
+}
+
 func (d *discord) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type == discordgo.InteractionApplicationCommand {
+		d.handleCommand(s, i)
+		return
+	}
 	if i.Type != discordgo.InteractionMessageComponent {
 		return
 	}
@@ -378,33 +708,20 @@ func (d *discord) handleInteraction(s *discordgo.Session, i *discordgo.Interacti
 		userID, _ = strconv.ParseInt(i.Member.User.ID, 10, 64)
 	}
 
-	var approvalID string
-	var approved bool
-
-	if len(data) > 8 && data[len(data)-8:] == ":approve" {
-		approvalID = data[:len(data)-8]
-		approved = true
-	} else if len(data) > 5 && data[len(data)-5:] == ":deny" {
-		approvalID = data[:len(data)-5]
-		approved = false
-	} else {
+	approvalID, approved, ok := parseApprovalCallback(data)
+	if !ok {
 		logger.Warn("unknown interaction format", "data", data)
 		return
 	}
 
 	d.approvalCallback(approvalID, approved, userID)
 
-	var resultText string
-	if approved {
-		resultText = "Approved"
-	} else {
-		resultText = "Denied"
-	}
+	resultText := callbackResult(approvalID, approved)
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
-			Content:    i.Message.Content + "\n\n" + resultText,
+			Content:    stripCountdown(i.Message.Content) + "\n\n" + resultText,
 			Components: []discordgo.MessageComponent{},
 		},
 	})
@@ -0,0 +1,396 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/agent"
+	"github.com/bowerhall/sheldon/internal/documents"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/mailbox"
+)
+
+// approvalReplyPattern matches "approve <id>" / "deny <id>" replies to approval requests
+var approvalReplyPattern = regexp.MustCompile(`(?i)^\s*(approve|deny)\s+([a-zA-Z0-9-]+)`)
+
+// incomingEmail is the parsed subset of a message the channel cares about
+type incomingEmail struct {
+	from       string
+	verified   bool // the trusted receiving server authenticated the From domain
+	subject    string
+	messageID  string
+	inReplyTo  string
+	references []string
+	text       string
+	media      []llm.MediaContent
+}
+
+func newEmail(cfg EmailConfig, agent *agent.Agent) (Bot, error) {
+	if cfg.IMAPAddr == "" || cfg.SMTPAddr == "" {
+		return nil, fmt.Errorf("email channel requires IMAP and SMTP addresses")
+	}
+	if cfg.OwnerAddress == "" && len(cfg.AllowedSenders) == 0 {
+		return nil, fmt.Errorf("email channel requires an owner address or allowed senders")
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Minute
+	}
+
+	return &email{
+		agent:          agent,
+		cfg:            cfg,
+		threads:        make(map[int64]*emailThread),
+		activeSessions: make(map[int64]context.CancelFunc),
+	}, nil
+}
+
+func (e *email) Start(ctx context.Context) error {
+	e.ctx = ctx
+
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+
+	logger.Info("email channel polling", "imap", e.cfg.IMAPAddr, "interval", e.cfg.PollInterval)
+	e.poll()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			e.poll()
+		}
+	}
+}
+
+// poll fetches unread messages, marks them read, and hands each to the agent
+func (e *email) poll() {
+	client, err := mailbox.Dial(e.cfg.IMAPAddr, 60*time.Second)
+	if err != nil {
+		logger.Error("email poll failed", "error", err)
+		return
+	}
+	defer client.Close()
+
+	if err := client.Login(e.cfg.Username, e.cfg.Password); err != nil {
+		logger.Error("imap login failed", "error", err)
+		return
+	}
+	if err := client.Select("INBOX"); err != nil {
+		logger.Error("imap select failed", "error", err)
+		return
+	}
+
+	uids, err := client.SearchUnseen()
+	if err != nil {
+		logger.Error("imap search failed", "error", err)
+		return
+	}
+
+	for _, uid := range uids {
+		raw, err := client.Fetch(uid)
+		if err != nil {
+			logger.Error("imap fetch failed", "uid", uid, "error", err)
+			continue
+		}
+		if err := client.MarkSeen(uid); err != nil {
+			logger.Warn("imap mark seen failed", "uid", uid, "error", err)
+		}
+
+		msg, err := parseEmail(raw, e.cfg.AuthservID)
+		if err != nil {
+			logger.Warn("failed to parse email", "uid", uid, "error", err)
+			continue
+		}
+
+		go e.handleMessage(msg)
+	}
+}
+
+func (e *email) isAllowed(address string) bool {
+	if address == e.cfg.OwnerAddress {
+		return true
+	}
+	for _, a := range e.cfg.AllowedSenders {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *email) handleMessage(msg *incomingEmail) {
+	if !e.isAllowed(msg.from) {
+		logger.Warn("ignoring email from unauthorized sender", "from", msg.from)
+		return
+	}
+
+	// each thread is a session, keyed by its root message ID
+	root := msg.messageID
+	if len(msg.references) > 0 {
+		root = msg.references[0]
+	} else if msg.inReplyTo != "" {
+		root = msg.inReplyTo
+	}
+	chatID := emailChatID(root)
+	sessionID := fmt.Sprintf("email:%d", chatID)
+	userID := emailChatID(msg.from)
+
+	e.mu.Lock()
+	e.threads[chatID] = &emailThread{
+		address:    msg.from,
+		subject:    msg.subject,
+		lastID:     msg.messageID,
+		references: append(msg.references, msg.messageID),
+	}
+	e.mu.Unlock()
+
+	if m := approvalReplyPattern.FindStringSubmatch(msg.text); m != nil && e.approvalCallback != nil {
+		// anyone can put the owner's address in From, so approvals need proof
+		if !msg.verified {
+			logger.Warn("ignoring approval from unauthenticated email", "from", msg.from, "approvalID", m[2])
+			return
+		}
+		approved := strings.EqualFold(m[1], "approve")
+		e.approvalCallback(m[2], approved, userID)
+		logger.Info("email approval received", "approvalID", m[2], "approved", approved)
+		return
+	}
+
+	if isStopCommand(msg.text) {
+		sessionMu.Lock()
+		if cancel, ok := e.activeSessions[chatID]; ok {
+			cancel()
+			delete(e.activeSessions, chatID)
+			sessionMu.Unlock()
+			logger.Info("operation cancelled by user", "session", sessionID)
+			e.Send(chatID, "Stopped.")
+			return
+		}
+		sessionMu.Unlock()
+		e.Send(chatID, "Nothing to stop.")
+		return
+	}
+
+	sessionMu.Lock()
+	if cancel, ok := e.activeSessions[chatID]; ok {
+		cancel()
+		delete(e.activeSessions, chatID)
+	}
+
+	opCtx, cancel := context.WithCancel(e.ctx)
+	e.activeSessions[chatID] = cancel
+	sessionMu.Unlock()
+
+	defer func() {
+		sessionMu.Lock()
+		delete(e.activeSessions, chatID)
+		sessionMu.Unlock()
+	}()
+
+	trusted := e.cfg.OwnerAddress != "" && msg.from == e.cfg.OwnerAddress && msg.verified
+
+	logger.Info("email received", "session", sessionID, "from", msg.from, "subject", truncate(msg.subject, 50), "attachments", len(msg.media), "verified", msg.verified, "trusted", trusted)
+
+	text := msg.text
+	if msg.subject != "" && !strings.HasPrefix(strings.ToLower(msg.subject), "re:") {
+		text = fmt.Sprintf("Subject: %s\n\n%s", msg.subject, text)
+	}
+
+	response, err := e.agent.ProcessWithOptions(opCtx, sessionID, text, agent.ProcessOptions{
+		Media:   msg.media,
+		Trusted: trusted,
+		UserID:  userID,
+	})
+	if err != nil {
+		if opCtx.Err() == context.Canceled {
+			logger.Info("operation was cancelled", "session", sessionID)
+			return
+		}
+		logger.Error("agent failed", "error", err)
+		response = "Something went wrong."
+	}
+
+	if response == "" {
+		return
+	}
+
+	if err := e.Send(chatID, response); err != nil {
+		logger.Error("email reply failed", "error", err)
+	} else {
+		logger.Info("reply sent", "chars", len(response))
+	}
+}
+
+// emailChatID derives a stable positive chat ID from a message ID or address
+func emailChatID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(key)))
+	return int64(h.Sum64() & 0x7fffffffffffffff)
+}
+
+// parseEmail reads a raw message, trusting the sender only if authservID, the
+// receiving server, authenticated it
+func parseEmail(raw []byte, authservID string) (*incomingEmail, error) {
+	msg, err := mailbox.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &incomingEmail{
+		from:       msg.From,
+		verified:   msg.AuthenticatedBy(authservID),
+		subject:    msg.Subject,
+		messageID:  msg.MessageID,
+		inReplyTo:  msg.InReplyTo,
+		references: msg.References,
+		text:       mailbox.StripQuotedReply(msg.Text),
+	}
+
+	for _, part := range msg.Attachments {
+		switch {
+		case strings.HasPrefix(part.MediaType, "image/"):
+			result.media = append(result.media, llm.MediaContent{Type: llm.MediaTypeImage, Data: part.Data, MimeType: part.MediaType})
+		case strings.HasPrefix(part.MediaType, "video/"):
+			result.media = append(result.media, llm.MediaContent{Type: llm.MediaTypeVideo, Data: part.Data, MimeType: part.MediaType})
+		case isPDF(part.MediaType):
+			result.media = append(result.media, llm.MediaContent{Type: llm.MediaTypePDF, Data: part.Data, MimeType: part.MediaType, Filename: part.Filename})
+		case documents.IsDOCX(part.MediaType, part.Filename):
+			result.media = append(result.media, llm.MediaContent{Type: llm.MediaTypeDocument, Data: part.Data, MimeType: documents.DOCXMimeType, Filename: part.Filename})
+		}
+	}
+
+	return result, nil
+}
+
+// thread returns the thread for a chat, falling back to a fresh thread with the owner
+func (e *email) thread(chatID int64) (*emailThread, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if t, ok := e.threads[chatID]; ok {
+		return t, nil
+	}
+
+	if e.cfg.OwnerAddress == "" {
+		return nil, fmt.Errorf("no email thread for chat %d", chatID)
+	}
+
+	t := &emailThread{address: e.cfg.OwnerAddress, subject: "Message from Sheldon"}
+	e.threads[chatID] = t
+	return t, nil
+}
+
+func (e *email) send(chatID int64, body string, attachment *mailbox.Part) error {
+	t, err := e.thread(chatID)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	subject := t.subject
+	if t.lastID != "" && !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+	out := mailbox.Outgoing{
+		From:       e.cfg.From,
+		To:         t.address,
+		Subject:    subject,
+		InReplyTo:  t.lastID,
+		References: append([]string(nil), t.references...),
+		Body:       body,
+		Attachment: attachment,
+	}
+	e.mu.Unlock()
+
+	msg, messageID := mailbox.Compose(out)
+	if err := mailbox.Send(e.cfg.SMTPAddr, e.cfg.Username, e.cfg.Password, e.cfg.From, t.address, msg); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	t.subject = subject
+	t.lastID = messageID
+	t.references = append(t.references, messageID)
+	e.mu.Unlock()
+
+	return nil
+}
+
+func (e *email) Send(chatID int64, message string) error {
+	err := e.send(chatID, message, nil)
+	if err != nil {
+		logger.Error("email send failed", "error", err, "chatID", chatID)
+	} else {
+		logger.Info("email sent", "chatID", chatID, "chars", len(message))
+	}
+	return err
+}
+
+// SendTyping is a no-op: email has no presence indicator
+func (e *email) SendTyping(chatID int64) error {
+	return nil
+}
+
+func (e *email) SendPhoto(chatID int64, data []byte, caption string) error {
+	return e.SendDocument(chatID, data, mediaFilename("image", data, ""), caption)
+}
+
+func (e *email) SendVideo(chatID int64, data []byte, caption string) error {
+	return e.SendDocument(chatID, data, mediaFilename("video", data, ".mp4"), caption)
+}
+
+func (e *email) SendDocument(chatID int64, data []byte, filename, caption string) error {
+	err := e.send(chatID, caption, &mailbox.Part{Filename: filename, Data: data})
+	if err != nil {
+		logger.Error("email send attachment failed", "error", err, "chatID", chatID)
+	} else {
+		logger.Info("email attachment sent", "chatID", chatID, "filename", filename)
+	}
+	return err
+}
+
+// Capabilities caps attachments at 25 MB, what most mail servers accept
+func (e *email) Capabilities() Capabilities {
+	return Capabilities{MaxFileSize: 25 << 20}
+}
+
+// SendWithButtons renders buttons as reply instructions since email has no interactive components
+func (e *email) SendWithButtons(chatID int64, message string, buttons []Button) (int64, error) {
+	var options []string
+	for _, b := range buttons {
+		id, action, ok := strings.Cut(b.CallbackID, ":")
+		if !ok {
+			continue
+		}
+		options = append(options, fmt.Sprintf("Reply \"%s %s\" to %s.", action, id, strings.ToLower(b.Label)))
+	}
+
+	body := message
+	if len(options) > 0 {
+		body += "\n\n" + strings.Join(options, "\n")
+	}
+
+	if err := e.send(chatID, body, nil); err != nil {
+		logger.Error("email send with buttons failed", "error", err, "chatID", chatID)
+		return 0, err
+	}
+	return 0, nil
+}
+
+// EditWithButtons is a no-op since sent email cannot be changed
+func (e *email) EditWithButtons(chatID, messageID int64, message string, buttons []Button) error {
+	return nil
+}
+
+func (e *email) SetApprovalCallback(fn ApprovalCallback) {
+	e.approvalCallback = fn
+}
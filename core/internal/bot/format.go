@@ -0,0 +1,318 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Models answer in markdown, which no chat renders as is: Telegram gets it
+// converted to HTML, Discord gets the subset of markdown it understands.
+// Replies over a provider's message limit are split between lines, closing
+// and reopening code blocks that straddle a split.
+
+var (
+	codeFence      = regexp.MustCompile("^\\s*```")
+	tableRow       = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	tableSeparator = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)+\|?\s*$`)
+	heading        = regexp.MustCompile(`^\s*(#{1,6})\s+(.+?)\s*#*\s*$`)
+	horizontalRule = regexp.MustCompile(`^\s*([-*_])\s*(\s*[-*_]){2,}\s*$`)
+	bulletItem     = regexp.MustCompile(`^(\s*)[*+-]\s+`)
+	imageLink      = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+)
+
+// fenceClose is reserved at the end of every chunk in case it has to close
+// a code block
+const fenceClose = "\n```"
+
+// splitMessage breaks text into chunks of at most limit characters, at a
+// paragraph break where one is near, otherwise at a line break. Code blocks
+// cut in two are closed at the end of one chunk and reopened, with their
+// language, at the start of the next.
+func splitMessage(text string, limit int) []string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var chunks []string
+	var lines []string
+	size := 0
+	fence := ""                  // opening line of the code block we're in
+	lastBreak, breakSize := 0, 0 // last blank line outside a code block
+
+	flush := func(chunk []string) {
+		if s := strings.TrimSpace(strings.Join(chunk, "\n")); s != "" {
+			chunks = append(chunks, s)
+		}
+	}
+
+	for _, line := range splitLongLines(text, limit/2) {
+		n := utf8.RuneCountInString(line) + 1
+		if size+n+len(fenceClose) > limit && len(lines) > 0 {
+			switch {
+			case lastBreak > 0 && breakSize > size/2:
+				// a code block still open here started after the break, so
+				// it moves to the next chunk whole
+				flush(lines[:lastBreak])
+				lines = append([]string(nil), lines[lastBreak+1:]...)
+			case fence != "":
+				flush(append(lines, "```"))
+				lines = []string{fence}
+			default:
+				flush(lines)
+				lines = nil
+			}
+			size = 0
+			for _, l := range lines {
+				size += utf8.RuneCountInString(l) + 1
+			}
+			lastBreak, breakSize = 0, 0
+		}
+
+		lines = append(lines, line)
+		size += n
+
+		switch {
+		case codeFence.MatchString(line):
+			if fence == "" {
+				fence = strings.TrimSpace(line)
+			} else {
+				fence = ""
+			}
+		case fence == "" && strings.TrimSpace(line) == "":
+			lastBreak, breakSize = len(lines)-1, size
+		}
+	}
+	flush(lines)
+
+	return chunks
+}
+
+// splitLongLines splits text into lines, breaking any longer than max at
+// the last space before it
+func splitLongLines(text string, max int) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		for utf8.RuneCountInString(line) > max {
+			runes := []rune(line)
+			cut := strings.LastIndex(string(runes[:max]), " ")
+			if cut <= 0 {
+				cut = len(string(runes[:max]))
+			}
+			out = append(out, line[:cut])
+			line = strings.TrimLeft(line[cut:], " ")
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// mapOutsideCode rewrites each line of text that isn't inside a code block
+func mapOutsideCode(text string, fn func(line string) string) string {
+	lines := strings.Split(text, "\n")
+	inCode := false
+	for i, line := range lines {
+		if codeFence.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			lines[i] = fn(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fenceTables puts markdown tables in code blocks with their columns
+// padded, since neither Telegram nor Discord renders tables
+func fenceTables(text string) string {
+	lines := strings.Split(text, "\n")
+	var out []string
+	inCode := false
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if codeFence.MatchString(line) {
+			inCode = !inCode
+		}
+		if inCode || !tableRow.MatchString(line) || i+1 >= len(lines) || !tableSeparator.MatchString(lines[i+1]) {
+			out = append(out, line)
+			continue
+		}
+
+		var rows [][]string
+		for ; i < len(lines) && tableRow.MatchString(lines[i]); i++ {
+			rows = append(rows, tableCells(lines[i]))
+		}
+		i--
+		out = append(out, "```")
+		out = append(out, formatTable(rows)...)
+		out = append(out, "```")
+	}
+	return strings.Join(out, "\n")
+}
+
+func tableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(c)
+	}
+	return cells
+}
+
+// formatTable pads cells to their column's width; the second row is the
+// header separator and is redrawn to match
+func formatTable(rows [][]string) []string {
+	var widths []int
+	for i, row := range rows {
+		if i == 1 {
+			continue
+		}
+		for j, cell := range row {
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], utf8.RuneCountInString(cell))
+		}
+	}
+
+	out := make([]string, 0, len(rows))
+	for i, row := range rows {
+		cells := make([]string, len(widths))
+		for j, w := range widths {
+			switch {
+			case i == 1:
+				cells[j] = strings.Repeat("-", w)
+			case j < len(row):
+				cells[j] = row[j] + strings.Repeat(" ", w-utf8.RuneCountInString(row[j]))
+			default:
+				cells[j] = strings.Repeat(" ", w)
+			}
+		}
+		out = append(out, strings.TrimRight(strings.Join(cells, " | "), " "))
+	}
+	return out
+}
+
+// markdownToDiscord rewrites what Discord shows literally: tables, headings
+// below ###, horizontal rules and inline images
+func markdownToDiscord(text string) string {
+	text = fenceTables(text)
+	return mapOutsideCode(text, func(line string) string {
+		if horizontalRule.MatchString(line) {
+			return ""
+		}
+		if m := heading.FindStringSubmatch(line); m != nil && len(m[1]) > 3 {
+			return "**" + m[2] + "**"
+		}
+		return imageLink.ReplaceAllString(line, "[$1]($2)")
+	})
+}
+
+// markdownToTelegramHTML converts common markdown to Telegram-safe HTML
+// Uses placeholder approach to prevent formatting inside code blocks and URLs
+func markdownToTelegramHTML(text string) string {
+	// Telegram has no headings, lists or rules, so turn them into text the
+	// inline formatting below won't misread
+	text = mapOutsideCode(fenceTables(text), func(line string) string {
+		if horizontalRule.MatchString(line) {
+			return ""
+		}
+		if m := heading.FindStringSubmatch(line); m != nil {
+			return "**" + m[2] + "**"
+		}
+		return bulletItem.ReplaceAllString(line, "$1• ")
+	})
+
+	// Escape HTML special chars first
+	text = html.EscapeString(text)
+
+	// Extract code blocks, inline code, links and URLs - replace with placeholders
+	var codeBlocks []string
+	var inlineCodes []string
+	var links []string
+	var urls []string
+
+	// Code blocks: ```lang\ncode``` → placeholder
+	codeBlock := regexp.MustCompile("```([\\w+#-]*)\\n?([\\s\\S]*?)```")
+	text = codeBlock.ReplaceAllStringFunc(text, func(m string) string {
+		inner := codeBlock.FindStringSubmatch(m)
+		code := strings.TrimSuffix(inner[2], "\n")
+		if inner[1] != "" {
+			code = fmt.Sprintf("<code class=\"language-%s\">%s</code>", inner[1], code)
+		}
+		codeBlocks = append(codeBlocks, code)
+		return fmt.Sprintf("\x00CODEBLOCK%d\x00", len(codeBlocks)-1)
+	})
+
+	// Inline code: `code` → placeholder
+	inlineCode := regexp.MustCompile("`([^`]+)`")
+	text = inlineCode.ReplaceAllStringFunc(text, func(m string) string {
+		inner := inlineCode.FindStringSubmatch(m)[1]
+		inlineCodes = append(inlineCodes, inner)
+		return fmt.Sprintf("\x00INLINE%d\x00", len(inlineCodes)-1)
+	})
+
+	// Links: [text](url) and ![alt](url) → <a href="url">text</a>
+	link := regexp.MustCompile(`!?\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	text = link.ReplaceAllStringFunc(text, func(m string) string {
+		inner := link.FindStringSubmatch(m)
+		links = append(links, fmt.Sprintf("<a href=\"%s\">%s</a>", inner[2], inner[1]))
+		return fmt.Sprintf("\x00LINK%d\x00", len(links)-1)
+	})
+
+	// URLs: protect from underscore/asterisk formatting
+	// Exclude * to avoid capturing markdown bold markers like **url**
+	urlPattern := regexp.MustCompile(`https?://[^\s<>"*]+`)
+	text = urlPattern.ReplaceAllStringFunc(text, func(m string) string {
+		urls = append(urls, m)
+		return fmt.Sprintf("\x00URL%d\x00", len(urls)-1)
+	})
+
+	// Now process formatting on text without code
+	// Bold: **text** or __text__ → <b>text</b>
+	bold := regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	text = bold.ReplaceAllStringFunc(text, func(m string) string {
+		inner := bold.FindStringSubmatch(m)
+		if inner[1] != "" {
+			return "<b>" + inner[1] + "</b>"
+		}
+		return "<b>" + inner[2] + "</b>"
+	})
+
+	// Italic: *text* or _text_ → <i>text</i>
+	italic := regexp.MustCompile(`\*([^*]+)\*|_([^_]+)_`)
+	text = italic.ReplaceAllStringFunc(text, func(m string) string {
+		inner := italic.FindStringSubmatch(m)
+		if inner[1] != "" {
+			return "<i>" + inner[1] + "</i>"
+		}
+		return "<i>" + inner[2] + "</i>"
+	})
+
+	// Strikethrough: ~~text~~ → <s>text</s>
+	strike := regexp.MustCompile(`~~(.+?)~~`)
+	text = strike.ReplaceAllString(text, "<s>$1</s>")
+
+	// Restore code blocks, links and URLs. strings.Replace, since code may
+	// contain $ that a regexp replacement would expand
+	for i, code := range codeBlocks {
+		text = strings.Replace(text, fmt.Sprintf("\x00CODEBLOCK%d\x00", i), "<pre>"+code+"</pre>", 1)
+	}
+	for i, code := range inlineCodes {
+		text = strings.Replace(text, fmt.Sprintf("\x00INLINE%d\x00", i), "<code>"+code+"</code>", 1)
+	}
+	for i, l := range links {
+		text = strings.Replace(text, fmt.Sprintf("\x00LINK%d\x00", i), l, 1)
+	}
+	for i, url := range urls {
+		text = strings.Replace(text, fmt.Sprintf("\x00URL%d\x00", i), url, 1)
+	}
+
+	return text
+}
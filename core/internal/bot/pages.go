@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const (
+	// maxPages is the most messages a reply is split into; anything longer
+	// (logs, reports) is uploaded and linked instead when there's storage
+	maxPages = 4
+	// pageMarkerRoom is kept free in each page for its "(2/3)" marker
+	pageMarkerRoom = 12
+	// longTextPreview is how much of a linked reply is posted in the chat
+	longTextPreview = 1000
+	longTextTimeout = 30 * time.Second
+)
+
+// SetLongTextStore lets bots upload replies too long to post and link them
+func (r *Router) SetLongTextStore(store LongTextStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.longText = store
+	for _, b := range r.bots {
+		if p, ok := b.(interface{ setLongTextStore(LongTextStore) }); ok {
+			p.setLongTextStore(store)
+		}
+	}
+}
+
+func (p *pager) setLongTextStore(store LongTextStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.store = store
+}
+
+// paginate splits a reply into messages of at most limit characters,
+// numbered so it's clear more is coming. A reply that would take more than
+// maxPages messages is uploaded, and the chat gets its opening and a link.
+func (p *pager) paginate(text string, limit int) []string {
+	chunks := splitMessage(text, limit)
+	if len(chunks) == 1 {
+		return chunks
+	}
+
+	p.mu.RLock()
+	store := p.store
+	p.mu.RUnlock()
+
+	if len(chunks) > maxPages && store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), longTextTimeout)
+		defer cancel()
+
+		url, err := store(ctx, text)
+		if err == nil {
+			preview := splitMessage(text, longTextPreview)[0]
+			logger.Info("long reply linked", "chars", utf8.RuneCountInString(text), "pages", len(chunks))
+			return []string{fmt.Sprintf("%s\n\n…\n\nFull response (%d characters): %s", preview, utf8.RuneCountInString(text), url)}
+		}
+		logger.Warn("failed to upload long reply, sending it in pages", "error", err)
+	}
+
+	chunks = splitMessage(text, limit-pageMarkerRoom)
+	for i := range chunks {
+		chunks[i] += fmt.Sprintf("\n\n(%d/%d)", i+1, len(chunks))
+	}
+	return chunks
+}
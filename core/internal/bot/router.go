@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/imaging"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// NewRouter creates a router. lookup resolves chats not seen since startup
+// (e.g. from the conversation buffer); it may be nil.
+func NewRouter(lookup SessionLookup) *Router {
+	return &Router{
+		bots:   make(map[string]Bot),
+		chats:  make(map[int64]string),
+		lookup: lookup,
+	}
+}
+
+// Add registers a bot under its provider name. The first bot added
+// receives messages for chats whose provider is unknown.
+func (r *Router) Add(provider string, b Bot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bots[provider] = b
+	if p, ok := b.(interface{ setLongTextStore(LongTextStore) }); ok && r.longText != nil {
+		p.setLongTextStore(r.longText)
+	}
+	if r.fallback == "" {
+		r.fallback = provider
+	}
+}
+
+// Track records which provider a session belongs to ("provider:chatID")
+func (r *Router) Track(sessionID string) {
+	provider, chatID, ok := parseSessionID(sessionID)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, known := r.bots[provider]; known {
+		r.chats[chatID] = provider
+	}
+}
+
+// Provider returns the provider that owns a chat
+func (r *Router) Provider(chatID int64) string {
+	r.mu.RLock()
+	provider, ok := r.chats[chatID]
+	r.mu.RUnlock()
+	if ok {
+		return provider
+	}
+
+	if r.lookup != nil {
+		sessionID, err := r.lookup(chatID)
+		if err != nil {
+			logger.Warn("session lookup failed", "chatID", chatID, "error", err)
+		} else if sessionID != "" {
+			r.Track(sessionID)
+
+			r.mu.RLock()
+			provider, ok = r.chats[chatID]
+			r.mu.RUnlock()
+			if ok {
+				return provider
+			}
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.fallback
+}
+
+// SessionID returns the session ID for a chat on its owning provider
+func (r *Router) SessionID(chatID int64) string {
+	return fmt.Sprintf("%s:%d", r.Provider(chatID), chatID)
+}
+
+func (r *Router) bot(chatID int64) (Bot, error) {
+	provider := r.Provider(chatID)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b, ok := r.bots[provider]
+	if !ok {
+		return nil, fmt.Errorf("no bot registered for chat %d", chatID)
+	}
+	return b, nil
+}
+
+func (r *Router) Send(chatID int64, message string) error {
+	b, err := r.bot(chatID)
+	if err != nil {
+		return err
+	}
+	return b.Send(chatID, message)
+}
+
+func (r *Router) SendTyping(chatID int64) error {
+	b, err := r.bot(chatID)
+	if err != nil {
+		return err
+	}
+	return b.SendTyping(chatID)
+}
+
+func (r *Router) SendPhoto(chatID int64, data []byte, caption string) error {
+	data, caption = r.fitPhoto(chatID, data, caption)
+	return r.sendFile(chatID, len(data), "photo", caption, func(b Bot, caption string) error {
+		return b.SendPhoto(chatID, data, caption)
+	})
+}
+
+func (r *Router) SendVideo(chatID int64, data []byte, caption string) error {
+	return r.sendFile(chatID, len(data), "video", caption, func(b Bot, caption string) error {
+		return b.SendVideo(chatID, data, caption)
+	})
+}
+
+func (r *Router) SendDocument(chatID int64, data []byte, filename, caption string) error {
+	return r.sendFile(chatID, len(data), filename, caption, func(b Bot, caption string) error {
+		return b.SendDocument(chatID, data, filename, caption)
+	})
+}
+
+// sendFile fits a file to the chat's provider: files over its size limit
+// are refused up front, and a caption too long to attach goes out as a
+// message of its own first
+func (r *Router) sendFile(chatID int64, size int, name, caption string, send func(b Bot, caption string) error) error {
+	b, err := r.bot(chatID)
+	if err != nil {
+		return err
+	}
+
+	caps := b.Capabilities()
+	if caps.MaxFileSize > 0 && int64(size) > caps.MaxFileSize {
+		return fmt.Errorf("%w: %s is %.1f MB, %s accepts up to %.0f MB; share a download link instead",
+			ErrFileTooLarge, name, float64(size)/(1<<20), r.Provider(chatID), float64(caps.MaxFileSize)/(1<<20))
+	}
+	if caps.MaxCaption > 0 && len([]rune(caption)) > caps.MaxCaption {
+		if err := b.Send(chatID, caption); err != nil {
+			return err
+		}
+		caption = ""
+	}
+	return send(b, caption)
+}
+
+// SetOriginalStore keeps the originals of photos compressed to fit a chat
+func (r *Router) SetOriginalStore(store OriginalStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.originals = store
+}
+
+// fitPhoto scales down and recompresses a photo that's over the provider's
+// limit or maxPhotoDimension. The original goes to storage first, and the
+// caption says where. If it can't be compressed the photo is sent as is.
+func (r *Router) fitPhoto(chatID int64, data []byte, caption string) ([]byte, string) {
+	b, err := r.bot(chatID)
+	if err != nil {
+		return data, caption
+	}
+
+	caps := b.Capabilities()
+	limits := imaging.Limits{MaxBytes: caps.MaxPhotoSize, MaxDimension: maxPhotoDimension, WebP: caps.WebP}
+	if limits.MaxBytes == 0 {
+		limits.MaxBytes = caps.MaxFileSize
+	}
+	if !imaging.NeedsFit(data, limits) {
+		return data, caption
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), photoFitTimeout)
+	defer cancel()
+
+	fitted, err := imaging.Fit(ctx, data, limits)
+	if err != nil {
+		logger.Warn("photo compression failed", "chatID", chatID, "size", len(data), "error", err)
+		return data, caption
+	}
+	logger.Info("photo compressed to fit", "chatID", chatID, "from", len(data), "to", len(fitted.Data), "format", fitted.MimeType)
+
+	r.mu.RLock()
+	originals := r.originals
+	r.mu.RUnlock()
+	if originals != nil {
+		path, err := originals(ctx, data)
+		if err != nil {
+			logger.Warn("failed to store original photo", "error", err)
+		} else {
+			caption = strings.TrimSpace(caption + "\n\nFull size: " + path)
+		}
+	}
+	return fitted.Data, caption
+}
+
+// MaxFileSize is the largest file the chat's provider accepts, 0 if it has
+// no limit
+func (r *Router) MaxFileSize(chatID int64) int64 {
+	b, err := r.bot(chatID)
+	if err != nil {
+		return 0
+	}
+	return b.Capabilities().MaxFileSize
+}
+
+func (r *Router) SendWithButtons(chatID int64, message string, buttons []Button) (int64, error) {
+	b, err := r.bot(chatID)
+	if err != nil {
+		return 0, err
+	}
+	return b.SendWithButtons(chatID, message, buttons)
+}
+
+func (r *Router) EditWithButtons(chatID, messageID int64, message string, buttons []Button) error {
+	b, err := r.bot(chatID)
+	if err != nil {
+		return err
+	}
+	return b.EditWithButtons(chatID, messageID, message, buttons)
+}
+
+func parseSessionID(sessionID string) (string, int64, bool) {
+	provider, id, ok := strings.Cut(sessionID, ":")
+	if !ok {
+		return "", 0, false
+	}
+	chatID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return provider, chatID, true
+}
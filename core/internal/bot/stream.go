@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// streamEditInterval throttles message edits while a response streams in.
+// Telegram and Discord both rate limit edits, roughly one per second per chat.
+const streamEditInterval = time.Second
+
+// streamCursor is appended to partial text so the user can tell more is coming
+const streamCursor = " ..."
+
+func newMessageStream(limit int, send func(text string) (string, error), edit func(id, text string) error) *messageStream {
+	return &messageStream{limit: limit, send: send, edit: edit}
+}
+
+// Update shows the latest partial text, posting the message on first call and
+// editing it afterwards. Calls arriving within streamEditInterval are coalesced.
+func (s *messageStream) Update(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	preview := text
+	if runes := []rune(preview); len(runes)+len(streamCursor) > s.limit {
+		preview = string(runes[:s.limit-len(streamCursor)])
+	}
+	s.show(preview + streamCursor)
+}
+
+// Status shows a progress line such as "still working: deploy app" in the
+// same message, which the final reply replaces
+func (s *messageStream) Status(status string) {
+	if strings.TrimSpace(status) == "" {
+		return
+	}
+	s.show("⏳ " + status + "…")
+}
+
+func (s *messageStream) show(preview string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastEdit) < streamEditInterval || preview == s.shown {
+		return
+	}
+
+	if s.id == "" {
+		id, err := s.send(preview)
+		if err != nil {
+			logger.Warn("stream send failed", "error", err)
+			return
+		}
+		s.id = id
+	} else if err := s.edit(s.id, preview); err != nil {
+		logger.Warn("stream edit failed", "error", err)
+	}
+
+	s.shown = preview
+	s.lastEdit = time.Now()
+}
+
+// MessageID returns the ID of the streamed message, or "" if nothing was posted yet
+func (s *messageStream) MessageID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
@@ -3,92 +3,22 @@ package bot
 import (
 	"context"
 	"fmt"
-	"html"
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bowerhall/sheldon/internal/agent"
+	"github.com/bowerhall/sheldon/internal/documents"
 	"github.com/bowerhall/sheldon/internal/llm"
 	"github.com/bowerhall/sheldon/internal/logger"
-	"github.com/bowerhall/sheldon/internal/transcribe"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// markdownToTelegramHTML converts common markdown to Telegram-safe HTML
-// Uses placeholder approach to prevent formatting inside code blocks and URLs
-func markdownToTelegramHTML(text string) string {
-	// Escape HTML special chars first
-	text = html.EscapeString(text)
-
-	// Extract code blocks, inline code, and URLs - replace with placeholders
-	var codeBlocks []string
-	var inlineCodes []string
-	var urls []string
-
-	// Code blocks: ```code``` → placeholder
-	codeBlock := regexp.MustCompile("```([\\s\\S]*?)```")
-	text = codeBlock.ReplaceAllStringFunc(text, func(m string) string {
-		inner := codeBlock.FindStringSubmatch(m)[1]
-		codeBlocks = append(codeBlocks, inner)
-		return fmt.Sprintf("\x00CODEBLOCK%d\x00", len(codeBlocks)-1)
-	})
-
-	// Inline code: `code` → placeholder
-	inlineCode := regexp.MustCompile("`([^`]+)`")
-	text = inlineCode.ReplaceAllStringFunc(text, func(m string) string {
-		inner := inlineCode.FindStringSubmatch(m)[1]
-		inlineCodes = append(inlineCodes, inner)
-		return fmt.Sprintf("\x00INLINE%d\x00", len(inlineCodes)-1)
-	})
-
-	// URLs: protect from underscore/asterisk formatting
-	// Exclude * to avoid capturing markdown bold markers like **url**
-	urlPattern := regexp.MustCompile(`https?://[^\s<>"*]+`)
-	text = urlPattern.ReplaceAllStringFunc(text, func(m string) string {
-		urls = append(urls, m)
-		return fmt.Sprintf("\x00URL%d\x00", len(urls)-1)
-	})
-
-	// Now process formatting on text without code
-	// Bold: **text** or __text__ → <b>text</b>
-	bold := regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
-	text = bold.ReplaceAllStringFunc(text, func(m string) string {
-		inner := bold.FindStringSubmatch(m)
-		if inner[1] != "" {
-			return "<b>" + inner[1] + "</b>"
-		}
-		return "<b>" + inner[2] + "</b>"
-	})
-
-	// Italic: *text* or _text_ → <i>text</i>
-	italic := regexp.MustCompile(`\*([^*]+)\*|_([^_]+)_`)
-	text = italic.ReplaceAllStringFunc(text, func(m string) string {
-		inner := italic.FindStringSubmatch(m)
-		if inner[1] != "" {
-			return "<i>" + inner[1] + "</i>"
-		}
-		return "<i>" + inner[2] + "</i>"
-	})
-
-	// Strikethrough: ~~text~~ → <s>text</s>
-	strike := regexp.MustCompile(`~~(.+?)~~`)
-	text = strike.ReplaceAllString(text, "<s>$1</s>")
-
-	// Restore code blocks and URLs
-	for i, code := range codeBlocks {
-		text = regexp.MustCompile(fmt.Sprintf("\x00CODEBLOCK%d\x00", i)).ReplaceAllString(text, "<pre>"+code+"</pre>")
-	}
-	for i, code := range inlineCodes {
-		text = regexp.MustCompile(fmt.Sprintf("\x00INLINE%d\x00", i)).ReplaceAllString(text, "<code>"+code+"</code>")
-	}
-	for i, url := range urls {
-		text = regexp.MustCompile(fmt.Sprintf("\x00URL%d\x00", i)).ReplaceAllString(text, url)
-	}
-
-	return text
-}
+// telegramMaxMessageLength is Telegram's limit on characters per message
+const telegramMaxMessageLength = 4096
 
 func newTelegram(token string, agent *agent.Agent, ownerChatID int64) (Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
@@ -105,6 +35,10 @@ func newTelegram(token string, agent *agent.Agent, ownerChatID int64) (Bot, erro
 }
 
 func (t *telegram) Start(ctx context.Context) error {
+	if err := t.PublishCommands(); err != nil {
+		logger.Warn("failed to publish telegram commands", "error", err)
+	}
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 	updates := t.api.GetUpdatesChan(u)
@@ -138,6 +72,23 @@ func (t *telegram) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	chatID := msg.Chat.ID
 	sessionID := fmt.Sprintf("telegram:%d", chatID)
 
+	// in groups only answer when addressed; everything else is kept as context
+	group := msg.Chat.IsGroup() || msg.Chat.IsSuperGroup()
+	speaker := telegramSpeaker(msg.From)
+	if isPinCommand(msg.Text) {
+		t.pin(ctx, msg, sessionID, group)
+		return
+	}
+	if group {
+		if !t.addressed(msg) {
+			t.agent.Observe(sessionID, msg.From.ID, speaker, msg.Text+msg.Caption)
+			return
+		}
+		mention := "@" + t.api.Self.UserName
+		msg.Text = strings.TrimSpace(strings.ReplaceAll(msg.Text, mention, ""))
+		msg.Caption = strings.TrimSpace(strings.ReplaceAll(msg.Caption, mention, ""))
+	}
+
 	// Check for stop command
 	if isStopCommand(msg.Text) {
 		sessionMu.Lock()
@@ -231,30 +182,51 @@ func (t *telegram) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 				Type:     llm.MediaTypePDF,
 				Data:     data,
 				MimeType: "application/pdf",
+				Filename: msg.Document.FileName,
 			})
 		}
 
 		text = msg.Caption
 		logger.Info("PDF received", "session", sessionID, "from", msg.From.UserName, "filename", msg.Document.FileName, "caption", truncate(text, 50))
-	} else if msg.Voice != nil {
-		data, mimeType, err := t.downloadFile(msg.Voice.FileID)
+	} else if msg.Document != nil && documents.IsDOCX(msg.Document.MimeType, msg.Document.FileName) {
+		data, _, err := t.downloadFile(msg.Document.FileID)
 		if err != nil {
-			logger.Error("failed to download voice", "error", err)
+			logger.Error("failed to download document", "error", err)
 		} else {
-			transcription, err := transcribe.Transcribe(data, mimeType)
-			if err != nil {
-				logger.Error("failed to transcribe voice", "error", err)
-				text = "[Voice message - transcription failed]"
-			} else {
-				text = transcription
-				logger.Info("voice transcribed", "session", sessionID, "from", msg.From.UserName, "duration", msg.Voice.Duration, "chars", len(transcription))
-			}
+			media = append(media, llm.MediaContent{
+				Type:     llm.MediaTypeDocument,
+				Data:     data,
+				MimeType: documents.DOCXMimeType,
+				Filename: msg.Document.FileName,
+			})
 		}
+
+		text = msg.Caption
+		logger.Info("document received", "session", sessionID, "from", msg.From.UserName, "filename", msg.Document.FileName, "caption", truncate(text, 50))
+	} else if msg.Voice != nil {
+		text = t.transcribe(opCtx, msg.Voice.FileID, msg.Voice.MimeType)
+		logger.Info("voice received", "session", sessionID, "from", msg.From.UserName, "duration", msg.Voice.Duration, "chars", len(text))
+	} else if msg.Audio != nil {
+		text = t.transcribe(opCtx, msg.Audio.FileID, msg.Audio.MimeType)
+		if msg.Caption != "" {
+			text = msg.Caption + "\n\n" + text
+		}
+		logger.Info("audio received", "session", sessionID, "from", msg.From.UserName, "duration", msg.Audio.Duration, "chars", len(text))
 	} else {
 		text = msg.Text
 		logger.Info("message received", "session", sessionID, "from", msg.From.UserName, "text", truncate(text, 50))
 	}
 
+	if reply := msg.ReplyToMessage; reply != nil {
+		author := "someone"
+		if reply.From != nil && reply.From.ID == t.api.Self.ID {
+			author = "you (Sheldon)"
+		} else if reply.From != nil {
+			author = reply.From.FirstName
+		}
+		text = withReplyContext(text, author, strings.TrimSpace(reply.Text+"\n"+reply.Caption))
+	}
+
 	// send typing indicator while processing
 	t.SendTyping(chatID)
 	typingDone := make(chan struct{})
@@ -273,10 +245,26 @@ func (t *telegram) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 		}
 	}()
 
+	stream := newMessageStream(telegramMaxMessageLength, func(text string) (string, error) {
+		preview := tgbotapi.NewMessage(chatID, text)
+		preview.ReplyToMessageID = msg.MessageID
+		sent, err := t.api.Send(preview)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(sent.MessageID), nil
+	}, func(id, text string) error {
+		return t.editMessage(chatID, id, text, "")
+	})
+
 	response, err := t.agent.ProcessWithOptions(opCtx, sessionID, text, agent.ProcessOptions{
-		Media:   media,
-		Trusted: true,
-		UserID:  msg.From.ID,
+		Media:      media,
+		Trusted:    !group,
+		UserID:     msg.From.ID,
+		Group:      group,
+		Speaker:    speaker,
+		OnStream:   stream.Update,
+		OnProgress: stream.Status,
 	})
 	close(typingDone)
 	if err != nil {
@@ -288,26 +276,184 @@ func (t *telegram) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 		response = "Something went wrong."
 	}
 
-	reply := tgbotapi.NewMessage(chatID, markdownToTelegramHTML(response))
-	reply.ReplyToMessageID = msg.MessageID
-	reply.ParseMode = tgbotapi.ModeHTML
+	// replace the streamed preview with the first part of the formatted
+	// final response and send the rest after it
+	chunks := t.chunks(response)
+	streamed := false
+	if id := stream.MessageID(); id != "" {
+		if err := t.editText(chatID, id, chunks[0]); err != nil {
+			logger.Warn("failed to finalize streamed reply", "error", err)
+		} else {
+			chunks = chunks[1:]
+			streamed = true
+		}
+	}
+
+	for i, chunk := range chunks {
+		reply := tgbotapi.NewMessage(chatID, chunk)
+		if i == 0 && !streamed {
+			reply.ReplyToMessageID = msg.MessageID
+		}
+		if _, err := t.sendText(reply); err != nil {
+			logger.Error("send failed", "error", err)
+			return
+		}
+	}
+	logger.Info("reply sent", "chars", len(response), "streamed", streamed, "messages", len(chunks))
+}
+
+// addressed reports whether a group message is meant for Sheldon: an @mention,
+// a reply to one of its messages, or calling it by name
+func (t *telegram) addressed(msg *tgbotapi.Message) bool {
+	if reply := msg.ReplyToMessage; reply != nil && reply.From != nil && reply.From.ID == t.api.Self.ID {
+		return true
+	}
+
+	text := msg.Text + " " + msg.Caption
+	if strings.Contains(strings.ToLower(text), "@"+strings.ToLower(t.api.Self.UserName)) {
+		return true
+	}
+	return nameTrigger.MatchString(text)
+}
+
+// pin saves the message a /pin replies to and reacts to it once saved.
+// Telegram only sends reactions to bots on request and this client can't ask,
+// so reacting with 📌 can't trigger a pin the way it does on Discord.
+func (t *telegram) pin(ctx context.Context, msg *tgbotapi.Message, sessionID string, group bool) {
+	reply := msg.ReplyToMessage
+	if reply == nil {
+		t.api.Send(tgbotapi.NewMessage(msg.Chat.ID, "Reply to a message with /pin to save it to memory."))
+		return
+	}
 
-	if _, err := t.api.Send(reply); err != nil {
-		logger.Error("send failed", "error", err)
+	author := ""
+	switch {
+	case reply.From != nil && reply.From.ID == t.api.Self.ID:
+		author = "Sheldon"
+	case group:
+		author = telegramSpeaker(reply.From)
+	}
+
+	err := t.agent.Pin(ctx, sessionID, author, strings.TrimSpace(reply.Text+"\n"+reply.Caption))
+	if err != nil {
+		logger.Warn("failed to pin message", "session", sessionID, "error", err)
 	} else {
-		logger.Info("reply sent", "chars", len(response))
+		t.react(msg.Chat.ID, reply.MessageID, pinReaction)
 	}
+
+	confirm := tgbotapi.NewMessage(msg.Chat.ID, pinReply(err))
+	confirm.ReplyToMessageID = reply.MessageID
+	t.api.Send(confirm)
+}
+
+// pinReaction marks a pinned message. 📌 isn't among the emoji Telegram
+// allows as reactions, so the writing hand stands in for it.
+const pinReaction = "✍"
+
+// react sets the bot's reaction on a message. The client predates
+// setMessageReaction, so the request is made by hand.
+func (t *telegram) react(chatID int64, messageID int, emoji string) {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("message_id", messageID)
+	if err := params.AddInterface("reaction", []map[string]string{{"type": "emoji", "emoji": emoji}}); err != nil {
+		return
+	}
+	if _, err := t.api.MakeRequest("setMessageReaction", params); err != nil {
+		logger.Warn("failed to react to message", "chat", chatID, "error", err)
+	}
+}
+
+// telegramSpeaker is the name a group member is attributed by
+func telegramSpeaker(user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		name = user.UserName
+	}
+	return name
+}
+
+// transcribe downloads a voice/audio file and returns its transcript, or a
+// placeholder the agent can react to if transcription isn't possible
+func (t *telegram) transcribe(ctx context.Context, fileID, mimeType string) string {
+	data, detected, err := t.downloadFile(fileID)
+	if err != nil {
+		logger.Error("failed to download voice", "error", err)
+		return "[Voice message - download failed]"
+	}
+	if mimeType == "" {
+		mimeType = detected
+	}
+
+	transcription, err := t.agent.Transcribe(ctx, data, mimeType)
+	if err != nil {
+		logger.Error("failed to transcribe voice", "error", err)
+		return "[Voice message - transcription failed]"
+	}
+
+	return transcription
 }
 
 func (t *telegram) Send(chatID int64, message string) error {
-	msg := tgbotapi.NewMessage(chatID, markdownToTelegramHTML(message))
+	for _, chunk := range t.chunks(message) {
+		if _, err := t.sendText(tgbotapi.NewMessage(chatID, chunk)); err != nil {
+			logger.Error("proactive send failed", "error", err, "chatID", chatID)
+			return err
+		}
+	}
+	logger.Info("proactive message sent", "chatID", chatID, "chars", len(message))
+	return nil
+}
+
+// chunks splits a reply into messages that fit Telegram once rendered
+func (t *telegram) chunks(text string) []string {
+	return t.paginate(fenceTables(text), telegramMaxMessageLength)
+}
+
+// isEntityError reports whether Telegram rejected a message's HTML, as it
+// does when a split leaves a tag unbalanced
+func isEntityError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "can't parse entities")
+}
+
+// sendText sends a markdown message as HTML, falling back to the raw text
+// if Telegram can't parse the conversion
+func (t *telegram) sendText(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	markdown := msg.Text
+	msg.Text = markdownToTelegramHTML(markdown)
 	msg.ParseMode = tgbotapi.ModeHTML
-	_, err := t.api.Send(msg)
+	sent, err := t.api.Send(msg)
+	if isEntityError(err) {
+		logger.Debug("telegram rejected html, sending plain text", "error", err)
+		msg.Text = markdown
+		msg.ParseMode = ""
+		sent, err = t.api.Send(msg)
+	}
+	return sent, err
+}
+
+// editText replaces a message with markdown rendered as HTML, or as plain
+// text if Telegram can't parse it
+func (t *telegram) editText(chatID int64, id, markdown string) error {
+	err := t.editMessage(chatID, id, markdownToTelegramHTML(markdown), tgbotapi.ModeHTML)
+	if isEntityError(err) {
+		logger.Debug("telegram rejected html, editing as plain text", "error", err)
+		err = t.editMessage(chatID, id, markdown, "")
+	}
+	return err
+}
+
+func (t *telegram) editMessage(chatID int64, id, text, parseMode string) error {
+	messageID, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Error("proactive send failed", "error", err, "chatID", chatID)
-	} else {
-		logger.Info("proactive message sent", "chatID", chatID, "chars", len(message))
+		return err
 	}
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = parseMode
+	_, err = t.api.Send(edit)
 	return err
 }
 
@@ -385,6 +531,42 @@ func (t *telegram) SendDocument(chatID int64, data []byte, filename, caption str
 	return err
 }
 
+// Capabilities reports the Bot API's upload limits: 50 MB per file, 10 MB
+// per photo and 1024 characters per caption. WebP photos arrive as stickers.
+func (t *telegram) Capabilities() Capabilities {
+	return Capabilities{MaxFileSize: 50 << 20, MaxPhotoSize: 10 << 20, MaxCaption: 1024}
+}
+
+// telegramCommandName matches what Telegram accepts as a command
+var telegramCommandName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// PublishCommands sets the bot's command menu to the core commands and
+// installed skills. Hyphens become underscores, which the agent maps back.
+func (t *telegram) PublishCommands() error {
+	var commands []tgbotapi.BotCommand
+	for _, cmd := range t.agent.Commands() {
+		name := strings.ReplaceAll(cmd.Name, "-", "_")
+		if !telegramCommandName.MatchString(name) {
+			logger.Debug("skipping command telegram can't register", "command", cmd.Name)
+			continue
+		}
+		if len(commands) == maxCommands {
+			logger.Warn("too many commands for telegram, skipping the rest", "max", maxCommands)
+			break
+		}
+		commands = append(commands, tgbotapi.BotCommand{
+			Command:     name,
+			Description: commandDescription(cmd.Description, 256),
+		})
+	}
+
+	if _, err := t.api.Request(tgbotapi.NewSetMyCommands(commands...)); err != nil {
+		return err
+	}
+	logger.Info("telegram commands published", "count", len(commands))
+	return nil
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -397,21 +579,21 @@ func isPDF(mimeType string) bool {
 	return mimeType == "application/pdf"
 }
 
+// SendWithButtons puts the keyboard on the last message when the text has
+// to be split
 func (t *telegram) SendWithButtons(chatID int64, message string, buttons []Button) (int64, error) {
-	var keyboardButtons []tgbotapi.InlineKeyboardButton
-	for _, b := range buttons {
-		keyboardButtons = append(keyboardButtons, tgbotapi.NewInlineKeyboardButtonData(b.Label, b.CallbackID))
+	chunks := t.chunks(message)
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if _, err := t.sendText(tgbotapi.NewMessage(chatID, chunk)); err != nil {
+			logger.Error("send with buttons failed", "error", err, "chatID", chatID)
+			return 0, err
+		}
 	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(keyboardButtons...),
-	)
-
-	msg := tgbotapi.NewMessage(chatID, markdownToTelegramHTML(message))
-	msg.ReplyMarkup = keyboard
-	msg.ParseMode = tgbotapi.ModeHTML
+	msg := tgbotapi.NewMessage(chatID, chunks[len(chunks)-1])
+	msg.ReplyMarkup = telegramKeyboard(buttons)
 
-	sent, err := t.api.Send(msg)
+	sent, err := t.sendText(msg)
 	if err != nil {
 		logger.Error("send with buttons failed", "error", err, "chatID", chatID)
 		return 0, err
@@ -421,6 +603,30 @@ func (t *telegram) SendWithButtons(chatID int64, message string, buttons []Butto
 	return int64(sent.MessageID), nil
 }
 
+// EditWithButtons replaces the text and inline keyboard of a message; nil buttons removes the keyboard
+func (t *telegram) EditWithButtons(chatID, messageID int64, message string, buttons []Button) error {
+	edit := tgbotapi.NewEditMessageText(chatID, int(messageID), markdownToTelegramHTML(message))
+	edit.ParseMode = tgbotapi.ModeHTML
+	if len(buttons) > 0 {
+		keyboard := telegramKeyboard(buttons)
+		edit.ReplyMarkup = &keyboard
+	}
+
+	_, err := t.api.Send(edit)
+	return err
+}
+
+func telegramKeyboard(buttons []Button) tgbotapi.InlineKeyboardMarkup {
+	var keyboardButtons []tgbotapi.InlineKeyboardButton
+	for _, b := range buttons {
+		keyboardButtons = append(keyboardButtons, tgbotapi.NewInlineKeyboardButtonData(b.Label, b.CallbackID))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(keyboardButtons...),
+	)
+}
+
 func (t *telegram) SetApprovalCallback(fn ApprovalCallback) {
 	t.approvalCallback = fn
 }
@@ -434,16 +640,8 @@ func (t *telegram) handleCallback(callback *tgbotapi.CallbackQuery) {
 	data := callback.Data
 	userID := callback.From.ID
 
-	var approvalID string
-	var approved bool
-
-	if len(data) > 8 && data[len(data)-8:] == ":approve" {
-		approvalID = data[:len(data)-8]
-		approved = true
-	} else if len(data) > 5 && data[len(data)-5:] == ":deny" {
-		approvalID = data[:len(data)-5]
-		approved = false
-	} else {
+	approvalID, approved, ok := parseApprovalCallback(data)
+	if !ok {
 		logger.Warn("unknown callback format", "data", data)
 		return
 	}
@@ -453,12 +651,7 @@ func (t *telegram) handleCallback(callback *tgbotapi.CallbackQuery) {
 	answer := tgbotapi.NewCallback(callback.ID, "")
 	t.api.Request(answer)
 
-	var resultText string
-	if approved {
-		resultText = "Approved"
-	} else {
-		resultText = "Denied"
-	}
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, callback.Message.Text+"\n\n"+resultText)
+	resultText := callbackResult(approvalID, approved)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, stripCountdown(callback.Message.Text)+"\n\n"+resultText)
 	t.api.Send(edit)
 }
@@ -2,9 +2,13 @@ package bot
 
 import (
 	"context"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/agent"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/gorilla/websocket"
 )
 
 type Bot interface {
@@ -15,7 +19,47 @@ type Bot interface {
 	SendVideo(chatID int64, data []byte, caption string) error
 	SendDocument(chatID int64, data []byte, filename, caption string) error
 	SendWithButtons(chatID int64, message string, buttons []Button) (messageID int64, err error)
+	EditWithButtons(chatID, messageID int64, message string, buttons []Button) error
 	SetApprovalCallback(fn ApprovalCallback)
+	Capabilities() Capabilities
+}
+
+// Capabilities is what a provider accepts when sending files, so the router
+// can fit photos, videos and documents to it rather than assume Telegram's
+// limits
+type Capabilities struct {
+	MaxFileSize  int64 // largest attachment in bytes, 0 = no limit
+	MaxPhotoSize int64 // largest photo, when lower than MaxFileSize
+	MaxCaption   int   // longest caption sent with a file, 0 = no limit
+	WebP         bool  // photos can be sent as WebP
+}
+
+// OriginalStore keeps the full-size original of a photo that was compressed
+// to fit a chat and returns where it was stored
+type OriginalStore func(ctx context.Context, data []byte) (string, error)
+
+// LongTextStore uploads a reply too long to post in chat and returns a link
+// to it
+type LongTextStore func(ctx context.Context, text string) (string, error)
+
+// pager splits replies into pages for the bots with a message limit. The
+// store is set after the bots have started, so it's behind a lock.
+type pager struct {
+	mu    sync.RWMutex
+	store LongTextStore
+}
+
+// ButtonSender can send and edit messages with buttons, which is all an
+// approval prompt needs. Both Bot and Router satisfy it.
+type ButtonSender interface {
+	SendWithButtons(chatID int64, message string, buttons []Button) (messageID int64, err error)
+	EditWithButtons(chatID, messageID int64, message string, buttons []Button) error
+}
+
+// CommandPublisher is a bot that offers the agent's slash commands for
+// autocomplete. Publish again whenever skills change.
+type CommandPublisher interface {
+	PublishCommands() error
 }
 
 type Button struct {
@@ -25,6 +69,21 @@ type Button struct {
 
 type ApprovalCallback func(approvalID string, approved bool, userID int64)
 
+// SessionLookup resolves a chat ID to its most recent session ID ("provider:chatID")
+type SessionLookup func(chatID int64) (string, error)
+
+// Router delivers outgoing messages through the bot that owns each chat,
+// so notifications reach users on the provider they actually talk on
+type Router struct {
+	mu        sync.RWMutex
+	bots      map[string]Bot
+	chats     map[int64]string
+	fallback  string
+	lookup    SessionLookup
+	originals OriginalStore
+	longText  LongTextStore
+}
+
 type Config struct {
 	Provider       string
 	Token          string
@@ -32,12 +91,92 @@ type Config struct {
 	GuildID        string // Discord: restrict to this guild/server ID
 	OwnerID        string // Discord: user ID with full access (sensitive facts)
 	TrustedChannel string // Discord: channel ID with full access
+	Addr           string // Web: listen address for the chat gateway
+}
+
+// messageStream progressively edits a single chat message as a response streams in
+type messageStream struct {
+	mu       sync.Mutex
+	limit    int
+	send     func(text string) (string, error)
+	edit     func(id, text string) error
+	id       string
+	shown    string
+	lastEdit time.Time
 }
 
 type telegram struct {
+	pager
 	api              *tgbotapi.BotAPI
 	agent            *agent.Agent
 	ownerChatID      int64
 	activeSessions   map[int64]context.CancelFunc
 	approvalCallback ApprovalCallback
 }
+
+type web struct {
+	agent            *agent.Agent
+	token            string
+	server           *http.Server
+	upgrader         websocket.Upgrader
+	ctx              context.Context
+	mu               sync.Mutex
+	clients          map[int64]map[*webClient]bool
+	activeSessions   map[int64]context.CancelFunc
+	approvalCallback ApprovalCallback
+	nextMessageID    int64
+}
+
+// webClient is a single browser connection. A chat can have several open tabs.
+type webClient struct {
+	conn   *websocket.Conn
+	chatID int64
+	mu     sync.Mutex
+}
+
+// webEvent is the JSON frame exchanged over the websocket in both directions
+type webEvent struct {
+	Type      string      `json:"type"`
+	Text      string      `json:"text,omitempty"`
+	Data      string      `json:"data,omitempty"`
+	Filename  string      `json:"filename,omitempty"`
+	MimeType  string      `json:"mime_type,omitempty"`
+	MessageID int64       `json:"message_id,omitempty"`
+	Buttons   []webButton `json:"buttons,omitempty"`
+}
+
+// EmailConfig configures the IMAP/SMTP email channel
+type EmailConfig struct {
+	IMAPAddr       string
+	SMTPAddr       string
+	Username       string
+	Password       string
+	From           string
+	AllowedSenders []string
+	OwnerAddress   string
+	AuthservID     string // receiving server whose Authentication-Results are trusted
+	PollInterval   time.Duration
+}
+
+type email struct {
+	agent            *agent.Agent
+	cfg              EmailConfig
+	ctx              context.Context
+	mu               sync.Mutex
+	threads          map[int64]*emailThread
+	activeSessions   map[int64]context.CancelFunc
+	approvalCallback ApprovalCallback
+}
+
+// emailThread tracks what is needed to reply in-thread to a conversation
+type emailThread struct {
+	address    string
+	subject    string
+	lastID     string
+	references []string
+}
+
+type webButton struct {
+	Label string `json:"label"`
+	ID    string `json:"id"`
+}
@@ -0,0 +1,421 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/agent"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/gorilla/websocket"
+)
+
+//go:embed web/index.html
+var webIndexHTML []byte
+
+// webOwnerID is the chat and user every web connection belongs to. The token
+// is the owner's, so all their browsers and tabs are one user and one
+// conversation, whatever the client says.
+const webOwnerID int64 = 1
+
+func newWeb(addr, token string, agent *agent.Agent) (Bot, error) {
+	if token == "" {
+		return nil, fmt.Errorf("web chat requires a token")
+	}
+
+	w := &web{
+		agent: agent,
+		token: token,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+		},
+		clients:        make(map[int64]map[*webClient]bool),
+		activeSessions: make(map[int64]context.CancelFunc),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", w.handleIndex)
+	mux.HandleFunc("/ws", w.handleWebSocket)
+
+	w.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return w, nil
+}
+
+func (w *web) Start(ctx context.Context) error {
+	w.ctx = ctx
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("web chat listening", "addr", w.server.Addr)
+		if err := w.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		logger.Error("web chat server failed", "error", err)
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return w.server.Shutdown(shutdownCtx)
+}
+
+func (w *web) handleIndex(rw http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(rw, r)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Write(webIndexHTML)
+}
+
+func (w *web) authorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(w.token)) == 1
+}
+
+func (w *web) handleWebSocket(rw http.ResponseWriter, r *http.Request) {
+	if !w.authorized(r) {
+		logger.Warn("rejecting unauthorized web chat connection", "remote", r.RemoteAddr)
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	chatID := webOwnerID
+
+	conn, err := w.upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		logger.Error("websocket upgrade failed", "error", err)
+		return
+	}
+
+	client := &webClient{conn: conn, chatID: chatID}
+	w.addClient(client)
+	defer w.removeClient(client)
+
+	logger.Info("web chat connected", "chatID", chatID, "remote", r.RemoteAddr)
+
+	conn.SetReadLimit(maxMediaSize * 2)
+	for {
+		var event webEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Debug("web chat read ended", "chatID", chatID, "error", err)
+			}
+			return
+		}
+
+		switch event.Type {
+		case "message":
+			go w.handleMessage(client, event)
+		case "callback":
+			go w.handleCallback(client, event)
+		default:
+			logger.Warn("unknown web chat event", "type", event.Type)
+		}
+	}
+}
+
+func (w *web) addClient(c *webClient) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.clients[c.chatID] == nil {
+		w.clients[c.chatID] = make(map[*webClient]bool)
+	}
+	w.clients[c.chatID][c] = true
+}
+
+func (w *web) removeClient(c *webClient) {
+	w.mu.Lock()
+	delete(w.clients[c.chatID], c)
+	if len(w.clients[c.chatID]) == 0 {
+		delete(w.clients, c.chatID)
+	}
+	w.mu.Unlock()
+
+	c.conn.Close()
+}
+
+func (w *web) handleMessage(client *webClient, event webEvent) {
+	chatID := client.chatID
+	sessionID := fmt.Sprintf("web:%d", chatID)
+
+	// Check for stop command
+	if isStopCommand(event.Text) {
+		sessionMu.Lock()
+		if cancel, ok := w.activeSessions[chatID]; ok {
+			cancel()
+			delete(w.activeSessions, chatID)
+			sessionMu.Unlock()
+			logger.Info("operation cancelled by user", "session", sessionID)
+			w.Send(chatID, "Stopped.")
+			return
+		}
+		sessionMu.Unlock()
+		w.Send(chatID, "Nothing to stop.")
+		return
+	}
+
+	// Cancel any existing operation for this chat before starting new one
+	sessionMu.Lock()
+	if cancel, ok := w.activeSessions[chatID]; ok {
+		cancel()
+		delete(w.activeSessions, chatID)
+	}
+
+	opCtx, cancel := context.WithCancel(w.ctx)
+	w.activeSessions[chatID] = cancel
+	sessionMu.Unlock()
+
+	defer func() {
+		sessionMu.Lock()
+		delete(w.activeSessions, chatID)
+		sessionMu.Unlock()
+	}()
+
+	text := event.Text
+	var media []llm.MediaContent
+	if event.Data != "" {
+		if data, err := base64.StdEncoding.DecodeString(event.Data); err == nil && isAudio(http.DetectContentType(data)) {
+			transcription, err := w.agent.Transcribe(opCtx, data, http.DetectContentType(data))
+			if err != nil {
+				logger.Error("failed to transcribe voice", "error", err)
+				transcription = "[Voice message - transcription failed]"
+			}
+			text = strings.TrimSpace(text + "\n\n" + transcription)
+		} else if m, ok := decodeWebMedia(event); ok {
+			media = append(media, m)
+		}
+	}
+
+	logger.Info("message received", "session", sessionID, "text", truncate(text, 50), "media", len(media))
+
+	// send typing indicator while processing
+	w.SendTyping(chatID)
+	typingDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(4 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-typingDone:
+				return
+			case <-opCtx.Done():
+				return
+			case <-ticker.C:
+				w.SendTyping(chatID)
+			}
+		}
+	}()
+
+	// the gateway is token protected, so anyone connected is the owner
+	response, err := w.agent.ProcessWithOptions(opCtx, sessionID, text, agent.ProcessOptions{
+		Media:   media,
+		Trusted: true,
+		UserID:  webOwnerID,
+		OnProgress: func(status string) {
+			w.broadcast(chatID, webEvent{Type: "status", Text: status})
+		},
+	})
+	close(typingDone)
+	if err != nil {
+		if opCtx.Err() == context.Canceled {
+			logger.Info("operation was cancelled", "session", sessionID)
+			return
+		}
+		logger.Error("agent failed", "error", err)
+		response = "Something went wrong."
+	}
+
+	if response == "" {
+		return
+	}
+
+	if err := w.Send(chatID, response); err != nil {
+		logger.Error("web chat reply failed", "error", err)
+	} else {
+		logger.Info("reply sent", "chars", len(response))
+	}
+}
+
+// decodeWebMedia converts an uploaded attachment into media for the agent
+func decodeWebMedia(event webEvent) (llm.MediaContent, bool) {
+	data, err := base64.StdEncoding.DecodeString(event.Data)
+	if err != nil {
+		logger.Warn("invalid web chat attachment", "error", err)
+		return llm.MediaContent{}, false
+	}
+	if len(data) > maxMediaSize {
+		logger.Warn("attachment too large, skipping", "size", len(data), "max", maxMediaSize)
+		return llm.MediaContent{}, false
+	}
+
+	mimeType := http.DetectContentType(data)
+
+	var mediaType llm.MediaType
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		mediaType = llm.MediaTypeImage
+	case strings.HasPrefix(mimeType, "video/"):
+		mediaType = llm.MediaTypeVideo
+	case isPDF(mimeType):
+		mediaType = llm.MediaTypePDF
+	default:
+		logger.Warn("unsupported attachment type", "mimeType", mimeType)
+		return llm.MediaContent{}, false
+	}
+
+	return llm.MediaContent{Type: mediaType, Data: data, MimeType: mimeType}, true
+}
+
+func (w *web) handleCallback(client *webClient, event webEvent) {
+	if w.approvalCallback == nil {
+		logger.Warn("received callback but no handler set", "data", event.Data)
+		return
+	}
+
+	data := event.Data
+
+	approvalID, approved, ok := parseApprovalCallback(data)
+	if !ok {
+		logger.Warn("unknown callback format", "data", data)
+		return
+	}
+
+	w.approvalCallback(approvalID, approved, webOwnerID)
+
+	resultText := callbackResult(approvalID, approved)
+	w.broadcast(client.chatID, webEvent{Type: "resolved", MessageID: event.MessageID, Text: resultText})
+}
+
+// broadcast writes an event to every open connection for the chat
+func (w *web) broadcast(chatID int64, event webEvent) error {
+	w.mu.Lock()
+	var targets []*webClient
+	for c := range w.clients[chatID] {
+		targets = append(targets, c)
+	}
+	w.mu.Unlock()
+
+	if len(targets) == 0 {
+		return fmt.Errorf("no web chat connection for chat %d", chatID)
+	}
+
+	var lastErr error
+	delivered := 0
+	for _, c := range targets {
+		c.mu.Lock()
+		c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		err := c.conn.WriteJSON(event)
+		c.mu.Unlock()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		delivered++
+	}
+
+	if delivered == 0 {
+		return lastErr
+	}
+	return nil
+}
+
+func (w *web) Send(chatID int64, message string) error {
+	err := w.broadcast(chatID, webEvent{Type: "message", Text: message})
+	if err != nil {
+		logger.Error("web chat send failed", "error", err, "chatID", chatID)
+	} else {
+		logger.Info("web chat message sent", "chatID", chatID, "chars", len(message))
+	}
+	return err
+}
+
+func (w *web) SendTyping(chatID int64) error {
+	return w.broadcast(chatID, webEvent{Type: "typing"})
+}
+
+func (w *web) sendMedia(chatID int64, kind string, data []byte, filename, caption string) error {
+	err := w.broadcast(chatID, webEvent{
+		Type:     kind,
+		Text:     caption,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		Filename: filename,
+		MimeType: http.DetectContentType(data),
+	})
+	if err != nil {
+		logger.Error("web chat send "+kind+" failed", "error", err, "chatID", chatID)
+	} else {
+		logger.Info("web chat "+kind+" sent", "chatID", chatID, "caption", truncate(caption, 50))
+	}
+	return err
+}
+
+func (w *web) SendPhoto(chatID int64, data []byte, caption string) error {
+	return w.sendMedia(chatID, "image", data, mediaFilename("image", data, ""), caption)
+}
+
+func (w *web) SendVideo(chatID int64, data []byte, caption string) error {
+	return w.sendMedia(chatID, "video", data, mediaFilename("video", data, ".mp4"), caption)
+}
+
+func (w *web) SendDocument(chatID int64, data []byte, filename, caption string) error {
+	return w.sendMedia(chatID, "document", data, filename, caption)
+}
+
+// Capabilities has no size limits: files go to the browser over the websocket
+func (w *web) Capabilities() Capabilities {
+	return Capabilities{WebP: true}
+}
+
+func (w *web) SendWithButtons(chatID int64, message string, buttons []Button) (int64, error) {
+	messageID := atomic.AddInt64(&w.nextMessageID, 1)
+
+	event := webEvent{Type: "buttons", Text: message, MessageID: messageID}
+	for _, b := range buttons {
+		event.Buttons = append(event.Buttons, webButton{Label: b.Label, ID: b.CallbackID})
+	}
+
+	if err := w.broadcast(chatID, event); err != nil {
+		logger.Error("web chat send with buttons failed", "error", err, "chatID", chatID)
+		return 0, err
+	}
+
+	logger.Info("web chat message with buttons sent", "chatID", chatID, "messageID", messageID)
+	return messageID, nil
+}
+
+// EditWithButtons re-renders a button message in place; nil buttons removes them
+func (w *web) EditWithButtons(chatID, messageID int64, message string, buttons []Button) error {
+	event := webEvent{Type: "buttons", Text: message, MessageID: messageID}
+	for _, b := range buttons {
+		event.Buttons = append(event.Buttons, webButton{Label: b.Label, ID: b.CallbackID})
+	}
+	return w.broadcast(chatID, event)
+}
+
+func (w *web) SetApprovalCallback(fn ApprovalCallback) {
+	w.approvalCallback = fn
+}
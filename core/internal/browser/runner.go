@@ -3,11 +3,13 @@ package browser
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/bowerhall/sheldon/internal/isolation"
 	"github.com/bowerhall/sheldon/internal/logger"
 )
 
@@ -15,12 +17,14 @@ import (
 type Runner struct {
 	image   string
 	timeout time.Duration
+	harden  isolation.Options
 }
 
 // Config holds configuration for the browser runner
 type Config struct {
 	Image   string        // container image (default: sheldon-browser-sandbox:latest)
 	Timeout time.Duration // command timeout (default: 60s)
+	Harden  isolation.Options
 }
 
 // NewRunner creates a new browser runner
@@ -35,6 +39,7 @@ func NewRunner(cfg Config) *Runner {
 	return &Runner{
 		image:   cfg.Image,
 		timeout: cfg.Timeout,
+		harden:  cfg.Harden,
 	}
 }
 
@@ -54,6 +59,7 @@ var allowedCommands = map[string]bool{
 	"find":       true,
 	"is":         true,
 	"close":      true,
+	"download":   true,
 }
 
 // Run executes a sequence of agent-browser commands in a container
@@ -76,6 +82,13 @@ func (r *Runner) Run(ctx context.Context, commands []string) (string, error) {
 		script.WriteString(fmt.Sprintf("agent-browser %s\n", cmd))
 	}
 
+	logger.Debug("browser runner executing", "commands", len(commands))
+
+	return r.exec(ctx, script.String())
+}
+
+// exec runs a shell script in a fresh sandbox container and returns its stdout
+func (r *Runner) exec(ctx context.Context, script string) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
@@ -83,11 +96,12 @@ func (r *Runner) Run(ctx context.Context, commands []string) (string, error) {
 		"run", "--rm",
 		"--network=host", // needed for browser to access the internet
 		"--shm-size=2g",  // needed for Chrome
-		r.image,
-		"-c", script.String(), // ENTRYPOINT is /bin/sh, so just pass -c and script
 	}
-
-	logger.Debug("browser runner executing", "commands", len(commands))
+	args = append(args, r.harden.Args()...)
+	args = append(args,
+		r.image,
+		"-c", script, // ENTRYPOINT is /bin/sh, so just pass -c and script
+	)
 
 	cmd := exec.CommandContext(ctx, "docker", args...)
 
@@ -150,10 +164,105 @@ func (r *Runner) GetText(ctx context.Context, ref string) (string, error) {
 	return r.Run(ctx, commands)
 }
 
-// Screenshot captures a screenshot of the page
-func (r *Runner) Screenshot(ctx context.Context, url string) ([]byte, error) {
-	// for now, just return an error - would need to mount volume for file
-	return nil, fmt.Errorf("screenshot not yet implemented")
+// MaxDownloadSize caps files captured by Download
+const MaxDownloadSize = 50 * 1024 * 1024
+
+// Download opens a page, clicks the element ref that triggers a download and returns
+// the file. The sandbox is fresh each time, so refs come from a new snapshot of the page.
+func (r *Runner) Download(ctx context.Context, url, ref string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid URL: must start with http:// or https://")
+	}
+
+	commands := []string{
+		fmt.Sprintf("open %q", url),
+		"snapshot",
+		fmt.Sprintf("download %s /tmp/download", ref),
+	}
+	for _, cmd := range commands {
+		if err := r.validateCommand(cmd); err != nil {
+			return nil, err
+		}
+	}
+
+	// one byte over the cap is enough to tell the file was too big
+	script := fmt.Sprintf(`set -e
+agent-browser %s >/dev/null
+agent-browser %s >/dev/null
+agent-browser %s >/dev/null
+head -c %d /tmp/download | base64
+`, commands[0], commands[1], commands[2], MaxDownloadSize+1)
+
+	logger.Debug("browser runner downloading", "url", url, "ref", ref)
+
+	out, err := r.exec(ctx, script)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(out), ""))
+	if err != nil {
+		return nil, fmt.Errorf("decode download: %w", err)
+	}
+	if len(data) > MaxDownloadSize {
+		return nil, fmt.Errorf("download is larger than %d MB", MaxDownloadSize/1024/1024)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("download was empty")
+	}
+	return data, nil
+}
+
+// Screenshot opens a URL and returns a PNG of it, the whole scrollable page when fullPage is set
+func (r *Runner) Screenshot(ctx context.Context, url string, fullPage bool) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid URL: must start with http:// or https://")
+	}
+	// the url ends up in a shell script, same checks as Run
+	if err := r.validateCommand(fmt.Sprintf("open %q", url)); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("browser runner taking screenshot", "url", url, "fullPage", fullPage)
+
+	return r.capture(ctx, url, fullPage)
+}
+
+// RenderHTML loads a self-contained HTML page and returns a PNG screenshot of it.
+// The page is passed as a data URL, so nothing is mounted into the container.
+func (r *Runner) RenderHTML(ctx context.Context, html string) ([]byte, error) {
+	page := "data:text/html;base64," + base64.StdEncoding.EncodeToString([]byte(html))
+
+	logger.Debug("browser runner rendering html", "bytes", len(html))
+
+	return r.capture(ctx, page, false)
+}
+
+// capture screenshots a page inside the container and reads the PNG back as base64 on stdout
+func (r *Runner) capture(ctx context.Context, page string, fullPage bool) ([]byte, error) {
+	flags := ""
+	if fullPage {
+		flags = "--full "
+	}
+
+	// the wait gives client-side rendering a moment after load
+	script := fmt.Sprintf(`set -e
+agent-browser open %q >/dev/null
+agent-browser wait 2000 >/dev/null
+agent-browser screenshot %s/tmp/capture.png >/dev/null
+base64 /tmp/capture.png
+`, page, flags)
+
+	out, err := r.exec(ctx, script)
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(out), ""))
+	if err != nil {
+		return nil, fmt.Errorf("decode screenshot: %w", err)
+	}
+	return png, nil
 }
 
 // validateCommand checks if a command is in the allowlist
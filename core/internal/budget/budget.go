@@ -89,10 +89,15 @@ func (t *Tracker) Add(tokens int) bool {
 }
 
 func (t *Tracker) Record(provider, model string, inputTokens, outputTokens int) bool {
-	totalTokens := inputTokens + outputTokens
+	return t.RecordTokens(provider, model, Tokens{Input: inputTokens, Output: outputTokens})
+}
+
+// RecordTokens persists a request's usage and cost and counts it against the daily limit
+func (t *Tracker) RecordTokens(provider, model string, tokens Tokens) bool {
+	totalTokens := tokens.Input + tokens.Output + tokens.CacheWrite + tokens.CacheRead
 
 	if t.store != nil {
-		if err := t.store.Record(provider, model, inputTokens, outputTokens); err != nil {
+		if err := t.store.RecordTokens(provider, model, tokens); err != nil {
 			// log but don't fail - usage tracking shouldn't block responses
 			println("budget: failed to record usage:", err.Error())
 		}
@@ -101,6 +106,20 @@ func (t *Tracker) Record(provider, model string, inputTokens, outputTokens int)
 	return t.Add(totalTokens)
 }
 
+// SetLimits changes the daily limit and warning threshold, e.g. after the
+// config was edited. A warning already sent today isn't repeated unless usage
+// dropped below the new threshold.
+func (t *Tracker) SetLimits(dailyLimit int, warnAt float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.dailyLimit = dailyLimit
+	t.warnAt = warnAt
+	if float64(t.tokens) < float64(dailyLimit)*warnAt {
+		t.warnSent = false
+	}
+}
+
 func (t *Tracker) Usage() (used, limit int) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -215,3 +215,108 @@ func TestPricingUnknownModel(t *testing.T) {
 		t.Error("expected unknown models to have non-zero cost")
 	}
 }
+
+func TestPricingCacheTokens(t *testing.T) {
+	// sonnet input is $3/M: writes cost 1.25x, reads 0.1x
+	cost := Cost("claude", "claude-sonnet-4-20250514", Tokens{CacheWrite: 1000000, CacheRead: 1000000})
+	want := 3.75 + 0.30
+	if cost < want-0.0001 || cost > want+0.0001 {
+		t.Errorf("expected cache cost %f, got %f", want, cost)
+	}
+}
+
+func TestPricingLookup(t *testing.T) {
+	tests := []struct {
+		provider string
+		model    string
+		want     float64
+	}{
+		{"openai", "gpt-4o-2024-08-06", 2.50},
+		{"openai", "gpt-4o-mini-2024-07-18", 0.15},
+		{"openrouter", "anthropic/claude-sonnet-4", 3.00},
+		{"ollama", "llama3.2", 0},
+	}
+
+	for _, tt := range tests {
+		cost := Cost(tt.provider, tt.model, Tokens{Input: 1000000})
+		if cost != tt.want {
+			t.Errorf("Cost(%s, %s) = %f, want %f", tt.provider, tt.model, cost, tt.want)
+		}
+		if !HasPricing(tt.provider, tt.model) {
+			t.Errorf("expected %s/%s to have known pricing", tt.provider, tt.model)
+		}
+	}
+
+	if HasPricing("openai", "unknown-model") {
+		t.Error("expected unknown model to use the fallback estimate")
+	}
+}
+
+func TestStoreBreakdownByModel(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewStore(db, time.UTC)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	store.RecordTokens("claude", "claude-sonnet-4-20250514", Tokens{Input: 1000000, CacheRead: 500})
+	store.RecordTokens("claude", "claude-sonnet-4-20250514", Tokens{Output: 1000000})
+	store.RecordTokens("openai", "gpt-4o", Tokens{Input: 1000000})
+
+	now := time.Now()
+	from, to := now.Add(-time.Hour), now.Add(time.Hour)
+
+	breakdown, err := store.BreakdownByModel(from, to)
+	if err != nil {
+		t.Fatalf("failed to get breakdown: %v", err)
+	}
+	if len(breakdown) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(breakdown))
+	}
+	if breakdown[0].Provider != "claude" || breakdown[0].Requests != 2 {
+		t.Errorf("expected claude first with 2 requests, got %+v", breakdown[0])
+	}
+
+	summary, err := store.SummaryRange(from, to)
+	if err != nil {
+		t.Fatalf("failed to get summary: %v", err)
+	}
+	if summary.TotalCacheTokens != 500 {
+		t.Errorf("expected 500 cache tokens, got %d", summary.TotalCacheTokens)
+	}
+}
+
+func TestStoreMigratesCacheColumns(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE usage (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp DATETIME NOT NULL,
+		provider TEXT NOT NULL,
+		model TEXT NOT NULL,
+		input_tokens INTEGER NOT NULL,
+		output_tokens INTEGER NOT NULL,
+		cost_usd REAL NOT NULL
+	)`)
+	if err != nil {
+		t.Fatalf("failed to create old schema: %v", err)
+	}
+
+	store, err := NewStore(db, time.UTC)
+	if err != nil {
+		t.Fatalf("failed to migrate store: %v", err)
+	}
+
+	if err := store.RecordTokens("claude", "claude-sonnet-4-20250514", Tokens{Input: 10, CacheRead: 20}); err != nil {
+		t.Errorf("failed to record after migration: %v", err)
+	}
+}
@@ -7,48 +7,130 @@ type ModelPricing struct {
 	OutputPerMillion float64
 }
 
+// Tokens is the token usage of a single request
+type Tokens struct {
+	Input      int // uncached input
+	Output     int
+	CacheWrite int // input written to the prompt cache
+	CacheRead  int // input served from the prompt cache
+}
+
+// prompt cache pricing relative to the input rate (Anthropic)
+const (
+	cacheWriteMultiplier = 1.25
+	cacheReadMultiplier  = 0.10
+)
+
+// unknownPricing is a conservative estimate for models missing from the table
+var unknownPricing = ModelPricing{5.00, 15.00}
+
+// pricing is matched exactly first, then by longest prefix so dated
+// snapshots (gpt-4o-2024-08-06, claude-sonnet-4-5-20250929) resolve
 var pricing = map[string]ModelPricing{
 	// Claude models (per million tokens)
-	"claude-opus-4-5-20251101":   {15.00, 75.00},
-	"claude-sonnet-4-20250514":   {3.00, 15.00},
-	"claude-haiku-3-5-20241022":  {0.80, 4.00},
+	"claude-opus-4-5-20251101":  {15.00, 75.00},
+	"claude-sonnet-4-20250514":  {3.00, 15.00},
+	"claude-haiku-3-5-20241022": {0.80, 4.00},
+	"claude-opus-4":             {15.00, 75.00},
+	"claude-sonnet-4":           {3.00, 15.00},
+	"claude-3-5-haiku":          {0.80, 4.00},
+	"claude-haiku-4-5":          {1.00, 5.00},
 
 	// OpenAI models
-	"gpt-4o":          {2.50, 10.00},
-	"gpt-4o-mini":     {0.15, 0.60},
-	"gpt-4-turbo":     {10.00, 30.00},
-	"o1":              {15.00, 60.00},
-	"o1-mini":         {3.00, 12.00},
+	"gpt-4o":       {2.50, 10.00},
+	"gpt-4o-mini":  {0.15, 0.60},
+	"gpt-4-turbo":  {10.00, 30.00},
+	"gpt-4.1":      {2.00, 8.00},
+	"gpt-4.1-mini": {0.40, 1.60},
+	"gpt-4.1-nano": {0.10, 0.40},
+	"o1":           {15.00, 60.00},
+	"o1-mini":      {3.00, 12.00},
+	"o3":           {2.00, 8.00},
+	"o3-mini":      {1.10, 4.40},
+	"o4-mini":      {1.10, 4.40},
 
 	// Kimi models (estimated, adjust as needed)
 	"kimi-k2-0711-preview": {1.00, 4.00},
 	"kimi-k2.5:cloud":      {1.50, 6.00},
 
+	// Other OpenAI-compatible providers
+	"deepseek-chat":     {0.27, 1.10},
+	"deepseek-reasoner": {0.55, 2.19},
+	"mistral-large":     {2.00, 6.00},
+	"mistral-small":     {0.20, 0.60},
+
 	// Ollama/local models (free)
 	"ollama": {0, 0},
 }
 
+// freeProviders run models locally and never cost money
+var freeProviders = map[string]bool{
+	"ollama": true,
+}
+
 func CalculateCost(model string, inputTokens, outputTokens int) float64 {
-	p, ok := pricing[model]
-	if !ok {
-		// Try prefix matching for ollama models
-		if strings.HasPrefix(model, "ollama/") || strings.Contains(model, ":") {
-			return 0
-		}
-		// Unknown model, use conservative estimate
-		p = ModelPricing{5.00, 15.00}
-	}
+	return Cost("", model, Tokens{Input: inputTokens, Output: outputTokens})
+}
+
+// Cost returns the USD cost of a request, including prompt cache reads and writes
+func Cost(provider, model string, t Tokens) float64 {
+	p, _ := lookupPricing(provider, model)
 
-	inputCost := float64(inputTokens) * p.InputPerMillion / 1_000_000
-	outputCost := float64(outputTokens) * p.OutputPerMillion / 1_000_000
+	inputCost := float64(t.Input) * p.InputPerMillion / 1_000_000
+	outputCost := float64(t.Output) * p.OutputPerMillion / 1_000_000
+	cacheCost := float64(t.CacheWrite)*p.InputPerMillion*cacheWriteMultiplier/1_000_000 +
+		float64(t.CacheRead)*p.InputPerMillion*cacheReadMultiplier/1_000_000
 
-	return inputCost + outputCost
+	return inputCost + outputCost + cacheCost
 }
 
 func GetPricing(model string) (input, output float64, found bool) {
-	p, ok := pricing[model]
+	p, ok := lookupPricing("", model)
 	if !ok {
 		return 0, 0, false
 	}
 	return p.InputPerMillion, p.OutputPerMillion, true
 }
+
+// HasPricing reports whether a model has known pricing rather than the fallback estimate
+func HasPricing(provider, model string) bool {
+	_, found := lookupPricing(provider, model)
+	return found
+}
+
+// lookupPricing resolves a model's pricing. found is false when the
+// conservative fallback estimate was used.
+func lookupPricing(provider, model string) (ModelPricing, bool) {
+	if freeProviders[provider] {
+		return ModelPricing{}, true
+	}
+
+	if p, ok := pricing[model]; ok {
+		return p, true
+	}
+
+	// OpenRouter IDs carry a vendor prefix (anthropic/claude-sonnet-4)
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		if strings.HasPrefix(model, "ollama/") {
+			return ModelPricing{}, true
+		}
+		return lookupPricing("", model[i+1:])
+	}
+
+	// ollama tags look like name:size
+	if strings.Contains(model, ":") {
+		return ModelPricing{}, true
+	}
+
+	best := ""
+	for name := range pricing {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best != "" {
+		return pricing[best], true
+	}
+
+	return unknownPricing, false
+}
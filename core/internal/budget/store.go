@@ -13,6 +13,8 @@ CREATE TABLE IF NOT EXISTS usage (
 	model TEXT NOT NULL,
 	input_tokens INTEGER NOT NULL,
 	output_tokens INTEGER NOT NULL,
+	cache_write_tokens INTEGER NOT NULL DEFAULT 0,
+	cache_read_tokens INTEGER NOT NULL DEFAULT 0,
 	cost_usd REAL NOT NULL
 );
 
@@ -32,6 +34,13 @@ func NewStore(db *sql.DB, timezone *time.Location) (*Store, error) {
 		return nil, err
 	}
 
+	// databases created before prompt caching lack the cache columns
+	for _, column := range []string{"cache_write_tokens", "cache_read_tokens"} {
+		if err := addColumn(db, column); err != nil {
+			return nil, err
+		}
+	}
+
 	tz := timezone
 	if tz == nil {
 		tz = time.UTC
@@ -40,6 +49,18 @@ func NewStore(db *sql.DB, timezone *time.Location) (*Store, error) {
 	return &Store{db: db, timezone: tz}, nil
 }
 
+func addColumn(db *sql.DB, column string) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('usage') WHERE name = ?`, column).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := db.Exec(`ALTER TABLE usage ADD COLUMN ` + column + ` INTEGER NOT NULL DEFAULT 0`)
+	return err
+}
+
 type UsageRecord struct {
 	Timestamp    time.Time
 	Provider     string
@@ -50,26 +71,32 @@ type UsageRecord struct {
 }
 
 func (s *Store) Record(provider, model string, inputTokens, outputTokens int) error {
-	cost := CalculateCost(model, inputTokens, outputTokens)
+	return s.RecordTokens(provider, model, Tokens{Input: inputTokens, Output: outputTokens})
+}
 
+// RecordTokens stores one request with its cost in USD
+func (s *Store) RecordTokens(provider, model string, t Tokens) error {
 	_, err := s.db.Exec(
-		`INSERT INTO usage (timestamp, provider, model, input_tokens, output_tokens, cost_usd) VALUES (?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO usage (timestamp, provider, model, input_tokens, output_tokens, cache_write_tokens, cache_read_tokens, cost_usd) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 		time.Now().In(s.timezone),
 		provider,
 		model,
-		inputTokens,
-		outputTokens,
-		cost,
+		t.Input,
+		t.Output,
+		t.CacheWrite,
+		t.CacheRead,
+		Cost(provider, model, t),
 	)
 
 	return err
 }
 
 type Summary struct {
-	TotalRequests    int
-	TotalInputTokens int
+	TotalRequests     int
+	TotalInputTokens  int
 	TotalOutputTokens int
-	TotalCostUSD     float64
+	TotalCacheTokens  int // cache writes and reads, billed at different rates than input
+	TotalCostUSD      float64
 }
 
 func (s *Store) SummaryRange(from, to time.Time) (*Summary, error) {
@@ -78,13 +105,14 @@ func (s *Store) SummaryRange(from, to time.Time) (*Summary, error) {
 			COUNT(*),
 			COALESCE(SUM(input_tokens), 0),
 			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(cache_write_tokens + cache_read_tokens), 0),
 			COALESCE(SUM(cost_usd), 0)
 		FROM usage
 		WHERE timestamp >= ? AND timestamp < ?
 	`, from, to)
 
 	var sum Summary
-	if err := row.Scan(&sum.TotalRequests, &sum.TotalInputTokens, &sum.TotalOutputTokens, &sum.TotalCostUSD); err != nil {
+	if err := row.Scan(&sum.TotalRequests, &sum.TotalInputTokens, &sum.TotalOutputTokens, &sum.TotalCacheTokens, &sum.TotalCostUSD); err != nil {
 		return nil, err
 	}
 
@@ -128,6 +156,7 @@ func (s *Store) TodayTokens() (int, error) {
 }
 
 type ModelBreakdown struct {
+	Provider     string
 	Model        string
 	Requests     int
 	InputTokens  int
@@ -138,6 +167,7 @@ type ModelBreakdown struct {
 func (s *Store) BreakdownByModel(from, to time.Time) ([]ModelBreakdown, error) {
 	rows, err := s.db.Query(`
 		SELECT
+			provider,
 			model,
 			COUNT(*),
 			SUM(input_tokens),
@@ -145,7 +175,7 @@ func (s *Store) BreakdownByModel(from, to time.Time) ([]ModelBreakdown, error) {
 			SUM(cost_usd)
 		FROM usage
 		WHERE timestamp >= ? AND timestamp < ?
-		GROUP BY model
+		GROUP BY provider, model
 		ORDER BY SUM(cost_usd) DESC
 	`, from, to)
 	if err != nil {
@@ -156,7 +186,7 @@ func (s *Store) BreakdownByModel(from, to time.Time) ([]ModelBreakdown, error) {
 	var result []ModelBreakdown
 	for rows.Next() {
 		var b ModelBreakdown
-		if err := rows.Scan(&b.Model, &b.Requests, &b.InputTokens, &b.OutputTokens, &b.CostUSD); err != nil {
+		if err := rows.Scan(&b.Provider, &b.Model, &b.Requests, &b.InputTokens, &b.OutputTokens, &b.CostUSD); err != nil {
 			return nil, err
 		}
 		result = append(result, b)
@@ -0,0 +1,202 @@
+package calendar
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalDAV talks to a single calendar collection, e.g. on Nextcloud, Radicale or iCloud
+type CalDAV struct {
+	url      *url.URL
+	username string
+	password string
+	timezone *time.Location
+	client   *http.Client
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				ETag         string `xml:"getetag"`
+				CalendarData string `xml:"calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// calendarQuery asks for events overlapping a time range, with recurrences expanded
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <d:getetag/>
+    <c:calendar-data><c:expand start="%[1]s" end="%[2]s"/></c:calendar-data>
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%[1]s" end="%[2]s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+func NewCalDAV(rawURL, username, password string, timezone *time.Location) *CalDAV {
+	u, err := url.Parse(strings.TrimSuffix(rawURL, "/") + "/")
+	if err != nil {
+		u = &url.URL{Path: "/"}
+	}
+
+	return &CalDAV{
+		url:      u,
+		username: username,
+		password: password,
+		timezone: timezone,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (c *CalDAV) Events(ctx context.Context, from, to time.Time) ([]Event, error) {
+	body := fmt.Sprintf(calendarQuery, from.UTC().Format(icalUTC), to.UTC().Format(icalUTC))
+
+	resp, err := c.do(ctx, "REPORT", c.url.String(), strings.NewReader(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError("list events", resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("decode calendar response: %w", err)
+	}
+
+	var events []Event
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.CalendarData == "" {
+				continue
+			}
+			parsed, err := parseEvents(ps.Prop.CalendarData, c.timezone)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", r.Href, err)
+			}
+			for _, e := range parsed {
+				e.ID = r.Href
+				events = append(events, e)
+			}
+		}
+	}
+
+	return events, nil
+}
+
+func (c *CalDAV) Create(ctx context.Context, event Event) (*Event, error) {
+	uid := uuid.NewString()
+	target := c.url.ResolveReference(&url.URL{Path: uid + ".ics"})
+
+	resp, err := c.do(ctx, http.MethodPut, target.String(), strings.NewReader(formatCalendar(uid, event)), map[string]string{
+		"Content-Type":  "text/calendar; charset=utf-8",
+		"If-None-Match": "*",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return nil, statusError("create event", resp)
+	}
+
+	event.ID = target.Path
+	return &event, nil
+}
+
+func (c *CalDAV) Update(ctx context.Context, id string, update EventUpdate) (*Event, error) {
+	target := c.url.ResolveReference(&url.URL{Path: id})
+
+	resp, err := c.do(ctx, http.MethodGet, target.String(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("fetch event", resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := parseEvents(string(data), c.timezone)
+	if err != nil || len(events) == 0 {
+		return nil, fmt.Errorf("event %s has no readable VEVENT", id)
+	}
+
+	updated, err := update.apply(events[0])
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	// only overwrite the version we read, so concurrent edits aren't lost
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		headers["If-Match"] = etag
+	}
+
+	put, err := c.do(ctx, http.MethodPut, target.String(), strings.NewReader(rewriteEvent(string(data), updated)), headers)
+	if err != nil {
+		return nil, err
+	}
+	defer put.Body.Close()
+
+	if put.StatusCode != http.StatusNoContent && put.StatusCode != http.StatusOK && put.StatusCode != http.StatusCreated {
+		return nil, statusError("update event", put)
+	}
+
+	updated.ID = target.Path
+	return &updated, nil
+}
+
+func (c *CalDAV) do(ctx context.Context, method, target string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calendar unreachable: %w", err)
+	}
+	return resp, nil
+}
+
+func statusError(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s failed (%d): %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
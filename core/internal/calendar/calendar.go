@@ -0,0 +1,60 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// New creates the configured calendar provider, or nil if none is configured
+func New(cfg Config) (Provider, error) {
+	if cfg.Timezone == nil {
+		cfg.Timezone = time.UTC
+	}
+
+	switch cfg.Provider {
+	case "caldav":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("CALDAV_URL is required for the caldav provider")
+		}
+		return NewCalDAV(cfg.URL, cfg.Username, cfg.Password, cfg.Timezone), nil
+	case "google":
+		if cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.RefreshToken == "" {
+			return nil, fmt.Errorf("GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET and GOOGLE_REFRESH_TOKEN are required for the google provider")
+		}
+		return NewGoogle(cfg.CalendarID, cfg.ClientID, cfg.ClientSecret, cfg.RefreshToken, cfg.Timezone), nil
+	case "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown calendar provider: %s", cfg.Provider)
+	}
+}
+
+// apply returns the event with the update's fields changed
+func (u EventUpdate) apply(e Event) (Event, error) {
+	duration := e.End.Sub(e.Start)
+
+	if u.Title != nil {
+		e.Title = *u.Title
+	}
+	if u.Location != nil {
+		e.Location = *u.Location
+	}
+	if u.Description != nil {
+		e.Description = *u.Description
+	}
+	if u.Start != nil {
+		e.Start = *u.Start
+		// moving an event keeps its length unless a new end is given
+		if u.End == nil {
+			e.End = e.Start.Add(duration)
+		}
+	}
+	if u.End != nil {
+		e.End = *u.End
+	}
+
+	if !e.End.After(e.Start) {
+		return e, fmt.Errorf("event must end after it starts")
+	}
+	return e, nil
+}
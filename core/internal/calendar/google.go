@@ -0,0 +1,280 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	googleAPIURL   = "https://www.googleapis.com/calendar/v3"
+)
+
+// Google uses the Calendar v3 REST API with an OAuth refresh token
+type Google struct {
+	calendarID   string
+	clientID     string
+	clientSecret string
+	refreshToken string
+	timezone     *time.Location
+	client       *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+type googleEvent struct {
+	ID          string      `json:"id,omitempty"`
+	Summary     string      `json:"summary,omitempty"`
+	Location    string      `json:"location,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Start       *googleTime `json:"start,omitempty"`
+	End         *googleTime `json:"end,omitempty"`
+}
+
+type googleTime struct {
+	DateTime string `json:"dateTime,omitempty"`
+	Date     string `json:"date,omitempty"`
+}
+
+func NewGoogle(calendarID, clientID, clientSecret, refreshToken string, timezone *time.Location) *Google {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+
+	return &Google{
+		calendarID:   calendarID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+		timezone:     timezone,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (g *Google) Events(ctx context.Context, from, to time.Time) ([]Event, error) {
+	query := url.Values{
+		"timeMin":      {from.Format(time.RFC3339)},
+		"timeMax":      {to.Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+		"maxResults":   {"250"},
+	}
+
+	var result struct {
+		Items []googleEvent `json:"items"`
+	}
+	if err := g.call(ctx, http.MethodGet, g.eventsURL("")+"?"+query.Encode(), nil, &result); err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+
+	events := make([]Event, 0, len(result.Items))
+	for _, item := range result.Items {
+		e, err := g.fromGoogle(item)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (g *Google) Create(ctx context.Context, event Event) (*Event, error) {
+	body := googleEvent{
+		Summary:     event.Title,
+		Location:    event.Location,
+		Description: event.Description,
+		Start:       g.toGoogleTime(event.Start, event.AllDay),
+		End:         g.toGoogleTime(event.End, event.AllDay),
+	}
+
+	var created googleEvent
+	if err := g.call(ctx, http.MethodPost, g.eventsURL(""), body, &created); err != nil {
+		return nil, fmt.Errorf("create event: %w", err)
+	}
+
+	event.ID = created.ID
+	return &event, nil
+}
+
+func (g *Google) Update(ctx context.Context, id string, update EventUpdate) (*Event, error) {
+	var existing googleEvent
+	if err := g.call(ctx, http.MethodGet, g.eventsURL(id), nil, &existing); err != nil {
+		return nil, fmt.Errorf("fetch event: %w", err)
+	}
+
+	current, err := g.fromGoogle(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := update.apply(current)
+	if err != nil {
+		return nil, err
+	}
+
+	// PATCH only the fields that changed; empty strings clear text fields
+	patch := map[string]any{}
+	if update.Title != nil {
+		patch["summary"] = updated.Title
+	}
+	if update.Location != nil {
+		patch["location"] = updated.Location
+	}
+	if update.Description != nil {
+		patch["description"] = updated.Description
+	}
+	if update.Start != nil || update.End != nil {
+		patch["start"] = g.toGoogleTime(updated.Start, updated.AllDay)
+		patch["end"] = g.toGoogleTime(updated.End, updated.AllDay)
+	}
+
+	if err := g.call(ctx, http.MethodPatch, g.eventsURL(id), patch, nil); err != nil {
+		return nil, fmt.Errorf("update event: %w", err)
+	}
+
+	return &updated, nil
+}
+
+func (g *Google) eventsURL(id string) string {
+	u := googleAPIURL + "/calendars/" + url.PathEscape(g.calendarID) + "/events"
+	if id != "" {
+		u += "/" + url.PathEscape(id)
+	}
+	return u
+}
+
+func (g *Google) fromGoogle(item googleEvent) (Event, error) {
+	e := Event{
+		ID:          item.ID,
+		Title:       item.Summary,
+		Location:    item.Location,
+		Description: item.Description,
+	}
+	if item.Start == nil || item.End == nil {
+		return e, fmt.Errorf("event %s has no start or end", item.ID)
+	}
+
+	var err error
+	if item.Start.Date != "" {
+		e.AllDay = true
+		if e.Start, err = time.ParseInLocation(time.DateOnly, item.Start.Date, g.timezone); err != nil {
+			return e, err
+		}
+		if e.End, err = time.ParseInLocation(time.DateOnly, item.End.Date, g.timezone); err != nil {
+			return e, err
+		}
+		return e, nil
+	}
+
+	if e.Start, err = time.Parse(time.RFC3339, item.Start.DateTime); err != nil {
+		return e, err
+	}
+	if e.End, err = time.Parse(time.RFC3339, item.End.DateTime); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+func (g *Google) toGoogleTime(t time.Time, allDay bool) *googleTime {
+	if allDay {
+		return &googleTime{Date: t.Format(time.DateOnly)}
+	}
+	return &googleTime{DateTime: t.Format(time.RFC3339)}
+}
+
+func (g *Google) call(ctx context.Context, method, target string, body, out any) error {
+	token, err := g.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("google calendar unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return statusError(method, resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// token returns a cached access token, refreshing it shortly before it expires
+func (g *Google) token(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.accessToken != "" && time.Now().Before(g.expiresAt.Add(-time.Minute)) {
+		return g.accessToken, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {g.clientID},
+		"client_secret": {g.clientSecret},
+		"refresh_token": {g.refreshToken},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("google oauth unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError("refresh google token", resp)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode google token: %w", err)
+	}
+
+	g.accessToken = result.AccessToken
+	g.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	return g.accessToken, nil
+}
@@ -0,0 +1,276 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalUTC  = "20060102T150405Z"
+	icalDate = "20060102"
+	icalTime = "20060102T150405"
+)
+
+var icalDuration = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// icalLine is one unfolded content line, e.g. DTSTART;TZID=Europe/London:20250101T090000
+type icalLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfold joins continuation lines and drops blank ones
+func unfold(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func parseLine(raw string) icalLine {
+	head, value, _ := strings.Cut(raw, ":")
+	parts := strings.Split(head, ";")
+
+	line := icalLine{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: value}
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			line.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return line
+}
+
+// parseEvents reads every VEVENT in an iCalendar object
+func parseEvents(data string, loc *time.Location) ([]Event, error) {
+	var events []Event
+	var current *Event
+	var duration time.Duration
+	depth := 0
+
+	for _, raw := range unfold(data) {
+		line := parseLine(raw)
+
+		switch {
+		case line.name == "BEGIN" && line.value == "VEVENT":
+			current = &Event{}
+			duration = 0
+			depth = 0
+			continue
+		case current == nil:
+			continue
+		case line.name == "BEGIN":
+			// nested components such as VALARM have their own properties
+			depth++
+			continue
+		case line.name == "END" && depth > 0:
+			depth--
+			continue
+		case line.name == "END" && line.value == "VEVENT":
+			if current.End.IsZero() {
+				if duration == 0 && current.AllDay {
+					duration = 24 * time.Hour
+				}
+				current.End = current.Start.Add(duration)
+			}
+			events = append(events, *current)
+			current = nil
+			continue
+		case depth > 0:
+			continue
+		}
+
+		switch line.name {
+		case "UID":
+			if current.ID == "" {
+				current.ID = line.value
+			}
+		case "SUMMARY":
+			current.Title = unescapeText(line.value)
+		case "LOCATION":
+			current.Location = unescapeText(line.value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(line.value)
+		case "DTSTART":
+			t, allDay, err := parseICalTime(line, loc)
+			if err != nil {
+				return nil, fmt.Errorf("DTSTART: %w", err)
+			}
+			current.Start, current.AllDay = t, allDay
+		case "DTEND":
+			t, _, err := parseICalTime(line, loc)
+			if err != nil {
+				return nil, fmt.Errorf("DTEND: %w", err)
+			}
+			current.End = t
+		case "DURATION":
+			d, err := parseICalDuration(line.value)
+			if err != nil {
+				return nil, err
+			}
+			duration = d
+		}
+	}
+
+	return events, nil
+}
+
+func parseICalTime(line icalLine, loc *time.Location) (time.Time, bool, error) {
+	if line.params["VALUE"] == "DATE" || len(line.value) == len(icalDate) {
+		t, err := time.ParseInLocation(icalDate, line.value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(line.value, "Z") {
+		t, err := time.Parse(icalUTC, line.value)
+		return t, false, err
+	}
+
+	if tzid := line.params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+	t, err := time.ParseInLocation(icalTime, line.value, loc)
+	return t, false, err
+}
+
+func parseICalDuration(value string) (time.Duration, error) {
+	m := icalDuration.FindStringSubmatch(strings.TrimPrefix(value, "+"))
+	if m == nil {
+		return 0, fmt.Errorf("unsupported duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+1] != "" {
+			n, _ := strconv.Atoi(m[i+1])
+			d += time.Duration(n) * unit
+		}
+	}
+	return d, nil
+}
+
+// eventProperties renders the properties this package manages for an event
+func eventProperties(e Event) []string {
+	props := []string{"SUMMARY:" + escapeText(e.Title)}
+
+	if e.AllDay {
+		props = append(props,
+			"DTSTART;VALUE=DATE:"+e.Start.Format(icalDate),
+			"DTEND;VALUE=DATE:"+e.End.Format(icalDate),
+		)
+	} else {
+		props = append(props,
+			"DTSTART:"+e.Start.UTC().Format(icalUTC),
+			"DTEND:"+e.End.UTC().Format(icalUTC),
+		)
+	}
+
+	if e.Location != "" {
+		props = append(props, "LOCATION:"+escapeText(e.Location))
+	}
+	if e.Description != "" {
+		props = append(props, "DESCRIPTION:"+escapeText(e.Description))
+	}
+	return props
+}
+
+// formatCalendar renders a new single-event iCalendar object
+func formatCalendar(uid string, e Event) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//bowerhall//sheldon//EN",
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:" + time.Now().UTC().Format(icalUTC),
+	}
+	lines = append(lines, eventProperties(e)...)
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	return joinLines(lines)
+}
+
+// managedProps are replaced on update; everything else (RRULE, VALARM, attendees) is kept
+var managedProps = map[string]bool{
+	"SUMMARY": true, "DTSTART": true, "DTEND": true, "DURATION": true,
+	"LOCATION": true, "DESCRIPTION": true, "DTSTAMP": true,
+}
+
+// rewriteEvent replaces the managed properties of the first VEVENT in data
+func rewriteEvent(data string, e Event) string {
+	var out []string
+	inEvent, done := false, false
+	depth := 0
+
+	for _, raw := range unfold(data) {
+		line := parseLine(raw)
+
+		switch {
+		case done:
+		case line.name == "BEGIN" && line.value == "VEVENT":
+			inEvent = true
+			out = append(out, raw)
+			out = append(out, "DTSTAMP:"+time.Now().UTC().Format(icalUTC))
+			out = append(out, eventProperties(e)...)
+			continue
+		case inEvent && line.name == "BEGIN":
+			depth++
+		case inEvent && line.name == "END" && depth > 0:
+			depth--
+		case inEvent && line.name == "END" && line.value == "VEVENT":
+			inEvent, done = false, true
+		case inEvent && depth == 0 && managedProps[line.name]:
+			continue
+		}
+
+		out = append(out, raw)
+	}
+
+	return joinLines(out)
+}
+
+// joinLines folds lines longer than 75 bytes and joins them with CRLF
+func joinLines(lines []string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		width := 0
+		for _, r := range line {
+			n := utf8.RuneLen(r)
+			if width+n > 75 {
+				b.WriteString("\r\n ")
+				width = 1
+			}
+			b.WriteRune(r)
+			width += n
+		}
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+func unescapeText(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return r.Replace(s)
+}
@@ -0,0 +1,114 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:abc-123\r\n" +
+	"SUMMARY:Lunch with Sarah\\, maybe Tom\r\n" +
+	"DTSTART;TZID=Europe/London:20250314T123000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"LOCATION:Dishoom\\; King's Cross\r\n" +
+	"DESCRIPTION:Book a table\\nfor three\r\n" +
+	"RRULE:FREQ=WEEKLY\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:day-1\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"DTSTART;VALUE=DATE:20250320\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseEvents(t *testing.T) {
+	events, err := parseEvents(sampleCalendar, time.UTC)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	lunch := events[0]
+	if lunch.Title != "Lunch with Sarah, maybe Tom" || lunch.Location != "Dishoom; King's Cross" {
+		t.Errorf("unexpected text fields: %+v", lunch)
+	}
+	if lunch.Description != "Book a table\nfor three" {
+		t.Errorf("expected VALARM description to be ignored, got %q", lunch.Description)
+	}
+	if want := time.Date(2025, 3, 14, 12, 30, 0, 0, time.UTC); !lunch.Start.Equal(want) {
+		t.Errorf("expected start %v, got %v", want, lunch.Start)
+	}
+	if lunch.End.Sub(lunch.Start) != 90*time.Minute {
+		t.Errorf("expected 90 minute duration, got %v", lunch.End.Sub(lunch.Start))
+	}
+
+	holiday := events[1]
+	if !holiday.AllDay || holiday.End.Sub(holiday.Start) != 24*time.Hour {
+		t.Errorf("expected one all-day event, got %+v", holiday)
+	}
+}
+
+func TestRewriteEventKeepsOtherProperties(t *testing.T) {
+	events, _ := parseEvents(sampleCalendar, time.UTC)
+
+	title := "Dinner with Sarah"
+	updated, err := EventUpdate{Title: &title}.apply(events[0])
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	out := rewriteEvent(sampleCalendar, updated)
+	for _, want := range []string{"SUMMARY:Dinner with Sarah", "RRULE:FREQ=WEEKLY", "BEGIN:VALARM", "DESCRIPTION:Reminder", "SUMMARY:Holiday"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in rewritten calendar:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DURATION:") {
+		t.Error("expected DURATION to be replaced by DTEND")
+	}
+
+	reparsed, _ := parseEvents(out, time.UTC)
+	if len(reparsed) != 2 || !reparsed[0].End.Equal(events[0].End) {
+		t.Errorf("expected end to be preserved, got %+v", reparsed)
+	}
+}
+
+func TestApplyMovesEnd(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	e := Event{Start: start, End: start.Add(time.Hour)}
+
+	moved := start.Add(3 * time.Hour)
+	got, err := EventUpdate{Start: &moved}.apply(e)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got.End.Sub(got.Start) != time.Hour {
+		t.Errorf("expected duration kept, got %v", got.End.Sub(got.Start))
+	}
+
+	early := start.Add(-time.Hour)
+	if _, err := (EventUpdate{End: &early}).apply(e); err == nil {
+		t.Error("expected error when end is before start")
+	}
+}
+
+func TestJoinLinesFolds(t *testing.T) {
+	out := joinLines([]string{"DESCRIPTION:" + strings.Repeat("é", 60)})
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 bytes: %d", len(line))
+		}
+	}
+	if got := unfold(out); len(got) != 1 || got[0] != "DESCRIPTION:"+strings.Repeat("é", 60) {
+		t.Errorf("expected fold to round-trip, got %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+package calendar
+
+import (
+	"context"
+	"time"
+)
+
+// Event is a calendar entry. ID is provider specific: the resource path on
+// CalDAV, the event ID on Google.
+type Event struct {
+	ID          string
+	Title       string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Location    string
+	Description string
+}
+
+// EventUpdate holds the fields to change; nil fields are left as they are
+type EventUpdate struct {
+	Title       *string
+	Start       *time.Time
+	End         *time.Time
+	Location    *string
+	Description *string
+}
+
+// Provider reads and writes events on a single calendar
+type Provider interface {
+	Events(ctx context.Context, from, to time.Time) ([]Event, error)
+	Create(ctx context.Context, event Event) (*Event, error)
+	Update(ctx context.Context, id string, update EventUpdate) (*Event, error)
+}
+
+type Config struct {
+	Provider string
+	Timezone *time.Location // used for floating times and all-day events
+
+	URL      string
+	Username string
+	Password string
+
+	CalendarID   string
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+}
@@ -0,0 +1,507 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	width  = 1000
+	height = 600
+
+	maxSeries     = 8
+	maxBars       = 240
+	maxLinePoints = 1000
+	maxPieSlices  = 12
+	maxLabelRunes = 24
+)
+
+// palette is colorblind-friendly; series and pie slices take colors in order
+var palette = []color.RGBA{
+	{0x4e, 0x79, 0xa7, 0xff}, {0xf2, 0x8e, 0x2b, 0xff}, {0x59, 0xa1, 0x4f, 0xff}, {0xe1, 0x57, 0x59, 0xff},
+	{0x76, 0xb7, 0xb2, 0xff}, {0xed, 0xc9, 0x48, 0xff}, {0xb0, 0x7a, 0xa1, 0xff}, {0x9c, 0x75, 0x5f, 0xff},
+	{0xff, 0x9d, 0xa7, 0xff}, {0xba, 0xb0, 0xac, 0xff}, {0x86, 0xbc, 0xb6, 0xff}, {0xd3, 0x72, 0x95, 0xff},
+}
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	textColor  = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	mutedColor = color.RGBA{0x77, 0x77, 0x77, 0xff}
+	gridColor  = color.RGBA{0xe8, 0xe8, 0xe8, 0xff}
+	axisColor  = color.RGBA{0xaa, 0xaa, 0xaa, 0xff}
+)
+
+var (
+	fontOnce sync.Once
+	goFont   *opentype.Font
+	fontErr  error
+)
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+type canvas struct {
+	img   *image.RGBA
+	ras   *vector.Rasterizer
+	text  font.Face
+	title font.Face
+}
+
+// Render draws a chart as a PNG
+func Render(c Chart) ([]byte, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	cv, err := newCanvas()
+	if err != nil {
+		return nil, err
+	}
+	if c.Type == TypePie {
+		cv.pie(c)
+	} else {
+		cv.axes(c)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, cv.img); err != nil {
+		return nil, fmt.Errorf("encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (c Chart) validate() error {
+	switch c.Type {
+	case TypeLine, TypeBar, TypePie:
+	default:
+		return fmt.Errorf("unknown chart type %q, use line, bar or pie", c.Type)
+	}
+	if len(c.Labels) == 0 {
+		return errors.New("labels are required, one per data point")
+	}
+	if len(c.Series) == 0 {
+		return errors.New("at least one series is required")
+	}
+	if len(c.Series) > maxSeries {
+		return fmt.Errorf("too many series (%d), the most that stay readable is %d", len(c.Series), maxSeries)
+	}
+	for _, s := range c.Series {
+		if len(s.Values) != len(c.Labels) {
+			return fmt.Errorf("series %q has %d values for %d labels, they must match", s.Name, len(s.Values), len(c.Labels))
+		}
+		for _, v := range s.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("series %q has a value that isn't a number", s.Name)
+			}
+		}
+	}
+
+	switch c.Type {
+	case TypeLine:
+		if len(c.Labels) > maxLinePoints {
+			return fmt.Errorf("too many points (%d), aggregate to at most %d", len(c.Labels), maxLinePoints)
+		}
+	case TypeBar:
+		if len(c.Labels)*len(c.Series) > maxBars {
+			return fmt.Errorf("too many bars (%d), aggregate or use a line chart", len(c.Labels)*len(c.Series))
+		}
+	case TypePie:
+		if len(c.Labels) > maxPieSlices {
+			return fmt.Errorf("too many slices (%d), group the smallest into 'Other' to get to %d", len(c.Labels), maxPieSlices)
+		}
+		total := 0.0
+		for _, v := range c.Series[0].Values {
+			if v < 0 {
+				return errors.New("pie values can't be negative")
+			}
+			total += v
+		}
+		if total == 0 {
+			return errors.New("pie values add up to zero")
+		}
+	}
+	return nil
+}
+
+func newCanvas() (*canvas, error) {
+	fontOnce.Do(func() {
+		goFont, fontErr = opentype.Parse(goregular.TTF)
+	})
+	if fontErr != nil {
+		return nil, fmt.Errorf("load font: %w", fontErr)
+	}
+
+	// faces cache glyphs and aren't safe to share, so each chart gets its own
+	text, err := opentype.NewFace(goFont, &opentype.FaceOptions{Size: 14, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("load font: %w", err)
+	}
+	title, err := opentype.NewFace(goFont, &opentype.FaceOptions{Size: 22, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("load font: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	return &canvas{img: img, ras: vector.NewRasterizer(width, height), text: text, title: title}, nil
+}
+
+// axes draws line and bar charts: title, y grid with ticks, x labels, data and legend
+func (cv *canvas) axes(c Chart) {
+	top := 30
+	if c.Title != "" {
+		cv.drawText(c.Title, width/2, 42, alignCenter, cv.title, textColor)
+		top = 80
+	}
+	if c.YLabel != "" {
+		top = max(top, 56)
+		cv.drawText(c.YLabel, 20, top-20, alignLeft, cv.text, mutedColor)
+	}
+	bottom := height - 50
+	if len(c.Series) > 1 {
+		bottom -= 30
+	}
+
+	lo, hi := valueRange(c)
+	ticks := niceTicks(lo, hi, 6)
+	lo, hi = ticks[0], ticks[len(ticks)-1]
+
+	tickLabels := formatTicks(ticks)
+	labelWidth := 0
+	for _, l := range tickLabels {
+		labelWidth = max(labelWidth, cv.measure(l, cv.text))
+	}
+	left := 20 + labelWidth + 10
+	right := width - 30
+
+	y := func(v float64) float32 {
+		return float32(bottom) - float32((v-lo)/(hi-lo))*float32(bottom-top)
+	}
+	for i, t := range ticks {
+		ty := int(math.Round(float64(y(t))))
+		cv.fillRect(image.Rect(left, ty, right, ty+1), gridColor)
+		cv.drawText(tickLabels[i], left-8, ty+5, alignRight, cv.text, mutedColor)
+	}
+
+	zero := y(math.Max(lo, math.Min(0, hi)))
+	xs := make([]float32, len(c.Labels))
+	slot := float32(right-left) / float32(len(c.Labels))
+
+	if c.Type == TypeBar {
+		barWidth := slot * 0.8 / float32(len(c.Series))
+		for i := range c.Labels {
+			xs[i] = float32(left) + (float32(i)+0.5)*slot
+			x0 := xs[i] - slot*0.4
+			for j, s := range c.Series {
+				top, bottom := y(s.Values[i]), zero
+				if top > bottom {
+					top, bottom = bottom, top
+				}
+				cv.polygon(palette[j%len(palette)],
+					x0+float32(j)*barWidth+1, top,
+					x0+float32(j+1)*barWidth-1, top,
+					x0+float32(j+1)*barWidth-1, bottom,
+					x0+float32(j)*barWidth+1, bottom)
+			}
+		}
+	} else {
+		const pad = 16
+		for i := range c.Labels {
+			if len(c.Labels) == 1 {
+				xs[i] = float32(left+right) / 2
+			} else {
+				xs[i] = float32(left+pad) + float32(i)*float32(right-left-2*pad)/float32(len(c.Labels)-1)
+			}
+		}
+		for j, s := range c.Series {
+			col := palette[j%len(palette)]
+			for i := 1; i < len(s.Values); i++ {
+				cv.line(xs[i-1], y(s.Values[i-1]), xs[i], y(s.Values[i]), 2.5, col)
+			}
+			// markers only while there are few points, small dots still round off the joins
+			for i, v := range s.Values {
+				if len(s.Values) <= 60 {
+					cv.circle(xs[i], y(v), 4, col)
+				} else {
+					cv.circle(xs[i], y(v), 1.25, col)
+				}
+			}
+		}
+	}
+
+	cv.fillRect(image.Rect(left, int(zero), right, int(zero)+1), axisColor)
+	cv.xLabels(c.Labels, xs, bottom+22)
+
+	if len(c.Series) > 1 {
+		names := make([]string, len(c.Series))
+		for i, s := range c.Series {
+			names[i] = s.Name
+			if names[i] == "" {
+				names[i] = fmt.Sprintf("Series %d", i+1)
+			}
+		}
+		cv.legend(names, height-24)
+	}
+}
+
+// xLabels skips labels evenly when they'd overlap, e.g. daily data over a year
+func (cv *canvas) xLabels(labels []string, xs []float32, baseline int) {
+	widest := 0
+	for _, l := range labels {
+		widest = max(widest, cv.measure(shorten(l), cv.text))
+	}
+	span := float32(width)
+	if len(xs) > 1 {
+		span = (xs[len(xs)-1] - xs[0]) / float32(len(xs)-1)
+	}
+	every := int(math.Ceil(float64(widest+12) / float64(span)))
+	for i, l := range labels {
+		if i%max(every, 1) != 0 {
+			continue
+		}
+		cv.drawText(shorten(l), int(xs[i]), baseline, alignCenter, cv.text, textColor)
+	}
+}
+
+func (cv *canvas) legend(names []string, baseline int) {
+	const swatch, gap = 12, 24
+	total := 0
+	for _, n := range names {
+		total += swatch + 6 + cv.measure(shorten(n), cv.text) + gap
+	}
+	x := (width - total + gap) / 2
+	for i, n := range names {
+		cv.fillRect(image.Rect(x, baseline-swatch+1, x+swatch, baseline+1), palette[i%len(palette)])
+		x += swatch + 6
+		cv.drawText(shorten(n), x, baseline, alignLeft, cv.text, textColor)
+		x += cv.measure(shorten(n), cv.text) + gap
+	}
+}
+
+// pie draws the first series as slices clockwise from 12 o'clock, with a
+// legend giving each slice's value and share
+func (cv *canvas) pie(c Chart) {
+	top := 20
+	if c.Title != "" {
+		cv.drawText(c.Title, width/2, 42, alignCenter, cv.title, textColor)
+		top = 70
+	}
+
+	values := c.Series[0].Values
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+
+	cx, cy := float32(320), float32(top+height)/2
+	r := float32(height-top-40) / 2
+	angle := -math.Pi / 2
+	var edges []float64
+	for i, v := range values {
+		sweep := v / total * 2 * math.Pi
+		if sweep == 0 {
+			continue
+		}
+		pts := []float32{cx, cy}
+		steps := max(2, int(sweep*40))
+		for s := 0; s <= steps; s++ {
+			a := angle + sweep*float64(s)/float64(steps)
+			pts = append(pts, cx+r*float32(math.Cos(a)), cy+r*float32(math.Sin(a)))
+		}
+		cv.polygon(palette[i%len(palette)], pts...)
+		edges = append(edges, angle)
+		angle += sweep
+	}
+	if len(edges) > 1 {
+		for _, a := range edges {
+			cv.line(cx, cy, cx+r*float32(math.Cos(a)), cy+r*float32(math.Sin(a)), 2, background)
+		}
+	}
+
+	const rowHeight = 30
+	x := 620
+	baseline := int(cy) - (len(values)*rowHeight)/2 + 18
+	for i, v := range values {
+		cv.fillRect(image.Rect(x, baseline-13, x+14, baseline+1), palette[i%len(palette)])
+		cv.drawText(shorten(c.Labels[i]), x+24, baseline, alignLeft, cv.text, textColor)
+		detail := fmt.Sprintf("%s (%.1f%%)", formatValue(v), v/total*100)
+		cv.drawText(detail, width-30, baseline, alignRight, cv.text, mutedColor)
+		baseline += rowHeight
+	}
+}
+
+func (cv *canvas) drawText(s string, x, y int, a align, face font.Face, col color.Color) {
+	switch a {
+	case alignCenter:
+		x -= cv.measure(s, face) / 2
+	case alignRight:
+		x -= cv.measure(s, face)
+	}
+	d := font.Drawer{Dst: cv.img, Src: image.NewUniform(col), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+func (cv *canvas) measure(s string, face font.Face) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+func (cv *canvas) fillRect(r image.Rectangle, col color.Color) {
+	draw.Draw(cv.img, r, image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// polygon fills an antialiased shape given as x, y pairs
+func (cv *canvas) polygon(col color.Color, pts ...float32) {
+	cv.ras.Reset(width, height)
+	cv.ras.MoveTo(pts[0], pts[1])
+	for i := 2; i+1 < len(pts); i += 2 {
+		cv.ras.LineTo(pts[i], pts[i+1])
+	}
+	cv.ras.ClosePath()
+	cv.ras.Draw(cv.img, cv.img.Bounds(), image.NewUniform(col), image.Point{})
+}
+
+func (cv *canvas) line(x0, y0, x1, y1, w float32, col color.Color) {
+	dx, dy := x1-x0, y1-y0
+	l := float32(math.Hypot(float64(dx), float64(dy)))
+	if l == 0 {
+		return
+	}
+	nx, ny := -dy/l*w/2, dx/l*w/2
+	cv.polygon(col, x0+nx, y0+ny, x1+nx, y1+ny, x1-nx, y1-ny, x0-nx, y0-ny)
+}
+
+func (cv *canvas) circle(x, y, r float32, col color.Color) {
+	const steps = 20
+	pts := make([]float32, 0, 2*steps)
+	for i := 0; i < steps; i++ {
+		a := 2 * math.Pi * float64(i) / steps
+		pts = append(pts, x+r*float32(math.Cos(a)), y+r*float32(math.Sin(a)))
+	}
+	cv.polygon(col, pts...)
+}
+
+// valueRange spans the data, and zero for bars and for lines whose values sit
+// close enough to it that leaving it out would exaggerate small changes
+func valueRange(c Chart) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if c.Type == TypeBar || (lo > 0 && lo < (hi-lo)) || (hi < 0 && -hi < (hi-lo)) {
+		lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	}
+	return lo, hi
+}
+
+// niceTicks returns evenly spaced round values covering lo..hi, e.g. 0, 250, 500
+func niceTicks(lo, hi float64, n int) []float64 {
+	if hi == lo {
+		if lo == 0 {
+			hi = 1
+		} else {
+			lo, hi = lo-math.Abs(lo)*0.1, hi+math.Abs(hi)*0.1
+		}
+	}
+
+	step := niceNum((hi - lo) / float64(n-1))
+	start := math.Floor(lo/step) * step
+	count := int(math.Round((math.Ceil(hi/step)*step-start)/step)) + 1
+
+	ticks := make([]float64, 0, count)
+	for i := 0; i < count; i++ {
+		v := start + float64(i)*step
+		if math.Abs(v) < step*1e-9 {
+			v = 0
+		}
+		ticks = append(ticks, v)
+	}
+	if len(ticks) < 2 {
+		ticks = append(ticks, start+step)
+	}
+	return ticks
+}
+
+func niceNum(x float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(x)))
+	switch f := x / exp; {
+	case f <= 1:
+		return exp
+	case f <= 2:
+		return 2 * exp
+	case f <= 5:
+		return 5 * exp
+	default:
+		return 10 * exp
+	}
+}
+
+// formatTicks abbreviates large values (12k, 1.5M) with one unit for the whole
+// axis and shows as many decimals as the tick step needs
+func formatTicks(ticks []float64) []string {
+	step := ticks[1] - ticks[0]
+	largest := math.Max(math.Abs(ticks[0]), math.Abs(ticks[len(ticks)-1]))
+
+	scale, suffix := 1.0, ""
+	switch {
+	case largest >= 1e9:
+		scale, suffix = 1e9, "B"
+	case largest >= 1e6:
+		scale, suffix = 1e6, "M"
+	case largest >= 1e4:
+		scale, suffix = 1e3, "k"
+	}
+	step /= scale
+
+	decimals := 0
+	for decimals < 6 {
+		scaled := step * math.Pow(10, float64(decimals))
+		if math.Abs(scaled-math.Round(scaled)) < 1e-9 {
+			break
+		}
+		decimals++
+	}
+
+	labels := make([]string, len(ticks))
+	for i, t := range ticks {
+		if t == 0 {
+			labels[i] = "0"
+			continue
+		}
+		labels[i] = strconv.FormatFloat(t/scale, 'f', decimals, 64) + suffix
+	}
+	return labels
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// shorten keeps long labels from running into their neighbours
+func shorten(s string) string {
+	if utf8.RuneCountInString(s) <= maxLabelRunes {
+		return s
+	}
+	return string([]rune(s)[:maxLabelRunes-1]) + "…"
+}
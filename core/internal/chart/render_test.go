@@ -0,0 +1,103 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	charts := []Chart{
+		{
+			Type:   TypeLine,
+			Title:  "Tokens per day",
+			YLabel: "tokens",
+			Labels: []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+			Series: []Series{
+				{Name: "input", Values: []float64{12000, 18500, 9000, 22000, 15000}},
+				{Name: "output", Values: []float64{3000, 4100, 2500, 5200, 3900}},
+			},
+		},
+		{
+			Type:   TypeBar,
+			Title:  "Spending by category",
+			Labels: []string{"Groceries", "Rent", "Transport", "Eating out with friends and colleagues"},
+			Series: []Series{{Name: "March", Values: []float64{310.5, 1250, 86, -20}}},
+		},
+		{
+			Type:   TypePie,
+			Labels: []string{"Disk", "Memory", "Free"},
+			Series: []Series{{Values: []float64{40, 25, 35}}},
+		},
+	}
+
+	for _, c := range charts {
+		data, err := Render(c)
+		if err != nil {
+			t.Fatalf("%s: %v", c.Type, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", c.Type, err)
+		}
+		if b := img.Bounds(); b.Dx() != width || b.Dy() != height {
+			t.Errorf("%s: size = %v", c.Type, b)
+		}
+
+		// the first series color must appear somewhere
+		found := false
+		for y := 0; y < height && !found; y += 2 {
+			for x := 0; x < width; x += 2 {
+				r, g, b, _ := img.At(x, y).RGBA()
+				if uint8(r>>8) == palette[0].R && uint8(g>>8) == palette[0].G && uint8(b>>8) == palette[0].B {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s: no data drawn", c.Type)
+		}
+	}
+}
+
+func TestRenderValidation(t *testing.T) {
+	tests := []struct {
+		chart Chart
+		want  string
+	}{
+		{Chart{Type: "scatter", Labels: []string{"a"}, Series: []Series{{Values: []float64{1}}}}, "unknown chart type"},
+		{Chart{Type: TypeLine, Series: []Series{{Values: []float64{1}}}}, "labels are required"},
+		{Chart{Type: TypeBar, Labels: []string{"a", "b"}, Series: []Series{{Name: "x", Values: []float64{1}}}}, `series "x" has 1 values for 2 labels`},
+		{Chart{Type: TypePie, Labels: []string{"a", "b"}, Series: []Series{{Values: []float64{1, -1}}}}, "negative"},
+		{Chart{Type: TypePie, Labels: []string{"a"}, Series: []Series{{Values: []float64{0}}}}, "add up to zero"},
+	}
+
+	for _, tt := range tests {
+		_, err := Render(tt.chart)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Render(%+v) error = %v, want %q", tt.chart, err, tt.want)
+		}
+	}
+}
+
+func TestTicks(t *testing.T) {
+	tests := []struct {
+		lo, hi float64
+		want   string
+	}{
+		{0, 1250, "0 500 1000 1500"},
+		{-20, 1250, "-500 0 500 1000 1500"},
+		{0, 22000, "0 5k 10k 15k 20k 25k"},
+		{0.1, 0.9, "0 0.2 0.4 0.6 0.8 1.0"},
+		{5, 5, "4.4 4.6 4.8 5.0 5.2 5.4 5.6"},
+	}
+
+	for _, tt := range tests {
+		got := strings.Join(formatTicks(niceTicks(tt.lo, tt.hi, 6)), " ")
+		if got != tt.want {
+			t.Errorf("ticks(%v, %v) = %s, want %s", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
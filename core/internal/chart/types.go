@@ -0,0 +1,24 @@
+package chart
+
+// Chart types
+const (
+	TypeLine = "line"
+	TypeBar  = "bar"
+	TypePie  = "pie"
+)
+
+// Chart is the data for one chart. Every series has one value per label;
+// a pie chart uses only the first series.
+type Chart struct {
+	Type   string
+	Title  string
+	YLabel string // optional unit or axis name, e.g. "EUR" or "GB"
+	Labels []string
+	Series []Series
+}
+
+// Series is one named line, bar group member or set of pie slices
+type Series struct {
+	Name   string
+	Values []float64
+}
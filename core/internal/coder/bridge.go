@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/bowerhall/sheldon/internal/budget"
 	"github.com/bowerhall/sheldon/internal/config"
 	"github.com/bowerhall/sheldon/internal/logger"
 )
@@ -28,6 +30,15 @@ type Bridge struct {
 	useIsolated  bool
 	// git operations (handled externally, not by coder)
 	gitOps *GitOps
+	// run detected tests/vet/lint after the coder finishes
+	verify      bool
+	fixAttempts int
+	// counts coder token usage against the daily budget
+	budget *budget.Tracker
+	// diff review before pushing; diffs up to autoApproveLines push without asking
+	reviewer         PushReviewer
+	reviewPushes     bool
+	autoApproveLines int
 }
 
 // BridgeConfig holds configuration for the Bridge
@@ -39,12 +50,22 @@ type BridgeConfig struct {
 	SkillsDir      string // directory with skill templates
 	Isolated       bool   // use ephemeral Docker containers
 	Image          string // coder container image
+	Verify         bool   // run detected tests/vet/lint before pushing
+	FixAttempts    int    // coder re-runs allowed to fix failing checks
 	// git integration
 	GitEnabled   bool
 	GitUserName  string
 	GitUserEmail string
 	GitOrgURL    string
 	GitToken     string
+	// ask before pushing diffs larger than AutoApproveLines changed lines
+	ReviewPushes     bool
+	AutoApproveLines int
+	// container runtime for isolated mode (docker/podman, network, uid mapping)
+	Runtime RuntimeConfig
+	// per-container resource caps and per-task workspace quota for isolated mode
+	Limits         ResourceLimits
+	WorkspaceQuota int64
 }
 
 func NewBridge(sandboxDir, provider, model string) (*Bridge, error) {
@@ -69,6 +90,11 @@ func NewBridgeWithConfig(cfg BridgeConfig) (*Bridge, error) {
 	b := &Bridge{
 		useIsolated: cfg.Isolated,
 		gitOps:      NewGitOps(gitCfg),
+		verify:      cfg.Verify,
+		fixAttempts: cfg.FixAttempts,
+
+		reviewPushes:     cfg.ReviewPushes,
+		autoApproveLines: cfg.AutoApproveLines,
 	}
 
 	// load skills if directory is configured
@@ -77,6 +103,7 @@ func NewBridgeWithConfig(cfg BridgeConfig) (*Bridge, error) {
 	}
 
 	if cfg.Isolated {
+		runtime := NewRuntime(cfg.Runtime)
 		b.dockerRunner = NewDockerRunner(DockerRunnerConfig{
 			Image:           cfg.Image,
 			ArtifactsDir:    cfg.SandboxDir,
@@ -84,8 +111,11 @@ func NewBridgeWithConfig(cfg BridgeConfig) (*Bridge, error) {
 			Provider:        cfg.Provider,
 			Model:           cfg.Model,
 			Git:             gitCfg,
+			Runtime:         runtime,
+			Limits:          cfg.Limits,
+			WorkspaceQuota:  cfg.WorkspaceQuota,
 		})
-		logger.Info("coder bridge using isolated containers", "image", cfg.Image)
+		logger.Info("coder bridge using isolated containers", "image", cfg.Image, "runtime", runtime.Name(), "rootless", runtime.Rootless())
 	} else {
 		sandbox, err := NewSandboxWithGit(cfg.SandboxDir, cfg.Provider, cfg.Model, gitCfg)
 		if err != nil {
@@ -104,8 +134,15 @@ func (b *Bridge) Execute(ctx context.Context, task Task) (*Result, error) {
 		cfg = complexityConfig[ComplexityStandard]
 	}
 
+	if err := b.checkBudget(); err != nil {
+		return nil, err
+	}
+
 	// enrich prompt with relevant skills
 	task.Prompt = b.enrichPrompt(task.Prompt)
+	if task.Continue {
+		task.Prompt = continuePrompt(task.Prompt)
+	}
 
 	// use Docker containers if isolated mode
 	if b.useIsolated && b.dockerRunner != nil {
@@ -129,6 +166,37 @@ func (b *Bridge) enrichPrompt(prompt string) string {
 	return prompt + skills
 }
 
+// prepareRepo clones task.GitRepo into the workspace and reports whether the repo is present.
+// Follow-up tasks keep the existing checkout so earlier work isn't discarded.
+func (b *Bridge) prepareRepo(ctx context.Context, task Task, dir string) bool {
+	if task.GitRepo == "" || b.gitOps == nil {
+		return false
+	}
+
+	if task.Continue {
+		return IsGitRepo(dir)
+	}
+
+	if err := b.gitOps.CloneRepo(ctx, task.GitRepo, dir); err != nil {
+		logger.Warn("git clone failed, proceeding without repo", "error", err, "repo", task.GitRepo)
+		return false
+	}
+
+	logger.Debug("cloned repo for coder", "repo", task.GitRepo, "path", dir)
+	return true
+}
+
+// continuePrompt tells the coder to build on the existing workspace instead of starting over
+func continuePrompt(prompt string) string {
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	sb.WriteString("\n\n## Follow-up Task\n")
+	sb.WriteString("- Your workspace already contains the project from an earlier task\n")
+	sb.WriteString("- Read the existing code first and make only the changes requested above\n")
+	sb.WriteString("- Do NOT regenerate, restructure or delete files unrelated to this change\n")
+	return sb.String()
+}
+
 // enrichPromptWithGitContext adds git repo context to the prompt
 // Note: The repo has already been cloned by Sheldon before passing to coder.
 // Coder should NOT have access to git push credentials.
@@ -169,24 +237,20 @@ func (b *Bridge) executeWithDocker(ctx context.Context, task Task, cfg struct {
 	workDir, _ := b.GetLocalWorkspacePath(ctx, task.ID)
 
 	// Clone repo before passing to coder (if GitRepo is set)
-	repoCloned := false
-	if task.GitRepo != "" && b.gitOps != nil {
-		if err := b.gitOps.CloneRepo(taskCtx, task.GitRepo, workDir); err != nil {
-			logger.Warn("git clone failed, proceeding without repo", "error", err, "repo", task.GitRepo)
-		} else {
-			repoCloned = true
-			logger.Debug("cloned repo for coder", "repo", task.GitRepo, "path", workDir)
-		}
-	}
+	repoCloned := b.prepareRepo(taskCtx, task, workDir)
 
-	result, err := b.dockerRunner.RunJob(taskCtx, JobConfig{
+	job := JobConfig{
 		TaskID:   task.ID,
 		Prompt:   b.enrichPromptWithGitContext(task.Prompt, task.GitRepo, repoCloned),
 		MaxTurns: cfg.MaxTurns,
 		Timeout:  cfg.Timeout,
 		Context:  task.Context,
 		GitRepo:  task.GitRepo,
-	})
+	}
+	result, err := b.dockerRunner.RunJob(taskCtx, job)
+	if result != nil {
+		b.charge(result, task.ID, result.Usage)
+	}
 
 	if err != nil {
 		logger.Error("coder docker job failed", "error", err, "task", task.ID)
@@ -198,18 +262,19 @@ func (b *Bridge) executeWithDocker(ctx context.Context, task Task, cfg struct {
 			"sanitized", result.Sanitized,
 		)
 
+		result.Verification = b.runVerification(taskCtx, task.ID, job.Prompt, result.WorkspacePath, func(ctx context.Context, prompt string) error {
+			job.Prompt = prompt
+			fix, err := b.dockerRunner.RunJob(ctx, job)
+			if fix != nil {
+				b.charge(result, task.ID, fix.Usage)
+				mergeFixResult(result, fix)
+			}
+			return err
+		})
+
 		// Push changes after coder completes (if GitRepo is set)
 		if task.GitRepo != "" && b.gitOps != nil {
-			branchName := "sheldon/" + task.ID
-			pushed, pushErr := b.gitOps.PushChanges(taskCtx, result.WorkspacePath, task.GitRepo, branchName)
-			if pushErr != nil {
-				logger.Error("git push failed", "error", pushErr, "repo", task.GitRepo)
-				result.GitError = pushErr.Error()
-			} else if pushed {
-				logger.Debug("pushed changes to repo", "repo", task.GitRepo, "branch", branchName)
-				result.GitPushed = true
-				result.GitBranch = branchName
-			}
+			b.pushChanges(taskCtx, task, result, result.WorkspacePath)
 		}
 	}
 
@@ -232,15 +297,7 @@ func (b *Bridge) executeWithSubprocess(ctx context.Context, task Task, cfg struc
 	}
 
 	// Clone repo before passing to coder (if GitRepo is set)
-	repoCloned := false
-	if task.GitRepo != "" && b.gitOps != nil {
-		if err := b.gitOps.CloneRepo(taskCtx, task.GitRepo, ws.Path); err != nil {
-			logger.Warn("git clone failed, proceeding without repo", "error", err, "repo", task.GitRepo)
-		} else {
-			repoCloned = true
-			logger.Debug("cloned repo for coder", "repo", task.GitRepo, "path", ws.Path)
-		}
-	}
+	repoCloned := b.prepareRepo(taskCtx, task, ws.Path)
 
 	// don't cleanup - workspace persists for build_image/deploy
 	// cleanup happens via periodic cleanup or cleanup_images tool
@@ -257,7 +314,9 @@ func (b *Bridge) executeWithSubprocess(ctx context.Context, task Task, cfg struc
 		prompt = b.enrichPromptWithGitContext(prompt, task.GitRepo, repoCloned)
 	}
 
-	output, err := b.run(taskCtx, ws, prompt, cfg.MaxTurns)
+	output, usage, err := b.run(taskCtx, ws, prompt, cfg.MaxTurns)
+	result.Usage = usage
+	b.charge(result, task.ID, usage)
 	if err != nil {
 		result.Error = err.Error()
 		logger.Error("claude code failed", "error", err, "task", task.ID)
@@ -269,6 +328,17 @@ func (b *Bridge) executeWithSubprocess(ctx context.Context, task Task, cfg struc
 	result.Sanitized = len(warnings) > 0
 	result.Duration = time.Since(start)
 
+	if result.Error == "" {
+		result.Verification = b.runVerification(taskCtx, task.ID, prompt, ws.Path, func(ctx context.Context, retry string) error {
+			output, usage, err := b.run(ctx, ws, retry, cfg.MaxTurns)
+			result.Usage.Add(usage)
+			b.charge(result, task.ID, usage)
+			mergeFixOutput(result, output)
+			return err
+		})
+		result.Duration = time.Since(start)
+	}
+
 	files, _ := b.sandbox.CollectFiles(ws)
 	result.Files = files
 	result.WorkspacePath = ws.Path
@@ -282,22 +352,13 @@ func (b *Bridge) executeWithSubprocess(ctx context.Context, task Task, cfg struc
 
 	// Push changes after coder completes (if GitRepo is set)
 	if task.GitRepo != "" && b.gitOps != nil && result.Error == "" {
-		branchName := "sheldon/" + task.ID
-		pushed, pushErr := b.gitOps.PushChanges(taskCtx, ws.Path, task.GitRepo, branchName)
-		if pushErr != nil {
-			logger.Error("git push failed", "error", pushErr, "repo", task.GitRepo)
-			result.GitError = pushErr.Error()
-		} else if pushed {
-			logger.Debug("pushed changes to repo", "repo", task.GitRepo, "branch", branchName)
-			result.GitPushed = true
-			result.GitBranch = branchName
-		}
+		b.pushChanges(taskCtx, task, result, ws.Path)
 	}
 
 	return result, nil
 }
 
-func (b *Bridge) run(ctx context.Context, ws *Workspace, prompt string, maxTurns int) (string, error) {
+func (b *Bridge) run(ctx context.Context, ws *Workspace, prompt string, maxTurns int) (string, Usage, error) {
 	// Build ollama launch claude command with model from sandbox config
 	model := b.sandbox.model
 	if model == "" {
@@ -310,7 +371,7 @@ func (b *Bridge) run(ctx context.Context, ws *Workspace, prompt string, maxTurns
 		"--model", model,
 		"--",
 		"--print",
-		"--output-format", "text",
+		"--output-format", "json",
 		"--max-turns", fmt.Sprintf("%d", maxTurns),
 		"--dangerously-skip-permissions",
 		"-p", prompt,
@@ -329,16 +390,16 @@ func (b *Bridge) run(ctx context.Context, ws *Workspace, prompt string, maxTurns
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return "", fmt.Errorf("stdout pipe: %w", err)
+		return "", Usage{}, fmt.Errorf("stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return "", fmt.Errorf("stderr pipe: %w", err)
+		return "", Usage{}, fmt.Errorf("stderr pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("start: %w", err)
+		return "", Usage{}, fmt.Errorf("start: %w", err)
 	}
 
 	// capture stderr
@@ -369,17 +430,19 @@ func (b *Bridge) run(ctx context.Context, ws *Workspace, prompt string, maxTurns
 
 	<-stderrDone // wait for stderr goroutine to finish
 
+	text, usage := parseJSONOutput(output.String())
+
 	if err := cmd.Wait(); err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return output.String(), fmt.Errorf("timeout exceeded")
+			return text, usage, fmt.Errorf("timeout exceeded")
 		}
 		if stderrBuf.Len() > 0 {
 			logger.Error("claude stderr output", "stderr", stderrBuf.String())
 		}
-		return output.String(), fmt.Errorf("exit: %w", err)
+		return text, usage, fmt.Errorf("exit: %w", err)
 	}
 
-	return output.String(), nil
+	return text, usage, nil
 }
 
 func (b *Bridge) ExecuteWithProgress(ctx context.Context, task Task, onProgress func(StreamEvent)) (*Result, error) {
@@ -388,8 +451,15 @@ func (b *Bridge) ExecuteWithProgress(ctx context.Context, task Task, onProgress
 		cfg = complexityConfig[ComplexityStandard]
 	}
 
+	if err := b.checkBudget(); err != nil {
+		return nil, err
+	}
+
 	// enrich prompt with relevant skills
 	task.Prompt = b.enrichPrompt(task.Prompt)
+	if task.Continue {
+		task.Prompt = continuePrompt(task.Prompt)
+	}
 
 	// use Docker containers if isolated mode
 	if b.useIsolated && b.dockerRunner != nil {
@@ -412,24 +482,20 @@ func (b *Bridge) executeWithDockerProgress(ctx context.Context, task Task, cfg s
 	workDir, _ := b.GetLocalWorkspacePath(ctx, task.ID)
 
 	// Clone repo before passing to coder (if GitRepo is set)
-	repoCloned := false
-	if task.GitRepo != "" && b.gitOps != nil {
-		if err := b.gitOps.CloneRepo(taskCtx, task.GitRepo, workDir); err != nil {
-			logger.Warn("git clone failed, proceeding without repo", "error", err, "repo", task.GitRepo)
-		} else {
-			repoCloned = true
-			logger.Debug("cloned repo for coder", "repo", task.GitRepo, "path", workDir)
-		}
-	}
+	repoCloned := b.prepareRepo(taskCtx, task, workDir)
 
-	result, err := b.dockerRunner.RunJobWithProgress(taskCtx, JobConfig{
+	job := JobConfig{
 		TaskID:   task.ID,
 		Prompt:   b.enrichPromptWithGitContext(task.Prompt, task.GitRepo, repoCloned),
 		MaxTurns: cfg.MaxTurns,
 		Timeout:  cfg.Timeout,
 		Context:  task.Context,
 		GitRepo:  task.GitRepo,
-	}, onProgress)
+	}
+	result, err := b.dockerRunner.RunJobWithProgress(taskCtx, job, onProgress)
+	if result != nil {
+		b.charge(result, task.ID, result.Usage)
+	}
 
 	if err != nil {
 		logger.Error("coder docker job failed", "error", err, "task", task.ID)
@@ -440,18 +506,19 @@ func (b *Bridge) executeWithDockerProgress(ctx context.Context, task Task, cfg s
 			"files", len(result.Files),
 		)
 
+		result.Verification = b.runVerification(taskCtx, task.ID, job.Prompt, result.WorkspacePath, func(ctx context.Context, prompt string) error {
+			job.Prompt = prompt
+			fix, err := b.dockerRunner.RunJobWithProgress(ctx, job, onProgress)
+			if fix != nil {
+				b.charge(result, task.ID, fix.Usage)
+				mergeFixResult(result, fix)
+			}
+			return err
+		})
+
 		// Push changes after coder completes (if GitRepo is set)
 		if task.GitRepo != "" && b.gitOps != nil {
-			branchName := "sheldon/" + task.ID
-			pushed, pushErr := b.gitOps.PushChanges(taskCtx, result.WorkspacePath, task.GitRepo, branchName)
-			if pushErr != nil {
-				logger.Error("git push failed", "error", pushErr, "repo", task.GitRepo)
-				result.GitError = pushErr.Error()
-			} else if pushed {
-				logger.Debug("pushed changes to repo", "repo", task.GitRepo, "branch", branchName)
-				result.GitPushed = true
-				result.GitBranch = branchName
-			}
+			b.pushChanges(taskCtx, task, result, result.WorkspacePath)
 		}
 	}
 
@@ -474,15 +541,7 @@ func (b *Bridge) executeWithSubprocessProgress(ctx context.Context, task Task, c
 	}
 
 	// Clone repo before passing to coder (if GitRepo is set)
-	repoCloned := false
-	if task.GitRepo != "" && b.gitOps != nil {
-		if err := b.gitOps.CloneRepo(taskCtx, task.GitRepo, ws.Path); err != nil {
-			logger.Warn("git clone failed, proceeding without repo", "error", err, "repo", task.GitRepo)
-		} else {
-			repoCloned = true
-			logger.Debug("cloned repo for coder", "repo", task.GitRepo, "path", ws.Path)
-		}
-	}
+	repoCloned := b.prepareRepo(taskCtx, task, ws.Path)
 
 	// don't cleanup - workspace persists for build_image/deploy
 
@@ -498,7 +557,9 @@ func (b *Bridge) executeWithSubprocessProgress(ctx context.Context, task Task, c
 		prompt = b.enrichPromptWithGitContext(prompt, task.GitRepo, repoCloned)
 	}
 
-	output, err := b.runWithProgress(taskCtx, ws, prompt, cfg.MaxTurns, onProgress)
+	output, usage, err := b.runWithProgress(taskCtx, ws, prompt, cfg.MaxTurns, onProgress)
+	result.Usage = usage
+	b.charge(result, task.ID, usage)
 	if err != nil {
 		result.Error = err.Error()
 		logger.Error("claude code failed", "error", err, "task", task.ID)
@@ -510,28 +571,30 @@ func (b *Bridge) executeWithSubprocessProgress(ctx context.Context, task Task, c
 	result.Sanitized = len(warnings) > 0
 	result.Duration = time.Since(start)
 
+	if result.Error == "" {
+		result.Verification = b.runVerification(taskCtx, task.ID, prompt, ws.Path, func(ctx context.Context, retry string) error {
+			output, usage, err := b.runWithProgress(ctx, ws, retry, cfg.MaxTurns, onProgress)
+			result.Usage.Add(usage)
+			b.charge(result, task.ID, usage)
+			mergeFixOutput(result, output)
+			return err
+		})
+		result.Duration = time.Since(start)
+	}
+
 	files, _ := b.sandbox.CollectFiles(ws)
 	result.Files = files
 	result.WorkspacePath = ws.Path
 
 	// Push changes after coder completes (if GitRepo is set)
 	if task.GitRepo != "" && b.gitOps != nil && result.Error == "" {
-		branchName := "sheldon/" + task.ID
-		pushed, pushErr := b.gitOps.PushChanges(taskCtx, ws.Path, task.GitRepo, branchName)
-		if pushErr != nil {
-			logger.Error("git push failed", "error", pushErr, "repo", task.GitRepo)
-			result.GitError = pushErr.Error()
-		} else if pushed {
-			logger.Debug("pushed changes to repo", "repo", task.GitRepo, "branch", branchName)
-			result.GitPushed = true
-			result.GitBranch = branchName
-		}
+		b.pushChanges(taskCtx, task, result, ws.Path)
 	}
 
 	return result, nil
 }
 
-func (b *Bridge) runWithProgress(ctx context.Context, ws *Workspace, prompt string, maxTurns int, onProgress func(StreamEvent)) (string, error) {
+func (b *Bridge) runWithProgress(ctx context.Context, ws *Workspace, prompt string, maxTurns int, onProgress func(StreamEvent)) (string, Usage, error) {
 	// Build ollama launch claude command with model from sandbox config
 	model := b.sandbox.model
 	if model == "" {
@@ -564,16 +627,16 @@ func (b *Bridge) runWithProgress(ctx context.Context, ws *Workspace, prompt stri
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return "", fmt.Errorf("stdout pipe: %w", err)
+		return "", Usage{}, fmt.Errorf("stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return "", fmt.Errorf("stderr pipe: %w", err)
+		return "", Usage{}, fmt.Errorf("stderr pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("start: %w", err)
+		return "", Usage{}, fmt.Errorf("start: %w", err)
 	}
 
 	// capture stderr in background
@@ -590,6 +653,7 @@ func (b *Bridge) runWithProgress(ctx context.Context, ws *Workspace, prompt stri
 		}
 	}()
 
+	var usage Usage
 	scanner := bufio.NewScanner(stdout)
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
 
@@ -629,6 +693,7 @@ func (b *Bridge) runWithProgress(ctx context.Context, ws *Workspace, prompt stri
 			}
 
 		case "result":
+			usage, _ = parseResultEvent(line)
 			if onProgress != nil {
 				onProgress(StreamEvent{Type: "complete"})
 			}
@@ -639,15 +704,82 @@ func (b *Bridge) runWithProgress(ctx context.Context, ws *Workspace, prompt stri
 
 	if err := cmd.Wait(); err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return output.String(), fmt.Errorf("timeout exceeded")
+			return output.String(), usage, fmt.Errorf("timeout exceeded")
 		}
 		if stderrBuf.Len() > 0 {
 			logger.Error("claude stderr output", "stderr", stderrBuf.String())
 		}
-		return output.String(), fmt.Errorf("exit: %w", err)
+		return output.String(), usage, fmt.Errorf("exit: %w", err)
+	}
+
+	return output.String(), usage, nil
+}
+
+// pushChanges pushes the workspace to the task branch, asking the user first
+// when the diff is larger than the auto-approve threshold
+func (b *Bridge) pushChanges(ctx context.Context, task Task, result *Result, workDir string) {
+	branchName := "sheldon/" + task.ID
+
+	diff, err := b.gitOps.DiffSummary(ctx, workDir, branchName)
+	if err != nil {
+		logger.Error("git diff failed", "error", err, "repo", task.GitRepo)
+		result.GitError = err.Error()
+		return
+	}
+	result.Diff = diff
+
+	if diff.FilesChanged > 0 && b.reviewer != nil && b.reviewPushes && diff.Lines() > b.autoApproveLines {
+		approved, err := b.reviewer(ctx, PushReview{TaskID: task.ID, Repo: task.GitRepo, Branch: branchName, Diff: diff})
+		if err != nil {
+			logger.Warn("push review failed", "error", err, "task", task.ID)
+			result.GitError = "push review failed: " + err.Error()
+			return
+		}
+		if !approved {
+			logger.Info("push declined by user", "task", task.ID, "repo", task.GitRepo)
+			result.GitDeclined = true
+			return
+		}
+	}
+
+	pushed, pushErr := b.gitOps.PushChanges(ctx, workDir, task.GitRepo, branchName)
+	if pushErr != nil {
+		logger.Error("git push failed", "error", pushErr, "repo", task.GitRepo)
+		result.GitError = pushErr.Error()
+	} else if pushed {
+		logger.Debug("pushed changes to repo", "repo", task.GitRepo, "branch", branchName)
+		result.GitPushed = true
+		result.GitBranch = branchName
+	}
+}
+
+// SetPushReviewer lets the user approve diffs before they're pushed
+func (b *Bridge) SetPushReviewer(reviewer PushReviewer) {
+	b.reviewer = reviewer
+}
+
+// mergeFixResult folds a docker fix run into the original result
+func mergeFixResult(result, fix *Result) {
+	mergeFixOutput(result, fix.Output)
+	result.Usage.Add(fix.Usage)
+	result.Duration += fix.Duration
+	result.Warnings = append(result.Warnings, fix.Warnings...)
+	result.Sanitized = result.Sanitized || fix.Sanitized
+	if len(fix.Files) > 0 {
+		result.Files = fix.Files
 	}
+}
 
-	return output.String(), nil
+// mergeFixOutput appends a fix run's sanitized output to the result
+func mergeFixOutput(result *Result, output string) {
+	sanitized, warnings := Sanitize(output)
+	if len(warnings) > 0 {
+		result.Warnings = append(result.Warnings, warnings...)
+		result.Sanitized = true
+	}
+	if strings.TrimSpace(sanitized) != "" {
+		result.Output += "\n\n[fix for failing checks]\n" + sanitized
+	}
 }
 
 // GetLocalWorkspacePath returns the local filesystem path for artifacts.
@@ -660,6 +792,16 @@ func (b *Bridge) GetLocalWorkspacePath(ctx context.Context, taskID string) (stri
 	return b.sandbox.baseDir + "/" + taskID, nil
 }
 
+// HasWorkspace reports whether a task's workspace still exists on disk
+func (b *Bridge) HasWorkspace(ctx context.Context, taskID string) bool {
+	path, err := b.GetLocalWorkspacePath(ctx, taskID)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 // CleanupTask removes artifacts for a completed task
 func (b *Bridge) CleanupTask(ctx context.Context, taskID string) error {
 	if b.useIsolated && b.dockerRunner != nil {
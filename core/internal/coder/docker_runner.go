@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,7 +16,7 @@ import (
 	"github.com/bowerhall/sheldon/internal/logger"
 )
 
-// DockerRunner runs code generation in ephemeral Docker containers
+// DockerRunner runs code generation in ephemeral Docker (or Podman) containers
 type DockerRunner struct {
 	image           string
 	artifactsDir    string
@@ -23,6 +24,9 @@ type DockerRunner struct {
 	provider        string
 	model           string
 	git             GitConfig
+	runtime         *Runtime
+	limits          ResourceLimits
+	workspaceQuota  int64
 }
 
 // DockerRunnerConfig holds configuration for DockerRunner
@@ -33,6 +37,35 @@ type DockerRunnerConfig struct {
 	Provider        string // LLM provider (kimi, claude, nvidia, ollama)
 	Model           string // model to use
 	Git             GitConfig
+	Runtime         *Runtime // container CLI (default: detected docker or podman)
+	Limits          ResourceLimits
+	WorkspaceQuota  int64 // max bytes a task's workspace may grow to (0 = unlimited)
+}
+
+// ResourceLimits caps what a single coder container can use. Empty or zero values leave a resource unlimited.
+type ResourceLimits struct {
+	CPUs   string // e.g. "2" or "1.5"
+	Memory string // e.g. "4g"; swap is capped at the same value
+	Pids   int    // max processes, guards against fork bombs
+	Disk   string // container rootfs size, e.g. "10g" (needs overlay2 on xfs with pquota)
+}
+
+// args returns the `run` flags for these limits
+func (l ResourceLimits) args() []string {
+	var args []string
+	if l.CPUs != "" && l.CPUs != "0" {
+		args = append(args, "--cpus", l.CPUs)
+	}
+	if l.Memory != "" && l.Memory != "0" {
+		args = append(args, "--memory", l.Memory, "--memory-swap", l.Memory)
+	}
+	if l.Pids > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(l.Pids))
+	}
+	if l.Disk != "" && l.Disk != "0" {
+		args = append(args, "--storage-opt", "size="+l.Disk)
+	}
+	return args
 }
 
 // JobConfig holds configuration for a code generation job
@@ -65,6 +98,10 @@ func NewDockerRunner(cfg DockerRunnerConfig) *DockerRunner {
 		cfg.HostArtifactDir = cfg.ArtifactsDir
 	}
 
+	if cfg.Runtime == nil {
+		cfg.Runtime = NewRuntime(RuntimeConfig{})
+	}
+
 	// ensure artifacts directory exists
 	os.MkdirAll(cfg.ArtifactsDir, 0755)
 
@@ -75,6 +112,9 @@ func NewDockerRunner(cfg DockerRunnerConfig) *DockerRunner {
 		provider:        cfg.Provider,
 		model:           cfg.Model,
 		git:             cfg.Git,
+		runtime:         cfg.Runtime,
+		limits:          cfg.Limits,
+		workspaceQuota:  cfg.WorkspaceQuota,
 	}
 }
 
@@ -88,10 +128,8 @@ func (r *DockerRunner) RunJob(ctx context.Context, cfg JobConfig) (*Result, erro
 		return nil, fmt.Errorf("create workspace: %w", err)
 	}
 
-	// ensure coder user (UID 1000) can write to workspace
-	if err := os.Chown(workDir, 1000, 1000); err != nil {
-		logger.Warn("could not chown workspace to coder user", "error", err)
-	}
+	// ensure coder user can write to workspace
+	r.runtime.PrepareWorkspace(workDir)
 
 	// write context file if provided
 	if cfg.Context != nil {
@@ -106,15 +144,16 @@ func (r *DockerRunner) RunJob(ctx context.Context, cfg JobConfig) (*Result, erro
 	// (when Sheldon runs in a container, Docker needs host paths for -v)
 	hostWorkDir := filepath.Join(r.hostArtifactDir, cfg.TaskID)
 
-	// build docker run command
-	args := []string{
-		"run", "--rm",
-		"--network", "sheldon-net",
-		"--add-host", "host.docker.internal:host-gateway", // for host ollama access
+	// build container run command
+	name := containerName(cfg.TaskID)
+	args := append(r.runtime.RunArgs(), r.runtime.HostGatewayArgs()...)
+	args = append(args, r.limits.args()...)
+	args = append(args,
+		"--name", name,
 		"-v", fmt.Sprintf("%s:/workspace", hostWorkDir),
 		"-w", "/workspace",
 		"-e", "OLLAMA_HOST="+os.Getenv("OLLAMA_HOST"), // inherit from parent
-	}
+	)
 
 	// pass API key for the configured provider
 	envKey := config.EnvKeyForProvider(r.provider)
@@ -140,13 +179,21 @@ func (r *DockerRunner) RunJob(ctx context.Context, cfg JobConfig) (*Result, erro
 	// add image and coder arguments
 	args = append(args, r.image,
 		"--print",
-		"--output-format", "text",
+		"--output-format", "json",
 		"--max-turns", fmt.Sprintf("%d", cfg.MaxTurns),
 		"--dangerously-skip-permissions",
 		"-p", cfg.Prompt,
 	)
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	// stop the job if the workspace outgrows its quota
+	jobCtx, stopJob := context.WithCancel(ctx)
+	defer stopJob()
+	quota := r.watchQuota(jobCtx, workDir, func() {
+		r.kill(name)
+		stopJob()
+	})
+
+	cmd := r.runtime.Command(jobCtx, args...)
 
 	const maxOutputBytes = 10 * 1024 * 1024 // 10MB output limit
 
@@ -196,13 +243,19 @@ func (r *DockerRunner) RunJob(ctx context.Context, cfg JobConfig) (*Result, erro
 
 	<-stderrDone // wait for stderr goroutine to finish
 
+	text, usage := parseJSONOutput(output.String())
 	result := &Result{
 		Duration:      time.Since(start),
 		WorkspacePath: workDir,
+		Usage:         usage,
 	}
 
 	if err := cmd.Wait(); err != nil {
+		if quota.exceeded.Load() {
+			return r.rejectOverQuota(result, cfg.TaskID, workDir)
+		}
 		if ctx.Err() == context.DeadlineExceeded {
+			r.kill(name)
 			result.Error = "timeout exceeded"
 			return result, fmt.Errorf("timeout exceeded")
 		}
@@ -213,8 +266,12 @@ func (r *DockerRunner) RunJob(ctx context.Context, cfg JobConfig) (*Result, erro
 		return result, fmt.Errorf("container exit: %w", err)
 	}
 
+	if r.overQuota(workDir) {
+		return r.rejectOverQuota(result, cfg.TaskID, workDir)
+	}
+
 	// sanitize output
-	sanitized, warnings := Sanitize(output.String())
+	sanitized, warnings := Sanitize(text)
 	result.Output = sanitized
 	result.Warnings = warnings
 	result.Sanitized = len(warnings) > 0
@@ -232,6 +289,22 @@ func (r *DockerRunner) RunJob(ctx context.Context, cfg JobConfig) (*Result, erro
 	return result, nil
 }
 
+// commandFor builds a command that runs a shell script in a fresh container
+// with the task's workspace mounted, bypassing the coder entrypoint. No API keys
+// are passed in.
+func (r *DockerRunner) commandFor(ctx context.Context, taskID, script string) *exec.Cmd {
+	hostWorkDir := filepath.Join(r.hostArtifactDir, taskID)
+	args := append(r.runtime.RunArgs(), r.limits.args()...)
+	args = append(args,
+		"--entrypoint", "/bin/bash",
+		"-v", fmt.Sprintf("%s:/workspace", hostWorkDir),
+		"-w", "/workspace",
+		"-e", "CI=true",
+		r.image, "-c", script,
+	)
+	return r.runtime.Command(ctx, args...)
+}
+
 // RunJobWithProgress runs with progress callbacks
 func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, onProgress func(StreamEvent)) (*Result, error) {
 	start := time.Now()
@@ -242,10 +315,8 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 		return nil, fmt.Errorf("create workspace: %w", err)
 	}
 
-	// ensure coder user (UID 1000) can write to workspace
-	if err := os.Chown(workDir, 1000, 1000); err != nil {
-		logger.Warn("could not chown workspace to coder user", "error", err)
-	}
+	// ensure coder user can write to workspace
+	r.runtime.PrepareWorkspace(workDir)
 
 	// write context file
 	if cfg.Context != nil {
@@ -259,15 +330,16 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 	// translate container path to host path for volume mount
 	hostWorkDir := filepath.Join(r.hostArtifactDir, cfg.TaskID)
 
-	// build docker run command with stream-json output
-	args := []string{
-		"run", "--rm",
-		"--network", "sheldon-net",
-		"--add-host", "host.docker.internal:host-gateway", // for host ollama access
+	// build container run command with stream-json output
+	name := containerName(cfg.TaskID)
+	args := append(r.runtime.RunArgs(), r.runtime.HostGatewayArgs()...)
+	args = append(args, r.limits.args()...)
+	args = append(args,
+		"--name", name,
 		"-v", fmt.Sprintf("%s:/workspace", hostWorkDir),
 		"-w", "/workspace",
 		"-e", "OLLAMA_HOST="+os.Getenv("OLLAMA_HOST"), // inherit from parent
-	}
+	)
 
 	// pass API key for the configured provider
 	envKey := config.EnvKeyForProvider(r.provider)
@@ -299,7 +371,15 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 		"-p", cfg.Prompt,
 	)
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	// stop the job if the workspace outgrows its quota
+	jobCtx, stopJob := context.WithCancel(ctx)
+	defer stopJob()
+	quota := r.watchQuota(jobCtx, workDir, func() {
+		r.kill(name)
+		stopJob()
+	})
+
+	cmd := r.runtime.Command(jobCtx, args...)
 
 	var output strings.Builder
 
@@ -326,6 +406,7 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 	}()
 
 	// process streaming json
+	var usage Usage
 	scanner := bufio.NewScanner(stdout)
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
 
@@ -362,6 +443,7 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 			}
 
 		case "result":
+			usage, _ = parseResultEvent(line)
 			if onProgress != nil {
 				onProgress(StreamEvent{Type: "complete"})
 			}
@@ -371,10 +453,15 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 	result := &Result{
 		Duration:      time.Since(start),
 		WorkspacePath: workDir,
+		Usage:         usage,
 	}
 
 	if err := cmd.Wait(); err != nil {
+		if quota.exceeded.Load() {
+			return r.rejectOverQuota(result, cfg.TaskID, workDir)
+		}
 		if ctx.Err() == context.DeadlineExceeded {
+			r.kill(name)
 			result.Error = "timeout exceeded"
 			return result, fmt.Errorf("timeout exceeded")
 		}
@@ -382,6 +469,10 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 		return result, fmt.Errorf("container exit: %w", err)
 	}
 
+	if r.overQuota(workDir) {
+		return r.rejectOverQuota(result, cfg.TaskID, workDir)
+	}
+
 	sanitized, warnings := Sanitize(output.String())
 	result.Output = sanitized
 	result.Warnings = warnings
@@ -393,6 +484,11 @@ func (r *DockerRunner) RunJobWithProgress(ctx context.Context, cfg JobConfig, on
 	return result, nil
 }
 
+// containerName gives each job a unique name so it can be killed if the CLI is interrupted
+func containerName(taskID string) string {
+	return fmt.Sprintf("sheldon-coder-%s-%d", taskID, time.Now().UnixNano())
+}
+
 // CleanupArtifacts removes artifacts for a task
 func (r *DockerRunner) CleanupArtifacts(taskID string) error {
 	return os.RemoveAll(filepath.Join(r.artifactsDir, taskID))
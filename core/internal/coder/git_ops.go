@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/bowerhall/sheldon/internal/logger"
@@ -324,9 +325,199 @@ func (g *GitOps) GetDiff(ctx context.Context, workspacePath string) (string, err
 	return string(output), nil
 }
 
+// emptyTree is git's well-known hash of an empty tree, the base for repos with no history
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// maxReviewStat and maxReviewPatch keep the diff sent for review within a chat message
+const (
+	maxReviewStat  = 1000
+	maxReviewPatch = 2500
+)
+
+// DiffSummary stages everything in the workspace and summarizes it against what
+// the branch was built on: the pushed branch, the remote default branch, or nothing.
+// Local commits made by the coder are included.
+func (g *GitOps) DiffSummary(ctx context.Context, workspacePath, branchName string) (*DiffSummary, error) {
+	if _, err := g.git(ctx, workspacePath, "add", "-A"); err != nil {
+		return nil, fmt.Errorf("git add: %w", err)
+	}
+
+	base := emptyTree
+	for _, ref := range []string{"origin/" + branchName, "origin/HEAD"} {
+		if output, err := g.git(ctx, workspacePath, "rev-parse", "--verify", "-q", ref); err == nil {
+			base = strings.TrimSpace(output)
+			break
+		}
+	}
+
+	numstat, err := g.git(ctx, workspacePath, "diff", "--cached", "--numstat", base)
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %w", err)
+	}
+
+	summary := &DiffSummary{}
+	for _, line := range strings.Split(strings.TrimSpace(numstat), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		summary.FilesChanged++
+		// binary files show "-" for both counts
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		summary.Additions += added
+		summary.Deletions += deleted
+	}
+
+	if summary.FilesChanged == 0 {
+		return summary, nil
+	}
+
+	stat, _ := g.git(ctx, workspacePath, "diff", "--cached", "--stat=72", base)
+	summary.Stat = truncateDiff(strings.TrimRight(stat, "\n"), maxReviewStat)
+
+	patch, _ := g.git(ctx, workspacePath, "diff", "--cached", base)
+	summary.Patch = truncateDiff(strings.TrimRight(patch, "\n"), maxReviewPatch)
+
+	return summary, nil
+}
+
+// truncateDiff cuts s at the last full line within max bytes
+func truncateDiff(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	if i := strings.LastIndex(s, "\n"); i > 0 {
+		s = s[:i]
+	}
+	return s + "\n... (truncated)"
+}
+
+// git runs a git command in the workspace and returns its stdout; errors carry stderr
+func (g *GitOps) git(ctx context.Context, workspacePath string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = workspacePath
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+	}
+	return string(output), err
+}
+
 // IsGitRepo checks if the path is a git repository
 func IsGitRepo(path string) bool {
 	gitDir := filepath.Join(path, ".git")
 	info, err := os.Stat(gitDir)
 	return err == nil && info.IsDir()
 }
+
+// RemoteHead returns the commit a branch of an org repo points to, without cloning it
+func (g *GitOps) RemoteHead(ctx context.Context, repoName, branch string) (string, error) {
+	output, err := g.remoteGit(ctx, "", repoName, "ls-remote", "{url}", "refs/heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("git ls-remote: %w", err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("branch %s not found in %s", branch, repoName)
+	}
+	return fields[0], nil
+}
+
+// DefaultBranch returns the branch the remote HEAD of an org repo points to
+func (g *GitOps) DefaultBranch(ctx context.Context, repoName string) (string, error) {
+	output, err := g.remoteGit(ctx, "", repoName, "ls-remote", "--symref", "{url}", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("git ls-remote: %w", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if ref, ok := strings.CutPrefix(line, "ref: refs/heads/"); ok {
+			return strings.Fields(ref)[0], nil
+		}
+	}
+	return "", fmt.Errorf("no default branch found for %s", repoName)
+}
+
+// SyncBranch makes dir a clean checkout of the branch's latest commit, cloning on first use
+func (g *GitOps) SyncBranch(ctx context.Context, repoName, branch, dir string) error {
+	if !IsGitRepo(dir) {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return fmt.Errorf("create checkout dir: %w", err)
+		}
+		if _, err := g.remoteGit(ctx, "", repoName, "clone", "--depth", "50", "--branch", branch, "{url}", dir); err != nil {
+			return fmt.Errorf("git clone: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := g.remoteGit(ctx, dir, repoName, "fetch", "--depth", "50", "{url}", branch); err != nil {
+		return fmt.Errorf("git fetch: %w", err)
+	}
+	if _, err := g.git(ctx, dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+		return fmt.Errorf("git reset: %w", err)
+	}
+	if _, err := g.git(ctx, dir, "clean", "-fd"); err != nil {
+		return fmt.Errorf("git clean: %w", err)
+	}
+	return nil
+}
+
+// CommitLog lists one-line summaries of the commits after from up to to, newest first.
+// When from is empty or not in the local history only the latest max commits are listed.
+func (g *GitOps) CommitLog(ctx context.Context, dir, from, to string, max int) []string {
+	revs := to
+	if from != "" {
+		if _, err := g.git(ctx, dir, "cat-file", "-e", from+"^{commit}"); err == nil {
+			revs = from + ".." + to
+		}
+	}
+
+	output, err := g.git(ctx, dir, "log", "--oneline", "--no-decorate", "-n", strconv.Itoa(max), revs)
+	if err != nil {
+		logger.Debug("git log failed", "dir", dir, "error", err)
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// remoteGit runs a git command against an org repo with credentials supplied through
+// the environment; the {url} argument is replaced with the repo's clone URL
+func (g *GitOps) remoteGit(ctx context.Context, dir, repoName string, args ...string) (string, error) {
+	if g.token == "" || g.orgURL == "" {
+		return "", fmt.Errorf("git not configured (missing token or org URL)")
+	}
+
+	cloneURL, err := g.buildCloneURL(repoName)
+	if err != nil {
+		return "", fmt.Errorf("build clone URL: %w", err)
+	}
+
+	resolved := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "{url}" {
+			arg = cloneURL
+		}
+		resolved[i] = arg
+	}
+
+	cmd := exec.CommandContext(ctx, "git", resolved...)
+	cmd.Dir = dir
+	cmd.Env = g.gitEnvWithAuth()
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+	}
+	return string(output), err
+}
@@ -0,0 +1,71 @@
+package coder
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffSummaryNewRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	g := NewGitOps(GitConfig{})
+	ctx := context.Background()
+
+	if _, err := g.git(ctx, dir, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# app\n"), 0644)
+
+	diff, err := g.DiffSummary(ctx, dir, "sheldon/task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff.FilesChanged != 2 || diff.Additions != 4 || diff.Deletions != 0 {
+		t.Errorf("got %d files +%d -%d, want 2 files +4 -0", diff.FilesChanged, diff.Additions, diff.Deletions)
+	}
+	if diff.Lines() != 4 {
+		t.Errorf("Lines() = %d, want 4", diff.Lines())
+	}
+	if !strings.Contains(diff.Stat, "main.go") || !strings.Contains(diff.Patch, "+func main() {}") {
+		t.Errorf("stat/patch missing changes:\n%s\n%s", diff.Stat, diff.Patch)
+	}
+}
+
+func TestDiffSummaryNoChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	g := NewGitOps(GitConfig{})
+
+	if _, err := g.git(context.Background(), dir, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := g.DiffSummary(context.Background(), dir, "sheldon/task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.FilesChanged != 0 || diff.Stat != "" || diff.Patch != "" {
+		t.Errorf("expected empty diff, got %+v", diff)
+	}
+}
+
+func TestTruncateDiff(t *testing.T) {
+	if got := truncateDiff("short", 10); got != "short" {
+		t.Errorf("got %q", got)
+	}
+	if got := truncateDiff("line one\nline two\nline three", 20); got != "line one\nline two\n... (truncated)" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package coder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// quotaCheckInterval is how often a running job's workspace size is measured
+const quotaCheckInterval = 15 * time.Second
+
+// quotaWatch tracks whether a running job outgrew its workspace quota
+type quotaWatch struct {
+	exceeded atomic.Bool
+}
+
+// watchQuota measures workDir until ctx ends and calls stop once it exceeds the quota
+func (r *DockerRunner) watchQuota(ctx context.Context, workDir string, stop func()) *quotaWatch {
+	w := &quotaWatch{}
+	if r.workspaceQuota <= 0 {
+		return w
+	}
+
+	go func() {
+		ticker := time.NewTicker(quotaCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if r.overQuota(workDir) {
+					w.exceeded.Store(true)
+					stop()
+					return
+				}
+			}
+		}
+	}()
+
+	return w
+}
+
+func (r *DockerRunner) overQuota(workDir string) bool {
+	if r.workspaceQuota <= 0 {
+		return false
+	}
+	size, err := dirSize(workDir)
+	return err == nil && size > r.workspaceQuota
+}
+
+// rejectOverQuota removes a workspace that outgrew its quota so it can't fill the disk
+func (r *DockerRunner) rejectOverQuota(result *Result, taskID, workDir string) (*Result, error) {
+	logger.Warn("coder workspace exceeded quota", "task", taskID, "quota", formatBytes(r.workspaceQuota))
+
+	if err := os.RemoveAll(workDir); err != nil {
+		logger.Warn("could not remove over-quota workspace", "task", taskID, "error", err)
+	}
+
+	result.Error = fmt.Sprintf("workspace exceeded the %s quota and was removed", formatBytes(r.workspaceQuota))
+	result.Files = nil
+	return result, errors.New(result.Error)
+}
+
+// kill stops a named container; killing the CLI alone can leave it running
+func (r *DockerRunner) kill(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if output, err := r.runtime.Command(ctx, "kill", name).CombinedOutput(); err != nil {
+		logger.Debug("kill coder container", "name", name, "error", err, "output", string(output))
+	}
+}
+
+// dirSize sums the size of regular files under dir
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+func formatBytes(n int64) string {
+	const mb = 1024 * 1024
+	if n >= 1024*mb {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1024*mb))
+	}
+	return fmt.Sprintf("%d MB", n/mb)
+}
@@ -0,0 +1,48 @@
+package coder
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResourceLimitArgs(t *testing.T) {
+	args := ResourceLimits{CPUs: "1.5", Memory: "2g", Pids: 256}.args()
+	want := []string{"--cpus", "1.5", "--memory", "2g", "--memory-swap", "2g", "--pids-limit", "256"}
+	if !slices.Equal(args, want) {
+		t.Errorf("expected %v, got %v", want, args)
+	}
+
+	if args := (ResourceLimits{CPUs: "0", Memory: "0"}).args(); len(args) != 0 {
+		t.Errorf("expected zero limits to be dropped, got %v", args)
+	}
+}
+
+func TestWorkspaceQuota(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "node_modules"), 0755)
+	os.WriteFile(filepath.Join(dir, "node_modules", "big.js"), make([]byte, 2048), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), make([]byte, 100), 0644)
+
+	if size, _ := dirSize(dir); size != 2148 {
+		t.Errorf("expected 2148 bytes, got %d", size)
+	}
+
+	r := &DockerRunner{workspaceQuota: 1024}
+	if !r.overQuota(dir) {
+		t.Error("expected workspace over quota")
+	}
+
+	result, err := r.rejectOverQuota(&Result{Files: []string{"main.go"}}, "t1", dir)
+	if err == nil || result.Error == "" || result.Files != nil {
+		t.Errorf("expected over-quota task to fail, got %+v", result)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("expected workspace to be removed")
+	}
+
+	if (&DockerRunner{}).overQuota(t.TempDir()) {
+		t.Error("expected no quota to mean unlimited")
+	}
+}
@@ -0,0 +1,179 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/isolation"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// Runtime wraps the container CLI (docker or podman) used to launch coder containers
+type Runtime struct {
+	binary   string
+	network  string
+	uid      int
+	gid      int
+	rootless bool
+	harden   isolation.Options
+}
+
+// RuntimeConfig holds configuration for Runtime
+type RuntimeConfig struct {
+	Binary   string // docker or podman (default: whichever is installed, docker first)
+	Network  string // container network (default: sheldon-net)
+	UID      int    // uid of the coder user inside the image (default: 1000)
+	GID      int    // gid of the coder user inside the image (default: 1000)
+	Rootless *bool  // nil detects it from the runtime
+	Harden   isolation.Options
+}
+
+// NewRuntime resolves the container CLI and whether it runs rootless
+func NewRuntime(cfg RuntimeConfig) *Runtime {
+	if cfg.Binary == "" {
+		cfg.Binary = detectBinary()
+	}
+	if cfg.Network == "" {
+		cfg.Network = "sheldon-net"
+	}
+	if cfg.UID <= 0 {
+		cfg.UID = 1000
+	}
+	if cfg.GID <= 0 {
+		cfg.GID = 1000
+	}
+
+	r := &Runtime{
+		binary:  cfg.Binary,
+		network: cfg.Network,
+		uid:     cfg.UID,
+		gid:     cfg.GID,
+		harden:  cfg.Harden,
+	}
+
+	if cfg.Rootless != nil {
+		r.rootless = *cfg.Rootless
+	} else {
+		r.rootless = r.detectRootless()
+	}
+
+	return r
+}
+
+// Name returns the container CLI in use
+func (r *Runtime) Name() string {
+	return r.binary
+}
+
+// Rootless reports whether containers run without root on the host
+func (r *Runtime) Rootless() bool {
+	return r.rootless
+}
+
+// Command builds a container CLI invocation
+func (r *Runtime) Command(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, r.binary, args...)
+}
+
+// RunArgs returns the leading `run` arguments shared by every coder container:
+// network, hardening flags and whatever user namespace mapping the runtime needs.
+func (r *Runtime) RunArgs() []string {
+	args := []string{"run", "--rm", "--network", r.network}
+	args = append(args, r.harden.Args()...)
+
+	if r.isPodman() && r.rootless {
+		// map the host user onto the image's coder user so workspace files stay owned by us
+		args = append(args, fmt.Sprintf("--userns=keep-id:uid=%d,gid=%d", r.uid, r.gid))
+	}
+
+	return args
+}
+
+// HostGatewayArgs exposes the host as host.docker.internal (for host ollama access).
+// Podman already provides host.containers.internal, so nothing is added there.
+func (r *Runtime) HostGatewayArgs() []string {
+	if r.isPodman() {
+		return nil
+	}
+	return []string{"--add-host", "host.docker.internal:host-gateway"}
+}
+
+// PrepareWorkspace makes the workspace writable by the coder user inside the container
+func (r *Runtime) PrepareWorkspace(dir string) {
+	switch {
+	case !r.rootless:
+		if err := os.Chown(dir, r.uid, r.gid); err != nil {
+			logger.Warn("could not chown workspace to coder user", "error", err, "uid", r.uid)
+		}
+	case r.isPodman():
+		// keep-id maps us to the coder user, nothing to do
+	default:
+		// rootless docker maps the coder user to a subordinate uid we can't chown to
+		if err := openPermissions(dir); err != nil {
+			logger.Warn("could not open workspace to coder user", "error", err)
+		}
+	}
+}
+
+// openPermissions makes everything under dir writable by any user.
+// Files created by an earlier container already belong to the coder user, so failures there are skipped.
+func openPermissions(dir string) error {
+	if err := os.Chmod(dir, 0777); err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		mode := info.Mode().Perm() | 0666
+		if d.IsDir() {
+			mode |= 0111
+		}
+		os.Chmod(path, mode)
+		return nil
+	})
+}
+
+func (r *Runtime) isPodman() bool {
+	return strings.Contains(r.binary, "podman")
+}
+
+func (r *Runtime) detectRootless() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if r.isPodman() {
+		out, err := r.Command(ctx, "info", "--format", "{{.Host.Security.Rootless}}").Output()
+		if err != nil {
+			return os.Geteuid() != 0
+		}
+		return strings.TrimSpace(string(out)) == "true"
+	}
+
+	out, err := r.Command(ctx, "info", "--format", "{{.SecurityOptions}}").Output()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(out), "rootless")
+}
+
+func detectBinary() string {
+	if _, err := exec.LookPath("docker"); err == nil {
+		return "docker"
+	}
+	if _, err := exec.LookPath("podman"); err == nil {
+		return "podman"
+	}
+	return "docker"
+}
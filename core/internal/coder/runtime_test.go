@@ -0,0 +1,53 @@
+package coder
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRuntimeRunArgs(t *testing.T) {
+	rootless := true
+	podman := NewRuntime(RuntimeConfig{Binary: "podman", Network: "coder-net", UID: 1001, GID: 1001, Rootless: &rootless})
+
+	args := podman.RunArgs()
+	if !slices.Contains(args, "coder-net") {
+		t.Errorf("expected configured network, got %v", args)
+	}
+	if !slices.Contains(args, "--userns=keep-id:uid=1001,gid=1001") {
+		t.Errorf("expected keep-id user mapping for rootless podman, got %v", args)
+	}
+	if len(podman.HostGatewayArgs()) != 0 {
+		t.Error("expected no host-gateway mapping for podman")
+	}
+
+	rootful := false
+	docker := NewRuntime(RuntimeConfig{Binary: "docker", Rootless: &rootful})
+	if !slices.Contains(docker.RunArgs(), "sheldon-net") {
+		t.Errorf("expected default network, got %v", docker.RunArgs())
+	}
+	if len(docker.HostGatewayArgs()) == 0 {
+		t.Error("expected host-gateway mapping for docker")
+	}
+}
+
+func TestOpenPermissions(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "sub", "main.go")
+	os.MkdirAll(filepath.Dir(file), 0755)
+	os.WriteFile(file, []byte("package main"), 0644)
+
+	if err := openPermissions(dir); err != nil {
+		t.Fatalf("open permissions: %v", err)
+	}
+
+	info, _ := os.Stat(file)
+	if info.Mode().Perm() != 0666 {
+		t.Errorf("expected file to be world-writable, got %v", info.Mode().Perm())
+	}
+	info, _ = os.Stat(filepath.Dir(file))
+	if info.Mode().Perm() != 0777 {
+		t.Errorf("expected dir to be world-writable, got %v", info.Mode().Perm())
+	}
+}
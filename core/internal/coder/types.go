@@ -1,6 +1,9 @@
 package coder
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type Complexity string
 
@@ -26,6 +29,7 @@ type Task struct {
 	Context     *MemoryContext
 	SystemHints string
 	GitRepo     string // target repo name (e.g., "weather-bot")
+	Continue    bool   // follow-up edit in the existing workspace of task ID
 }
 
 type MemoryContext struct {
@@ -43,15 +47,75 @@ type Result struct {
 	Output        string
 	Files         []string
 	WorkspacePath string
-	TurnsUsed     int
+	Usage         Usage   // summed over the initial run and any fix runs
+	CostUSD       float64 // estimated from Usage and the coder model's pricing
 	Duration      time.Duration
 	Warnings      []string
 	Sanitized     bool
 	Error         string
 	// Git status
-	GitPushed bool   // true if changes were pushed
-	GitBranch string // branch name if pushed
-	GitError  string // error message if push failed
+	GitPushed   bool         // true if changes were pushed
+	GitBranch   string       // branch name if pushed
+	GitError    string       // error message if push failed
+	GitDeclined bool         // user rejected the push; changes stay in the workspace
+	Diff        *DiffSummary // changes reviewed before pushing
+	// test/vet/lint results, nil if verification didn't run
+	Verification *Verification
+}
+
+// DiffSummary describes what a push would change relative to the branch's base
+type DiffSummary struct {
+	FilesChanged int
+	Additions    int
+	Deletions    int
+	Stat         string // git diff --stat
+	Patch        string // unified diff, truncated for chat
+}
+
+// Lines is the number of added plus deleted lines
+func (d *DiffSummary) Lines() int {
+	return d.Additions + d.Deletions
+}
+
+// PushReview is a request to approve pushing a task's changes
+type PushReview struct {
+	TaskID string
+	Repo   string
+	Branch string
+	Diff   *DiffSummary
+}
+
+// PushReviewer asks the user to approve a push and reports their answer
+type PushReviewer func(ctx context.Context, review PushReview) (bool, error)
+
+// Usage is what the coder CLI reports for a run
+type Usage struct {
+	Turns      int
+	Input      int // uncached input tokens
+	Output     int
+	CacheWrite int
+	CacheRead  int
+}
+
+// Check is a verification command detected from the workspace, e.g. go test
+type Check struct {
+	Name    string
+	Command string // run with bash -c in the workspace
+	Tool    string // binary that must exist, otherwise the check is skipped
+}
+
+// CheckResult is the outcome of one check
+type CheckResult struct {
+	Check
+	Passed  bool
+	Skipped bool   // tool not installed in the sandbox
+	Output  string // tail of combined output, kept for failures
+}
+
+// Verification is the final state of the checks after any fix attempts
+type Verification struct {
+	Checks      []CheckResult
+	FixAttempts int // coder re-runs made to fix failures
 }
 
 type StreamEvent struct {
@@ -0,0 +1,108 @@
+package coder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/budget"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// cliResult is the final `result` event of claude's json and stream-json output
+type cliResult struct {
+	Type     string `json:"type"`
+	Result   string `json:"result"`
+	NumTurns int    `json:"num_turns"`
+	Usage    struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+}
+
+func (r cliResult) usage() Usage {
+	return Usage{
+		Turns:      r.NumTurns,
+		Input:      r.Usage.InputTokens,
+		Output:     r.Usage.OutputTokens,
+		CacheWrite: r.Usage.CacheCreationInputTokens,
+		CacheRead:  r.Usage.CacheReadInputTokens,
+	}
+}
+
+// parseResultEvent reads usage from a stream-json line, reporting false for other event types
+func parseResultEvent(line string) (Usage, bool) {
+	var r cliResult
+	if err := json.Unmarshal([]byte(line), &r); err != nil || r.Type != "result" {
+		return Usage{}, false
+	}
+	return r.usage(), true
+}
+
+// parseJSONOutput splits `--output-format json` output into the coder's reply and its usage.
+// Anything that isn't a result object is returned unchanged.
+func parseJSONOutput(raw string) (string, Usage) {
+	var r cliResult
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &r); err != nil || r.Type != "result" {
+		return raw, Usage{}
+	}
+	return r.Result, r.usage()
+}
+
+// Add sums another run's usage into u
+func (u *Usage) Add(other Usage) {
+	u.Turns += other.Turns
+	u.Input += other.Input
+	u.Output += other.Output
+	u.CacheWrite += other.CacheWrite
+	u.CacheRead += other.CacheRead
+}
+
+// Tokens is the total across input, output and cache
+func (u Usage) Tokens() int {
+	return u.Input + u.Output + u.CacheWrite + u.CacheRead
+}
+
+// SetBudget counts coder runs against the daily token budget
+func (b *Bridge) SetBudget(tracker *budget.Tracker) {
+	b.budget = tracker
+}
+
+// checkBudget refuses to start a coder run once the daily budget is spent
+func (b *Bridge) checkBudget() error {
+	if b.budget == nil {
+		return nil
+	}
+	if used, limit := b.budget.Usage(); used >= limit {
+		return fmt.Errorf("daily token budget exhausted (%d/%d), try again tomorrow", used, limit)
+	}
+	return nil
+}
+
+// charge prices one run's usage, adds it to the result's cost and records it against the budget
+func (b *Bridge) charge(result *Result, taskID string, u Usage) {
+	if u.Tokens() == 0 {
+		return
+	}
+
+	provider, model := b.coderModel()
+	tokens := budget.Tokens{Input: u.Input, Output: u.Output, CacheWrite: u.CacheWrite, CacheRead: u.CacheRead}
+	cost := budget.Cost(provider, model, tokens)
+	result.CostUSD += cost
+
+	logger.Info("coder usage", "task", taskID, "provider", provider, "model", model, "turns", u.Turns, "tokens", u.Tokens(), "costUSD", fmt.Sprintf("%.4f", cost))
+
+	if b.budget != nil {
+		b.budget.RecordTokens(provider, model, tokens)
+	}
+}
+
+// coderModel is the provider and model the coder CLI runs with
+func (b *Bridge) coderModel() (string, string) {
+	if b.useIsolated && b.dockerRunner != nil {
+		return b.dockerRunner.provider, b.dockerRunner.model
+	}
+	return b.sandbox.provider, b.sandbox.model
+}
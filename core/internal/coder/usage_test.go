@@ -0,0 +1,37 @@
+package coder
+
+import "testing"
+
+const sampleResult = `{"type":"result","subtype":"success","num_turns":4,"result":"Built the app.","usage":{"input_tokens":1200,"output_tokens":800,"cache_creation_input_tokens":300,"cache_read_input_tokens":5000}}`
+
+func TestParseJSONOutput(t *testing.T) {
+	text, usage := parseJSONOutput(sampleResult + "\n")
+	if text != "Built the app." {
+		t.Errorf("expected result text, got %q", text)
+	}
+	want := Usage{Turns: 4, Input: 1200, Output: 800, CacheWrite: 300, CacheRead: 5000}
+	if usage != want {
+		t.Errorf("expected %+v, got %+v", want, usage)
+	}
+
+	if text, usage := parseJSONOutput("plain text output"); text != "plain text output" || usage.Tokens() != 0 {
+		t.Errorf("expected non-json output unchanged, got %q %+v", text, usage)
+	}
+}
+
+func TestParseResultEvent(t *testing.T) {
+	if _, ok := parseResultEvent(`{"type":"assistant","message":{}}`); ok {
+		t.Error("expected assistant event to be ignored")
+	}
+
+	usage, ok := parseResultEvent(sampleResult)
+	if !ok || usage.Tokens() != 7300 {
+		t.Errorf("expected 7300 tokens, got %+v", usage)
+	}
+
+	total := usage
+	total.Add(usage)
+	if total.Turns != 8 || total.Tokens() != 14600 {
+		t.Errorf("expected usage to sum, got %+v", total)
+	}
+}
@@ -0,0 +1,245 @@
+package coder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const (
+	checkTimeout   = 5 * time.Minute
+	maxCheckOutput = 4000
+)
+
+// Passed reports whether every check that ran succeeded
+func (v *Verification) Passed() bool {
+	for _, c := range v.Checks {
+		if !c.Passed && !c.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the checks that ran and failed
+func (v *Verification) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, c := range v.Checks {
+		if !c.Passed && !c.Skipped {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// DetectChecks looks at the project files in dir and returns the test, vet and
+// lint commands that apply. Projects in subdirectories one level down are
+// picked up too, since the coder often creates the project in its own folder.
+func DetectChecks(dir string) []Check {
+	checks := detectChecksIn(dir, ".")
+	if len(checks) > 0 {
+		return checks
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") || e.Name() == "node_modules" {
+			continue
+		}
+		checks = append(checks, detectChecksIn(filepath.Join(dir, e.Name()), e.Name())...)
+	}
+	return checks
+}
+
+func detectChecksIn(dir, rel string) []Check {
+	var checks []Check
+	prefix := ""
+	if rel != "." {
+		prefix = fmt.Sprintf("cd %q && ", rel)
+	}
+	add := func(name, tool, command string) {
+		if rel != "." {
+			name = rel + ": " + name
+		}
+		checks = append(checks, Check{Name: name, Command: prefix + command, Tool: tool})
+	}
+
+	if fileExists(dir, "go.mod") {
+		add("go vet", "go", "go vet ./...")
+		add("go test", "go", "go test ./...")
+	}
+
+	if scripts := packageScripts(dir); scripts != nil {
+		install := "(npm ci --no-audit --no-fund || npm install --no-audit --no-fund) >/dev/null && "
+		if test, ok := scripts["test"]; ok && !strings.Contains(test, "no test specified") {
+			add("npm test", "npm", install+"npm test")
+			install = ""
+		}
+		if _, ok := scripts["lint"]; ok {
+			add("npm run lint", "npm", install+"npm run lint")
+		}
+	}
+
+	if fileExists(dir, "Cargo.toml") {
+		add("cargo test", "cargo", "cargo test")
+	}
+
+	if fileExists(dir, "pyproject.toml") || fileExists(dir, "pytest.ini") || fileExists(dir, "setup.py") || hasPythonTests(dir) {
+		add("pytest", "pytest", "python3 -m pytest -q")
+	}
+
+	if len(checks) == 0 && hasMakeTarget(dir, "test") {
+		add("make test", "make", "make test")
+	}
+
+	return checks
+}
+
+// runVerification runs the workspace's checks and, while any fail, asks the coder to fix
+// them up to fixAttempts times. rerun runs the coder again with a prompt in the
+// same workspace.
+func (b *Bridge) runVerification(ctx context.Context, taskID, prompt, workDir string, rerun func(ctx context.Context, prompt string) error) *Verification {
+	checks := DetectChecks(workDir)
+	if !b.verify || len(checks) == 0 {
+		return nil
+	}
+
+	v := &Verification{Checks: b.runChecks(ctx, taskID, workDir, checks)}
+	for !v.Passed() && v.FixAttempts < b.fixAttempts && ctx.Err() == nil {
+		v.FixAttempts++
+		logger.Info("coder checks failed, asking for a fix", "task", taskID, "attempt", v.FixAttempts, "failed", len(v.Failed()))
+
+		if err := rerun(ctx, fixPrompt(prompt, v.Failed())); err != nil {
+			logger.Warn("coder fix attempt failed", "task", taskID, "error", err)
+			break
+		}
+		v.Checks = b.runChecks(ctx, taskID, workDir, checks)
+	}
+
+	logger.Debug("coder verification complete", "task", taskID, "passed", v.Passed(), "attempts", v.FixAttempts)
+	return v
+}
+
+func (b *Bridge) runChecks(ctx context.Context, taskID, workDir string, checks []Check) []CheckResult {
+	results := make([]CheckResult, 0, len(checks))
+	for _, c := range checks {
+		results = append(results, b.runCheck(ctx, taskID, workDir, c))
+	}
+	return results
+}
+
+// runCheck runs a check in the same sandbox the coder used: an ephemeral
+// container in isolated mode, otherwise a subprocess with a clean environment.
+// Neither gets API keys or git credentials.
+func (b *Bridge) runCheck(ctx context.Context, taskID, workDir string, c Check) CheckResult {
+	result := CheckResult{Check: c}
+
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	script := fmt.Sprintf("if ! command -v %s >/dev/null 2>&1; then exit 127; fi\n%s", c.Tool, c.Command)
+
+	var cmd *exec.Cmd
+	if b.useIsolated && b.dockerRunner != nil {
+		cmd = b.dockerRunner.commandFor(checkCtx, taskID, script)
+	} else {
+		cmd = exec.CommandContext(checkCtx, "bash", "-c", script)
+		cmd.Dir = workDir
+		cmd.Env = checkEnv()
+	}
+
+	output, err := cmd.CombinedOutput()
+	switch {
+	case err == nil:
+		result.Passed = true
+	case cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 127 && len(strings.TrimSpace(string(output))) == 0:
+		result.Skipped = true
+	case checkCtx.Err() == context.DeadlineExceeded:
+		result.Output = fmt.Sprintf("timed out after %s\n%s", checkTimeout, tail(string(output), maxCheckOutput))
+	default:
+		result.Output = tail(string(output), maxCheckOutput)
+	}
+
+	return result
+}
+
+// checkEnv is the environment for checks run as a subprocess
+func checkEnv() []string {
+	return []string{
+		"HOME=/tmp",
+		"PATH=/usr/local/go/bin:/usr/local/bin:/usr/bin:/bin",
+		"LANG=en_US.UTF-8",
+		"TERM=dumb",
+		"CI=true",
+	}
+}
+
+func fixPrompt(original string, failed []CheckResult) string {
+	var sb strings.Builder
+	sb.WriteString("You already worked on this task in the current workspace:\n\n")
+	sb.WriteString(original)
+	sb.WriteString("\n\n## Failing checks\n")
+	sb.WriteString("These checks fail on your changes. Fix the code (not the checks) so they pass, and keep the original task working.\n")
+	for _, c := range failed {
+		fmt.Fprintf(&sb, "\n### %s\n$ %s\n```\n%s\n```\n", c.Name, c.Command, strings.TrimSpace(c.Output))
+	}
+	return sb.String()
+}
+
+func fileExists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+func packageScripts(dir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || pkg.Scripts == nil {
+		return nil
+	}
+	return pkg.Scripts
+}
+
+func hasPythonTests(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "test_*.py"))
+	if len(matches) > 0 {
+		return true
+	}
+	matches, _ = filepath.Glob(filepath.Join(dir, "tests", "test_*.py"))
+	return len(matches) > 0
+}
+
+func hasMakeTarget(dir, target string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "Makefile"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, target+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
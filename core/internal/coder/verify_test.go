@@ -0,0 +1,79 @@
+package coder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkNames(checks []Check) []string {
+	names := make([]string, len(checks))
+	for i, c := range checks {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func TestDetectChecks(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":       "module example.com/app\n",
+		"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1", "lint": "eslint ."}}`,
+	})
+
+	got := checkNames(DetectChecks(dir))
+	want := []string{"go vet", "go test", "npm run lint"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestDetectChecksInSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"weather-bot/tests/test_api.py": "def test_ok():\n    pass\n",
+		"node_modules/pkg/go.mod":       "module ignored\n",
+		"README.md":                     "# weather bot\n",
+	})
+
+	checks := DetectChecks(dir)
+	if len(checks) != 1 || checks[0].Name != "weather-bot: pytest" {
+		t.Fatalf("expected pytest in weather-bot, got %v", checkNames(checks))
+	}
+	if checks[0].Command != `cd "weather-bot" && python3 -m pytest -q` {
+		t.Errorf("unexpected command %q", checks[0].Command)
+	}
+}
+
+func TestVerificationPassed(t *testing.T) {
+	v := &Verification{Checks: []CheckResult{
+		{Check: Check{Name: "go test"}, Passed: true},
+		{Check: Check{Name: "pytest"}, Skipped: true},
+	}}
+	if !v.Passed() {
+		t.Error("expected skipped checks not to fail verification")
+	}
+
+	v.Checks = append(v.Checks, CheckResult{Check: Check{Name: "npm test"}, Output: "1 failing"})
+	if v.Passed() || len(v.Failed()) != 1 {
+		t.Errorf("expected one failed check, got %+v", v.Failed())
+	}
+}
@@ -4,9 +4,15 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func Load() (*Config, error) {
+	if err := applyConfigFile(); err != nil {
+		return nil, err
+	}
+
 	essencePath := os.Getenv("SHELDON_ESSENCE")
 	if essencePath == "" {
 		essencePath = "essence"
@@ -28,6 +34,7 @@ func Load() (*Config, error) {
 	}
 
 	embedderConfig := loadEmbedderConfig()
+	speechConfig := loadSpeechConfig()
 
 	botConfig, err := loadBotConfig()
 	if err != nil {
@@ -35,20 +42,34 @@ func Load() (*Config, error) {
 	}
 
 	alertConfig := loadAlertConfig()
+	notifyConfig := loadNotifyConfig()
 	multiBot := loadMultiBotConfig()
 	budgetConfig := loadBudgetConfig()
+	backupConfig := loadBackupConfig()
+	sessionConfig := loadSessionConfig()
 	coderConfig := loadCoderConfig()
 	browserConfig := loadBrowserConfig()
 	pinchtabConfig := loadPinchtabConfig()
 	storageConfig := loadStorageConfig()
 	deployerConfig := loadDeployerConfig()
+	tracingConfig := loadTracingConfig()
+	adminConfig := loadAdminConfig()
+	digestConfig := loadDigestConfig()
+	calendarConfig := loadCalendarConfig()
+	inboxConfig := loadInboxConfig()
+	feedsConfig := loadFeedsConfig()
+	geoConfig := loadGeoConfig()
+	sandboxConfig := loadSandboxConfig()
+	agentConfig := loadHomelabAgentConfig()
 
 	return &Config{
 		EssencePath: essencePath,
 		MemoryPath:  memoryPath,
+		MemoryKey:   os.Getenv("MEMORY_ENCRYPTION_KEY"),
 		Timezone:    timezone,
 		LLM:         llmConfig,
 		Embedder:    embedderConfig,
+		Speech:      speechConfig,
 		Coder:       coderConfig,
 		Browser:     browserConfig,
 		Pinchtab:    pinchtabConfig,
@@ -57,10 +78,46 @@ func Load() (*Config, error) {
 		Bot:         botConfig,
 		Bots:        multiBot,
 		Alert:       alertConfig,
+		Notify:      notifyConfig,
 		Budget:      budgetConfig,
+		Backup:      backupConfig,
+		Sessions:    sessionConfig,
+		Tracing:     tracingConfig,
+		Admin:       adminConfig,
+		Digest:      digestConfig,
+		Calendar:    calendarConfig,
+		Inbox:       inboxConfig,
+		Feeds:       feedsConfig,
+		Geo:         geoConfig,
+		Sandbox:     sandboxConfig,
+		Agent:       agentConfig,
+		SSHHosts:    os.Getenv("SSH_HOSTS_FILE"),
+
+		SkillIndexes:  loadSkillIndexes(),
+		DisabledTools: loadDisabledTools(),
 	}, nil
 }
 
+func loadSkillIndexes() []string {
+	var urls []string
+	for _, url := range strings.Split(os.Getenv("SKILLS_INDEX_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+func loadDisabledTools() []string {
+	var names []string
+	for _, name := range strings.Split(os.Getenv("DISABLED_TOOLS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func loadDeployerConfig() DeployerConfig {
 	appsFile := os.Getenv("DEPLOYER_APPS_FILE")
 	if appsFile == "" {
@@ -81,12 +138,35 @@ func loadDeployerConfig() DeployerConfig {
 		network = "sheldon-net"
 	}
 
+	// where deploy_app runs apps: compose (default) or kubernetes
+	target := os.Getenv("DEPLOYER_TARGET")
+	switch target {
+	case "":
+		target = "compose"
+	case "k8s", "k3s":
+		target = "kubernetes"
+	}
+
+	// auto-update checks are a cheap ls-remote, but keep them to at most one a minute
+	updateInterval := 5 * time.Minute
+	if d, err := time.ParseDuration(os.Getenv("DEPLOYER_UPDATE_INTERVAL")); err == nil && d >= time.Minute {
+		updateInterval = d
+	}
+
 	return DeployerConfig{
 		AppsFile:     appsFile,
 		HostAppsFile: hostAppsFile,
 		PathPrefix:   pathPrefix,
 		HostPrefix:   hostPrefix,
 		Network:      network,
+		Target:       target,
+		Kubernetes: KubernetesConfig{
+			Namespace:    os.Getenv("K8S_NAMESPACE"),
+			Registry:     os.Getenv("K8S_REGISTRY"),
+			IngressClass: os.Getenv("K8S_INGRESS_CLASS"),
+			CertIssuer:   os.Getenv("K8S_CERT_ISSUER"),
+		},
+		UpdateInterval: updateInterval,
 	}
 }
 
@@ -115,7 +195,35 @@ func loadStorageConfig() StorageConfig {
 		SecretKey:      os.Getenv("STORAGE_SHELDON_PASSWORD"),
 		UseSSL:         os.Getenv("STORAGE_USE_SSL") == "true",
 		PublicUseSSL:   os.Getenv("STORAGE_PUBLIC_USE_SSL") == "true",
+		UserQuota:      parseByteSize(os.Getenv("STORAGE_USER_QUOTA")),
+		AgentQuota:     parseByteSize(os.Getenv("STORAGE_AGENT_QUOTA")),
+		UserPrune:      os.Getenv("STORAGE_USER_PRUNE") == "true",
+		AgentPrune:     os.Getenv("STORAGE_AGENT_PRUNE") != "false",
+	}
+}
+
+// parseByteSize reads sizes like 500MB, 5GB or 1.5G (powers of 1024, plain
+// numbers are bytes). Anything unparsable is 0, which means no limit.
+func parseByteSize(v string) int64 {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	v = strings.TrimSuffix(v, "B")
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40}} {
+		if n, ok := strings.CutSuffix(v, unit.suffix); ok {
+			v, multiplier = n, unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || n <= 0 {
+		return 0
 	}
+	return int64(n * float64(multiplier))
 }
 
 func loadCoderConfig() CoderConfig {
@@ -145,6 +253,45 @@ func loadCoderConfig() CoderConfig {
 		image = "ghcr.io/bowerhall/sheldon-coder-sandbox:latest"
 	}
 
+	// test/vet/lint the coder's output and feed failures back for a few fix rounds
+	verify := os.Getenv("CODER_VERIFY") != "false"
+	fixAttempts := 2
+	if n, err := strconv.Atoi(os.Getenv("CODER_FIX_ATTEMPTS")); err == nil && n >= 0 {
+		fixAttempts = n
+	}
+
+	// docker or podman, rootful or rootless; detected unless overridden
+	runtime := ContainerRuntimeConfig{
+		Binary:  os.Getenv("CODER_RUNTIME"),
+		Network: os.Getenv("CODER_NETWORK"),
+	}
+	runtime.UID, _ = strconv.Atoi(os.Getenv("CODER_UID"))
+	runtime.GID, _ = strconv.Atoi(os.Getenv("CODER_GID"))
+	if rootless, err := strconv.ParseBool(os.Getenv("CODER_ROOTLESS")); err == nil {
+		runtime.Rootless = &rootless
+	}
+
+	// resource caps for isolated coder containers; "0" lifts a limit
+	limits := CoderLimitsConfig{
+		CPUs:             os.Getenv("CODER_CPUS"),
+		Memory:           os.Getenv("CODER_MEMORY"),
+		Pids:             512,
+		Disk:             os.Getenv("CODER_DISK"),
+		WorkspaceQuotaMB: 2048,
+	}
+	if limits.CPUs == "" {
+		limits.CPUs = "2"
+	}
+	if limits.Memory == "" {
+		limits.Memory = "4g"
+	}
+	if n, err := strconv.Atoi(os.Getenv("CODER_PIDS")); err == nil && n >= 0 {
+		limits.Pids = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("CODER_WORKSPACE_QUOTA_MB")); err == nil && n >= 0 {
+		limits.WorkspaceQuotaMB = n
+	}
+
 	skillsDir := os.Getenv("CODER_SKILLS_DIR")
 	if skillsDir == "" {
 		skillsDir = "/skills"
@@ -158,6 +305,10 @@ func loadCoderConfig() CoderConfig {
 		OrgURL:    os.Getenv("GIT_ORG_URL"),
 	}
 	gitConfig.Enabled = gitConfig.Token != "" && gitConfig.OrgURL != ""
+	gitConfig.ReviewPushes = os.Getenv("CODER_PUSH_REVIEW") != "false"
+	if n, err := strconv.Atoi(os.Getenv("CODER_AUTO_APPROVE_LINES")); err == nil && n >= 0 {
+		gitConfig.AutoApproveLines = n
+	}
 
 	// enabled if we have an API key for the provider (or it's ollama)
 	envKey := EnvKeyForProvider(provider)
@@ -172,7 +323,11 @@ func loadCoderConfig() CoderConfig {
 		SkillsDir:      skillsDir,
 		Isolated:       isolated,
 		Image:          image,
+		Verify:         verify,
+		FixAttempts:    fixAttempts,
 		Git:            gitConfig,
+		Runtime:        runtime,
+		Limits:         limits,
 	}
 }
 
@@ -197,9 +352,58 @@ func loadBudgetConfig() BudgetConfig {
 	}
 }
 
+// loadBackupConfig reads BACKUP_INTERVAL (a duration of at least an hour,
+// 0 or off disables) and BACKUP_KEEP
+func loadBackupConfig() BackupConfig {
+	cfg := BackupConfig{Interval: 24 * time.Hour, Keep: 7}
+
+	switch v := strings.ToLower(os.Getenv("BACKUP_INTERVAL")); v {
+	case "":
+	case "0", "off", "false":
+		cfg.Interval = 0
+	default:
+		if d, err := time.ParseDuration(v); err == nil && d >= time.Hour {
+			cfg.Interval = d
+		}
+	}
+
+	if n, err := strconv.Atoi(os.Getenv("BACKUP_KEEP")); err == nil && n > 0 {
+		cfg.Keep = n
+	}
+
+	return cfg
+}
+
+func loadSessionConfig() SessionConfig {
+	cfg := SessionConfig{BufferSize: 12, IdleTTL: 6 * time.Hour, MaxSessions: 200}
+
+	if size, err := strconv.Atoi(os.Getenv("CONVERSATION_BUFFER_SIZE")); err == nil && size > 0 {
+		cfg.BufferSize = size
+	}
+
+	if v := os.Getenv("SESSION_IDLE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.IdleTTL = d
+		}
+	}
+	if v := os.Getenv("SESSION_MAX"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.MaxSessions = n
+		}
+	}
+
+	return cfg
+}
+
 func loadMultiBotConfig() MultiBot {
 	telegramToken := os.Getenv("TELEGRAM_TOKEN")
 	discordToken := os.Getenv("DISCORD_TOKEN")
+	webToken := os.Getenv("WEB_CHAT_TOKEN")
+
+	webAddr := os.Getenv("WEB_CHAT_ADDR")
+	if webAddr == "" {
+		webAddr = ":8081"
+	}
 
 	var ownerChatID int64
 	if id, err := strconv.ParseInt(os.Getenv("OWNER_CHAT_ID"), 10, 64); err == nil {
@@ -219,6 +423,54 @@ func loadMultiBotConfig() MultiBot {
 			OwnerID:        os.Getenv("DISCORD_OWNER_ID"),
 			TrustedChannel: os.Getenv("DISCORD_TRUSTED_CHANNEL"),
 		},
+		Web: BotInstance{
+			Enabled: webToken != "",
+			Token:   webToken,
+			Addr:    webAddr,
+		},
+		Email: loadEmailBotConfig(),
+	}
+}
+
+func loadEmailBotConfig() EmailBotConfig {
+	imapAddr := os.Getenv("EMAIL_IMAP_ADDR")
+	smtpAddr := os.Getenv("EMAIL_SMTP_ADDR")
+	username := os.Getenv("EMAIL_USERNAME")
+	password := os.Getenv("EMAIL_PASSWORD")
+	owner := strings.ToLower(strings.TrimSpace(os.Getenv("EMAIL_OWNER_ADDRESS")))
+
+	from := os.Getenv("EMAIL_FROM")
+	if from == "" {
+		from = username
+	}
+
+	var allowed []string
+	for _, addr := range strings.Split(os.Getenv("EMAIL_ALLOWED_SENDERS"), ",") {
+		if addr = strings.ToLower(strings.TrimSpace(addr)); addr != "" {
+			allowed = append(allowed, addr)
+		}
+	}
+
+	pollInterval := 60 * time.Second
+	if d, err := time.ParseDuration(os.Getenv("EMAIL_POLL_INTERVAL")); err == nil && d >= 10*time.Second {
+		pollInterval = d
+	}
+
+	// never answer strangers: without an owner or allowlist the channel stays off
+	enabled := imapAddr != "" && smtpAddr != "" && username != "" && password != "" &&
+		(owner != "" || len(allowed) > 0)
+
+	return EmailBotConfig{
+		Enabled:        enabled,
+		IMAPAddr:       imapAddr,
+		SMTPAddr:       smtpAddr,
+		Username:       username,
+		Password:       password,
+		From:           from,
+		AllowedSenders: allowed,
+		OwnerAddress:   owner,
+		AuthservID:     strings.TrimSpace(os.Getenv("EMAIL_AUTHSERV_ID")),
+		PollInterval:   pollInterval,
 	}
 }
 
@@ -237,6 +489,34 @@ func loadBrowserConfig() BrowserConfig {
 	}
 }
 
+func loadSandboxConfig() SandboxConfig {
+	return SandboxConfig{
+		Runtime:         os.Getenv("SANDBOX_RUNTIME"),
+		SeccompProfile:  os.Getenv("SANDBOX_SECCOMP_PROFILE"),
+		NoNewPrivileges: os.Getenv("SANDBOX_NO_NEW_PRIVILEGES") != "false",
+	}
+}
+
+func loadHomelabAgentConfig() HomelabAgentConfig {
+	hosts := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("HOMELAB_HOSTS"), ",") {
+		name, url, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		hosts[strings.TrimSpace(name)] = strings.TrimRight(strings.TrimSpace(url), "/")
+	}
+
+	return HomelabAgentConfig{
+		Token:     os.Getenv("HOMELAB_AGENT_TOKEN"),
+		CAFile:    os.Getenv("HOMELAB_AGENT_CA_FILE"),
+		CertFile:  os.Getenv("HOMELAB_AGENT_CERT_FILE"),
+		KeyFile:   os.Getenv("HOMELAB_AGENT_KEY_FILE"),
+		AlertAddr: os.Getenv("HOMELAB_ALERT_ADDR"),
+		Hosts:     hosts,
+	}
+}
+
 func loadPinchtabConfig() PinchtabConfig {
 	url := os.Getenv("PINCHTAB_URL")
 	token := os.Getenv("PINCHTAB_TOKEN")
@@ -268,6 +548,33 @@ func loadAlertConfig() AlertConfig {
 	}
 }
 
+func loadNotifyConfig() NotifyConfig {
+	routes := make(map[string][]string)
+	for _, kind := range NotifyKinds {
+		sinks := []string{"chat"}
+		if v := os.Getenv("NOTIFY_" + strings.ToUpper(kind)); v != "" {
+			sinks = nil
+			for _, sink := range strings.Split(v, ",") {
+				if sink = strings.ToLower(strings.TrimSpace(sink)); sink != "" {
+					sinks = append(sinks, sink)
+				}
+			}
+		}
+		routes[kind] = sinks
+	}
+
+	return NotifyConfig{
+		NtfyURL:       os.Getenv("NTFY_URL"),
+		NtfyToken:     os.Getenv("NTFY_TOKEN"),
+		GotifyURL:     os.Getenv("GOTIFY_URL"),
+		GotifyToken:   os.Getenv("GOTIFY_TOKEN"),
+		PushoverToken: os.Getenv("PUSHOVER_TOKEN"),
+		PushoverUser:  os.Getenv("PUSHOVER_USER"),
+		WebhookURL:    os.Getenv("NOTIFY_WEBHOOK_URL"),
+		Routes:        routes,
+	}
+}
+
 func loadEmbedderConfig() EmbedderConfig {
 	return EmbedderConfig{
 		Provider: os.Getenv("EMBEDDER_PROVIDER"),
@@ -276,6 +583,166 @@ func loadEmbedderConfig() EmbedderConfig {
 	}
 }
 
+func loadSpeechConfig() SpeechConfig {
+	provider := os.Getenv("SPEECH_PROVIDER")
+	// keep voice notes working out of the box when an OpenAI key is present
+	if provider == "" && os.Getenv("OPENAI_API_KEY") != "" {
+		provider = "openai"
+	}
+
+	apiKey := os.Getenv("SPEECH_API_KEY")
+	if apiKey == "" && provider == "openai" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+
+	return SpeechConfig{
+		Provider: provider,
+		APIKey:   apiKey,
+		BaseURL:  os.Getenv("SPEECH_URL"),
+		Model:    os.Getenv("SPEECH_MODEL"),
+		Language: os.Getenv("SPEECH_LANGUAGE"),
+	}
+}
+
+func loadAdminConfig() AdminConfig {
+	addr := os.Getenv("ADMIN_ADDR")
+	if addr == "" {
+		addr = ":8082"
+	}
+
+	return AdminConfig{
+		Token: os.Getenv("ADMIN_TOKEN"),
+		Addr:  addr,
+	}
+}
+
+func loadCalendarConfig() CalendarConfig {
+	calendarID := os.Getenv("GOOGLE_CALENDAR_ID")
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+
+	return CalendarConfig{
+		Provider:     strings.ToLower(os.Getenv("CALENDAR_PROVIDER")),
+		URL:          os.Getenv("CALDAV_URL"),
+		Username:     os.Getenv("CALDAV_USERNAME"),
+		Password:     os.Getenv("CALDAV_PASSWORD"),
+		CalendarID:   calendarID,
+		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+		RefreshToken: os.Getenv("GOOGLE_REFRESH_TOKEN"),
+	}
+}
+
+func loadInboxConfig() InboxConfig {
+	cfg := InboxConfig{
+		IMAPAddr: os.Getenv("INBOX_IMAP_ADDR"),
+		SMTPAddr: os.Getenv("INBOX_SMTP_ADDR"),
+		Username: os.Getenv("INBOX_USERNAME"),
+		Password: os.Getenv("INBOX_PASSWORD"),
+		From:     os.Getenv("INBOX_FROM"),
+
+		AuthservID: strings.TrimSpace(os.Getenv("INBOX_AUTHSERV_ID")),
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+
+	for _, addr := range strings.Split(os.Getenv("INBOX_ALLOWED_SENDERS"), ",") {
+		if addr = strings.ToLower(strings.TrimSpace(addr)); addr != "" {
+			cfg.AllowedSenders = append(cfg.AllowedSenders, addr)
+		}
+	}
+
+	cfg.Enabled = cfg.IMAPAddr != "" && cfg.Username != "" && cfg.Password != ""
+	return cfg
+}
+
+// loadFeedsConfig reads FEED_POLL_INTERVAL, which is floored at 5 minutes to be polite to publishers
+func loadFeedsConfig() FeedsConfig {
+	cfg := FeedsConfig{PollInterval: 30 * time.Minute}
+	if d, err := time.ParseDuration(os.Getenv("FEED_POLL_INTERVAL")); err == nil && d >= 5*time.Minute {
+		cfg.PollInterval = d
+	}
+	return cfg
+}
+
+// loadGeoConfig enables the location tools with GEO_ENABLED=true, or when a
+// self-hosted NOMINATIM_URL or OSRM_URL is set; unset URLs use the public servers
+func loadGeoConfig() GeoConfig {
+	cfg := GeoConfig{
+		NominatimURL: os.Getenv("NOMINATIM_URL"),
+		OSRMURL:      os.Getenv("OSRM_URL"),
+	}
+	cfg.Enabled = os.Getenv("GEO_ENABLED") == "true" || cfg.NominatimURL != "" || cfg.OSRMURL != ""
+	return cfg
+}
+
+// loadDigestConfig reads DIGEST_FREQUENCY (daily|weekly), DIGEST_TIME (HH:MM)
+// and DIGEST_CHAT_ID, which falls back to OWNER_CHAT_ID
+func loadDigestConfig() DigestConfig {
+	cfg := DigestConfig{Hour: 8}
+
+	switch freq := strings.ToLower(os.Getenv("DIGEST_FREQUENCY")); freq {
+	case "daily", "weekly":
+		cfg.Frequency = freq
+	}
+
+	if at := os.Getenv("DIGEST_TIME"); at != "" {
+		if t, err := time.Parse("15:04", at); err == nil {
+			cfg.Hour, cfg.Minute = t.Hour(), t.Minute()
+		}
+	}
+
+	if id, err := strconv.ParseInt(os.Getenv("DIGEST_CHAT_ID"), 10, 64); err == nil {
+		cfg.ChatID = id
+	} else if id, err := strconv.ParseInt(os.Getenv("OWNER_CHAT_ID"), 10, 64); err == nil {
+		cfg.ChatID = id
+	}
+
+	return cfg
+}
+
+// Schedule returns the cron expression for delivering the digest. Weekly
+// digests go out on Monday morning.
+func (d DigestConfig) Schedule() string {
+	if d.Frequency == "weekly" {
+		return fmt.Sprintf("0 %d %d * * 1", d.Minute, d.Hour)
+	}
+	return fmt.Sprintf("0 %d %d * * *", d.Minute, d.Hour)
+}
+
+// Period is how far back a digest looks
+func (d DigestConfig) Period() time.Duration {
+	if d.Frequency == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// loadTracingConfig reads the standard OpenTelemetry exporter variables
+func loadTracingConfig() TracingConfig {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return TracingConfig{
+		Endpoint:    endpoint,
+		ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+		Headers:     headers,
+	}
+}
+
 func loadBotConfig() (BotConfig, error) {
 	provider := os.Getenv("BOT_PROVIDER")
 	if provider == "" {
@@ -294,6 +761,16 @@ func loadBotConfig() (BotConfig, error) {
 		if token == "" {
 			return BotConfig{}, fmt.Errorf("DISCORD_TOKEN not set")
 		}
+	case "web":
+		token = os.Getenv("WEB_CHAT_TOKEN")
+		if token == "" {
+			return BotConfig{}, fmt.Errorf("WEB_CHAT_TOKEN not set")
+		}
+	case "email":
+		token = os.Getenv("EMAIL_PASSWORD")
+		if token == "" {
+			return BotConfig{}, fmt.Errorf("EMAIL_PASSWORD not set")
+		}
 	default:
 		return BotConfig{}, fmt.Errorf("unknown BOT_PROVIDER: %s", provider)
 	}
@@ -317,24 +794,56 @@ func loadLLMConfig() (LLMConfig, error) {
 
 	model := os.Getenv("LLM_MODEL")
 	if model == "" {
-		model = defaultLLMModel(provider)
+		model = DefaultLLMModel(provider)
 	}
 
-	return LLMConfig{
+	cfg := LLMConfig{
 		Provider: provider,
 		APIKey:   apiKey,
 		Model:    model,
-	}, nil
+	}
+
+	if attempts, err := strconv.Atoi(os.Getenv("LLM_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		cfg.MaxAttempts = attempts
+	}
+
+	cfg.LogFile = os.Getenv("LLM_LOG_FILE")
+	if mb, err := strconv.Atoi(os.Getenv("LLM_LOG_MAX_MB")); err == nil && mb > 0 {
+		cfg.LogMaxMB = mb
+	}
+
+	cfg.EvalModel = os.Getenv("EVAL_MODEL")
+	cfg.EvalProvider = os.Getenv("EVAL_PROVIDER")
+	if cfg.EvalProvider == "" {
+		cfg.EvalProvider = provider
+	}
+	cfg.EvalSample = 1
+	if rate, err := strconv.ParseFloat(os.Getenv("EVAL_SAMPLE_RATE"), 64); err == nil && rate >= 0 && rate <= 1 {
+		cfg.EvalSample = rate
+	}
+
+	if provider == "azure" {
+		cfg.BaseURL = os.Getenv("AZURE_OPENAI_ENDPOINT")
+		if cfg.BaseURL == "" {
+			return LLMConfig{}, fmt.Errorf("AZURE_OPENAI_ENDPOINT not set")
+		}
+		cfg.APIVersion = os.Getenv("AZURE_OPENAI_API_VERSION")
+	}
+
+	return cfg, nil
 }
 
-func defaultLLMModel(provider string) string {
+// DefaultLLMModel is the chat model used when LLM_MODEL is not set
+func DefaultLLMModel(provider string) string {
 	switch provider {
 	case "kimi":
 		return "kimi-k2-0711-preview"
 	case "claude":
 		return "claude-sonnet-4-20250514"
-	case "openai":
+	case "openai", "azure":
 		return "gpt-4o"
+	case "openrouter":
+		return "anthropic/claude-sonnet-4"
 	default:
 		return "qwen2.5:3b"
 	}
@@ -393,6 +902,18 @@ func getAPIKey(provider, prefix string) (string, error) {
 			return "", fmt.Errorf("KIMI_API_KEY not set")
 		}
 		return key, nil
+	case "azure":
+		key := os.Getenv("AZURE_OPENAI_API_KEY")
+		if key == "" {
+			return "", fmt.Errorf("AZURE_OPENAI_API_KEY not set")
+		}
+		return key, nil
+	case "openrouter":
+		key := os.Getenv("OPENROUTER_API_KEY")
+		if key == "" {
+			return "", fmt.Errorf("OPENROUTER_API_KEY not set")
+		}
+		return key, nil
 	case "ollama":
 		// Ollama doesn't need an API key
 		return "ollama", nil
@@ -2,7 +2,10 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDetectProviderKimi(t *testing.T) {
@@ -135,6 +138,7 @@ func TestEnvKeyForProvider(t *testing.T) {
 		{"kimi", "KIMI_API_KEY"},
 		{"claude", "ANTHROPIC_API_KEY"},
 		{"openai", "OPENAI_API_KEY"},
+		{"azure", "AZURE_OPENAI_API_KEY"},
 		{"ollama", ""},
 		{"unknown", "UNKNOWN_API_KEY"}, // unknown providers get uppercased + _API_KEY
 	}
@@ -146,3 +150,319 @@ func TestEnvKeyForProvider(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadDigestConfig(t *testing.T) {
+	t.Setenv("DIGEST_FREQUENCY", "Weekly")
+	t.Setenv("DIGEST_TIME", "18:30")
+	t.Setenv("DIGEST_CHAT_ID", "")
+	t.Setenv("OWNER_CHAT_ID", "42")
+
+	cfg := loadDigestConfig()
+	if cfg.Frequency != "weekly" {
+		t.Errorf("expected weekly, got %q", cfg.Frequency)
+	}
+	if cfg.ChatID != 42 {
+		t.Errorf("expected owner chat fallback 42, got %d", cfg.ChatID)
+	}
+	if got := cfg.Schedule(); got != "0 30 18 * * 1" {
+		t.Errorf("unexpected schedule %q", got)
+	}
+	if cfg.Period() != 7*24*time.Hour {
+		t.Errorf("unexpected period %s", cfg.Period())
+	}
+}
+
+func TestLoadDigestConfigDisabled(t *testing.T) {
+	t.Setenv("DIGEST_FREQUENCY", "hourly")
+	t.Setenv("DIGEST_TIME", "")
+
+	cfg := loadDigestConfig()
+	if cfg.Frequency != "" {
+		t.Errorf("expected digests disabled, got %q", cfg.Frequency)
+	}
+	if got := cfg.Schedule(); got != "0 0 8 * * *" {
+		t.Errorf("expected default 08:00 daily schedule, got %q", got)
+	}
+}
+
+func TestLoadBackupConfig(t *testing.T) {
+	t.Setenv("BACKUP_INTERVAL", "12h")
+	t.Setenv("BACKUP_KEEP", "14")
+
+	cfg := loadBackupConfig()
+	if cfg.Interval != 12*time.Hour || cfg.Keep != 14 {
+		t.Errorf("got %+v, want 12h keeping 14", cfg)
+	}
+
+	t.Setenv("BACKUP_INTERVAL", "off")
+	if cfg := loadBackupConfig(); cfg.Interval != 0 {
+		t.Errorf("expected backups disabled, got %s", cfg.Interval)
+	}
+
+	// too frequent falls back to the daily default
+	t.Setenv("BACKUP_INTERVAL", "5m")
+	t.Setenv("BACKUP_KEEP", "")
+	if cfg := loadBackupConfig(); cfg.Interval != 24*time.Hour || cfg.Keep != 7 {
+		t.Errorf("got %+v, want the defaults", cfg)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]int64{
+		"500MB": 500 << 20,
+		"5gb":   5 << 30,
+		"1.5G":  3 << 29,
+		"2048":  2048,
+		"":      0,
+		"lots":  0,
+		"-1GB":  0,
+		"10 KB": 10 << 10,
+	}
+	for in, want := range tests {
+		if got := parseByteSize(in); got != want {
+			t.Errorf("parseByteSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestSessionModelOverride(t *testing.T) {
+	dir := t.TempDir()
+	rc, err := NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rc.SetSessionModel("telegram:42", ModelOverride{Provider: "ollama", Model: "qwen3:8b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// overrides survive a restart and don't touch the global model
+	rc, err = NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	override, ok := rc.SessionModel("telegram:42")
+	if !ok || override.Provider != "ollama" || override.Model != "qwen3:8b" {
+		t.Errorf("SessionModel = %+v, %v", override, ok)
+	}
+	if _, ok := rc.SessionModel("telegram:7"); ok {
+		t.Error("override leaked to another session")
+	}
+	if _, isOverride := rc.Overrides()["llm_model"]; isOverride {
+		t.Error("session override changed the global model")
+	}
+
+	if cleared, err := rc.ClearSessionModel("telegram:42"); err != nil || !cleared {
+		t.Errorf("ClearSessionModel = %v, %v", cleared, err)
+	}
+	if _, ok := rc.SessionModel("telegram:42"); ok {
+		t.Error("override still set after clearing")
+	}
+}
+
+func TestModelProbeCache(t *testing.T) {
+	dir := t.TempDir()
+	rc, err := NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	probe := ModelProbe{Provider: "ollama", Model: "llava:7b", Chat: true, Vision: true, ProbedAt: time.Now()}
+	if err := rc.SetModelProbe(probe); err != nil {
+		t.Fatal(err)
+	}
+	// probes are a cache, resetting overrides keeps them
+	if err := rc.ResetAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err = NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := rc.ModelProbe("ollama", "llava:7b")
+	if !ok {
+		t.Fatal("probe not persisted")
+	}
+	if caps := strings.Join(got.Capabilities(), ","); caps != "chat,vision" {
+		t.Errorf("capabilities = %s, want chat,vision", caps)
+	}
+	if _, ok := rc.ModelProbe("ollama", "qwen3:8b"); ok {
+		t.Error("unprobed model has a probe")
+	}
+}
+
+func TestSessionProactivity(t *testing.T) {
+	dir := t.TempDir()
+	rc, err := NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rc.SessionProactivity("telegram:42"); got != DefaultProactivity {
+		t.Errorf("unset level = %q, want %q", got, DefaultProactivity)
+	}
+	if err := rc.SetSessionProactivity("telegram:42", "pushy"); err == nil {
+		t.Error("unknown level accepted")
+	}
+	if err := rc.SetSessionProactivity("telegram:42", "high"); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err = NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rc.SessionProactivity("telegram:42"); got != "high" {
+		t.Errorf("level after restart = %q, want high", got)
+	}
+}
+
+func TestQuietHoursUntil(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 4, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		quiet QuietHours
+		now   time.Time
+		until time.Time
+		ok    bool
+	}{
+		{QuietHours{"22:00", "07:00"}, day(23, 30), day(7, 0).AddDate(0, 0, 1), true},
+		{QuietHours{"22:00", "07:00"}, day(6, 59), day(7, 0), true},
+		{QuietHours{"22:00", "07:00"}, day(7, 0), time.Time{}, false},
+		{QuietHours{"22:00", "07:00"}, day(12, 0), time.Time{}, false},
+		{QuietHours{"13:00", "14:30"}, day(13, 15), day(14, 30), true},
+		{QuietHours{"13:00", "14:30"}, day(15, 0), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		until, ok := tt.quiet.Until(tt.now)
+		if ok != tt.ok || !until.Equal(tt.until) {
+			t.Errorf("%+v.Until(%s) = %s, %v, want %s, %v", tt.quiet, tt.now.Format("15:04"), until, ok, tt.until, tt.ok)
+		}
+	}
+}
+
+func TestSkillGrants(t *testing.T) {
+	dir := t.TempDir()
+	rc, err := NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rc.SkillGrants("weather"); len(got) != 0 {
+		t.Errorf("unset grants = %v, want none", got)
+	}
+	if err := rc.SetSkillGrants("weather", []string{"network"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err = NewRuntimeConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rc.SkillGrants("weather"); len(got) != 1 || got[0] != "network" {
+		t.Errorf("grants after restart = %v, want [network]", got)
+	}
+
+	if err := rc.SetSkillGrants("weather", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := rc.SkillGrants("weather"); len(got) != 0 {
+		t.Errorf("grants after revoke = %v, want none", got)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sheldon.yaml")
+	data := `
+llm:
+  provider: claude
+  max-attempts: 3
+budget:
+  warn_at: 0.9
+disabled_tools: [browse, ssh_exec]
+TZ: Europe/Berlin
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"LLM_PROVIDER":     "claude",
+		"LLM_MAX_ATTEMPTS": "3",
+		"BUDGET_WARN_AT":   "0.9",
+		"DISABLED_TOOLS":   "browse,ssh_exec",
+		"TZ":               "Europe/Berlin",
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %q, want %q", key, values[key], value)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("llm:\n  provider: claude\nLLM_PROVIDER: kimi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(path); err == nil {
+		t.Error("expected error for a key set twice")
+	}
+}
+
+func TestConfigFileEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sheldon.yaml")
+	if err := os.WriteFile(path, []byte("budget:\n  daily_limit: 5000\n  warn_at: 0.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SHELDON_CONFIG", path)
+	t.Setenv("BUDGET_WARN_AT", "0.7")
+	t.Setenv("BUDGET_DAILY_LIMIT", "")
+	os.Unsetenv("BUDGET_DAILY_LIMIT")
+
+	if err := applyConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+	budget := loadBudgetConfig()
+	if budget.DailyLimit != 5000 {
+		t.Errorf("daily limit = %d, want 5000 from the file", budget.DailyLimit)
+	}
+	if budget.WarnAt != 0.7 {
+		t.Errorf("warn at = %v, want 0.7 from env", budget.WarnAt)
+	}
+
+	t.Setenv("SHELDON_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	if err := applyConfigFile(); err == nil {
+		t.Error("expected error for a missing SHELDON_CONFIG file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	essence := t.TempDir()
+	if err := os.WriteFile(filepath.Join(essence, "SOUL.md"), []byte("soul"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{EssencePath: essence, Timezone: "UTC", Deployer: DeployerConfig{Target: "compose"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+
+	cfg.Timezone = "Mars/Olympus"
+	cfg.Agent.CertFile = filepath.Join(essence, "missing.pem")
+	cfg.Notify.Routes = map[string][]string{"budget": {"chat", "ntfy"}, "alerts": {"pager"}}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{"TZ", "HOMELAB_AGENT_CERT_FILE: ", "set together", "NOTIFY_BUDGET: ntfy is not configured", `NOTIFY_ALERTS: unknown sink "pager"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q: %v", want, err)
+		}
+	}
+}
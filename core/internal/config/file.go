@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is read from the working directory when SHELDON_CONFIG
+// is not set. A missing default file is fine; everything can come from env.
+const DefaultConfigFile = "sheldon.yaml"
+
+var envKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// ConfigFile returns the config file path, SHELDON_CONFIG or sheldon.yaml
+func ConfigFile() string {
+	if path := os.Getenv("SHELDON_CONFIG"); path != "" {
+		return path
+	}
+	return DefaultConfigFile
+}
+
+// ReadFile parses a config file into the env vars it stands for. Nested keys
+// join with underscores, so
+//
+//	llm:
+//	  provider: claude
+//	budget:
+//	  daily_limit: 5000000
+//
+// is LLM_PROVIDER=claude and BUDGET_DAILY_LIMIT=5000000. Lists become comma
+// separated values, e.g. disabled_tools: [browse, ssh_exec].
+func ReadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root map[string]any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", root, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, node map[string]any, values map[string]string) error {
+	for name, value := range node {
+		key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		if !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid key %q", name)
+		}
+
+		if _, dup := values[key]; dup {
+			return fmt.Errorf("%s is set twice", key)
+		}
+
+		switch v := value.(type) {
+		case nil:
+		case map[string]any:
+			if err := flatten(key, v, values); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := scalar(item)
+				if !ok {
+					return fmt.Errorf("%s: list items must be plain values", key)
+				}
+				items = append(items, s)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			s, ok := scalar(v)
+			if !ok {
+				return fmt.Errorf("%s: unsupported value", key)
+			}
+			values[key] = s
+		}
+	}
+	return nil
+}
+
+func scalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(v), true
+	case time.Time:
+		return v.Format(time.RFC3339), true
+	}
+	return "", false
+}
+
+// applyConfigFile sets env vars from the config file. Anything already in the
+// environment (including .env) wins, so the file holds the defaults and env
+// overrides them.
+func applyConfigFile() error {
+	path := ConfigFile()
+	values, err := ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv("SHELDON_CONFIG") == "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return nil
+}
+
+// Validate checks settings that Load accepts but would fail later at
+// runtime. All problems are returned together.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, err := os.Stat(filepath.Join(c.EssencePath, "SOUL.md")); err != nil {
+		errs = append(errs, fmt.Errorf("SHELDON_ESSENCE: SOUL.md not found in %s", c.EssencePath))
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		errs = append(errs, fmt.Errorf("TZ: unknown timezone %q", c.Timezone))
+	}
+	if c.Deployer.Target != "compose" && c.Deployer.Target != "kubernetes" {
+		errs = append(errs, fmt.Errorf("DEPLOYER_TARGET: must be compose or kubernetes, got %q", c.Deployer.Target))
+	}
+
+	files := map[string]string{
+		"SSH_HOSTS_FILE":          c.SSHHosts,
+		"HOMELAB_AGENT_CA_FILE":   c.Agent.CAFile,
+		"HOMELAB_AGENT_CERT_FILE": c.Agent.CertFile,
+		"HOMELAB_AGENT_KEY_FILE":  c.Agent.KeyFile,
+		"SANDBOX_SECCOMP_PROFILE": c.Sandbox.SeccompProfile,
+	}
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if path := files[key]; path != "" {
+			if _, err := os.Stat(path); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s not found", key, path))
+			}
+		}
+	}
+
+	if (c.Agent.CertFile == "") != (c.Agent.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("HOMELAB_AGENT_CERT_FILE and HOMELAB_AGENT_KEY_FILE must be set together"))
+	}
+
+	for _, kind := range NotifyKinds {
+		for _, sink := range c.Notify.Routes[kind] {
+			key := "NOTIFY_" + strings.ToUpper(kind)
+			if !slices.Contains(NotifySinks, sink) {
+				errs = append(errs, fmt.Errorf("%s: unknown sink %q, use %s", key, sink, strings.Join(NotifySinks, ", ")))
+			} else if !c.Notify.Configured(sink) {
+				errs = append(errs, fmt.Errorf("%s: %s is not configured", key, sink))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
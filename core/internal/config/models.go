@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,9 +21,19 @@ type ModelInfo struct {
 	Capabilities []string `json:"capabilities,omitempty"`
 }
 
+// openRouterModelsURL lists every model routable through OpenRouter (no auth required)
+const openRouterModelsURL = "https://openrouter.ai/api/v1/models"
+
+// openRouterCacheTTL avoids refetching the (large) OpenRouter catalogue on every list_models call
+const openRouterCacheTTL = time.Hour
+
 type ModelRegistry struct {
 	runtimeConfig *RuntimeConfig
 	client        *http.Client
+
+	mu                sync.Mutex
+	openRouterModels  []ModelInfo
+	openRouterFetched time.Time
 }
 
 func NewModelRegistry(rc *RuntimeConfig) *ModelRegistry {
@@ -90,20 +102,113 @@ func (r *ModelRegistry) LocalModels(ctx context.Context) ([]ModelInfo, error) {
 
 	models := make([]ModelInfo, 0, len(tagsResp.Models))
 	for _, m := range tagsResp.Models {
-		models = append(models, ModelInfo{
+		info := ModelInfo{
 			ID:       m.Name,
 			Provider: "ollama",
 			Name:     m.Name,
 			Local:    true,
+		}
+		// ollama doesn't say what a model can do, a probe does
+		if r.runtimeConfig != nil {
+			if probe, ok := r.runtimeConfig.ModelProbe("ollama", m.Name); ok {
+				info.Capabilities = probe.Capabilities()
+			}
+		}
+		models = append(models, info)
+	}
+
+	return models, nil
+}
+
+type openRouterModelsResponse struct {
+	Data []openRouterModel `json:"data"`
+}
+
+type openRouterModel struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Architecture struct {
+		InputModalities []string `json:"input_modalities"`
+	} `json:"architecture"`
+	SupportedParameters []string `json:"supported_parameters"`
+}
+
+// OpenRouterModels fetches the OpenRouter model catalogue, cached for an hour
+func (r *ModelRegistry) OpenRouterModels(ctx context.Context) ([]ModelInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.openRouterModels != nil && time.Since(r.openRouterFetched) < openRouterCacheTTL {
+		return r.openRouterModels, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", openRouterModelsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openrouter request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openrouter returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var modelsResp openRouterModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	models := make([]ModelInfo, 0, len(modelsResp.Data))
+	for _, m := range modelsResp.Data {
+		caps := []string{"chat"}
+		for _, p := range m.SupportedParameters {
+			if p == "tools" {
+				caps = append(caps, "tools")
+				break
+			}
+		}
+		for _, modality := range m.Architecture.InputModalities {
+			if modality == "image" {
+				caps = append(caps, "vision")
+				break
+			}
+		}
+
+		models = append(models, ModelInfo{
+			ID:           m.ID,
+			Provider:     "openrouter",
+			Name:         m.Name,
+			Local:        false,
+			Capabilities: caps,
 		})
 	}
 
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].ID < models[j].ID
+	})
+
+	r.openRouterModels = models
+	r.openRouterFetched = time.Now()
+
 	return models, nil
 }
 
 func (r *ModelRegistry) AllModels(ctx context.Context) ([]ModelInfo, error) {
 	models := r.CloudModels()
 
+	// the OpenRouter catalogue is hundreds of models, only list it when usable
+	if os.Getenv(EnvKeyForProvider("openrouter")) != "" {
+		routed, err := r.OpenRouterModels(ctx)
+		if err == nil {
+			models = append(models, routed...)
+		}
+	}
+
 	localModels, err := r.LocalModels(ctx)
 	if err != nil {
 		return models, nil
@@ -243,6 +348,7 @@ func (r *ModelRegistry) Providers() []ProviderInfo {
 		{ID: "kimi", Name: "Moonshot Kimi", EnvKey: "KIMI_API_KEY"},
 		{ID: "claude", Name: "Anthropic Claude", EnvKey: "ANTHROPIC_API_KEY"},
 		{ID: "openai", Name: "OpenAI", EnvKey: "OPENAI_API_KEY"},
+		{ID: "azure", Name: "Azure OpenAI", EnvKey: "AZURE_OPENAI_API_KEY"},
 		{ID: "nvidia", Name: "NVIDIA NIM", EnvKey: "NVIDIA_API_KEY"},
 		{ID: "ollama", Name: "Ollama (local)", EnvKey: ""},
 		// OpenAI-compatible providers (add API key to Doppler to enable)
@@ -252,6 +358,8 @@ func (r *ModelRegistry) Providers() []ProviderInfo {
 		{ID: "deepseek", Name: "DeepSeek", EnvKey: "DEEPSEEK_API_KEY"},
 		{ID: "fireworks", Name: "Fireworks AI", EnvKey: "FIREWORKS_API_KEY"},
 		{ID: "perplexity", Name: "Perplexity", EnvKey: "PERPLEXITY_API_KEY"},
+		// Model router - one key for models from many vendors
+		{ID: "openrouter", Name: "OpenRouter", EnvKey: "OPENROUTER_API_KEY"},
 	}
 }
 
@@ -266,6 +374,8 @@ func EnvKeyForProvider(provider string) string {
 		return "KIMI_API_KEY"
 	case "nvidia":
 		return "NVIDIA_API_KEY"
+	case "azure":
+		return "AZURE_OPENAI_API_KEY"
 	case "ollama":
 		return ""
 	default:
@@ -308,6 +418,9 @@ func (r *ModelRegistry) ProviderStatus(ctx context.Context) []ProviderStatus {
 		if p.ID == "ollama" {
 			_, err := r.LocalModels(ctx)
 			status.Available = err == nil
+		} else if p.ID == "openrouter" {
+			_, err := r.OpenRouterModels(ctx)
+			status.Available = err == nil
 		} else {
 			status.Available = true
 		}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 )
 
 // RuntimeConfig holds config values that can be changed at runtime
@@ -25,8 +27,65 @@ type RuntimeData struct {
 	CoderProvider    string `json:"coder_provider,omitempty"`
 	CoderModel       string `json:"coder_model,omitempty"`
 	OllamaHost       string `json:"ollama_host,omitempty"`
+
+	// homelab-agents added with add_remote_host, name -> URL
+	RemoteHosts map[string]string `json:"remote_hosts,omitempty"`
+
+	// chat model overrides from switch_model with scope session, session ID -> model
+	SessionModels map[string]ModelOverride `json:"session_models,omitempty"`
+
+	// personas chosen with switch_persona, session ID -> persona name
+	SessionPersonas map[string]string `json:"session_personas,omitempty"`
+
+	// conversation buffer sizes from set_context_size, session ID -> messages
+	SessionBufferSizes map[string]int `json:"session_buffer_sizes,omitempty"`
+
+	// how eagerly Sheldon schedules its own check-ins, session ID -> level
+	SessionProactivity map[string]string `json:"session_proactivity,omitempty"`
+
+	// sessions that archive uploaded photos and files, from set_media_archive
+	SessionMediaArchive map[string]bool `json:"session_media_archive,omitempty"`
+
+	// when crons and proactive messages hold off, from set_quiet_hours and vacation_until
+	QuietHours    *QuietHours `json:"quiet_hours,omitempty"`
+	VacationUntil *time.Time  `json:"vacation_until,omitempty"`
+
+	// capabilities the user granted skill scripts, skill -> network, storage
+	SkillGrants map[string][]string `json:"skill_grants,omitempty"`
+
+	// live capability tests, provider/model -> result
+	ModelProbes map[string]ModelProbe `json:"model_probes,omitempty"`
+}
+
+// ModelProbe is what a live test found a model can do
+type ModelProbe struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Chat     bool      `json:"chat"`
+	Tools    bool      `json:"tools"`
+	Vision   bool      `json:"vision"`
+	ProbedAt time.Time `json:"probed_at"`
 }
 
+// QuietHours is a daily window, in the user's timezone, when nothing is sent
+// unprompted. Start after end wraps past midnight.
+type QuietHours struct {
+	Start string `json:"start"` // 15:04
+	End   string `json:"end"`
+}
+
+// ModelOverride is a provider and model used instead of the global llm settings
+type ModelOverride struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// ProactivityLevels are the settings for self-scheduled check-ins, least to most
+var ProactivityLevels = []string{"off", "low", "normal", "high"}
+
+// DefaultProactivity applies to sessions that never chose a level
+const DefaultProactivity = "low"
+
 // AllowedKeys defines which config keys can be changed at runtime
 // NOTE: ollama_host is intentionally excluded - it's infrastructure config
 // that should only be set via environment variable to prevent attacks
@@ -55,6 +114,40 @@ func NewRuntimeConfig(dataDir string) (*RuntimeConfig, error) {
 	return rc, nil
 }
 
+// Path is the file the runtime config is saved to
+func (rc *RuntimeConfig) Path() string {
+	return rc.path
+}
+
+// Reload re-reads the file after it was edited outside Sheldon and reports
+// whether anything changed. A file that doesn't parse is left unapplied.
+func (rc *RuntimeConfig) Reload() (bool, error) {
+	raw, err := os.ReadFile(rc.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var data RuntimeData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return false, fmt.Errorf("parse %s: %w", filepath.Base(rc.path), err)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	// our own saves trigger a reload too, those change nothing
+	before, _ := json.Marshal(rc.data)
+	after, _ := json.Marshal(data)
+	if string(before) == string(after) {
+		return false, nil
+	}
+	rc.data = data
+	return true, nil
+}
+
 // validateAndFix checks for invalid model configurations and resets them
 func (rc *RuntimeConfig) validateAndFix() {
 	changed := false
@@ -200,7 +293,8 @@ func (rc *RuntimeConfig) ResetAll() error {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
 
-	rc.data = RuntimeData{}
+	// remote hosts are a registry and probes a cache, not overrides
+	rc.data = RuntimeData{RemoteHosts: rc.data.RemoteHosts, ModelProbes: rc.data.ModelProbes}
 	return rc.save()
 }
 
@@ -243,6 +337,190 @@ func (rc *RuntimeConfig) Overrides() map[string]string {
 	return result
 }
 
+// RemoteHosts returns the homelab-agents added at runtime
+func (rc *RuntimeConfig) RemoteHosts() map[string]string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	hosts := make(map[string]string, len(rc.data.RemoteHosts))
+	for name, url := range rc.data.RemoteHosts {
+		hosts[name] = url
+	}
+	return hosts
+}
+
+// SetRemoteHost adds or replaces a homelab-agent
+func (rc *RuntimeConfig) SetRemoteHost(name, url string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.data.RemoteHosts == nil {
+		rc.data.RemoteHosts = make(map[string]string)
+	}
+	rc.data.RemoteHosts[name] = url
+	return rc.save()
+}
+
+// RemoveRemoteHost forgets a homelab-agent added at runtime
+func (rc *RuntimeConfig) RemoveRemoteHost(name string) (bool, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, ok := rc.data.RemoteHosts[name]; !ok {
+		return false, nil
+	}
+	delete(rc.data.RemoteHosts, name)
+	return true, rc.save()
+}
+
+// SessionModel returns the chat model override for a session, if it has one
+func (rc *RuntimeConfig) SessionModel(sessionID string) (ModelOverride, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	override, ok := rc.data.SessionModels[sessionID]
+	return override, ok
+}
+
+// SetSessionModel makes one session use a different chat model than everyone else
+func (rc *RuntimeConfig) SetSessionModel(sessionID string, override ModelOverride) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.data.SessionModels == nil {
+		rc.data.SessionModels = make(map[string]ModelOverride)
+	}
+	rc.data.SessionModels[sessionID] = override
+	return rc.save()
+}
+
+// ClearSessionModel puts a session back on the global chat model
+func (rc *RuntimeConfig) ClearSessionModel(sessionID string) (bool, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, ok := rc.data.SessionModels[sessionID]; !ok {
+		return false, nil
+	}
+	delete(rc.data.SessionModels, sessionID)
+	return true, rc.save()
+}
+
+// SessionPersona returns the persona a session switched to, if any
+func (rc *RuntimeConfig) SessionPersona(sessionID string) (string, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	name, ok := rc.data.SessionPersonas[sessionID]
+	return name, ok
+}
+
+// SetSessionPersona makes one session use a persona instead of SOUL.md
+func (rc *RuntimeConfig) SetSessionPersona(sessionID, name string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.data.SessionPersonas == nil {
+		rc.data.SessionPersonas = make(map[string]string)
+	}
+	rc.data.SessionPersonas[sessionID] = name
+	return rc.save()
+}
+
+// ClearSessionPersona puts a session back on the default persona
+func (rc *RuntimeConfig) ClearSessionPersona(sessionID string) (bool, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, ok := rc.data.SessionPersonas[sessionID]; !ok {
+		return false, nil
+	}
+	delete(rc.data.SessionPersonas, sessionID)
+	return true, rc.save()
+}
+
+// SessionBufferSize returns how many recent messages a session keeps, if it
+// set its own size
+func (rc *RuntimeConfig) SessionBufferSize(sessionID string) (int, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	size, ok := rc.data.SessionBufferSizes[sessionID]
+	return size, ok
+}
+
+// SetSessionBufferSize overrides the conversation buffer size for one session
+func (rc *RuntimeConfig) SetSessionBufferSize(sessionID string, size int) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.data.SessionBufferSizes == nil {
+		rc.data.SessionBufferSizes = make(map[string]int)
+	}
+	rc.data.SessionBufferSizes[sessionID] = size
+	return rc.save()
+}
+
+// ClearSessionBufferSize puts a session back on the deployment's buffer size
+func (rc *RuntimeConfig) ClearSessionBufferSize(sessionID string) (bool, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, ok := rc.data.SessionBufferSizes[sessionID]; !ok {
+		return false, nil
+	}
+	delete(rc.data.SessionBufferSizes, sessionID)
+	return true, rc.save()
+}
+
+// ModelProbe returns the cached capability test for a model, if it was probed
+func (rc *RuntimeConfig) ModelProbe(provider, model string) (ModelProbe, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	probe, ok := rc.data.ModelProbes[provider+"/"+model]
+	return probe, ok
+}
+
+// ModelProbes returns every cached capability test
+func (rc *RuntimeConfig) ModelProbes() []ModelProbe {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	probes := make([]ModelProbe, 0, len(rc.data.ModelProbes))
+	for _, p := range rc.data.ModelProbes {
+		probes = append(probes, p)
+	}
+	return probes
+}
+
+// SetModelProbe caches a capability test so it survives restarts
+func (rc *RuntimeConfig) SetModelProbe(probe ModelProbe) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.data.ModelProbes == nil {
+		rc.data.ModelProbes = make(map[string]ModelProbe)
+	}
+	rc.data.ModelProbes[probe.Provider+"/"+probe.Model] = probe
+	return rc.save()
+}
+
+// Capabilities lists the probed capabilities in the ModelInfo format
+func (p ModelProbe) Capabilities() []string {
+	caps := []string{}
+	if p.Chat {
+		caps = append(caps, "chat")
+	}
+	if p.Tools {
+		caps = append(caps, "tools")
+	}
+	if p.Vision {
+		caps = append(caps, "vision")
+	}
+	return caps
+}
+
 func (rc *RuntimeConfig) save() error {
 	data, err := json.MarshalIndent(rc.data, "", "  ")
 	if err != nil {
@@ -250,3 +528,168 @@ func (rc *RuntimeConfig) save() error {
 	}
 	return os.WriteFile(rc.path, data, 0644)
 }
+
+// SessionProactivity returns how eagerly a session wants check-ins scheduled
+// for it, DefaultProactivity if it never chose
+func (rc *RuntimeConfig) SessionProactivity(sessionID string) string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	if level, ok := rc.data.SessionProactivity[sessionID]; ok {
+		return level
+	}
+	return DefaultProactivity
+}
+
+// SetSessionProactivity stores a session's check-in level
+func (rc *RuntimeConfig) SetSessionProactivity(sessionID, level string) error {
+	if !slices.Contains(ProactivityLevels, level) {
+		return fmt.Errorf("unknown proactivity level %q", level)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.data.SessionProactivity == nil {
+		rc.data.SessionProactivity = make(map[string]string)
+	}
+	rc.data.SessionProactivity[sessionID] = level
+	return rc.save()
+}
+
+// SessionMediaArchive reports whether a session opted in to archiving the
+// photos and files it sends
+func (rc *RuntimeConfig) SessionMediaArchive(sessionID string) bool {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.data.SessionMediaArchive[sessionID]
+}
+
+// SetSessionMediaArchive turns media archiving on or off for a session
+func (rc *RuntimeConfig) SetSessionMediaArchive(sessionID string, on bool) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if !on {
+		delete(rc.data.SessionMediaArchive, sessionID)
+		return rc.save()
+	}
+	if rc.data.SessionMediaArchive == nil {
+		rc.data.SessionMediaArchive = make(map[string]bool)
+	}
+	rc.data.SessionMediaArchive[sessionID] = true
+	return rc.save()
+}
+
+// Until returns when the quiet window around now ends, if now falls inside it
+func (q QuietHours) Until(now time.Time) (time.Time, bool) {
+	start, err := time.Parse("15:04", q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse("15:04", q.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	s := start.Hour()*60 + start.Minute()
+	e := end.Hour()*60 + end.Minute()
+	m := now.Hour()*60 + now.Minute()
+
+	inside := s < e && m >= s && m < e || s > e && (m >= s || m < e)
+	if !inside {
+		return time.Time{}, false
+	}
+
+	until := time.Date(now.Year(), now.Month(), now.Day(), end.Hour(), end.Minute(), 0, 0, now.Location())
+	if !until.After(now) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// QuietHours returns the daily quiet window, if one is set
+func (rc *RuntimeConfig) QuietHours() (QuietHours, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	if rc.data.QuietHours == nil {
+		return QuietHours{}, false
+	}
+	return *rc.data.QuietHours, true
+}
+
+// SetQuietHours sets the daily quiet window, both ends as 15:04
+func (rc *RuntimeConfig) SetQuietHours(start, end string) error {
+	for _, t := range []string{start, end} {
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("invalid time %q, use HH:MM", t)
+		}
+	}
+	if start == end {
+		return fmt.Errorf("quiet hours must start and end at different times")
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.data.QuietHours = &QuietHours{Start: start, End: end}
+	return rc.save()
+}
+
+// ClearQuietHours removes the daily quiet window
+func (rc *RuntimeConfig) ClearQuietHours() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.data.QuietHours = nil
+	return rc.save()
+}
+
+// VacationUntil returns when vacation mode ends, if it is on
+func (rc *RuntimeConfig) VacationUntil() (time.Time, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	if rc.data.VacationUntil == nil || !rc.data.VacationUntil.After(time.Now()) {
+		return time.Time{}, false
+	}
+	return *rc.data.VacationUntil, true
+}
+
+// SetVacationUntil turns vacation mode on until t, or off for a zero t
+func (rc *RuntimeConfig) SetVacationUntil(t time.Time) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if t.IsZero() {
+		rc.data.VacationUntil = nil
+	} else {
+		rc.data.VacationUntil = &t
+	}
+	return rc.save()
+}
+
+// SkillGrants returns the capabilities the user granted a skill's scripts
+func (rc *RuntimeConfig) SkillGrants(skill string) []string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	return slices.Clone(rc.data.SkillGrants[skill])
+}
+
+// SetSkillGrants replaces a skill's granted capabilities, none revokes all
+func (rc *RuntimeConfig) SetSkillGrants(skill string, caps []string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if len(caps) == 0 {
+		delete(rc.data.SkillGrants, skill)
+		return rc.save()
+	}
+	if rc.data.SkillGrants == nil {
+		rc.data.SkillGrants = make(map[string][]string)
+	}
+	rc.data.SkillGrants[skill] = caps
+	return rc.save()
+}
@@ -1,11 +1,15 @@
 package config
 
+import "time"
+
 type Config struct {
 	EssencePath string
 	MemoryPath  string
+	MemoryKey   string // secret for encrypting sensitive facts and backups, empty disables encryption
 	Timezone    string
 	LLM         LLMConfig
 	Embedder    EmbedderConfig
+	Speech      SpeechConfig
 	Coder       CoderConfig
 	Browser     BrowserConfig
 	Pinchtab    PinchtabConfig
@@ -14,7 +18,24 @@ type Config struct {
 	Bot         BotConfig
 	Bots        MultiBot
 	Alert       AlertConfig
+	Notify      NotifyConfig
 	Budget      BudgetConfig
+	Backup      BackupConfig
+	Sessions    SessionConfig
+	Tracing     TracingConfig
+	Admin       AdminConfig
+	Digest      DigestConfig
+	Calendar    CalendarConfig
+	Inbox       InboxConfig
+	Feeds       FeedsConfig
+	Geo         GeoConfig
+	Sandbox     SandboxConfig
+	Agent       HomelabAgentConfig
+	SSHHosts    string // YAML file of allowlisted SSH hosts, empty disables run_remote_command
+
+	SkillIndexes []string // HTTPS skills index files search_skills looks in (SKILLS_INDEX_URLS)
+
+	DisabledTools []string // tools hidden from the model (DISABLED_TOOLS), applied on reload too
 }
 
 type BrowserConfig struct {
@@ -22,6 +43,23 @@ type BrowserConfig struct {
 	Image          string // browser sandbox image (default: sheldon-browser-sandbox:latest)
 }
 
+// SandboxConfig hardens the coder and browser sandbox containers
+type SandboxConfig struct {
+	Runtime         string // OCI runtime: runsc/gvisor or kata; empty uses the engine default
+	SeccompProfile  string // custom seccomp profile path; empty keeps the engine default
+	NoNewPrivileges bool   // default: true
+}
+
+// HomelabAgentConfig is how Sheldon authenticates to homelab-agents
+type HomelabAgentConfig struct {
+	Token     string // bearer token shared with the agents' AGENT_TOKEN
+	CAFile    string // CA that signed the agents' TLS certs; set to talk HTTPS
+	CertFile  string // client cert for agents that require mTLS
+	KeyFile   string
+	AlertAddr string            // listen address for agent alert webhooks, empty disables
+	Hosts     map[string]string // named agents from HOMELAB_HOSTS (nas=http://nas:8080,...)
+}
+
 type PinchtabConfig struct {
 	Enabled bool
 	URL     string // pinchtab server URL (default: http://pinchtab:9867)
@@ -34,6 +72,17 @@ type DeployerConfig struct {
 	PathPrefix   string // container path prefix (e.g., /data)
 	HostPrefix   string // host path prefix (e.g., /opt/sheldon/data)
 	Network      string // docker network name
+	Target       string // compose or kubernetes (default: compose)
+	Kubernetes   KubernetesConfig
+
+	UpdateInterval time.Duration // how often auto-updating apps check their branch (default: 5m)
+}
+
+type KubernetesConfig struct {
+	Namespace    string // namespace for apps (default: sheldon-apps)
+	Registry     string // registry images are pushed to; empty = cluster shares the local docker image store
+	IngressClass string // default: traefik
+	CertIssuer   string // cert-manager ClusterIssuer for HTTPS, empty = HTTP only
 }
 
 type StorageConfig struct {
@@ -42,8 +91,12 @@ type StorageConfig struct {
 	PublicEndpoint string // public endpoint for shareable URLs (e.g., s3.example.com)
 	AccessKey      string
 	SecretKey      string
-	UseSSL         bool // SSL for internal endpoint
-	PublicUseSSL   bool // SSL for public endpoint (typically true if using Traefik)
+	UseSSL         bool  // SSL for internal endpoint
+	PublicUseSSL   bool  // SSL for public endpoint (typically true if using Traefik)
+	UserQuota      int64 // bytes the user bucket may hold, 0 = unlimited
+	AgentQuota     int64 // bytes the agent bucket may hold, 0 = unlimited
+	UserPrune      bool  // delete the oldest user files to make room (default: false)
+	AgentPrune     bool  // delete the oldest agent files to make room (default: true)
 }
 
 type CoderConfig struct {
@@ -55,7 +108,27 @@ type CoderConfig struct {
 	SkillsDir      string // directory with skill patterns
 	Isolated       bool   // use ephemeral Docker containers for isolation
 	Image          string // coder container image (default: sheldon-coder-sandbox:latest)
+	Verify         bool   // run detected tests/vet/lint after the coder finishes (default: true)
+	FixAttempts    int    // times the coder is asked to fix failing checks (default: 2)
 	Git            GitConfig
+	Runtime        ContainerRuntimeConfig
+	Limits         CoderLimitsConfig
+}
+
+type CoderLimitsConfig struct {
+	CPUs             string // per container (default: 2)
+	Memory           string // per container, swap capped to match (default: 4g)
+	Pids             int    // per container (default: 512)
+	Disk             string // container rootfs size, needs overlay2 on xfs (default: unlimited)
+	WorkspaceQuotaMB int    // max workspace size per task (default: 2048)
+}
+
+type ContainerRuntimeConfig struct {
+	Binary   string // docker or podman (default: detected)
+	Network  string // network coder containers join (default: sheldon-net)
+	UID      int    // coder user inside the image (default: 1000)
+	GID      int
+	Rootless *bool // nil = detect from the runtime
 }
 
 type GitConfig struct {
@@ -64,13 +137,25 @@ type GitConfig struct {
 	UserEmail string // git commit author email
 	Token     string // GitHub PAT for pushing
 	OrgURL    string // base URL for org repos (e.g., https://github.com/myorg)
+
+	ReviewPushes     bool // ask the user to approve the diff before pushing (default: true)
+	AutoApproveLines int  // diffs with at most this many changed lines push without asking (default: 0)
 }
 
 type LLMConfig struct {
-	Provider string
-	APIKey   string
-	Model    string
-	BaseURL  string
+	Provider    string
+	APIKey      string
+	Model       string
+	BaseURL     string
+	APIVersion  string // Azure OpenAI api-version
+	MaxAttempts int    // tries per request on transient errors (LLM_MAX_ATTEMPTS)
+	LogFile     string // JSON lines log of every request (LLM_LOG_FILE), empty disables
+	LogMaxMB    int    // log size before rotating (LLM_LOG_MAX_MB, default 50)
+
+	// optional second model that scores replies for quality
+	EvalProvider string  // EVAL_PROVIDER, defaults to Provider
+	EvalModel    string  // EVAL_MODEL, empty disables evaluation
+	EvalSample   float64 // share of replies scored (EVAL_SAMPLE_RATE, default 1)
 }
 
 type EmbedderConfig struct {
@@ -79,6 +164,72 @@ type EmbedderConfig struct {
 	Model    string
 }
 
+type DigestConfig struct {
+	Frequency string // "daily" or "weekly", empty disables digests
+	Hour      int    // local hour to send at (default: 8)
+	Minute    int
+	ChatID    int64 // chat to deliver to (default: OWNER_CHAT_ID)
+}
+
+type CalendarConfig struct {
+	Provider string // caldav or google, empty disables calendar tools
+
+	// CalDAV: URL is the calendar collection (e.g. https://cloud.example.com/remote.php/dav/calendars/me/personal/)
+	URL      string
+	Username string
+	Password string
+
+	// Google: OAuth client plus a long-lived refresh token with calendar scope
+	CalendarID   string // default: primary
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+}
+
+// InboxConfig is the user's own mailbox, read and written by the email tools.
+// It is separate from the email channel, which is Sheldon's address.
+type InboxConfig struct {
+	Enabled        bool
+	IMAPAddr       string   // host:port for IMAP over TLS
+	SMTPAddr       string   // host:port for SMTP (587 STARTTLS or 465 TLS)
+	Username       string   // login for both IMAP and SMTP
+	Password       string   // app password
+	From           string   // address mail is sent from (default: Username)
+	AllowedSenders []string // if set, only mail from these addresses is read
+	AuthservID     string   // receiving server whose Authentication-Results are trusted
+}
+
+// FeedsConfig controls how often subscribed RSS and Atom feeds are checked
+type FeedsConfig struct {
+	PollInterval time.Duration // default: 30m
+}
+
+// GeoConfig points the location tools at Nominatim (geocoding) and OSRM (routing)
+type GeoConfig struct {
+	Enabled      bool
+	NominatimURL string // default: public nominatim.openstreetmap.org
+	OSRMURL      string // default: public router.project-osrm.org (driving only)
+}
+
+type AdminConfig struct {
+	Token string // bearer token, empty disables the admin API
+	Addr  string // listen address (default: :8082)
+}
+
+type TracingConfig struct {
+	Endpoint    string            // OTLP/HTTP collector URL, empty disables tracing
+	ServiceName string            // service.name resource attribute
+	Headers     map[string]string // extra export headers (auth for hosted collectors)
+}
+
+type SpeechConfig struct {
+	Provider string // openai, whispercpp, or empty to disable voice transcription
+	APIKey   string
+	BaseURL  string
+	Model    string
+	Language string
+}
+
 type BotConfig struct {
 	Provider string
 	Token    string
@@ -87,6 +238,8 @@ type BotConfig struct {
 type MultiBot struct {
 	Telegram BotInstance
 	Discord  BotInstance
+	Web      BotInstance
+	Email    EmailBotConfig
 }
 
 type BotInstance struct {
@@ -96,6 +249,21 @@ type BotInstance struct {
 	GuildID        string // Discord: restrict to this guild/server ID
 	OwnerID        string // Discord: user ID with full access (sensitive facts)
 	TrustedChannel string // Discord: channel ID with full access (alternative to OwnerID)
+	Addr           string // Web: listen address for the chat gateway (e.g., :8081)
+}
+
+// EmailBotConfig configures the IMAP/SMTP email channel
+type EmailBotConfig struct {
+	Enabled        bool
+	IMAPAddr       string        // host:port for IMAP over TLS (e.g., imap.gmail.com:993)
+	SMTPAddr       string        // host:port for SMTP (587 STARTTLS or 465 TLS)
+	Username       string        // login for both IMAP and SMTP
+	Password       string        // app password
+	From           string        // address replies are sent from (default: Username)
+	AllowedSenders []string      // addresses Sheldon will answer (empty = owner only)
+	OwnerAddress   string        // address with full access (sensitive facts)
+	AuthservID     string        // receiving server whose Authentication-Results are trusted
+	PollInterval   time.Duration // how often to check the inbox
 }
 
 // AlertConfig specifies where to send system alerts (budget warnings, errors)
@@ -103,8 +271,59 @@ type AlertConfig struct {
 	ChatID int64 // telegram chat ID for alerts
 }
 
+// NotifySinks are the places a notification can go; chat is the bot
+var NotifySinks = []string{"chat", "ntfy", "gotify", "pushover", "webhook"}
+
+// NotifyKinds are the notifications that can be routed separately
+var NotifyKinds = []string{"budget", "cron", "alerts", "backup"}
+
+// NotifyConfig sets up push services besides the chat bots and which kinds of
+// notification go to which of them
+type NotifyConfig struct {
+	NtfyURL       string // topic URL, e.g. https://ntfy.sh/my-sheldon
+	NtfyToken     string
+	GotifyURL     string
+	GotifyToken   string // application token
+	PushoverToken string // application API token
+	PushoverUser  string // user or group key
+	WebhookURL    string // receives a JSON POST per notification
+
+	// kind -> sinks, every kind goes to chat unless NOTIFY_<KIND> says otherwise
+	Routes map[string][]string
+}
+
+// Configured reports whether a sink has what it needs to send
+func (n NotifyConfig) Configured(sink string) bool {
+	switch sink {
+	case "chat":
+		return true
+	case "ntfy":
+		return n.NtfyURL != ""
+	case "gotify":
+		return n.GotifyURL != "" && n.GotifyToken != ""
+	case "pushover":
+		return n.PushoverToken != "" && n.PushoverUser != ""
+	case "webhook":
+		return n.WebhookURL != ""
+	}
+	return false
+}
+
+// SessionConfig bounds the in-memory chat sessions
+type SessionConfig struct {
+	BufferSize  int           // recent messages reloaded per chat (default: 12), set_context_size overrides it per chat
+	IdleTTL     time.Duration // evict sessions idle this long (default: 6h, 0 disables)
+	MaxSessions int           // keep at most this many, least recently used go first (default: 200, 0 = unlimited)
+}
+
 type BudgetConfig struct {
 	Enabled    bool
 	DailyLimit int     // max tokens per day (0 = unlimited)
 	WarnAt     float64 // warn at this percentage (0.8 = 80%)
 }
+
+// BackupConfig schedules memory backups to the backups bucket (needs storage)
+type BackupConfig struct {
+	Interval time.Duration // time between backups (default: 24h, 0 disables)
+	Keep     int           // scheduled backups kept, the oldest are deleted (default: 7)
+}
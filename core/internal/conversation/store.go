@@ -2,6 +2,7 @@ package conversation
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -13,9 +14,13 @@ type Message struct {
 	CreatedAt time.Time
 }
 
+// LimitFunc returns a session's own buffer size, if it has one
+type LimitFunc func(sessionID string) (int, bool)
+
 type Store struct {
 	db          *sql.DB
 	maxMessages int
+	limit       LimitFunc
 }
 
 const schema = `
@@ -28,6 +33,12 @@ CREATE TABLE IF NOT EXISTS recent_messages (
 );
 
 CREATE INDEX IF NOT EXISTS idx_recent_messages_session ON recent_messages(session_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS conversation_summaries (
+    session_id TEXT PRIMARY KEY,
+    summary TEXT NOT NULL,
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
 `
 
 // NewStore creates a conversation buffer using the provided database connection
@@ -42,6 +53,21 @@ func NewStore(db *sql.DB, maxMessages int) (*Store, error) {
 	return s, nil
 }
 
+// SetLimitFunc lets sessions keep more or fewer messages than the default
+func (s *Store) SetLimitFunc(fn LimitFunc) {
+	s.limit = fn
+}
+
+// MaxMessages returns how many recent messages are kept for a session
+func (s *Store) MaxMessages(sessionID string) int {
+	if s.limit != nil {
+		if n, ok := s.limit(sessionID); ok && n > 0 {
+			return n
+		}
+	}
+	return s.maxMessages
+}
+
 func (s *Store) migrate() error {
 	// Check if old schema exists (chat_id column)
 	var hasOldSchema bool
@@ -78,6 +104,7 @@ type AddResult struct {
 
 func (s *Store) Add(sessionID string, role, content string) (*AddResult, error) {
 	result := &AddResult{}
+	maxMessages := s.MaxMessages(sessionID)
 
 	// First, check if we'll overflow and capture those messages
 	rows, err := s.db.Query(`
@@ -86,7 +113,7 @@ func (s *Store) Add(sessionID string, role, content string) (*AddResult, error)
 		WHERE session_id = ?
 		ORDER BY created_at ASC
 		LIMIT ?`,
-		sessionID, s.maxMessages)
+		sessionID, maxMessages)
 	if err != nil {
 		return nil, err
 	}
@@ -105,8 +132,8 @@ func (s *Store) Add(sessionID string, role, content string) (*AddResult, error)
 	rows.Close()
 
 	// If buffer is full, the oldest messages will be evicted
-	if len(existing) >= s.maxMessages {
-		evictCount := len(existing) - s.maxMessages + 2 // +2 for incoming user+assistant
+	if len(existing) >= maxMessages {
+		evictCount := len(existing) - maxMessages + 2 // +2 for incoming user+assistant
 		if evictCount > 0 && evictCount <= len(existing) {
 			result.Overflow = existing[:evictCount]
 		}
@@ -129,18 +156,21 @@ func (s *Store) Add(sessionID string, role, content string) (*AddResult, error)
 			WHERE session_id = ?
 			ORDER BY created_at DESC
 			LIMIT ?
-		)`, sessionID, sessionID, s.maxMessages)
+		)`, sessionID, sessionID, maxMessages)
 
 	return result, err
 }
 
 func (s *Store) GetRecent(sessionID string) ([]Message, error) {
+	// newest messages first so a shrunk buffer keeps the latest, then oldest first
 	rows, err := s.db.Query(`
-		SELECT role, content, created_at
-		FROM recent_messages
-		WHERE session_id = ?
-		ORDER BY created_at ASC
-		LIMIT ?`, sessionID, s.maxMessages)
+		SELECT role, content, created_at FROM (
+			SELECT id, role, content, created_at
+			FROM recent_messages
+			WHERE session_id = ?
+			ORDER BY id DESC
+			LIMIT ?
+		) ORDER BY id ASC`, sessionID, s.MaxMessages(sessionID))
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +191,45 @@ func (s *Store) GetRecent(sessionID string) ([]Message, error) {
 }
 
 func (s *Store) Clear(sessionID string) error {
-	_, err := s.db.Exec(`DELETE FROM recent_messages WHERE session_id = ?`, sessionID)
+	if _, err := s.db.Exec(`DELETE FROM recent_messages WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM conversation_summaries WHERE session_id = ?`, sessionID)
 	return err
 }
+
+// SaveSummary stores the rolling summary of history compacted out of a session
+func (s *Store) SaveSummary(sessionID, summary string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO conversation_summaries (session_id, summary, updated_at)
+		VALUES (?, ?, datetime('now'))
+		ON CONFLICT(session_id) DO UPDATE SET summary = excluded.summary, updated_at = excluded.updated_at`,
+		sessionID, summary)
+	return err
+}
+
+// GetSummary returns the stored summary for a session, or "" if none
+func (s *Store) GetSummary(sessionID string) (string, error) {
+	var summary string
+	err := s.db.QueryRow(`SELECT summary FROM conversation_summaries WHERE session_id = ?`, sessionID).Scan(&summary)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return summary, err
+}
+
+// FindSession returns the most recently active session ID for a chat ID,
+// or "" if the chat has no buffered messages on any provider
+func (s *Store) FindSession(chatID int64) (string, error) {
+	var sessionID string
+	err := s.db.QueryRow(`
+		SELECT session_id
+		FROM recent_messages
+		WHERE session_id LIKE ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`, fmt.Sprintf("%%:%d", chatID)).Scan(&sessionID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return sessionID, err
+}
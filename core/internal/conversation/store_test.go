@@ -185,3 +185,102 @@ func TestStoreDefaultMaxMessages(t *testing.T) {
 		t.Errorf("expected default %d messages, got %d", defaultMaxMessages, len(messages))
 	}
 }
+
+func TestStoreFindSession(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewStore(db, 10)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	store.Add("telegram:9123", "user", "hello")
+	store.Add("discord:123", "user", "hello")
+
+	sessionID, err := store.FindSession(123)
+	if err != nil {
+		t.Fatalf("failed to find session: %v", err)
+	}
+	if sessionID != "discord:123" {
+		t.Errorf("expected discord:123, got %q", sessionID)
+	}
+
+	sessionID, err = store.FindSession(456)
+	if err != nil {
+		t.Fatalf("failed to find session: %v", err)
+	}
+	if sessionID != "" {
+		t.Errorf("expected no session, got %q", sessionID)
+	}
+}
+
+func TestStoreSummary(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewStore(db, 10)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	sessionID := "telegram:123"
+
+	summary, err := store.GetSummary(sessionID)
+	if err != nil || summary != "" {
+		t.Fatalf("expected empty summary, got %q (err %v)", summary, err)
+	}
+
+	store.SaveSummary(sessionID, "first")
+	store.SaveSummary(sessionID, "second")
+
+	summary, _ = store.GetSummary(sessionID)
+	if summary != "second" {
+		t.Errorf("expected latest summary, got %q", summary)
+	}
+
+	store.Clear(sessionID)
+	summary, _ = store.GetSummary(sessionID)
+	if summary != "" {
+		t.Errorf("expected summary cleared, got %q", summary)
+	}
+}
+
+func TestStoreSessionLimit(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewStore(db, 10)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	store.SetLimitFunc(func(sessionID string) (int, bool) {
+		return 4, sessionID == "telegram:small"
+	})
+
+	for _, id := range []string{"telegram:small", "telegram:big"} {
+		for i := 0; i < 8; i++ {
+			if _, err := store.Add(id, "user", "message"); err != nil {
+				t.Fatalf("failed to add message: %v", err)
+			}
+		}
+	}
+
+	if got := store.MaxMessages("telegram:small"); got != 4 {
+		t.Errorf("MaxMessages(small) = %d, want 4", got)
+	}
+	small, _ := store.GetRecent("telegram:small")
+	big, _ := store.GetRecent("telegram:big")
+	if len(small) != 4 || len(big) != 8 {
+		t.Errorf("kept %d and %d messages, want 4 and 8", len(small), len(big))
+	}
+}
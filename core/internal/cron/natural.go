@@ -0,0 +1,415 @@
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// naturalSchedule is a schedule written as a phrase, like "every weekday at 8:30"
+// or "first monday of the month at 10am"
+type naturalSchedule struct {
+	interval time.Duration  // "every 15 minutes", zero for calendar schedules
+	hour     int            // time of day for calendar schedules
+	minute   int            //
+	weekdays []time.Weekday // days of the week, empty means every day
+	monthDay int            // day of the month, 0 if unset
+	nth      int            // nth weekday of the month, -1 for the last, 0 if unset
+}
+
+var (
+	ampmTime   = regexp.MustCompile(`(?:\bat\s+)?\b(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b`)
+	clockTime  = regexp.MustCompile(`(?:\bat\s+)?\b(\d{1,2}):(\d{2})\b`)
+	namedTime  = regexp.MustCompile(`(?:\bat\s+)?\b(noon|midday|midnight)\b`)
+	hourTime   = regexp.MustCompile(`\bat\s+(\d{1,2})\b`)
+	cronField  = regexp.MustCompile(`^[\d*/,?-]+$`)
+	intervalRe = regexp.MustCompile(`^(?:every|each|in)\s+(\d+|an?|one)?\s*(seconds?|secs?|minutes?|mins?|hours?|hrs?)$`)
+	ordinalRe  = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)$`)
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var ordinalWords = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "last": -1}
+
+// words that carry no meaning of their own in a schedule phrase
+var fillerWords = map[string]bool{"every": true, "each": true, "on": true, "the": true, "of": true, "and": true, "a": true, "at": true, "in": true}
+
+// ParseSchedule accepts cron syntax ("0 30 8 * * 1-5", "@every 10m") or a phrase
+// such as "every weekday at 8:30", "every 15 minutes", "mondays and thursdays at 7pm",
+// "on the 15th of every month" or "first monday of the month at 10am"
+func ParseSchedule(schedule string) (cron.Schedule, error) {
+	schedule = strings.TrimSpace(schedule)
+	sched, err := cronParser.Parse(schedule)
+	if err == nil {
+		return sched, nil
+	}
+	if fields := strings.Fields(schedule); strings.HasPrefix(schedule, "@") || len(fields) == 6 && cronField.MatchString(fields[0]) {
+		return nil, fmt.Errorf("invalid cron schedule '%s': %w", schedule, err)
+	}
+
+	n, err := parseNatural(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': %w", schedule, err)
+	}
+	if n.nth != 0 {
+		return n, nil
+	}
+	return cronParser.Parse(n.spec())
+}
+
+// Describe renders a schedule in plain words for echoing back to the user,
+// falling back to the schedule itself when it has no simple description
+func Describe(schedule string) string {
+	schedule = strings.TrimSpace(schedule)
+	if n, ok := fromCron(schedule); ok {
+		return n.String()
+	}
+	if _, err := cronParser.Parse(schedule); err == nil {
+		return schedule
+	}
+	if n, err := parseNatural(schedule); err == nil {
+		return n.String()
+	}
+	return schedule
+}
+
+func parseNatural(phrase string) (naturalSchedule, error) {
+	s := strings.ToLower(strings.TrimSpace(phrase))
+	s = strings.NewReplacer("a.m.", "am", "p.m.", "pm", ",", " ", ".", " ", "-", " to ").Replace(s)
+	s = strings.Join(strings.Fields(s), " ")
+
+	n := naturalSchedule{hour: 9}
+	if s == "hourly" {
+		n.interval = time.Hour
+		return n, nil
+	}
+	if m := intervalRe.FindStringSubmatch(s); m != nil {
+		count := 1
+		if v, err := strconv.Atoi(m[1]); err == nil {
+			count = v
+		}
+		if count <= 0 {
+			return n, fmt.Errorf("interval must be positive")
+		}
+		unit := time.Hour
+		switch m[2][0] {
+		case 's':
+			unit = time.Second
+		case 'm':
+			unit = time.Minute
+		}
+		n.interval = time.Duration(count) * unit
+		return n, nil
+	}
+
+	s, hasTime, err := n.takeTime(s)
+	if err != nil {
+		return n, err
+	}
+
+	var ordinal int
+	var monthly, daily bool
+	words := strings.Fields(s)
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		switch {
+		case fillerWords[w]:
+		case w == "day" || w == "days" || w == "daily" || w == "everyday":
+			daily = true
+		case w == "month" || w == "months" || w == "monthly":
+			monthly = true
+		case w == "weekday" || w == "weekdays":
+			n.weekdays = append(n.weekdays, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		case w == "weekend" || w == "weekends":
+			n.weekdays = append(n.weekdays, time.Saturday, time.Sunday)
+		case (w == "to" || w == "through" || w == "thru") && len(n.weekdays) > 0 && i+1 < len(words):
+			to, ok := parseWeekday(words[i+1])
+			if !ok {
+				return n, fmt.Errorf("don't understand %q", w+" "+words[i+1])
+			}
+			for d := n.weekdays[len(n.weekdays)-1] + 1; d%7 != to; d++ {
+				n.weekdays = append(n.weekdays, d%7)
+			}
+			n.weekdays = append(n.weekdays, to)
+			i++
+		default:
+			if d, ok := parseWeekday(w); ok {
+				n.weekdays = append(n.weekdays, d)
+			} else if v, ok := ordinalWords[w]; ok && ordinal == 0 {
+				ordinal = v
+			} else if m := ordinalRe.FindStringSubmatch(w); m != nil && ordinal == 0 {
+				ordinal, _ = strconv.Atoi(m[1])
+			} else {
+				return n, fmt.Errorf("don't understand %q", w)
+			}
+		}
+	}
+
+	switch {
+	case ordinal != 0 && len(n.weekdays) == 1:
+		if ordinal > 4 {
+			return n, fmt.Errorf("a weekday can only be the first to fourth or last of the month")
+		}
+		n.nth = ordinal
+	case ordinal != 0 && len(n.weekdays) > 1:
+		return n, fmt.Errorf("pick one weekday for a monthly schedule")
+	case ordinal == -1:
+		return n, fmt.Errorf("the last day of the month isn't supported, use the 28th")
+	case ordinal != 0:
+		if ordinal > 31 {
+			return n, fmt.Errorf("day of the month must be 1-31")
+		}
+		n.monthDay = ordinal
+	case monthly:
+		n.monthDay = 1
+	case len(n.weekdays) == 0 && !daily && !hasTime:
+		return n, fmt.Errorf("say when, e.g. \"every day at 8pm\" or \"every 30 minutes\"")
+	}
+
+	return n, nil
+}
+
+// takeTime pulls the time of day out of a phrase
+func (n *naturalSchedule) takeTime(s string) (string, bool, error) {
+	found := 0
+
+	s = ampmTime.ReplaceAllStringFunc(s, func(match string) string {
+		m := ampmTime.FindStringSubmatch(match)
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour < 1 || hour > 12 || minute > 59 {
+			found = -100
+			return ""
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+		n.hour, n.minute = hour, minute
+		found++
+		return " "
+	})
+	s = clockTime.ReplaceAllStringFunc(s, func(match string) string {
+		m := clockTime.FindStringSubmatch(match)
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			found = -100
+			return ""
+		}
+		n.hour, n.minute = hour, minute
+		found++
+		return " "
+	})
+	s = hourTime.ReplaceAllStringFunc(s, func(match string) string {
+		hour, _ := strconv.Atoi(hourTime.FindStringSubmatch(match)[1])
+		if hour > 23 {
+			found = -100
+			return ""
+		}
+		n.hour, n.minute = hour, 0
+		found++
+		return " "
+	})
+	s = namedTime.ReplaceAllStringFunc(s, func(match string) string {
+		n.hour, n.minute = 12, 0
+		if strings.HasSuffix(match, "midnight") {
+			n.hour = 0
+		}
+		found++
+		return " "
+	})
+
+	if found < 0 {
+		return s, false, fmt.Errorf("invalid time of day")
+	}
+	if found > 1 {
+		return s, false, fmt.Errorf("only one time of day per schedule, set separate triggers for more")
+	}
+	return s, found == 1, nil
+}
+
+func parseWeekday(w string) (time.Weekday, bool) {
+	d, ok := weekdayNames[w]
+	if !ok {
+		d, ok = weekdayNames[strings.TrimSuffix(w, "s")]
+	}
+	return d, ok
+}
+
+// spec is the cron expression for schedules cron syntax can express
+func (n naturalSchedule) spec() string {
+	if n.interval > 0 {
+		return "@every " + n.interval.String()
+	}
+	dom, dow := "*", "*"
+	if n.monthDay > 0 {
+		dom = strconv.Itoa(n.monthDay)
+	}
+	if len(n.weekdays) > 0 {
+		days := make([]string, len(n.weekdays))
+		for i, d := range n.weekdays {
+			days[i] = strconv.Itoa(int(d))
+		}
+		dow = strings.Join(days, ",")
+	}
+	return fmt.Sprintf("0 %d %d %s * %s", n.minute, n.hour, dom, dow)
+}
+
+// Next implements cron.Schedule for nth-weekday schedules, which cron syntax
+// can't express
+func (n naturalSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < 14; i++ {
+		first := time.Date(t.Year(), t.Month()+time.Month(i), 1, 0, 0, 0, 0, t.Location())
+		day := n.nthWeekday(first)
+		next := time.Date(first.Year(), first.Month(), day, n.hour, n.minute, 0, 0, t.Location())
+		if next.After(t) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func (n naturalSchedule) nthWeekday(first time.Time) int {
+	want := n.weekdays[0]
+	if n.nth == -1 {
+		last := first.AddDate(0, 1, -1)
+		return last.Day() - (int(last.Weekday())-int(want)+7)%7
+	}
+	return 1 + (int(want)-int(first.Weekday())+7)%7 + 7*(n.nth-1)
+}
+
+func (n naturalSchedule) String() string {
+	if n.interval > 0 {
+		return "every " + describeInterval(n.interval)
+	}
+
+	at := "at " + time.Date(0, 1, 1, n.hour, n.minute, 0, 0, time.UTC).Format("3:04 PM")
+	switch {
+	case n.nth != 0:
+		nth := map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth", -1: "last"}[n.nth]
+		return fmt.Sprintf("%s %s of every month %s", nth, n.weekdays[0], at)
+	case n.monthDay > 0:
+		return fmt.Sprintf("the %s of every month %s", ordinal(n.monthDay), at)
+	case len(n.weekdays) == 0 || len(n.weekdays) == 7:
+		return "every day " + at
+	case sameDays(n.weekdays, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday):
+		return "every weekday " + at
+	case sameDays(n.weekdays, time.Saturday, time.Sunday):
+		return "every weekend day " + at
+	}
+
+	names := make([]string, len(n.weekdays))
+	for i, d := range n.weekdays {
+		names[i] = d.String()
+	}
+	if len(names) > 1 {
+		names = append(names[:len(names)-2], names[len(names)-2]+" and "+names[len(names)-1])
+	}
+	return fmt.Sprintf("every %s %s", strings.Join(names, ", "), at)
+}
+
+// fromCron reads simple cron expressions back into a naturalSchedule so they
+// can be described; anything with ranges of hours, steps or months is left alone
+func fromCron(expr string) (naturalSchedule, bool) {
+	var n naturalSchedule
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		n.interval = d
+		return n, err == nil && d > 0
+	}
+
+	f := strings.Fields(expr)
+	if len(f) != 6 || f[0] != "0" || f[4] != "*" {
+		return n, false
+	}
+	var err error
+	if n.minute, err = strconv.Atoi(f[1]); err != nil || n.minute > 59 {
+		return n, false
+	}
+	if n.hour, err = strconv.Atoi(f[2]); err != nil || n.hour > 23 {
+		return n, false
+	}
+	if f[3] != "*" {
+		if n.monthDay, err = strconv.Atoi(f[3]); err != nil || f[5] != "*" {
+			return n, false
+		}
+	}
+	if f[5] == "*" {
+		return n, true
+	}
+	for _, part := range strings.Split(f[5], ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		a, err := strconv.Atoi(from)
+		if err != nil || a > 7 {
+			return n, false
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(to); err != nil || b > 7 || b < a {
+				return n, false
+			}
+		}
+		for d := a; d <= b; d++ {
+			n.weekdays = append(n.weekdays, time.Weekday(d%7))
+		}
+	}
+	return n, true
+}
+
+func describeInterval(d time.Duration) string {
+	for _, u := range []struct {
+		size time.Duration
+		name string
+	}{{time.Hour, "hour"}, {time.Minute, "minute"}, {time.Second, "second"}} {
+		if d%u.size == 0 {
+			if count := int(d / u.size); count != 1 {
+				return fmt.Sprintf("%d %ss", count, u.name)
+			}
+			return u.name
+		}
+	}
+	return d.String()
+}
+
+func sameDays(days []time.Weekday, want ...time.Weekday) bool {
+	if len(days) != len(want) {
+		return false
+	}
+	seen := make(map[time.Weekday]bool, len(days))
+	for _, d := range days {
+		seen[d] = true
+	}
+	for _, d := range want {
+		if !seen[d] {
+			return false
+		}
+	}
+	return true
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Wednesday, March 4 2026 at noon
+	from := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		schedule string
+		next     string
+		describe string
+	}{
+		{"0 30 8 * * 1-5", "2026-03-05 08:30", "every weekday at 8:30 AM"},
+		{"@every 10m", "2026-03-04 12:10", "every 10 minutes"},
+		{"every 15 minutes", "2026-03-04 12:15", "every 15 minutes"},
+		{"every hour", "2026-03-04 13:00", "every hour"},
+		{"every weekday at 8:30", "2026-03-05 08:30", "every weekday at 8:30 AM"},
+		{"Daily at 8pm", "2026-03-04 20:00", "every day at 8:00 PM"},
+		{"mondays and thursdays at 7:15 p.m.", "2026-03-05 19:15", "every Monday and Thursday at 7:15 PM"},
+		{"every Mon-Wed at noon", "2026-03-09 12:00", "every Monday, Tuesday and Wednesday at 12:00 PM"},
+		{"weekends at 10", "2026-03-07 10:00", "every weekend day at 10:00 AM"},
+		{"on the 15th of every month", "2026-03-15 09:00", "the 15th of every month at 9:00 AM"},
+		{"monthly", "2026-04-01 09:00", "the 1st of every month at 9:00 AM"},
+		{"first Monday of the month at 10am", "2026-04-06 10:00", "first Monday of every month at 10:00 AM"},
+		{"last friday of every month at 17:00", "2026-03-27 17:00", "last Friday of every month at 5:00 PM"},
+	}
+
+	for _, tt := range tests {
+		sched, err := ParseSchedule(tt.schedule)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.schedule, err)
+			continue
+		}
+		if got := sched.Next(from).Format("2006-01-02 15:04"); got != tt.next {
+			t.Errorf("ParseSchedule(%q).Next = %s, want %s", tt.schedule, got, tt.next)
+		}
+		if got := Describe(tt.schedule); got != tt.describe {
+			t.Errorf("Describe(%q) = %q, want %q", tt.schedule, got, tt.describe)
+		}
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, schedule := range []string{
+		"0 99 8 * * *",
+		"@every banana",
+		"every other day",
+		"at 9am and 5pm",
+		"fifth monday of the month",
+		"last day of the month",
+		"sometime soon",
+		"at 25:00",
+	} {
+		if _, err := ParseSchedule(schedule); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error", schedule)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package cron
 
 import (
 	"database/sql"
-	"fmt"
+	"strings"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -18,12 +18,36 @@ type Cron struct {
 	PausedUntil *time.Time // temporarily paused until this time
 	NextRun     time.Time  // pre-computed next fire time
 	CreatedAt   time.Time
+
+	// Prompt replaces keyword recall with exact instructions for what to do
+	// when the cron fires; Tools limits that turn to the listed tools
+	Prompt string
+	Tools  []string
+
+	// Skill runs an installed skill's instructions when the cron fires; Prompt
+	// then adds to them
+	Skill string
+}
+
+// OneTime reports whether the cron fires only once, i.e. it expires before
+// next, the run that follows the one it is due for. Recurring crons can expire
+// too, just later.
+func (c Cron) OneTime(next time.Time) bool {
+	return c.ExpiresAt != nil && c.ExpiresAt.Before(next)
+}
+
+// RunSummary counts how often a cron fired over a period
+type RunSummary struct {
+	Keyword string
+	Count   int
+	LastRun time.Time
 }
 
 // Store manages cron persistence
 type Store struct {
 	db       *sql.DB
 	timezone *time.Location
+	zones    func(chatID int64) *time.Location
 }
 
 // cronParser is configured for 6-field cron expressions (with seconds)
@@ -44,6 +68,15 @@ CREATE TABLE IF NOT EXISTS crons (
 
 CREATE INDEX IF NOT EXISTS idx_crons_next_run ON crons(next_run);
 CREATE INDEX IF NOT EXISTS idx_crons_chat_id ON crons(chat_id);
+
+CREATE TABLE IF NOT EXISTS cron_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    keyword TEXT NOT NULL,
+    chat_id INTEGER NOT NULL,
+    ran_at DATETIME DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_cron_runs_chat ON cron_runs(chat_id, ran_at);
 `
 
 // NewStore creates a cron store using the provided database connection
@@ -60,24 +93,59 @@ func NewStore(db *sql.DB, timezone *time.Location) (*Store, error) {
 	return s, nil
 }
 
+// SetTimezoneResolver sets how a chat's own timezone is looked up, so
+// schedules run on the local time of whoever they're for. Chats it returns
+// nil for use the store's default timezone.
+func (s *Store) SetTimezoneResolver(fn func(chatID int64) *time.Location) {
+	s.zones = fn
+}
+
+// Location is the timezone a chat's schedules are interpreted in
+func (s *Store) Location(chatID int64) *time.Location {
+	if s.zones != nil {
+		if loc := s.zones(chatID); loc != nil {
+			return loc
+		}
+	}
+	return s.timezone
+}
+
 func (s *Store) migrate() error {
 	if _, err := s.db.Exec(schema); err != nil {
 		return err
 	}
+	for _, column := range []string{"prompt", "tools", "skill"} {
+		if err := s.addColumn(column); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (s *Store) addColumn(column string) error {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('crons') WHERE name = ?`, column).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := s.db.Exec(`ALTER TABLE crons ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`)
+	return err
+}
+
 // Create creates a new scheduled reminder
 func (s *Store) Create(keyword, schedule string, chatID int64, expiresAt *time.Time) (*Cron, error) {
-	// validate cron expression
-	sched, err := cronParser.Parse(schedule)
+	// validate cron expression or schedule phrase
+	schedule = strings.TrimSpace(schedule)
+	sched, err := ParseSchedule(schedule)
 	if err != nil {
-		return nil, fmt.Errorf("invalid cron schedule '%s': %w", schedule, err)
+		return nil, err
 	}
 
 	// interpret cron expression in user's timezone, then convert to UTC for storage
 	// this ensures "8pm" means 8pm in the user's timezone, not UTC
-	nextRun := sched.Next(time.Now().In(s.timezone)).UTC()
+	nextRun := sched.Next(time.Now().In(s.Location(chatID))).UTC()
 
 	// format times for SQLite compatibility (YYYY-MM-DD HH:MM:SS)
 	nextRunStr := nextRun.Format("2006-01-02 15:04:05")
@@ -110,7 +178,7 @@ func (s *Store) Create(keyword, schedule string, chatID int64, expiresAt *time.T
 // GetDue returns all crons that should fire now (next_run <= now, not expired, not paused)
 func (s *Store) GetDue() ([]Cron, error) {
 	rows, err := s.db.Query(`
-		SELECT id, keyword, schedule, chat_id, expires_at, paused_until, next_run, created_at
+		SELECT id, keyword, schedule, chat_id, expires_at, paused_until, next_run, created_at, prompt, tools, skill
 		FROM crons
 		WHERE datetime(next_run) <= datetime('now')
 		AND (expires_at IS NULL OR datetime(expires_at) > datetime('now'))
@@ -127,7 +195,7 @@ func (s *Store) GetDue() ([]Cron, error) {
 // GetByChat returns all active crons for a specific chat
 func (s *Store) GetByChat(chatID int64) ([]Cron, error) {
 	rows, err := s.db.Query(`
-		SELECT id, keyword, schedule, chat_id, expires_at, paused_until, next_run, created_at
+		SELECT id, keyword, schedule, chat_id, expires_at, paused_until, next_run, created_at, prompt, tools, skill
 		FROM crons
 		WHERE chat_id = ?
 		AND (expires_at IS NULL OR datetime(expires_at) > datetime('now'))
@@ -150,6 +218,18 @@ func (s *Store) UpdateNextRun(id int64, nextRun time.Time) error {
 	return err
 }
 
+// Postpone moves a cron's next run to until. keepAlive pushes the expiry
+// past until as well, so a one-time cron isn't deleted before it fires.
+func (s *Store) Postpone(id int64, until time.Time, keepAlive bool) error {
+	if !keepAlive {
+		return s.UpdateNextRun(id, until)
+	}
+	untilStr := until.UTC().Format("2006-01-02 15:04:05")
+	expiryStr := until.Add(time.Hour).UTC().Format("2006-01-02 15:04:05")
+	_, err := s.db.Exec(`UPDATE crons SET next_run = ?, expires_at = ? WHERE id = ?`, untilStr, expiryStr, id)
+	return err
+}
+
 // Delete deletes a cron by ID
 func (s *Store) Delete(id int64) error {
 	_, err := s.db.Exec(`DELETE FROM crons WHERE id = ?`, id)
@@ -197,10 +277,12 @@ func (s *Store) scanCrons(rows *sql.Rows) ([]Cron, error) {
 		var c Cron
 		var expiresAt, pausedUntil, nextRun, createdAt *string
 
-		err := rows.Scan(&c.ID, &c.Keyword, &c.Schedule, &c.ChatID, &expiresAt, &pausedUntil, &nextRun, &createdAt)
+		var tools string
+		err := rows.Scan(&c.ID, &c.Keyword, &c.Schedule, &c.ChatID, &expiresAt, &pausedUntil, &nextRun, &createdAt, &c.Prompt, &tools, &c.Skill)
 		if err != nil {
 			return nil, err
 		}
+		c.Tools = splitTools(tools)
 
 		if expiresAt != nil {
 			t := parseTime(*expiresAt)
@@ -237,10 +319,25 @@ func (s *Store) SetPausedUntil(keyword string, chatID int64, until *time.Time) e
 	return err
 }
 
+// SetPrompt stores the trigger prompt and tool allowlist for a cron. An empty
+// prompt goes back to keyword recall, no tools allows every tool.
+func (s *Store) SetPrompt(keyword string, chatID int64, prompt string, tools []string) error {
+	_, err := s.db.Exec(`UPDATE crons SET prompt = ?, tools = ? WHERE keyword = ? AND chat_id = ?`,
+		strings.TrimSpace(prompt), strings.Join(tools, ","), keyword, chatID)
+	return err
+}
+
+// SetSkill makes a cron run the named skill when it fires, or go back to its
+// prompt or keyword recall for an empty name
+func (s *Store) SetSkill(keyword string, chatID int64, skill string) error {
+	_, err := s.db.Exec(`UPDATE crons SET skill = ? WHERE keyword = ? AND chat_id = ?`, skill, keyword, chatID)
+	return err
+}
+
 // GetByKeyword returns a cron by keyword and chat ID
 func (s *Store) GetByKeyword(keyword string, chatID int64) (*Cron, error) {
 	row := s.db.QueryRow(`
-		SELECT id, keyword, schedule, chat_id, expires_at, paused_until, next_run, created_at
+		SELECT id, keyword, schedule, chat_id, expires_at, paused_until, next_run, created_at, prompt, tools, skill
 		FROM crons
 		WHERE keyword = ? AND chat_id = ?
 		AND (expires_at IS NULL OR datetime(expires_at) > datetime('now'))`,
@@ -248,14 +345,16 @@ func (s *Store) GetByKeyword(keyword string, chatID int64) (*Cron, error) {
 
 	var c Cron
 	var expiresAt, pausedUntil, nextRun, createdAt *string
+	var tools string
 
-	err := row.Scan(&c.ID, &c.Keyword, &c.Schedule, &c.ChatID, &expiresAt, &pausedUntil, &nextRun, &createdAt)
+	err := row.Scan(&c.ID, &c.Keyword, &c.Schedule, &c.ChatID, &expiresAt, &pausedUntil, &nextRun, &createdAt, &c.Prompt, &tools, &c.Skill)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	c.Tools = splitTools(tools)
 
 	if expiresAt != nil {
 		t := parseTime(*expiresAt)
@@ -278,13 +377,102 @@ func (s *Store) GetByKeyword(keyword string, chatID int64) (*Cron, error) {
 	return &c, nil
 }
 
-// ComputeNextRun calculates the next run time from a cron schedule
-func (s *Store) ComputeNextRun(schedule string) (time.Time, error) {
-	sched, err := cronParser.Parse(schedule)
+// RecordRun logs that a cron fired, for digests of what ran
+func (s *Store) RecordRun(keyword string, chatID int64) error {
+	_, err := s.db.Exec(`INSERT INTO cron_runs (keyword, chat_id) VALUES (?, ?)`, keyword, chatID)
+	return err
+}
+
+// RunsSince summarizes the crons that fired for a chat since the given time
+func (s *Store) RunsSince(chatID int64, since time.Time) ([]RunSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT keyword, COUNT(*), MAX(ran_at)
+		FROM cron_runs
+		WHERE chat_id = ? AND datetime(ran_at) >= datetime(?)
+		GROUP BY keyword
+		ORDER BY MAX(ran_at) DESC`,
+		chatID, since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []RunSummary
+	for rows.Next() {
+		var r RunSummary
+		var lastRun string
+		if err := rows.Scan(&r.Keyword, &r.Count, &lastRun); err != nil {
+			return nil, err
+		}
+		r.LastRun = parseTime(lastRun)
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// DeleteRunsBefore prunes run history older than the given time
+func (s *Store) DeleteRunsBefore(before time.Time) (int, error) {
+	result, err := s.db.Exec(`DELETE FROM cron_runs WHERE datetime(ran_at) < datetime(?)`, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// ComputeNextRun calculates the next run time from a cron schedule or phrase
+func (s *Store) ComputeNextRun(schedule string, chatID int64) (time.Time, error) {
+	return s.NextRunAfter(schedule, chatID, time.Now())
+}
+
+// Reschedule recomputes the next run of a chat's recurring crons, after its
+// timezone changed. One-time crons keep theirs; they were set for a moment
+// in time, not a time of day.
+func (s *Store) Reschedule(chatID int64) (int, error) {
+	crons, err := s.GetByChat(chatID)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, c := range crons {
+		next, err := s.ComputeNextRun(c.Schedule, chatID)
+		if err != nil {
+			return n, err
+		}
+		// a one-time reminder keeps the moment it was set for
+		after, err := s.NextRunAfter(c.Schedule, chatID, next)
+		if err != nil {
+			return n, err
+		}
+		if c.OneTime(after) {
+			continue
+		}
+		if err := s.UpdateNextRun(c.ID, next); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func splitTools(list string) []string {
+	var tools []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			tools = append(tools, name)
+		}
+	}
+	return tools
+}
+
+// NextRunAfter calculates the first run time of a schedule after t
+func (s *Store) NextRunAfter(schedule string, chatID int64, t time.Time) (time.Time, error) {
+	sched, err := ParseSchedule(schedule)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid cron schedule '%s': %w", schedule, err)
+		return time.Time{}, err
 	}
 
 	// interpret cron expression in user's timezone, convert to UTC for storage
-	return sched.Next(time.Now().In(s.timezone)).UTC(), nil
+	return sched.Next(t.In(s.Location(chatID))).UTC(), nil
 }
@@ -0,0 +1,126 @@
+package cron
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "crons.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s, err := NewStore(db, nil)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return s
+}
+
+func TestStorePrompt(t *testing.T) {
+	s := newTestStore(t)
+
+	if _, err := s.Create("briefing", "every weekday at 7:30", 42, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPrompt("briefing", 42, "Summarize today's calendar and the weather", []string{"list_events", "get_weather"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := s.GetByKeyword("briefing", 42)
+	if err != nil || c == nil {
+		t.Fatalf("GetByKeyword = %v, %v", c, err)
+	}
+	if c.Prompt != "Summarize today's calendar and the weather" {
+		t.Errorf("Prompt = %q", c.Prompt)
+	}
+	if !slices.Equal(c.Tools, []string{"list_events", "get_weather"}) {
+		t.Errorf("Tools = %v", c.Tools)
+	}
+
+	// migrating an existing table again is a no-op
+	if err := s.migrate(); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	crons, err := s.GetByChat(42)
+	if err != nil || len(crons) != 1 || crons[0].Prompt == "" {
+		t.Errorf("GetByChat = %+v, %v", crons, err)
+	}
+}
+
+func TestStoreSkill(t *testing.T) {
+	s := newTestStore(t)
+
+	if _, err := s.Create("meal-plan", "sundays at 6pm", 42, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetSkill("meal-plan", 42, "meal-planner"); err != nil {
+		t.Fatal(err)
+	}
+
+	crons, err := s.GetByChat(42)
+	if err != nil || len(crons) != 1 {
+		t.Fatalf("GetByChat = %+v, %v", crons, err)
+	}
+	if crons[0].Skill != "meal-planner" {
+		t.Errorf("Skill = %q, want meal-planner", crons[0].Skill)
+	}
+}
+
+func TestStoreTimezones(t *testing.T) {
+	s := newTestStore(t)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	zones := map[int64]*time.Location{7: tokyo}
+	s.SetTimezoneResolver(func(chatID int64) *time.Location { return zones[chatID] })
+
+	if s.Location(7) != tokyo || s.Location(42) != time.UTC {
+		t.Fatalf("Location = %v, %v", s.Location(7), s.Location(42))
+	}
+
+	for _, chatID := range []int64{7, 42} {
+		if _, err := s.Create("stretch", "every day at 8am", chatID, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hours := func(chatID int64) int {
+		c, err := s.GetByKeyword("stretch", chatID)
+		if err != nil || c == nil {
+			t.Fatalf("GetByKeyword = %v, %v", c, err)
+		}
+		return c.NextRun.In(s.Location(chatID)).Hour()
+	}
+	if hours(7) != 8 || hours(42) != 8 {
+		t.Errorf("next runs at %d and %d, want 8 local", hours(7), hours(42))
+	}
+
+	// a recurring cron with an expiry moves too, a one-time one doesn't
+	monthOut := time.Now().AddDate(0, 1, 0)
+	if _, err := s.Create("water", "every day at 9am", 42, &monthOut); err != nil {
+		t.Fatal(err)
+	}
+	soon := time.Now().Add(12 * time.Hour)
+	if _, err := s.Create("call mum", "every day at 10am", 42, &soon); err != nil {
+		t.Fatal(err)
+	}
+
+	// moving chat 42 to Tokyo keeps the reminders at the same time there
+	zones[42] = tokyo
+	if n, err := s.Reschedule(42); err != nil || n != 2 {
+		t.Fatalf("Reschedule = %d, %v", n, err)
+	}
+	if hours(42) != 8 {
+		t.Errorf("rescheduled run at %d, want 8 Tokyo time", hours(42))
+	}
+}
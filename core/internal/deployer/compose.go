@@ -8,8 +8,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/logger"
 	"gopkg.in/yaml.v3"
@@ -60,6 +62,8 @@ type ComposeDeployer struct {
 	pathPrefix   string // container path prefix (e.g., /data)
 	hostPrefix   string // host path prefix (e.g., /opt/sheldon/data)
 	network      string // docker network name
+	secrets      SecretSource
+	history      *History
 }
 
 // ComposeService represents a service in docker compose
@@ -70,17 +74,29 @@ type ComposeService struct {
 	Ports       []string          `yaml:"ports,omitempty"`
 	Volumes     []string          `yaml:"volumes,omitempty"`
 	Environment map[string]string `yaml:"environment,omitempty"`
+	EnvFile     []string          `yaml:"env_file,omitempty"`
+	Expose      []string          `yaml:"expose,omitempty"`
+	Deploy      *ComposeDeploy    `yaml:"deploy,omitempty"`
 	Labels      []string          `yaml:"labels,omitempty"`
 	Networks    []string          `yaml:"networks,omitempty"`
 	DependsOn   []string          `yaml:"depends_on,omitempty"`
 }
 
+// ComposeDeploy holds the deploy section of a service
+type ComposeDeploy struct {
+	Replicas int `yaml:"replicas,omitempty"`
+}
+
 // ComposeFile represents a docker-compose.yml structure
 type ComposeFile struct {
 	Services map[string]ComposeService `yaml:"services"`
 	Networks map[string]ComposeNetwork `yaml:"networks,omitempty"`
+	Volumes  map[string]ComposeVolume  `yaml:"volumes,omitempty"`
 }
 
+// ComposeVolume declares a named volume (defaults only)
+type ComposeVolume struct{}
+
 // ComposeNetwork represents a docker network
 type ComposeNetwork struct {
 	External bool `yaml:"external,omitempty"`
@@ -115,6 +131,16 @@ func NewComposeDeployer(cfg ComposeDeployerConfig) *ComposeDeployer {
 	}
 }
 
+// SetSecrets injects each app's secrets as environment variables when it's deployed
+func (d *ComposeDeployer) SetSecrets(secrets SecretSource) {
+	d.secrets = secrets
+}
+
+// SetHistory records each deploy so apps can be rolled back
+func (d *ComposeDeployer) SetHistory(history *History) {
+	d.history = history
+}
+
 // toHostPath converts a container path to host path
 func (d *ComposeDeployer) toHostPath(containerPath string) string {
 	if d.pathPrefix == "" || d.hostPrefix == "" {
@@ -128,7 +154,7 @@ func (d *ComposeDeployer) toHostPath(containerPath string) string {
 
 // findDockerfile searches for Dockerfile in appDir and immediate subdirectories
 // Returns the directory containing Dockerfile, or empty string if not found
-func (d *ComposeDeployer) findDockerfile(appDir string) string {
+func findDockerfile(appDir string) string {
 	// check root first
 	if _, err := os.Stat(filepath.Join(appDir, "Dockerfile")); err == nil {
 		return appDir
@@ -157,7 +183,24 @@ func (d *ComposeDeployer) findDockerfile(appDir string) string {
 // Detects the project type from files present and writes an appropriate Dockerfile.
 // Returns the directory containing the generated Dockerfile, or empty string if
 // the project type couldn't be determined.
-func (d *ComposeDeployer) autoDockerfile(appDir string) string {
+func autoDockerfile(appDir string) string {
+	dockerfile := dockerfileFor(appDir)
+	if dockerfile == "" {
+		return ""
+	}
+
+	path := filepath.Join(appDir, "Dockerfile")
+	if err := os.WriteFile(path, []byte(dockerfile), 0644); err != nil {
+		logger.Error("failed to write auto-generated Dockerfile", "error", err)
+		return ""
+	}
+	logger.Info("auto-generated Dockerfile", "path", path, "dir", appDir)
+	return appDir
+}
+
+// dockerfileFor returns the Dockerfile autoDockerfile would write for appDir, or "" if
+// the project type couldn't be determined
+func dockerfileFor(appDir string) string {
 	entries, err := os.ReadDir(appDir)
 	if err != nil {
 		return ""
@@ -208,21 +251,13 @@ func (d *ComposeDeployer) autoDockerfile(appDir string) string {
 			fmt.Fprintf(&copyLines, "COPY %s /usr/share/nginx/html/%s\n", name, name)
 		}
 		dockerfile = "FROM nginx:alpine\n" + copyLines.String()
-	default:
-		return ""
 	}
 
-	path := filepath.Join(appDir, "Dockerfile")
-	if err := os.WriteFile(path, []byte(dockerfile), 0644); err != nil {
-		logger.Error("failed to write auto-generated Dockerfile", "error", err)
-		return ""
-	}
-	logger.Info("auto-generated Dockerfile", "path", path, "dir", appDir)
-	return appDir
+	return dockerfile
 }
 
 // Deploy adds a service to apps.yml and runs docker compose up
-func (d *ComposeDeployer) Deploy(ctx context.Context, appDir string, name string, domain string) (*DeployResult, error) {
+func (d *ComposeDeployer) Deploy(ctx context.Context, appDir string, name string, domain string, opts DeployOptions) (*DeployResult, error) {
 	// validate app name (alphanumeric + hyphens, max 63 chars, must start with alphanumeric)
 	if !validAppName.MatchString(name) {
 		return nil, fmt.Errorf("invalid app name %q: must be lowercase alphanumeric with hyphens, 1-63 chars, start with letter/number", name)
@@ -233,6 +268,10 @@ func (d *ComposeDeployer) Deploy(ctx context.Context, appDir string, name string
 		return nil, fmt.Errorf("invalid domain %q: %w", domain, err)
 	}
 
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// validate app directory exists
 	if _, err := os.Stat(appDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("app directory does not exist: %s (expected path from write_code workspace)", appDir)
@@ -244,57 +283,29 @@ func (d *ComposeDeployer) Deploy(ctx context.Context, appDir string, name string
 		return nil, fmt.Errorf("load compose file: %w", err)
 	}
 
-	// determine if we're deploying from a build or an image
-	service := ComposeService{
-		Restart:  "unless-stopped",
-		Networks: []string{d.network},
-	}
-
 	// find Dockerfile - check root first, then immediate subdirectories
-	dockerfilePath := d.findDockerfile(appDir)
+	dockerfilePath := findDockerfile(appDir)
 	if dockerfilePath == "" {
 		// no Dockerfile found - try to auto-generate one based on project files
-		dockerfilePath = d.autoDockerfile(appDir)
+		dockerfilePath = autoDockerfile(appDir)
 	}
-	if dockerfilePath != "" {
-		service.Build = dockerfilePath
-		logger.Debug("found Dockerfile", "path", dockerfilePath)
-	} else {
+	if dockerfilePath == "" {
 		return nil, fmt.Errorf("no Dockerfile found in %s or its subdirectories, and could not auto-detect project type", appDir)
 	}
+	logger.Debug("found Dockerfile", "path", dockerfilePath)
 
-	// routing configuration depends on domain type
-	isIP := net.ParseIP(domain) != nil
-	var appURL string
-	var appPort int
+	service, appURL, appPort, err := d.serviceFor(compose, name, domain, dockerfilePath, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	if domain != "" && domain != "localhost" && !isIP {
-		// Domain name - use HTTPS with Let's Encrypt via Traefik
-		service.Labels = []string{
-			"traefik.enable=true",
-			fmt.Sprintf("traefik.http.routers.%s.rule=Host(`%s.%s`)", name, name, domain),
-			fmt.Sprintf("traefik.http.routers.%s.entrypoints=websecure", name),
-			fmt.Sprintf("traefik.http.routers.%s.tls.certresolver=letsencrypt", name),
-		}
-		appURL = fmt.Sprintf("https://%s.%s", name, domain)
-	} else if domain == "localhost" {
-		// localhost: HTTP only with subdomain via Traefik
-		service.Labels = []string{
-			"traefik.enable=true",
-			fmt.Sprintf("traefik.http.routers.%s.rule=Host(`%s.%s`)", name, name, domain),
-			fmt.Sprintf("traefik.http.routers.%s.entrypoints=web", name),
-		}
-		appURL = fmt.Sprintf("http://%s.%s", name, domain)
-	} else if isIP {
-		// IP address - expose port directly (no Traefik routing)
-		// check if this service already has a port assigned
-		appPort = d.getServicePort(compose, name)
-		if appPort == 0 {
-			appPort = d.findNextAvailablePort(compose)
-		}
-		service.Ports = []string{fmt.Sprintf("%d:80", appPort)}
-		appURL = fmt.Sprintf("http://%s:%d", domain, appPort)
-		logger.Debug("IP-only deployment", "port", appPort, "url", appURL)
+	// secrets go in an owner-only env file next to apps.yml, never in apps.yml itself
+	envFile, err := d.writeSecretsFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("write secrets: %w", err)
+	}
+	if envFile != "" {
+		service.EnvFile = []string{envFile}
 	}
 
 	// add service to compose
@@ -324,12 +335,173 @@ func (d *ComposeDeployer) Deploy(ctx context.Context, appDir string, name string
 
 	logger.Info("app deployed via compose", "name", name, "file", d.appsFile, "url", appURL)
 
-	return &DeployResult{
+	result := &DeployResult{
 		Resources: []string{name},
 		Status:    "deployed",
 		URL:       appURL,
 		Port:      appPort,
-	}, nil
+	}
+
+	if d.history != nil {
+		deployment := &Deployment{App: name, Image: service.Image, Digest: imageID(ctx, service.Image)}
+		deployment.Branch, deployment.Commit = gitRevision(ctx, appDir)
+		if err := d.history.Record(deployment); err != nil {
+			logger.Warn("failed to record deployment", "name", name, "error", err)
+		} else {
+			result.DeploymentID = deployment.ID
+		}
+	}
+
+	return result, nil
+}
+
+// serviceFor works out the service definition a deploy of buildDir writes to apps.yml.
+// New named volumes are added to compose; nothing is written to disk.
+func (d *ComposeDeployer) serviceFor(compose *ComposeFile, name, domain, buildDir string, opts DeployOptions) (ComposeService, string, int, error) {
+	service := ComposeService{
+		// every deploy gets its own tag so earlier versions stay around for rollback
+		Image:    fmt.Sprintf("sheldon-app/%s:%s", name, time.Now().UTC().Format("20060102-150405")),
+		Build:    buildDir,
+		Restart:  "unless-stopped",
+		Networks: []string{d.network},
+	}
+
+	// per-app settings carry over from the last deploy unless given
+	previous := compose.Services[name]
+	service.Environment = previous.Environment
+	if opts.Env != nil {
+		service.Environment = opts.Env
+	}
+
+	internalPort := exposedPort(previous)
+	if opts.Port > 0 {
+		internalPort = opts.Port
+	}
+	if internalPort > 0 {
+		service.Expose = []string{strconv.Itoa(internalPort)}
+	}
+
+	service.Volumes = previous.Volumes
+	if opts.Volumes != nil {
+		volumes, _ := opts.ParseVolumes()
+		service.Volumes = nil
+		for _, v := range volumes {
+			// scoped to the app so two apps can't share a volume by picking the same name
+			volume := name + "-" + v.Name
+			if compose.Volumes == nil {
+				compose.Volumes = make(map[string]ComposeVolume)
+			}
+			compose.Volumes[volume] = ComposeVolume{}
+			mount := volume + ":" + v.Path
+			if v.ReadOnly {
+				mount += ":ro"
+			}
+			service.Volumes = append(service.Volumes, mount)
+		}
+	}
+
+	service.Deploy = previous.Deploy
+	if opts.Replicas > 0 {
+		service.Deploy = &ComposeDeploy{Replicas: opts.Replicas}
+	}
+
+	// routing configuration depends on domain type
+	isIP := net.ParseIP(domain) != nil
+	var appURL string
+	var appPort int
+
+	if domain != "" && !isIP {
+		// domain name: HTTPS with Let's Encrypt via Traefik; localhost: HTTP only
+		route := Route{
+			App:        name,
+			Hosts:      []string{name + "." + domain},
+			EntryPoint: "websecure",
+			TLS:        true,
+			Port:       internalPort,
+		}
+		if domain == "localhost" {
+			route.EntryPoint = "web"
+			route.TLS = false
+		}
+		// custom domains and basic auth carry over from the last deploy
+		prev := parseRoute(name, previous)
+		if len(prev.Hosts) > 1 {
+			route.Hosts = append(route.Hosts, prev.Hosts[1:]...)
+		}
+		route.AuthUsers = prev.AuthUsers
+		service.Labels = route.labels()
+		appURL = route.URL()
+	} else if isIP {
+		if service.Deploy != nil && service.Deploy.Replicas > 1 {
+			return service, "", 0, fmt.Errorf("replicas need a domain: IP-only apps bind a host port, which only one container can hold")
+		}
+		// IP address - expose port directly (no Traefik routing)
+		// check if this service already has a port assigned
+		appPort = d.getServicePort(compose, name)
+		if appPort == 0 {
+			appPort = d.findNextAvailablePort(compose)
+		}
+		containerPort := internalPort
+		if containerPort == 0 {
+			containerPort = 80
+		}
+		service.Ports = []string{fmt.Sprintf("%d:%d", appPort, containerPort)}
+		appURL = fmt.Sprintf("http://%s:%d", domain, appPort)
+		logger.Debug("IP-only deployment", "port", appPort, "url", appURL)
+	}
+
+	return service, appURL, appPort, nil
+}
+
+// Rollback runs an earlier deployment's image again, keeping the app's routing.
+// An id of 0 picks the last deployment with a different image than the current one.
+func (d *ComposeDeployer) Rollback(ctx context.Context, name string, id int64) (*Deployment, error) {
+	if d.history == nil {
+		return nil, fmt.Errorf("deployment history is not enabled")
+	}
+
+	compose, err := d.loadComposeFile()
+	if err != nil {
+		return nil, fmt.Errorf("load compose file: %w", err)
+	}
+	service, exists := compose.Services[name]
+	if !exists {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+
+	target, err := d.history.Target(name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if imageID(ctx, target.Image) == "" {
+		return nil, fmt.Errorf("image %s for deployment #%d no longer exists", target.Image, target.ID)
+	}
+
+	// run the old image as-is; the next deploy_app sets the build back
+	service.Image = target.Image
+	service.Build = ""
+	compose.Services[name] = service
+
+	if err := d.saveComposeFile(compose); err != nil {
+		return nil, fmt.Errorf("save compose file: %w", err)
+	}
+	if err := d.composeStart(ctx, name); err != nil {
+		return nil, err
+	}
+
+	logger.Info("app rolled back", "name", name, "deployment", target.ID, "image", target.Image)
+
+	d.history.RecordRollback(target)
+	return target, nil
+}
+
+// History returns an app's recorded deployments, newest first
+func (d *ComposeDeployer) History(name string, limit int) ([]Deployment, error) {
+	if d.history == nil {
+		return nil, fmt.Errorf("deployment history is not enabled")
+	}
+	return d.history.List(name, limit)
 }
 
 // Remove stops and removes a service from apps.yml
@@ -352,6 +524,9 @@ func (d *ComposeDeployer) Remove(ctx context.Context, name string) error {
 
 	// remove from compose
 	delete(compose.Services, name)
+	if err := os.Remove(d.secretsFile(name)); err != nil && !os.IsNotExist(err) {
+		logger.Warn("failed to remove secrets file", "name", name, "error", err)
+	}
 
 	// save compose file
 	if err := d.saveComposeFile(compose); err != nil {
@@ -396,6 +571,67 @@ func (d *ComposeDeployer) Logs(ctx context.Context, name string, lines int) (str
 	return string(output), nil
 }
 
+// secretsFile is where an app's env file lives, alongside apps.yml
+func (d *ComposeDeployer) secretsFile(name string) string {
+	return filepath.Join(filepath.Dir(d.appsFile), "secrets", name+".env")
+}
+
+// writeSecretsFile writes the app's secrets to its env file and returns the path,
+// or removes a stale file and returns "" when the app has none
+func (d *ComposeDeployer) writeSecretsFile(name string) (string, error) {
+	path := d.secretsFile(name)
+
+	var env map[string]string
+	if d.secrets != nil {
+		var err error
+		if env, err = d.secrets.Env(name); err != nil {
+			return "", err
+		}
+	}
+	if len(env) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		return "", nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%s=%s\n", k, quoteEnvValue(env[k]))
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(sb.String()), 0600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		return "", err
+	}
+
+	logger.Debug("wrote app secrets", "name", name, "count", len(keys))
+	return path, nil
+}
+
+// envValueEscaper escapes a value for a double-quoted env file entry. Single
+// quotes can't hold a ' at all (backslash is literal inside them), and an
+// unescaped $ would be interpolated by compose.
+var envValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+
+// quoteEnvValue writes v so compose's env file parser reads it back unchanged
+func quoteEnvValue(v string) string {
+	return `"` + envValueEscaper.Replace(v) + `"`
+}
+
 func (d *ComposeDeployer) loadComposeFile() (*ComposeFile, error) {
 	compose := &ComposeFile{
 		Services: make(map[string]ComposeService),
@@ -462,8 +698,12 @@ func (d *ComposeDeployer) composeUp(ctx context.Context, service string) error {
 		return fmt.Errorf("docker build failed for %s: %w", service, err)
 	}
 
-	// start service
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", composeFile, "up", "-d", service)
+	return d.composeStart(ctx, service)
+}
+
+// composeStart (re)creates a service's container from its current definition without building
+func (d *ComposeDeployer) composeStart(ctx context.Context, service string) error {
+	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", d.appsFile, "up", "-d", "--no-build", service)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("compose up: %w\n%s", err, string(output))
@@ -471,6 +711,25 @@ func (d *ComposeDeployer) composeUp(ctx context.Context, service string) error {
 	return nil
 }
 
+// imageID returns the local ID of an image, or "" if it doesn't exist
+func imageID(ctx context.Context, image string) string {
+	output, err := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Id}}", image).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// gitRevision returns the branch and commit of the app dir, empty if it isn't a repo
+func gitRevision(ctx context.Context, dir string) (string, string) {
+	branch, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", ""
+	}
+	commit, _ := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+	return strings.TrimSpace(string(branch)), strings.TrimSpace(string(commit))
+}
+
 func (d *ComposeDeployer) composeDown(ctx context.Context, service string) error {
 	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", d.appsFile, "rm", "-f", "-s", service)
 	output, err := cmd.CombinedOutput()
@@ -507,6 +766,16 @@ func (d *ComposeDeployer) findNextAvailablePort(compose *ComposeFile) int {
 	return baseAppPort + len(compose.Services)
 }
 
+// exposedPort returns the internal port a service was deployed with, or 0 if unset
+func exposedPort(svc ComposeService) int {
+	for _, p := range svc.Expose {
+		if port, err := strconv.Atoi(p); err == nil {
+			return port
+		}
+	}
+	return 0
+}
+
 // getServicePort returns the host port for an existing service, or 0 if not found
 func (d *ComposeDeployer) getServicePort(compose *ComposeFile, name string) int {
 	svc, ok := compose.Services[name]
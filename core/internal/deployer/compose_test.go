@@ -0,0 +1,33 @@
+package deployer
+
+import (
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+func TestQuoteEnvValueRoundTrip(t *testing.T) {
+	values := []string{
+		"plain",
+		"it's a secret",
+		`say "hi" twice`,
+		`C:\path\to`,
+		"pa$$word and $HOME and ${USER}",
+		`\$ already escaped`,
+		"line one\nline two",
+		"# not a comment",
+		"",
+	}
+
+	// compose's env file parser is a fork of godotenv and quotes the same way
+	for _, v := range values {
+		env, err := godotenv.Unmarshal("SECRET=" + quoteEnvValue(v) + "\n")
+		if err != nil {
+			t.Errorf("%q: %v", v, err)
+			continue
+		}
+		if got := env["SECRET"]; got != v {
+			t.Errorf("round trip of %q gave %q", v, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package deployer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+var ErrDeploymentNotFound = errors.New("deployment not found")
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS deployments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    app TEXT NOT NULL,
+    image TEXT NOT NULL,
+    digest TEXT,
+    branch TEXT,
+    commit_sha TEXT,
+    rollback_of INTEGER,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployments_app ON deployments(app, id);
+`
+
+const sqliteTime = "2006-01-02 15:04:05"
+
+// History records every deploy so apps can be rolled back
+type History struct {
+	db *sql.DB
+}
+
+// NewHistory creates the deployments table in the given database
+func NewHistory(db *sql.DB) (*History, error) {
+	if _, err := db.Exec(historySchema); err != nil {
+		return nil, err
+	}
+	return &History{db: db}, nil
+}
+
+// Record saves a deployment, filling in its ID and time
+func (h *History) Record(d *Deployment) error {
+	d.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	result, err := h.db.Exec(`INSERT INTO deployments (app, image, digest, branch, commit_sha, rollback_of, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		d.App, d.Image, d.Digest, d.Branch, d.Commit, d.RollbackOf, d.CreatedAt.Format(sqliteTime))
+	if err != nil {
+		return err
+	}
+	d.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// List returns an app's deployments, newest first
+func (h *History) List(app string, limit int) ([]Deployment, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	return h.query(`WHERE app = ? ORDER BY id DESC LIMIT ?`, app, limit)
+}
+
+// Get returns one of an app's deployments by ID
+func (h *History) Get(app string, id int64) (*Deployment, error) {
+	deployments, err := h.query(`WHERE app = ? AND id = ?`, app, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, ErrDeploymentNotFound
+	}
+	return &deployments[0], nil
+}
+
+// Target picks the deployment to roll back to: the given ID, or the previous version when id is 0
+func (h *History) Target(app string, id int64) (*Deployment, error) {
+	var target *Deployment
+	var err error
+	if id > 0 {
+		target, err = h.Get(app, id)
+	} else {
+		target, err = h.Previous(app)
+	}
+
+	if errors.Is(err, ErrDeploymentNotFound) {
+		if id > 0 {
+			return nil, fmt.Errorf("%s has no deployment #%d", app, id)
+		}
+		return nil, fmt.Errorf("%s has no earlier deployment to roll back to", app)
+	}
+	return target, err
+}
+
+// RecordRollback logs a rollback to target as a new deployment
+func (h *History) RecordRollback(target *Deployment) {
+	rollback := &Deployment{
+		App:        target.App,
+		Image:      target.Image,
+		Digest:     target.Digest,
+		Branch:     target.Branch,
+		Commit:     target.Commit,
+		RollbackOf: target.ID,
+	}
+	if err := h.Record(rollback); err != nil {
+		logger.Warn("failed to record rollback", "name", target.App, "error", err)
+	}
+}
+
+// Previous returns the most recent deployment running a different image than the current one
+func (h *History) Previous(app string) (*Deployment, error) {
+	deployments, err := h.query(`WHERE app = ? ORDER BY id DESC`, app)
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, ErrDeploymentNotFound
+	}
+
+	current := deployments[0].Image
+	for _, d := range deployments[1:] {
+		if d.Image != current {
+			return &d, nil
+		}
+	}
+	return nil, ErrDeploymentNotFound
+}
+
+func (h *History) query(where string, args ...any) ([]Deployment, error) {
+	rows, err := h.db.Query(`SELECT id, app, image, digest, branch, commit_sha, rollback_of, created_at FROM deployments `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deployments []Deployment
+	for rows.Next() {
+		var d Deployment
+		var digest, branch, commit *string
+		var rollbackOf *int64
+		var createdAt string
+		if err := rows.Scan(&d.ID, &d.App, &d.Image, &digest, &branch, &commit, &rollbackOf, &createdAt); err != nil {
+			return nil, err
+		}
+		if digest != nil {
+			d.Digest = *digest
+		}
+		if branch != nil {
+			d.Branch = *branch
+		}
+		if commit != nil {
+			d.Commit = *commit
+		}
+		if rollbackOf != nil {
+			d.RollbackOf = *rollbackOf
+		}
+		d.CreatedAt, _ = time.Parse(sqliteTime, createdAt)
+		deployments = append(deployments, d)
+	}
+	return deployments, rows.Err()
+}
@@ -0,0 +1,78 @@
+package deployer
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+func newTestHistory(t *testing.T) *History {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h, err := NewHistory(db)
+	if err != nil {
+		t.Fatalf("failed to create history: %v", err)
+	}
+	return h
+}
+
+func TestHistoryRecordAndList(t *testing.T) {
+	h := newTestHistory(t)
+
+	for _, image := range []string{"sheldon-app/web:1", "sheldon-app/web:2"} {
+		if err := h.Record(&Deployment{App: "web", Image: image, Branch: "main", Commit: "abc123"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.Record(&Deployment{App: "other", Image: "sheldon-app/other:1"})
+
+	deployments, err := h.List("web", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployments) != 2 {
+		t.Fatalf("got %d deployments, want 2", len(deployments))
+	}
+	if deployments[0].Image != "sheldon-app/web:2" || deployments[0].Branch != "main" || deployments[0].CreatedAt.IsZero() {
+		t.Errorf("newest deployment = %+v", deployments[0])
+	}
+
+	got, err := h.Get("web", deployments[1].ID)
+	if err != nil || got.Image != "sheldon-app/web:1" {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	if _, err := h.Get("other", deployments[1].ID); !errors.Is(err, ErrDeploymentNotFound) {
+		t.Errorf("Get across apps = %v, want ErrDeploymentNotFound", err)
+	}
+}
+
+func TestHistoryPrevious(t *testing.T) {
+	h := newTestHistory(t)
+
+	if _, err := h.Previous("web"); !errors.Is(err, ErrDeploymentNotFound) {
+		t.Errorf("empty history = %v", err)
+	}
+
+	v1 := &Deployment{App: "web", Image: "sheldon-app/web:1"}
+	h.Record(v1)
+	h.Record(&Deployment{App: "web", Image: "sheldon-app/web:2"})
+	// a redeploy of the same image shouldn't count as a version to go back to
+	h.Record(&Deployment{App: "web", Image: "sheldon-app/web:2"})
+
+	prev, err := h.Previous("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev.ID != v1.ID {
+		t.Errorf("previous = #%d, want #%d", prev.ID, v1.ID)
+	}
+}
@@ -0,0 +1,591 @@
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	appLabel       = "app.kubernetes.io/name"
+)
+
+var exposePattern = regexp.MustCompile(`(?im)^\s*EXPOSE\s+(\d+)`)
+
+// K8sDeployer deploys apps to a kubernetes cluster (e.g. k3s) with kubectl,
+// as a Deployment + Service, plus an Ingress when there's a domain
+type K8sDeployer struct {
+	namespace    string
+	registry     string // pushed to before deploying; empty means the cluster uses the local image store
+	ingressClass string
+	certIssuer   string // cert-manager ClusterIssuer for TLS, empty = plain HTTP
+	secrets      SecretSource
+	history      *History
+}
+
+// K8sDeployerConfig holds configuration for K8sDeployer
+type K8sDeployerConfig struct {
+	Namespace    string // namespace for apps (default: sheldon-apps)
+	Registry     string // image registry prefix, e.g. registry.example.com:5000
+	IngressClass string // ingress class (default: traefik, as shipped with k3s)
+	CertIssuer   string // cert-manager ClusterIssuer name for HTTPS
+}
+
+// NewK8sDeployer creates a kubernetes deployer
+func NewK8sDeployer(cfg K8sDeployerConfig) *K8sDeployer {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "sheldon-apps"
+	}
+	if cfg.IngressClass == "" {
+		cfg.IngressClass = "traefik"
+	}
+	return &K8sDeployer{
+		namespace:    cfg.Namespace,
+		registry:     strings.TrimSuffix(cfg.Registry, "/"),
+		ingressClass: cfg.IngressClass,
+		certIssuer:   cfg.CertIssuer,
+	}
+}
+
+// SetSecrets injects each app's secrets from a kubernetes Secret when it's deployed
+func (d *K8sDeployer) SetSecrets(secrets SecretSource) {
+	d.secrets = secrets
+}
+
+// SetHistory records each deploy so apps can be rolled back
+func (d *K8sDeployer) SetHistory(history *History) {
+	d.history = history
+}
+
+// Deploy builds the app image and applies its manifests
+func (d *K8sDeployer) Deploy(ctx context.Context, appDir string, name string, domain string, opts DeployOptions) (*DeployResult, error) {
+	if !validAppName.MatchString(name) {
+		return nil, fmt.Errorf("invalid app name %q: must be lowercase alphanumeric with hyphens, 1-63 chars, start with letter/number", name)
+	}
+	if err := validateDomain(domain); err != nil {
+		return nil, fmt.Errorf("invalid domain %q: %w", domain, err)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(opts.Volumes) > 0 {
+		return nil, fmt.Errorf("volumes are only supported with the compose deploy target")
+	}
+	if _, err := os.Stat(appDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("app directory does not exist: %s (expected path from write_code workspace)", appDir)
+	}
+
+	buildDir := findDockerfile(appDir)
+	if buildDir == "" {
+		buildDir = autoDockerfile(appDir)
+	}
+	if buildDir == "" {
+		return nil, fmt.Errorf("no Dockerfile found in %s or its subdirectories, and could not auto-detect project type", appDir)
+	}
+
+	image := d.imageTag(name)
+	if err := d.buildImage(ctx, buildDir, image); err != nil {
+		return nil, err
+	}
+
+	if err := d.ensureNamespace(ctx); err != nil {
+		return nil, err
+	}
+	hasSecrets, err := d.applySecrets(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("apply secrets: %w", err)
+	}
+
+	opts = d.resolveOptions(ctx, name, buildDir, opts)
+
+	spec := k8sApp{
+		Name:          name,
+		Namespace:     d.namespace,
+		Image:         image,
+		Port:          opts.Port,
+		Env:           opts.Env,
+		Replicas:      opts.Replicas,
+		Domain:        domain,
+		IngressClass:  d.ingressClass,
+		CertIssuer:    d.certIssuer,
+		SecretEnvFrom: hasSecrets,
+	}
+	manifests, err := spec.manifests()
+	if err != nil {
+		return nil, fmt.Errorf("render manifests: %w", err)
+	}
+
+	if _, err := d.kubectlInput(ctx, manifests, "apply", "-f", "-"); err != nil {
+		return nil, err
+	}
+	if err := d.waitRollout(ctx, name); err != nil {
+		return &DeployResult{
+			Resources: spec.resources(),
+			Status:    fmt.Sprintf("failed: %v", err),
+		}, err
+	}
+
+	appURL, appPort := spec.url(), 0
+	if spec.nodePort() {
+		appPort = d.nodePort(ctx, name)
+		if domain != "" {
+			appURL = fmt.Sprintf("http://%s:%d", domain, appPort)
+		}
+	}
+
+	logger.Info("app deployed to kubernetes", "name", name, "namespace", d.namespace, "image", image, "url", appURL)
+
+	result := &DeployResult{
+		Resources: spec.resources(),
+		Status:    "deployed",
+		URL:       appURL,
+		Port:      appPort,
+	}
+
+	if d.history != nil {
+		deployment := &Deployment{App: name, Image: image, Digest: imageID(ctx, image)}
+		deployment.Branch, deployment.Commit = gitRevision(ctx, appDir)
+		if err := d.history.Record(deployment); err != nil {
+			logger.Warn("failed to record deployment", "name", name, "error", err)
+		} else {
+			result.DeploymentID = deployment.ID
+		}
+	}
+
+	return result, nil
+}
+
+// Remove deletes everything sheldon created for the app
+func (d *K8sDeployer) Remove(ctx context.Context, name string) error {
+	if _, err := d.kubectl(ctx, "get", "deployment", name); err != nil {
+		return fmt.Errorf("service %s not found", name)
+	}
+
+	selector := fmt.Sprintf("%s=%s,%s=sheldon", appLabel, name, managedByLabel)
+	if _, err := d.kubectl(ctx, "delete", "deployment,service,ingress,secret", "-l", selector, "--ignore-not-found"); err != nil {
+		return err
+	}
+
+	logger.Info("app removed from kubernetes", "name", name, "namespace", d.namespace)
+	return nil
+}
+
+// List returns all apps deployed by sheldon
+func (d *K8sDeployer) List(ctx context.Context) ([]string, error) {
+	output, err := d.kubectl(ctx, "get", "deployments", "-l", managedByLabel+"=sheldon", "-o", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// Status returns the rollout state of an app
+func (d *K8sDeployer) Status(ctx context.Context, name string) (string, error) {
+	output, err := d.kubectl(ctx, "get", "deployment", name, "-o",
+		"jsonpath={.status.readyReplicas}/{.spec.replicas} ready, image {.spec.template.spec.containers[0].image}")
+	if err != nil {
+		return "", fmt.Errorf("get status: %w", err)
+	}
+	// readyReplicas is omitted entirely when nothing is ready
+	if strings.HasPrefix(output, "/") {
+		output = "0" + output
+	}
+	return output, nil
+}
+
+// Logs returns recent logs for an app
+func (d *K8sDeployer) Logs(ctx context.Context, name string, lines int) (string, error) {
+	output, err := d.kubectl(ctx, "logs", "deployment/"+name, "--tail", strconv.Itoa(lines), "--all-containers")
+	if err != nil {
+		return "", fmt.Errorf("get logs: %w", err)
+	}
+	return output, nil
+}
+
+// Rollback points the app's Deployment back at an earlier image.
+// An id of 0 picks the last deployment with a different image than the current one.
+func (d *K8sDeployer) Rollback(ctx context.Context, name string, id int64) (*Deployment, error) {
+	if d.history == nil {
+		return nil, fmt.Errorf("deployment history is not enabled")
+	}
+	if _, err := d.kubectl(ctx, "get", "deployment", name); err != nil {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+
+	target, err := d.history.Target(name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := d.kubectl(ctx, "set", "image", "deployment/"+name, "app="+target.Image); err != nil {
+		return nil, err
+	}
+	if err := d.waitRollout(ctx, name); err != nil {
+		return nil, err
+	}
+
+	logger.Info("app rolled back", "name", name, "deployment", target.ID, "image", target.Image)
+
+	d.history.RecordRollback(target)
+	return target, nil
+}
+
+// History returns an app's recorded deployments, newest first
+func (d *K8sDeployer) History(name string, limit int) ([]Deployment, error) {
+	if d.history == nil {
+		return nil, fmt.Errorf("deployment history is not enabled")
+	}
+	return d.history.List(name, limit)
+}
+
+func (d *K8sDeployer) buildImage(ctx context.Context, dir, image string) error {
+	cmd := exec.CommandContext(ctx, "docker", "build", "-t", image, dir)
+	cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("docker build: %w\n%s", err, lastLines(string(output), 20))
+	}
+
+	if d.registry == "" {
+		return nil
+	}
+	if output, err := exec.CommandContext(ctx, "docker", "push", image).CombinedOutput(); err != nil {
+		return fmt.Errorf("docker push: %w\n%s", err, lastLines(string(output), 10))
+	}
+	return nil
+}
+
+// applySecrets syncs the app's secrets into a kubernetes Secret, reporting whether it has any
+func (d *K8sDeployer) applySecrets(ctx context.Context, name string) (bool, error) {
+	var env map[string]string
+	if d.secrets != nil {
+		var err error
+		if env, err = d.secrets.Env(name); err != nil {
+			return false, err
+		}
+	}
+
+	if len(env) == 0 {
+		_, err := d.kubectl(ctx, "delete", "secret", name+"-secrets", "--ignore-not-found")
+		return false, err
+	}
+
+	secret := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   k8sMetadata(name+"-secrets", d.namespace, name),
+		"type":       "Opaque",
+		"stringData": env,
+	}
+	data, err := yaml.Marshal(secret)
+	if err != nil {
+		return false, err
+	}
+
+	// piped over stdin so values never appear in arguments or on disk
+	_, err = d.kubectlInput(ctx, string(data), "apply", "-f", "-")
+	return err == nil, err
+}
+
+// currentOptions reads env, port and replicas back from the app's Deployment, if it exists
+func (d *K8sDeployer) currentOptions(ctx context.Context, name string) DeployOptions {
+	output, err := d.kubectl(ctx, "get", "deployment", name, "-o", "json")
+	if err != nil {
+		return DeployOptions{}
+	}
+
+	var current struct {
+		Spec struct {
+			Replicas int
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Env   []struct{ Name, Value string }
+						Ports []struct{ ContainerPort int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(output), &current); err != nil || len(current.Spec.Template.Spec.Containers) == 0 {
+		return DeployOptions{}
+	}
+
+	container := current.Spec.Template.Spec.Containers[0]
+	opts := DeployOptions{Replicas: current.Spec.Replicas}
+	if len(container.Env) > 0 {
+		opts.Env = make(map[string]string, len(container.Env))
+		for _, e := range container.Env {
+			opts.Env[e.Name] = e.Value
+		}
+	}
+	if len(container.Ports) > 0 {
+		opts.Port = container.Ports[0].ContainerPort
+	}
+	return opts
+}
+
+// resolveOptions fills in the settings a deploy leaves out: per-app settings carry over
+// from the running Deployment, then fall back to the Dockerfile's port and one replica
+func (d *K8sDeployer) resolveOptions(ctx context.Context, name, buildDir string, opts DeployOptions) DeployOptions {
+	current := d.currentOptions(ctx, name)
+	if opts.Env == nil {
+		opts.Env = current.Env
+	}
+	if opts.Port == 0 {
+		opts.Port = current.Port
+	}
+	if opts.Port == 0 {
+		opts.Port = containerPort(buildDir)
+	}
+	if opts.Replicas == 0 {
+		opts.Replicas = max(current.Replicas, 1)
+	}
+	return opts
+}
+
+// imageTag returns a fresh versioned image name for the app, in the registry if one is set
+func (d *K8sDeployer) imageTag(name string) string {
+	image := fmt.Sprintf("sheldon-app/%s:%s", name, time.Now().UTC().Format("20060102-150405"))
+	if d.registry != "" {
+		image = d.registry + "/" + image
+	}
+	return image
+}
+
+// currentImage returns the image the app's Deployment runs, or "" if it isn't deployed
+func (d *K8sDeployer) currentImage(ctx context.Context, name string) string {
+	output, err := d.kubectl(ctx, "get", "deployment", name, "-o", "jsonpath={.spec.template.spec.containers[0].image}")
+	if err != nil {
+		return ""
+	}
+	return output
+}
+
+func (d *K8sDeployer) ensureNamespace(ctx context.Context) error {
+	namespace := fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n", d.namespace)
+	_, err := d.kubectlInput(ctx, namespace, "apply", "-f", "-")
+	return err
+}
+
+func (d *K8sDeployer) waitRollout(ctx context.Context, name string) error {
+	if _, err := d.kubectl(ctx, "rollout", "status", "deployment/"+name, "--timeout=3m"); err != nil {
+		return fmt.Errorf("rollout: %w", err)
+	}
+	return nil
+}
+
+func (d *K8sDeployer) nodePort(ctx context.Context, name string) int {
+	output, err := d.kubectl(ctx, "get", "service", name, "-o", "jsonpath={.spec.ports[0].nodePort}")
+	if err != nil {
+		return 0
+	}
+	port, _ := strconv.Atoi(strings.TrimSpace(output))
+	return port
+}
+
+func (d *K8sDeployer) kubectl(ctx context.Context, args ...string) (string, error) {
+	return d.kubectlInput(ctx, "", args...)
+}
+
+func (d *K8sDeployer) kubectlInput(ctx context.Context, stdin string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "kubectl", append([]string{"-n", d.namespace}, args...)...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("kubectl %s: %w\n%s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// k8sApp describes the manifests for one app
+type k8sApp struct {
+	Name          string
+	Namespace     string
+	Image         string
+	Port          int
+	Env           map[string]string
+	Replicas      int
+	Domain        string
+	IngressClass  string
+	CertIssuer    string
+	SecretEnvFrom bool
+}
+
+// nodePort reports whether the app is reached by IP, so exposed on a node port instead of an ingress
+func (a k8sApp) nodePort() bool {
+	return a.Domain == "" || net.ParseIP(a.Domain) != nil
+}
+
+func (a k8sApp) host() string {
+	return a.Name + "." + a.Domain
+}
+
+func (a k8sApp) tls() bool {
+	return a.CertIssuer != "" && a.Domain != "localhost"
+}
+
+func (a k8sApp) url() string {
+	if a.nodePort() {
+		return ""
+	}
+	if a.tls() {
+		return "https://" + a.host()
+	}
+	return "http://" + a.host()
+}
+
+func (a k8sApp) resources() []string {
+	resources := []string{"deployment/" + a.Name, "service/" + a.Name}
+	if !a.nodePort() {
+		resources = append(resources, "ingress/"+a.Name)
+	}
+	return resources
+}
+
+// manifests renders the app's Deployment, Service and Ingress as one YAML stream
+func (a k8sApp) manifests() (string, error) {
+	container := map[string]any{
+		"name":  "app",
+		"image": a.Image,
+		"ports": []map[string]any{{"containerPort": a.Port}},
+	}
+	if len(a.Env) > 0 {
+		names := make([]string, 0, len(a.Env))
+		for name := range a.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		env := make([]map[string]any, 0, len(names))
+		for _, name := range names {
+			env = append(env, map[string]any{"name": name, "value": a.Env[name]})
+		}
+		container["env"] = env
+	}
+	if a.SecretEnvFrom {
+		container["envFrom"] = []map[string]any{{"secretRef": map[string]any{"name": a.Name + "-secrets"}}}
+	}
+
+	selector := map[string]string{appLabel: a.Name}
+
+	docs := []map[string]any{
+		{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   k8sMetadata(a.Name, a.Namespace, a.Name),
+			"spec": map[string]any{
+				"replicas": max(a.Replicas, 1),
+				"selector": map[string]any{"matchLabels": selector},
+				"template": map[string]any{
+					"metadata": map[string]any{"labels": k8sLabels(a.Name)},
+					"spec":     map[string]any{"containers": []map[string]any{container}},
+				},
+			},
+		},
+	}
+
+	service := map[string]any{
+		"selector": selector,
+		"ports":    []map[string]any{{"name": "http", "port": 80, "targetPort": a.Port}},
+	}
+	if a.nodePort() {
+		service["type"] = "NodePort"
+	}
+	docs = append(docs, map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   k8sMetadata(a.Name, a.Namespace, a.Name),
+		"spec":       service,
+	})
+
+	if !a.nodePort() {
+		metadata := k8sMetadata(a.Name, a.Namespace, a.Name)
+		ingress := map[string]any{
+			"ingressClassName": a.IngressClass,
+			"rules": []map[string]any{{
+				"host": a.host(),
+				"http": map[string]any{
+					"paths": []map[string]any{{
+						"path":     "/",
+						"pathType": "Prefix",
+						"backend": map[string]any{
+							"service": map[string]any{"name": a.Name, "port": map[string]any{"number": 80}},
+						},
+					}},
+				},
+			}},
+		}
+		if a.tls() {
+			metadata["annotations"] = map[string]string{"cert-manager.io/cluster-issuer": a.CertIssuer}
+			ingress["tls"] = []map[string]any{{"hosts": []string{a.host()}, "secretName": a.Name + "-tls"}}
+		}
+		docs = append(docs, map[string]any{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata":   metadata,
+			"spec":       ingress,
+		})
+	}
+
+	var sb strings.Builder
+	for i, doc := range docs {
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			sb.WriteString("---\n")
+		}
+		sb.Write(data)
+	}
+	return sb.String(), nil
+}
+
+func k8sLabels(app string) map[string]string {
+	return map[string]string{appLabel: app, managedByLabel: "sheldon"}
+}
+
+func k8sMetadata(name, namespace, app string) map[string]any {
+	return map[string]any{"name": name, "namespace": namespace, "labels": k8sLabels(app)}
+}
+
+// containerPort reads the first EXPOSE from the Dockerfile, defaulting to 80
+func containerPort(buildDir string) int {
+	data, err := os.ReadFile(filepath.Join(buildDir, "Dockerfile"))
+	if err != nil {
+		return 80
+	}
+	if m := exposePattern.FindSubmatch(data); m != nil {
+		if port, err := strconv.Atoi(string(m[1])); err == nil && port > 0 && port < 65536 {
+			return port
+		}
+	}
+	return 80
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
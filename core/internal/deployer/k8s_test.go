@@ -0,0 +1,91 @@
+package deployer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func decodeManifests(t *testing.T, stream string) map[string]map[string]any {
+	t.Helper()
+	docs := make(map[string]map[string]any)
+	dec := yaml.NewDecoder(strings.NewReader(stream))
+	for {
+		var doc map[string]any
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		docs[doc["kind"].(string)] = doc
+	}
+	return docs
+}
+
+func TestK8sManifestsWithDomain(t *testing.T) {
+	app := k8sApp{
+		Name:          "weather",
+		Namespace:     "apps",
+		Image:         "registry.local/sheldon-app/weather:1",
+		Port:          3000,
+		Env:           map[string]string{"LOG_LEVEL": "info"},
+		Replicas:      2,
+		Domain:        "example.com",
+		IngressClass:  "traefik",
+		CertIssuer:    "letsencrypt",
+		SecretEnvFrom: true,
+	}
+
+	stream, err := app.manifests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := decodeManifests(t, stream)
+
+	for _, kind := range []string{"Deployment", "Service", "Ingress"} {
+		if docs[kind] == nil {
+			t.Fatalf("missing %s in:\n%s", kind, stream)
+		}
+	}
+	for _, want := range []string{"containerPort: 3000", "name: weather-secrets", "host: weather.example.com", "cert-manager.io/cluster-issuer: letsencrypt", "app.kubernetes.io/managed-by: sheldon", "name: LOG_LEVEL", "replicas: 2"} {
+		if !strings.Contains(stream, want) {
+			t.Errorf("manifests missing %q", want)
+		}
+	}
+	if app.url() != "https://weather.example.com" {
+		t.Errorf("url = %s", app.url())
+	}
+}
+
+func TestK8sManifestsForIP(t *testing.T) {
+	app := k8sApp{Name: "todo", Namespace: "apps", Image: "sheldon-app/todo:1", Port: 80, Domain: "1.2.3.4"}
+
+	stream, err := app.manifests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := decodeManifests(t, stream)
+
+	if docs["Ingress"] != nil {
+		t.Error("IP deployments shouldn't get an ingress")
+	}
+	if !strings.Contains(stream, "type: NodePort") {
+		t.Error("IP deployments should use a NodePort service")
+	}
+	if strings.Contains(stream, "envFrom") {
+		t.Error("envFrom set without secrets")
+	}
+}
+
+func TestContainerPort(t *testing.T) {
+	dir := t.TempDir()
+	if got := containerPort(dir); got != 80 {
+		t.Errorf("no Dockerfile: got %d, want 80", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM node:alpine\nexpose 3000\nCMD [\"npm\", \"start\"]\n"), 0644)
+	if got := containerPort(dir); got != 3000 {
+		t.Errorf("got %d, want 3000", got)
+	}
+}
@@ -0,0 +1,62 @@
+package deployer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const maxReplicas = 10
+
+var (
+	validEnvName    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+	validVolumeName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+)
+
+// Validate checks options before anything is built
+func (o DeployOptions) Validate() error {
+	for name := range o.Env {
+		if !validEnvName.MatchString(name) {
+			return fmt.Errorf("invalid env var name %q", name)
+		}
+	}
+	if o.Port < 0 || o.Port > 65535 {
+		return fmt.Errorf("invalid port %d", o.Port)
+	}
+	if o.Replicas < 0 || o.Replicas > maxReplicas {
+		return fmt.Errorf("replicas must be between 1 and %d", maxReplicas)
+	}
+	if _, err := o.ParseVolumes(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseVolumes splits name:/path[:ro] specs. Only named volumes are allowed, never host paths.
+func (o DeployOptions) ParseVolumes() ([]Volume, error) {
+	var volumes []Volume
+	seen := make(map[string]bool)
+
+	for _, spec := range o.Volumes {
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "ro" && parts[2] != "rw") {
+			return nil, fmt.Errorf("invalid volume %q: use name:/container/path or name:/container/path:ro", spec)
+		}
+
+		v := Volume{Name: parts[0], Path: parts[1], ReadOnly: len(parts) == 3 && parts[2] == "ro"}
+		if !validVolumeName.MatchString(v.Name) {
+			return nil, fmt.Errorf("invalid volume name %q: lowercase letters, digits, - and _ only (host paths aren't allowed)", v.Name)
+		}
+		if !path.IsAbs(v.Path) || path.Clean(v.Path) != v.Path || v.Path == "/" {
+			return nil, fmt.Errorf("invalid volume path %q: must be a clean absolute path inside the container", v.Path)
+		}
+		if seen[v.Path] {
+			return nil, fmt.Errorf("volume path %s is mounted twice", v.Path)
+		}
+		seen[v.Path] = true
+
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
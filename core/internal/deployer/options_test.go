@@ -0,0 +1,42 @@
+package deployer
+
+import "testing"
+
+func TestDeployOptionsValidate(t *testing.T) {
+	valid := []DeployOptions{
+		{},
+		{Env: map[string]string{"LOG_LEVEL": "debug"}, Port: 3000, Replicas: 2},
+		{Volumes: []string{"data:/app/data", "config:/etc/app:ro"}},
+	}
+	for _, o := range valid {
+		if err := o.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", o, err)
+		}
+	}
+
+	invalid := []DeployOptions{
+		{Env: map[string]string{"BAD-NAME": "x"}},
+		{Port: 70000},
+		{Replicas: 11},
+		{Volumes: []string{"/etc:/host-etc"}},
+		{Volumes: []string{"data:relative/path"}},
+		{Volumes: []string{"data:/app/../etc"}},
+		{Volumes: []string{"data:/app:rx"}},
+		{Volumes: []string{"a:/data", "b:/data"}},
+	}
+	for _, o := range invalid {
+		if err := o.Validate(); err == nil {
+			t.Errorf("%+v: expected error", o)
+		}
+	}
+}
+
+func TestParseVolumes(t *testing.T) {
+	volumes, err := DeployOptions{Volumes: []string{"data:/app/data", "cfg:/etc/app:ro"}}.ParseVolumes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(volumes) != 2 || volumes[0] != (Volume{Name: "data", Path: "/app/data"}) || !volumes[1].ReadOnly {
+		t.Errorf("got %+v", volumes)
+	}
+}
@@ -0,0 +1,291 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Plan works out what Deploy would write to apps.yml for the app, without building,
+// writing files or touching containers
+func (d *ComposeDeployer) Plan(ctx context.Context, appDir string, name string, domain string, opts DeployOptions) (*DeployPlan, error) {
+	if !validAppName.MatchString(name) {
+		return nil, fmt.Errorf("invalid app name %q: must be lowercase alphanumeric with hyphens, 1-63 chars, start with letter/number", name)
+	}
+	if err := validateDomain(domain); err != nil {
+		return nil, fmt.Errorf("invalid domain %q: %w", domain, err)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(appDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("app directory does not exist: %s (expected path from write_code workspace)", appDir)
+	}
+
+	compose, err := d.loadComposeFile()
+	if err != nil {
+		return nil, fmt.Errorf("load compose file: %w", err)
+	}
+
+	plan := &DeployPlan{App: name}
+
+	buildDir := findDockerfile(appDir)
+	if buildDir == "" {
+		if dockerfileFor(appDir) == "" {
+			return nil, fmt.Errorf("no Dockerfile found in %s or its subdirectories, and could not auto-detect project type", appDir)
+		}
+		buildDir = appDir
+		plan.Notes = append(plan.Notes, fmt.Sprintf("no Dockerfile found, one will be generated in %s", appDir))
+	}
+
+	previous, exists := compose.Services[name]
+	plan.Create = !exists
+
+	volumesBefore := make(map[string]bool, len(compose.Volumes))
+	for v := range compose.Volumes {
+		volumesBefore[v] = true
+	}
+
+	service, appURL, _, err := d.serviceFor(compose, name, domain, buildDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	plan.URL = appURL
+
+	secretNames, err := secretNames(d.secrets, name)
+	if err != nil {
+		return nil, fmt.Errorf("read secrets: %w", err)
+	}
+	if len(secretNames) > 0 {
+		service.EnvFile = []string{d.secretsFile(name)}
+		plan.Notes = append(plan.Notes, fmt.Sprintf("secrets %s are written to %s (values hidden)", strings.Join(secretNames, ", "), d.secretsFile(name)))
+	}
+
+	plan.Changes = diffServices(previous, service)
+	for v := range compose.Volumes {
+		if !volumesBefore[v] {
+			plan.Changes = append(plan.Changes, fmt.Sprintf("volume %s: created", v))
+		}
+	}
+	if _, ok := compose.Networks[d.network]; !ok {
+		plan.Changes = append(plan.Changes, fmt.Sprintf("network %s: declared as external", d.network))
+	}
+	plan.Notes = append(plan.Notes, fmt.Sprintf("builds %s from %s, then runs docker compose up -d %s", service.Image, service.Build, name))
+
+	data, err := yaml.Marshal(map[string]map[string]ComposeService{"services": {name: service}})
+	if err != nil {
+		return nil, err
+	}
+	plan.Config = string(data)
+
+	return plan, nil
+}
+
+// Plan renders the manifests Deploy would apply and compares them with the running
+// Deployment, without building or applying anything
+func (d *K8sDeployer) Plan(ctx context.Context, appDir string, name string, domain string, opts DeployOptions) (*DeployPlan, error) {
+	if !validAppName.MatchString(name) {
+		return nil, fmt.Errorf("invalid app name %q: must be lowercase alphanumeric with hyphens, 1-63 chars, start with letter/number", name)
+	}
+	if err := validateDomain(domain); err != nil {
+		return nil, fmt.Errorf("invalid domain %q: %w", domain, err)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(opts.Volumes) > 0 {
+		return nil, fmt.Errorf("volumes are only supported with the compose deploy target")
+	}
+	if _, err := os.Stat(appDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("app directory does not exist: %s (expected path from write_code workspace)", appDir)
+	}
+
+	plan := &DeployPlan{App: name}
+
+	buildDir := findDockerfile(appDir)
+	if buildDir == "" {
+		if dockerfileFor(appDir) == "" {
+			return nil, fmt.Errorf("no Dockerfile found in %s or its subdirectories, and could not auto-detect project type", appDir)
+		}
+		buildDir = appDir
+		plan.Notes = append(plan.Notes, fmt.Sprintf("no Dockerfile found, one will be generated in %s", appDir))
+	}
+
+	currentImage := d.currentImage(ctx, name)
+	plan.Create = currentImage == ""
+
+	var current DeployOptions
+	if !plan.Create {
+		current = d.currentOptions(ctx, name)
+	}
+	opts = d.resolveOptions(ctx, name, buildDir, opts)
+
+	secretNames, err := secretNames(d.secrets, name)
+	if err != nil {
+		return nil, fmt.Errorf("read secrets: %w", err)
+	}
+
+	spec := k8sApp{
+		Name:          name,
+		Namespace:     d.namespace,
+		Image:         d.imageTag(name),
+		Port:          opts.Port,
+		Env:           opts.Env,
+		Replicas:      opts.Replicas,
+		Domain:        domain,
+		IngressClass:  d.ingressClass,
+		CertIssuer:    d.certIssuer,
+		SecretEnvFrom: len(secretNames) > 0,
+	}
+	manifests, err := spec.manifests()
+	if err != nil {
+		return nil, fmt.Errorf("render manifests: %w", err)
+	}
+	plan.Config = manifests
+
+	plan.URL = spec.url()
+	if spec.nodePort() && domain != "" {
+		plan.URL = fmt.Sprintf("http://%s:<node port>", domain)
+	}
+
+	if plan.Create {
+		for _, r := range spec.resources() {
+			plan.Changes = append(plan.Changes, fmt.Sprintf("%s: created in namespace %s", r, d.namespace))
+		}
+	}
+	plan.Changes = append(plan.Changes, diffValue("image", currentImage, spec.Image)...)
+	plan.Changes = append(plan.Changes, diffValue("replicas", positive(current.Replicas), positive(opts.Replicas))...)
+	plan.Changes = append(plan.Changes, diffValue("port", positive(current.Port), positive(opts.Port))...)
+	plan.Changes = append(plan.Changes, diffEnv(current.Env, opts.Env)...)
+
+	build := fmt.Sprintf("builds %s from %s", spec.Image, buildDir)
+	if d.registry != "" {
+		build += " and pushes it"
+	}
+	plan.Notes = append(plan.Notes, build+", then runs kubectl apply and waits for the rollout")
+	if len(secretNames) > 0 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("secrets %s are synced into the %s-secrets Secret (values hidden)", strings.Join(secretNames, ", "), name))
+	}
+
+	return plan, nil
+}
+
+// secretNames lists an app's secret names, sorted, without their values
+func secretNames(source SecretSource, name string) ([]string, error) {
+	if source == nil {
+		return nil, nil
+	}
+	env, err := source.Env(name)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(env))
+	for k := range env {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// diffServices lists the differences between an app's current and planned service,
+// field by field; a zero old service shows everything as added
+func diffServices(old, new ComposeService) []string {
+	var changes []string
+	changes = append(changes, diffValue("image", old.Image, new.Image)...)
+	changes = append(changes, diffValue("build", old.Build, new.Build)...)
+	changes = append(changes, diffValue("restart", old.Restart, new.Restart)...)
+	changes = append(changes, diffValue("replicas", replicas(old), replicas(new))...)
+	changes = append(changes, diffEnv(old.Environment, new.Environment)...)
+	changes = append(changes, diffList("env_file", old.EnvFile, new.EnvFile)...)
+	changes = append(changes, diffList("expose", old.Expose, new.Expose)...)
+	changes = append(changes, diffList("ports", old.Ports, new.Ports)...)
+	changes = append(changes, diffList("volumes", old.Volumes, new.Volumes)...)
+	changes = append(changes, diffList("networks", old.Networks, new.Networks)...)
+	changes = append(changes, diffList("labels", old.Labels, new.Labels)...)
+	return changes
+}
+
+func replicas(svc ComposeService) string {
+	if svc.Deploy == nil {
+		return ""
+	}
+	return positive(svc.Deploy.Replicas)
+}
+
+func positive(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func diffValue(field, old, new string) []string {
+	switch {
+	case old == new:
+		return nil
+	case old == "":
+		return []string{fmt.Sprintf("%s: + %s", field, new)}
+	case new == "":
+		return []string{fmt.Sprintf("%s: - %s", field, old)}
+	}
+	return []string{fmt.Sprintf("%s: %s → %s", field, old, new)}
+}
+
+func diffList(field string, old, new []string) []string {
+	oldSet := make(map[string]bool, len(old))
+	for _, v := range old {
+		oldSet[v] = true
+	}
+	newSet := make(map[string]bool, len(new))
+	for _, v := range new {
+		newSet[v] = true
+	}
+
+	var changes []string
+	for _, v := range old {
+		if !newSet[v] {
+			changes = append(changes, fmt.Sprintf("%s: - %s", field, v))
+		}
+	}
+	for _, v := range new {
+		if !oldSet[v] {
+			changes = append(changes, fmt.Sprintf("%s: + %s", field, v))
+		}
+	}
+	return changes
+}
+
+func diffEnv(old, new map[string]string) []string {
+	keys := make(map[string]bool, len(old)+len(new))
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, k := range sorted {
+		oldValue, inOld := old[k]
+		newValue, inNew := new[k]
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("env %s: + %s", k, newValue))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("env %s: - %s", k, oldValue))
+		case oldValue != newValue:
+			changes = append(changes, fmt.Sprintf("env %s: %s → %s", k, oldValue, newValue))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,118 @@
+package deployer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Env(app string) (map[string]string, error) {
+	return s, nil
+}
+
+func TestComposePlanNewApp(t *testing.T) {
+	dir := t.TempDir()
+	appDir := filepath.Join(dir, "weather")
+	os.MkdirAll(appDir, 0755)
+	os.WriteFile(filepath.Join(appDir, "index.html"), []byte("<h1>hi</h1>"), 0644)
+
+	d := NewComposeDeployer(ComposeDeployerConfig{AppsFile: filepath.Join(dir, "apps.yml")})
+	d.SetSecrets(staticSecrets{"API_KEY": "hunter2-secret"})
+
+	plan, err := d.Plan(context.Background(), appDir, "weather", "example.com", DeployOptions{Port: 3000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !plan.Create || plan.URL != "https://weather.example.com" {
+		t.Errorf("plan = %+v", plan)
+	}
+	for _, want := range []string{
+		"labels: + traefik.http.routers.weather.rule=Host(`weather.example.com`)",
+		"expose: + 3000",
+		"network sheldon-net: declared as external",
+	} {
+		if !slices.Contains(plan.Changes, want) {
+			t.Errorf("missing change %q in %v", want, plan.Changes)
+		}
+	}
+
+	notes := strings.Join(plan.Notes, "\n")
+	if !strings.Contains(notes, "Dockerfile") || !strings.Contains(notes, "API_KEY") {
+		t.Errorf("notes = %v", plan.Notes)
+	}
+	if strings.Contains(notes+plan.Config+strings.Join(plan.Changes, "\n"), "hunter2") {
+		t.Error("plan leaked a secret value")
+	}
+
+	// a plan writes nothing
+	if _, err := os.Stat(filepath.Join(dir, "apps.yml")); !os.IsNotExist(err) {
+		t.Error("plan wrote apps.yml")
+	}
+	if _, err := os.Stat(filepath.Join(appDir, "Dockerfile")); !os.IsNotExist(err) {
+		t.Error("plan wrote a Dockerfile")
+	}
+}
+
+func TestComposePlanUpdate(t *testing.T) {
+	dir := t.TempDir()
+	appsFile := filepath.Join(dir, "apps.yml")
+	appDir := filepath.Join(dir, "weather")
+	os.MkdirAll(appDir, 0755)
+	os.WriteFile(filepath.Join(appDir, "Dockerfile"), []byte("FROM nginx:alpine\n"), 0644)
+
+	d := NewComposeDeployer(ComposeDeployerConfig{AppsFile: appsFile})
+	compose := &ComposeFile{
+		Services: map[string]ComposeService{
+			"weather": {
+				Image:       "sheldon-app/weather:20260101-000000",
+				Build:       appDir,
+				Restart:     "unless-stopped",
+				Environment: map[string]string{"LOG_LEVEL": "info", "OLD": "1"},
+				Networks:    []string{"sheldon-net"},
+			},
+		},
+		Networks: map[string]ComposeNetwork{"sheldon-net": {External: true}},
+	}
+	if err := d.saveComposeFile(compose); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(appsFile)
+
+	plan, err := d.Plan(context.Background(), appDir, "weather", "localhost", DeployOptions{
+		Env:     map[string]string{"LOG_LEVEL": "debug"},
+		Volumes: []string{"data:/data"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Create {
+		t.Error("existing app planned as new")
+	}
+	for _, want := range []string{
+		"env LOG_LEVEL: info → debug",
+		"env OLD: - 1",
+		"volumes: + weather-data:/data",
+		"volume weather-data: created",
+	} {
+		if !slices.Contains(plan.Changes, want) {
+			t.Errorf("missing change %q in %v", want, plan.Changes)
+		}
+	}
+	for _, c := range plan.Changes {
+		if strings.HasPrefix(c, "network ") || strings.HasPrefix(c, "restart") || strings.HasPrefix(c, "build") {
+			t.Errorf("unexpected change %q", c)
+		}
+	}
+
+	after, _ := os.ReadFile(appsFile)
+	if string(before) != string(after) {
+		t.Error("plan modified apps.yml")
+	}
+}
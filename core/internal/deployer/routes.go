@@ -0,0 +1,214 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	hostRule      = regexp.MustCompile("Host\\(`([^`]+)`\\)")
+	validAuthUser = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+)
+
+// parseRoute reads an app's routing back from its service labels and ports
+func parseRoute(name string, svc ComposeService) Route {
+	route := Route{App: name}
+
+	router := "traefik.http.routers." + name + "."
+	for _, label := range svc.Labels {
+		key, value, _ := strings.Cut(label, "=")
+		switch key {
+		case router + "rule":
+			for _, m := range hostRule.FindAllStringSubmatch(value, -1) {
+				route.Hosts = append(route.Hosts, m[1])
+			}
+		case router + "entrypoints":
+			route.EntryPoint = value
+		case router + "tls.certresolver":
+			route.TLS = true
+		case "traefik.http.services." + name + ".loadbalancer.server.port":
+			route.Port, _ = strconv.Atoi(value)
+		case "traefik.http.middlewares." + name + "-auth.basicauth.users":
+			// compose would interpolate a single $, so hashes are stored with $$
+			route.AuthUsers = strings.Split(strings.ReplaceAll(value, "$$", "$"), ",")
+		}
+	}
+
+	for _, mapping := range svc.Ports {
+		if port, err := strconv.Atoi(strings.Split(mapping, ":")[0]); err == nil {
+			route.HostPort = port
+			break
+		}
+	}
+
+	return route
+}
+
+// labels renders the Traefik labels for a route
+func (r Route) labels() []string {
+	rules := make([]string, len(r.Hosts))
+	for i, host := range r.Hosts {
+		rules[i] = fmt.Sprintf("Host(`%s`)", host)
+	}
+
+	labels := []string{
+		"traefik.enable=true",
+		fmt.Sprintf("traefik.http.routers.%s.rule=%s", r.App, strings.Join(rules, " || ")),
+		fmt.Sprintf("traefik.http.routers.%s.entrypoints=%s", r.App, r.EntryPoint),
+	}
+	if r.TLS {
+		labels = append(labels, fmt.Sprintf("traefik.http.routers.%s.tls.certresolver=letsencrypt", r.App))
+	}
+	if len(r.AuthUsers) > 0 {
+		users := strings.ReplaceAll(strings.Join(r.AuthUsers, ","), "$", "$$")
+		labels = append(labels,
+			fmt.Sprintf("traefik.http.middlewares.%s-auth.basicauth.users=%s", r.App, users),
+			fmt.Sprintf("traefik.http.routers.%s.middlewares=%s-auth", r.App, r.App),
+		)
+	}
+	if r.Port > 0 {
+		labels = append(labels, fmt.Sprintf("traefik.http.services.%s.loadbalancer.server.port=%d", r.App, r.Port))
+	}
+	return labels
+}
+
+// URL returns the address the app is reached at through its default host
+func (r Route) URL() string {
+	if len(r.Hosts) == 0 {
+		return ""
+	}
+	scheme := "http"
+	if r.TLS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Hosts[0])
+}
+
+// AuthUserNames returns the basic auth user names without their hashes
+func (r Route) AuthUserNames() []string {
+	names := make([]string, 0, len(r.AuthUsers))
+	for _, u := range r.AuthUsers {
+		name, _, _ := strings.Cut(u, ":")
+		names = append(names, name)
+	}
+	return names
+}
+
+// HTPasswd returns a bcrypt htpasswd entry for Traefik's basic auth
+func HTPasswd(user, password string) (string, error) {
+	if !validAuthUser.MatchString(user) {
+		return "", fmt.Errorf("invalid user name %q: letters, digits, dots, dashes and underscores only", user)
+	}
+	if len(password) < 8 {
+		return "", fmt.Errorf("password must be at least 8 characters")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return user + ":" + string(hash), nil
+}
+
+// Routes returns the routing of every app in apps.yml, sorted by name
+func (d *ComposeDeployer) Routes() ([]Route, error) {
+	compose, err := d.loadComposeFile()
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]Route, 0, len(compose.Services))
+	for name, svc := range compose.Services {
+		routes = append(routes, parseRoute(name, svc))
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].App < routes[j].App })
+	return routes, nil
+}
+
+// AddDomain routes an extra host name to an app, on top of name.domain
+func (d *ComposeDeployer) AddDomain(ctx context.Context, name, host string) (*Route, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return nil, fmt.Errorf("invalid domain %q: must be a DNS name like app.example.org", host)
+	}
+	if err := validateDomain(host); err != nil {
+		return nil, fmt.Errorf("invalid domain %q: %w", host, err)
+	}
+
+	return d.updateRoute(ctx, name, func(compose *ComposeFile, route *Route) error {
+		for other, svc := range compose.Services {
+			if slices.Contains(parseRoute(other, svc).Hosts, host) {
+				return fmt.Errorf("%s is already routed to %s", host, other)
+			}
+		}
+		route.Hosts = append(route.Hosts, host)
+		return nil
+	})
+}
+
+// RemoveDomain stops routing a custom host name to an app
+func (d *ComposeDeployer) RemoveDomain(ctx context.Context, name, host string) (*Route, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	return d.updateRoute(ctx, name, func(compose *ComposeFile, route *Route) error {
+		i := slices.Index(route.Hosts, host)
+		switch {
+		case i < 0:
+			return fmt.Errorf("%s isn't routed to %s", host, name)
+		case i == 0:
+			return fmt.Errorf("%s is the app's default domain; use remove_app to take it down", host)
+		}
+		route.Hosts = slices.Delete(route.Hosts, i, i+1)
+		return nil
+	})
+}
+
+// SetBasicAuth puts the app behind basic auth for the given htpasswd entries, or
+// removes it when there are none
+func (d *ComposeDeployer) SetBasicAuth(ctx context.Context, name string, users []string) (*Route, error) {
+	return d.updateRoute(ctx, name, func(compose *ComposeFile, route *Route) error {
+		route.AuthUsers = users
+		return nil
+	})
+}
+
+// updateRoute applies a change to an app's route, saves apps.yml and recreates the
+// container so Traefik picks up the new labels; the image isn't rebuilt
+func (d *ComposeDeployer) updateRoute(ctx context.Context, name string, change func(*ComposeFile, *Route) error) (*Route, error) {
+	compose, err := d.loadComposeFile()
+	if err != nil {
+		return nil, fmt.Errorf("load compose file: %w", err)
+	}
+	service, exists := compose.Services[name]
+	if !exists {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+
+	route := parseRoute(name, service)
+	if len(route.Hosts) == 0 {
+		return nil, fmt.Errorf("%s isn't routed through Traefik; apps deployed by IP need a DOMAIN for custom domains and basic auth", name)
+	}
+	if err := change(compose, &route); err != nil {
+		return nil, err
+	}
+
+	service.Labels = route.labels()
+	compose.Services[name] = service
+	if err := d.saveComposeFile(compose); err != nil {
+		return nil, fmt.Errorf("save compose file: %w", err)
+	}
+	if err := d.composeStart(ctx, name); err != nil {
+		return nil, err
+	}
+
+	logger.Info("app route updated", "name", name, "hosts", route.Hosts, "auth", len(route.AuthUsers) > 0)
+	return &route, nil
+}
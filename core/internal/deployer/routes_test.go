@@ -0,0 +1,92 @@
+package deployer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRouteLabelsRoundTrip(t *testing.T) {
+	route := Route{
+		App:        "weather",
+		Hosts:      []string{"weather.example.com", "weather.org"},
+		EntryPoint: "websecure",
+		TLS:        true,
+		Port:       3000,
+		AuthUsers:  []string{"admin:$2a$10$abc"},
+	}
+
+	labels := route.labels()
+	if !slices.Contains(labels, "traefik.http.routers.weather.rule=Host(`weather.example.com`) || Host(`weather.org`)") {
+		t.Errorf("rule missing from %v", labels)
+	}
+	if !slices.Contains(labels, "traefik.http.middlewares.weather-auth.basicauth.users=admin:$$2a$$10$$abc") {
+		t.Errorf("auth users not escaped for compose: %v", labels)
+	}
+
+	got := parseRoute("weather", ComposeService{Labels: labels})
+	if !slices.Equal(got.Hosts, route.Hosts) || !got.TLS || got.Port != 3000 || got.EntryPoint != "websecure" {
+		t.Errorf("parsed %+v", got)
+	}
+	if !slices.Equal(got.AuthUsers, route.AuthUsers) {
+		t.Errorf("auth users = %v", got.AuthUsers)
+	}
+	if got.URL() != "https://weather.example.com" || !slices.Equal(got.AuthUserNames(), []string{"admin"}) {
+		t.Errorf("URL = %s, users = %v", got.URL(), got.AuthUserNames())
+	}
+}
+
+func TestParseRouteIPApp(t *testing.T) {
+	got := parseRoute("weather", ComposeService{Ports: []string{"8081:80"}})
+	if len(got.Hosts) != 0 || got.HostPort != 8081 {
+		t.Errorf("parsed %+v", got)
+	}
+}
+
+func TestRedeployKeepsCustomDomainsAndAuth(t *testing.T) {
+	d := NewComposeDeployer(ComposeDeployerConfig{AppsFile: t.TempDir() + "/apps.yml"})
+	previous := Route{
+		App:        "weather",
+		Hosts:      []string{"weather.old.com", "weather.org"},
+		EntryPoint: "websecure",
+		TLS:        true,
+		AuthUsers:  []string{"admin:hash"},
+	}
+	compose := &ComposeFile{Services: map[string]ComposeService{"weather": {Labels: previous.labels()}}}
+
+	service, url, _, err := d.serviceFor(compose, "weather", "example.com", "/apps/weather", DeployOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := parseRoute("weather", service)
+	if !slices.Equal(got.Hosts, []string{"weather.example.com", "weather.org"}) {
+		t.Errorf("hosts = %v", got.Hosts)
+	}
+	if !slices.Equal(got.AuthUsers, []string{"admin:hash"}) {
+		t.Errorf("auth users = %v", got.AuthUsers)
+	}
+	if url != "https://weather.example.com" {
+		t.Errorf("url = %s", url)
+	}
+}
+
+func TestHTPasswd(t *testing.T) {
+	entry, err := HTPasswd("admin", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, hash, _ := strings.Cut(entry, ":")
+	if user != "admin" || bcrypt.CompareHashAndPassword([]byte(hash), []byte("correct horse")) != nil {
+		t.Errorf("bad entry %q", entry)
+	}
+
+	if _, err := HTPasswd("ad:min", "correct horse"); err == nil {
+		t.Error("expected error for user with colon")
+	}
+	if _, err := HTPasswd("admin", "short"); err == nil {
+		t.Error("expected error for short password")
+	}
+}
@@ -1,5 +1,24 @@
 package deployer
 
+import (
+	"context"
+	"time"
+)
+
+// Deployer runs apps on a deploy target: docker compose (ComposeDeployer) or kubernetes (K8sDeployer)
+type Deployer interface {
+	Deploy(ctx context.Context, appDir string, name string, domain string, opts DeployOptions) (*DeployResult, error)
+	Plan(ctx context.Context, appDir string, name string, domain string, opts DeployOptions) (*DeployPlan, error)
+	Remove(ctx context.Context, name string) error
+	List(ctx context.Context) ([]string, error)
+	Status(ctx context.Context, name string) (string, error)
+	Logs(ctx context.Context, name string, lines int) (string, error)
+	Rollback(ctx context.Context, name string, id int64) (*Deployment, error)
+	History(name string, limit int) ([]Deployment, error)
+	SetSecrets(secrets SecretSource)
+	SetHistory(history *History)
+}
+
 type BuildResult struct {
 	ImageName string
 	ImageTag  string
@@ -12,4 +31,68 @@ type DeployResult struct {
 	Status    string
 	URL       string // full URL to access the app (e.g., http://1.2.3.4:8080 or https://app.example.com)
 	Port      int    // exposed port (for IP-only deployments)
+
+	DeploymentID int64 // history entry for this deploy, 0 if history is off
+}
+
+// DeployPlan describes what a deploy would change, without building or applying anything
+type DeployPlan struct {
+	App     string
+	Create  bool // the app isn't deployed yet
+	URL     string
+	Changes []string // one line per added, removed or changed setting
+	Notes   []string // side effects that aren't config changes, e.g. a generated Dockerfile
+	Config  string   // the resulting service definition or manifests
+}
+
+// RouteManager inspects and edits how Traefik routes to deployed apps
+type RouteManager interface {
+	Routes() ([]Route, error)
+	AddDomain(ctx context.Context, name, host string) (*Route, error)
+	RemoveDomain(ctx context.Context, name, host string) (*Route, error)
+	SetBasicAuth(ctx context.Context, name string, users []string) (*Route, error)
+}
+
+// Route is an app's Traefik routing, read back from its labels
+type Route struct {
+	App        string
+	Hosts      []string // name.domain first, then custom domains
+	EntryPoint string   // websecure (HTTPS) or web
+	TLS        bool
+	Port       int      // container port Traefik forwards to, 0 = Traefik's default
+	AuthUsers  []string // htpasswd entries for basic auth, empty when it's off
+	HostPort   int      // IP-only apps skip Traefik and publish this port instead
+}
+
+// SecretSource provides the environment secrets injected into an app at deploy time
+type SecretSource interface {
+	Env(app string) (map[string]string, error)
+}
+
+// Deployment is one recorded deploy or rollback of an app
+type Deployment struct {
+	ID         int64
+	App        string
+	Image      string // versioned tag, e.g. sheldon-app/weather:20260101-120000
+	Digest     string // image ID the tag pointed to when deployed
+	Branch     string // git branch of the app dir, if it was a repo
+	Commit     string
+	RollbackOf int64 // deployment this rolled back to, 0 for normal deploys
+	CreatedAt  time.Time
+}
+
+// DeployOptions are per-app settings for deploy_app. Zero fields keep whatever the app
+// was last deployed with.
+type DeployOptions struct {
+	Env      map[string]string // plain configuration; secrets belong in the secrets store
+	Port     int               // port the app listens on inside the container
+	Volumes  []string          // persistent named volumes as name:/container/path[:ro]
+	Replicas int
+}
+
+// Volume is a parsed DeployOptions volume
+type Volume struct {
+	Name     string
+	Path     string
+	ReadOnly bool
 }
@@ -0,0 +1,289 @@
+// Package documents turns uploaded files into text chunks for indexing in memory
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	chunkSize    = 1200 // characters per chunk, small enough for a focused embedding
+	chunkOverlap = 200  // carried over so sentences split across chunks stay searchable
+)
+
+// DOCXMimeType is what mail clients and chat apps send Word documents as
+const DOCXMimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// maxDOCXSize bounds word/document.xml, which is far bigger than the text it holds
+const maxDOCXSize = 50 << 20
+
+var ErrUnsupported = errors.New("unsupported document type")
+
+// textExtensions are indexed as-is when the mime type is missing or generic
+var textExtensions = map[string]bool{
+	".txt": true, ".md": true, ".markdown": true, ".csv": true, ".json": true,
+	".yaml": true, ".yml": true, ".log": true, ".html": true, ".xml": true,
+}
+
+// Supported reports whether a file can be indexed
+func Supported(mimeType, filename string) bool {
+	return IsPDF(mimeType, filename) || IsDOCX(mimeType, filename) || isText(mimeType, filename)
+}
+
+// ExtractText returns the plain text of a PDF, Word or text file.
+// PDFs are converted with pdftotext from poppler-utils.
+func ExtractText(ctx context.Context, data []byte, mimeType, filename string) (string, error) {
+	switch {
+	case IsPDF(mimeType, filename):
+		return pdfText(ctx, data)
+	case IsDOCX(mimeType, filename):
+		return docxText(data)
+	case isText(mimeType, filename):
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("%s is not valid UTF-8 text", filename)
+		}
+		return string(data), nil
+	default:
+		return "", ErrUnsupported
+	}
+}
+
+func pdfText(ctx context.Context, data []byte) (string, error) {
+	return PDFPages(ctx, data, 0, 0)
+}
+
+// PDFPages returns the text of pages first through last (1-based). Zero means
+// from the start or to the end.
+func PDFPages(ctx context.Context, data []byte, first, last int) (string, error) {
+	args := []string{"-layout"}
+	if first > 0 {
+		args = append(args, "-f", strconv.Itoa(first))
+	}
+	if last > 0 {
+		args = append(args, "-l", strconv.Itoa(last))
+	}
+	args = append(args, "-", "-")
+
+	cmd := exec.CommandContext(ctx, "pdftotext", args...)
+	cmd.Stdin = bytes.NewReader(data)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("pdftotext: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// PDFPageCount reads the page count with pdfinfo
+func PDFPageCount(ctx context.Context, data []byte) (int, error) {
+	cmd := exec.CommandContext(ctx, "pdfinfo", "-")
+	cmd.Stdin = bytes.NewReader(data)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("pdfinfo: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if rest, ok := strings.CutPrefix(line, "Pages:"); ok {
+			return strconv.Atoi(strings.TrimSpace(rest))
+		}
+	}
+	return 0, fmt.Errorf("pdfinfo: no page count")
+}
+
+// PDFPageImages renders pages first through last as PNGs with pdftoppm, for
+// models that can look at images but can't read PDFs (or scanned PDFs with no text)
+func PDFPageImages(ctx context.Context, data []byte, first, last, dpi int) ([][]byte, error) {
+	dir, err := os.MkdirTemp("", "pdfpages")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.CommandContext(ctx, "pdftoppm", "-png", "-r", strconv.Itoa(dpi),
+		"-f", strconv.Itoa(first), "-l", strconv.Itoa(last), "-", filepath.Join(dir, "page"))
+	cmd.Stdin = bytes.NewReader(data)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftoppm: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// pdftoppm zero-pads page numbers to the width of the page count, so names sort in order
+	files, err := filepath.Glob(filepath.Join(dir, "page-*.png"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	images := make([][]byte, 0, len(files))
+	for _, f := range files {
+		img, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// docxText pulls paragraphs out of word/document.xml. Tables come out one cell
+// per line, which is good enough to answer questions about them.
+func docxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("open docx: %w", err)
+	}
+
+	var body io.ReadCloser
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			if body, err = f.Open(); err != nil {
+				return "", fmt.Errorf("open docx body: %w", err)
+			}
+			break
+		}
+	}
+	if body == nil {
+		return "", fmt.Errorf("docx has no word/document.xml")
+	}
+	defer body.Close()
+
+	var b strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(body, maxDOCXSize))
+	inText := false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parse docx: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString("\t")
+			case "br", "cr":
+				b.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+// IsDOCX reports whether a file is a Word document
+func IsDOCX(mimeType, filename string) bool {
+	return mimeType == DOCXMimeType || strings.EqualFold(filepath.Ext(filename), ".docx")
+}
+
+// IsPDF reports whether a file is a PDF
+func IsPDF(mimeType, filename string) bool {
+	return mimeType == "application/pdf" || strings.EqualFold(filepath.Ext(filename), ".pdf")
+}
+
+func isText(mimeType, filename string) bool {
+	return strings.HasPrefix(mimeType, "text/") || textExtensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// Chunk splits text into overlapping pieces, breaking on paragraph or line
+// boundaries where possible
+func Chunk(text string) []string {
+	text = normalize(text)
+	if text == "" {
+		return nil
+	}
+
+	runes := []rune(text)
+	var chunks []string
+
+	for start := 0; start < len(runes); {
+		end := min(start+chunkSize, len(runes))
+		if end < len(runes) {
+			end = breakPoint(runes, start, end)
+		}
+
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(runes) {
+			break
+		}
+
+		start = max(end-chunkOverlap, start+1)
+	}
+
+	return chunks
+}
+
+// breakPoint moves end back to the last paragraph, line or sentence break in
+// the second half of the chunk
+func breakPoint(runes []rune, start, end int) int {
+	window := string(runes[start:end])
+	half := len(window) / 2
+
+	for _, sep := range []string{"\n\n", "\n", ". ", " "} {
+		if i := strings.LastIndex(window, sep); i > half {
+			return start + utf8.RuneCountInString(window[:i+len(sep)])
+		}
+	}
+	return end
+}
+
+// normalize collapses the runs of blank lines and trailing spaces pdftotext leaves behind
+func normalize(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var out []string
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\f")
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
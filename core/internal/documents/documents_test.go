@@ -0,0 +1,93 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestChunkShortText(t *testing.T) {
+	chunks := Chunk("  hello\n\n\n\nworld  ")
+	if len(chunks) != 1 || chunks[0] != "hello\n\nworld" {
+		t.Errorf("expected one normalized chunk, got %q", chunks)
+	}
+}
+
+func TestChunkOverlapAndBounds(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		b.WriteString("This is sentence number one of many. ")
+	}
+
+	chunks := Chunk(b.String())
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for i, c := range chunks {
+		if len([]rune(c)) > chunkSize {
+			t.Errorf("chunk %d has %d runes, over the %d limit", i, len([]rune(c)), chunkSize)
+		}
+		if !strings.HasSuffix(c, ".") && i < len(chunks)-1 {
+			t.Errorf("chunk %d should end on a sentence break: %q", i, c[len(c)-20:])
+		}
+	}
+
+	tail := chunks[0][len(chunks[0])-50:]
+	if !strings.Contains(chunks[1], tail) {
+		t.Error("expected consecutive chunks to overlap")
+	}
+}
+
+func TestChunkEmpty(t *testing.T) {
+	if chunks := Chunk(" \n\n "); chunks != nil {
+		t.Errorf("expected no chunks, got %q", chunks)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	cases := []struct {
+		mime, name string
+		want       bool
+	}{
+		{"application/pdf", "", true},
+		{"", "Lease.PDF", true},
+		{"text/plain", "notes", true},
+		{"application/octet-stream", "README.md", true},
+		{DOCXMimeType, "", true},
+		{"", "Offer Letter.docx", true},
+		{"image/png", "photo.png", false},
+	}
+	for _, c := range cases {
+		if got := Supported(c.mime, c.name); got != c.want {
+			t.Errorf("Supported(%q, %q) = %v, want %v", c.mime, c.name, got, c.want)
+		}
+	}
+}
+
+func TestExtractDOCX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("word/document.xml")
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Notice period:</w:t></w:r><w:r><w:tab/><w:t xml:space="preserve"> 3 months</w:t></w:r></w:p>
+<w:p><w:r><w:t>Line one</w:t><w:br/><w:t>Line two &amp; more</w:t></w:r></w:p>
+</w:body></w:document>`))
+	zw.Close()
+
+	text, err := ExtractText(context.Background(), buf.Bytes(), "", "contract.docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Notice period:\t 3 months\nLine one\nLine two & more\n"
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+
+	if _, err := ExtractText(context.Background(), []byte("not a zip"), DOCXMimeType, ""); err == nil {
+		t.Error("expected an error for a broken docx")
+	}
+}
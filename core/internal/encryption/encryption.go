@@ -0,0 +1,122 @@
+// Package encryption derives the memory encryption key from a secret and
+// seals data with it: sensitive fact values, app secrets and backups. The
+// memory database itself is not encrypted, only those values inside it.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// SaltSize is the length of the random salt each database keeps for its key
+const SaltSize = 16
+
+// legacySalt is the fixed salt keys were derived with before each database
+// had its own; LegacyKey still uses it so older data can be re-sealed
+var legacySalt = []byte("sheldon-memory-v1")
+
+// exportMagic starts data sealed with SealExport, followed by the salt
+var exportMagic = []byte("sheldon-enc-v2\n")
+
+var ErrCiphertext = errors.New("ciphertext too short or key mismatch")
+
+// Key is an AES-256 key and the salt it was derived with
+type Key struct {
+	key  []byte
+	salt []byte
+}
+
+// NewSalt returns a random salt for a new database
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey stretches a secret into a key with argon2id, using the
+// parameters RFC 9106 recommends when memory is constrained
+func DeriveKey(secret string, salt []byte) *Key {
+	return &Key{key: argon2.IDKey([]byte(secret), salt, 3, 64*1024, 4, 32), salt: salt}
+}
+
+// LegacyKey is the key earlier versions derived with a fixed salt and a
+// single pass. Use it only to read data sealed before per-database salts.
+func LegacyKey(secret string) *Key {
+	return &Key{key: argon2.IDKey([]byte(secret), legacySalt, 1, 64*1024, 4, 32), salt: legacySalt}
+}
+
+// Seal encrypts data with AES-256-GCM, prefixing the random nonce
+func (k *Key) Seal(data []byte) ([]byte, error) {
+	aead, err := newAEAD(k.key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Open decrypts data produced by Seal
+func (k *Key) Open(data []byte) ([]byte, error) {
+	aead, err := newAEAD(k.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, ErrCiphertext
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrCiphertext
+	}
+	return plain, nil
+}
+
+// SealExport seals data that leaves the database, like a backup. The salt
+// goes in front so OpenExport can derive the key from the secret alone.
+func (k *Key) SealExport(data []byte) ([]byte, error) {
+	sealed, err := k.Seal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(exportMagic)+len(k.salt)+len(sealed))
+	out = append(out, exportMagic...)
+	out = append(out, k.salt...)
+	return append(out, sealed...), nil
+}
+
+// OpenExport decrypts data produced by SealExport, or by Seal under the
+// legacy key for backups made before per-database salts
+func OpenExport(secret string, data []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, exportMagic)
+	if !ok {
+		return LegacyKey(secret).Open(data)
+	}
+	if len(rest) < SaltSize {
+		return nil, ErrCiphertext
+	}
+	return DeriveKey(secret, rest[:SaltSize]).Open(rest[SaltSize:])
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
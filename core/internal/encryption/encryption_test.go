@@ -0,0 +1,84 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testKey(t *testing.T, secret string) *Key {
+	t.Helper()
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return DeriveKey(secret, salt)
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	key := testKey(t, "correct horse battery staple")
+	data := []byte("memory backup")
+
+	sealed, err := key.Seal(data)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if bytes.Contains(sealed, data) {
+		t.Error("sealed data contains plaintext")
+	}
+
+	plain, err := key.Open(sealed)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !bytes.Equal(plain, data) {
+		t.Errorf("expected %q, got %q", data, plain)
+	}
+}
+
+func TestOpenWrongKey(t *testing.T) {
+	sealed, _ := testKey(t, "one").Seal([]byte("secret"))
+
+	if _, err := testKey(t, "two").Open(sealed); err != ErrCiphertext {
+		t.Errorf("expected ErrCiphertext, got %v", err)
+	}
+}
+
+func TestDeriveKeySalted(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	if !bytes.Equal(DeriveKey("secret", salt).key, DeriveKey("secret", salt).key) {
+		t.Error("expected same key for same secret and salt")
+	}
+	if len(DeriveKey("secret", salt).key) != 32 {
+		t.Error("expected 32-byte key")
+	}
+
+	other := []byte("fedcba9876543210")
+	if bytes.Equal(DeriveKey("secret", salt).key, DeriveKey("secret", other).key) {
+		t.Error("expected different salts to give different keys")
+	}
+	if bytes.Equal(DeriveKey("secret", legacySalt).key, LegacyKey("secret").key) {
+		t.Error("expected the legacy key to keep its old parameters")
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	data := []byte("zip bytes")
+
+	sealed, err := testKey(t, "backup-secret").SealExport(data)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	plain, err := OpenExport("backup-secret", sealed)
+	if err != nil || !bytes.Equal(plain, data) {
+		t.Errorf("expected %q, got %q, %v", data, plain, err)
+	}
+	if _, err := OpenExport("wrong", sealed); err != ErrCiphertext {
+		t.Errorf("expected ErrCiphertext for the wrong secret, got %v", err)
+	}
+
+	// backups from before per-database salts still open
+	legacy, _ := LegacyKey("backup-secret").Seal(data)
+	if plain, err := OpenExport("backup-secret", legacy); err != nil || !bytes.Equal(plain, data) {
+		t.Errorf("expected legacy backup to open, got %q, %v", plain, err)
+	}
+}
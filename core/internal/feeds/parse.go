@@ -0,0 +1,148 @@
+package feeds
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// xmlFeed covers RSS 2.0, RSS 1.0 (RDF) and Atom in a single decode
+type xmlFeed struct {
+	XMLName xml.Name
+	Title   string     `xml:"title"`
+	Channel xmlChannel `xml:"channel"`
+	Items   []xmlEntry `xml:"item"`
+	Entries []xmlEntry `xml:"entry"`
+}
+
+type xmlChannel struct {
+	Title string     `xml:"title"`
+	Items []xmlEntry `xml:"item"`
+}
+
+type xmlEntry struct {
+	Title     string    `xml:"title"`
+	Links     []xmlLink `xml:"link"`
+	GUID      string    `xml:"guid"`
+	ID        string    `xml:"id"`
+	About     string    `xml:"about,attr"`
+	PubDate   string    `xml:"pubDate"`
+	Date      string    `xml:"date"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+}
+
+// xmlLink is an RSS <link>url</link> or an Atom <link href="url" rel="..."/>
+type xmlLink struct {
+	Href  string `xml:"href,attr"`
+	Rel   string `xml:"rel,attr"`
+	Value string `xml:",chardata"`
+}
+
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+}
+
+// Parse reads an RSS or Atom document and returns its title and items
+func Parse(data []byte) (string, []Item, error) {
+	var doc xmlFeed
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		return "", nil, fmt.Errorf("not a valid feed: %w", err)
+	}
+
+	var title string
+	var entries []xmlEntry
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		title = doc.Channel.Title
+		entries = doc.Channel.Items
+	case "rdf":
+		title = doc.Channel.Title
+		entries = doc.Items
+	case "feed":
+		title = doc.Title
+		entries = doc.Entries
+	default:
+		return "", nil, fmt.Errorf("not a feed: unexpected <%s> document", doc.XMLName.Local)
+	}
+
+	items := make([]Item, 0, len(entries))
+	for _, e := range entries {
+		item := Item{
+			Title:     cleanText(e.Title),
+			Link:      e.link(),
+			Published: parseFeedTime(e.PubDate, e.Published, e.Date, e.Updated),
+		}
+		item.GUID = firstNonEmpty(e.GUID, e.ID, e.About, item.Link)
+		if item.GUID == "" {
+			sum := sha256.Sum256([]byte(item.Title))
+			item.GUID = hex.EncodeToString(sum[:8])
+		}
+		if item.Title == "" && item.Link == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return cleanText(title), items, nil
+}
+
+func (e xmlEntry) link() string {
+	for _, l := range e.Links {
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	for _, l := range e.Links {
+		if v := strings.TrimSpace(l.Value); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func parseFeedTime(values ...string) time.Time {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		for _, layout := range feedTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// cleanText collapses whitespace so titles fit on one line
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,77 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+)
+
+const sampleRSS = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss version="2.0">
+<channel>
+  <title>Example News</title>
+  <item>
+    <title>First
+      story</title>
+    <link>https://example.com/1</link>
+    <guid isPermaLink="false">story-1</guid>
+    <pubDate>Fri, 14 Mar 2025 09:12:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>Second story</title>
+    <link>https://example.com/2</link>
+  </item>
+</channel>
+</rss>`
+
+const sampleAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Blog</title>
+  <entry>
+    <title>Hello</title>
+    <link rel="edit" href="https://example.com/edit/1"/>
+    <link href="https://example.com/hello"/>
+    <id>tag:example.com,2025:1</id>
+    <updated>2025-03-14T09:12:00Z</updated>
+  </entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	title, items, err := Parse([]byte(sampleRSS))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if title != "Example News" {
+		t.Errorf("unexpected title %q", title)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0].Title != "First story" || items[0].GUID != "story-1" {
+		t.Errorf("unexpected first item %+v", items[0])
+	}
+	if want := time.Date(2025, 3, 14, 9, 12, 0, 0, time.UTC); !items[0].Published.Equal(want) {
+		t.Errorf("expected published %v, got %v", want, items[0].Published)
+	}
+	if items[1].GUID != "https://example.com/2" {
+		t.Errorf("expected link as fallback guid, got %q", items[1].GUID)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	title, items, err := Parse([]byte(sampleAtom))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if title != "Example Blog" || len(items) != 1 {
+		t.Fatalf("unexpected feed %q with %d items", title, len(items))
+	}
+	if items[0].Link != "https://example.com/hello" || items[0].GUID != "tag:example.com,2025:1" {
+		t.Errorf("unexpected entry %+v", items[0])
+	}
+}
+
+func TestParseRejectsHTML(t *testing.T) {
+	if _, _, err := Parse([]byte("<html><body>nope</body></html>")); err == nil {
+		t.Error("expected error for non-feed document")
+	}
+}
@@ -0,0 +1,110 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const (
+	maxFeedSize = 5 << 20
+	// delivered items that drop out of their feed are forgotten after this long
+	itemRetention = 30 * 24 * time.Hour
+)
+
+// NewPoller creates a poller that checks every feed once per interval
+func NewPoller(store *Store, interval time.Duration) *Poller {
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+
+	return &Poller{
+		store:    store,
+		interval: interval,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Fetch downloads and parses a feed
+func (p *Poller) Fetch(ctx context.Context, url string) (string, []Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("User-Agent", "Sheldon/1.0 (+feed reader)")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("fetch feed: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return "", nil, fmt.Errorf("read feed: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Run polls all feeds until the context is cancelled
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.pollAll(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debug("feed poller stopping")
+			return
+		case <-ticker.C:
+			p.pollAll(ctx)
+		}
+	}
+}
+
+func (p *Poller) pollAll(ctx context.Context) {
+	feeds, err := p.store.All()
+	if err != nil {
+		logger.Error("failed to list feeds", "error", err)
+		return
+	}
+
+	for _, f := range feeds {
+		if ctx.Err() != nil {
+			return
+		}
+
+		title, items, err := p.Fetch(ctx, f.URL)
+		if err != nil {
+			logger.Warn("feed poll failed", "feed", f.ID, "url", f.URL, "error", err)
+		} else if added, err := p.store.AddItems(f.ID, items, false); err != nil {
+			logger.Error("failed to store feed items", "feed", f.ID, "error", err)
+		} else if added > 0 {
+			logger.Debug("new feed items", "feed", f.ID, "count", added)
+		}
+
+		if err := p.store.RecordPoll(f.ID, title, err); err != nil {
+			logger.Warn("failed to record feed poll", "feed", f.ID, "error", err)
+		}
+	}
+
+	if pruned, err := p.store.PruneDelivered(time.Now().Add(-itemRetention)); err != nil {
+		logger.Error("failed to prune feed items", "error", err)
+	} else if pruned > 0 {
+		logger.Debug("feed items pruned", "count", pruned)
+	}
+}
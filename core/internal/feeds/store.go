@@ -0,0 +1,253 @@
+package feeds
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAlreadySubscribed = errors.New("already subscribed to this feed")
+	ErrFeedNotFound      = errors.New("feed not found")
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS feeds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    title TEXT,
+    last_polled DATETIME,
+    last_error TEXT,
+    created_at DATETIME DEFAULT (datetime('now')),
+    UNIQUE(chat_id, url)
+);
+
+CREATE TABLE IF NOT EXISTS feed_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    guid TEXT NOT NULL,
+    title TEXT,
+    link TEXT,
+    published DATETIME,
+    delivered_at DATETIME,
+    last_seen DATETIME DEFAULT (datetime('now')),
+    created_at DATETIME DEFAULT (datetime('now')),
+    UNIQUE(feed_id, guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_items_pending ON feed_items(feed_id, delivered_at);
+`
+
+const sqliteTime = "2006-01-02 15:04:05"
+
+// NewStore creates a feed store using the provided database connection
+func NewStore(db *sql.DB) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Subscribe adds a feed for a chat
+func (s *Store) Subscribe(chatID int64, url, title string) (*Feed, error) {
+	var exists int
+	s.db.QueryRow(`SELECT COUNT(*) FROM feeds WHERE chat_id = ? AND url = ?`, chatID, url).Scan(&exists)
+	if exists > 0 {
+		return nil, ErrAlreadySubscribed
+	}
+
+	result, err := s.db.Exec(`INSERT INTO feeds (chat_id, url, title) VALUES (?, ?, ?)`, chatID, url, title)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	return &Feed{ID: id, ChatID: chatID, URL: url, Title: title, CreatedAt: time.Now()}, nil
+}
+
+// Unsubscribe removes a chat's feed and its items
+func (s *Store) Unsubscribe(chatID, feedID int64) (*Feed, error) {
+	feeds, err := s.List(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range feeds {
+		if f.ID != feedID {
+			continue
+		}
+		if _, err := s.db.Exec(`DELETE FROM feed_items WHERE feed_id = ?`, feedID); err != nil {
+			return nil, err
+		}
+		if _, err := s.db.Exec(`DELETE FROM feeds WHERE id = ?`, feedID); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	return nil, ErrFeedNotFound
+}
+
+// List returns a chat's feeds
+func (s *Store) List(chatID int64) ([]*Feed, error) {
+	return s.query(`WHERE chat_id = ? ORDER BY id`, chatID)
+}
+
+// All returns every feed across chats, for polling
+func (s *Store) All() ([]*Feed, error) {
+	return s.query(`ORDER BY id`)
+}
+
+func (s *Store) query(where string, args ...any) ([]*Feed, error) {
+	rows, err := s.db.Query(`SELECT id, chat_id, url, title, last_polled, last_error, created_at FROM feeds `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feeds []*Feed
+	for rows.Next() {
+		var f Feed
+		var title, lastPolled, lastError, createdAt *string
+		if err := rows.Scan(&f.ID, &f.ChatID, &f.URL, &title, &lastPolled, &lastError, &createdAt); err != nil {
+			return nil, err
+		}
+		if title != nil {
+			f.Title = *title
+		}
+		if lastPolled != nil {
+			t := parseTime(*lastPolled)
+			f.LastPolled = &t
+		}
+		if lastError != nil {
+			f.LastError = *lastError
+		}
+		if createdAt != nil {
+			f.CreatedAt = parseTime(*createdAt)
+		}
+		feeds = append(feeds, &f)
+	}
+
+	return feeds, rows.Err()
+}
+
+// AddItems stores items not seen before and returns how many were new.
+// Items added with delivered set are never sent in a digest, which is used to
+// skip a feed's backlog when subscribing.
+func (s *Store) AddItems(feedID int64, items []Item, delivered bool) (int, error) {
+	now := time.Now().UTC().Format(sqliteTime)
+	var deliveredAt *string
+	if delivered {
+		deliveredAt = &now
+	}
+
+	added := 0
+	for _, item := range items {
+		result, err := s.db.Exec(`UPDATE feed_items SET last_seen = ? WHERE feed_id = ? AND guid = ?`, now, feedID, item.GUID)
+		if err != nil {
+			return added, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			continue
+		}
+
+		var published *string
+		if !item.Published.IsZero() {
+			p := item.Published.UTC().Format(sqliteTime)
+			published = &p
+		}
+
+		if _, err := s.db.Exec(`
+			INSERT INTO feed_items (feed_id, guid, title, link, published, delivered_at, last_seen)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			feedID, item.GUID, item.Title, item.Link, published, deliveredAt, now); err != nil {
+			return added, err
+		}
+		added++
+	}
+
+	return added, nil
+}
+
+// RecordPoll stores the outcome of the latest fetch
+func (s *Store) RecordPoll(feedID int64, title string, pollErr error) error {
+	var lastError *string
+	if pollErr != nil {
+		msg := pollErr.Error()
+		lastError = &msg
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE feeds SET last_polled = ?, last_error = ?, title = COALESCE(NULLIF(?, ''), title)
+		WHERE id = ?`,
+		time.Now().UTC().Format(sqliteTime), lastError, title, feedID)
+	return err
+}
+
+// Pending returns undelivered items for a chat, oldest first
+func (s *Store) Pending(chatID int64) ([]Item, error) {
+	rows, err := s.db.Query(`
+		SELECT i.id, i.feed_id, COALESCE(f.title, f.url), i.guid, COALESCE(i.title, ''), COALESCE(i.link, ''), i.published
+		FROM feed_items i
+		JOIN feeds f ON f.id = i.feed_id
+		WHERE f.chat_id = ? AND i.delivered_at IS NULL
+		ORDER BY f.id, i.published, i.id`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		var item Item
+		var published *string
+		if err := rows.Scan(&item.ID, &item.FeedID, &item.FeedTitle, &item.GUID, &item.Title, &item.Link, &published); err != nil {
+			return nil, err
+		}
+		if published != nil {
+			item.Published = parseTime(*published)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// MarkDelivered records that items were sent in a digest
+func (s *Store) MarkDelivered(items []Item) error {
+	now := time.Now().UTC().Format(sqliteTime)
+	for _, item := range items {
+		if _, err := s.db.Exec(`UPDATE feed_items SET delivered_at = ? WHERE id = ?`, now, item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneDelivered deletes delivered items that haven't appeared in their feed
+// since the cutoff. Items still in the feed are kept so they aren't sent again.
+func (s *Store) PruneDelivered(before time.Time) (int, error) {
+	result, err := s.db.Exec(`DELETE FROM feed_items WHERE delivered_at IS NOT NULL AND last_seen < ?`, before.UTC().Format(sqliteTime))
+	if err != nil {
+		return 0, fmt.Errorf("prune feed items: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// parseTime tries multiple formats to parse SQLite datetime strings
+func parseTime(s string) time.Time {
+	formats := []string{
+		time.RFC3339,
+		sqliteTime,
+		"2006-01-02T15:04:05",
+	}
+	for _, f := range formats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,44 @@
+package feeds
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+// DigestKeyword is the reserved cron keyword that delivers new feed items
+const DigestKeyword = "feed-digest"
+
+// Feed is an RSS or Atom subscription belonging to one chat
+type Feed struct {
+	ID         int64
+	ChatID     int64
+	URL        string
+	Title      string
+	LastPolled *time.Time
+	LastError  string
+	CreatedAt  time.Time
+}
+
+// Item is a single entry from a feed
+type Item struct {
+	ID        int64
+	FeedID    int64
+	FeedTitle string
+	GUID      string
+	Title     string
+	Link      string
+	Published time.Time
+}
+
+// Store persists subscriptions and the items seen on them
+type Store struct {
+	db *sql.DB
+}
+
+// Poller fetches every subscribed feed on an interval and stores new items
+type Poller struct {
+	store    *Store
+	client   *http.Client
+	interval time.Duration
+}
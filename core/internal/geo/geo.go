@@ -0,0 +1,210 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+	DefaultOSRMURL      = "https://router.project-osrm.org"
+)
+
+var ErrNotFound = errors.New("location not found")
+
+// Profiles are the OSRM routing profiles. The public demo server only routes by car.
+var Profiles = []string{"driving", "walking", "cycling"}
+
+func New(nominatimURL, osrmURL, userAgent string) *Client {
+	if nominatimURL == "" {
+		nominatimURL = DefaultNominatimURL
+	}
+	if osrmURL == "" {
+		osrmURL = DefaultOSRMURL
+	}
+	if userAgent == "" {
+		userAgent = "Sheldon/1.0 (+https://github.com/bowerhall/sheldon)"
+	}
+
+	return &Client{
+		nominatimURL: strings.TrimSuffix(nominatimURL, "/"),
+		osrmURL:      strings.TrimSuffix(osrmURL, "/"),
+		userAgent:    userAgent,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// Search geocodes a free-form address or place name, best match first
+func (c *Client) Search(ctx context.Context, query string, limit int) ([]Place, error) {
+	params := url.Values{
+		"q":      {query},
+		"format": {"jsonv2"},
+		"limit":  {strconv.Itoa(limit)},
+	}
+
+	var results []struct {
+		DisplayName string `json:"display_name"`
+		Lat         string `json:"lat"`
+		Lon         string `json:"lon"`
+	}
+	if err := c.nominatim(ctx, "/search?"+params.Encode(), &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrNotFound
+	}
+
+	places := make([]Place, 0, len(results))
+	for _, r := range results {
+		lat, errLat := strconv.ParseFloat(r.Lat, 64)
+		lon, errLon := strconv.ParseFloat(r.Lon, 64)
+		if errLat != nil || errLon != nil {
+			continue
+		}
+		places = append(places, Place{Name: r.DisplayName, Lat: lat, Lon: lon})
+	}
+	return places, nil
+}
+
+// Reverse finds the address at a coordinate
+func (c *Client) Reverse(ctx context.Context, lat, lon float64) (*Place, error) {
+	params := url.Values{
+		"lat":    {formatCoord(lat)},
+		"lon":    {formatCoord(lon)},
+		"format": {"jsonv2"},
+	}
+
+	var result struct {
+		DisplayName string `json:"display_name"`
+		Error       string `json:"error"`
+	}
+	if err := c.nominatim(ctx, "/reverse?"+params.Encode(), &result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" || result.DisplayName == "" {
+		return nil, ErrNotFound
+	}
+
+	return &Place{Name: result.DisplayName, Lat: lat, Lon: lon}, nil
+}
+
+// Resolve accepts either "lat,lon" or an address and returns a single place
+func (c *Client) Resolve(ctx context.Context, location string) (*Place, error) {
+	if lat, lon, ok := ParseCoords(location); ok {
+		return &Place{Name: location, Lat: lat, Lon: lon}, nil
+	}
+
+	places, err := c.Search(ctx, location, 1)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", location, err)
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("%q: %w", location, ErrNotFound)
+	}
+	return &places[0], nil
+}
+
+// Route estimates travel time between two places with the given OSRM profile
+func (c *Client) Route(ctx context.Context, from, to Place, profile string) (*Route, error) {
+	coords := fmt.Sprintf("%s,%s;%s,%s", formatCoord(from.Lon), formatCoord(from.Lat), formatCoord(to.Lon), formatCoord(to.Lat))
+	target := fmt.Sprintf("%s/route/v1/%s/%s?overview=false", c.osrmURL, profile, coords)
+
+	var result struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Routes  []struct {
+			Duration float64 `json:"duration"`
+			Distance float64 `json:"distance"`
+		} `json:"routes"`
+	}
+	if err := c.get(ctx, target, &result); err != nil {
+		return nil, fmt.Errorf("route: %w", err)
+	}
+	if result.Code != "Ok" || len(result.Routes) == 0 {
+		if result.Code == "NoRoute" {
+			return nil, fmt.Errorf("no %s route between these places", profile)
+		}
+		return nil, fmt.Errorf("route: %s %s", result.Code, result.Message)
+	}
+
+	return &Route{
+		Duration: time.Duration(result.Routes[0].Duration * float64(time.Second)),
+		Distance: result.Routes[0].Distance,
+	}, nil
+}
+
+// nominatim waits out the one-request-per-second limit before calling the geocoder
+func (c *Client) nominatim(ctx context.Context, path string, out any) error {
+	c.mu.Lock()
+	wait := time.Until(c.lastRequest.Add(time.Second))
+	if wait > 0 {
+		select {
+		case <-ctx.Done():
+			c.mu.Unlock()
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	c.lastRequest = time.Now()
+	c.mu.Unlock()
+
+	if err := c.get(ctx, c.nominatimURL+path, out); err != nil {
+		return fmt.Errorf("geocode: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, target string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// OSRM reports NoRoute and similar as 400 with a JSON body
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ParseCoords reads "lat,lon" as decimal degrees
+func ParseCoords(s string) (float64, float64, bool) {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+func formatCoord(f float64) string {
+	return strconv.FormatFloat(f, 'f', 6, 64)
+}
@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCoords(t *testing.T) {
+	lat, lon, ok := ParseCoords("51.5072, -0.1276")
+	if !ok || lat != 51.5072 || lon != -0.1276 {
+		t.Errorf("unexpected coords %v %v %v", lat, lon, ok)
+	}
+
+	for _, s := range []string{"10 Downing Street, London", "91,0", "0,181", "51.5"} {
+		if _, _, ok := ParseCoords(s); ok {
+			t.Errorf("expected %q not to parse as coordinates", s)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/route/v1/walking/-0.127600,51.507200;") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"code":"Ok","routes":[{"duration":1800.5,"distance":2400}]}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, server.URL, "")
+	route, err := c.Route(context.Background(), Place{Lat: 51.5072, Lon: -0.1276}, Place{Lat: 51.52, Lon: -0.1}, "walking")
+	if err != nil {
+		t.Fatalf("route: %v", err)
+	}
+	if route.Duration.Round(time.Second) != 30*time.Minute+time.Second || route.Distance != 2400 {
+		t.Errorf("unexpected route %+v", route)
+	}
+}
+
+func TestRouteNoRoute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"NoRoute","message":"Impossible route between points"}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, server.URL, "")
+	if _, err := c.Route(context.Background(), Place{}, Place{Lat: 1}, "driving"); err == nil || !strings.Contains(err.Error(), "no driving route") {
+		t.Errorf("expected no route error, got %v", err)
+	}
+}
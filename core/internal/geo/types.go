@@ -0,0 +1,32 @@
+package geo
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Place is a geocoded location
+type Place struct {
+	Name string // display name from the geocoder
+	Lat  float64
+	Lon  float64
+}
+
+// Route is the result of a travel time estimate
+type Route struct {
+	Duration time.Duration
+	Distance float64 // metres
+}
+
+// Client talks to a Nominatim geocoder and an OSRM router
+type Client struct {
+	nominatimURL string
+	osrmURL      string
+	userAgent    string
+	client       *http.Client
+
+	// Nominatim's usage policy allows at most one request per second
+	mu          sync.Mutex
+	lastRequest time.Time
+}
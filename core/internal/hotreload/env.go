@@ -0,0 +1,62 @@
+package hotreload
+
+import (
+	"os"
+	"sort"
+
+	"github.com/joho/godotenv"
+)
+
+// NewEnvFile remembers what path set when it was loaded at startup. A missing
+// file counts as empty so creating it later works.
+func NewEnvFile(path string) *EnvFile {
+	loaded, err := godotenv.Read(path)
+	if err != nil {
+		loaded = map[string]string{}
+	}
+	return &EnvFile{path: path, loaded: loaded}
+}
+
+func (e *EnvFile) Path() string {
+	return e.path
+}
+
+// Reload applies the file's current contents and returns the names of the
+// variables that changed. Variables removed from the file are unset.
+func (e *EnvFile) Reload() ([]string, error) {
+	current, err := godotenv.Read(e.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		current = map[string]string{}
+	}
+
+	var changed []string
+	for key, value := range current {
+		old, fromFile := e.loaded[key]
+		actual, set := os.LookupEnv(key)
+		// set by the real environment, not by us
+		if set && (!fromFile || actual != old) {
+			continue
+		}
+		if set && actual == value {
+			continue
+		}
+		os.Setenv(key, value)
+		changed = append(changed, key)
+	}
+	for key, old := range e.loaded {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		if actual, set := os.LookupEnv(key); set && actual == old {
+			os.Unsetenv(key)
+			changed = append(changed, key)
+		}
+	}
+
+	e.loaded = current
+	sort.Strings(changed)
+	return changed, nil
+}
@@ -0,0 +1,102 @@
+package hotreload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("HOTRELOAD_A=1\nHOTRELOAD_B=keep\nHOTRELOAD_REAL=file\n"), 0600)
+
+	// startup: the real environment wins over the file, like godotenv.Load
+	t.Setenv("HOTRELOAD_REAL", "env")
+	t.Setenv("HOTRELOAD_A", "1")
+	t.Setenv("HOTRELOAD_B", "keep")
+	env := NewEnvFile(path)
+
+	os.WriteFile(path, []byte("HOTRELOAD_A=2\nHOTRELOAD_REAL=file2\nHOTRELOAD_NEW=x\n"), 0600)
+	t.Cleanup(func() { os.Unsetenv("HOTRELOAD_NEW") })
+
+	changed, err := env.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := strings.Join(changed, ","); got != "HOTRELOAD_A,HOTRELOAD_B,HOTRELOAD_NEW" {
+		t.Errorf("changed = %s", got)
+	}
+	if os.Getenv("HOTRELOAD_A") != "2" || os.Getenv("HOTRELOAD_NEW") != "x" {
+		t.Errorf("file values not applied: A=%s NEW=%s", os.Getenv("HOTRELOAD_A"), os.Getenv("HOTRELOAD_NEW"))
+	}
+	if _, set := os.LookupEnv("HOTRELOAD_B"); set {
+		t.Error("variable removed from the file should be unset")
+	}
+	if os.Getenv("HOTRELOAD_REAL") != "env" {
+		t.Errorf("real environment overridden: %s", os.Getenv("HOTRELOAD_REAL"))
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	essence := filepath.Join(dir, "essence")
+	os.MkdirAll(essence, 0755)
+	config := filepath.Join(dir, "runtime_config.json")
+
+	w, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	w.debounce = 20 * time.Millisecond
+
+	essenceReloads := make(chan struct{}, 10)
+	configReloads := make(chan struct{}, 10)
+	if err := w.WatchDir(essence, func() { essenceReloads <- struct{}{} }); err != nil {
+		t.Fatalf("WatchDir: %v", err)
+	}
+	if err := w.WatchFile(config, func() { configReloads <- struct{}{} }); err != nil {
+		t.Fatalf("WatchFile: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	wait := func(ch chan struct{}, what string) {
+		t.Helper()
+		select {
+		case <-ch:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no reload after %s", what)
+		}
+	}
+
+	// several writes in a burst reload once
+	for i := range 3 {
+		os.WriteFile(filepath.Join(essence, "SOUL.md"), []byte(strings.Repeat("x", i+1)), 0644)
+	}
+	wait(essenceReloads, "writing SOUL.md")
+
+	// files in subdirectories created after Run started count too
+	os.MkdirAll(filepath.Join(essence, "personas"), 0755)
+	wait(essenceReloads, "creating personas/")
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(filepath.Join(essence, "personas", "work.md"), []byte("work"), 0644)
+	wait(essenceReloads, "writing personas/work.md")
+
+	// unrelated files next to a watched file are ignored
+	os.WriteFile(filepath.Join(dir, "memory.db"), []byte("x"), 0644)
+	os.WriteFile(config, []byte("{}"), 0644)
+	wait(configReloads, "writing runtime_config.json")
+
+	select {
+	case <-essenceReloads:
+		t.Error("essence reloaded for an unrelated change")
+	case <-configReloads:
+		t.Error("config reloaded twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,33 @@
+package hotreload
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher calls a reload function when a watched file or directory changes.
+// Editors save in bursts (temp file, rename, chmod), so calls are debounced.
+type Watcher struct {
+	fs       *fsnotify.Watcher
+	debounce time.Duration
+
+	mu      sync.Mutex
+	targets []*target
+}
+
+// target is a file or a directory tree with its reload function
+type target struct {
+	path    string
+	dir     bool
+	reload  func()
+	pending bool
+}
+
+// EnvFile re-applies a .env file. Only variables that came from the file are
+// changed, so values set by the real environment keep priority like at startup.
+type EnvFile struct {
+	path   string
+	loaded map[string]string
+}
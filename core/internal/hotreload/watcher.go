@@ -0,0 +1,136 @@
+package hotreload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const defaultDebounce = 500 * time.Millisecond
+
+func New() (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{fs: fs, debounce: defaultDebounce}, nil
+}
+
+// WatchFile reloads when path is written, replaced or removed. The parent
+// directory is watched since editors often replace the file by renaming.
+func (w *Watcher) WatchFile(path string, reload func()) error {
+	path = filepath.Clean(path)
+	if err := w.fs.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.targets = append(w.targets, &target{path: path, reload: reload})
+	return nil
+}
+
+// WatchDir reloads when anything in dir or its subdirectories changes,
+// including subdirectories created later
+func (w *Watcher) WatchDir(dir string, reload func()) error {
+	dir = filepath.Clean(dir)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return w.fs.Add(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.targets = append(w.targets, &target{path: dir, dir: true, reload: reload})
+	return nil
+}
+
+// Run handles events until ctx is done, then closes the watcher
+func (w *Watcher) Run(ctx context.Context) {
+	defer w.fs.Close()
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if w.handle(event) {
+				timer.Reset(w.debounce)
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("file watcher error", "error", err)
+		case <-timer.C:
+			w.fire()
+		}
+	}
+}
+
+// handle marks the targets an event belongs to and reports whether any matched
+func (w *Watcher) handle(event fsnotify.Event) bool {
+	path := filepath.Clean(event.Name)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	matched := false
+	for _, t := range w.targets {
+		if t.dir {
+			if path != t.path && !strings.HasPrefix(path, t.path+string(filepath.Separator)) {
+				continue
+			}
+			// new subdirectories need their own watch
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(path); err == nil && info.IsDir() {
+					if err := w.fs.Add(path); err != nil {
+						logger.Warn("failed to watch new directory", "path", path, "error", err)
+					}
+				}
+			}
+		} else if path != t.path || event.Op == fsnotify.Chmod {
+			continue
+		}
+		t.pending = true
+		matched = true
+	}
+	return matched
+}
+
+func (w *Watcher) fire() {
+	w.mu.Lock()
+	var due []*target
+	for _, t := range w.targets {
+		if t.pending {
+			t.pending = false
+			due = append(due, t)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, t := range due {
+		logger.Info("reloading after file change", "path", t.path)
+		t.reload()
+	}
+}
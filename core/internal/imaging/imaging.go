@@ -0,0 +1,168 @@
+// Package imaging shrinks photos to fit chat providers' upload limits,
+// downscaling them and re-encoding as WebP (with cwebp) or JPEG
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrCannotFit is returned when an image is still over the limit at the
+// smallest size and lowest quality tried
+var ErrCannotFit = errors.New("image can't be compressed to fit")
+
+// qualities are tried in order at each size before scaling down further
+var qualities = []int{85, 75, 60}
+
+const (
+	// scaleStep shrinks both edges each round that no quality fits
+	scaleStep = 0.75
+	// minEdge is as small as the longest edge gets before giving up
+	minEdge = 320
+)
+
+// Limits is what Fit compresses an image to
+type Limits struct {
+	MaxBytes     int64 // 0 = any size
+	MaxDimension int   // longest edge in pixels, 0 = any
+	WebP         bool  // encode as WebP when cwebp is installed, otherwise JPEG
+}
+
+// Result is a compressed image
+type Result struct {
+	Data     []byte
+	MimeType string
+	Width    int
+	Height   int
+}
+
+// NeedsFit reports whether an image is over its limits. Animated GIFs and
+// anything that isn't a still image are left alone.
+func NeedsFit(data []byte, l Limits) bool {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format == "gif" {
+		return false
+	}
+	if l.MaxBytes > 0 && int64(len(data)) > l.MaxBytes {
+		return true
+	}
+	return l.MaxDimension > 0 && max(cfg.Width, cfg.Height) > l.MaxDimension
+}
+
+// Fit downscales an image to MaxDimension and re-encodes it, lowering the
+// quality and then the size until it's under MaxBytes
+func Fit(ctx context.Context, data []byte, l Limits) (Result, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("decode image: %w", err)
+	}
+
+	encode := encodeJPEG
+	mimeType := "image/jpeg"
+	if l.WebP && HasWebP() {
+		encode = encodeWebP
+		mimeType = "image/webp"
+	}
+
+	b := src.Bounds()
+	scale := 1.0
+	if edge := max(b.Dx(), b.Dy()); l.MaxDimension > 0 && edge > l.MaxDimension {
+		scale = float64(l.MaxDimension) / float64(edge)
+	}
+
+	for {
+		w, h := max(int(float64(b.Dx())*scale), 1), max(int(float64(b.Dy())*scale), 1)
+		img := resize(src, w, h)
+
+		for _, q := range qualities {
+			out, err := encode(ctx, img, q)
+			if err != nil {
+				return Result{}, err
+			}
+			if l.MaxBytes <= 0 || int64(len(out)) <= l.MaxBytes {
+				return Result{Data: out, MimeType: mimeType, Width: w, Height: h}, nil
+			}
+		}
+
+		if max(w, h) <= minEdge {
+			return Result{}, fmt.Errorf("%w under %d bytes", ErrCannotFit, l.MaxBytes)
+		}
+		scale *= scaleStep
+	}
+}
+
+// resize scales src to w x h, or returns it as is when that's its size
+func resize(src image.Image, w, h int) image.Image {
+	if b := src.Bounds(); b.Dx() == w && b.Dy() == h {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// encodeJPEG flattens transparency onto white, since JPEG has no alpha and
+// would otherwise turn it black
+func encodeJPEG(_ context.Context, img image.Image, quality int) ([]byte, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// HasWebP reports whether cwebp (libwebp-tools) is installed
+func HasWebP() bool {
+	_, err := exec.LookPath("cwebp")
+	return err == nil
+}
+
+// encodeWebP hands a lossless PNG of the image to cwebp, which keeps
+// transparency
+func encodeWebP(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "webp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+	f, err := os.Create(in)
+	if err != nil {
+		return nil, err
+	}
+	if err := (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(f, img); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "cwebp", "-quiet", "-q", strconv.Itoa(quality), in, "-o", out)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("cwebp: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return os.ReadFile(out)
+}
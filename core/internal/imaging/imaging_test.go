@@ -0,0 +1,70 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// gradientPNG is a smooth, photo-like image that JPEG stores compactly
+func gradientPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / w), uint8(y * 255 / h), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFit(t *testing.T) {
+	data := gradientPNG(t, 1200, 800)
+	limits := Limits{MaxBytes: 40 << 10, MaxDimension: 600}
+
+	if !NeedsFit(data, limits) {
+		t.Fatal("NeedsFit = false for an image over both limits")
+	}
+
+	result, err := Fit(context.Background(), data, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(result.Data)) > limits.MaxBytes {
+		t.Errorf("fitted size %d, want at most %d", len(result.Data), limits.MaxBytes)
+	}
+	if result.Width != 600 || result.Height != 400 {
+		t.Errorf("fitted to %dx%d, want 600x400", result.Width, result.Height)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(result.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || cfg.Width != result.Width {
+		t.Errorf("decoded %s %dx%d, want the jpeg Fit reported", format, cfg.Width, cfg.Height)
+	}
+	if NeedsFit(result.Data, limits) {
+		t.Error("fitted image still needs fitting")
+	}
+}
+
+func TestFitCannotFit(t *testing.T) {
+	_, err := Fit(context.Background(), gradientPNG(t, 400, 400), Limits{MaxBytes: 10})
+	if err == nil {
+		t.Error("fit a 400x400 image into 10 bytes")
+	}
+}
+
+func TestNeedsFitSkipsNonImages(t *testing.T) {
+	if NeedsFit([]byte("%PDF-1.7"), Limits{MaxBytes: 1}) {
+		t.Error("NeedsFit = true for a PDF")
+	}
+}
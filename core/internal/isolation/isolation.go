@@ -0,0 +1,43 @@
+// Package isolation builds the hardening flags for sandbox containers that run untrusted code
+package isolation
+
+import "strings"
+
+// Options describe extra isolation applied to sandbox containers
+type Options struct {
+	Runtime         string // OCI runtime: runsc (gVisor), kata-runtime, or empty for the engine default
+	SeccompProfile  string // path to a seccomp profile; empty keeps the engine's default profile
+	NoNewPrivileges bool   // block setuid/setcap privilege escalation inside the container
+}
+
+// runtimeAliases lets config use the project names instead of the binaries
+var runtimeAliases = map[string]string{
+	"gvisor": "runsc",
+	"kata":   "kata-runtime",
+}
+
+// RuntimeName resolves aliases like "gvisor" to the runtime the engine knows
+func (o Options) RuntimeName() string {
+	name := strings.TrimSpace(o.Runtime)
+	if alias, ok := runtimeAliases[strings.ToLower(name)]; ok {
+		return alias
+	}
+	return name
+}
+
+// Args returns the `run` flags for these options
+func (o Options) Args() []string {
+	var args []string
+
+	if runtime := o.RuntimeName(); runtime != "" {
+		args = append(args, "--runtime", runtime)
+	}
+	if o.NoNewPrivileges {
+		args = append(args, "--security-opt", "no-new-privileges")
+	}
+	if o.SeccompProfile != "" {
+		args = append(args, "--security-opt", "seccomp="+o.SeccompProfile)
+	}
+
+	return args
+}
@@ -0,0 +1,22 @@
+package isolation
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	args := Options{Runtime: "gVisor", SeccompProfile: "/etc/sheldon/seccomp.json", NoNewPrivileges: true}.Args()
+	want := []string{"--runtime", "runsc", "--security-opt", "no-new-privileges", "--security-opt", "seccomp=/etc/sheldon/seccomp.json"}
+	if !slices.Equal(args, want) {
+		t.Errorf("expected %v, got %v", want, args)
+	}
+
+	if args := (Options{Runtime: "kata"}).Args(); !slices.Equal(args, []string{"--runtime", "kata-runtime"}) {
+		t.Errorf("expected kata alias resolved, got %v", args)
+	}
+
+	if args := (Options{}).Args(); len(args) != 0 {
+		t.Errorf("expected no flags by default, got %v", args)
+	}
+}
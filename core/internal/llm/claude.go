@@ -10,14 +10,13 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
-const maxRetries = 3
-const baseDelay = 2 * time.Second
+// ephemeralCache marks a prompt caching breakpoint (5 minute TTL)
+var ephemeralCache = anthropic.CacheControlEphemeralParam{Type: "ephemeral"}
 
 // validToolIDPattern matches Claude's required pattern for tool IDs
 var validToolIDPattern = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
@@ -35,14 +34,19 @@ type rawMessage struct {
 }
 
 type rawContentBlock struct {
-	Type      string          `json:"type"`
-	Text      string          `json:"text,omitempty"`
-	ID        string          `json:"id,omitempty"`
-	Name      string          `json:"name,omitempty"`
-	Input     map[string]any  `json:"input,omitempty"`
-	ToolUseID string          `json:"tool_use_id,omitempty"`
-	Content   string          `json:"content,omitempty"`
-	Source    *rawMediaSource `json:"source,omitempty"`
+	Type         string           `json:"type"`
+	Text         string           `json:"text,omitempty"`
+	ID           string           `json:"id,omitempty"`
+	Name         string           `json:"name,omitempty"`
+	Input        map[string]any   `json:"input,omitempty"`
+	ToolUseID    string           `json:"tool_use_id,omitempty"`
+	Content      string           `json:"content,omitempty"`
+	Source       *rawMediaSource  `json:"source,omitempty"`
+	CacheControl *rawCacheControl `json:"cache_control,omitempty"`
+}
+
+type rawCacheControl struct {
+	Type string `json:"type"`
 }
 
 type rawMediaSource struct {
@@ -54,15 +58,16 @@ type rawMediaSource struct {
 type rawRequest struct {
 	Model     string            `json:"model"`
 	MaxTokens int               `json:"max_tokens"`
-	System    string            `json:"system,omitempty"`
+	System    []rawContentBlock `json:"system,omitempty"`
 	Messages  []rawMessage      `json:"messages"`
 	Tools     []rawTool         `json:"tools,omitempty"`
 }
 
 type rawTool struct {
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	InputSchema map[string]any `json:"input_schema"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	InputSchema  map[string]any   `json:"input_schema"`
+	CacheControl *rawCacheControl `json:"cache_control,omitempty"`
 }
 
 type rawResponse struct {
@@ -81,8 +86,10 @@ type rawResponseBlock struct {
 }
 
 type rawUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 }
 
 type rawError struct {
@@ -94,7 +101,8 @@ func newClaude(apiKey, model string) LLM {
 	if model == "" {
 		model = "claude-sonnet-4-20250514"
 	}
-	client := anthropic.NewClient(option.WithAPIKey(apiKey))
+	// retries are handled by the shared wrapper in retry.go
+	client := anthropic.NewClient(option.WithAPIKey(apiKey), option.WithMaxRetries(0))
 	return &claude{client: client, apiKey: apiKey, model: model}
 }
 
@@ -108,79 +116,93 @@ func (c *claude) Chat(ctx context.Context, systemPrompt string, messages []Messa
 
 func (c *claude) ChatWithTools(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*ChatResponse, error) {
 	// Check if any message contains video or PDF - use raw API if so
-	needsRawAPI := false
-	for _, msg := range messages {
-		for _, media := range msg.Media {
-			if media.Type == MediaTypeVideo || media.Type == MediaTypePDF {
-				needsRawAPI = true
-				break
-			}
-		}
-		if needsRawAPI {
-			break
+	if needsRawAPI(messages) {
+		return c.chatWithToolsRaw(ctx, systemPrompt, messages, tools)
+	}
+
+	// Use SDK for non-video messages
+	params := c.buildParams(systemPrompt, messages, tools)
+
+	resp, err := c.client.Messages.New(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseResponse(resp), nil
+}
+
+func (c *claude) ChatWithToolsStream(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, onText StreamFunc) (*ChatResponse, error) {
+	// the raw API path has no streaming support, deliver the full text at once
+	if needsRawAPI(messages) {
+		resp, err := c.chatWithToolsRaw(ctx, systemPrompt, messages, tools)
+		if err == nil && onText != nil && resp.Content != "" {
+			onText(resp.Content)
 		}
+		return resp, err
 	}
 
-	if needsRawAPI {
-		return c.chatWithToolsRaw(ctx, systemPrompt, messages, tools)
+	params := c.buildParams(systemPrompt, messages, tools)
+
+	var text strings.Builder
+	message := anthropic.Message{}
+
+	stream := c.client.Messages.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	for stream.Next() {
+		event := stream.Current()
+		if err := message.Accumulate(event); err != nil {
+			return nil, fmt.Errorf("accumulate stream: %w", err)
+		}
+
+		if event.Type == "content_block_delta" && event.Delta.Type == "text_delta" {
+			text.WriteString(event.Delta.Text)
+			if onText != nil {
+				onText(text.String())
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
 	}
 
-	// Use SDK for non-video messages
-	anthropicMessages := c.convertMessages(messages)
+	return c.parseResponse(&message), nil
+}
 
+func (c *claude) buildParams(systemPrompt string, messages []Message, tools []Tool) anthropic.MessageNewParams {
 	params := anthropic.MessageNewParams{
 		Model:     c.model,
 		MaxTokens: 4096,
-		Messages:  anthropicMessages,
+		Messages:  c.convertMessages(messages),
 	}
 
+	// prompt caching: tools and system prompt form a stable ~10k token prefix
+	// that is resent on every loop iteration. Breakpoints are placed on both so
+	// a change to the dynamic tail of the system prompt still reuses the tools.
 	if systemPrompt != "" {
 		params.System = []anthropic.TextBlockParam{
-			{Text: systemPrompt},
+			{Text: systemPrompt, CacheControl: ephemeralCache},
 		}
 	}
 
 	if len(tools) > 0 {
 		params.Tools = c.convertTools(tools)
+		params.Tools[len(params.Tools)-1].OfTool.CacheControl = ephemeralCache
 	}
 
-	var resp *anthropic.Message
-	var err error
-	for attempt := range maxRetries {
-		resp, err = c.client.Messages.New(ctx, params)
-		if err == nil {
-			break
-		}
-		if !isRetryableError(err) {
-			return nil, err
-		}
-		if attempt < maxRetries-1 {
-			delay := baseDelay * time.Duration(1<<attempt)
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
+	return params
+}
+
+// needsRawAPI reports whether any message carries video or PDF, which the SDK can't send
+func needsRawAPI(messages []Message) bool {
+	for _, msg := range messages {
+		for _, media := range msg.Media {
+			if media.Type == MediaTypeVideo || media.Type == MediaTypePDF {
+				return true
 			}
 		}
 	}
-	if err != nil {
-		return nil, err
-	}
-
-	return c.parseResponse(resp), nil
-}
-
-func isRetryableError(err error) bool {
-	errStr := err.Error()
-	return strings.Contains(errStr, "529") ||
-		strings.Contains(errStr, "overloaded") ||
-		strings.Contains(errStr, "Overloaded") ||
-		strings.Contains(errStr, "503") ||
-		strings.Contains(errStr, "502")
-}
-
-func isRetryableStatus(code int) bool {
-	return code == 529 || code == 503 || code == 502 || code == 500
+	return false
 }
 
 // chatWithToolsRaw uses raw HTTP API for video support
@@ -193,12 +215,15 @@ func (c *claude) chatWithToolsRaw(ctx context.Context, systemPrompt string, mess
 		Messages:  rawMessages,
 	}
 
+	// cache breakpoints on the last tool and the system prompt, which are
+	// resent unchanged on every agent loop iteration
 	if systemPrompt != "" {
-		req.System = systemPrompt
+		req.System = []rawContentBlock{{Type: "text", Text: systemPrompt, CacheControl: &rawCacheControl{Type: "ephemeral"}}}
 	}
 
 	if len(tools) > 0 {
 		req.Tools = c.convertToolsRaw(tools)
+		req.Tools[len(req.Tools)-1].CacheControl = &rawCacheControl{Type: "ephemeral"}
 	}
 
 	jsonBody, err := json.Marshal(req)
@@ -206,47 +231,27 @@ func (c *claude) chatWithToolsRaw(ctx context.Context, systemPrompt string, mess
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	var body []byte
-	var statusCode int
-	for attempt := range maxRetries {
-		httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(jsonBody))
-		if err != nil {
-			return nil, fmt.Errorf("create request: %w", err)
-		}
-		httpReq.Header.Set("Content-Type", "application/json")
-		httpReq.Header.Set("x-api-key", c.apiKey)
-		httpReq.Header.Set("anthropic-version", "2023-06-01")
-
-		resp, err := http.DefaultClient.Do(httpReq)
-		if err != nil {
-			return nil, fmt.Errorf("http request: %w", err)
-		}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
 
-		body, err = io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			return nil, fmt.Errorf("read response: %w", err)
-		}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
 
-		statusCode = resp.StatusCode
-		if statusCode == 200 {
-			break
-		}
-		if !isRetryableStatus(statusCode) {
-			return nil, fmt.Errorf("api error (status %d): %s", statusCode, string(body))
-		}
-		if attempt < maxRetries-1 {
-			delay := baseDelay * time.Duration(1<<attempt)
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
-		}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
 	}
 
-	if statusCode != 200 {
-		return nil, fmt.Errorf("api error (status %d): %s", statusCode, string(body))
+	if resp.StatusCode != 200 {
+		return nil, newAPIError(resp, body)
 	}
 
 	var rawResp rawResponse
@@ -382,6 +387,8 @@ func (c *claude) parseRawResponse(resp *rawResponse) *ChatResponse {
 			PromptTokens:     resp.Usage.InputTokens,
 			CompletionTokens: resp.Usage.OutputTokens,
 			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+			CacheWriteTokens: resp.Usage.CacheCreationInputTokens,
+			CacheReadTokens:  resp.Usage.CacheReadInputTokens,
 		}
 	}
 
@@ -520,6 +527,8 @@ func (c *claude) parseResponse(resp *anthropic.Message) *ChatResponse {
 		PromptTokens:     int(resp.Usage.InputTokens),
 		CompletionTokens: int(resp.Usage.OutputTokens),
 		TotalTokens:      int(resp.Usage.InputTokens + resp.Usage.OutputTokens),
+		CacheWriteTokens: int(resp.Usage.CacheCreationInputTokens),
+		CacheReadTokens:  int(resp.Usage.CacheReadInputTokens),
 	}
 
 	return result
@@ -527,10 +536,11 @@ func (c *claude) parseResponse(resp *anthropic.Message) *ChatResponse {
 
 func (c *claude) Capabilities() Capabilities {
 	return Capabilities{
-		Vision:     true,
-		VideoInput: true,
-		PDFInput:   true,
-		ToolUse:    true,
+		Vision:        true,
+		VideoInput:    true,
+		PDFInput:      true,
+		ToolUse:       true,
+		ContextWindow: 200000,
 	}
 }
 
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	healthBaseCooldown = time.Minute
+	healthMaxCooldown  = 30 * time.Minute
+)
+
+// HealthTracker remembers which providers failed recently. A failing provider
+// cools down for a minute, doubling with each consecutive failure up to 30
+// minutes; once the cooldown passes it gets tried again, and a success clears
+// its history.
+type HealthTracker struct {
+	mu        sync.Mutex
+	providers map[string]*ProviderHealth
+}
+
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{providers: make(map[string]*ProviderHealth)}
+}
+
+// RecordFailure starts or extends the provider's cooldown
+func (t *HealthTracker) RecordFailure(provider string, err error) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h := t.get(provider)
+	h.Failures++
+	h.TotalFailures++
+	h.LastFailure = time.Now()
+	if err != nil {
+		h.LastError = err.Error()
+	}
+
+	cooldown := healthBaseCooldown << min(h.Failures-1, 5)
+	cooldown = min(cooldown, healthMaxCooldown)
+	h.CooldownUntil = h.LastFailure.Add(cooldown)
+
+	return cooldown
+}
+
+// RecordSuccess clears the provider's cooldown. It reports whether the
+// provider had been failing, i.e. it just recovered.
+func (t *HealthTracker) RecordSuccess(provider string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h := t.get(provider)
+	recovered := h.Failures > 0
+	h.Failures = 0
+	h.CooldownUntil = time.Time{}
+	h.LastSuccess = time.Now()
+
+	return recovered
+}
+
+// Available reports whether the provider is worth trying, which includes a
+// provider whose cooldown just ran out
+func (t *HealthTracker) Available(provider string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.providers[provider]
+	return !ok || !h.CoolingDown(time.Now())
+}
+
+// Reset forgets a provider's failures so it is tried on the next request
+func (t *HealthTracker) Reset(provider string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.providers[provider]
+	if !ok {
+		return false
+	}
+	h.Failures = 0
+	h.CooldownUntil = time.Time{}
+	return true
+}
+
+// Snapshot returns every provider seen so far, sorted by name
+func (t *HealthTracker) Snapshot() []ProviderHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]ProviderHealth, 0, len(t.providers))
+	for _, h := range t.providers {
+		result = append(result, *h)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Provider < result[j].Provider })
+
+	return result
+}
+
+func (t *HealthTracker) get(provider string) *ProviderHealth {
+	h, ok := t.providers[provider]
+	if !ok {
+		h = &ProviderHealth{Provider: provider}
+		t.providers[provider] = h
+	}
+	return h
+}
@@ -11,9 +11,22 @@ var openAICompatibleProviders = map[string]string{
 	"deepseek":   "https://api.deepseek.com/v1",
 	"fireworks":  "https://api.fireworks.ai/inference/v1",
 	"perplexity": "https://api.perplexity.ai",
+	"openrouter": "https://openrouter.ai/api/v1",
 }
 
+const defaultAzureAPIVersion = "2024-10-21"
+
+// New creates a provider client wrapped with retry and backoff, and with the
+// recorder if one is set
 func New(cfg Config) (LLM, error) {
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return withRecorder(withRetry(provider, cfg.MaxAttempts)), nil
+}
+
+func newProvider(cfg Config) (LLM, error) {
 	switch cfg.Provider {
 	case "claude":
 		return newClaude(cfg.APIKey, cfg.Model), nil
@@ -51,6 +64,21 @@ func New(cfg Config) (LLM, error) {
 
 		// Ollama's OpenAI-compatible endpoint
 		return newOpenAICompatible("ollama", "ollama", baseURL+"/v1", model), nil
+	case "azure":
+		// Azure addresses models by deployment name, which goes in Model
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("azure requires an endpoint (AZURE_OPENAI_ENDPOINT)")
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("azure requires a deployment name (LLM_MODEL)")
+		}
+
+		apiVersion := cfg.APIVersion
+		if apiVersion == "" {
+			apiVersion = defaultAzureAPIVersion
+		}
+
+		return newAzureOpenAI(cfg.APIKey, cfg.BaseURL, cfg.Model, apiVersion), nil
 	default:
 		// check if it's an OpenAI-compatible provider
 		if baseURL, ok := openAICompatibleProviders[cfg.Provider]; ok {
@@ -65,7 +93,7 @@ func New(cfg Config) (LLM, error) {
 
 // KnownProviders returns all known provider IDs
 func KnownProviders() []string {
-	providers := []string{"claude", "openai", "kimi", "ollama"}
+	providers := []string{"claude", "openai", "kimi", "ollama", "azure"}
 	for p := range openAICompatibleProviders {
 		providers = append(providers, p)
 	}
@@ -75,7 +103,7 @@ func KnownProviders() []string {
 // IsKnownProvider checks if a provider is recognized
 func IsKnownProvider(provider string) bool {
 	switch provider {
-	case "claude", "openai", "kimi", "ollama":
+	case "claude", "openai", "kimi", "ollama", "azure":
 		return true
 	default:
 		_, ok := openAICompatibleProviders[provider]
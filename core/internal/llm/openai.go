@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/base64"
@@ -8,20 +9,29 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
 type openaiCompatible struct {
-	provider string
-	apiKey   string
-	baseURL  string
-	model    string
+	provider   string
+	apiKey     string
+	baseURL    string
+	model      string
+	apiVersion string // set for Azure, which also authenticates with an api-key header
+	probing    bool   // claims every capability so Probe can find out which ones are real
 }
 
 type openaiRequest struct {
-	Model    string          `json:"model"`
-	Messages []openaiMessage `json:"messages"`
-	Tools    []openaiTool    `json:"tools,omitempty"`
+	Model         string               `json:"model"`
+	Messages      []openaiMessage      `json:"messages"`
+	Tools         []openaiTool         `json:"tools,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openaiStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openaiStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openaiContentPart struct {
@@ -80,6 +90,32 @@ type openaiResponse struct {
 	} `json:"error,omitempty"`
 }
 
+// openaiStreamChunk is a single server-sent event from a streaming completion
+type openaiStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls,omitempty"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
 func newOpenAICompatible(provider, apiKey, baseURL, model string) LLM {
 	return &openaiCompatible{
 		provider: provider,
@@ -89,6 +125,18 @@ func newOpenAICompatible(provider, apiKey, baseURL, model string) LLM {
 	}
 }
 
+// newAzureOpenAI creates a client for an Azure OpenAI deployment.
+// The deployment name doubles as the model name for logging and budget tracking.
+func newAzureOpenAI(apiKey, endpoint, deployment, apiVersion string) LLM {
+	return &openaiCompatible{
+		provider:   "azure",
+		apiKey:     apiKey,
+		baseURL:    strings.TrimRight(endpoint, "/") + "/openai/deployments/" + url.PathEscape(deployment),
+		model:      deployment,
+		apiVersion: apiVersion,
+	}
+}
+
 func (o *openaiCompatible) Chat(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
 	resp, err := o.ChatWithTools(ctx, systemPrompt, messages, nil)
 	if err != nil {
@@ -98,6 +146,189 @@ func (o *openaiCompatible) Chat(ctx context.Context, systemPrompt string, messag
 }
 
 func (o *openaiCompatible) ChatWithTools(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*ChatResponse, error) {
+	resp, err := o.post(ctx, o.buildRequest(systemPrompt, messages, tools))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, newAPIError(resp, body)
+	}
+
+	var oaiResp openaiResponse
+
+	if err := json.Unmarshal(body, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, fmt.Errorf("api error: %s", oaiResp.Error.Message)
+	}
+
+	if len(oaiResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	choice := oaiResp.Choices[0]
+	result := &ChatResponse{
+		Content:    choice.Message.Content,
+		StopReason: choice.FinishReason,
+	}
+
+	if oaiResp.Usage != nil {
+		result.Usage = &Usage{
+			PromptTokens:     oaiResp.Usage.PromptTokens,
+			CompletionTokens: oaiResp.Usage.CompletionTokens,
+			TotalTokens:      oaiResp.Usage.TotalTokens,
+		}
+	}
+
+	for _, tc := range choice.Message.ToolCalls {
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+
+	return result, nil
+}
+
+func (o *openaiCompatible) ChatWithToolsStream(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, onText StreamFunc) (*ChatResponse, error) {
+	reqBody := o.buildRequest(systemPrompt, messages, tools)
+	reqBody.Stream = true
+	reqBody.StreamOptions = &openaiStreamOptions{IncludeUsage: true}
+
+	resp, err := o.post(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, body)
+	}
+
+	result := &ChatResponse{}
+	var content strings.Builder
+	var toolCalls []ToolCall
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openaiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("api error: %s", chunk.Error.Message)
+		}
+
+		if chunk.Usage != nil {
+			result.Usage = &Usage{
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+				TotalTokens:      chunk.Usage.TotalTokens,
+			}
+		}
+
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			result.StopReason = choice.FinishReason
+		}
+
+		if choice.Delta.Content != "" {
+			content.WriteString(choice.Delta.Content)
+			if onText != nil {
+				onText(content.String())
+			}
+		}
+
+		// tool call arguments arrive in fragments keyed by index
+		for _, tc := range choice.Delta.ToolCalls {
+			for len(toolCalls) <= tc.Index {
+				toolCalls = append(toolCalls, ToolCall{})
+			}
+			if tc.ID != "" {
+				toolCalls[tc.Index].ID = tc.ID
+			}
+			if tc.Function.Name != "" {
+				toolCalls[tc.Index].Name = tc.Function.Name
+			}
+			toolCalls[tc.Index].Arguments += tc.Function.Arguments
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read stream: %w", err)
+	}
+
+	result.Content = content.String()
+	for _, tc := range toolCalls {
+		if tc.Name != "" {
+			result.ToolCalls = append(result.ToolCalls, tc)
+		}
+	}
+
+	return result, nil
+}
+
+func (o *openaiCompatible) post(ctx context.Context, reqBody openaiRequest) (*http.Response, error) {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := o.baseURL + "/chat/completions"
+	if o.apiVersion != "" {
+		endpoint += "?api-version=" + url.QueryEscape(o.apiVersion)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiVersion != "" {
+		req.Header.Set("api-key", o.apiKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+	if o.provider == "openrouter" {
+		// identifies the app in OpenRouter's dashboard and rankings
+		req.Header.Set("X-Title", "Sheldon")
+	}
+
+	return http.DefaultClient.Do(req)
+}
+
+func (o *openaiCompatible) buildRequest(systemPrompt string, messages []Message, tools []Tool) openaiRequest {
 	var oaiMessages []openaiMessage
 
 	if systemPrompt != "" {
@@ -164,72 +395,7 @@ func (o *openaiCompatible) ChatWithTools(ctx context.Context, systemPrompt strin
 		reqBody.Tools = o.convertTools(tools)
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/chat/completions", bytes.NewReader(jsonBody))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+o.apiKey)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	var oaiResp openaiResponse
-
-	if err := json.Unmarshal(body, &oaiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if oaiResp.Error != nil {
-		return nil, fmt.Errorf("api error: %s", oaiResp.Error.Message)
-	}
-
-	if len(oaiResp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices in response")
-	}
-
-	choice := oaiResp.Choices[0]
-	result := &ChatResponse{
-		Content:    choice.Message.Content,
-		StopReason: choice.FinishReason,
-	}
-
-	if oaiResp.Usage != nil {
-		result.Usage = &Usage{
-			PromptTokens:     oaiResp.Usage.PromptTokens,
-			CompletionTokens: oaiResp.Usage.CompletionTokens,
-			TotalTokens:      oaiResp.Usage.TotalTokens,
-		}
-	}
-
-	for _, tc := range choice.Message.ToolCalls {
-		result.ToolCalls = append(result.ToolCalls, ToolCall{
-			ID:        tc.ID,
-			Name:      tc.Function.Name,
-			Arguments: tc.Function.Arguments,
-		})
-	}
-
-	return result, nil
+	return reqBody
 }
 
 func (o *openaiCompatible) convertTools(tools []Tool) []openaiTool {
@@ -255,20 +421,58 @@ func (o *openaiCompatible) convertTools(tools []Tool) []openaiTool {
 }
 
 func (o *openaiCompatible) Capabilities() Capabilities {
+	// routed model IDs carry a vendor prefix (openai/gpt-4o on OpenRouter)
+	model := o.model
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+
 	// Check if model supports vision based on known model patterns
-	vision := false
+	vision, toolUse := false, true
 	switch {
-	case strings.HasPrefix(o.model, "gpt-4o"),
-		strings.HasPrefix(o.model, "gpt-4-vision"),
-		strings.HasPrefix(o.model, "gpt-4-turbo"),
-		strings.Contains(o.model, "vision"):
+	case strings.HasPrefix(model, "gpt-4o"),
+		strings.HasPrefix(model, "gpt-4-vision"),
+		strings.HasPrefix(model, "gpt-4-turbo"),
+		strings.HasPrefix(model, "claude-"),
+		strings.Contains(model, "vision"):
+		vision = true
+	}
+
+	// a probe beats guessing from the name, e.g. for local models
+	if o.probing {
 		vision = true
+	} else if probed, ok := probedCapabilities(o.provider, o.model); ok {
+		vision, toolUse = probed.Vision, probed.ToolUse
 	}
 
 	return Capabilities{
-		Vision:     vision,
-		VideoInput: false,
-		ToolUse:    true,
+		Vision:        vision,
+		VideoInput:    false,
+		ToolUse:       toolUse,
+		ContextWindow: o.contextWindow(model),
+	}
+}
+
+// contextWindow returns a conservative context size for known model families
+func (o *openaiCompatible) contextWindow(model string) int {
+	switch {
+	case strings.HasPrefix(model, "gpt-4.1"):
+		return 1000000
+	case strings.HasPrefix(model, "claude-"):
+		return 200000
+	case strings.HasPrefix(model, "gpt-4o"),
+		strings.HasPrefix(model, "gpt-4-turbo"),
+		strings.HasPrefix(model, "o1"),
+		strings.HasPrefix(model, "o3"),
+		strings.HasPrefix(model, "kimi"),
+		strings.HasPrefix(model, "deepseek"),
+		strings.HasPrefix(model, "mistral-large"):
+		return 128000
+	case o.provider == "ollama":
+		// local models typically run with small context to fit in memory
+		return 8192
+	default:
+		return 32000
 	}
 }
 
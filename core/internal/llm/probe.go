@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math/rand/v2"
+	"strings"
+	"sync"
+)
+
+// probed holds probe results so every client for a model reports them,
+// including ones created before the probe ran
+var probed = struct {
+	sync.RWMutex
+	results map[string]ProbeResult
+}{results: make(map[string]ProbeResult)}
+
+// probeColors are easy to name; a random one keeps text-only models from
+// passing the vision test by guessing
+var probeColors = map[string]color.RGBA{
+	"red":    {0xe0, 0x10, 0x10, 0xff},
+	"green":  {0x10, 0xc0, 0x10, 0xff},
+	"blue":   {0x10, 0x30, 0xe0, 0xff},
+	"yellow": {0xf0, 0xe0, 0x10, 0xff},
+}
+
+var probeTool = Tool{
+	Name:        "add_numbers",
+	Description: "Add two numbers and return the sum",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"a": map[string]any{"type": "number"},
+			"b": map[string]any{"type": "number"},
+		},
+		"required": []string{"a", "b"},
+	},
+}
+
+// RecordProbe makes clients for a model report what a probe found instead of
+// guessing from the model name
+func RecordProbe(provider, model string, result ProbeResult) {
+	probed.Lock()
+	defer probed.Unlock()
+	probed.results[provider+"/"+model] = result
+}
+
+func probedCapabilities(provider, model string) (ProbeResult, bool) {
+	probed.RLock()
+	defer probed.RUnlock()
+	result, ok := probed.results[provider+"/"+model]
+	return result, ok
+}
+
+// Probe tests what a model can actually do: reply to a message, call a tool
+// and name the color of an image. A test the provider rejects outright (e.g.
+// "model does not support tools") counts as unsupported; other errors, like an
+// unreachable server, are returned.
+func Probe(ctx context.Context, model LLM) (ProbeResult, error) {
+	for {
+		w, ok := model.(interface{ Unwrap() LLM })
+		if !ok {
+			break
+		}
+		model = w.Unwrap()
+	}
+
+	switch m := model.(type) {
+	case *claude:
+		// every Claude model handles tools and images
+		caps := m.Capabilities()
+		return ProbeResult{Chat: true, ToolUse: caps.ToolUse, Vision: caps.Vision}, nil
+	case *openaiCompatible:
+		probing := *m
+		probing.probing = true
+		model = &probing
+	}
+
+	var result ProbeResult
+
+	reply, err := model.Chat(ctx, "", []Message{{Role: "user", Content: "Reply with the single word: ready"}})
+	if err != nil {
+		if unsupported(err) {
+			return result, nil
+		}
+		return result, err
+	}
+	result.Chat = strings.TrimSpace(reply) != ""
+	if !result.Chat {
+		return result, nil
+	}
+
+	resp, err := model.ChatWithTools(ctx, "", []Message{{Role: "user", Content: "Use the add_numbers tool to add 17 and 25."}}, []Tool{probeTool})
+	if err != nil && !unsupported(err) {
+		return result, err
+	}
+	if err == nil {
+		for _, tc := range resp.ToolCalls {
+			if tc.Name == probeTool.Name {
+				result.ToolUse = true
+			}
+		}
+	}
+
+	name, img, err := probeImage()
+	if err != nil {
+		return result, err
+	}
+	reply, err = model.Chat(ctx, "", []Message{{
+		Role:    "user",
+		Content: "What color is this image? Answer with one word.",
+		Media:   []MediaContent{{Type: MediaTypeImage, Data: img, MimeType: "image/png"}},
+	}})
+	if err != nil && !unsupported(err) {
+		return result, err
+	}
+	result.Vision = err == nil && strings.Contains(strings.ToLower(reply), name)
+
+	return result, nil
+}
+
+// unsupported reports whether the provider refused the request itself rather
+// than failing to answer it
+func unsupported(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != 401 && apiErr.StatusCode != 403 && apiErr.StatusCode != 429
+	}
+	return false
+}
+
+func probeImage() (string, []byte, error) {
+	names := make([]string, 0, len(probeColors))
+	for name := range probeColors {
+		names = append(names, name)
+	}
+	name := names[rand.IntN(len(names))]
+
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(img, img.Bounds(), image.NewUniform(probeColors[name]), image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", nil, err
+	}
+	return name, buf.Bytes(), nil
+}
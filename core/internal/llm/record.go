@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+var recorder atomic.Pointer[Recorder]
+
+// SetRecorder sends every request made by clients from New to r, including
+// fallback and per-session clients. Clients created earlier are not recorded.
+func SetRecorder(r Recorder) {
+	recorder.Store(&r)
+}
+
+// recorded reports each request with its final outcome, after retries
+type recorded struct {
+	LLM
+	recorder Recorder
+}
+
+func withRecorder(inner LLM) LLM {
+	r := recorder.Load()
+	if r == nil || *r == nil {
+		return inner
+	}
+	return &recorded{LLM: inner, recorder: *r}
+}
+
+func (r *recorded) Unwrap() LLM {
+	return r.LLM
+}
+
+func (r *recorded) Chat(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	start := time.Now()
+	content, err := r.LLM.Chat(ctx, systemPrompt, messages)
+
+	var resp *ChatResponse
+	if err == nil {
+		resp = &ChatResponse{Content: content}
+	}
+	r.record(ctx, systemPrompt, messages, nil, resp, err, start)
+
+	return content, err
+}
+
+func (r *recorded) ChatWithTools(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*ChatResponse, error) {
+	start := time.Now()
+	resp, err := r.LLM.ChatWithTools(ctx, systemPrompt, messages, tools)
+	r.record(ctx, systemPrompt, messages, tools, resp, err, start)
+	return resp, err
+}
+
+func (r *recorded) ChatWithToolsStream(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, onText StreamFunc) (*ChatResponse, error) {
+	start := time.Now()
+	resp, err := r.LLM.ChatWithToolsStream(ctx, systemPrompt, messages, tools, onText)
+	r.record(ctx, systemPrompt, messages, tools, resp, err, start)
+	return resp, err
+}
+
+func (r *recorded) record(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, resp *ChatResponse, err error, start time.Time) {
+	r.recorder.Record(ctx, Exchange{
+		Provider: r.Provider(),
+		Model:    r.Model(),
+		System:   systemPrompt,
+		Messages: messages,
+		Tools:    tools,
+		Response: resp,
+		Err:      err,
+		Latency:  time.Since(start),
+	})
+}
@@ -0,0 +1,180 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+const (
+	defaultMaxAttempts = 3
+	baseDelay          = 2 * time.Second
+	maxDelay           = 30 * time.Second
+)
+
+// APIError is a non-2xx response from a provider's HTTP API
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header, 0 if absent
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (status %d): %s", e.StatusCode, e.Body)
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		apiErr.RetryAfter = time.Duration(secs) * time.Second
+	}
+	return apiErr
+}
+
+// retrying wraps any provider with retry, exponential backoff and jitter
+// for transient failures (rate limits, overload, dropped connections)
+type retrying struct {
+	LLM
+	maxAttempts int
+}
+
+func withRetry(inner LLM, maxAttempts int) LLM {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if maxAttempts == 1 {
+		return inner
+	}
+	return &retrying{LLM: inner, maxAttempts: maxAttempts}
+}
+
+func (r *retrying) Unwrap() LLM {
+	return r.LLM
+}
+
+func (r *retrying) Chat(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	var content string
+	err := r.do(ctx, func() error {
+		var err error
+		content, err = r.LLM.Chat(ctx, systemPrompt, messages)
+		return err
+	})
+	return content, err
+}
+
+func (r *retrying) ChatWithTools(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*ChatResponse, error) {
+	var resp *ChatResponse
+	err := r.do(ctx, func() error {
+		var err error
+		resp, err = r.LLM.ChatWithTools(ctx, systemPrompt, messages, tools)
+		return err
+	})
+	return resp, err
+}
+
+func (r *retrying) ChatWithToolsStream(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, onText StreamFunc) (*ChatResponse, error) {
+	var resp *ChatResponse
+	streamed := false
+	err := r.do(ctx, func() error {
+		var err error
+		resp, err = r.LLM.ChatWithToolsStream(ctx, systemPrompt, messages, tools, func(text string) {
+			streamed = true
+			if onText != nil {
+				onText(text)
+			}
+		})
+		// text already reached the user, a retry would show it twice
+		if err != nil && streamed {
+			return permanent{err}
+		}
+		return err
+	})
+	return resp, err
+}
+
+func (r *retrying) do(ctx context.Context, call func() error) error {
+	var err error
+	for attempt := range r.maxAttempts {
+		err = call()
+		if err == nil {
+			return nil
+		}
+
+		var p permanent
+		if errors.As(err, &p) {
+			return p.err
+		}
+		if !isRetryableError(ctx, err) || attempt == r.maxAttempts-1 {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff(attempt, err)):
+		}
+	}
+	return err
+}
+
+// permanent stops the retry loop regardless of the underlying error
+type permanent struct{ err error }
+
+func (p permanent) Error() string { return p.err.Error() }
+
+// backoff returns an exponential delay with jitter, honoring Retry-After
+func backoff(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return min(apiErr.RetryAfter, maxDelay)
+	}
+
+	delay := min(baseDelay*time.Duration(1<<attempt), maxDelay)
+	// equal jitter: half fixed, half random so concurrent callers spread out
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+
+	var sdkErr *anthropic.Error
+	if errors.As(err, &sdkErr) {
+		return isRetryableStatus(sdkErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// errors delivered inside a stream only carry text
+	errStr := err.Error()
+	return strings.Contains(errStr, "overloaded") ||
+		strings.Contains(errStr, "Overloaded") ||
+		strings.Contains(errStr, "rate_limit")
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	}
+	return false
+}
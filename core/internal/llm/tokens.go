@@ -0,0 +1,52 @@
+package llm
+
+import "encoding/json"
+
+// Token estimates are deliberately rough (~4 characters per token for English
+// text). They only need to be good enough to decide when to compact history.
+const (
+	charsPerToken    = 4
+	messageOverhead  = 4    // role and framing tokens per message
+	imageTokens      = 1600 // a typical resized image
+	videoTokens      = 8000
+	pdfTokensPerByte = 0.01 // ~1k tokens per 100KB of PDF
+)
+
+// EstimateTokens approximates the token count of a piece of text
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateMessageTokens approximates the tokens a message occupies in the context window
+func EstimateMessageTokens(msg Message) int {
+	tokens := messageOverhead + EstimateTokens(msg.Content)
+
+	for _, tc := range msg.ToolCalls {
+		tokens += EstimateTokens(tc.Name) + EstimateTokens(tc.Arguments)
+	}
+
+	for _, m := range msg.Media {
+		switch m.Type {
+		case MediaTypeImage:
+			tokens += imageTokens
+		case MediaTypeVideo:
+			tokens += videoTokens
+		case MediaTypePDF:
+			tokens += int(float64(len(m.Data)) * pdfTokensPerByte)
+		}
+	}
+
+	return tokens
+}
+
+// EstimateToolTokens approximates the tokens used by tool definitions
+func EstimateToolTokens(tools []Tool) int {
+	tokens := 0
+	for _, t := range tools {
+		tokens += messageOverhead + EstimateTokens(t.Name) + EstimateTokens(t.Description)
+		if schema, err := json.Marshal(t.Parameters); err == nil {
+			tokens += EstimateTokens(string(schema))
+		}
+	}
+	return tokens
+}
@@ -1,12 +1,17 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Config struct {
-	Provider string
-	APIKey   string
-	Model    string
-	BaseURL  string
+	Provider    string
+	APIKey      string
+	Model       string
+	BaseURL     string
+	APIVersion  string // Azure OpenAI only
+	MaxAttempts int    // total tries per request on transient errors, 0 = default (3)
 }
 
 type MediaType string
@@ -15,12 +20,15 @@ const (
 	MediaTypeImage MediaType = "image"
 	MediaTypeVideo MediaType = "video"
 	MediaTypePDF   MediaType = "pdf"
+	// office documents no provider reads natively, read_document extracts their text
+	MediaTypeDocument MediaType = "document"
 )
 
 type MediaContent struct {
 	Type      MediaType
 	Data      []byte
 	MimeType  string
+	Filename  string // original file name for documents, if known
 }
 
 type Message struct {
@@ -51,9 +59,11 @@ type ChatResponse struct {
 }
 
 type Usage struct {
-	PromptTokens     int
+	PromptTokens     int // uncached input tokens
 	CompletionTokens int
 	TotalTokens      int
+	CacheWriteTokens int // input tokens written to the prompt cache (Claude)
+	CacheReadTokens  int // input tokens served from the prompt cache (Claude)
 }
 
 type Capabilities struct {
@@ -61,11 +71,57 @@ type Capabilities struct {
 	VideoInput  bool
 	PDFInput    bool
 	ToolUse     bool
+
+	ContextWindow int // max input tokens the model accepts
 }
 
+// ProbeResult is what a live test found a model can do
+type ProbeResult struct {
+	Chat    bool
+	ToolUse bool
+	Vision  bool
+}
+
+// ProviderHealth is the failure history of one provider
+type ProviderHealth struct {
+	Provider      string
+	Failures      int // consecutive failures, reset by a success
+	TotalFailures int
+	LastError     string
+	LastFailure   time.Time
+	LastSuccess   time.Time
+	CooldownUntil time.Time
+}
+
+// CoolingDown reports whether the provider should be skipped until CooldownUntil
+func (h ProviderHealth) CoolingDown(now time.Time) bool {
+	return now.Before(h.CooldownUntil)
+}
+
+// Exchange is one request to a provider and its outcome, see SetRecorder
+type Exchange struct {
+	Provider string
+	Model    string
+	System   string
+	Messages []Message
+	Tools    []Tool
+	Response *ChatResponse // nil on error
+	Err      error
+	Latency  time.Duration
+}
+
+// Recorder receives every exchange of clients created with New
+type Recorder interface {
+	Record(ctx context.Context, ex Exchange)
+}
+
+// StreamFunc receives the full response text generated so far each time the model emits more
+type StreamFunc func(text string)
+
 type LLM interface {
 	Chat(ctx context.Context, systemPrompt string, messages []Message) (string, error)
 	ChatWithTools(ctx context.Context, systemPrompt string, messages []Message, tools []Tool) (*ChatResponse, error)
+	ChatWithToolsStream(ctx context.Context, systemPrompt string, messages []Message, tools []Tool, onText StreamFunc) (*ChatResponse, error)
 	Capabilities() Capabilities
 	Provider() string
 	Model() string
@@ -0,0 +1,115 @@
+package llmlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+func TestRedact(t *testing.T) {
+	r := NewRedactor("static-secret-value")
+	r.SetSource(func() ([]string, error) {
+		return []string{"DE89370400440532013000", "no"}, nil
+	})
+
+	in := "iban DE89370400440532013000, key sk-ant-REDACTED, token static-secret-value, no change"
+	got := r.Redact(in)
+
+	for _, secret := range []string{"DE89370400440532013000", "sk-ant-api03", "static-secret-value"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("expected %q to be redacted, got %q", secret, got)
+		}
+	}
+	if !strings.Contains(got, "no change") {
+		t.Errorf("short values should not be redacted, got %q", got)
+	}
+}
+
+func TestRedactKeepsValuesOnSourceError(t *testing.T) {
+	r := NewRedactor("static-secret-value")
+	r.SetSource(func() ([]string, error) { return nil, errors.New("db closed") })
+
+	if got := r.Redact("static-secret-value"); got != redacted {
+		t.Errorf("expected static secret redacted after source error, got %q", got)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llm.jsonl")
+	l, err := New(Config{
+		Path:        path,
+		MaxMessages: 2,
+		MaxChars:    20,
+		Session:     func(context.Context) string { return "telegram:1" },
+	}, NewRedactor("hunter2-password"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer l.Close()
+
+	l.Record(context.Background(), llm.Exchange{
+		Provider: "claude",
+		Model:    "claude-sonnet-4",
+		Messages: []llm.Message{
+			{Role: "user", Content: "first"},
+			{Role: "user", Content: "my password is hunter2-password"},
+			{Role: "assistant", ToolCalls: []llm.ToolCall{{Name: "save_memory", Arguments: `{"value":"hunter2-password"}`}}},
+		},
+		Tools:    []llm.Tool{{Name: "save_memory"}},
+		Response: &llm.ChatResponse{Content: strings.Repeat("x", 50), Usage: &llm.Usage{PromptTokens: 10, CompletionTokens: 5}},
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Fatalf("secret leaked into log: %s", data)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("decode entry: %v", err)
+	}
+	if entry.Session != "telegram:1" || entry.MessageCount != 3 || len(entry.Messages) != 2 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.Messages[1].ToolCalls[0].Name != "save_memory" {
+		t.Errorf("expected tool call kept, got %+v", entry.Messages[1])
+	}
+	if !strings.HasPrefix(entry.Response.Content, strings.Repeat("x", 20)+"...") {
+		t.Errorf("expected truncated response, got %q", entry.Response.Content)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llm.jsonl")
+	l, err := New(Config{Path: path, MaxBytes: 300, MaxFiles: 2}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer l.Close()
+
+	for range 10 {
+		l.Record(context.Background(), llm.Exchange{
+			Provider: "ollama",
+			Model:    "qwen3:8b",
+			Messages: []llm.Message{{Role: "user", Content: strings.Repeat("a", 100)}},
+		})
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("expected %s: %v", filepath.Base(name), err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated files, got .3 (err %v)", err)
+	}
+}
@@ -0,0 +1,196 @@
+package llmlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const (
+	defaultMaxBytes    = 50 << 20
+	defaultMaxFiles    = 3
+	defaultMaxMessages = 10
+	defaultMaxChars    = 2000
+)
+
+// New opens (or continues) the log at cfg.Path. The file is created owner-only
+// since it holds conversations even after redaction.
+func New(cfg Config, redactor *Redactor) (*Logger, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log path is required")
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultMaxBytes
+	}
+	if cfg.MaxFiles <= 0 {
+		cfg.MaxFiles = defaultMaxFiles
+	}
+	if cfg.MaxMessages <= 0 {
+		cfg.MaxMessages = defaultMaxMessages
+	}
+	if cfg.MaxChars <= 0 {
+		cfg.MaxChars = defaultMaxChars
+	}
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0700); err != nil {
+		return nil, fmt.Errorf("create log dir: %w", err)
+	}
+
+	l := &Logger{cfg: cfg, redactor: redactor}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record writes one exchange, see llm.SetRecorder
+func (l *Logger) Record(ctx context.Context, ex llm.Exchange) {
+	entry := l.entry(ctx, ex)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.Warn("failed to encode llm log entry", "error", err)
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.cfg.MaxBytes {
+		if err := l.rotate(); err != nil {
+			logger.Warn("failed to rotate llm log", "error", err)
+			return
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		logger.Warn("failed to write llm log", "error", err)
+	}
+}
+
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *Logger) entry(ctx context.Context, ex llm.Exchange) Entry {
+	entry := Entry{
+		Time:         time.Now().UTC(),
+		Provider:     ex.Provider,
+		Model:        ex.Model,
+		LatencyMS:    ex.Latency.Milliseconds(),
+		System:       l.text(ex.System),
+		MessageCount: len(ex.Messages),
+	}
+	if l.cfg.Session != nil {
+		entry.Session = l.cfg.Session(ctx)
+	}
+
+	messages := ex.Messages
+	if len(messages) > l.cfg.MaxMessages {
+		messages = messages[len(messages)-l.cfg.MaxMessages:]
+	}
+	for _, m := range messages {
+		entry.Messages = append(entry.Messages, Message{
+			Role:       m.Role,
+			Content:    l.text(m.Content),
+			Media:      len(m.Media),
+			ToolCalls:  l.toolCalls(m.ToolCalls),
+			ToolCallID: m.ToolCallID,
+		})
+	}
+
+	for _, t := range ex.Tools {
+		entry.Tools = append(entry.Tools, t.Name)
+	}
+
+	if ex.Response != nil {
+		entry.Response = &Response{
+			Content:    l.text(ex.Response.Content),
+			ToolCalls:  l.toolCalls(ex.Response.ToolCalls),
+			StopReason: ex.Response.StopReason,
+		}
+		if u := ex.Response.Usage; u != nil {
+			entry.Response.InputTokens = u.PromptTokens + u.CacheWriteTokens + u.CacheReadTokens
+			entry.Response.OutputTokens = u.CompletionTokens
+		}
+	}
+	if ex.Err != nil {
+		entry.Error = l.text(ex.Err.Error())
+	}
+
+	return entry
+}
+
+func (l *Logger) toolCalls(calls []llm.ToolCall) []ToolCall {
+	var result []ToolCall
+	for _, tc := range calls {
+		result = append(result, ToolCall{Name: tc.Name, Arguments: l.text(tc.Arguments)})
+	}
+	return result
+}
+
+// text redacts before truncating so a secret cut at the limit can't leak
+func (l *Logger) text(s string) string {
+	s = l.redactor.Redact(s)
+	if len(s) > l.cfg.MaxChars {
+		return s[:l.cfg.MaxChars] + fmt.Sprintf("...(%d more chars)", len(s)-l.cfg.MaxChars)
+	}
+	return s
+}
+
+func (l *Logger) open() error {
+	f, err := os.OpenFile(l.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("open llm log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat llm log: %w", err)
+	}
+
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate shifts path.1 to path.2 and so on, dropping the oldest
+func (l *Logger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		logger.Warn("failed to close llm log", "error", err)
+	}
+	l.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", l.cfg.Path, l.cfg.MaxFiles))
+	for i := l.cfg.MaxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.cfg.Path, i), fmt.Sprintf("%s.%d", l.cfg.Path, i+1))
+	}
+	if err := os.Rename(l.cfg.Path, l.cfg.Path+".1"); err != nil {
+		return err
+	}
+
+	return l.open()
+}
@@ -0,0 +1,90 @@
+package llmlog
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const (
+	redacted = "[REDACTED]"
+
+	// sensitive facts change rarely, no need to query memory per request
+	refreshInterval = time.Minute
+
+	// shorter values would redact ordinary words and numbers everywhere
+	minSecretLen = 4
+)
+
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`), // openai, anthropic, moonshot
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+}
+
+// NewRedactor redacts key patterns and the given static secrets, e.g. the
+// configured API keys
+func NewRedactor(static ...string) *Redactor {
+	r := &Redactor{static: static}
+	r.values = r.merge(nil)
+	return r
+}
+
+// SetSource adds values to redact that can change at runtime, like sensitive
+// facts in memory. It is called at most once a minute.
+func (r *Redactor) SetSource(source func() ([]string, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.source = source
+	r.fetched = time.Time{}
+}
+
+// Redact replaces every known secret in s
+func (r *Redactor) Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, secret := range r.secrets() {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+func (r *Redactor) secrets() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.source != nil && time.Since(r.fetched) > refreshInterval {
+		values, err := r.source()
+		if err != nil {
+			// keep the previous values, better stale than none
+			logger.Warn("failed to load values to redact", "error", err)
+		} else {
+			r.values = r.merge(values)
+		}
+		r.fetched = time.Now()
+	}
+
+	return r.values
+}
+
+// merge combines dynamic and static secrets, longest first so a secret
+// containing another is replaced whole
+func (r *Redactor) merge(values []string) []string {
+	var merged []string
+	for _, v := range append(values, r.static...) {
+		if len(v) >= minSecretLen {
+			merged = append(merged, v)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return len(merged[i]) > len(merged[j]) })
+	return merged
+}
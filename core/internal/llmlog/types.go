@@ -0,0 +1,76 @@
+package llmlog
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config controls where the log goes and how much of each request is kept
+type Config struct {
+	Path        string // JSON lines file
+	MaxBytes    int64  // rotate when the file grows past this (default 50MB)
+	MaxFiles    int    // rotated files kept next to Path as .1, .2, ... (default 3)
+	MaxMessages int    // most recent messages logged per request (default 10)
+	MaxChars    int    // per message, system prompt and response (default 2000)
+
+	// Session reads the session ID from the request context, optional
+	Session func(ctx context.Context) string
+}
+
+// Logger writes one redacted JSON line per LLM request and implements llm.Recorder
+type Logger struct {
+	cfg      Config
+	redactor *Redactor
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Entry is one line of the log
+type Entry struct {
+	Time         time.Time `json:"time"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Session      string    `json:"session,omitempty"`
+	LatencyMS    int64     `json:"latency_ms"`
+	System       string    `json:"system,omitempty"`
+	MessageCount int       `json:"message_count"`
+	Messages     []Message `json:"messages"` // the most recent MaxMessages
+	Tools        []string  `json:"tools,omitempty"`
+	Response     *Response `json:"response,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content,omitempty"`
+	Media      int        `json:"media,omitempty"` // attachments are counted, never logged
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+type ToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type Response struct {
+	Content      string     `json:"content,omitempty"`
+	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
+	StopReason   string     `json:"stop_reason,omitempty"`
+	InputTokens  int        `json:"input_tokens,omitempty"`
+	OutputTokens int        `json:"output_tokens,omitempty"`
+}
+
+// Redactor replaces secrets in logged text: API keys by pattern, plus
+// sensitive memory facts and configured secrets by value
+type Redactor struct {
+	mu      sync.Mutex
+	static  []string
+	source  func() ([]string, error)
+	values  []string
+	fetched time.Time
+}
@@ -0,0 +1,126 @@
+package locale
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateNames are the weekday and month names of a language and how it orders
+// them. Layouts use {W} weekday, {D} day, {M} month, {Y} year and {T} time.
+type dateNames struct {
+	weekdays [7]string // Sunday first, like time.Weekday
+	months   [12]string
+	long     string // weekday, full date and time
+	short    string // weekday, date without the year, and time
+	date     string // date only
+}
+
+var dates = map[string]dateNames{
+	"de": {
+		weekdays: [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		months:   [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		long:     "{W}, {D}. {M} {Y}, {T}",
+		short:    "{W}, {D}. {M}, {T}",
+		date:     "{D}. {M} {Y}",
+	},
+	"fr": {
+		weekdays: [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		months:   [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		long:     "{W} {D} {M} {Y}, {T}",
+		short:    "{W} {D} {M}, {T}",
+		date:     "{D} {M} {Y}",
+	},
+	"es": {
+		weekdays: [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		months:   [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		long:     "{W}, {D} de {M} de {Y}, {T}",
+		short:    "{W}, {D} de {M}, {T}",
+		date:     "{D} de {M} de {Y}",
+	},
+	"it": {
+		weekdays: [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		months:   [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		long:     "{W} {D} {M} {Y}, {T}",
+		short:    "{W} {D} {M}, {T}",
+		date:     "{D} {M} {Y}",
+	},
+	"pt": {
+		weekdays: [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		months:   [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		long:     "{W}, {D} de {M} de {Y}, {T}",
+		short:    "{W}, {D} de {M}, {T}",
+		date:     "{D} de {M} de {Y}",
+	},
+	"nl": {
+		weekdays: [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		months:   [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		long:     "{W} {D} {M} {Y}, {T}",
+		short:    "{W} {D} {M}, {T}",
+		date:     "{D} {M} {Y}",
+	},
+}
+
+// LongDateTime is a full date and time, e.g. "Monday, January 2, 2006 3:04 PM"
+// or "Montag, 2. Januar 2006, 15:04"
+func (p Prefs) LongDateTime(t time.Time) string {
+	if p.english() {
+		return t.Format("Monday, January 2, 2006 " + p.clock())
+	}
+	if names, ok := dates[p.Lang]; ok {
+		return names.format(t, names.long, p.clock())
+	}
+	return t.Format("Monday 2006-01-02 " + p.clock())
+}
+
+// DateTime is a date and time within the year, e.g. "Mon Jan 2 3:04 PM" or
+// "Montag, 2. Januar, 15:04"
+func (p Prefs) DateTime(t time.Time) string {
+	if p.english() {
+		return t.Format("Mon Jan 2 " + p.clock())
+	}
+	if names, ok := dates[p.Lang]; ok {
+		return names.format(t, names.short, p.clock())
+	}
+	return t.Format("2006-01-02 " + p.clock())
+}
+
+// Date is a date without the time, e.g. "Jan 2, 2006" or "2. Januar 2006"
+func (p Prefs) Date(t time.Time) string {
+	if p.english() {
+		return t.Format("Jan 2, 2006")
+	}
+	if names, ok := dates[p.Lang]; ok {
+		return names.format(t, names.date, p.clock())
+	}
+	return t.Format("2006-01-02")
+}
+
+// Time is the time of day, e.g. "3:04 PM" or "15:04"
+func (p Prefs) Time(t time.Time) string {
+	return t.Format(p.clock())
+}
+
+func (p Prefs) english() bool {
+	return p.Lang == "" || p.Lang == English
+}
+
+// clock is the time layout: English speakers get a 12-hour clock and
+// everyone else a 24-hour one, unless they said otherwise
+func (p Prefs) clock() string {
+	if p.Clock == 12 || (p.Clock == 0 && p.english()) {
+		return "3:04 PM"
+	}
+	return "15:04"
+}
+
+// format fills a layout
+func (n dateNames) format(t time.Time, layout, clock string) string {
+	return strings.NewReplacer(
+		"{W}", n.weekdays[t.Weekday()],
+		"{D}", strconv.Itoa(t.Day()),
+		"{M}", n.months[t.Month()-1],
+		"{Y}", strconv.Itoa(t.Year()),
+		"{T}", t.Format(clock),
+	).Replace(layout)
+}
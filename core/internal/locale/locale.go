@@ -0,0 +1,188 @@
+// Package locale detects which language a message is written in and formats
+// dates the way speakers of that language write them
+package locale
+
+import (
+	"strings"
+	"unicode"
+)
+
+// English is the default when a chat's language isn't known
+const English = "en"
+
+// names maps ISO 639-1 codes to English language names. Detection covers
+// fewer languages; the rest can still be set as a preference by name.
+var names = map[string]string{
+	"ar": "Arabic",
+	"cs": "Czech",
+	"da": "Danish",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fi": "Finnish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"hu": "Hungarian",
+	"id": "Indonesian",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"no": "Norwegian",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ro": "Romanian",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"th": "Thai",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"vi": "Vietnamese",
+	"zh": "Chinese",
+}
+
+// Name is the English name of a language code, or the code itself if it's
+// not one we know
+func Name(code string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return code
+}
+
+// Code turns a language code or English name ("de", "German", "german")
+// into its code, empty if it isn't recognized
+func Code(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if _, ok := names[s]; ok {
+		return s
+	}
+	for code, name := range names {
+		if strings.ToLower(name) == s {
+			return code
+		}
+	}
+	return ""
+}
+
+// stopwords are short, frequent words that tell Latin-script languages
+// apart. Words shared between languages count for each of them.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "to", "of", "it", "that", "what", "this", "for", "with", "have", "my", "me", "was", "can", "do", "not", "please", "i", "be", "on", "at", "how", "when", "remind", "tomorrow", "thanks"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "nicht", "du", "ein", "eine", "zu", "mit", "auf", "für", "es", "sie", "wir", "wie", "was", "bitte", "mir", "mich", "den", "dem", "auch", "noch", "kannst", "habe", "morgen", "danke", "erinnere"},
+	"fr": {"le", "la", "les", "et", "est", "je", "tu", "vous", "une", "un", "des", "du", "pas", "que", "qui", "pour", "avec", "dans", "ce", "mon", "ma", "sur", "il", "elle", "merci", "peux", "demain", "rappelle", "moi", "c'est"},
+	"es": {"el", "la", "los", "las", "y", "es", "yo", "tú", "que", "de", "un", "una", "por", "para", "con", "no", "mi", "me", "qué", "cómo", "está", "gracias", "puedes", "del", "lo", "mañana", "recuérdame"},
+	"it": {"il", "lo", "la", "gli", "le", "e", "è", "io", "che", "di", "un", "una", "per", "con", "non", "mi", "sono", "cosa", "come", "grazie", "puoi", "del", "della", "ciao", "domani", "ricordami"},
+	"pt": {"o", "a", "os", "as", "e", "é", "eu", "você", "que", "de", "um", "uma", "por", "para", "com", "não", "meu", "minha", "obrigado", "obrigada", "pode", "do", "da", "está", "amanhã", "lembre"},
+	"nl": {"de", "het", "een", "en", "is", "ik", "je", "jij", "niet", "van", "dat", "met", "voor", "op", "wat", "hoe", "mijn", "kun", "kan", "bedankt", "alsjeblieft", "ook", "morgen", "herinner"},
+}
+
+var stopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}()
+
+// minWords is how many words a Latin-script message needs before its
+// language is trusted; "ok" or "lol" says nothing
+const minWords = 3
+
+// Detect guesses the language of text. confident is false when the text is
+// too short or too mixed to tell, and callers shouldn't act on the guess.
+func Detect(text string) (lang string, confident bool) {
+	if lang, ok := detectScript(text); ok {
+		return lang, true
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) < minWords {
+		return English, false
+	}
+
+	scores := make(map[string]int)
+	for _, w := range words {
+		for _, lang := range stopwordIndex[w] {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, second := English, 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore || (score == bestScore && lang < best):
+			best, second, bestScore = lang, bestScore, score
+		case score > second:
+			second = score
+		}
+	}
+
+	// a clear winner: at least two hits and half again as many as the runner-up
+	return best, bestScore >= 2 && bestScore*2 >= second*3
+}
+
+// scripts maps alphabets to the language they're most likely written in;
+// detectScript refines Cyrillic and Han
+var scripts = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// detectScript recognizes languages by their alphabet, which is reliable
+// even for short messages
+func detectScript(text string) (string, bool) {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[s.lang]++
+				break
+			}
+		}
+	}
+
+	// kanji with any kana is Japanese, not Chinese
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+
+	best, bestCount := "", 0
+	for lang, n := range counts {
+		if n > bestCount {
+			best, bestCount = lang, n
+		}
+	}
+
+	// mostly one script, and enough of it
+	if bestCount < 2 || bestCount*2 < letters {
+		return "", false
+	}
+	if best == "ru" && strings.ContainsAny(text, "іїєґІЇЄҐ") {
+		best = "uk"
+	}
+	return best, true
+}
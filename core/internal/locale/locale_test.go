@@ -0,0 +1,100 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text      string
+		lang      string
+		confident bool
+	}{
+		{"Can you remind me to call the dentist tomorrow?", "en", true},
+		{"Kannst du mich morgen an den Zahnarzt erinnern?", "de", true},
+		{"Peux-tu me rappeler d'appeler le dentiste demain ?", "fr", true},
+		{"¿Puedes recordarme llamar al dentista mañana por la tarde?", "es", true},
+		{"Puoi ricordarmi di chiamare il dentista domani?", "it", true},
+		{"Você pode me lembrar de ligar para o dentista amanhã?", "pt", true},
+		{"Kun je me morgen herinneren de tandarts te bellen?", "nl", true},
+		{"Напомни мне завтра позвонить стоматологу", "ru", true},
+		{"Нагадай мені завтра зателефонувати їй", "uk", true},
+		{"明日歯医者に電話するのを思い出させて", "ja", true},
+		{"明天提醒我给牙医打电话", "zh", true},
+		{"내일 치과에 전화하라고 알려줘", "ko", true},
+		{"ok thanks", "en", false},
+		{"git push origin main", "en", false},
+	}
+
+	for _, tt := range tests {
+		lang, confident := Detect(tt.text)
+		if confident != tt.confident || (tt.confident && lang != tt.lang) {
+			t.Errorf("Detect(%q) = %s, %v; want %s, %v", tt.text, lang, confident, tt.lang, tt.confident)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	for in, want := range map[string]string{"de": "de", "German": "de", " spanish ": "es", "Klingon": ""} {
+		if got := Code(in); got != want {
+			t.Errorf("Code(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if Name("fr") != "French" || Name("xx") != "xx" {
+		t.Error("unexpected language names")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	ts := time.Date(2026, 3, 2, 15, 4, 0, 0, time.UTC)
+	en, de := Prefs{}, Prefs{Lang: "de"}
+
+	tests := []struct {
+		got, want string
+	}{
+		{en.DateTime(ts), "Mon Mar 2 3:04 PM"},
+		{de.DateTime(ts), "Montag, 2. März, 15:04"},
+		{Prefs{Lang: "ru"}.DateTime(ts), "2026-03-02 15:04"},
+		{Prefs{Lang: "en"}.LongDateTime(ts), "Monday, March 2, 2026 3:04 PM"},
+		{Prefs{Lang: "es"}.LongDateTime(ts), "lunes, 2 de marzo de 2026, 15:04"},
+		{en.Date(ts), "Mar 2, 2026"},
+		{Prefs{Lang: "fr"}.Date(ts), "2 mars 2026"},
+		{Prefs{Clock: 24}.DateTime(ts), "Mon Mar 2 15:04"},
+		{Prefs{Lang: "de", Clock: 12}.Time(ts), "3:04 PM"},
+		{en.Distance(12345), "12.3 km"},
+		{Prefs{Imperial: true}.Distance(12345), "7.7 mi"},
+		{Money(0.01234, "USD"), "$0.0123"},
+		{Money(12.5, "EUR"), "€12.50"},
+		{Money(12.5, "CHF"), "12.50 CHF"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestPrefsSet(t *testing.T) {
+	var p Prefs
+	for _, f := range [][2]string{
+		{"units", "Imperial (miles, °F)"},
+		{"clock_format", "24h"},
+		{"preferred_currency", "euros"},
+	} {
+		if !p.Set(f[0], f[1]) {
+			t.Errorf("Set(%q, %q) not understood", f[0], f[1])
+		}
+	}
+	if want := (Prefs{Imperial: true, Clock: 24, Currency: "EUR"}); p != want {
+		t.Errorf("prefs = %+v, want %+v", p, want)
+	}
+
+	// look-alike fields and unknown values leave the preferences alone
+	if p.Set("community_role", "imperial") || p.Set("currency", "seashells") || p.Set("time_zone", "24") {
+		t.Error("unrelated fact was taken as a preference")
+	}
+	if p.Currency != "EUR" || p.Clock != 24 {
+		t.Errorf("prefs changed: %+v", p)
+	}
+}
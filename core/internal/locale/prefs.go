@@ -0,0 +1,126 @@
+package locale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prefs are how a user likes dates, measurements and money written. The zero
+// value follows the language: metric, and a 12-hour clock only in English.
+type Prefs struct {
+	Lang     string // language code, empty for English
+	Imperial bool   // miles instead of kilometres
+	Clock    int    // 12 or 24, 0 follows the language
+	Currency string // ISO 4217 code the user thinks in, empty when not known
+}
+
+// currencies are the symbols written before an amount; other codes go after
+// it, like "12.50 CHF"
+var currencies = map[string]struct {
+	symbol string
+	names  []string
+}{
+	"USD": {"$", []string{"$", "dollar", "dollars", "us dollar", "us dollars"}},
+	"EUR": {"€", []string{"€", "euro", "euros"}},
+	"GBP": {"£", []string{"£", "pound", "pounds", "sterling"}},
+	"JPY": {"¥", []string{"¥", "yen"}},
+	"INR": {"₹", []string{"₹", "rupee", "rupees"}},
+	"CHF": {"", []string{"swiss franc", "swiss francs", "franc", "francs"}},
+	"SEK": {"", []string{"swedish krona", "kronor"}},
+	"NOK": {"", []string{"norwegian krone"}},
+	"DKK": {"", []string{"danish krone"}},
+	"PLN": {"", []string{"zloty", "złoty"}},
+	"CAD": {"", []string{"canadian dollar", "canadian dollars"}},
+	"AUD": {"", []string{"australian dollar", "australian dollars"}},
+}
+
+// CurrencyCode turns a code, symbol or name ("eur", "€", "euros") into an
+// ISO 4217 code, empty if it isn't recognized
+func CurrencyCode(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if _, ok := currencies[strings.ToUpper(s)]; ok {
+		return strings.ToUpper(s)
+	}
+	for code, c := range currencies {
+		for _, name := range c.names {
+			if name == s {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// Set reads a remembered preference into p, e.g. field "units" with value
+// "imperial", "clock_format" with "24h" or "currency" with "euros". It
+// reports whether the fact was one it understood.
+func (p *Prefs) Set(field, value string) bool {
+	words := strings.FieldsFunc(strings.ToLower(field), func(r rune) bool {
+		return r == '_' || r == '-' || r == ' '
+	})
+	has := func(w ...string) bool {
+		for _, word := range words {
+			for _, want := range w {
+				if word == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	switch {
+	case has("units", "unit", "measurement", "measurements"):
+		switch {
+		case strings.Contains(value, "imperial") || strings.Contains(value, "mile"):
+			p.Imperial = true
+		case strings.Contains(value, "metric") || strings.Contains(value, "kilomet"):
+			p.Imperial = false
+		default:
+			return false
+		}
+	case has("clock") || (has("time") && has("format")):
+		switch {
+		case strings.Contains(value, "12") || strings.Contains(value, "am/pm"):
+			p.Clock = 12
+		case strings.Contains(value, "24"):
+			p.Clock = 24
+		default:
+			return false
+		}
+	case has("currency"):
+		code := CurrencyCode(value)
+		if code == "" {
+			return false
+		}
+		p.Currency = code
+	default:
+		return false
+	}
+	return true
+}
+
+// Distance is a length given in metres, e.g. "12.3 km" or "7.6 mi"
+func (p Prefs) Distance(meters float64) string {
+	if p.Imperial {
+		return fmt.Sprintf("%.1f mi", meters/1609.344)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
+
+// Money is an amount in the given currency, e.g. "$0.0123" or "12.50 CHF".
+// Amounts under one keep four decimals so small API costs don't round to
+// zero. Nothing is converted to the preferred currency: there's no exchange
+// rate to do it with.
+func Money(amount float64, currency string) string {
+	decimals := 2
+	if amount != 0 && amount < 1 && amount > -1 {
+		decimals = 4
+	}
+	number := fmt.Sprintf("%.*f", decimals, amount)
+	if c, ok := currencies[currency]; ok && c.symbol != "" {
+		return c.symbol + number
+	}
+	return number + " " + currency
+}
@@ -3,10 +3,14 @@ package logger
 import (
 	"log/slog"
 	"os"
+	"sync/atomic"
 )
 
 var log *slog.Logger
 
+// redactor scrubs secrets from log output, set once secrets are loaded
+var redactor atomic.Pointer[func(string) string]
+
 func init() {
 	level := slog.LevelInfo
 	if os.Getenv("SHELDON_DEBUG") == "true" {
@@ -18,23 +22,54 @@ func init() {
 	log = slog.New(handler)
 }
 
+// SetRedactor applies fn to every logged message and string or error value
+func SetRedactor(fn func(string) string) {
+	redactor.Store(&fn)
+}
+
 func Debug(msg string, args ...any) {
+	msg, args = scrub(msg, args)
 	log.Debug(msg, args...)
 }
 
 func Info(msg string, args ...any) {
+	msg, args = scrub(msg, args)
 	log.Info(msg, args...)
 }
 
 func Warn(msg string, args ...any) {
+	msg, args = scrub(msg, args)
 	log.Warn(msg, args...)
 }
 
 func Error(msg string, args ...any) {
+	msg, args = scrub(msg, args)
 	log.Error(msg, args...)
 }
 
 func Fatal(msg string, args ...any) {
+	msg, args = scrub(msg, args)
 	log.Error(msg, args...)
 	os.Exit(1)
 }
+
+func scrub(msg string, args []any) (string, []any) {
+	fn := redactor.Load()
+	if fn == nil {
+		return msg, args
+	}
+
+	redact := *fn
+	scrubbed := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			scrubbed[i] = redact(v)
+		case error:
+			scrubbed[i] = redact(v.Error())
+		default:
+			scrubbed[i] = arg
+		}
+	}
+	return redact(msg), scrubbed
+}
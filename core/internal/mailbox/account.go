@@ -0,0 +1,146 @@
+package mailbox
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// maxScan bounds how many headers Recent fetches while skipping disallowed senders
+const maxScan = 100
+
+var ErrSenderNotAllowed = errors.New("sender is not on the allowlist")
+
+// Account is a mailbox reached over IMAP and SMTP with the same credentials
+type Account struct {
+	IMAPAddr       string
+	SMTPAddr       string
+	Username       string
+	Password       string
+	From           string
+	AllowedSenders []string // if set, mail from anyone else is never read
+	AuthservID     string   // receiving server whose Authentication-Results are trusted
+	Timeout        time.Duration
+}
+
+// Envelope is the header summary of a message in the inbox
+type Envelope struct {
+	UID      uint32
+	From     string
+	FromName string
+	Subject  string
+	Date     time.Time
+}
+
+// Allowed reports whether a message may be read. With an allowlist the
+// sender also has to be authenticated by AuthservID, since anyone can write
+// any From.
+func (a *Account) Allowed(msg *Message) bool {
+	if len(a.AllowedSenders) == 0 {
+		return true
+	}
+	return msg.AuthenticatedBy(a.AuthservID) && slices.Contains(a.AllowedSenders, msg.From)
+}
+
+// Recent returns up to limit messages from the inbox, newest first, without
+// marking them as read. skipped counts messages hidden by the allowlist.
+func (a *Account) Recent(unreadOnly bool, limit int) (envelopes []Envelope, skipped int, err error) {
+	client, err := a.open()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer client.Close()
+
+	criteria := "SINCE " + time.Now().AddDate(0, 0, -14).Format("02-Jan-2006")
+	if unreadOnly {
+		criteria = "UNSEEN"
+	}
+
+	uids, err := client.Search(criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scanned := 0
+	for i := len(uids) - 1; i >= 0 && len(envelopes) < limit && scanned < maxScan; i-- {
+		scanned++
+
+		raw, err := client.FetchHeader(uids[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		msg, err := ParseHeader(raw)
+		if err != nil {
+			continue
+		}
+		if !a.Allowed(msg) {
+			skipped++
+			continue
+		}
+
+		envelopes = append(envelopes, Envelope{
+			UID:      uids[i],
+			From:     msg.From,
+			FromName: msg.FromName,
+			Subject:  msg.Subject,
+			Date:     msg.Date,
+		})
+	}
+
+	return envelopes, skipped, nil
+}
+
+// Read fetches and parses a message without marking it as read
+func (a *Account) Read(uid uint32) (*Message, error) {
+	client, err := a.open()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	raw, err := client.Fetch(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Allowed(msg) {
+		return nil, ErrSenderNotAllowed
+	}
+	return msg, nil
+}
+
+// Send composes and delivers a message from the account's address
+func (a *Account) Send(out Outgoing) error {
+	if a.SMTPAddr == "" {
+		return fmt.Errorf("sending is disabled: no SMTP address configured")
+	}
+	out.From = a.From
+	msg, _ := Compose(out)
+	return Send(a.SMTPAddr, a.Username, a.Password, a.From, out.To, msg)
+}
+
+func (a *Account) open() (*Client, error) {
+	timeout := a.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	client, err := Dial(a.IMAPAddr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Login(a.Username, a.Password); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("imap login: %w", err)
+	}
+	if err := client.Select("INBOX"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("imap select: %w", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,182 @@
+package mailbox
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is a minimal IMAP4rev1 client covering the handful of commands
+// the email channel and inbox tools need (login, select, search, fetch, store).
+// It avoids pulling in a full IMAP library for what is essentially inbox polling.
+type Client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	tag    int
+}
+
+// imapResponse holds untagged lines and any literals returned by a command
+type imapResponse struct {
+	lines    []string
+	literals [][]byte
+}
+
+// Dial connects to an IMAP server over implicit TLS
+func Dial(addr string, timeout time.Duration) (*Client, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid imap address: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: host})
+	if err != nil {
+		return nil, fmt.Errorf("imap dial: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c := &Client{conn: conn, reader: bufio.NewReader(conn)}
+
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("imap greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected imap greeting: %s", greeting)
+	}
+
+	return c, nil
+}
+
+func (c *Client) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// command sends a tagged command and collects the response until the tagged status line
+func (c *Client) command(format string, args ...any) (*imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	resp := &imapResponse{}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		// literal: line ends with {N}, followed by exactly N bytes
+		if strings.HasSuffix(line, "}") {
+			if open := strings.LastIndex(line, "{"); open >= 0 {
+				if n, err := strconv.Atoi(line[open+1 : len(line)-1]); err == nil {
+					literal := make([]byte, n)
+					if _, err := io.ReadFull(c.reader, literal); err != nil {
+						return nil, err
+					}
+					resp.literals = append(resp.literals, literal)
+				}
+			}
+		}
+
+		if strings.HasPrefix(line, tag+" ") {
+			status := strings.TrimPrefix(line, tag+" ")
+			if !strings.HasPrefix(status, "OK") {
+				return resp, fmt.Errorf("imap: %s", status)
+			}
+			return resp, nil
+		}
+
+		resp.lines = append(resp.lines, line)
+	}
+}
+
+func (c *Client) Login(username, password string) error {
+	_, err := c.command("LOGIN %s %s", Quote(username), Quote(password))
+	return err
+}
+
+func (c *Client) Select(mailbox string) error {
+	_, err := c.command("SELECT %s", Quote(mailbox))
+	return err
+}
+
+// SearchUnseen returns UIDs of unread messages
+func (c *Client) SearchUnseen() ([]uint32, error) {
+	return c.Search("UNSEEN")
+}
+
+// Search returns UIDs matching raw IMAP search criteria, e.g. `UNSEEN FROM "bob@example.com"`
+func (c *Client) Search(criteria string) ([]uint32, error) {
+	resp, err := c.command("UID SEARCH %s", criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []uint32
+	for _, line := range resp.lines {
+		if !strings.HasPrefix(line, "* SEARCH") {
+			continue
+		}
+		for _, field := range strings.Fields(strings.TrimPrefix(line, "* SEARCH")) {
+			if uid, err := strconv.ParseUint(field, 10, 32); err == nil {
+				uids = append(uids, uint32(uid))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// Fetch returns the raw RFC 822 message without marking it as read
+func (c *Client) Fetch(uid uint32) ([]byte, error) {
+	resp, err := c.command("UID FETCH %d BODY.PEEK[]", uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.literals) == 0 {
+		return nil, fmt.Errorf("message %d not found", uid)
+	}
+	return resp.literals[0], nil
+}
+
+// FetchHeader returns just the message header, without marking it as read
+func (c *Client) FetchHeader(uid uint32) ([]byte, error) {
+	resp, err := c.command("UID FETCH %d BODY.PEEK[HEADER]", uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.literals) == 0 {
+		return nil, fmt.Errorf("message %d not found", uid)
+	}
+	return resp.literals[0], nil
+}
+
+func (c *Client) MarkSeen(uid uint32) error {
+	_, err := c.command(`UID STORE %d +FLAGS (\Seen)`, uid)
+	return err
+}
+
+func (c *Client) Close() error {
+	c.command("LOGOUT")
+	return c.conn.Close()
+}
+
+// Quote formats a string as an IMAP quoted string
+func Quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,248 @@
+package mailbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxPartSize caps how much of a single MIME part is read
+const MaxPartSize = 20 * 1024 * 1024
+
+// quoteHeaderPattern matches the "On <date>, <name> wrote:" line mail clients add above quoted replies
+var quoteHeaderPattern = regexp.MustCompile(`(?m)^On .+wrote:\s*$`)
+
+var htmlTagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// commentPattern matches the parenthesized comments in structured headers
+var commentPattern = regexp.MustCompile(`\([^)]*\)`)
+
+// Message is the parsed subset of an email the channel and tools care about
+type Message struct {
+	From        string // lowercased address
+	FromName    string
+	Subject     string
+	Date        time.Time
+	MessageID   string
+	InReplyTo   string
+	References  []string
+	Text        string // plain text body, or the HTML body with tags stripped
+	Attachments []Part
+
+	// authResults are the Authentication-Results headers, topmost first.
+	// From can be forged, so nothing should trust it unless AuthenticatedBy.
+	authResults []string
+}
+
+// Part is a non-text MIME part such as an image or PDF
+type Part struct {
+	MediaType string
+	Filename  string
+	Data      []byte
+}
+
+// ParseHeader parses only the header fields of a message
+func ParseHeader(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	return parseHeader(msg.Header)
+}
+
+// Parse parses a full RFC 822 message, decoding its text body and attachments
+func Parse(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseHeader(msg.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain, html string
+	err = WalkParts(textproto.MIMEHeader(msg.Header), msg.Body, func(mediaType string, header textproto.MIMEHeader, data []byte) {
+		disposition, params, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+		switch {
+		case mediaType == "text/plain" && disposition != "attachment" && plain == "":
+			plain = string(data)
+		case mediaType == "text/html" && disposition != "attachment" && html == "":
+			html = string(data)
+		case !strings.HasPrefix(mediaType, "text/"):
+			result.Attachments = append(result.Attachments, Part{MediaType: mediaType, Filename: params["filename"], Data: data})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if plain == "" && html != "" {
+		plain = StripHTML(html)
+	}
+	result.Text = plain
+
+	return result, nil
+}
+
+func parseHeader(header mail.Header) (*Message, error) {
+	from, err := mail.ParseAddress(header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %w", err)
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(header.Get("Subject"))
+	if err != nil {
+		subject = header.Get("Subject")
+	}
+
+	date, _ := header.Date()
+
+	msg := &Message{
+		From:       strings.ToLower(from.Address),
+		FromName:   from.Name,
+		Subject:    subject,
+		Date:       date,
+		MessageID:  strings.TrimSpace(header.Get("Message-ID")),
+		InReplyTo:  strings.TrimSpace(header.Get("In-Reply-To")),
+		References: strings.Fields(header.Get("References")),
+	}
+	msg.authResults = header["Authentication-Results"]
+	return msg, nil
+}
+
+// AuthenticatedBy reports whether authservID, the receiving server, verified
+// the sender's domain with DKIM or SPF. See SenderAuthenticated.
+func (m *Message) AuthenticatedBy(authservID string) bool {
+	_, domain, _ := strings.Cut(m.From, "@")
+	return SenderAuthenticated(mail.Header{"Authentication-Results": m.authResults}, domain, authservID)
+}
+
+// SenderAuthenticated reports whether the Authentication-Results header shows
+// a DKIM signature from domain or an SPF pass for an envelope sender in it.
+// Only the topmost header counts, and only if it names authservID as the
+// server that checked: a sender can put any header in the message, so one
+// the receiving server didn't add must not be believed. An empty authservID
+// trusts nothing.
+func SenderAuthenticated(header mail.Header, domain, authservID string) bool {
+	results := header["Authentication-Results"]
+	if len(results) == 0 || domain == "" || authservID == "" {
+		return false
+	}
+
+	// drop comments like "(google.com: domain of bob@example.com designates ...)"
+	clean := commentPattern.ReplaceAllString(results[0], " ")
+	// the first entry is the server that checked (an optional version may
+	// follow its name), the rest are results
+	entries := strings.Split(clean, ";")
+	server := strings.Fields(entries[0])
+	if len(server) == 0 || !strings.EqualFold(server[0], authservID) {
+		return false
+	}
+	for _, entry := range entries[1:] {
+		fields := strings.Fields(strings.ToLower(entry))
+		if len(fields) == 0 {
+			continue
+		}
+		var want string
+		switch fields[0] {
+		case "dkim=pass":
+			want = "header.d"
+		case "spf=pass":
+			want = "smtp.mailfrom"
+		default:
+			continue
+		}
+		for _, f := range fields[1:] {
+			key, value, _ := strings.Cut(f, "=")
+			if key != want {
+				continue
+			}
+			if _, d, ok := strings.Cut(value, "@"); ok {
+				value = d
+			}
+			if value == domain {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WalkParts decodes a (possibly multipart) body and calls fn for every leaf part
+func WalkParts(header textproto.MIMEHeader, body io.Reader, fn func(mediaType string, header textproto.MIMEHeader, data []byte)) error {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := WalkParts(part.Header, part, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	// multipart.Reader already decodes quoted-printable; base64 is left to us
+	reader := io.LimitReader(body, MaxPartSize)
+	if strings.EqualFold(header.Get("Content-Transfer-Encoding"), "base64") {
+		reader = base64.NewDecoder(base64.StdEncoding, reader)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	fn(mediaType, header, data)
+	return nil
+}
+
+// StripQuotedReply drops the quoted history mail clients append to replies
+func StripQuotedReply(text string) string {
+	if loc := quoteHeaderPattern.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	if idx := strings.Index(text, "-----Original Message-----"); idx >= 0 {
+		text = text[:idx]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r "))
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func StripHTML(s string) string {
+	s = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>`).ReplaceAllString(s, "\n")
+	return htmlTagPattern.ReplaceAllString(s, "")
+}
@@ -0,0 +1,133 @@
+package mailbox
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+const sampleEmail = "From: Bob Smith <Bob@Example.com>\r\n" +
+	"To: me@example.com\r\n" +
+	"Subject: =?utf-8?q?Caf=C3=A9_plans?=\r\n" +
+	"Date: Fri, 14 Mar 2025 09:12:00 +0000\r\n" +
+	"Message-ID: <m1@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=XYZ\r\n" +
+	"\r\n" +
+	"--XYZ\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Lunch Friday?</p><br>Bob\r\n" +
+	"--XYZ\r\n" +
+	"Content-Type: application/pdf\r\n" +
+	"Content-Disposition: attachment; filename=\"menu.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQ=\r\n" +
+	"--XYZ--\r\n"
+
+func TestParse(t *testing.T) {
+	msg, err := Parse([]byte(sampleEmail))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if msg.From != "bob@example.com" || msg.FromName != "Bob Smith" {
+		t.Errorf("unexpected sender %q %q", msg.FromName, msg.From)
+	}
+	if msg.Subject != "Café plans" {
+		t.Errorf("expected decoded subject, got %q", msg.Subject)
+	}
+	if msg.Date.IsZero() {
+		t.Error("expected date to be parsed")
+	}
+	if !strings.Contains(msg.Text, "Lunch Friday?") || strings.Contains(msg.Text, "<p>") {
+		t.Errorf("expected HTML body stripped to text, got %q", msg.Text)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "menu.pdf" || string(msg.Attachments[0].Data) != "%PDF-1.4" {
+		t.Errorf("unexpected attachments %+v", msg.Attachments)
+	}
+}
+
+func TestStripQuotedReply(t *testing.T) {
+	text := "Sounds good.\n\nOn Fri, 14 Mar 2025, Bob wrote:\n> Lunch Friday?\n"
+	if got := StripQuotedReply(text); got != "Sounds good." {
+		t.Errorf("expected quoted reply removed, got %q", got)
+	}
+}
+
+func TestComposeReply(t *testing.T) {
+	raw, messageID := Compose(Outgoing{
+		From:       "Me <me@example.com>",
+		To:         "bob@example.com",
+		Subject:    "Re: Café plans",
+		InReplyTo:  "<m1@example.com>",
+		References: []string{"<m1@example.com>"},
+		Body:       "Yes!\nSee you there.",
+	})
+
+	if !strings.HasSuffix(messageID, "@example.com>") {
+		t.Errorf("expected message ID on sender's domain, got %s", messageID)
+	}
+
+	msg, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("parse composed message: %v", err)
+	}
+	if msg.Subject != "Re: Café plans" || msg.InReplyTo != "<m1@example.com>" || msg.MessageID != messageID {
+		t.Errorf("unexpected headers %+v", msg)
+	}
+	if msg.Text != "Yes!\r\nSee you there." {
+		t.Errorf("unexpected body %q", msg.Text)
+	}
+}
+
+func TestSenderAuthenticated(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []string
+		want    bool
+	}{
+		{"dkim pass", []string{"mx.example.net; dkim=pass header.d=example.com header.s=s1; spf=fail"}, true},
+		{"spf pass", []string{"mx.example.net; spf=pass (sender permitted) smtp.mailfrom=bob@example.com"}, true},
+		{"other domain", []string{"mx.example.net; dkim=pass header.d=attacker.net; spf=pass smtp.mailfrom=attacker.net"}, false},
+		{"failed", []string{"mx.example.net; dkim=fail header.d=example.com; spf=softfail smtp.mailfrom=example.com"}, false},
+		{"forged below", []string{"mx.example.net; dkim=none", "evil.net; dkim=pass header.d=example.com"}, false},
+		{"forged on top", []string{"evil.net; dkim=pass header.d=example.com"}, false},
+		{"forged with no server header", []string{"x; dkim=pass header.d=example.com; spf=pass smtp.mailfrom=example.com"}, false},
+		{"server with version", []string{"MX.example.net 1; dkim=pass header.d=example.com"}, true},
+		{"missing", nil, false},
+	}
+	for _, tt := range tests {
+		header := mail.Header{}
+		if tt.results != nil {
+			header["Authentication-Results"] = tt.results
+		}
+		if got := SenderAuthenticated(header, "example.com", "mx.example.net"); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	header := mail.Header{"Authentication-Results": {"mx.example.net; dkim=pass header.d=example.com"}}
+	if SenderAuthenticated(header, "example.com", "") {
+		t.Error("expected nothing trusted without a configured authserv-id")
+	}
+}
+
+func TestMessageAuthenticatedBy(t *testing.T) {
+	raw := "Authentication-Results: x; dkim=pass header.d=example.com\r\n" +
+		"From: Bob <bob@example.com>\r\n" +
+		"Subject: approve 1234\r\n\r\n"
+	msg, err := ParseHeader([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.AuthenticatedBy("mx.example.net") {
+		t.Error("a header the sender wrote was trusted")
+	}
+
+	account := &Account{AllowedSenders: []string{"bob@example.com"}, AuthservID: "mx.example.net"}
+	if account.Allowed(msg) {
+		t.Error("forged sender passed the allowlist")
+	}
+}
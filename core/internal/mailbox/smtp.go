@@ -0,0 +1,144 @@
+package mailbox
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outgoing is a plain text email, optionally with one attachment
+type Outgoing struct {
+	From       string
+	To         string
+	Subject    string
+	InReplyTo  string
+	References []string
+	Body       string
+	Attachment *Part
+}
+
+// Compose renders an outgoing message and returns it with its new Message-ID
+func Compose(out Outgoing) ([]byte, string) {
+	domain := "sheldon.local"
+	if at := strings.LastIndex(Address(out.From), "@"); at >= 0 {
+		domain = Address(out.From)[at+1:]
+	}
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New().String(), domain)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", out.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", out.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", out.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", messageID)
+	if out.InReplyTo != "" {
+		fmt.Fprintf(&buf, "In-Reply-To: %s\r\n", out.InReplyTo)
+		fmt.Fprintf(&buf, "References: %s\r\n", strings.Join(out.References, " "))
+	}
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	body := strings.ReplaceAll(out.Body, "\n", "\r\n")
+	if out.Attachment == nil {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+		buf.WriteString(body)
+		return buf.Bytes(), messageID
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	textPart, _ := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	textPart.Write([]byte(body))
+
+	mediaType := out.Attachment.MediaType
+	if mediaType == "" {
+		mediaType = http.DetectContentType(out.Attachment.Data)
+	}
+	filePart, _ := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mediaType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": out.Attachment.Filename})},
+	})
+	encoded := base64.StdEncoding.EncodeToString(out.Attachment.Data)
+	for len(encoded) > 76 {
+		filePart.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	filePart.Write([]byte(encoded))
+	mw.Close()
+
+	return buf.Bytes(), messageID
+}
+
+// Address returns the bare address from a From header value
+func Address(from string) string {
+	if addr, err := mail.ParseAddress(from); err == nil {
+		return addr.Address
+	}
+	return from
+}
+
+// Send delivers via implicit TLS on port 465, otherwise STARTTLS through smtp.SendMail
+func Send(addr, username, password, from, to string, msg []byte) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid smtp address: %w", err)
+	}
+
+	auth := smtp.PlainAuth("", username, password, host)
+
+	if port != "465" {
+		return smtp.SendMail(addr, auth, Address(from), []string{to}, msg)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: host})
+	if err != nil {
+		return fmt.Errorf("smtp dial: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Auth(auth); err != nil {
+		return err
+	}
+	if err := client.Mail(Address(from)); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
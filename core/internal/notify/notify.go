@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const sendTimeout = 10 * time.Second
+
+// New builds a notifier for the configured services. chat delivers to the
+// bots; kinds without a route go to chat.
+func New(cfg config.NotifyConfig, chat Sink) *Notifier {
+	client := &http.Client{Timeout: sendTimeout}
+
+	n := &Notifier{
+		sinks:  map[string]Sink{"chat": chat},
+		routes: make(map[Kind][]string),
+	}
+	if cfg.Configured("ntfy") {
+		n.sinks["ntfy"] = &ntfy{url: cfg.NtfyURL, token: cfg.NtfyToken, client: client}
+	}
+	if cfg.Configured("gotify") {
+		n.sinks["gotify"] = &gotify{url: cfg.GotifyURL, token: cfg.GotifyToken, client: client}
+	}
+	if cfg.Configured("pushover") {
+		n.sinks["pushover"] = &pushover{token: cfg.PushoverToken, user: cfg.PushoverUser, url: pushoverURL, client: client}
+	}
+	if cfg.Configured("webhook") {
+		n.sinks["webhook"] = &webhook{url: cfg.WebhookURL, client: client}
+	}
+
+	for kind, sinks := range cfg.Routes {
+		n.routes[Kind(kind)] = sinks
+	}
+	return n
+}
+
+// Send delivers m to every sink routed for its kind. A failing sink doesn't
+// stop the others; the errors are logged and returned together.
+func (n *Notifier) Send(ctx context.Context, m Message) error {
+	var errs []error
+	for _, name := range n.route(m.Kind) {
+		sink, ok := n.sinks[name]
+		if !ok || sink == nil {
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := sink.Send(sendCtx, m)
+		cancel()
+		if err != nil {
+			logger.Warn("notification failed", "sink", name, "kind", m.Kind, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// External reports whether a kind goes anywhere besides the chat bots, so it
+// can be sent even without an alert chat
+func (n *Notifier) External(kind Kind) bool {
+	for _, name := range n.route(kind) {
+		if _, ok := n.sinks[name]; ok && name != "chat" {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notifier) route(kind Kind) []string {
+	if sinks, ok := n.routes[kind]; ok {
+		return sinks
+	}
+	return []string{"chat"}
+}
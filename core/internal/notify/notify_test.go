@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bowerhall/sheldon/internal/config"
+)
+
+type request struct {
+	path   string
+	header http.Header
+	body   string
+}
+
+func recorder(t *testing.T) (*httptest.Server, chan request) {
+	t.Helper()
+	got := make(chan request, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- request{path: r.URL.Path, header: r.Header, body: string(body)}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func TestNotifierRoutes(t *testing.T) {
+	srv, got := recorder(t)
+
+	var chatted []Message
+	chat := SinkFunc(func(ctx context.Context, m Message) error {
+		chatted = append(chatted, m)
+		return nil
+	})
+
+	n := New(config.NotifyConfig{
+		NtfyURL: srv.URL + "/sheldon",
+		Routes: map[string][]string{
+			"budget": {"ntfy"},
+			"alerts": {"chat", "ntfy"},
+		},
+	}, chat)
+
+	if err := n.Send(context.Background(), Message{Kind: KindBudget, Title: "Budget warning", Text: "90% used", Priority: PriorityHigh}); err != nil {
+		t.Fatal(err)
+	}
+	r := <-got
+	if r.path != "/sheldon" || r.body != "90% used" || r.header.Get("Title") != "Budget warning" || r.header.Get("Priority") != "high" {
+		t.Errorf("ntfy request = %+v", r)
+	}
+	if len(chatted) != 0 {
+		t.Errorf("budget went to chat: %v", chatted)
+	}
+
+	// cron has no route and falls back to chat
+	n.Send(context.Background(), Message{Kind: KindCron, ChatID: 42, Text: "stretch"})
+	if len(chatted) != 1 || chatted[0].ChatID != 42 {
+		t.Errorf("chatted = %v", chatted)
+	}
+
+	if !n.External(KindAlerts) || n.External(KindCron) {
+		t.Error("External reports the wrong kinds")
+	}
+}
+
+func TestSinks(t *testing.T) {
+	srv, got := recorder(t)
+	m := Message{Kind: KindAlerts, Title: "Sheldon", Text: "disk full", Priority: PriorityHigh}
+
+	t.Run("gotify", func(t *testing.T) {
+		s := &gotify{url: srv.URL + "/", token: "app", client: srv.Client()}
+		if err := s.Send(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		r := <-got
+		var body map[string]any
+		json.Unmarshal([]byte(r.body), &body)
+		if r.path != "/message" || r.header.Get("X-Gotify-Key") != "app" || body["message"] != "disk full" || body["priority"] != float64(8) {
+			t.Errorf("request = %+v", r)
+		}
+	})
+
+	t.Run("pushover", func(t *testing.T) {
+		s := &pushover{token: "app", user: "me", url: srv.URL + "/1/messages.json", client: srv.Client()}
+		if err := s.Send(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		r := <-got
+		if r.body != "message=disk+full&priority=1&title=Sheldon&token=app&user=me" {
+			t.Errorf("body = %q", r.body)
+		}
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		s := &webhook{url: srv.URL + "/hook", client: srv.Client()}
+		if err := s.Send(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		r := <-got
+		var body map[string]any
+		json.Unmarshal([]byte(r.body), &body)
+		if body["kind"] != "alerts" || body["message"] != "disk full" || body["priority"] != "high" {
+			t.Errorf("body = %s", r.body)
+		}
+	})
+}
+
+func TestSinkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	n := New(config.NotifyConfig{WebhookURL: srv.URL, Routes: map[string][]string{"cron": {"webhook"}}}, nil)
+	if err := n.Send(context.Background(), Message{Kind: KindCron, Text: "hi"}); err == nil {
+		t.Error("expected an error from a rejected webhook")
+	}
+}
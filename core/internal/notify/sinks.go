@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pushoverURL = "https://api.pushover.net/1/messages.json"
+
+// ntfy publishes to a topic URL, https://docs.ntfy.sh/publish/
+func (s *ntfy) Send(ctx context.Context, m Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(m.Text))
+	if err != nil {
+		return err
+	}
+	if m.Title != "" {
+		req.Header.Set("Title", m.Title)
+	}
+	req.Header.Set("Tags", string(m.Kind))
+	if m.Priority == PriorityHigh {
+		req.Header.Set("Priority", "high")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return do(s.client, req)
+}
+
+// gotify posts to an application's message endpoint, https://gotify.net/docs/pushmsg
+func (s *gotify) Send(ctx context.Context, m Message) error {
+	priority := 5
+	if m.Priority == PriorityHigh {
+		priority = 8
+	}
+	body, err := json.Marshal(map[string]any{
+		"title":    m.Title,
+		"message":  m.Text,
+		"priority": priority,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.url, "/")+"/message", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", s.token)
+	return do(s.client, req)
+}
+
+// pushover sends through the messages API, https://pushover.net/api
+func (s *pushover) Send(ctx context.Context, m Message) error {
+	priority := 0
+	if m.Priority == PriorityHigh {
+		priority = 1
+	}
+	form := url.Values{
+		"token":    {s.token},
+		"user":     {s.user},
+		"message":  {m.Text},
+		"priority": {strconv.Itoa(priority)},
+	}
+	if m.Title != "" {
+		form.Set("title", m.Title)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(s.client, req)
+}
+
+// webhook posts the notification as JSON for anything else to pick up
+func (s *webhook) Send(ctx context.Context, m Message) error {
+	priority := "normal"
+	if m.Priority == PriorityHigh {
+		priority = "high"
+	}
+	body, err := json.Marshal(map[string]any{
+		"kind":     m.Kind,
+		"title":    m.Title,
+		"message":  m.Text,
+		"priority": priority,
+		"time":     time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(s.client, req)
+}
+
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// Kind is what a notification is about; each kind is routed on its own
+type Kind string
+
+const (
+	KindBudget Kind = "budget"
+	KindCron   Kind = "cron"
+	KindAlerts Kind = "alerts"
+	KindBackup Kind = "backup"
+)
+
+// Priority maps onto the urgency levels of the push services
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+)
+
+// Message is one notification
+type Message struct {
+	Kind     Kind
+	ChatID   int64 // chat for the chat sink, 0 sends to the alert chat
+	Title    string
+	Text     string
+	Priority Priority
+}
+
+// Sink delivers notifications somewhere
+type Sink interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// SinkFunc adapts a function to a Sink, e.g. sending through a chat bot
+type SinkFunc func(ctx context.Context, m Message) error
+
+func (f SinkFunc) Send(ctx context.Context, m Message) error {
+	return f(ctx, m)
+}
+
+// Notifier sends each message to the sinks its kind is routed to
+type Notifier struct {
+	sinks  map[string]Sink
+	routes map[Kind][]string
+}
+
+type ntfy struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type gotify struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type pushover struct {
+	token  string
+	user   string
+	url    string
+	client *http.Client
+}
+
+type webhook struct {
+	url    string
+	client *http.Client
+}
@@ -0,0 +1,104 @@
+package persona
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Load reads every persona in essencePath/personas. A missing directory
+// means no personas; a broken file is an error so typos don't go unnoticed.
+func Load(essencePath string) (map[string]*Persona, error) {
+	dir := filepath.Join(essencePath, Dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*Persona{}, nil
+		}
+		return nil, err
+	}
+
+	personas := make(map[string]*Persona)
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+		p, err := Parse(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		personas[name] = p
+	}
+
+	return personas, nil
+}
+
+// Parse reads a persona file: an optional YAML header between --- lines,
+// then the prompt that replaces SOUL.md
+func Parse(name string, data []byte) (*Persona, error) {
+	if !validName.MatchString(name) || name == Default {
+		return nil, fmt.Errorf("invalid persona name %q", name)
+	}
+
+	var meta frontmatter
+	body := data
+	if rest, ok := bytes.CutPrefix(data, []byte("---\n")); ok {
+		header, after, found := bytes.Cut(rest, []byte("\n---"))
+		if !found {
+			return nil, fmt.Errorf("unterminated frontmatter")
+		}
+		if err := yaml.Unmarshal(header, &meta); err != nil {
+			return nil, fmt.Errorf("frontmatter: %w", err)
+		}
+		body = after
+	}
+
+	soul := strings.TrimSpace(string(body))
+	if soul == "" {
+		return nil, fmt.Errorf("persona has no prompt")
+	}
+	if (meta.Provider == "") != (meta.Model == "") {
+		return nil, fmt.Errorf("set both provider and model, or neither")
+	}
+
+	return &Persona{
+		Name:        name,
+		Description: meta.Description,
+		Provider:    meta.Provider,
+		Model:       meta.Model,
+		Tone:        meta.Tone,
+		Soul:        soul,
+	}, nil
+}
+
+// Prompt is the persona's replacement for SOUL.md
+func (p *Persona) Prompt() string {
+	if len(p.Tone) == 0 {
+		return p.Soul
+	}
+	return p.Soul + "\n\n## Tone\n- " + strings.Join(p.Tone, "\n- ")
+}
+
+// Names returns persona names in order
+func Names(personas map[string]*Persona) []string {
+	names := make([]string, 0, len(personas))
+	for name := range personas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,83 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse("work", []byte(`---
+description: Focused work assistant
+provider: claude
+model: claude-sonnet-4-20250514
+tone:
+  - concise
+  - no emoji
+---
+You are Sheldon at work.
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Description != "Focused work assistant" || p.Provider != "claude" || p.Model != "claude-sonnet-4-20250514" {
+		t.Errorf("unexpected persona: %+v", p)
+	}
+	if p.Soul != "You are Sheldon at work." {
+		t.Errorf("Soul = %q", p.Soul)
+	}
+	if !strings.Contains(p.Prompt(), "## Tone\n- concise\n- no emoji") {
+		t.Errorf("Prompt missing tone: %q", p.Prompt())
+	}
+}
+
+func TestParseWithoutFrontmatter(t *testing.T) {
+	p, err := Parse("casual", []byte("Be relaxed.\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Prompt() != "Be relaxed." || p.Model != "" {
+		t.Errorf("unexpected persona: %+v", p)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]struct {
+		name string
+		data string
+	}{
+		"reserved name":   {Default, "hi"},
+		"invalid name":    {"Work Mode", "hi"},
+		"empty prompt":    {"work", "---\ndescription: x\n---\n"},
+		"model only":      {"work", "---\nmodel: gpt-4o\n---\nhi"},
+		"unterminated":    {"work", "---\ndescription: x\nhi"},
+		"bad frontmatter": {"work", "---\ntone: [\n---\nhi"},
+	}
+	for desc, tt := range tests {
+		if _, err := Parse(tt.name, []byte(tt.data)); err == nil {
+			t.Errorf("%s: expected error", desc)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	essence := t.TempDir()
+	if personas, err := Load(essence); err != nil || len(personas) != 0 {
+		t.Fatalf("missing dir: %v, %v", personas, err)
+	}
+
+	dir := filepath.Join(essence, Dir)
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "Casual.md"), []byte("Be relaxed."), 0644)
+	os.WriteFile(filepath.Join(dir, "work.md"), []byte("Be focused."), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	personas, err := Load(essence)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := strings.Join(Names(personas), ","); got != "casual,work" {
+		t.Errorf("Names = %s", got)
+	}
+}
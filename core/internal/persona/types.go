@@ -0,0 +1,26 @@
+package persona
+
+// Dir holds one <name>.md per persona inside the essence directory
+const Dir = "personas"
+
+// Default is the persona built from SOUL.md, selecting it clears the session's persona
+const Default = "default"
+
+// Persona is a named alternative to SOUL.md with its own model and tone,
+// so one deployment can be a work assistant in one chat and casual in another
+type Persona struct {
+	Name        string
+	Description string
+	Provider    string // model default for sessions using the persona, optional
+	Model       string
+	Tone        []string // short tone facts, e.g. "formal", "no emoji"
+	Soul        string   // replaces SOUL.md
+}
+
+// frontmatter is the optional YAML header of a persona file
+type frontmatter struct {
+	Description string   `yaml:"description"`
+	Provider    string   `yaml:"provider"`
+	Model       string   `yaml:"model"`
+	Tone        []string `yaml:"tone"`
+}
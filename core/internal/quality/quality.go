@@ -0,0 +1,169 @@
+// Package quality has a second, cheaper model score the agent's replies for
+// instruction-following and hallucination risk, so answer quality can be
+// compared across models over time
+package quality
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+const (
+	// these bound what the evaluator is shown; it only needs enough to judge
+	// the reply, not the whole transcript
+	maxRequestChars  = 2000
+	maxResponseChars = 4000
+	maxToolChars     = 1500
+	maxTools         = 8
+)
+
+const evalPrompt = `You review an AI assistant's reply to a user. Judge only what is shown.
+
+Score two things from 1 to 5:
+- instruction_following: 5 = did exactly what was asked, in the form asked; 3 = partly, or added things nobody asked for; 1 = ignored or misread the request.
+- hallucination_risk: 1 = every specific claim (names, numbers, dates, file contents, command output) is backed by the tool results or is common knowledge; 3 = some specifics are unsupported; 5 = states things as fact that the tool results contradict or that it had no way of knowing. Claiming to have done something no tool call did counts as a hallucination.
+
+Reply with only a JSON object:
+{"instruction_following": 1-5, "hallucination_risk": 1-5, "notes": "one sentence on the main problem, or empty"}`
+
+// Turn is one user message and the agent's reply, with the tool results the
+// reply could draw on
+type Turn struct {
+	SessionID string
+	Provider  string
+	Model     string
+	Request   string
+	Response  string
+	Tools     []ToolResult
+}
+
+// ToolResult is one tool call made while answering
+type ToolResult struct {
+	Name   string
+	Output string
+}
+
+// Score is the evaluator's verdict on a turn
+type Score struct {
+	ID                   int64
+	Time                 time.Time
+	SessionID            string
+	Provider             string
+	Model                string // model that wrote the reply
+	Evaluator            string // model that scored it
+	InstructionFollowing int    // 1-5, higher is better
+	HallucinationRisk    int    // 1-5, higher is worse
+	Notes                string
+}
+
+// Flagged reports whether a score is bad enough to show when auditing
+func (s Score) Flagged() bool {
+	return s.InstructionFollowing <= 2 || s.HallucinationRisk >= 4
+}
+
+// Evaluator scores turns with its model and records the scores
+type Evaluator struct {
+	model llm.LLM
+	store *Store
+}
+
+// NewEvaluator creates an evaluator. Pick a small, cheap model; it reads
+// every reply it scores.
+func NewEvaluator(model llm.LLM, store *Store) *Evaluator {
+	return &Evaluator{model: model, store: store}
+}
+
+// Model is the evaluator's provider/model
+func (e *Evaluator) Model() string {
+	return e.model.Provider() + "/" + e.model.Model()
+}
+
+// Evaluate scores a turn and records the score
+func (e *Evaluator) Evaluate(ctx context.Context, turn Turn) (*Score, error) {
+	reply, err := e.model.Chat(ctx, evalPrompt, []llm.Message{{Role: "user", Content: transcript(turn)}})
+	if err != nil {
+		return nil, fmt.Errorf("evaluate: %w", err)
+	}
+
+	score, err := parseScore(reply)
+	if err != nil {
+		return nil, err
+	}
+	score.SessionID = turn.SessionID
+	score.Provider = turn.Provider
+	score.Model = turn.Model
+	score.Evaluator = e.Model()
+
+	if err := e.store.Record(score); err != nil {
+		return nil, fmt.Errorf("record score: %w", err)
+	}
+	return score, nil
+}
+
+// transcript lays out a turn for the evaluator
+func transcript(turn Turn) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "USER:\n%s\n\n", clip(turn.Request, maxRequestChars))
+
+	if len(turn.Tools) == 0 {
+		sb.WriteString("TOOL RESULTS: none, the assistant called no tools\n\n")
+	} else {
+		sb.WriteString("TOOL RESULTS:\n")
+		tools := turn.Tools
+		if len(tools) > maxTools {
+			fmt.Fprintf(&sb, "(%d earlier calls omitted)\n", len(tools)-maxTools)
+			tools = tools[len(tools)-maxTools:]
+		}
+		for _, t := range tools {
+			fmt.Fprintf(&sb, "[%s]\n%s\n", t.Name, clip(t.Output, maxToolChars))
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "ASSISTANT REPLY:\n%s", clip(turn.Response, maxResponseChars))
+	return sb.String()
+}
+
+func clip(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n]) + " [...]"
+	}
+	return s
+}
+
+// parseScore reads the evaluator's JSON, tolerating prose or code fences
+// around it
+func parseScore(reply string) (*Score, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("evaluator reply has no JSON: %q", clip(reply, 200))
+	}
+
+	var result struct {
+		InstructionFollowing int    `json:"instruction_following"`
+		HallucinationRisk    int    `json:"hallucination_risk"`
+		Notes                string `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("parse evaluator reply: %w", err)
+	}
+	if !inRange(result.InstructionFollowing) || !inRange(result.HallucinationRisk) {
+		return nil, fmt.Errorf("evaluator scores out of range: %d, %d", result.InstructionFollowing, result.HallucinationRisk)
+	}
+
+	return &Score{
+		InstructionFollowing: result.InstructionFollowing,
+		HallucinationRisk:    result.HallucinationRisk,
+		Notes:                strings.TrimSpace(result.Notes),
+	}, nil
+}
+
+func inRange(n int) bool {
+	return n >= 1 && n <= 5
+}
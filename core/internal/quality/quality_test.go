@@ -0,0 +1,108 @@
+package quality
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+func TestParseScore(t *testing.T) {
+	reply := "Here you go:\n```json\n{\"instruction_following\": 4, \"hallucination_risk\": 2, \"notes\": \" fine \"}\n```"
+	score, err := parseScore(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score.InstructionFollowing != 4 || score.HallucinationRisk != 2 || score.Notes != "fine" {
+		t.Errorf("got %+v", score)
+	}
+
+	for _, bad := range []string{
+		"no json here",
+		`{"instruction_following": 0, "hallucination_risk": 2}`,
+		`{"instruction_following": 3, "hallucination_risk": 9}`,
+		`{"instruction_following": "high"}`,
+	} {
+		if _, err := parseScore(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestTranscriptKeepsLatestTools(t *testing.T) {
+	turn := Turn{Request: "what's on my calendar?", Response: strings.Repeat("x", maxResponseChars+10)}
+	for i := 0; i < maxTools+2; i++ {
+		turn.Tools = append(turn.Tools, ToolResult{Name: "tool" + string(rune('a'+i)), Output: "ok"})
+	}
+
+	got := transcript(turn)
+	if !strings.Contains(got, "(2 earlier calls omitted)") {
+		t.Error("expected omitted calls to be counted")
+	}
+	if strings.Contains(got, "[toola]") || !strings.Contains(got, "[toolj]") {
+		t.Error("expected the earliest calls dropped and the latest kept")
+	}
+	if !strings.HasSuffix(got, " [...]") {
+		t.Error("expected a long reply to be clipped")
+	}
+}
+
+func TestStoreByModelAndFlagged(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := NewStore(db, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	scores := []*Score{
+		{Time: now, SessionID: "telegram:1", Provider: "claude", Model: "big", Evaluator: "ollama/small", InstructionFollowing: 5, HallucinationRisk: 1},
+		{Time: now, SessionID: "telegram:1", Provider: "claude", Model: "big", Evaluator: "ollama/small", InstructionFollowing: 3, HallucinationRisk: 1},
+		{Time: now, SessionID: "telegram:1", Provider: "ollama", Model: "tiny", Evaluator: "ollama/small", InstructionFollowing: 4, HallucinationRisk: 5, Notes: "made up a flight number"},
+		{Time: now.Add(-48 * time.Hour), SessionID: "telegram:1", Provider: "ollama", Model: "tiny", Evaluator: "ollama/small", InstructionFollowing: 1, HallucinationRisk: 1},
+	}
+	for _, s := range scores {
+		if err := store.Record(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from, to := now.Add(-time.Hour), now.Add(time.Hour)
+	models, err := store.ByModel(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(models))
+	}
+	if models[0].Model != "big" || models[0].Turns != 2 || models[0].InstructionFollowing != 4 || models[0].Flagged != 0 {
+		t.Errorf("unexpected summary for big: %+v", models[0])
+	}
+	if models[1].Model != "tiny" || models[1].Flagged != 1 {
+		t.Errorf("unexpected summary for tiny: %+v", models[1])
+	}
+
+	flagged, err := store.Flagged(now.Add(-72*time.Hour), to, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flagged) != 2 || flagged[0].Notes != "made up a flight number" {
+		t.Errorf("expected 2 flagged turns, newest first, got %+v", flagged)
+	}
+
+	days, err := store.ByDay(now.Add(-72*time.Hour), to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 3 {
+		t.Errorf("expected 3 day/model rows, got %d", len(days))
+	}
+}
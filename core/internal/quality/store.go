@@ -0,0 +1,180 @@
+package quality
+
+import (
+	"database/sql"
+	"time"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS response_scores (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp DATETIME NOT NULL,
+	session_id TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	model TEXT NOT NULL,
+	evaluator TEXT NOT NULL,
+	instruction_following INTEGER NOT NULL,
+	hallucination_risk INTEGER NOT NULL,
+	notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_scores_timestamp ON response_scores(timestamp);
+CREATE INDEX IF NOT EXISTS idx_response_scores_model ON response_scores(model);
+`
+
+// flaggedCondition matches the scores Score.Flagged reports
+const flaggedCondition = `(instruction_following <= 2 OR hallucination_risk >= 4)`
+
+type Store struct {
+	db       *sql.DB
+	timezone *time.Location
+}
+
+// NewStore creates a score store using the provided database connection
+func NewStore(db *sql.DB, timezone *time.Location) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+
+	tz := timezone
+	if tz == nil {
+		tz = time.UTC
+	}
+
+	return &Store{db: db, timezone: tz}, nil
+}
+
+// Record stores a score, stamping it with the current time if it has none
+func (s *Store) Record(score *Score) error {
+	if score.Time.IsZero() {
+		score.Time = time.Now()
+	}
+
+	result, err := s.db.Exec(
+		`INSERT INTO response_scores (timestamp, session_id, provider, model, evaluator, instruction_following, hallucination_risk, notes) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		score.Time.In(s.timezone),
+		score.SessionID,
+		score.Provider,
+		score.Model,
+		score.Evaluator,
+		score.InstructionFollowing,
+		score.HallucinationRisk,
+		score.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	score.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// ModelSummary is how one model's replies scored over a period
+type ModelSummary struct {
+	Provider             string
+	Model                string
+	Turns                int
+	InstructionFollowing float64 // average
+	HallucinationRisk    float64 // average
+	Flagged              int
+}
+
+// ByModel averages scores per model, most scored first
+func (s *Store) ByModel(from, to time.Time) ([]ModelSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			provider,
+			model,
+			COUNT(*),
+			AVG(instruction_following),
+			AVG(hallucination_risk),
+			SUM(CASE WHEN `+flaggedCondition+` THEN 1 ELSE 0 END)
+		FROM response_scores
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY provider, model
+		ORDER BY COUNT(*) DESC
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []ModelSummary
+	for rows.Next() {
+		var m ModelSummary
+		if err := rows.Scan(&m.Provider, &m.Model, &m.Turns, &m.InstructionFollowing, &m.HallucinationRisk, &m.Flagged); err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+
+	return result, rows.Err()
+}
+
+// DailySummary is one model's average scores on one day
+type DailySummary struct {
+	Date                 string
+	Provider             string
+	Model                string
+	Turns                int
+	InstructionFollowing float64
+	HallucinationRisk    float64
+}
+
+// ByDay averages scores per model per day, newest first, to show trends
+func (s *Store) ByDay(from, to time.Time) ([]DailySummary, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			DATE(timestamp),
+			provider,
+			model,
+			COUNT(*),
+			AVG(instruction_following),
+			AVG(hallucination_risk)
+		FROM response_scores
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY DATE(timestamp), provider, model
+		ORDER BY DATE(timestamp) DESC, COUNT(*) DESC
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []DailySummary
+	for rows.Next() {
+		var d DailySummary
+		if err := rows.Scan(&d.Date, &d.Provider, &d.Model, &d.Turns, &d.InstructionFollowing, &d.HallucinationRisk); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+
+	return result, rows.Err()
+}
+
+// Flagged returns the most recent poorly scored turns
+func (s *Store) Flagged(from, to time.Time, limit int) ([]Score, error) {
+	rows, err := s.db.Query(`
+		SELECT id, timestamp, session_id, provider, model, evaluator, instruction_following, hallucination_risk, notes
+		FROM response_scores
+		WHERE timestamp >= ? AND timestamp < ? AND `+flaggedCondition+`
+		ORDER BY timestamp DESC
+		LIMIT ?
+	`, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []Score
+	for rows.Next() {
+		var sc Score
+		if err := rows.Scan(&sc.ID, &sc.Time, &sc.SessionID, &sc.Provider, &sc.Model, &sc.Evaluator, &sc.InstructionFollowing, &sc.HallucinationRisk, &sc.Notes); err != nil {
+			return nil, err
+		}
+		result = append(result, sc)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,209 @@
+// Package secrets stores environment secrets for deployed apps, encrypted at
+// rest, and scrubs their values from text that leaves the process
+package secrets
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/encryption"
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS app_secrets (
+    app TEXT NOT NULL,
+    name TEXT NOT NULL,
+    value BLOB NOT NULL,
+    updated_at DATETIME DEFAULT (datetime('now')),
+    PRIMARY KEY (app, name)
+);
+`
+
+// minRedactLength skips values so short that redacting them would mangle ordinary text
+const minRedactLength = 4
+
+var (
+	validApp  = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+)
+
+// NewStore creates the secrets table and loads existing secrets with the
+// given key. Secrets only legacy opens, sealed before the database had its
+// own salt, are re-sealed with key; legacy may be nil.
+func NewStore(db *sql.DB, key, legacy *encryption.Key) (*Store, error) {
+	if key == nil {
+		return nil, fmt.Errorf("app secrets need an encryption key")
+	}
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+
+	s := &Store{db: db, key: key, values: make(map[string]map[string]string)}
+	if err := s.load(legacy); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load(legacy *encryption.Key) error {
+	rows, err := s.db.Query(`SELECT app, name, value FROM app_secrets`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type stale struct{ app, name, value string }
+	var reseal []stale
+	for rows.Next() {
+		var app, name string
+		var sealed []byte
+		if err := rows.Scan(&app, &name, &sealed); err != nil {
+			return err
+		}
+		plain, err := s.key.Open(sealed)
+		if err != nil && legacy != nil {
+			if plain, err = legacy.Open(sealed); err == nil {
+				reseal = append(reseal, stale{app, name, string(plain)})
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("decrypt %s/%s: %w", app, name, err)
+		}
+		s.cache(app, name, string(plain))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, r := range reseal {
+		sealed, err := s.key.Seal([]byte(r.value))
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(`UPDATE app_secrets SET value = ? WHERE app = ? AND name = ?`, sealed, r.app, r.name); err != nil {
+			return fmt.Errorf("re-seal %s/%s: %w", r.app, r.name, err)
+		}
+	}
+	return nil
+}
+
+// Set creates or replaces a secret for an app
+func (s *Store) Set(app, name, value string) error {
+	if !validApp.MatchString(app) {
+		return fmt.Errorf("invalid app name %q", app)
+	}
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits and underscores, e.g. API_KEY", name)
+	}
+	if value == "" {
+		return fmt.Errorf("secret value is empty")
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("secret values must be a single line")
+	}
+
+	sealed, err := s.key.Seal([]byte(value))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO app_secrets (app, name, value) VALUES (?, ?, ?)
+		ON CONFLICT(app, name) DO UPDATE SET value = excluded.value, updated_at = datetime('now')`,
+		app, name, sealed)
+	if err != nil {
+		return err
+	}
+
+	s.cache(app, name, value)
+	return nil
+}
+
+// Delete removes one secret from an app
+func (s *Store) Delete(app, name string) error {
+	result, err := s.db.Exec(`DELETE FROM app_secrets WHERE app = ? AND name = ?`, app, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	s.mu.Lock()
+	delete(s.values[app], name)
+	if len(s.values[app]) == 0 {
+		delete(s.values, app)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Names lists an app's secret names, sorted; values are never listed
+func (s *Store) Names(app string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.values[app]))
+	for name := range s.values[app] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Env returns an app's secrets as environment variables
+func (s *Store) Env(app string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	env := make(map[string]string, len(s.values[app]))
+	for name, value := range s.values[app] {
+		env[name] = value
+	}
+	return env, nil
+}
+
+// Redact replaces every known secret value in text with its name. Longer
+// values go first, so a secret that contains another is never left half shown.
+func (s *Store) Redact(text string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type found struct{ value, label string }
+	var matches []found
+	for app, secrets := range s.values {
+		for name, value := range secrets {
+			if len(value) < minRedactLength || !strings.Contains(text, value) {
+				continue
+			}
+			matches = append(matches, found{value, fmt.Sprintf("[secret %s/%s]", app, name)})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if len(matches[i].value) != len(matches[j].value) {
+			return len(matches[i].value) > len(matches[j].value)
+		}
+		return matches[i].label < matches[j].label
+	})
+
+	for _, m := range matches {
+		text = strings.ReplaceAll(text, m.value, m.label)
+	}
+	return text
+}
+
+func (s *Store) cache(app, name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values[app] == nil {
+		s.values[app] = make(map[string]string)
+	}
+	s.values[app][name] = value
+}
@@ -0,0 +1,163 @@
+package secrets
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bowerhall/sheldon/internal/encryption"
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "secrets.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testSalt keeps the tests' keys stable across NewStore calls
+var testSalt = []byte("0123456789abcdef")
+
+func testKey(secret string) *encryption.Key {
+	return encryption.DeriveKey(secret, testSalt)
+}
+
+func TestSetEnvAndReload(t *testing.T) {
+	db := openDB(t)
+	key := testKey("test-secret")
+
+	store, err := NewStore(db, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("weather-bot", "API_KEY", "sk-live-123456"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("weather-bot", "API_KEY", "sk-live-abcdef"); err != nil {
+		t.Fatal(err)
+	}
+
+	var raw []byte
+	db.QueryRow(`SELECT value FROM app_secrets WHERE app = 'weather-bot'`).Scan(&raw)
+	if strings.Contains(string(raw), "sk-live") {
+		t.Error("secret stored in plaintext")
+	}
+
+	reloaded, err := NewStore(db, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, _ := reloaded.Env("weather-bot")
+	if env["API_KEY"] != "sk-live-abcdef" || len(env) != 1 {
+		t.Errorf("env = %v", env)
+	}
+
+	if _, err := NewStore(db, testKey("wrong"), nil); err == nil {
+		t.Error("expected error loading with the wrong key")
+	}
+}
+
+func TestLegacySecretsResealed(t *testing.T) {
+	db := openDB(t)
+	legacy := encryption.LegacyKey("test-secret")
+
+	old, err := NewStore(db, legacy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.Set("bot", "TOKEN", "tok-987654")
+
+	if _, err := NewStore(db, testKey("test-secret"), nil); err == nil {
+		t.Fatal("expected the new key alone not to open legacy secrets")
+	}
+	if _, err := NewStore(db, testKey("test-secret"), legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	// re-sealed, so the new key alone opens them from now on
+	store, err := NewStore(db, testKey("test-secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env, _ := store.Env("bot"); env["TOKEN"] != "tok-987654" {
+		t.Errorf("env = %v", env)
+	}
+}
+
+func TestSetValidation(t *testing.T) {
+	store, err := NewStore(openDB(t), testKey("test-secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct{ app, name, value string }{
+		{"Bad App", "API_KEY", "value"},
+		{"app", "API-KEY", "value"},
+		{"app", "API_KEY", ""},
+		{"app", "API_KEY", "line one\nline two"},
+	}
+	for _, c := range cases {
+		if err := store.Set(c.app, c.name, c.value); err == nil {
+			t.Errorf("Set(%q, %q, %q) should fail", c.app, c.name, c.value)
+		}
+	}
+}
+
+func TestDeleteAndNames(t *testing.T) {
+	store, err := NewStore(openDB(t), testKey("test-secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("app", "TOKEN", "abc123")
+	store.Set("app", "DB_URL", "postgres://x")
+
+	if got := strings.Join(store.Names("app"), ","); got != "DB_URL,TOKEN" {
+		t.Errorf("names = %s", got)
+	}
+	if err := store.Delete("app", "TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("app", "TOKEN"); err != ErrNotFound {
+		t.Errorf("second delete = %v, want ErrNotFound", err)
+	}
+	if got := store.Names("app"); len(got) != 1 {
+		t.Errorf("names after delete = %v", got)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	store, err := NewStore(openDB(t), testKey("test-secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("bot", "TOKEN", "tok-987654")
+	store.Set("bot", "SHORT", "ab")
+
+	got := store.Redact("auth failed for tok-987654 (ab)")
+	if got != "auth failed for [secret bot/TOKEN] (ab)" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRedactOverlapping(t *testing.T) {
+	store, err := NewStore(openDB(t), testKey("test-secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("db", "PASSWORD", "hunter22")
+	store.Set("db", "URL", "postgres://app:hunter22@db:5432/app")
+
+	// map order varies between runs, so try a few times
+	for range 20 {
+		got := store.Redact("dial postgres://app:hunter22@db:5432/app: refused")
+		if got != "dial [secret db/URL]: refused" {
+			t.Fatalf("got %q", got)
+		}
+	}
+}
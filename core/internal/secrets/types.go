@@ -0,0 +1,18 @@
+package secrets
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/bowerhall/sheldon/internal/encryption"
+)
+
+// Store keeps per-app secrets sealed with the memory encryption key
+type Store struct {
+	db  *sql.DB
+	key *encryption.Key
+
+	// decrypted values by app and name, for injection and redaction
+	mu     sync.RWMutex
+	values map[string]map[string]string
+}
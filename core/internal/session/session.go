@@ -1,6 +1,11 @@
 package session
 
-import "github.com/bowerhall/sheldon/internal/llm"
+import (
+	"sort"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
 
 func (s *Session) AddMessage(role, content string, toolCalls []llm.ToolCall, toolCallID string) {
 	s.AddMessageWithMedia(role, content, nil, toolCalls, toolCallID)
@@ -9,6 +14,7 @@ func (s *Session) AddMessage(role, content string, toolCalls []llm.ToolCall, too
 func (s *Session) AddMessageWithMedia(role, content string, media []llm.MediaContent, toolCalls []llm.ToolCall, toolCallID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.lastActive = time.Now()
 	s.messages = append(s.messages, llm.Message{
 		Role:       role,
 		Content:    content,
@@ -18,6 +24,20 @@ func (s *Session) AddMessageWithMedia(role, content string, media []llm.MediaCon
 	})
 }
 
+// AddIdleMessage appends a message only to a session that has history and
+// isn't mid-turn, so it can't land between a tool call and its result. It
+// reports whether the message was added.
+func (s *Session) AddIdleMessage(role, content string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.messages) == 0 || s.busy.Load() {
+		return false
+	}
+	s.lastActive = time.Now()
+	s.messages = append(s.messages, llm.Message{Role: role, Content: content})
+	return true
+}
+
 func (s *Session) Messages() []llm.Message {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -31,23 +51,34 @@ func (s *Session) Messages() []llm.Message {
 // TryAcquire attempts to acquire the processing lock.
 // Returns true if acquired, false if already processing.
 func (s *Session) TryAcquire() bool {
-	return s.processing.TryLock()
+	if !s.processing.TryLock() {
+		return false
+	}
+	// set under mu so AddIdleMessage sees the turn start
+	s.mu.Lock()
+	s.busy.Store(true)
+	s.mu.Unlock()
+	return true
 }
 
 // Release releases the processing lock.
 func (s *Session) Release() {
+	s.mu.Lock()
+	s.busy.Store(false)
+	s.mu.Unlock()
 	s.processing.Unlock()
 }
 
+// Processing reports whether a message is currently being handled
+func (s *Session) Processing() bool {
+	return s.busy.Load()
+}
+
 // Queue adds a message to the pending queue
-func (s *Session) Queue(content string, media []llm.MediaContent, trusted bool) {
+func (s *Session) Queue(msg QueuedMessage) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.queue = append(s.queue, QueuedMessage{
-		Content: content,
-		Media:   media,
-		Trusted: trusted,
-	})
+	s.queue = append(s.queue, msg)
 }
 
 // Dequeue removes and returns the next queued message, or nil if empty
@@ -62,6 +93,15 @@ func (s *Session) Dequeue() *QueuedMessage {
 	return &msg
 }
 
+// DequeueAll removes and returns every queued message in arrival order
+func (s *Session) DequeueAll() []QueuedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queued := s.queue
+	s.queue = nil
+	return queued
+}
+
 // QueueLen returns the number of queued messages
 func (s *Session) QueueLen() int {
 	s.mu.Lock()
@@ -74,9 +114,12 @@ func NewStore() *Store {
 }
 
 func (s *Store) Get(sessionID string) *Session {
+	// touched under the store lock so a concurrent Sweep sees it as active
 	s.mu.RLock()
-
 	sess, ok := s.sessions[sessionID]
+	if ok {
+		sess.touch()
+	}
 	s.mu.RUnlock()
 
 	if ok {
@@ -87,11 +130,185 @@ func (s *Store) Get(sessionID string) *Session {
 	defer s.mu.Unlock()
 
 	if sess, ok = s.sessions[sessionID]; ok {
+		sess.touch()
 		return sess
 	}
 
-	sess = &Session{}
+	sess = &Session{lastActive: time.Now()}
 	s.sessions[sessionID] = sess
 
 	return sess
 }
+
+// Acquire fetches a session and takes its processing lock, or queues msg on
+// it when another turn holds the lock. Both happen under the store lock, so
+// Sweep can't evict the session between the lookup and the lock: it never
+// removes one that is processing or has a queue.
+func (s *Store) Acquire(sessionID string, msg QueuedMessage) (*Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		sess = &Session{}
+		s.sessions[sessionID] = sess
+	}
+	sess.touch()
+
+	if sess.TryAcquire() {
+		return sess, true
+	}
+	sess.Queue(msg)
+	return sess, false
+}
+
+func (s *Session) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastActive = time.Now()
+}
+
+// LastActive returns when the session was last fetched or written to
+func (s *Session) LastActive() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastActive
+}
+
+// Sweep removes sessions idle for longer than ttl, then the least recently
+// active ones until at most max remain. Sessions that are processing or have
+// queued messages are never removed. Zero disables either limit.
+func (s *Store) Sweep(ttl time.Duration, max int) []Evicted {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type candidate struct {
+		id         string
+		lastActive time.Time
+	}
+	var idle []candidate
+	for id, sess := range s.sessions {
+		if sess.Processing() || sess.QueueLen() > 0 {
+			continue
+		}
+		idle = append(idle, candidate{id, sess.LastActive()})
+	}
+	sort.Slice(idle, func(i, j int) bool { return idle[i].lastActive.Before(idle[j].lastActive) })
+
+	var evicted []Evicted
+	now := time.Now()
+	for _, c := range idle {
+		expired := ttl > 0 && now.Sub(c.lastActive) > ttl
+		overCap := max > 0 && len(s.sessions) > max
+		if !expired && !overCap {
+			break
+		}
+		evicted = append(evicted, Evicted{ID: c.id, Session: s.sessions[c.id]})
+		delete(s.sessions, c.id)
+	}
+
+	return evicted
+}
+
+// MarkForReset asks for the history to be cleared after the current turn.
+// Clearing mid-turn would separate tool calls from their results.
+func (s *Session) MarkForReset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset = true
+}
+
+// ResetIfMarked clears the history if MarkForReset was called, reporting
+// whether it did
+func (s *Session) ResetIfMarked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.reset {
+		return false
+	}
+	s.reset = false
+	s.messages = nil
+	return true
+}
+
+// Tokens returns the estimated token count of the session history
+func (s *Session) Tokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, m := range s.messages {
+		total += llm.EstimateMessageTokens(m)
+	}
+	return total
+}
+
+// CompactionPoint returns how many of the oldest messages must be removed for the
+// history to fit within target tokens. The cut always lands on a user message so a
+// tool call is never separated from its result. Returns 0 if nothing can be removed.
+func (s *Session) CompactionPoint(target int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, m := range s.messages {
+		total += llm.EstimateMessageTokens(m)
+	}
+	if total <= target {
+		return 0
+	}
+
+	// index 0 is never a cut point, at least the latest turn must survive
+	cut, removed := 0, 0
+	for i := 1; i < len(s.messages); i++ {
+		removed += llm.EstimateMessageTokens(s.messages[i-1])
+		if s.messages[i].Role != "user" {
+			continue
+		}
+		cut = i
+		if total-removed <= target {
+			break
+		}
+	}
+
+	return cut
+}
+
+// Compact replaces the n oldest messages with a single summary message.
+// An empty summary just drops them.
+func (s *Session) Compact(n int, summary string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n <= 0 || n > len(s.messages) {
+		return
+	}
+
+	kept := s.messages[n:]
+	compacted := make([]llm.Message, 0, len(kept)+1)
+	if summary != "" {
+		compacted = append(compacted, llm.Message{Role: "system", Content: summary})
+	}
+	s.messages = append(compacted, kept...)
+}
+
+// List returns a snapshot of all sessions, sorted by ID
+func (s *Store) List() []Info {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]Info, 0, len(s.sessions))
+	for id, sess := range s.sessions {
+		infos = append(infos, Info{
+			ID:         id,
+			Messages:   len(sess.Messages()),
+			Tokens:     sess.Tokens(),
+			Queued:     sess.QueueLen(),
+			Processing: sess.Processing(),
+			LastActive: sess.LastActive(),
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
@@ -1,8 +1,10 @@
 package session
 
 import (
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/llm"
 )
@@ -109,6 +111,31 @@ func TestSessionTryAcquireAndRelease(t *testing.T) {
 	s.Release()
 }
 
+func TestSessionAddIdleMessage(t *testing.T) {
+	s := &Session{}
+
+	if s.AddIdleMessage("user", "before any turn") {
+		t.Error("expected no message added to a session without history")
+	}
+
+	s.AddMessage("user", "hello", nil, "")
+	s.TryAcquire()
+	s.AddMessage("assistant", "", []llm.ToolCall{{ID: "call_1", Name: "web_search"}}, "")
+	if s.AddIdleMessage("user", "mid-turn") {
+		t.Error("expected no message added while a turn is running")
+	}
+	s.AddMessage("tool", "results", nil, "call_1")
+	s.Release()
+
+	if !s.AddIdleMessage("user", "after the turn") {
+		t.Error("expected the message added once the turn ended")
+	}
+	msgs := s.Messages()
+	if len(msgs) != 4 || msgs[2].ToolCallID != "call_1" || msgs[3].Content != "after the turn" {
+		t.Errorf("unexpected history %+v", msgs)
+	}
+}
+
 func TestSessionQueue(t *testing.T) {
 	s := &Session{}
 
@@ -122,8 +149,8 @@ func TestSessionQueue(t *testing.T) {
 	}
 
 	// add to queue
-	s.Queue("message 1", nil, true)
-	s.Queue("message 2", nil, false)
+	s.Queue(QueuedMessage{Content: "message 1", Trusted: true})
+	s.Queue(QueuedMessage{Content: "message 2", Trusted: false})
 
 	if s.QueueLen() != 2 {
 		t.Errorf("expected queue length 2, got %d", s.QueueLen())
@@ -231,3 +258,177 @@ func TestStoreConcurrentGet(t *testing.T) {
 		}
 	}
 }
+
+func TestSessionCompactionPoint(t *testing.T) {
+	s := &Session{}
+	long := strings.Repeat("x", 400) // ~100 tokens
+
+	s.AddMessage("user", long, nil, "")
+	s.AddMessage("assistant", "", []llm.ToolCall{{ID: "call_1", Name: "recall_memory", Arguments: "{}"}}, "")
+	s.AddMessage("tool", long, nil, "call_1")
+	s.AddMessage("assistant", long, nil, "")
+	s.AddMessage("user", long, nil, "")
+	s.AddMessage("assistant", long, nil, "")
+	s.AddMessage("user", "latest", nil, "")
+
+	if cut := s.CompactionPoint(s.Tokens()); cut != 0 {
+		t.Errorf("expected no cut when history fits, got %d", cut)
+	}
+
+	// must cut at a user message, never between a tool call and its result
+	if cut := s.CompactionPoint(300); cut != 4 {
+		t.Errorf("expected cut at first user boundary (4), got %d", cut)
+	}
+
+	// when nothing fits, keep the latest user turn
+	if cut := s.CompactionPoint(1); cut != 6 {
+		t.Errorf("expected cut at latest user message (6), got %d", cut)
+	}
+}
+
+func TestSessionCompact(t *testing.T) {
+	s := &Session{}
+	s.AddMessage("user", "one", nil, "")
+	s.AddMessage("assistant", "two", nil, "")
+	s.AddMessage("user", "three", nil, "")
+
+	s.Compact(2, "summary of one and two")
+
+	msgs := s.Messages()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	if msgs[0].Role != "system" || msgs[0].Content != "summary of one and two" {
+		t.Errorf("expected summary first, got %+v", msgs[0])
+	}
+	if msgs[1].Content != "three" {
+		t.Errorf("expected latest message kept, got %+v", msgs[1])
+	}
+
+	s.Compact(1, "")
+	if msgs := s.Messages(); len(msgs) != 1 || msgs[0].Content != "three" {
+		t.Errorf("expected summary dropped, got %+v", msgs)
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	store := NewStore()
+
+	busy := store.Get("telegram:2")
+	busy.AddMessage("user", "hello", nil, "")
+	busy.TryAcquire()
+	busy.Queue(QueuedMessage{Content: "second", Trusted: true})
+
+	store.Get("discord:1")
+
+	infos := store.List()
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(infos))
+	}
+	if infos[0].ID != "discord:1" {
+		t.Errorf("expected sessions sorted by ID, got %s first", infos[0].ID)
+	}
+
+	info := infos[1]
+	if info.Messages != 1 || info.Queued != 1 || !info.Processing {
+		t.Errorf("unexpected session info: %+v", info)
+	}
+
+	busy.Release()
+	if store.List()[1].Processing {
+		t.Error("expected session to be idle after release")
+	}
+}
+
+func TestStoreSweep(t *testing.T) {
+	store := NewStore()
+	old := store.Get("telegram:1")
+	old.AddMessage("user", "hello", nil, "")
+	old.lastActive = time.Now().Add(-2 * time.Hour)
+
+	busy := store.Get("telegram:2")
+	busy.lastActive = time.Now().Add(-2 * time.Hour)
+	busy.TryAcquire()
+	defer busy.Release()
+
+	store.Get("telegram:3")
+
+	evicted := store.Sweep(time.Hour, 0)
+	if len(evicted) != 1 || evicted[0].ID != "telegram:1" || len(evicted[0].Session.Messages()) != 1 {
+		t.Fatalf("evicted = %+v, want only the idle session with its history", evicted)
+	}
+	if got := len(store.List()); got != 2 {
+		t.Errorf("%d sessions left, want 2", got)
+	}
+}
+
+func TestStoreSweepMaxSessions(t *testing.T) {
+	store := NewStore()
+	for i, id := range []string{"a", "b", "c", "d"} {
+		store.Get(id).lastActive = time.Now().Add(time.Duration(i) * time.Minute)
+	}
+
+	evicted := store.Sweep(0, 2)
+	var ids []string
+	for _, e := range evicted {
+		ids = append(ids, e.ID)
+	}
+	if strings.Join(ids, ",") != "a,b" {
+		t.Errorf("evicted %v, want the two least recently active", ids)
+	}
+}
+
+func TestSessionResetAfterTurn(t *testing.T) {
+	s := &Session{}
+	s.AddMessage("user", "forget everything", nil, "")
+
+	if s.ResetIfMarked() {
+		t.Fatal("reset without being marked")
+	}
+
+	s.MarkForReset()
+	s.AddMessage("assistant", "done", nil, "")
+	if len(s.Messages()) != 2 {
+		t.Fatal("history cleared before the turn ended")
+	}
+	if !s.ResetIfMarked() || len(s.Messages()) != 0 {
+		t.Errorf("history not cleared: %d messages", len(s.Messages()))
+	}
+	if s.ResetIfMarked() {
+		t.Error("reset flag not cleared")
+	}
+}
+
+func TestSessionDequeueAll(t *testing.T) {
+	s := &Session{}
+	s.Queue(QueuedMessage{Content: "one", Trusted: true})
+	s.Queue(QueuedMessage{Content: "two", Trusted: false})
+
+	queued := s.DequeueAll()
+	if len(queued) != 2 || queued[0].Content != "one" || queued[1].Content != "two" {
+		t.Fatalf("DequeueAll = %+v, want both messages in order", queued)
+	}
+	if s.QueueLen() != 0 || len(s.DequeueAll()) != 0 {
+		t.Error("queue not empty after DequeueAll")
+	}
+}
+
+func TestStoreAcquire(t *testing.T) {
+	store := NewStore()
+
+	sess, ok := store.Acquire("telegram:1", QueuedMessage{Content: "first"})
+	if !ok || sess.QueueLen() != 0 {
+		t.Fatal("expected the first message to take the session")
+	}
+
+	again, ok := store.Acquire("telegram:1", QueuedMessage{Content: "second"})
+	if ok || again != sess || sess.QueueLen() != 1 {
+		t.Fatal("expected a busy session to queue the message")
+	}
+
+	sess.lastActive = time.Now().Add(-2 * time.Hour)
+	if evicted := store.Sweep(time.Hour, 0); len(evicted) != 0 {
+		t.Errorf("evicted %+v, want an acquired session kept", evicted)
+	}
+	sess.Release()
+}
@@ -2,6 +2,8 @@ package session
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/llm"
 )
@@ -11,13 +13,37 @@ type QueuedMessage struct {
 	Content string
 	Media   []llm.MediaContent
 	Trusted bool
+
+	// who sent it, so a queued group message is still attributed to its speaker
+	UserID  int64
+	Group   bool
+	Speaker string
 }
 
 type Session struct {
 	mu         sync.Mutex
 	messages   []llm.Message
 	processing sync.Mutex
+	busy       atomic.Bool
 	queue      []QueuedMessage
+	lastActive time.Time
+	reset      bool // clear history once the current turn ends
+}
+
+// Info is a point-in-time view of a session for monitoring
+type Info struct {
+	ID         string    `json:"id"`
+	Messages   int       `json:"messages"`
+	Tokens     int       `json:"tokens"`
+	Queued     int       `json:"queued"`
+	Processing bool      `json:"processing"`
+	LastActive time.Time `json:"last_active"`
+}
+
+// Evicted is a session removed from the store by Sweep
+type Evicted struct {
+	ID      string
+	Session *Session
 }
 
 type Store struct {
@@ -0,0 +1,349 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldonmem"
+)
+
+const verifyTimeout = 30 * time.Second
+
+//go:embed soul.md
+var soulTemplate string
+
+var (
+	providers = []string{"claude", "kimi", "openai", "openrouter", "ollama"}
+	bots      = []string{"telegram", "discord", "web"}
+)
+
+// New creates a wizard reading answers from in and writing prompts to out
+func New(in io.Reader, out io.Writer, verify Verifier) *Wizard {
+	return &Wizard{
+		in:         bufio.NewScanner(in),
+		out:        out,
+		verify:     verify,
+		initMemory: InitMemory,
+	}
+}
+
+// Run walks through first-run setup: it writes envPath, creates SOUL.md if
+// there is none, initializes the memory database and checks the provider key.
+// Existing files are only replaced after asking.
+func (w *Wizard) Run(ctx context.Context, envPath string) error {
+	fmt.Fprintln(w.out, "👋 Sheldon setup. Press enter to accept the [default].")
+
+	writeEnv := true
+	if _, err := os.Stat(envPath); err == nil {
+		writeEnv = w.confirm(fmt.Sprintf("%s already exists. Replace it?", envPath), false)
+	}
+
+	a := w.ask()
+
+	if err := w.verifyProvider(ctx, &a); err != nil {
+		return err
+	}
+
+	if writeEnv {
+		if err := os.WriteFile(envPath, []byte(EnvFile(a)), 0600); err != nil {
+			return fmt.Errorf("write %s: %w", envPath, err)
+		}
+		fmt.Fprintf(w.out, "✅ wrote %s\n", envPath)
+	} else {
+		fmt.Fprintf(w.out, "⏭️ kept %s\n", envPath)
+	}
+
+	created, err := WriteSoul(a.EssencePath, a)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Fprintf(w.out, "✅ created %s\n", filepath.Join(a.EssencePath, "SOUL.md"))
+	} else {
+		fmt.Fprintf(w.out, "⏭️ kept existing %s\n", filepath.Join(a.EssencePath, "SOUL.md"))
+	}
+
+	if err := w.initMemory(a.MemoryPath); err != nil {
+		return fmt.Errorf("initialize memory: %w", err)
+	}
+	fmt.Fprintf(w.out, "✅ memory ready at %s\n", a.MemoryPath)
+
+	fmt.Fprintln(w.out, "\n🚀 Setup complete. Start Sheldon with: sheldon")
+	if a.Bot == "web" {
+		fmt.Fprintln(w.out, "Open the web chat and sign in with WEB_CHAT_TOKEN from", envPath)
+	}
+	return nil
+}
+
+func (w *Wizard) ask() Answers {
+	var a Answers
+
+	a.UserName = w.prompt("What should Sheldon call you?", "")
+
+	provider := config.DetectProvider()
+	if !slices.Contains(providers, provider) {
+		provider = providers[0]
+	}
+	a.Provider = w.choose("LLM provider", providers, provider)
+	if key := config.EnvKeyForProvider(a.Provider); key != "" {
+		a.APIKey = w.prompt(key, os.Getenv(key))
+	}
+	a.Model = w.prompt("Model", config.DefaultLLMModel(a.Provider))
+
+	a.Bot = w.choose("Chat channel", bots, "telegram")
+	switch a.Bot {
+	case "telegram":
+		a.BotToken = w.prompt("Telegram bot token (from @BotFather)", os.Getenv("TELEGRAM_TOKEN"))
+		a.OwnerID = w.prompt("Your Telegram chat ID (ask @userinfobot)", os.Getenv("OWNER_CHAT_ID"))
+	case "discord":
+		a.BotToken = w.prompt("Discord bot token", os.Getenv("DISCORD_TOKEN"))
+		a.OwnerID = w.prompt("Your Discord user ID", os.Getenv("DISCORD_OWNER_ID"))
+	case "web":
+		a.BotToken = os.Getenv("WEB_CHAT_TOKEN")
+		if a.BotToken == "" {
+			a.BotToken = randomToken()
+		}
+	}
+
+	tz := localTimezone()
+	for {
+		a.Timezone = w.prompt("Timezone", tz)
+		if _, err := time.LoadLocation(a.Timezone); err == nil {
+			break
+		}
+		fmt.Fprintf(w.out, "❌ unknown timezone %q, use a name like Europe/Berlin\n", a.Timezone)
+		tz = "UTC"
+	}
+
+	a.EssencePath = w.prompt("Essence directory", envOr("SHELDON_ESSENCE", "essence"))
+	a.MemoryPath = w.prompt("Memory database", envOr("SHELDON_MEMORY", "sheldon.db"))
+
+	return a
+}
+
+func (w *Wizard) verifyProvider(ctx context.Context, a *Answers) error {
+	if w.verify == nil {
+		return nil
+	}
+
+	for {
+		fmt.Fprintf(w.out, "🔑 checking %s/%s...\n", a.Provider, a.Model)
+		err := w.verify(ctx, a.Provider, a.APIKey, a.Model)
+		if err == nil {
+			fmt.Fprintln(w.out, "✅ provider works")
+			return nil
+		}
+
+		fmt.Fprintf(w.out, "❌ %s\n", err)
+		if !w.confirm("Try again?", true) {
+			if w.confirm("Save the config anyway?", false) {
+				return nil
+			}
+			return errors.New("setup cancelled: provider check failed")
+		}
+		if key := config.EnvKeyForProvider(a.Provider); key != "" {
+			a.APIKey = w.prompt(key, a.APIKey)
+		}
+		a.Model = w.prompt("Model", a.Model)
+	}
+}
+
+// prompt asks a question and returns the answer, or def for an empty line or
+// closed input
+func (w *Wizard) prompt(question, def string) string {
+	if def != "" && !secret(question) {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else if def != "" {
+		fmt.Fprintf(w.out, "%s [keep current]: ", question)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+
+	if !w.in.Scan() {
+		fmt.Fprintln(w.out)
+		return def
+	}
+	if answer := strings.TrimSpace(w.in.Text()); answer != "" {
+		return answer
+	}
+	return def
+}
+
+func (w *Wizard) choose(question string, options []string, def string) string {
+	for {
+		answer := strings.ToLower(w.prompt(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), def))
+		if slices.Contains(options, answer) {
+			return answer
+		}
+		fmt.Fprintf(w.out, "❌ pick one of: %s\n", strings.Join(options, ", "))
+	}
+}
+
+func (w *Wizard) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Fprintf(w.out, "%s [%s]: ", question, hint)
+	if !w.in.Scan() {
+		fmt.Fprintln(w.out)
+		return def
+	}
+	switch strings.ToLower(strings.TrimSpace(w.in.Text())) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// EnvFile renders answers as a .env file
+func EnvFile(a Answers) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by sheldon init on %s\n", time.Now().Format("2006-01-02"))
+	sb.WriteString("# See .env.example for every option.\n\n")
+
+	set := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%s=%s\n", key, value)
+		}
+	}
+
+	set("LLM_PROVIDER", a.Provider)
+	set(config.EnvKeyForProvider(a.Provider), a.APIKey)
+	set("LLM_MODEL", a.Model)
+	sb.WriteString("\n")
+
+	switch a.Bot {
+	case "telegram":
+		set("TELEGRAM_TOKEN", a.BotToken)
+		set("OWNER_CHAT_ID", a.OwnerID)
+	case "discord":
+		set("BOT_PROVIDER", "discord")
+		set("DISCORD_TOKEN", a.BotToken)
+		set("DISCORD_OWNER_ID", a.OwnerID)
+	case "web":
+		set("BOT_PROVIDER", "web")
+		set("WEB_CHAT_TOKEN", a.BotToken)
+	}
+	sb.WriteString("\n")
+
+	set("TZ", a.Timezone)
+	if a.EssencePath != "essence" {
+		set("SHELDON_ESSENCE", a.EssencePath)
+	}
+	if a.MemoryPath != "sheldon.db" {
+		set("SHELDON_MEMORY", a.MemoryPath)
+	}
+
+	return sb.String()
+}
+
+// WriteSoul creates SOUL.md from the template unless one exists already,
+// reporting whether it wrote the file
+func WriteSoul(essencePath string, a Answers) (bool, error) {
+	path := filepath.Join(essencePath, "SOUL.md")
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+
+	tmpl, err := template.New("soul").Parse(soulTemplate)
+	if err != nil {
+		return false, err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, a); err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(essencePath, 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// InitMemory creates the memory database with its domains and the Sheldon
+// entity, which the startup health check expects
+func InitMemory(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	memory, err := sheldonmem.Open(path)
+	if err != nil {
+		return err
+	}
+	defer memory.Close()
+
+	if _, err := memory.FindEntityByName("Sheldon"); err != nil {
+		return fmt.Errorf("sheldon entity missing: %w", err)
+	}
+	return nil
+}
+
+// VerifyProvider sends one short chat request to check the key and model
+func VerifyProvider(ctx context.Context, provider, apiKey, model string) error {
+	client, err := llm.New(llm.Config{Provider: provider, APIKey: apiKey, Model: model, MaxAttempts: 1})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	reply, err := client.Chat(ctx, "", []llm.Message{{Role: "user", Content: "Reply with the single word: ready"}})
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(reply) == "" {
+		return fmt.Errorf("%s returned an empty reply", model)
+	}
+	return nil
+}
+
+func secret(question string) bool {
+	return strings.Contains(question, "KEY") || strings.Contains(strings.ToLower(question), "token")
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func localTimezone() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		return tz
+	}
+	if name := time.Local.String(); name != "Local" {
+		return name
+	}
+	return "UTC"
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
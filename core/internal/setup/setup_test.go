@@ -0,0 +1,118 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestWizard(input string, verify Verifier) (*Wizard, *strings.Builder) {
+	var out strings.Builder
+	w := New(strings.NewReader(input), &out, verify)
+	w.initMemory = func(path string) error { return nil }
+	return w, &out
+}
+
+func TestWizardWritesFiles(t *testing.T) {
+	t.Setenv("TZ", "")
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	essence := filepath.Join(dir, "essence")
+
+	input := strings.Join([]string{
+		"Jamie",         // name
+		"claude",        // provider
+		"sk-ant-test",   // key
+		"",              // model default
+		"telegram",      // channel
+		"123:abc",       // bot token
+		"42",            // chat id
+		"Europe/Berlin", // timezone
+		essence,         // essence dir
+		filepath.Join(dir, "m.db"),
+	}, "\n") + "\n"
+
+	var verified string
+	w, out := newTestWizard(input, func(ctx context.Context, provider, key, model string) error {
+		verified = provider + "/" + key
+		return nil
+	})
+	if err := w.Run(context.Background(), envPath); err != nil {
+		t.Fatalf("Run: %v\n%s", err, out)
+	}
+	if verified != "claude/sk-ant-test" {
+		t.Errorf("verified %q", verified)
+	}
+
+	env, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"LLM_PROVIDER=claude", "ANTHROPIC_API_KEY=sk-ant-test", "LLM_MODEL=claude-sonnet-4-20250514", "TELEGRAM_TOKEN=123:abc", "OWNER_CHAT_ID=42", "TZ=Europe/Berlin", "SHELDON_ESSENCE=" + essence} {
+		if !strings.Contains(string(env), want) {
+			t.Errorf(".env missing %q:\n%s", want, env)
+		}
+	}
+
+	soul, err := os.ReadFile(filepath.Join(essence, "SOUL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(soul), "You serve one person: Jamie.") {
+		t.Errorf("SOUL.md not personalized:\n%s", soul)
+	}
+}
+
+func TestWizardKeepsExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("KEEP=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "essence"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "essence", "SOUL.md"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// decline replacing .env, then accept every default; the web channel
+	// needs no token prompt
+	input := "n\n\nollama\n\nweb\nUTC\n" + filepath.Join(dir, "essence") + "\n\n"
+	w, out := newTestWizard(input, nil)
+	if err := w.Run(context.Background(), envPath); err != nil {
+		t.Fatalf("Run: %v\n%s", err, out)
+	}
+
+	if env, _ := os.ReadFile(envPath); string(env) != "KEEP=1\n" {
+		t.Errorf(".env replaced: %s", env)
+	}
+	if soul, _ := os.ReadFile(filepath.Join(dir, "essence", "SOUL.md")); string(soul) != "mine" {
+		t.Errorf("SOUL.md replaced: %s", soul)
+	}
+}
+
+func TestWizardProviderCheckFails(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+
+	// bad key, retry with a new key that also fails, give up without saving
+	input := "\nkimi\nbad\n\nweb\nUTC\n" + filepath.Join(dir, "essence") + "\n\n" + "y\nstill-bad\n\nn\nn\n"
+	calls := 0
+	w, out := newTestWizard(input, func(ctx context.Context, provider, key, model string) error {
+		calls++
+		return errors.New("401 unauthorized")
+	})
+	if err := w.Run(context.Background(), envPath); err == nil {
+		t.Fatalf("expected error\n%s", out)
+	}
+	if calls != 2 {
+		t.Errorf("verify called %d times, want 2", calls)
+	}
+	if _, err := os.Stat(envPath); !os.IsNotExist(err) {
+		t.Error(".env written after a failed provider check")
+	}
+}
@@ -0,0 +1,32 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// Answers are the choices made during setup
+type Answers struct {
+	UserName    string
+	Provider    string
+	APIKey      string
+	Model       string
+	Bot         string // telegram, discord or web
+	BotToken    string
+	OwnerID     string // telegram chat ID or discord user ID, unused for web
+	Timezone    string
+	EssencePath string
+	MemoryPath  string
+}
+
+// Verifier checks that a provider accepts a key by sending it a tiny request
+type Verifier func(ctx context.Context, provider, apiKey, model string) error
+
+// Wizard asks the first-run questions and writes the files Sheldon needs
+type Wizard struct {
+	in         *bufio.Scanner
+	out        io.Writer
+	verify     Verifier
+	initMemory func(path string) error
+}
@@ -0,0 +1,92 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+)
+
+type openaiTranscriber struct {
+	apiKey   string
+	baseURL  string
+	model    string
+	language string
+	client   *http.Client
+}
+
+type transcriptionResponse struct {
+	Text string `json:"text"`
+}
+
+func newOpenAI(apiKey, baseURL, model, language string) Transcriber {
+	return &openaiTranscriber{
+		apiKey:   apiKey,
+		baseURL:  baseURL,
+		model:    model,
+		language: language,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Transcribe converts audio to text using the OpenAI audio transcription API.
+// Any OpenAI-compatible server (e.g., faster-whisper-server) works via BaseURL.
+func (o *openaiTranscriber) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	fw, err := w.CreateFormFile("file", audioFilename(mimeType))
+	if err != nil {
+		return "", err
+	}
+	if _, err := fw.Write(audio); err != nil {
+		return "", err
+	}
+
+	if err := w.WriteField("model", o.model); err != nil {
+		return "", err
+	}
+	if o.language != "" {
+		if err := w.WriteField("language", o.language); err != nil {
+			return "", err
+		}
+	}
+	w.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/audio/transcriptions", &buf)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("whisper API error: %s", string(body))
+	}
+
+	var result transcriptionResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+
+	return result.Text, nil
+}
+
+func (o *openaiTranscriber) Provider() string {
+	return "openai"
+}
@@ -0,0 +1,56 @@
+package speech
+
+import (
+	"fmt"
+	"strings"
+)
+
+func New(cfg Config) (Transcriber, error) {
+	switch cfg.Provider {
+	case "openai":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("openai transcription requires an API key")
+		}
+
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+
+		model := cfg.Model
+		if model == "" {
+			model = "whisper-1"
+		}
+
+		return newOpenAI(cfg.APIKey, baseURL, model, cfg.Language), nil
+	case "whispercpp":
+		// local whisper.cpp server (examples/server), started with --convert so it accepts ogg/mp3
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "http://whisper:8080"
+		}
+
+		return newWhisperCpp(baseURL, cfg.Language), nil
+	case "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown speech provider: %s", cfg.Provider)
+	}
+}
+
+// audioFilename picks a file extension the transcription servers use to detect the codec
+func audioFilename(mimeType string) string {
+	mimeType = strings.ToLower(mimeType)
+	switch {
+	case strings.Contains(mimeType, "mpeg"), strings.Contains(mimeType, "mp3"):
+		return "audio.mp3"
+	case strings.Contains(mimeType, "wav"):
+		return "audio.wav"
+	case strings.Contains(mimeType, "mp4"), strings.Contains(mimeType, "m4a"):
+		return "audio.m4a"
+	case strings.Contains(mimeType, "webm"):
+		return "audio.webm"
+	default:
+		return "audio.ogg"
+	}
+}
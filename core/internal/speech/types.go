@@ -0,0 +1,17 @@
+package speech
+
+import "context"
+
+type Config struct {
+	Provider string
+	APIKey   string
+	BaseURL  string
+	Model    string
+	Language string // ISO-639-1 hint (e.g., "en"); empty lets the model detect it
+}
+
+// Transcriber converts recorded speech to text
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error)
+	Provider() string
+}
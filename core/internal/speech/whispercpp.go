@@ -0,0 +1,84 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type whisperCpp struct {
+	baseURL  string
+	language string
+	client   *http.Client
+}
+
+func newWhisperCpp(baseURL, language string) Transcriber {
+	return &whisperCpp{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		language: language,
+		// local inference on CPU is slow for long notes
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Transcribe posts audio to a whisper.cpp server's /inference endpoint
+func (w *whisperCpp) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fw, err := mw.CreateFormFile("file", audioFilename(mimeType))
+	if err != nil {
+		return "", err
+	}
+	if _, err := fw.Write(audio); err != nil {
+		return "", err
+	}
+
+	if err := mw.WriteField("response_format", "json"); err != nil {
+		return "", err
+	}
+	if w.language != "" {
+		if err := mw.WriteField("language", w.language); err != nil {
+			return "", err
+		}
+	}
+	mw.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.baseURL+"/inference", &buf)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("whisper.cpp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("whisper.cpp error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result transcriptionResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("decode response: %w", err)
+	}
+
+	return strings.TrimSpace(result.Text), nil
+}
+
+func (w *whisperCpp) Provider() string {
+	return "whispercpp"
+}
@@ -0,0 +1,264 @@
+package sshexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/yaml.v3"
+)
+
+// shellMeta is refused outright: the remote shell would otherwise let an allowed
+// prefix smuggle in a second command
+const shellMeta = ";&|`$<>\\\n\r(){}"
+
+// LoadConfig reads an SSH hosts file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ssh hosts: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse ssh hosts: %w", err)
+	}
+	return &cfg, nil
+}
+
+// New validates the config, loads keys and known hosts, and compiles the allowlists
+func New(cfg *Config) (*Executor, error) {
+	if cfg.KnownHosts == "" {
+		return nil, fmt.Errorf("known_hosts is required")
+	}
+	callback, err := knownhosts.New(cfg.KnownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("load known hosts: %w", err)
+	}
+
+	e := &Executor{
+		hosts:     make(map[string]*host),
+		callback:  callback,
+		timeout:   cfg.Timeout,
+		maxOutput: cfg.MaxOutput,
+	}
+	if e.timeout <= 0 {
+		e.timeout = 30 * time.Second
+	}
+	if e.maxOutput <= 0 {
+		e.maxOutput = 8000
+	}
+
+	signers := make(map[string]ssh.Signer)
+	for name, hc := range cfg.Hosts {
+		if hc.Address == "" || hc.User == "" {
+			return nil, fmt.Errorf("host %s: address and user are required", name)
+		}
+
+		keyFile := hc.KeyFile
+		if keyFile == "" {
+			keyFile = cfg.KeyFile
+		}
+		if keyFile == "" {
+			return nil, fmt.Errorf("host %s: no key_file", name)
+		}
+		signer, ok := signers[keyFile]
+		if !ok {
+			if signer, err = loadSigner(keyFile); err != nil {
+				return nil, fmt.Errorf("host %s: %w", name, err)
+			}
+			signers[keyFile] = signer
+		}
+
+		h := &host{name: name, address: hc.Address, user: hc.User, signer: signer, config: hc}
+		if _, _, err := net.SplitHostPort(h.address); err != nil {
+			h.address = net.JoinHostPort(h.address, "22")
+		}
+		if h.readOnly, err = compileAll(hc.ReadOnly); err != nil {
+			return nil, fmt.Errorf("host %s read_only: %w", name, err)
+		}
+		if h.allow, err = compileAll(hc.Allow); err != nil {
+			return nil, fmt.Errorf("host %s allow: %w", name, err)
+		}
+		e.hosts[name] = h
+	}
+
+	return e, nil
+}
+
+func loadSigner(path string) (ssh.Signer, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(pem)
+	if err != nil {
+		return nil, fmt.Errorf("parse key %s (passphrase-protected keys aren't supported): %w", path, err)
+	}
+	return signer, nil
+}
+
+// compileAll anchors each pattern so it has to match the whole command
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(`^(?:` + strings.TrimSuffix(strings.TrimPrefix(p, "^"), "$") + `)$`)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Hosts returns the configured host names, sorted
+func (e *Executor) Hosts() []string {
+	names := make([]string, 0, len(e.hosts))
+	for name := range e.hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Allowlist returns a host's read-only and approval-required patterns
+func (e *Executor) Allowlist(name string) (readOnly, allow []string) {
+	h, ok := e.hosts[name]
+	if !ok {
+		return nil, nil
+	}
+	return h.config.ReadOnly, h.config.Allow
+}
+
+// Check reports whether a command may run on a host and whether it is read-only.
+// Refused commands return an error saying why.
+func (e *Executor) Check(name, command string) (readOnly bool, err error) {
+	h, ok := e.hosts[name]
+	if !ok {
+		return false, fmt.Errorf("unknown ssh host %q, configured hosts: %s", name, strings.Join(e.Hosts(), ", "))
+	}
+
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return false, fmt.Errorf("command is empty")
+	}
+	if strings.ContainsAny(command, shellMeta) {
+		return false, fmt.Errorf("shell operators, redirects and substitutions aren't allowed, run one command at a time")
+	}
+
+	for _, re := range h.readOnly {
+		if re.MatchString(command) {
+			return true, nil
+		}
+	}
+	for _, re := range h.allow {
+		if re.MatchString(command) {
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%q is not in the allowlist for %s", command, name)
+}
+
+// Run executes an allowlisted command. Callers are expected to have asked for
+// approval when Check says the command isn't read-only.
+func (e *Executor) Run(ctx context.Context, name, command string) (*Result, error) {
+	if _, err := e.Check(name, command); err != nil {
+		return nil, err
+	}
+	h := e.hosts[name]
+	command = strings.TrimSpace(command)
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	start := time.Now()
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", h.address)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", name, err)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            h.user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(h.signer)},
+		HostKeyCallback: e.callback,
+		Timeout:         e.timeout,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, h.address, config)
+	if err != nil {
+		conn.Close()
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			return nil, fmt.Errorf("host key for %s is unknown or changed, check known_hosts: %w", name, err)
+		}
+		return nil, fmt.Errorf("ssh handshake with %s: %w", name, err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("open session: %w", err)
+	}
+	defer session.Close()
+
+	output := &limitedBuffer{limit: e.maxOutput}
+	session.Stdout = output
+	session.Stderr = output
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run(command) }()
+
+	var runErr error
+	select {
+	case runErr = <-done:
+	case <-ctx.Done():
+		client.Close()
+		return nil, fmt.Errorf("%s on %s timed out after %s", command, name, e.timeout)
+	}
+
+	result := &Result{
+		Output:    output.String(),
+		Truncated: output.truncated, // writers are done once Run returns
+		Duration:  time.Since(start),
+	}
+	if runErr != nil {
+		var exitErr *ssh.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return nil, fmt.Errorf("run on %s: %w", name, runErr)
+		}
+		result.ExitCode = exitErr.ExitStatus()
+	}
+	return result, nil
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,261 @@
+package sshexec
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newSigner(t *testing.T) (ssh.Signer, ed25519.PrivateKey) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, key
+}
+
+// startServer runs a minimal SSH server that answers a few canned commands
+func startServer(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) string {
+	t.Helper()
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), clientKey.Marshal()) && conn.User() == "admin" {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChan := range chans {
+		channel, requests, err := newChan.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				command := string(req.Payload[4:])
+				req.Reply(true, nil)
+
+				status := uint32(0)
+				switch command {
+				case "uptime":
+					channel.Write([]byte("up 3 days\n"))
+				case "systemctl restart nginx":
+					channel.Stderr().Write([]byte("Job failed\n"))
+					status = 1
+				case "cat big.log":
+					channel.Write(bytes.Repeat([]byte("x"), 20000))
+				}
+				payload := make([]byte, 4)
+				binary.BigEndian.PutUint32(payload, status)
+				channel.SendRequest("exit-status", false, payload)
+				return
+			}
+		}()
+	}
+}
+
+func newTestExecutor(t *testing.T) *Executor {
+	t.Helper()
+	dir := t.TempDir()
+
+	hostKey, _ := newSigner(t)
+	clientSigner, clientKey := newSigner(t)
+	addr := startServer(t, hostKey, clientSigner.PublicKey())
+
+	block, err := ssh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "id_ed25519")
+	os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600)
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{addr}, hostKey.PublicKey())+"\n"), 0600)
+
+	e, err := New(&Config{
+		KeyFile:    keyFile,
+		KnownHosts: knownHosts,
+		Timeout:    5 * time.Second,
+		MaxOutput:  1000,
+		Hosts: map[string]HostConfig{
+			"router": {
+				Address:  addr,
+				User:     "admin",
+				ReadOnly: []string{"uptime", `cat [a-z.]+\.log`, "df -h.*"},
+				Allow:    []string{"^systemctl restart (nginx|dnsmasq)$"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestCheck(t *testing.T) {
+	e := newTestExecutor(t)
+
+	tests := []struct {
+		command  string
+		readOnly bool
+		refused  string
+	}{
+		{"uptime", true, ""},
+		{"  df -h /srv ", true, ""},
+		{"systemctl restart nginx", false, ""},
+		{"systemctl restart sshd", false, "not in the allowlist"},
+		{"uptime --pretty", false, "not in the allowlist"},
+		{"df -h; rm -rf /", false, "shell operators"},
+		{"df -h $(reboot)", false, "shell operators"},
+		{"cat x.log > /etc/passwd", false, "shell operators"},
+		{"", false, "empty"},
+	}
+
+	for _, tt := range tests {
+		readOnly, err := e.Check("router", tt.command)
+		if tt.refused != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.refused) {
+				t.Errorf("Check(%q) error = %v, want %q", tt.command, err, tt.refused)
+			}
+			continue
+		}
+		if err != nil || readOnly != tt.readOnly {
+			t.Errorf("Check(%q) = %v, %v, want readOnly %v", tt.command, readOnly, err, tt.readOnly)
+		}
+	}
+
+	if _, err := e.Check("nas", "uptime"); err == nil || !strings.Contains(err.Error(), "configured hosts: router") {
+		t.Errorf("unknown host error = %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	e := newTestExecutor(t)
+	ctx := context.Background()
+
+	result, err := e.Run(ctx, "router", "uptime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "up 3 days\n" || result.ExitCode != 0 || result.Truncated {
+		t.Errorf("uptime = %+v", result)
+	}
+
+	result, err = e.Run(ctx, "router", "systemctl restart nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 1 || result.Output != "Job failed\n" {
+		t.Errorf("failing command = %+v", result)
+	}
+
+	result, err = e.Run(ctx, "router", "cat big.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Output) != 1000 || !result.Truncated {
+		t.Errorf("big output kept %d bytes, truncated %v", len(result.Output), result.Truncated)
+	}
+
+	if _, err := e.Run(ctx, "router", "reboot"); err == nil {
+		t.Error("refused command ran")
+	}
+}
+
+func TestRunRejectsUnknownHostKey(t *testing.T) {
+	e := newTestExecutor(t)
+
+	otherKey, _ := newSigner(t)
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{e.hosts["router"].address}, otherKey.PublicKey())+"\n"), 0600)
+	callback, err := knownhosts.New(knownHosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.callback = callback
+
+	if _, err := e.Run(context.Background(), "router", "uptime"); err == nil || !strings.Contains(err.Error(), "known_hosts") {
+		t.Errorf("expected a host key error, got %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ssh.yml")
+	os.WriteFile(path, []byte(`
+key_file: /etc/sheldon/ssh/id_ed25519
+known_hosts: /etc/sheldon/ssh/known_hosts
+timeout: 45s
+hosts:
+  router:
+    address: 192.168.1.1
+    user: admin
+    read_only:
+      - uptime
+    allow:
+      - reboot
+`), 0600)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 45*time.Second || cfg.KnownHosts != "/etc/sheldon/ssh/known_hosts" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	router := cfg.Hosts["router"]
+	if router.Address != "192.168.1.1" || router.User != "admin" || len(router.ReadOnly) != 1 || router.Allow[0] != "reboot" {
+		t.Errorf("router = %+v", router)
+	}
+}
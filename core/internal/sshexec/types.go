@@ -0,0 +1,65 @@
+package sshexec
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Config is the SSH hosts file (SSH_HOSTS_FILE)
+type Config struct {
+	KeyFile    string                `yaml:"key_file"`    // private key used unless a host sets its own
+	KnownHosts string                `yaml:"known_hosts"` // required, hosts are never trusted on first use
+	Timeout    time.Duration         `yaml:"timeout"`     // per command (default: 30s)
+	MaxOutput  int                   `yaml:"max_output"`  // bytes of output kept (default: 8000)
+	Hosts      map[string]HostConfig `yaml:"hosts"`
+}
+
+// HostConfig is one machine and the commands Sheldon may run on it.
+// Commands matching read_only run straight away, ones matching allow need approval,
+// anything else is refused.
+type HostConfig struct {
+	Address  string   `yaml:"address"` // host or host:port
+	User     string   `yaml:"user"`
+	KeyFile  string   `yaml:"key_file"`
+	ReadOnly []string `yaml:"read_only"` // regexes, anchored to the whole command
+	Allow    []string `yaml:"allow"`
+}
+
+// Result is the outcome of a command; a non-zero exit is a result, not an error
+type Result struct {
+	Output    string
+	ExitCode  int
+	Truncated bool
+	Duration  time.Duration
+}
+
+// Executor runs allowlisted commands over SSH
+type Executor struct {
+	hosts     map[string]*host
+	callback  ssh.HostKeyCallback
+	timeout   time.Duration
+	maxOutput int
+}
+
+type host struct {
+	name     string
+	address  string
+	user     string
+	signer   ssh.Signer
+	config   HostConfig
+	readOnly []*regexp.Regexp
+	allow    []*regexp.Regexp
+}
+
+// limitedBuffer keeps the first limit bytes and notes that more were dropped.
+// stdout and stderr write to it concurrently.
+type limitedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
@@ -20,6 +20,7 @@ type Client struct {
 	mcPublic   *minio.Client // public client for presigned URLs (may be same as mc)
 	userBucket  string
 	agentBucket string
+	quotas      map[string]Quota // by bucket
 }
 
 // Config holds MinIO connection settings
@@ -30,6 +31,8 @@ type Config struct {
 	SecretKey      string
 	UseSSL         bool // SSL for internal endpoint
 	PublicUseSSL   bool // SSL for public endpoint
+	UserQuota      Quota
+	AgentQuota     Quota
 }
 
 // NewClient creates a new storage client
@@ -65,6 +68,10 @@ func NewClient(cfg Config) (*Client, error) {
 		userBucket:  "sheldon-user",
 		agentBucket: "sheldon-agent",
 	}
+	c.quotas = map[string]Quota{
+		c.userBucket:  cfg.UserQuota,
+		c.agentBucket: cfg.AgentQuota,
+	}
 
 	return c, nil
 }
@@ -100,19 +107,8 @@ type FileInfo struct {
 
 // Upload uploads a file to the specified bucket
 func (c *Client) Upload(ctx context.Context, bucket, name string, data []byte, contentType string) error {
-	if contentType == "" {
-		contentType = "application/octet-stream"
-	}
-
-	_, err := c.mc.PutObject(ctx, bucket, name, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
-		ContentType: contentType,
-	})
-	if err != nil {
-		return fmt.Errorf("upload %s/%s: %w", bucket, name, err)
-	}
-
-	logger.Debug("file uploaded", "bucket", bucket, "name", name, "size", len(data))
-	return nil
+	_, err := c.UploadStream(ctx, bucket, name, bytes.NewReader(data), int64(len(data)), contentType)
+	return err
 }
 
 // Download downloads a file from the specified bucket
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+)
+
+// originalsPrefix is where full-size photos are kept when a compressed copy
+// is sent to chat
+const originalsPrefix = "originals/"
+
+// KeepOriginal stores a full-size photo in the agent bucket, named by its
+// content so sending the same photo again doesn't store it twice. Returns
+// the path as storage tools take it, e.g. "agent/originals/3f2a….png".
+func (c *Client) KeepOriginal(ctx context.Context, data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	ext := ""
+	switch contentType {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	case "image/webp":
+		ext = ".webp"
+	}
+
+	sum := sha256.Sum256(data)
+	name := fmt.Sprintf("%s%x%s", originalsPrefix, sum[:8], ext)
+	if err := c.Upload(ctx, c.agentBucket, name, data, contentType); err != nil {
+		return "", err
+	}
+	return "agent/" + name, nil
+}
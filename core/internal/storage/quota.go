@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// ErrQuotaExceeded is returned by Upload when a file doesn't fit in its
+// bucket's quota and the bucket isn't allowed to prune
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// Quota caps how much a bucket may hold
+type Quota struct {
+	MaxBytes    int64 // 0 = unlimited
+	PruneOldest bool  // delete the oldest files to make room instead of refusing uploads
+}
+
+// PrefixUsage is how much is stored under one top-level folder of a bucket
+type PrefixUsage struct {
+	Prefix string // "" for files at the bucket root
+	Size   int64
+	Files  int64
+}
+
+// object is the part of a stored object quota checks look at
+type object struct {
+	name     string
+	size     int64
+	modified time.Time
+}
+
+// Quota returns the quota set for a bucket, if any
+func (c *Client) Quota(bucket string) (Quota, bool) {
+	q, ok := c.quotas[bucket]
+	return q, ok && q.MaxBytes > 0
+}
+
+func (c *Client) objects(ctx context.Context, bucket string) ([]object, error) {
+	var objs []object
+	for obj := range c.mc.ListObjects(ctx, bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("list %s: %w", bucket, obj.Err)
+		}
+		objs = append(objs, object{name: obj.Key, size: obj.Size, modified: obj.LastModified})
+	}
+	return objs, nil
+}
+
+// makeRoom checks an upload against the bucket's quota, pruning the oldest
+// files first when the bucket allows it
+func (c *Client) makeRoom(ctx context.Context, bucket, name string, size int64) error {
+	quota, ok := c.Quota(bucket)
+	if !ok {
+		return nil
+	}
+
+	objs, err := c.objects(ctx, bucket)
+	if err != nil {
+		return err
+	}
+
+	prune, fits := planPrune(objs, name, size, quota.MaxBytes)
+	if !fits {
+		return fmt.Errorf("%w: %s is %s, over %s's %s limit", ErrQuotaExceeded, name, formatBytes(size), bucket, formatBytes(quota.MaxBytes))
+	}
+	if len(prune) == 0 {
+		return nil
+	}
+	if !quota.PruneOldest {
+		return fmt.Errorf("%w: %s holds %s of %s, %s more won't fit; delete files first", ErrQuotaExceeded, bucket, formatBytes(usedBytes(objs, name)), formatBytes(quota.MaxBytes), formatBytes(size))
+	}
+
+	for _, old := range prune {
+		if err := c.Delete(ctx, bucket, old); err != nil {
+			return err
+		}
+		logger.Info("pruned file for storage quota", "bucket", bucket, "name", old)
+	}
+	return nil
+}
+
+// planPrune picks the oldest files to delete so size more bytes fit under
+// limit. The file being overwritten doesn't count, and a file bigger than the
+// whole quota never fits.
+func planPrune(objs []object, name string, size, limit int64) ([]string, bool) {
+	if size > limit {
+		return nil, false
+	}
+
+	used := usedBytes(objs, name)
+	if used+size <= limit {
+		return nil, true
+	}
+
+	sorted := slices.Clone(objs)
+	slices.SortFunc(sorted, func(a, b object) int { return a.modified.Compare(b.modified) })
+
+	var prune []string
+	for _, o := range sorted {
+		if o.name == name {
+			continue
+		}
+		prune = append(prune, o.name)
+		used -= o.size
+		if used+size <= limit {
+			break
+		}
+	}
+	return prune, true
+}
+
+func usedBytes(objs []object, except string) int64 {
+	var used int64
+	for _, o := range objs {
+		if o.name != except {
+			used += o.size
+		}
+	}
+	return used
+}
+
+// Usage totals a bucket by top-level folder, largest first
+func (c *Client) Usage(ctx context.Context, bucket string) ([]PrefixUsage, error) {
+	objs, err := c.objects(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	return usageByPrefix(objs), nil
+}
+
+func usageByPrefix(objs []object) []PrefixUsage {
+	byPrefix := make(map[string]*PrefixUsage)
+	for _, o := range objs {
+		prefix := ""
+		if dir, _, ok := strings.Cut(o.name, "/"); ok {
+			prefix = dir + "/"
+		}
+		u, ok := byPrefix[prefix]
+		if !ok {
+			u = &PrefixUsage{Prefix: prefix}
+			byPrefix[prefix] = u
+		}
+		u.Size += o.size
+		u.Files++
+	}
+
+	usage := make([]PrefixUsage, 0, len(byPrefix))
+	for _, u := range byPrefix {
+		usage = append(usage, *u)
+	}
+	slices.SortFunc(usage, func(a, b PrefixUsage) int {
+		if a.Size != b.Size {
+			return cmp.Compare(b.Size, a.Size)
+		}
+		return cmp.Compare(a.Prefix, b.Prefix)
+	})
+	return usage
+}
+
+// FormatBytes renders a size the way quota messages show it
+func formatBytes(n int64) string {
+	const (
+		kb = 1024
+		mb = kb * 1024
+		gb = mb * 1024
+	)
+
+	switch {
+	case n >= gb:
+		return fmt.Sprintf("%.1f GB", float64(n)/gb)
+	case n >= mb:
+		return fmt.Sprintf("%.1f MB", float64(n)/mb)
+	case n >= kb:
+		return fmt.Sprintf("%.1f KB", float64(n)/kb)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPlanPrune(t *testing.T) {
+	now := time.Now()
+	objs := []object{
+		{name: "notes/new.md", size: 300, modified: now},
+		{name: "downloads/old.pdf", size: 400, modified: now.Add(-2 * time.Hour)},
+		{name: "downloads/older.pdf", size: 200, modified: now.Add(-3 * time.Hour)},
+		{name: "report.pdf", size: 100, modified: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		file      string
+		size      int64
+		wantPrune []string
+		wantFits  bool
+	}{
+		{"fits", "a.txt", 0, nil, true},
+		{"prunes oldest first", "a.txt", 500, []string{"downloads/older.pdf", "downloads/old.pdf"}, true},
+		{"overwrite frees its own space", "notes/new.md", 300, nil, true},
+		{"bigger than the quota", "huge.iso", 1001, nil, false},
+	}
+	for _, tt := range tests {
+		prune, fits := planPrune(objs, tt.file, tt.size, 1000)
+		if fits != tt.wantFits || !slices.Equal(prune, tt.wantPrune) {
+			t.Errorf("%s: got %v, %v, want %v, %v", tt.name, prune, fits, tt.wantPrune, tt.wantFits)
+		}
+	}
+}
+
+func TestUsageByPrefix(t *testing.T) {
+	usage := usageByPrefix([]object{
+		{name: "downloads/a.pdf", size: 400},
+		{name: "downloads/sub/b.pdf", size: 200},
+		{name: "notes/c.md", size: 50},
+		{name: "readme.md", size: 10},
+	})
+
+	want := []PrefixUsage{
+		{Prefix: "downloads/", Size: 600, Files: 2},
+		{Prefix: "notes/", Size: 50, Files: 1},
+		{Prefix: "", Size: 10, Files: 1},
+	}
+	if !slices.Equal(usage, want) {
+		t.Errorf("usage = %+v, want %+v", usage, want)
+	}
+}
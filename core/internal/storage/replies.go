@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+const (
+	// repliesPrefix is where replies too long to post in chat are kept
+	repliesPrefix = "replies/"
+	// replyLinkExpiry is the longest a presigned link can last
+	replyLinkExpiry = 7 * 24 * time.Hour
+)
+
+// ShareText stores a reply too long for chat in the agent bucket and returns
+// a public link to it, valid for a week. It's saved as plain text so the
+// link opens in the browser rather than downloading.
+func (c *Client) ShareText(ctx context.Context, text string) (string, error) {
+	sum := sha256.Sum256([]byte(text))
+	name := fmt.Sprintf("%s%s-%x.md", repliesPrefix, time.Now().Format("20060102-150405"), sum[:4])
+	if err := c.Upload(ctx, c.agentBucket, name, []byte(text), "text/plain; charset=utf-8"); err != nil {
+		return "", err
+	}
+	return c.PublicPresignedURL(ctx, c.agentBucket, name, replyLinkExpiry)
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// partSize is the multipart chunk size. Uploads hold about one part per
+// upload thread in memory, whatever the file size; without it minio-go sizes
+// parts for a 5 TiB object when the length is unknown.
+const partSize = 16 << 20
+
+// ErrTooLarge is returned when a stream runs past the limit it was read with
+var ErrTooLarge = errors.New("file too large")
+
+// UploadStream uploads from r without buffering the whole file. Pass size -1
+// when the length isn't known up front; the quota is then checked once the
+// upload is done and the object removed if it doesn't fit. Returns the
+// number of bytes stored.
+func (c *Client) UploadStream(ctx context.Context, bucket, name string, r io.Reader, size int64, contentType string) (int64, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if size >= 0 {
+		if err := c.makeRoom(ctx, bucket, name, size); err != nil {
+			return 0, err
+		}
+	}
+
+	info, err := c.mc.PutObject(ctx, bucket, name, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    partSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("upload %s/%s: %w", bucket, name, err)
+	}
+
+	if size < 0 {
+		if err := c.makeRoom(ctx, bucket, name, info.Size); err != nil {
+			if derr := c.Delete(ctx, bucket, name); derr != nil {
+				logger.Warn("failed to remove upload over quota", "bucket", bucket, "name", name, "error", derr)
+			}
+			return 0, err
+		}
+	}
+
+	logger.Debug("file uploaded", "bucket", bucket, "name", name, "size", info.Size)
+	return info.Size, nil
+}
+
+// UploadFile streams a local file into a bucket
+func (c *Client) UploadFile(ctx context.Context, bucket, name, path, contentType string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return c.UploadStream(ctx, bucket, name, f, stat.Size(), contentType)
+}
+
+// DownloadStream opens an object for reading along with its size. The
+// caller closes it.
+func (c *Client) DownloadStream(ctx context.Context, bucket, name string) (io.ReadCloser, int64, error) {
+	obj, err := c.mc.GetObject(ctx, bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("get %s/%s: %w", bucket, name, err)
+	}
+
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, 0, fmt.Errorf("stat %s/%s: %w", bucket, name, err)
+	}
+	return obj, stat.Size, nil
+}
+
+// DownloadFile streams an object to a local file, replacing it only once
+// the download is complete
+func (c *Client) DownloadFile(ctx context.Context, bucket, name, path string) (int64, error) {
+	obj, _, err := c.DownloadStream(ctx, bucket, name)
+	if err != nil {
+		return 0, err
+	}
+	defer obj.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, obj)
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("read %s/%s: %w", bucket, name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return 0, err
+	}
+	return n, os.Rename(tmp.Name(), path)
+}
+
+// LimitReader reads at most limit bytes from r and fails with ErrTooLarge
+// rather than truncating when there's more
+func LimitReader(r io.Reader, limit int64) io.Reader {
+	return &limitedReader{r: r, left: limit}
+}
+
+type limitedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLimitReader(t *testing.T) {
+	data, err := io.ReadAll(LimitReader(strings.NewReader("hello"), 5))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("at the limit: %q, %v", data, err)
+	}
+
+	_, err = io.ReadAll(LimitReader(strings.NewReader("hello!"), 5))
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("over the limit: err = %v, want ErrTooLarge", err)
+	}
+}
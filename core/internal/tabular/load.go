@@ -0,0 +1,347 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// MaxRows caps how much of a file is loaded, queries run in memory
+const MaxRows = 200_000
+
+// Load reads a CSV or XLSX file. name is only used to tell the formats apart
+// when the content doesn't; sheet picks an xlsx sheet by name (default: first).
+func Load(data []byte, name, sheet string) (*Table, error) {
+	if IsXLSX(data, name) {
+		return loadXLSX(data, sheet)
+	}
+	return loadCSV(data)
+}
+
+// IsXLSX reports whether a file is an Excel workbook rather than delimited text
+func IsXLSX(data []byte, name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == ".xls" || ext == ".xlsm" || ext == ".xlsx" {
+		return true
+	}
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+func loadCSV(data []byte) (*Table, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = sniffDelimiter(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var records [][]string
+	for len(records) <= MaxRows {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse csv: %w", err)
+		}
+		records = append(records, record)
+	}
+	return newTable(records, "")
+}
+
+// sniffDelimiter picks whichever of comma, semicolon or tab is most common
+// in the header line, since European exports often use semicolons
+func sniffDelimiter(data []byte) rune {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(line, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// newTable uses the first non-empty record as the header and pads the rest
+func newTable(records [][]string, sheet string) (*Table, error) {
+	for len(records) > 0 && isBlank(records[0]) {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file has no rows")
+	}
+
+	width := 0
+	for _, record := range records {
+		width = max(width, len(record))
+	}
+
+	t := &Table{Columns: headers(records[0], width), Sheet: sheet}
+	for _, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		if len(t.Rows) == MaxRows {
+			return nil, fmt.Errorf("file has more than %d rows, too large to analyze in chat", MaxRows)
+		}
+		row := make([]string, width)
+		copy(row, record)
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// headers names unnamed columns col_N and numbers duplicates so every column
+// can be referenced in a query
+func headers(record []string, width int) []string {
+	names := make([]string, width)
+	seen := make(map[string]int)
+	for i := range names {
+		name := ""
+		if i < len(record) {
+			name = strings.Join(strings.Fields(record[i]), " ")
+		}
+		if name == "" {
+			name = fmt.Sprintf("col_%d", i+1)
+		}
+		key := strings.ToLower(name)
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		names[i] = name
+	}
+	return names
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func loadXLSX(data []byte, sheet string) (*Table, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open xlsx: %w (old .xls files aren't supported, save as .xlsx or .csv)", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := readXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, errors.New("workbook has no sheets")
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+
+	chosen := workbook.Sheets[0]
+	if sheet != "" {
+		found := false
+		var names []string
+		for _, s := range workbook.Sheets {
+			names = append(names, s.Name)
+			if strings.EqualFold(s.Name, sheet) {
+				chosen, found = s, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no sheet named %q, sheets: %s", sheet, strings.Join(names, ", "))
+		}
+	}
+
+	target := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == chosen.RID {
+			target = rel.Target
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("sheet %q not found in workbook", chosen.Name)
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	shared, err := sharedStrings(files)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := sheetRecords(files, target, shared)
+	if err != nil {
+		return nil, err
+	}
+	return newTable(records, chosen.Name)
+}
+
+func readXML(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("xlsx is missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// sharedStrings reads the string table that most text cells point into.
+// Rich text runs (<r><t>) are joined into one string.
+func sharedStrings(files map[string]*zip.File) ([]string, error) {
+	if _, ok := files["xl/sharedStrings.xml"]; !ok {
+		return nil, nil
+	}
+	var sst struct {
+		Items []struct {
+			T    string   `xml:"t"`
+			Runs []string `xml:"r>t"`
+		} `xml:"si"`
+	}
+	if err := readXML(files, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+	shared := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		shared[i] = item.T + strings.Join(item.Runs, "")
+	}
+	return shared, nil
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		T    string   `xml:"t"`
+		Runs []string `xml:"r>t"`
+	} `xml:"is"`
+}
+
+// sheetRecords streams the sheet's rows so large sheets don't need a full DOM
+func sheetRecords(files map[string]*zip.File, name string, shared []string) ([][]string, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("xlsx is missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("read sheet: %w", err)
+	}
+	defer rc.Close()
+
+	var records [][]string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse sheet: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row struct {
+			Ref   int        `xml:"r,attr"`
+			Cells []xlsxCell `xml:"c"`
+		}
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("parse sheet row: %w", err)
+		}
+
+		// rows and cells may be skipped when empty, refs say where they belong
+		if row.Ref > len(records)+1 && row.Ref <= MaxRows+1 {
+			records = append(records, make([][]string, row.Ref-len(records)-1)...)
+		}
+		var record []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			if col < len(record) || col > 16383 {
+				continue
+			}
+			for len(record) < col {
+				record = append(record, "")
+			}
+			record = append(record, cellValue(c, shared))
+		}
+		records = append(records, record)
+		if len(records) > MaxRows+1 {
+			return nil, fmt.Errorf("sheet has more than %d rows, too large to analyze in chat", MaxRows)
+		}
+	}
+	return records, nil
+}
+
+func cellValue(c xlsxCell, shared []string) string {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+		return shared[i]
+	case "inlineStr":
+		return c.Inline.T + strings.Join(c.Inline.Runs, "")
+	case "b":
+		if c.Value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return c.Value
+	}
+}
+
+// columnIndex turns a cell reference like "AB12" into a zero-based column
+func columnIndex(ref string) int {
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
@@ -0,0 +1,246 @@
+package tabular
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ncruces/go-sqlite3"
+)
+
+// maxCellWidth keeps one long text cell from crowding out the rest of a table
+const maxCellWidth = 80
+
+// blockedFunctions can reach outside the database even in a read-only query
+var blockedFunctions = map[string]bool{
+	"load_extension": true, "readfile": true, "writefile": true, "edit": true, "fts3_tokenizer": true,
+}
+
+// Open loads a table into a private in-memory database. Once loaded the
+// connection only allows reads, so queries from the LLM can't modify or
+// attach anything.
+func Open(t *Table) (*DB, error) {
+	if len(t.Columns) == 0 {
+		return nil, errors.New("table has no columns")
+	}
+
+	conn, err := sqlite3.Open(":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	db := &DB{conn: conn, columns: inferColumns(t), rows: len(t.Rows)}
+	if err := db.load(t); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.Limit(sqlite3.LIMIT_ATTACHED, 0)
+	err = conn.SetAuthorizer(func(action sqlite3.AuthorizerActionCode, _, name4th, _, _ string) sqlite3.AuthorizerReturnCode {
+		switch action {
+		case sqlite3.AUTH_SELECT, sqlite3.AUTH_READ, sqlite3.AUTH_RECURSIVE:
+			return sqlite3.AUTH_OK
+		case sqlite3.AUTH_FUNCTION:
+			if blockedFunctions[strings.ToLower(name4th)] {
+				return sqlite3.AUTH_DENY
+			}
+			return sqlite3.AUTH_OK
+		}
+		return sqlite3.AUTH_DENY
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("restrict database: %w", err)
+	}
+	return db, nil
+}
+
+// Close releases the database
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
+
+// Columns returns the loaded columns with their inferred types
+func (db *DB) Columns() []Column {
+	return db.columns
+}
+
+// RowCount is the number of data rows loaded
+func (db *DB) RowCount() int {
+	return db.rows
+}
+
+func (db *DB) load(t *Table) error {
+	defs := make([]string, len(db.columns))
+	marks := make([]string, len(db.columns))
+	for i, c := range db.columns {
+		defs[i] = quoteIdent(c.Name) + " " + c.Type
+		marks[i] = "?"
+	}
+	if err := db.conn.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", TableName, strings.Join(defs, ", "))); err != nil {
+		return fmt.Errorf("create table: %w", err)
+	}
+
+	stmt, _, err := db.conn.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", TableName, strings.Join(marks, ", ")))
+	if err != nil {
+		return fmt.Errorf("prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	if err := db.conn.Exec("BEGIN"); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		for i, c := range db.columns {
+			if err := bind(stmt, i+1, c.Type, row[i]); err != nil {
+				return fmt.Errorf("load row: %w", err)
+			}
+		}
+		if err := stmt.Exec(); err != nil {
+			return fmt.Errorf("load row: %w", err)
+		}
+	}
+	return db.conn.Exec("COMMIT")
+}
+
+func bind(stmt *sqlite3.Stmt, param int, typ, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return stmt.BindNull(param)
+	}
+	switch typ {
+	case "INTEGER":
+		n, _ := strconv.ParseInt(cleanNumber(value), 10, 64)
+		return stmt.BindInt64(param, n)
+	case "REAL":
+		f, _ := strconv.ParseFloat(cleanNumber(value), 64)
+		return stmt.BindFloat(param, f)
+	}
+	return stmt.BindText(param, value)
+}
+
+// inferColumns types a column as a number when every non-empty value is one,
+// so SUM and comparisons work without casts
+func inferColumns(t *Table) []Column {
+	columns := make([]Column, len(t.Columns))
+	for i, name := range t.Columns {
+		typ, seen := "INTEGER", false
+		for _, row := range t.Rows {
+			v := strings.TrimSpace(row[i])
+			if v == "" {
+				continue
+			}
+			seen = true
+			v = cleanNumber(v)
+			if typ == "INTEGER" {
+				if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+					continue
+				}
+				typ = "REAL"
+			}
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				typ = "TEXT"
+				break
+			}
+		}
+		if !seen {
+			typ = "TEXT"
+		}
+		columns[i] = Column{Name: name, Type: typ}
+	}
+	return columns
+}
+
+// cleanNumber drops thousands separators, e.g. "1,250.00" -> "1250.00"
+func cleanNumber(v string) string {
+	if strings.Contains(v, ",") && strings.Contains(v, ".") {
+		return strings.ReplaceAll(v, ",", "")
+	}
+	return v
+}
+
+// Query runs a single SELECT and returns up to maxRows rows. The query is
+// interrupted when ctx is done.
+func (db *DB) Query(ctx context.Context, query string, maxRows int) (*Result, error) {
+	query = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(query), ";"))
+	if query == "" {
+		return nil, errors.New("empty query")
+	}
+
+	old := db.conn.SetInterrupt(ctx)
+	defer db.conn.SetInterrupt(old)
+
+	stmt, tail, err := db.conn.Prepare(query)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer stmt.Close()
+	if strings.TrimSpace(strings.Trim(tail, "; \n\t")) != "" {
+		return nil, errors.New("run one query at a time")
+	}
+	if !stmt.ReadOnly() || stmt.ColumnCount() == 0 {
+		return nil, errors.New("only SELECT queries are allowed")
+	}
+
+	result := &Result{Columns: make([]string, stmt.ColumnCount())}
+	for i := range result.Columns {
+		result.Columns[i] = stmt.ColumnName(i)
+	}
+	for stmt.Step() {
+		if len(result.Rows) == maxRows {
+			result.Truncated = true
+			break
+		}
+		row := make([]string, len(result.Columns))
+		for i := range row {
+			if stmt.ColumnType(i) != sqlite3.NULL {
+				row[i] = stmt.ColumnText(i)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := stmt.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("query took too long: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	return result, nil
+}
+
+// String formats the result as a pipe-separated table
+func (r *Result) String() string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString(" | ")
+			}
+			cell = strings.Join(strings.Fields(cell), " ")
+			if len(cell) > maxCellWidth {
+				cell = cell[:maxCellWidth] + "…"
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(r.Columns)
+	for i, c := range r.Columns {
+		if i > 0 {
+			b.WriteString("-|-")
+		}
+		b.WriteString(strings.Repeat("-", max(3, min(len(c), maxCellWidth))))
+	}
+	b.WriteString("\n")
+	for _, row := range r.Rows {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,145 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+const sampleCSV = "\xef\xbb\xbfdate;category;amount;note\n" +
+	"2026-01-03;groceries;42.50;\n" +
+	"2026-01-05;rent;1,250.00;january\n" +
+	"\n" +
+	"2026-01-09;groceries;17.25;\"market; cash\"\n"
+
+func TestLoadCSV(t *testing.T) {
+	table, err := Load([]byte(sampleCSV), "spending.csv", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(table.Columns, ","); got != "date,category,amount,note" {
+		t.Errorf("columns = %s", got)
+	}
+	if len(table.Rows) != 3 {
+		t.Fatalf("rows = %d, want 3", len(table.Rows))
+	}
+	if table.Rows[2][3] != "market; cash" {
+		t.Errorf("quoted cell = %q", table.Rows[2][3])
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	got := headers([]string{"Name", "", "name", " Total  Spent "}, 5)
+	want := "Name,col_2,name_2,Total Spent,col_5"
+	if strings.Join(got, ",") != want {
+		t.Errorf("headers = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	table, err := Load([]byte(sampleCSV), "spending.csv", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := Open(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	types := make([]string, 0, len(db.Columns()))
+	for _, c := range db.Columns() {
+		types = append(types, c.Type)
+	}
+	if got := strings.Join(types, ","); got != "TEXT,TEXT,REAL,TEXT" {
+		t.Errorf("types = %s", got)
+	}
+
+	result, err := db.Query(context.Background(), "SELECT category, SUM(amount) AS total FROM data GROUP BY category ORDER BY total DESC;", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "category | total\n---------|------\nrent | 1250.0\ngroceries | 59.75"
+	if got := result.String(); got != want {
+		t.Errorf("result =\n%s\nwant\n%s", got, want)
+	}
+
+	result, err = db.Query(context.Background(), "SELECT * FROM data", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 2 || !result.Truncated {
+		t.Errorf("rows = %d, truncated = %v", len(result.Rows), result.Truncated)
+	}
+}
+
+func TestQueryRejectsWrites(t *testing.T) {
+	db, err := Open(&Table{Columns: []string{"a"}, Rows: [][]string{{"1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, q := range []string{
+		"DELETE FROM data",
+		"DROP TABLE data",
+		"SELECT 1; DELETE FROM data",
+		"ATTACH DATABASE '/tmp/x.db' AS x",
+		"PRAGMA table_info(data)",
+		"CREATE TABLE t AS SELECT * FROM data",
+	} {
+		if _, err := db.Query(context.Background(), q, 10); err == nil {
+			t.Errorf("%q was allowed", q)
+		}
+	}
+
+	result, err := db.Query(context.Background(), "SELECT COUNT(*) FROM data", 10)
+	if err != nil || result.Rows[0][0] != "1" {
+		t.Errorf("table changed: %v %v", result, err)
+	}
+}
+
+func TestLoadXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Sales" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>region</t></si><si><t>units</t></si><si><r><t>No</t></r><r><t>rth</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>nothing</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>7</v></c></row>
+<row r="4"><c r="B4"><v>12</v></c></row>
+</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	table, err := Load(buf.Bytes(), "report.xlsx", "sales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Sheet != "Sales" {
+		t.Errorf("sheet = %q", table.Sheet)
+	}
+	if got := strings.Join(table.Columns, ","); got != "region,units,col_3" {
+		t.Errorf("columns = %s", got)
+	}
+	if len(table.Rows) != 2 || strings.Join(table.Rows[0], ",") != "North,,7" || strings.Join(table.Rows[1], ",") != ",12," {
+		t.Errorf("rows = %q", table.Rows)
+	}
+
+	if _, err := Load(buf.Bytes(), "report.xlsx", "missing"); err == nil || !strings.Contains(err.Error(), "Summary, Sales") {
+		t.Errorf("missing sheet error = %v", err)
+	}
+}
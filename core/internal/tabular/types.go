@@ -0,0 +1,33 @@
+package tabular
+
+import "github.com/ncruces/go-sqlite3"
+
+// TableName is what queries select from
+const TableName = "data"
+
+// Table is a spreadsheet or CSV file as a header row and string cells
+type Table struct {
+	Columns []string
+	Rows    [][]string
+	Sheet   string // xlsx only, the sheet that was loaded
+}
+
+// Column is a loaded column and the SQL type inferred from its values
+type Column struct {
+	Name string
+	Type string // INTEGER, REAL or TEXT
+}
+
+// DB is an in-memory SQLite database holding one table, locked to read-only queries
+type DB struct {
+	conn    *sqlite3.Conn
+	columns []Column
+	rows    int
+}
+
+// Result is the output of a query
+type Result struct {
+	Columns   []string
+	Rows      [][]string
+	Truncated bool // more rows matched than were returned
+}
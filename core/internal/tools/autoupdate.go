@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/autoupdate"
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+type SetAutoUpdateArgs struct {
+	App        string `json:"app"`
+	Repo       string `json:"repo"`
+	Branch     string `json:"branch,omitempty"`
+	OnPush     *bool  `json:"on_push,omitempty"`
+	EveryHours int    `json:"every_hours,omitempty"`
+}
+
+type AutoUpdateAppArgs struct {
+	App string `json:"app"`
+}
+
+// RegisterAutoUpdateTools registers set_auto_update, disable_auto_update and list_auto_updates
+func RegisterAutoUpdateTools(registry *Registry, updater *autoupdate.Updater) {
+	setTool := llm.Tool{
+		Name:        "set_auto_update",
+		Description: "Keep a deployed app up to date with its git repo: Sheldon pulls, rebuilds and redeploys it when the branch gets new commits and/or on a fixed schedule, then sends a summary of what changed. The app keeps the env, port, volumes and replicas it was last deployed with.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name as used with deploy_app",
+				},
+				"repo": map[string]any{
+					"type":        "string",
+					"description": "Repository name in the configured git org",
+				},
+				"branch": map[string]any{
+					"type":        "string",
+					"description": "Branch to follow (default: the repo's default branch)",
+				},
+				"on_push": map[string]any{
+					"type":        "boolean",
+					"description": "Redeploy when the branch gets new commits (default: true)",
+				},
+				"every_hours": map[string]any{
+					"type":        "integer",
+					"description": "Also rebuild every N hours even without new commits, e.g. 168 for weekly base image updates (default: 0 = off)",
+				},
+			},
+			"required": []string{"app", "repo"},
+		},
+	}
+
+	registry.Register(setTool, func(ctx context.Context, args string) (string, error) {
+		var params SetAutoUpdateArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		onPush := params.OnPush == nil || *params.OnPush
+		w, err := updater.Enable(ctx, autoupdate.Watch{
+			App:        params.App,
+			Repo:       params.Repo,
+			Branch:     params.Branch,
+			OnPush:     onPush,
+			EveryHours: params.EveryHours,
+			ChatID:     ChatIDFromContext(ctx),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("🔄 Auto-update enabled for %s: %s. Currently at %s@%s.", w.App, describeTriggers(*w), w.Branch, shortCommit(w.LastCommit)), nil
+	})
+
+	disableTool := llm.Tool{
+		Name:        "disable_auto_update",
+		Description: "Stop automatically redeploying an app. The app keeps running as it is.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name",
+				},
+			},
+			"required": []string{"app"},
+		},
+	}
+
+	registry.Register(disableTool, func(ctx context.Context, args string) (string, error) {
+		var params AutoUpdateAppArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if err := updater.Store().Delete(params.App); err != nil {
+			if errors.Is(err, autoupdate.ErrNotFound) {
+				return fmt.Sprintf("Auto-update isn't enabled for %s.", params.App), nil
+			}
+			return "", err
+		}
+
+		return fmt.Sprintf("Auto-update disabled for %s.", params.App), nil
+	})
+
+	listTool := llm.Tool{
+		Name:        "list_auto_updates",
+		Description: "List apps that are redeployed automatically, what they follow and how their last update went.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(listTool, func(ctx context.Context, args string) (string, error) {
+		watches, err := updater.Store().All()
+		if err != nil {
+			return "", err
+		}
+
+		if len(watches) == 0 {
+			return "No apps have auto-update enabled.", nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString("Auto-updating apps:\n")
+		for _, w := range watches {
+			sb.WriteString(fmt.Sprintf("\n%s ← %s@%s (%s)\n", w.App, w.Repo, w.Branch, describeTriggers(w)))
+			sb.WriteString(fmt.Sprintf("  deployed commit: %s\n", shortCommit(w.LastCommit)))
+			if w.LastDeployed != nil {
+				sb.WriteString(fmt.Sprintf("  last auto-update: %s\n", w.LastDeployed.Format("2006-01-02 15:04")))
+			}
+			if w.LastError != "" {
+				sb.WriteString(fmt.Sprintf("  ❌ %s failed: %s\n", shortCommit(w.FailedCommit), w.LastError))
+			}
+		}
+
+		return sb.String(), nil
+	})
+}
+
+func describeTriggers(w autoupdate.Watch) string {
+	var triggers []string
+	if w.OnPush {
+		triggers = append(triggers, "on new commits")
+	}
+	if w.EveryHours > 0 {
+		triggers = append(triggers, fmt.Sprintf("every %dh", w.EveryHours))
+	}
+	return strings.Join(triggers, " and ")
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
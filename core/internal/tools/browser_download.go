@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/browser"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/storage"
+)
+
+// RegisterBrowserDownloadTool registers browse_download, which clicks a download link
+// in the browser sandbox and saves the file to storage
+func RegisterBrowserDownloadTool(registry *Registry, runner *browser.Runner, client *storage.Client) {
+	tool := llm.Tool{
+		Name:        "browse_download",
+		Description: "Download a file that a page only offers through a button or JavaScript link: opens the page in the browser sandbox, clicks the element and saves the downloaded file to storage. For direct file URLs use fetch_url instead.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url": map[string]any{
+					"type":        "string",
+					"description": "The page containing the download link",
+				},
+				"ref": map[string]any{
+					"type":        "string",
+					"description": "Element reference of the download link or button from browse (e.g., @e5)",
+				},
+				"space": map[string]any{
+					"type":        "string",
+					"enum":        []string{"user", "agent"},
+					"description": "Storage space: 'user' for user files, 'agent' for agent files",
+				},
+				"path": map[string]any{
+					"type":        "string",
+					"description": "Destination path in storage (e.g., 'downloads/report.pdf')",
+				},
+			},
+			"required": []string{"url", "ref", "space", "path"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			URL   string `json:"url"`
+			Ref   string `json:"ref"`
+			Space string `json:"space"`
+			Path  string `json:"path"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid params: %w", err)
+		}
+		if params.Ref == "" || params.Path == "" {
+			return "", fmt.Errorf("ref and path are required")
+		}
+
+		logger.Debug("browse_download", "url", params.URL, "ref", params.Ref)
+
+		data, err := runner.Download(ctx, params.URL, params.Ref)
+		if err != nil {
+			return "", fmt.Errorf("download: %w", err)
+		}
+
+		bucket := client.UserBucket()
+		if params.Space == "agent" {
+			bucket = client.AgentBucket()
+		}
+
+		contentType := guessContentType(params.Path)
+		if contentType == "application/octet-stream" {
+			contentType = http.DetectContentType(data)
+		}
+
+		path := strings.TrimPrefix(params.Path, "/")
+		if err := client.Upload(ctx, bucket, path, data, contentType); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("📥 downloaded %s from %s to %s/%s (%d bytes, %s)", params.Ref, params.URL, params.Space, path, len(data), contentType), nil
+	})
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bowerhall/sheldon/internal/browser"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// RegisterScreenshotTool registers browse_screenshot, which captures a page in the
+// browser sandbox and sends the PNG straight to the user
+func RegisterScreenshotTool(registry *Registry, runner *browser.Runner, sender PhotoSender) {
+	tool := llm.Tool{
+		Name:        "browse_screenshot",
+		Description: "Take a screenshot of a web page in the browser sandbox and send it to the user as an image. Use when the user asks what a page looks like, or to check that a deployed app renders. Without url, captures the page last opened with browse.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url": map[string]any{
+					"type":        "string",
+					"description": "The URL to capture (default: the current page from browse)",
+				},
+				"full_page": map[string]any{
+					"type":        "boolean",
+					"description": "Capture the whole scrollable page instead of just the visible viewport",
+				},
+				"caption": map[string]any{
+					"type":        "string",
+					"description": "Optional caption for the image (default: the URL)",
+				},
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			URL      string `json:"url"`
+			FullPage bool   `json:"full_page"`
+			Caption  string `json:"caption"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid params: %w", err)
+			}
+		}
+
+		if params.URL == "" {
+			params.URL = lastBrowsed.get(SessionIDFromContext(ctx))
+			if params.URL == "" {
+				return "", fmt.Errorf("no page open yet, pass a url or browse to one first")
+			}
+		}
+
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		logger.Debug("browse_screenshot", "url", params.URL, "fullPage", params.FullPage)
+
+		png, err := runner.Screenshot(ctx, params.URL, params.FullPage)
+		if err != nil {
+			return "", fmt.Errorf("screenshot: %w", err)
+		}
+
+		caption := params.Caption
+		if caption == "" {
+			caption = params.URL
+		}
+		if err := sender.SendPhoto(chatID, png, caption); err != nil {
+			return "", fmt.Errorf("send screenshot: %w", err)
+		}
+
+		return fmt.Sprintf("📸 sent screenshot of %s to the user (%d KB)", params.URL, len(png)/1024), nil
+	})
+}
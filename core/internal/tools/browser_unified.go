@@ -30,6 +30,21 @@ func DefaultBrowserConfig() BrowserConfig {
 	}
 }
 
+// lastBrowsed is shared by browse and browse_screenshot, which are registered separately
+var lastBrowsed = &browsedPages{urls: make(map[string]string)}
+
+func (p *browsedPages) set(sessionID, url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.urls[sessionID] = url
+}
+
+func (p *browsedPages) get(sessionID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.urls[sessionID]
+}
+
 // RegisterUnifiedBrowserTools registers browser tools that prefer sandbox, fallback to HTTP
 func RegisterUnifiedBrowserTools(registry *Registry, runner *browser.Runner, httpCfg BrowserConfig) {
 	client := &http.Client{
@@ -72,6 +87,7 @@ func RegisterUnifiedBrowserTools(registry *Registry, runner *browser.Runner, htt
 		if runner != nil {
 			result, err := runner.Browse(ctx, params.URL)
 			if err == nil {
+				lastBrowsed.set(SessionIDFromContext(ctx), params.URL)
 				if len(result) > 15000 {
 					result = result[:15000] + "\n\n[Content truncated...]"
 				}
@@ -81,9 +97,16 @@ func RegisterUnifiedBrowserTools(registry *Registry, runner *browser.Runner, htt
 		}
 
 		// fallback to HTTP fetch
-		return httpFetch(ctx, client, httpCfg.UserAgent, params.URL)
+		result, err := httpFetch(ctx, client, httpCfg.UserAgent, params.URL)
+		if err != nil {
+			return "", err
+		}
+		lastBrowsed.set(SessionIDFromContext(ctx), params.URL)
+		return result, nil
 	})
 
+	registry.Cacheable("browse", 5*time.Minute)
+
 	// browse_click - only works with sandbox
 	if runner != nil {
 		clickTool := llm.Tool{
@@ -214,6 +237,7 @@ func RegisterUnifiedBrowserTools(registry *Registry, runner *browser.Runner, htt
 
 		return wrapUntrustedContent(extractSearchResults(string(body))), nil
 	})
+	registry.Cacheable("search_web", 10*time.Minute)
 }
 
 func httpFetch(ctx context.Context, client *http.Client, userAgent, targetURL string) (string, error) {
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxCachedResults bounds memory use; browse results can be large
+const maxCachedResults = 256
+
+func newResultCache(capacity int) *resultCache {
+	return &resultCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *resultCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *resultCache) put(key, value string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.expires = time.Now().Add(ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// purge drops every entry for the given tool
+func (c *resultCache) purge(tool string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prefix := tool + "\x00"
+	for key, el := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(el)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// cacheKey scopes results to the session, the user entity and safe mode so
+// one user's memories or an untrusted context never see another's cached
+// output. Group members share a session but not their memories.
+func cacheKey(tool, sessionID, owner string, safeMode bool, args string) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%t\x00%s", tool, sessionID, owner, safeMode, canonicalArgs(args))
+}
+
+// canonicalArgs re-encodes JSON so key order and whitespace don't cause misses
+func canonicalArgs(args string) string {
+	var v any
+	if err := json.Unmarshal([]byte(args), &v); err != nil {
+		return args
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return args
+	}
+	return string(b)
+}
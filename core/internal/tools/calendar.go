@@ -0,0 +1,336 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/calendar"
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/locale"
+)
+
+type ListEventsArgs struct {
+	From string `json:"from,omitempty"`
+	Days int    `json:"days,omitempty"`
+}
+
+type CreateEventArgs struct {
+	Title           string `json:"title"`
+	Start           string `json:"start"`
+	End             string `json:"end,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+	AllDay          bool   `json:"all_day,omitempty"`
+	Location        string `json:"location,omitempty"`
+	Description     string `json:"description,omitempty"`
+	RemindBefore    int    `json:"remind_minutes_before,omitempty"`
+}
+
+type UpdateEventArgs struct {
+	EventID     string  `json:"event_id"`
+	Title       *string `json:"title,omitempty"`
+	Start       string  `json:"start,omitempty"`
+	End         string  `json:"end,omitempty"`
+	Location    *string `json:"location,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// calendarTimeLayouts are the formats accepted for event times, in the user's timezone unless an offset is given
+var calendarTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", time.DateOnly}
+
+// RegisterCalendarTools registers list_events, create_event and update_event.
+// With a cron store, events can also schedule a reminder before they start.
+func RegisterCalendarTools(registry *Registry, cal calendar.Provider, cronStore *cron.Store, timezone *time.Location) {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+
+	listTool := llm.Tool{
+		Name:        "list_events",
+		Description: "List events on the user's calendar. Use before scheduling to check for clashes, and to answer questions like 'what's on Friday?'.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"from": map[string]any{
+					"type":        "string",
+					"description": "First day to include, YYYY-MM-DD. Default: today.",
+				},
+				"days": map[string]any{
+					"type":        "integer",
+					"description": "Number of days to include (default: 7, max: 62)",
+				},
+			},
+		},
+	}
+
+	registry.Register(listTool, func(ctx context.Context, args string) (string, error) {
+		tz := TimezoneFromContext(ctx, timezone)
+		var params ListEventsArgs
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		now := time.Now().In(tz)
+		from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+		if params.From != "" {
+			t, err := time.ParseInLocation(time.DateOnly, params.From, tz)
+			if err != nil {
+				return "", fmt.Errorf("invalid from date %q: use YYYY-MM-DD", params.From)
+			}
+			from = t
+		}
+
+		days := params.Days
+		if days <= 0 {
+			days = 7
+		}
+		days = min(days, 62)
+		to := from.AddDate(0, 0, days)
+
+		events, err := cal.Events(ctx, from, to)
+		if err != nil {
+			return "", err
+		}
+		if len(events) == 0 {
+			return fmt.Sprintf("No events between %s and %s.", from.Format("Mon Jan 2"), to.AddDate(0, 0, -1).Format("Mon Jan 2")), nil
+		}
+
+		var sb strings.Builder
+		for _, e := range events {
+			sb.WriteString(formatEvent(e, tz, PrefsFromContext(ctx)))
+			sb.WriteString("\n")
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	createTool := llm.Tool{
+		Name:        "create_event",
+		Description: "Add an event to the user's calendar. Check list_events for clashes first. Times are in the user's timezone. Set remind_minutes_before to also get a reminder from you before it starts.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"title": map[string]any{
+					"type":        "string",
+					"description": "Event title, e.g. 'Lunch with Sarah'",
+				},
+				"start": map[string]any{
+					"type":        "string",
+					"description": "Start time as YYYY-MM-DDTHH:MM, or YYYY-MM-DD for all-day events",
+				},
+				"end": map[string]any{
+					"type":        "string",
+					"description": "End time as YYYY-MM-DDTHH:MM. Overrides duration_minutes.",
+				},
+				"duration_minutes": map[string]any{
+					"type":        "integer",
+					"description": "Length of the event if end isn't given (default: 60)",
+				},
+				"all_day": map[string]any{
+					"type":        "boolean",
+					"description": "Whole-day event such as a holiday or birthday",
+				},
+				"location": map[string]any{
+					"type":        "string",
+					"description": "Where the event takes place",
+				},
+				"description": map[string]any{
+					"type":        "string",
+					"description": "Notes for the event",
+				},
+				"remind_minutes_before": map[string]any{
+					"type":        "integer",
+					"description": "Schedule a one-time reminder this many minutes before the start",
+				},
+			},
+			"required": []string{"title", "start"},
+		},
+	}
+
+	registry.Register(createTool, func(ctx context.Context, args string) (string, error) {
+		tz := TimezoneFromContext(ctx, timezone)
+		var params CreateEventArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if strings.TrimSpace(params.Title) == "" {
+			return "", fmt.Errorf("title is required")
+		}
+
+		start, err := parseEventTime(params.Start, tz)
+		if err != nil {
+			return "", err
+		}
+
+		event := calendar.Event{
+			Title:       params.Title,
+			Start:       start,
+			AllDay:      params.AllDay || len(params.Start) == len(time.DateOnly),
+			Location:    params.Location,
+			Description: params.Description,
+		}
+
+		switch {
+		case params.End != "":
+			if event.End, err = parseEventTime(params.End, tz); err != nil {
+				return "", err
+			}
+		case event.AllDay:
+			event.End = start.AddDate(0, 0, 1)
+		default:
+			duration := params.DurationMinutes
+			if duration <= 0 {
+				duration = 60
+			}
+			event.End = start.Add(time.Duration(duration) * time.Minute)
+		}
+		if !event.End.After(event.Start) {
+			return "", fmt.Errorf("event must end after it starts")
+		}
+
+		created, err := cal.Create(ctx, event)
+		if err != nil {
+			return "", err
+		}
+
+		result := "Added to calendar: " + formatEvent(*created, tz, PrefsFromContext(ctx))
+		if params.RemindBefore > 0 {
+			result += scheduleEventReminder(ctx, cronStore, *created, params.RemindBefore)
+		}
+		return result, nil
+	})
+
+	updateTool := llm.Tool{
+		Name:        "update_event",
+		Description: "Change an event on the user's calendar, e.g. move it or rename it. Get the event_id from list_events. Only the fields given are changed; moving the start keeps the event's length.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"event_id": map[string]any{
+					"type":        "string",
+					"description": "ID from list_events",
+				},
+				"title": map[string]any{
+					"type":        "string",
+					"description": "New title",
+				},
+				"start": map[string]any{
+					"type":        "string",
+					"description": "New start time as YYYY-MM-DDTHH:MM",
+				},
+				"end": map[string]any{
+					"type":        "string",
+					"description": "New end time as YYYY-MM-DDTHH:MM",
+				},
+				"location": map[string]any{
+					"type":        "string",
+					"description": "New location (empty string clears it)",
+				},
+				"description": map[string]any{
+					"type":        "string",
+					"description": "New notes (empty string clears them)",
+				},
+			},
+			"required": []string{"event_id"},
+		},
+	}
+
+	registry.Register(updateTool, func(ctx context.Context, args string) (string, error) {
+		tz := TimezoneFromContext(ctx, timezone)
+		var params UpdateEventArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		update := calendar.EventUpdate{
+			Title:       params.Title,
+			Location:    params.Location,
+			Description: params.Description,
+		}
+		if params.Start != "" {
+			start, err := parseEventTime(params.Start, tz)
+			if err != nil {
+				return "", err
+			}
+			update.Start = &start
+		}
+		if params.End != "" {
+			end, err := parseEventTime(params.End, tz)
+			if err != nil {
+				return "", err
+			}
+			update.End = &end
+		}
+
+		updated, err := cal.Update(ctx, params.EventID, update)
+		if err != nil {
+			return "", err
+		}
+
+		return "Updated: " + formatEvent(*updated, tz, PrefsFromContext(ctx)), nil
+	})
+
+	registry.Cacheable("list_events", 2*time.Minute)
+	registry.Invalidates("create_event", "list_events")
+	registry.Invalidates("update_event", "list_events")
+}
+
+func parseEventTime(s string, timezone *time.Location) (time.Time, error) {
+	for _, layout := range calendarTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(s), timezone); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use YYYY-MM-DDTHH:MM or YYYY-MM-DD", s)
+}
+
+func formatEvent(e calendar.Event, timezone *time.Location, prefs locale.Prefs) string {
+	var when string
+	if e.AllDay {
+		when = e.Start.Format("Mon Jan 2") + " (all day)"
+		if days := int(e.End.Sub(e.Start).Hours() / 24); days > 1 {
+			when = fmt.Sprintf("%s - %s (all day)", e.Start.Format("Mon Jan 2"), e.End.AddDate(0, 0, -1).Format("Mon Jan 2"))
+		}
+	} else {
+		start, end := e.Start.In(timezone), e.End.In(timezone)
+		when = prefs.DateTime(start) + " - " + prefs.Time(end)
+	}
+
+	line := fmt.Sprintf("- %s: %s", when, e.Title)
+	if e.Location != "" {
+		line += " @ " + e.Location
+	}
+	return line + fmt.Sprintf(" [id: %s]", e.ID)
+}
+
+// scheduleEventReminder adds a one-time cron before the event and describes the outcome
+func scheduleEventReminder(ctx context.Context, cronStore *cron.Store, e calendar.Event, minutes int) string {
+	at := e.Start.Add(-time.Duration(minutes) * time.Minute)
+	return scheduleOneTimeReminder(ctx, cronStore, e.Title, at)
+}
+
+// scheduleOneTimeReminder adds a cron that fires once at the given time and describes the outcome
+func scheduleOneTimeReminder(ctx context.Context, cronStore *cron.Store, keyword string, at time.Time) string {
+	chatID := ChatIDFromContext(ctx)
+	if cronStore == nil || chatID == 0 {
+		return "\n(Reminder not scheduled: reminders are unavailable here.)"
+	}
+
+	// the cron expression is read in the chat's timezone
+	at = at.In(cronStore.Location(chatID))
+	if !at.After(time.Now()) {
+		return "\n(Reminder not scheduled: that time has already passed.)"
+	}
+
+	schedule := fmt.Sprintf("0 %d %d %d %d *", at.Minute(), at.Hour(), at.Day(), int(at.Month()))
+	expiry := at.Add(time.Hour)
+	if _, err := cronStore.Create(keyword, schedule, chatID, &expiry); err != nil {
+		return fmt.Sprintf("\n(Reminder not scheduled: %v)", err)
+	}
+
+	return fmt.Sprintf("\nReminder '%s' scheduled for %s.", keyword, PrefsFromContext(ctx).DateTime(at))
+}
@@ -1,11 +1,23 @@
 package tools
 
 var DangerousTools = map[string]bool{
-	"deploy_app":     true,
-	"remove_app":     true,
-	"browse_session": true,
+	"deploy_app":         true,
+	"remove_app":         true,
+	"rollback_app":       true,
+	"set_auto_update":    true,
+	"set_app_basic_auth": true,
+	"browse_session":     true,
+	"send_email":         true,
+	"add_remote_host":    true,
+	"prune_docker":       true,
+	"grant_skill_access": true,
 }
 
-func RequiresApproval(toolName string) bool {
+// RequiresApproval reports whether a call needs the user's approval: always
+// for DangerousTools, per call for tools registered as ConditionallyDangerous
+func (r *Registry) RequiresApproval(toolName, args string) bool {
+	if check, ok := r.approvalChecks[toolName]; ok {
+		return check(args)
+	}
 	return DangerousTools[toolName]
 }
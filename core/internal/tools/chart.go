@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bowerhall/sheldon/internal/chart"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+type RenderChartArgs struct {
+	Type   string   `json:"type,omitempty"`
+	Title  string   `json:"title,omitempty"`
+	YLabel string   `json:"y_label,omitempty"`
+	Labels []string `json:"labels"`
+	Series []struct {
+		Name   string    `json:"name,omitempty"`
+		Values []float64 `json:"values"`
+	} `json:"series"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// RegisterChartTool registers render_chart, which draws series data as a PNG
+// and sends it to the chat
+func RegisterChartTool(registry *Registry, sender PhotoSender) {
+	tool := llm.Tool{
+		Name:        "render_chart",
+		Description: "Draw a line, bar or pie chart and send it to the user as an image. Use for usage reports, expense summaries, monitoring data or any numbers that are easier to see than read, e.g. after analyze_csv or usage_breakdown. Each series needs exactly one value per label.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"type": map[string]any{
+					"type":        "string",
+					"enum":        []string{chart.TypeLine, chart.TypeBar, chart.TypePie},
+					"description": "line for trends over time, bar for comparing categories, pie for shares of a whole (first series only). Default: line",
+				},
+				"title": map[string]any{
+					"type":        "string",
+					"description": "Chart title",
+				},
+				"y_label": map[string]any{
+					"type":        "string",
+					"description": "Unit shown above the value axis, e.g. 'EUR' or 'GB'",
+				},
+				"labels": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "X axis labels (dates, categories) or pie slice names",
+				},
+				"series": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"name": map[string]any{
+								"type":        "string",
+								"description": "Series name for the legend",
+							},
+							"values": map[string]any{
+								"type":        "array",
+								"items":       map[string]any{"type": "number"},
+								"description": "One value per label",
+							},
+						},
+						"required": []string{"values"},
+					},
+					"description": "Data series, up to 8",
+				},
+				"caption": map[string]any{
+					"type":        "string",
+					"description": "Optional caption for the image (default: the title)",
+				},
+			},
+			"required": []string{"labels", "series"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params RenderChartArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		c := chart.Chart{
+			Type:   params.Type,
+			Title:  params.Title,
+			YLabel: params.YLabel,
+			Labels: params.Labels,
+		}
+		if c.Type == "" {
+			c.Type = chart.TypeLine
+		}
+		for _, s := range params.Series {
+			c.Series = append(c.Series, chart.Series{Name: s.Name, Values: s.Values})
+		}
+
+		png, err := chart.Render(c)
+		if err != nil {
+			return "", fmt.Errorf("render chart: %w", err)
+		}
+
+		caption := params.Caption
+		if caption == "" {
+			caption = params.Title
+		}
+		if err := sender.SendPhoto(chatID, png, caption); err != nil {
+			return "", fmt.Errorf("send chart: %w", err)
+		}
+
+		logger.Debug("render_chart", "type", c.Type, "points", len(c.Labels), "series", len(c.Series))
+		return fmt.Sprintf("📈 sent %s chart to the user (%d points, %d series)", c.Type, len(c.Labels), len(c.Series)), nil
+	})
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/bowerhall/sheldon/internal/coder"
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/locale"
 	"github.com/bowerhall/sheldonmem"
 	"github.com/google/uuid"
 )
@@ -19,6 +21,16 @@ type CoderArgs struct {
 	GitRepo    string `json:"git_repo,omitempty"` // target repo name (e.g., "weather-bot")
 }
 
+type ContinueTaskArgs struct {
+	TaskID     string `json:"task_id"`
+	Task       string `json:"task"`
+	Complexity string `json:"complexity,omitempty"`
+	GitRepo    string `json:"git_repo,omitempty"`
+}
+
+// taskIDPattern guards continue_task against paths outside the workspace dir
+var taskIDPattern = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
 func RegisterCoderTool(registry *Registry, bridge *coder.Bridge, memory *sheldonmem.Store) {
 	tool := llm.Tool{
 		Name:        "write_code",
@@ -56,11 +68,7 @@ func RegisterCoderTool(registry *Registry, bridge *coder.Bridge, memory *sheldon
 		}
 
 		// notify user that coding has started
-		taskSummary := params.Task
-		if len(taskSummary) > 50 {
-			taskSummary = taskSummary[:50] + "..."
-		}
-		registry.Notify(ctx, fmt.Sprintf("🔨 Working on: %s", taskSummary))
+		registry.Notify(ctx, fmt.Sprintf("🔨 Working on: %s", truncateSummary(params.Task)))
 
 		memCtx := buildMemoryContext(ctx, memory, params.Task)
 
@@ -72,22 +80,67 @@ func RegisterCoderTool(registry *Registry, bridge *coder.Bridge, memory *sheldon
 			GitRepo:    params.GitRepo,
 		}
 
-		// simplified progress - typing indicator handles "in progress" state
-		// only log tool usage for debugging, don't spam chat
-		onProgress := func(event coder.StreamEvent) {
-			// silently track progress - typing indicator shows activity
+		return runCoderTask(ctx, registry, bridge, task)
+	})
+
+	continueTool := llm.Tool{
+		Name:        "continue_task",
+		Description: "Make a follow-up change to code from an earlier write_code or continue_task call, e.g. 'now add dark mode to that app'. Reuses the existing workspace and branch instead of starting over. Pass the same git_repo as the original task so changes are pushed to the same branch.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"task_id": map[string]any{
+					"type":        "string",
+					"description": "Task ID from the earlier write_code result",
+				},
+				"task": map[string]any{
+					"type":        "string",
+					"description": "The change to make to the existing code. Describe only what's new.",
+				},
+				"complexity": map[string]any{
+					"type":        "string",
+					"enum":        []string{"simple", "standard", "complex"},
+					"description": "Size of the change (default: simple)",
+				},
+				"git_repo": map[string]any{
+					"type":        "string",
+					"description": "Repository name used by the original task, if any",
+				},
+			},
+			"required": []string{"task_id", "task"},
+		},
+	}
+
+	registry.Register(continueTool, func(ctx context.Context, args string) (string, error) {
+		var params ContinueTaskArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if !taskIDPattern.MatchString(params.TaskID) {
+			return "", fmt.Errorf("invalid task_id %q", params.TaskID)
+		}
+		if !bridge.HasWorkspace(ctx, params.TaskID) {
+			return "", fmt.Errorf("workspace for task %s no longer exists, use write_code to start a new task", params.TaskID)
 		}
 
-		result, err := bridge.ExecuteWithProgress(ctx, task, onProgress)
-		if err != nil {
-			registry.Notify(ctx, fmt.Sprintf("❌ Code task failed: %v", err))
-			return "", err
+		complexity := coder.Complexity(params.Complexity)
+		if complexity == "" {
+			complexity = coder.ComplexitySimple
 		}
 
-		// notify completion
-		registry.Notify(ctx, fmt.Sprintf("✅ Code complete: %d files created", len(result.Files)))
+		registry.Notify(ctx, fmt.Sprintf("🔨 Updating %s: %s", params.TaskID, truncateSummary(params.Task)))
 
-		return formatResult(result), nil
+		task := coder.Task{
+			ID:         params.TaskID,
+			Prompt:     params.Task,
+			Complexity: complexity,
+			Context:    buildMemoryContext(ctx, memory, params.Task),
+			GitRepo:    params.GitRepo,
+			Continue:   true,
+		}
+
+		return runCoderTask(ctx, registry, bridge, task)
 	})
 
 	// cleanup workspaces tool
@@ -131,6 +184,54 @@ func RegisterCoderTool(registry *Registry, bridge *coder.Bridge, memory *sheldon
 	})
 }
 
+func runCoderTask(ctx context.Context, registry *Registry, bridge *coder.Bridge, task coder.Task) (string, error) {
+	// status line only, the chat gets a message when the task finishes
+	onProgress := func(event coder.StreamEvent) {
+		if event.Type == "tool_use" {
+			ReportProgress(ctx, "coding: "+coderActivity(event.Tool))
+		}
+	}
+
+	result, err := bridge.ExecuteWithProgress(ctx, task, onProgress)
+	if err != nil {
+		registry.Notify(ctx, fmt.Sprintf("❌ Code task failed: %v", err))
+		return "", err
+	}
+
+	// notify completion
+	if task.Continue {
+		registry.Notify(ctx, fmt.Sprintf("✅ Update complete: %d files in workspace", len(result.Files)))
+	} else {
+		registry.Notify(ctx, fmt.Sprintf("✅ Code complete: %d files created", len(result.Files)))
+	}
+
+	return formatResult(task.ID, result), nil
+}
+
+// coderActivity describes a Claude Code tool in words a user understands
+func coderActivity(tool string) string {
+	switch tool {
+	case "Read", "Glob", "Grep", "LS":
+		return "reading the code"
+	case "Write", "Edit", "MultiEdit":
+		return "writing code"
+	case "Bash":
+		return "running commands"
+	case "WebFetch", "WebSearch":
+		return "looking things up"
+	case "TodoWrite":
+		return "planning"
+	}
+	return strings.ToLower(tool)
+}
+
+func truncateSummary(s string) string {
+	if len(s) > 50 {
+		return s[:50] + "..."
+	}
+	return s
+}
+
 func buildMemoryContext(ctx context.Context, memory *sheldonmem.Store, taskDescription string) *coder.MemoryContext {
 	memCtx := &coder.MemoryContext{
 		UserPreferences: make(map[string]string),
@@ -141,9 +242,20 @@ func buildMemoryContext(ctx context.Context, memory *sheldonmem.Store, taskDescr
 		},
 	}
 
+	// only this user's facts and shared ones go into the coder's context
+	user, err := memory.FindEntityByName(UserEntityName(ctx))
+	if err != nil {
+		return memCtx
+	}
+	opts := sheldonmem.RecallOptions{
+		Depth:            1,
+		ExcludeSensitive: SafeModeFromContext(ctx),
+		OwnerID:          user.ID,
+	}
+
 	// recall facts relevant to the specific task
 	// search across preferences (11), knowledge (5), work (7), and identity (1)
-	result, err := memory.Recall(ctx, taskDescription, []int{1, 5, 7, 11}, 10)
+	result, err := memory.RecallWithOptions(ctx, taskDescription, []int{1, 5, 7, 11}, 10, opts)
 	if err != nil {
 		return memCtx
 	}
@@ -172,15 +284,15 @@ func buildMemoryContext(ctx context.Context, memory *sheldonmem.Store, taskDescr
 		strings.Contains(taskLower, "about me") ||
 		strings.Contains(taskLower, "about page") ||
 		strings.Contains(taskLower, "myself") {
-		addSheldonIdentity(ctx, memory, memCtx)
+		addSheldonIdentity(ctx, memory, memCtx, opts)
 	}
 
 	return memCtx
 }
 
-func addSheldonIdentity(ctx context.Context, memory *sheldonmem.Store, memCtx *coder.MemoryContext) {
+func addSheldonIdentity(ctx context.Context, memory *sheldonmem.Store, memCtx *coder.MemoryContext, opts sheldonmem.RecallOptions) {
 	// find the sheldon entity and get its facts
-	result, err := memory.Recall(ctx, "sheldon personality identity assistant", []int{1}, 10)
+	result, err := memory.RecallWithOptions(ctx, "sheldon personality identity assistant", []int{1}, 10, opts)
 	if err != nil {
 		return
 	}
@@ -203,13 +315,25 @@ func addSheldonIdentity(ctx context.Context, memory *sheldonmem.Store, memCtx *c
 	}
 }
 
-func formatResult(result *coder.Result) string {
+func formatResult(taskID string, result *coder.Result) string {
 	var sb strings.Builder
 
 	if result.Error != "" {
 		fmt.Fprintf(&sb, "Error: %s\n\n", result.Error)
 	}
 
+	fmt.Fprintf(&sb, "Task ID: %s (pass to continue_task for follow-up changes)\n", taskID)
+	if result.GitPushed {
+		fmt.Fprintf(&sb, "Pushed to branch: %s\n", result.GitBranch)
+	} else if result.GitDeclined {
+		sb.WriteString("Push not approved: changes remain in the workspace (use continue_task to revise)\n")
+	} else if result.GitError != "" {
+		fmt.Fprintf(&sb, "Git push failed: %s\n", result.GitError)
+	}
+	if d := result.Diff; d != nil && d.FilesChanged > 0 {
+		fmt.Fprintf(&sb, "Changes: %d files, +%d -%d\n", d.FilesChanged, d.Additions, d.Deletions)
+	}
+
 	if result.WorkspacePath != "" {
 		fmt.Fprintf(&sb, "Workspace: %s\n", result.WorkspacePath)
 		fmt.Fprintf(&sb, "\n⚠️ IMPORTANT: To deploy this app, use exactly this path:\n")
@@ -230,10 +354,36 @@ func formatResult(result *coder.Result) string {
 		sb.WriteString("\n")
 	}
 
+	if v := result.Verification; v != nil {
+		sb.WriteString("\nChecks:\n")
+		for _, c := range v.Checks {
+			status := "passed"
+			if c.Skipped {
+				status = "skipped (" + c.Tool + " not installed)"
+			} else if !c.Passed {
+				status = "FAILED"
+			}
+			fmt.Fprintf(&sb, "- %s: %s\n", c.Name, status)
+		}
+		if v.FixAttempts > 0 {
+			fmt.Fprintf(&sb, "Fix attempts: %d\n", v.FixAttempts)
+		}
+		if failed := v.Failed(); len(failed) > 0 {
+			sb.WriteString("\n⚠️ Checks still fail. Tell the user before opening a PR or deploying.\n")
+			for _, c := range failed {
+				fmt.Fprintf(&sb, "\n%s output:\n%s\n", c.Name, c.Output)
+			}
+		}
+	}
+
 	if result.Sanitized {
 		sb.WriteString("\n⚠️ Some content was redacted for security.\n")
 	}
 
+	if u := result.Usage; u.Tokens() > 0 {
+		fmt.Fprintf(&sb, "\nUsage: %d turns, %d tokens (~%s)", u.Turns, u.Tokens(), locale.Money(result.CostUSD, "USD"))
+	}
+
 	fmt.Fprintf(&sb, "\nCompleted in %s", result.Duration.Round(time.Second))
 
 	return sb.String()
@@ -118,11 +118,6 @@ func RegisterCoderStorageTools(registry *Registry, bridge *coder.Bridge, client
 			bucket = client.AgentBucket()
 		}
 
-		data, err := client.Download(ctx, bucket, params.Path)
-		if err != nil {
-			return "", fmt.Errorf("download from storage: %w", err)
-		}
-
 		workspacePath, err := bridge.GetLocalWorkspacePath(ctx, params.WorkspaceID)
 		if err != nil {
 			return "", fmt.Errorf("get workspace path: %w", err)
@@ -134,12 +129,13 @@ func RegisterCoderStorageTools(registry *Registry, bridge *coder.Bridge, client
 			return "", fmt.Errorf("create directories: %w", err)
 		}
 
-		if err := os.WriteFile(fullPath, data, 0644); err != nil {
-			return "", fmt.Errorf("write file: %w", err)
+		size, err := client.DownloadFile(ctx, bucket, params.Path, fullPath)
+		if err != nil {
+			return "", fmt.Errorf("download from storage: %w", err)
 		}
 
 		return fmt.Sprintf("downloaded %s/%s to workspace %s at %s (%d bytes)",
-			params.Space, params.Path, params.WorkspaceID, params.DestPath, len(data)), nil
+			params.Space, params.Path, params.WorkspaceID, params.DestPath, size), nil
 	})
 }
 
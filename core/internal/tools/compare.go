@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/budget"
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/locale"
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const (
+	maxCompareModels   = 3
+	compareTimeout     = 2 * time.Minute
+	maxCompareResponse = 3000
+)
+
+// ModelFactory creates an LLM client for a provider and model
+type ModelFactory func(provider, model string) (llm.LLM, error)
+
+type CompareModelsArgs struct {
+	Prompt string `json:"prompt"`
+	System string `json:"system,omitempty"`
+	Models []struct {
+		Provider string `json:"provider,omitempty"`
+		Model    string `json:"model"`
+	} `json:"models"`
+}
+
+type comparison struct {
+	provider string
+	model    string
+	reply    string
+	latency  time.Duration
+	tokens   budget.Tokens
+	err      error
+}
+
+// RegisterCompareTool registers compare_models, which sends one prompt to
+// several models at once so the user can pick a default. Usage counts
+// against the budget like any other request.
+func RegisterCompareTool(registry *Registry, factory ModelFactory, mr *config.ModelRegistry, tracker *budget.Tracker) {
+	tool := llm.Tool{
+		Name:        "compare_models",
+		Description: "Send the same prompt to 2-3 models concurrently and show their answers side by side with latency, tokens and cost. Use when the user wants to compare models or choose a default. Check list_providers first; each comparison costs real tokens on every model.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"prompt": map[string]any{
+					"type":        "string",
+					"description": "The prompt every model answers",
+				},
+				"system": map[string]any{
+					"type":        "string",
+					"description": "Optional system prompt for all models",
+				},
+				"models": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"provider": map[string]any{
+								"type":        "string",
+								"description": "Provider (kimi, claude, openai, openrouter, ollama). Inferred from the model if omitted.",
+							},
+							"model": map[string]any{
+								"type":        "string",
+								"description": "Model ID, e.g. claude-sonnet-4-20250514, gpt-4o, qwen3:8b",
+							},
+						},
+						"required": []string{"model"},
+					},
+					"description": "2-3 models to compare",
+				},
+			},
+			"required": []string{"prompt", "models"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params CompareModelsArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if strings.TrimSpace(params.Prompt) == "" {
+			return "", fmt.Errorf("prompt is required")
+		}
+		if len(params.Models) < 2 || len(params.Models) > maxCompareModels {
+			return "", fmt.Errorf("compare 2 to %d models, got %d", maxCompareModels, len(params.Models))
+		}
+
+		results := make([]*comparison, len(params.Models))
+		seen := make(map[string]bool)
+		for i, m := range params.Models {
+			provider := m.Provider
+			if provider == "" {
+				provider = inferProvider(m.Model, mr)
+				if provider == "" {
+					return "", fmt.Errorf("could not infer provider for model %q, please specify provider explicitly", m.Model)
+				}
+			}
+			if !providerConfigured(provider) {
+				return "", fmt.Errorf("cannot use %s: %s not configured", provider, config.EnvKeyForProvider(provider))
+			}
+			key := provider + "/" + m.Model
+			if seen[key] {
+				return "", fmt.Errorf("%s is listed twice", key)
+			}
+			seen[key] = true
+			results[i] = &comparison{provider: provider, model: m.Model}
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, compareTimeout)
+		defer cancel()
+
+		messages := []llm.Message{{Role: "user", Content: params.Prompt}}
+		var wg sync.WaitGroup
+		for _, r := range results {
+			wg.Add(1)
+			go func(r *comparison) {
+				defer wg.Done()
+				r.run(ctx, factory, params.System, messages)
+				if r.err == nil && tracker != nil {
+					tracker.RecordTokens(r.provider, r.model, r.tokens)
+				}
+			}(r)
+		}
+		wg.Wait()
+
+		logger.Debug("compare_models", "models", len(results))
+		return formatComparison(results), nil
+	})
+}
+
+func (r *comparison) run(ctx context.Context, factory ModelFactory, system string, messages []llm.Message) {
+	model, err := factory(r.provider, r.model)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	start := time.Now()
+	resp, err := model.ChatWithTools(ctx, system, messages, nil)
+	r.latency = time.Since(start)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	r.reply = strings.TrimSpace(resp.Content)
+	if resp.Usage != nil {
+		r.tokens = budget.Tokens{
+			Input:      resp.Usage.PromptTokens,
+			Output:     resp.Usage.CompletionTokens,
+			CacheWrite: resp.Usage.CacheWriteTokens,
+			CacheRead:  resp.Usage.CacheReadTokens,
+		}
+	}
+}
+
+func formatComparison(results []*comparison) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "⚖️ Compared %d models on the same prompt\n", len(results))
+
+	for _, r := range results {
+		fmt.Fprintf(&sb, "\n### %s/%s\n", r.provider, r.model)
+		if r.err != nil {
+			fmt.Fprintf(&sb, "❌ failed after %s: %s\n", r.latency.Round(100*time.Millisecond), r.err)
+			continue
+		}
+
+		cost := budget.Cost(r.provider, r.model, r.tokens)
+		fmt.Fprintf(&sb, "⏱️ %s · %d in / %d out tokens · %s\n\n", r.latency.Round(100*time.Millisecond), r.tokens.Input+r.tokens.CacheWrite+r.tokens.CacheRead, r.tokens.Output, locale.Money(cost, "USD"))
+
+		reply := r.reply
+		if reply == "" {
+			reply = "(empty response)"
+		}
+		if len(reply) > maxCompareResponse {
+			reply = reply[:maxCompareResponse] + "\n...(truncated)"
+		}
+		sb.WriteString(reply)
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,275 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// birthdayReminderHour is when the yearly birthday cron fires, in the user's timezone
+const birthdayReminderHour = 9
+
+type SaveContactArgs struct {
+	Name     string `json:"name"`
+	Phone    string `json:"phone,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Birthday string `json:"birthday,omitempty"`
+}
+
+type GetContactArgs struct {
+	Name string `json:"name,omitempty"`
+}
+
+type UpcomingBirthdaysArgs struct {
+	Days int `json:"days,omitempty"`
+}
+
+// RegisterContactTools registers save_contact, get_contact and upcoming_birthdays.
+// Contacts hang off person entities in the memory graph; saving a birthday also
+// schedules a yearly reminder cron when a cron store is available.
+func RegisterContactTools(registry *Registry, memory *sheldonmem.Store, cronStore *cron.Store, timezone *time.Location) {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+
+	saveTool := llm.Tool{
+		Name:        "save_contact",
+		Description: "Save or update someone's phone number, email or birthday. Use this instead of save_memory for contact details. Only the fields given are changed. Saving a birthday also sets a yearly reminder.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "The person's name as the user refers to them, e.g. 'Sarah' or 'Mom'",
+				},
+				"phone": map[string]any{
+					"type":        "string",
+					"description": "Phone number",
+				},
+				"email": map[string]any{
+					"type":        "string",
+					"description": "Email address",
+				},
+				"birthday": map[string]any{
+					"type":        "string",
+					"description": "Birthday as YYYY-MM-DD, or --MM-DD if the year isn't known",
+				},
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	registry.Register(saveTool, func(ctx context.Context, args string) (string, error) {
+		var params SaveContactArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		params.Name = strings.TrimSpace(params.Name)
+		if params.Name == "" {
+			return "", fmt.Errorf("name is required")
+		}
+		if params.Phone == "" && params.Email == "" && params.Birthday == "" {
+			return "", fmt.Errorf("give at least one of phone, email or birthday")
+		}
+
+		ownerID, err := contactOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+
+		contact, err := memory.SaveContact(ownerID, sheldonmem.Contact{
+			Name:     params.Name,
+			Phone:    strings.TrimSpace(params.Phone),
+			Email:    strings.TrimSpace(params.Email),
+			Birthday: strings.TrimSpace(params.Birthday),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		result := "Saved contact: " + formatContact(contact)
+		if params.Birthday != "" {
+			result += scheduleBirthdayReminder(ctx, cronStore, contact)
+		}
+		return result, nil
+	})
+
+	getTool := llm.Tool{
+		Name:        "get_contact",
+		Description: "Look up a saved contact's phone, email and birthday. Omit name to list all contacts.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "The person's name",
+				},
+			},
+		},
+	}
+
+	registry.Register(getTool, func(ctx context.Context, args string) (string, error) {
+		var params GetContactArgs
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		ownerID, err := contactOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+
+		if name := strings.TrimSpace(params.Name); name != "" {
+			contact, err := memory.GetContact(ownerID, name)
+			if errors.Is(err, sheldonmem.ErrContactNotFound) {
+				return fmt.Sprintf("No contact saved for %s.", name), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			return formatContact(contact), nil
+		}
+
+		contacts, err := memory.ListContacts(ownerID)
+		if err != nil {
+			return "", err
+		}
+		if len(contacts) == 0 {
+			return "No contacts saved.", nil
+		}
+
+		var sb strings.Builder
+		for _, c := range contacts {
+			sb.WriteString("- " + formatContact(c) + "\n")
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	birthdaysTool := llm.Tool{
+		Name:        "upcoming_birthdays",
+		Description: "List saved contacts with a birthday coming up, soonest first",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"days": map[string]any{
+					"type":        "integer",
+					"description": "How many days ahead to look (default: 30, max: 366)",
+				},
+			},
+		},
+	}
+
+	registry.Register(birthdaysTool, func(ctx context.Context, args string) (string, error) {
+		tz := TimezoneFromContext(ctx, timezone)
+		var params UpcomingBirthdaysArgs
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		days := params.Days
+		if days <= 0 {
+			days = 30
+		}
+		days = min(days, 366)
+
+		ownerID, err := contactOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+
+		now := time.Now().In(tz)
+		upcoming, err := memory.UpcomingBirthdays(ownerID, now, days)
+		if err != nil {
+			return "", err
+		}
+		if len(upcoming) == 0 {
+			return fmt.Sprintf("No birthdays in the next %d days.", days), nil
+		}
+
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+		var sb strings.Builder
+		for _, b := range upcoming {
+			fmt.Fprintf(&sb, "- %s: %s", b.Date.Format("Mon Jan 2"), b.Contact.Name)
+			if b.Age > 0 {
+				fmt.Fprintf(&sb, " (turns %d)", b.Age)
+			}
+			switch away := int(b.Date.Sub(today).Hours() / 24); away {
+			case 0:
+				sb.WriteString(" - today")
+			case 1:
+				sb.WriteString(" - tomorrow")
+			default:
+				fmt.Fprintf(&sb, " - in %d days", away)
+			}
+			sb.WriteString("\n")
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	registry.Cacheable("get_contact", 2*time.Minute)
+	registry.Cacheable("upcoming_birthdays", 2*time.Minute)
+	registry.Invalidates("save_contact", "get_contact", "upcoming_birthdays")
+}
+
+func contactOwner(ctx context.Context, memory *sheldonmem.Store) (int64, error) {
+	entity, err := memory.FindEntityByName(UserEntityName(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("could not find user entity: %w", err)
+	}
+	return entity.ID, nil
+}
+
+func formatContact(c *sheldonmem.Contact) string {
+	parts := []string{c.Name}
+	if c.Phone != "" {
+		parts = append(parts, "phone "+c.Phone)
+	}
+	if c.Email != "" {
+		parts = append(parts, "email "+c.Email)
+	}
+	if c.Birthday != "" {
+		parts = append(parts, "birthday "+c.Birthday)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// scheduleBirthdayReminder replaces the contact's yearly birthday cron and describes the outcome
+func scheduleBirthdayReminder(ctx context.Context, cronStore *cron.Store, c *sheldonmem.Contact) string {
+	chatID := ChatIDFromContext(ctx)
+	if cronStore == nil || chatID == 0 {
+		return "\n(Birthday reminder not scheduled: reminders are unavailable here.)"
+	}
+
+	_, month, day, err := sheldonmem.ParseBirthday(c.Birthday)
+	if err != nil {
+		return fmt.Sprintf("\n(Birthday reminder not scheduled: %v)", err)
+	}
+	// a Feb 29 birthday is remembered every year on Feb 28
+	if month == time.February && day == 29 {
+		day = 28
+	}
+
+	keyword := c.Name + "'s birthday"
+	if err := cronStore.DeleteByKeyword(keyword, chatID); err != nil {
+		return fmt.Sprintf("\n(Birthday reminder not scheduled: %v)", err)
+	}
+
+	schedule := fmt.Sprintf("0 0 %d %d %d *", birthdayReminderHour, day, int(month))
+	if _, err := cronStore.Create(keyword, schedule, chatID, nil); err != nil {
+		return fmt.Sprintf("\n(Birthday reminder not scheduled: %v)", err)
+	}
+
+	return fmt.Sprintf("\nYearly reminder set for %s at %d:00.", time.Date(2000, month, day, 0, 0, 0, 0, time.UTC).Format("Jan 2"), birthdayReminderHour)
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/conversation"
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+const (
+	minContextSize = 2 // one exchange
+	maxContextSize = 100
+)
+
+// RegisterContextTools registers clear_context and set_context_size, which
+// control the chat history carried between messages and across restarts.
+// clear marks a session to be wiped once the current turn ends.
+func RegisterContextTools(registry *Registry, rc *config.RuntimeConfig, convo *conversation.Store, clear func(sessionID string)) {
+	clearTool := llm.Tool{
+		Name:        "clear_context",
+		Description: "Start this chat fresh: forget the conversation so far, including the buffered recent messages and the summary of older ones. Long-term memory (facts, notes) is kept. Use when the user asks to start over, change topic completely, or says earlier messages are confusing things.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(clearTool, func(ctx context.Context, args string) (string, error) {
+		sessionID := SessionIDFromContext(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("no session in context")
+		}
+
+		clear(sessionID)
+		return "🧹 context will be cleared after this reply; the next message starts fresh. Long-term memory is kept.", nil
+	})
+
+	sizeTool := llm.Tool{
+		Name:        "set_context_size",
+		Description: fmt.Sprintf("Set how many recent messages this chat keeps in its conversation buffer, which is reloaded after restarts and idle periods. Fewer is cheaper, more keeps longer threads intact. %d-%d, or 0 to go back to the default.", minContextSize, maxContextSize),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"messages": map[string]any{
+					"type":        "integer",
+					"description": "Number of messages to keep (user and assistant each count), 0 for the default",
+				},
+			},
+			"required": []string{"messages"},
+		},
+	}
+
+	registry.Register(sizeTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Messages int `json:"messages"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		sessionID := SessionIDFromContext(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("no session in context")
+		}
+
+		if params.Messages == 0 {
+			if _, err := rc.ClearSessionBufferSize(sessionID); err != nil {
+				return "", fmt.Errorf("failed to save: %w", err)
+			}
+			return fmt.Sprintf("📏 this chat is back to the default buffer of %d messages", convo.MaxMessages(sessionID)), nil
+		}
+
+		if params.Messages < minContextSize || params.Messages > maxContextSize {
+			return "", fmt.Errorf("messages must be between %d and %d", minContextSize, maxContextSize)
+		}
+		if err := rc.SetSessionBufferSize(sessionID, params.Messages); err != nil {
+			return "", fmt.Errorf("failed to save: %w", err)
+		}
+		return fmt.Sprintf("📏 this chat now keeps the last %d messages", params.Messages), nil
+	})
+}
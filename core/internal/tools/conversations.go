@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldonmem"
+)
+
+type SearchConversationsArgs struct {
+	Query  string `json:"query"`
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// RegisterConversationTools registers search_conversations, a full-text
+// search over past messages and daily summaries in the current chat
+func RegisterConversationTools(registry *Registry, memory *sheldonmem.Store) {
+	tool := llm.Tool{
+		Name:        "search_conversations",
+		Description: "Search past conversations in this chat by their words, across every day, e.g. \"kitchen remodel\" after 2026-06-01 before 2026-07-01 for \"what did we decide about the kitchen remodel in June?\". Returns matching passages from daily summaries and messages with their dates. Use recall_memory for facts; use this for what was said.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Words to look for, e.g. 'kitchen remodel contractor'. Passages with more of them rank higher.",
+				},
+				"after": map[string]any{
+					"type":        "string",
+					"description": "Only conversations on or after this date (YYYY-MM-DD)",
+				},
+				"before": map[string]any{
+					"type":        "string",
+					"description": "Only conversations before this date (YYYY-MM-DD)",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Maximum results (default: 10, max: 30)",
+				},
+			},
+			"required": []string{"query"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params SearchConversationsArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if strings.TrimSpace(params.Query) == "" {
+			return "", fmt.Errorf("query is required")
+		}
+
+		sessionID := SessionIDFromContext(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("no session in context")
+		}
+
+		q := sheldonmem.ConversationQuery{Text: params.Query, SessionID: sessionID, Limit: min(max(params.Limit, 1), 30)}
+		if params.Limit == 0 {
+			q.Limit = 10
+		}
+		var err error
+		if q.After, err = parseMediaDate(params.After); err != nil {
+			return "", err
+		}
+		if q.Before, err = parseMediaDate(params.Before); err != nil {
+			return "", err
+		}
+
+		hits, err := memory.SearchConversations(ctx, q)
+		if err != nil {
+			return "", fmt.Errorf("search conversations: %w", err)
+		}
+		if len(hits) == 0 {
+			return "No past conversations match that.", nil
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Found %d:\n", len(hits))
+		for _, h := range hits {
+			switch h.Kind {
+			case "summary":
+				fmt.Fprintf(&sb, "- %s (day summary): %s\n", h.Date, h.Snippet)
+			default:
+				fmt.Fprintf(&sb, "- %s %s: %s\n", h.CreatedAt.Format("2006-01-02 15:04"), h.Role, h.Snippet)
+			}
+		}
+		return sb.String(), nil
+	})
+}
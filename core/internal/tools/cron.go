@@ -12,10 +12,13 @@ import (
 )
 
 type SetCronArgs struct {
-	Keyword   string `json:"keyword"`
-	Schedule  string `json:"schedule"`
-	ExpiresIn string `json:"expires_in,omitempty"`
-	OneTime   bool   `json:"one_time,omitempty"`
+	Keyword   string   `json:"keyword"`
+	Schedule  string   `json:"schedule"`
+	ExpiresIn string   `json:"expires_in,omitempty"`
+	OneTime   bool     `json:"one_time,omitempty"`
+	Prompt    string   `json:"prompt,omitempty"`
+	Tools     []string `json:"tools,omitempty"`
+	Skill     string   `json:"skill,omitempty"`
 }
 
 type DeleteCronArgs struct {
@@ -27,7 +30,7 @@ type PauseCronArgs struct {
 	Until   string `json:"until"`
 }
 
-func RegisterCronTools(registry *Registry, cronStore *cron.Store, timezone *time.Location) {
+func RegisterCronTools(registry *Registry, cronStore *cron.Store, skills *SkillsManager, timezone *time.Location) {
 	if timezone == nil {
 		timezone = time.UTC
 	}
@@ -36,21 +39,29 @@ func RegisterCronTools(registry *Registry, cronStore *cron.Store, timezone *time
 		Name: "set_cron",
 		Description: `Schedule a trigger. When the cron fires, you'll wake up with the keyword's recalled context and decide what to do.
 
+Write the schedule in plain words; it is checked and echoed back so you can confirm it with the user. Understood phrases:
+- "every 10 minutes", "every 2 hours", "hourly"
+- "every day at 8pm", "daily at 20:00"
+- "every weekday at 8:30", "weekends at 10am", "mondays and thursdays at 7pm", "mon-fri at 9"
+- "on the 15th of every month at 9am", "monthly"
+- "first monday of the month at 10am", "last friday of every month at 5pm"
+Raw cron syntax (6 fields with seconds, e.g. "0 0 20 * * *") also works for anything else.
+
 CRITICAL - Distinguish ONE-TIME vs RECURRING:
 
 ONE-TIME (set one_time=true):
-- "remind me IN 10 minutes" → schedule="@every 10m", one_time=true
-- "check on me IN 2 hours" → schedule="@every 2h", one_time=true
-- "remind me AT 3pm" → schedule="0 0 15 * * *", one_time=true
-- "do this tomorrow at 9am" → schedule="0 0 9 * * *", one_time=true
+- "remind me IN 10 minutes" → schedule="in 10 minutes", one_time=true
+- "check on me IN 2 hours" → schedule="in 2 hours", one_time=true
+- "remind me AT 3pm" → schedule="every day at 3pm", one_time=true
 
 RECURRING (one_time=false or omit):
-- "remind me EVERY 10 minutes" → schedule="@every 10m"
-- "check on me EVERY 6 hours" → schedule="@every 6h"
-- "remind me DAILY at 8pm" → schedule="0 0 20 * * *"
+- "remind me EVERY 10 minutes" → schedule="every 10 minutes"
+- "remind me DAILY at 8pm" → schedule="every day at 8pm"
 
 The word "IN" means ONE-TIME. The word "EVERY" or "DAILY" means RECURRING.
-If unsure, ask the user to clarify.`,
+If unsure, ask the user to clarify.
+
+For routines with a fixed recipe (a morning briefing, a weekly review), put the exact steps in prompt instead of relying on keyword recall, and list the tools they need in tools. To run an installed skill on a schedule (a weekly meal planner), name it in skill; prompt then adds to the skill's instructions.`,
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -60,7 +71,7 @@ If unsure, ask the user to clarify.`,
 				},
 				"schedule": map[string]any{
 					"type":        "string",
-					"description": "Cron expression or interval. Intervals: '@every 5m', '@every 1h', '@every 30s'. Cron (6 fields): '0 0 20 * * *' (8pm daily), '0 0 9 * * 1-5' (9am weekdays).",
+					"description": "When to fire, in plain words ('every weekday at 8:30', 'every 30 minutes', 'first monday of the month at 10am') or cron syntax with seconds ('0 0 20 * * *').",
 				},
 				"one_time": map[string]any{
 					"type":        "boolean",
@@ -70,6 +81,19 @@ If unsure, ask the user to clarify.`,
 					"type":        "string",
 					"description": "Auto-delete after duration. Examples: '2 weeks', '1 month'. Ignored if one_time=true.",
 				},
+				"prompt": map[string]any{
+					"type":        "string",
+					"description": "Instructions to follow when it fires, e.g. 'Check my calendar for today, the weather in Berlin and unread email, then send a short briefing'. Replaces keyword recall.",
+				},
+				"tools": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Only allow these tools when it fires, e.g. ['list_events', 'get_weather']. Omit to allow all.",
+				},
+				"skill": map[string]any{
+					"type":        "string",
+					"description": "Installed skill to run when it fires, e.g. 'meal-planner'. Its instructions replace keyword recall.",
+				},
 			},
 			"required": []string{"keyword", "schedule"},
 		},
@@ -86,18 +110,37 @@ If unsure, ask the user to clarify.`,
 			return "", fmt.Errorf("no chat context available")
 		}
 
+		var unknown []string
+		for _, name := range params.Tools {
+			if _, ok := registry.handlers[name]; !ok {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			return "", fmt.Errorf("unknown tools: %s", strings.Join(unknown, ", "))
+		}
+
+		if params.Skill != "" {
+			if skills == nil {
+				return "", fmt.Errorf("skills are not enabled")
+			}
+			if _, err := skills.Read(params.Skill); err != nil {
+				return "", fmt.Errorf("skill not found: %s", params.Skill)
+			}
+		}
+
 		var expiresAt *time.Time
 
 		if params.OneTime {
 			// for one-time triggers, compute next run and set expiry 1 hour after
-			nextRun, err := cronStore.ComputeNextRun(params.Schedule)
+			nextRun, err := cronStore.ComputeNextRun(params.Schedule, chatID)
 			if err != nil {
 				return "", fmt.Errorf("invalid schedule: %w", err)
 			}
 			expiry := nextRun.Add(1 * time.Hour)
 			expiresAt = &expiry
 		} else if params.ExpiresIn != "" {
-			t := parseExpiry(params.ExpiresIn)
+			t := parseExpiry(params.ExpiresIn, TimezoneFromContext(ctx, timezone))
 			if t != nil {
 				expiresAt = t
 			}
@@ -107,17 +150,33 @@ If unsure, ask the user to clarify.`,
 		if err != nil {
 			return "", fmt.Errorf("failed to create cron: %w", err)
 		}
+		if params.Prompt != "" || len(params.Tools) > 0 {
+			if err := cronStore.SetPrompt(c.Keyword, chatID, params.Prompt, params.Tools); err != nil {
+				return "", fmt.Errorf("failed to save prompt: %w", err)
+			}
+		}
+		if params.Skill != "" {
+			if err := cronStore.SetSkill(c.Keyword, chatID, params.Skill); err != nil {
+				return "", fmt.Errorf("failed to save skill: %w", err)
+			}
+		}
 
+		prefs, tz := PrefsFromContext(ctx), TimezoneFromContext(ctx, timezone)
 		expiryInfo := ""
 		if params.OneTime {
 			expiryInfo = " (one-time)"
 		} else if expiresAt != nil {
-			expiryInfo = fmt.Sprintf(" (expires %s)", expiresAt.Format("Jan 2, 2006"))
+			expiryInfo = fmt.Sprintf(" (expires %s)", prefs.Date(expiresAt.In(tz)))
 		}
 
-		return fmt.Sprintf("Reminder '%s' scheduled. Next: %s%s",
+		schedule := ""
+		if !params.OneTime {
+			schedule = ": " + cron.Describe(c.Schedule)
+		}
+		return fmt.Sprintf("Reminder '%s' scheduled%s. Next: %s%s",
 			c.Keyword,
-			c.NextRun.In(timezone).Format("Mon Jan 2 3:04 PM"),
+			schedule,
+			prefs.DateTime(c.NextRun.In(tz)),
 			expiryInfo), nil
 	})
 
@@ -146,23 +205,33 @@ If unsure, ask the user to clarify.`,
 			return "No active scheduled triggers.", nil
 		}
 
+		prefs, tz := PrefsFromContext(ctx), TimezoneFromContext(ctx, timezone)
 		var sb strings.Builder
 		sb.WriteString("Active scheduled triggers:\n")
 		for _, c := range crons {
 			status := ""
 			if c.PausedUntil != nil && c.PausedUntil.After(time.Now()) {
-				status = fmt.Sprintf(" [PAUSED until %s]", c.PausedUntil.In(timezone).Format("Mon Jan 2 3:04 PM"))
+				status = fmt.Sprintf(" [PAUSED until %s]", prefs.DateTime(c.PausedUntil.In(tz)))
 			}
 			expiryInfo := ""
 			if c.ExpiresAt != nil {
-				expiryInfo = fmt.Sprintf(" (expires %s)", c.ExpiresAt.In(timezone).Format("Jan 2"))
+				expiryInfo = fmt.Sprintf(" (expires %s)", prefs.Date(c.ExpiresAt.In(tz)))
 			}
-			fmt.Fprintf(&sb, "- %s: next %s, schedule '%s'%s%s\n",
+			fmt.Fprintf(&sb, "- %s: next %s, %s%s%s\n",
 				c.Keyword,
-				c.NextRun.In(timezone).Format("Mon Jan 2 3:04 PM"),
-				c.Schedule,
+				prefs.DateTime(c.NextRun.In(tz)),
+				cron.Describe(c.Schedule),
 				status,
 				expiryInfo)
+			if c.Skill != "" {
+				fmt.Fprintf(&sb, "  skill: %s\n", c.Skill)
+			}
+			if c.Prompt != "" {
+				fmt.Fprintf(&sb, "  prompt: %s\n", c.Prompt)
+			}
+			if len(c.Tools) > 0 {
+				fmt.Fprintf(&sb, "  tools: %s\n", strings.Join(c.Tools, ", "))
+			}
 		}
 		return sb.String(), nil
 	})
@@ -234,7 +303,8 @@ If unsure, ask the user to clarify.`,
 		}
 
 		// parse the until time
-		until := parseExpiry(params.Until)
+		tz := TimezoneFromContext(ctx, timezone)
+		until := parseExpiry(params.Until, tz)
 		if until == nil {
 			return "", fmt.Errorf("could not parse time: %s", params.Until)
 		}
@@ -244,7 +314,7 @@ If unsure, ask the user to clarify.`,
 			return "", fmt.Errorf("failed to pause cron: %w", err)
 		}
 
-		return fmt.Sprintf("Trigger '%s' paused until %s.", params.Keyword, until.In(timezone).Format("Mon Jan 2 3:04 PM")), nil
+		return fmt.Sprintf("Trigger '%s' paused until %s.", params.Keyword, PrefsFromContext(ctx).DateTime(until.In(tz))), nil
 	})
 
 	// resume_cron tool (unpause)
@@ -283,15 +353,16 @@ If unsure, ask the user to clarify.`,
 	})
 }
 
-// parseExpiry converts human-readable duration or datetime to time
-func parseExpiry(s string) *time.Time {
+// parseExpiry converts human-readable duration or datetime to time, reading
+// dates and times of day in loc
+func parseExpiry(s string, loc *time.Location) *time.Time {
 	original := s
 	s = strings.ToLower(strings.TrimSpace(s))
 	if s == "" || s == "never" {
 		return nil
 	}
 
-	now := time.Now()
+	now := time.Now().In(loc)
 
 	// try absolute datetime formats first (use original case for parsing)
 	dateFormats := []string{
@@ -11,9 +11,13 @@ import (
 )
 
 type ComposeDeployArgs struct {
-	AppDir string `json:"app_dir"`
-	Name   string `json:"name"`
-	Domain string `json:"domain,omitempty"`
+	AppDir   string            `json:"app_dir"`
+	Name     string            `json:"name"`
+	Domain   string            `json:"domain,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	Port     int               `json:"port,omitempty"`
+	Volumes  []string          `json:"volumes,omitempty"`
+	Replicas int               `json:"replicas,omitempty"`
 }
 
 type BuildArgs struct {
@@ -26,10 +30,20 @@ type ComposeServiceArgs struct {
 	Name string `json:"name"`
 }
 
-func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder, deploy *deployer.ComposeDeployer, domain string) {
+type DeploymentHistoryArgs struct {
+	Name  string `json:"name"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+type RollbackArgs struct {
+	Name         string `json:"name"`
+	DeploymentID int64  `json:"deployment_id,omitempty"`
+}
+
+func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder, deploy deployer.Deployer, domain string) {
 	deployTool := llm.Tool{
 		Name:        "deploy_app",
-		Description: "Deploy an app using Docker Compose. The app directory should contain a Dockerfile. Sheldon will build the image and add it to the apps.yml file with Traefik routing.",
+		Description: "Deploy an app to the configured target (Docker Compose or Kubernetes). The app directory should contain a Dockerfile. Sheldon will build the image and route name.yourdomain.com to it. Secrets saved with set_app_secret for this name are passed to the app as environment variables. Use plan_deploy first to show the user what will change.",
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -41,6 +55,24 @@ func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder,
 					"type":        "string",
 					"description": "Name for the app (used for routing: name.yourdomain.com)",
 				},
+				"env": map[string]any{
+					"type":                 "object",
+					"additionalProperties": map[string]any{"type": "string"},
+					"description":          "Plain environment variables, e.g. {\"LOG_LEVEL\": \"info\"}. Replaces the app's previous env; omit to keep it. Use set_app_secret for keys and passwords.",
+				},
+				"port": map[string]any{
+					"type":        "integer",
+					"description": "Port the app listens on inside the container (default: from the Dockerfile or 80)",
+				},
+				"volumes": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Persistent named volumes as name:/container/path or name:/container/path:ro, e.g. [\"data:/app/data\"]. Data survives redeploys.",
+				},
+				"replicas": map[string]any{
+					"type":        "integer",
+					"description": "Number of containers to run (default: 1, max: 10)",
+				},
 			},
 			"required": []string{"app_dir", "name"},
 		},
@@ -54,7 +86,18 @@ func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder,
 
 		registry.Notify(ctx, fmt.Sprintf("🚀 Deploying %s...", params.Name))
 
-		result, err := deploy.Deploy(ctx, params.AppDir, params.Name, domain)
+		opts := deployer.DeployOptions{
+			Env:      params.Env,
+			Port:     params.Port,
+			Volumes:  params.Volumes,
+			Replicas: params.Replicas,
+		}
+		if err := opts.Validate(); err != nil {
+			return "", err
+		}
+
+		ReportProgress(ctx, fmt.Sprintf("deploying %s: building and starting containers", params.Name))
+		result, err := deploy.Deploy(ctx, params.AppDir, params.Name, domain, opts)
 		if err != nil {
 			registry.Notify(ctx, fmt.Sprintf("❌ Deploy failed: %v", err))
 			return "", err
@@ -62,13 +105,43 @@ func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder,
 
 		registry.Notify(ctx, fmt.Sprintf("✅ Deployed: %s → %s", params.Name, result.URL))
 
-		return fmt.Sprintf("App deployed: %s\nURL: %s\nStatus: %s",
-			strings.Join(result.Resources, ", "), result.URL, result.Status), nil
+		output := fmt.Sprintf("App deployed: %s\nURL: %s\nStatus: %s",
+			strings.Join(result.Resources, ", "), result.URL, result.Status)
+		if result.DeploymentID > 0 {
+			output += fmt.Sprintf("\nDeployment: #%d (use rollback_app to revert)", result.DeploymentID)
+		}
+		return output, nil
+	})
+
+	planTool := llm.Tool{
+		Name:        "plan_deploy",
+		Description: "Preview a deploy_app call without building or changing anything: shows whether the app is new, each setting, label, volume and network that would be added, removed or changed, and the resulting config. Takes the same arguments as deploy_app. Summarize the changes for the user before asking to deploy.",
+		Parameters:  deployTool.Parameters,
+	}
+
+	registry.Register(planTool, func(ctx context.Context, args string) (string, error) {
+		var params ComposeDeployArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		opts := deployer.DeployOptions{
+			Env:      params.Env,
+			Port:     params.Port,
+			Volumes:  params.Volumes,
+			Replicas: params.Replicas,
+		}
+		plan, err := deploy.Plan(ctx, params.AppDir, params.Name, domain, opts)
+		if err != nil {
+			return "", err
+		}
+
+		return formatDeployPlan(plan), nil
 	})
 
 	removeTool := llm.Tool{
 		Name:        "remove_app",
-		Description: "Stop and remove a deployed app from Docker Compose.",
+		Description: "Stop and remove a deployed app.",
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -174,6 +247,96 @@ func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder,
 		return logs, nil
 	})
 
+	historyTool := llm.Tool{
+		Name:        "deployment_history",
+		Description: "List past deployments of an app (newest first) with image, git branch/commit and time. Use to pick a deployment_id for rollback_app.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Name of the app",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Number of deployments to show (default: 10)",
+				},
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	registry.Register(historyTool, func(ctx context.Context, args string) (string, error) {
+		var params DeploymentHistoryArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		deployments, err := deploy.History(params.Name, params.Limit)
+		if err != nil {
+			return "", err
+		}
+		if len(deployments) == 0 {
+			return fmt.Sprintf("No recorded deployments of %s.", params.Name), nil
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Deployments of %s (newest first):\n", params.Name)
+		for i, d := range deployments {
+			fmt.Fprintf(&sb, "- #%d %s: %s", d.ID, d.CreatedAt.Format("2006-01-02 15:04 UTC"), d.Image)
+			if d.Branch != "" {
+				fmt.Fprintf(&sb, " (%s@%s)", d.Branch, d.Commit)
+			}
+			if d.RollbackOf > 0 {
+				fmt.Fprintf(&sb, " [rollback to #%d]", d.RollbackOf)
+			}
+			if i == 0 {
+				sb.WriteString(" [current]")
+			}
+			sb.WriteString("\n")
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	rollbackTool := llm.Tool{
+		Name:        "rollback_app",
+		Description: "Revert a deployed app to an earlier deployment's image, e.g. after a bad deploy. Without deployment_id, goes back to the version before the current one. Routing and secrets are unchanged.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Name of the app",
+				},
+				"deployment_id": map[string]any{
+					"type":        "integer",
+					"description": "Deployment to restore, from deployment_history (default: the previous version)",
+				},
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	registry.Register(rollbackTool, func(ctx context.Context, args string) (string, error) {
+		var params RollbackArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("⏪ Rolling back %s...", params.Name))
+
+		target, err := deploy.Rollback(ctx, params.Name, params.DeploymentID)
+		if err != nil {
+			registry.Notify(ctx, fmt.Sprintf("❌ Rollback failed: %v", err))
+			return "", err
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("✅ %s rolled back to deployment #%d", params.Name, target.ID))
+
+		return fmt.Sprintf("App %s rolled back to deployment #%d (%s, deployed %s)",
+			params.Name, target.ID, target.Image, target.CreatedAt.Format("2006-01-02 15:04 UTC")), nil
+	})
+
 	buildTool := llm.Tool{
 		Name:        "build_image",
 		Description: "Build a Docker image from a directory containing a Dockerfile. Use this before deploy_app if you want to pre-build the image.",
@@ -210,6 +373,7 @@ func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder,
 
 		registry.Notify(ctx, fmt.Sprintf("🐳 Building image: %s:%s", params.ImageName, tag))
 
+		ReportProgress(ctx, fmt.Sprintf("building image %s:%s", params.ImageName, tag))
 		result, err := builder.Build(ctx, params.ContextDir, params.ImageName, tag)
 		if err != nil {
 			registry.Notify(ctx, fmt.Sprintf("❌ Build failed: %v", err))
@@ -244,3 +408,33 @@ func RegisterComposeDeployerTools(registry *Registry, builder *deployer.Builder,
 		return fmt.Sprintf("Cleaned up %d unused images", count), nil
 	})
 }
+
+func formatDeployPlan(plan *deployer.DeployPlan) string {
+	var sb strings.Builder
+	if plan.Create {
+		sb.WriteString(fmt.Sprintf("Deploy plan for %s (new app)\n", plan.App))
+	} else {
+		sb.WriteString(fmt.Sprintf("Deploy plan for %s (update)\n", plan.App))
+	}
+	if plan.URL != "" {
+		sb.WriteString(fmt.Sprintf("URL: %s\n", plan.URL))
+	}
+
+	sb.WriteString("\nChanges:\n")
+	if len(plan.Changes) == 0 {
+		sb.WriteString("- none besides the rebuild\n")
+	}
+	for _, c := range plan.Changes {
+		sb.WriteString(fmt.Sprintf("- %s\n", c))
+	}
+
+	if len(plan.Notes) > 0 {
+		sb.WriteString("\nAlso:\n")
+		for _, n := range plan.Notes {
+			sb.WriteString(fmt.Sprintf("- %s\n", n))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\nResulting config:\n```yaml\n%s\n```\n\nNothing has been deployed yet.", strings.TrimRight(plan.Config, "\n")))
+	return sb.String()
+}
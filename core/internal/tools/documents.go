@@ -0,0 +1,417 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/documents"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/storage"
+	"github.com/bowerhall/sheldonmem"
+)
+
+type SearchDocumentsArgs struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+type IndexDocumentArgs struct {
+	Space string `json:"space"`
+	Path  string `json:"path"`
+}
+
+type DocumentIDArgs struct {
+	DocumentID int64 `json:"document_id"`
+}
+
+type ReadDocumentArgs struct {
+	Space      string `json:"space,omitempty"`
+	Path       string `json:"path,omitempty"`
+	MediaIndex *int   `json:"media_index,omitempty"`
+	Pages      string `json:"pages,omitempty"`
+	Images     bool   `json:"images,omitempty"`
+}
+
+const (
+	// maxReadChars keeps one read_document call from filling the context window
+	maxReadChars = 30000
+	// maxPageImages bounds how many rendered pages go to the model at once
+	maxPageImages = 5
+	pageImageDPI  = 100
+)
+
+// RegisterDocumentTools registers search over the user's document library.
+// With a storage client, files in storage can also be added to it.
+func RegisterDocumentTools(registry *Registry, memory *sheldonmem.Store, client *storage.Client) {
+	searchTool := llm.Tool{
+		Name:        "search_documents",
+		Description: "Search the user's document library (PDFs and text files they've uploaded) and return the most relevant passages. Use this to answer questions about their contracts, manuals, statements and other documents.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "What to look for, phrased as a question or topic",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Maximum passages to return (default: 5, max: 10)",
+				},
+			},
+			"required": []string{"query"},
+		},
+	}
+
+	registry.Register(searchTool, func(ctx context.Context, args string) (string, error) {
+		var params SearchDocumentsArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		ownerID, err := documentOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+		limit := min(max(params.Limit, 1), 10)
+		if params.Limit == 0 {
+			limit = 5
+		}
+
+		matches, err := memory.SearchDocuments(ctx, ownerID, params.Query, limit)
+		if err != nil {
+			return "", fmt.Errorf("search documents: %w", err)
+		}
+		if len(matches) == 0 {
+			return "No matching passages in the document library.", nil
+		}
+
+		var b strings.Builder
+		for _, m := range matches {
+			fmt.Fprintf(&b, "--- %s (document %d, part %d) ---\n%s\n\n", m.DocumentName, m.DocumentID, m.Seq+1, m.Content)
+		}
+		return strings.TrimSpace(b.String()), nil
+	})
+
+	listTool := llm.Tool{
+		Name:        "list_documents",
+		Description: "List the documents in the user's library.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(listTool, func(ctx context.Context, args string) (string, error) {
+		ownerID, err := documentOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+		docs, err := memory.ListDocuments(ownerID)
+		if err != nil {
+			return "", fmt.Errorf("list documents: %w", err)
+		}
+		if len(docs) == 0 {
+			return "The document library is empty. Send a PDF in chat or index a file from storage to add one.", nil
+		}
+
+		var lines []string
+		for _, d := range docs {
+			lines = append(lines, fmt.Sprintf("- #%d %s (%d parts, added %s)", d.ID, d.Name, d.ChunkCount, d.CreatedAt.Format("2006-01-02")))
+		}
+		return strings.Join(lines, "\n"), nil
+	})
+
+	deleteTool := llm.Tool{
+		Name:        "delete_document",
+		Description: "Remove a document from the user's library. Only use when the user asks.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"document_id": map[string]any{
+					"type":        "integer",
+					"description": "ID from list_documents",
+				},
+			},
+			"required": []string{"document_id"},
+		},
+	}
+
+	registry.Register(deleteTool, func(ctx context.Context, args string) (string, error) {
+		var params DocumentIDArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		ownerID, err := documentOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+		if err := memory.DeleteDocument(params.DocumentID, ownerID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Removed document #%d from the library.", params.DocumentID), nil
+	})
+
+	registry.Cacheable("search_documents", 2*time.Minute)
+	registry.Invalidates("delete_document", "search_documents")
+
+	readTool := llm.Tool{
+		Name:        "read_document",
+		Description: "Read the text of a PDF, Word (.docx) or text file, either attached to the current message or in storage. Use it when the model can't read PDFs directly or to read specific pages. For scanned PDFs with no text, set images to look at the pages instead.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"space": map[string]any{
+					"type":        "string",
+					"enum":        []string{"user", "agent"},
+					"description": "Storage space of the file, with path",
+				},
+				"path": map[string]any{
+					"type":        "string",
+					"description": "Path to the file in storage. Leave empty to read a document attached to the current message.",
+				},
+				"media_index": map[string]any{
+					"type":        "integer",
+					"description": "Which attachment of the current message to read (default: the first document)",
+				},
+				"pages": map[string]any{
+					"type":        "string",
+					"description": "PDF pages to read, e.g. '3' or '2-5' (default: all, cut off at about 30k characters)",
+				},
+				"images": map[string]any{
+					"type":        "boolean",
+					"description": "Also show the PDF pages as images (up to 5 pages), for scans, charts and forms",
+				},
+			},
+		},
+	}
+
+	registry.Register(readTool, func(ctx context.Context, args string) (string, error) {
+		var params ReadDocumentArgs
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		data, mimeType, name, err := loadDocument(ctx, client, params)
+		if err != nil {
+			return "", err
+		}
+		if !documents.Supported(mimeType, name) {
+			return "", fmt.Errorf("%s is not a PDF, Word or text file", name)
+		}
+
+		first, last, err := parsePageRange(params.Pages)
+		if err != nil {
+			return "", err
+		}
+
+		isPDF := documents.IsPDF(mimeType, name)
+		var text string
+		var b strings.Builder
+		if isPDF {
+			pages, err := documents.PDFPageCount(ctx, data)
+			if err != nil {
+				return "", err
+			}
+			if first > pages {
+				return "", fmt.Errorf("%s only has %d pages", name, pages)
+			}
+			if last == 0 || last > pages {
+				last = pages
+			}
+			if first == 0 {
+				first = 1
+			}
+			if text, err = documents.PDFPages(ctx, data, first, last); err != nil {
+				return "", fmt.Errorf("extract text: %w", err)
+			}
+			fmt.Fprintf(&b, "📄 %s, pages %d-%d of %d\n\n", name, first, last, pages)
+		} else {
+			if params.Pages != "" || params.Images {
+				return "", fmt.Errorf("pages and images only work for PDFs")
+			}
+			if text, err = documents.ExtractText(ctx, data, mimeType, name); err != nil {
+				return "", fmt.Errorf("extract text: %w", err)
+			}
+			fmt.Fprintf(&b, "📄 %s\n\n", name)
+		}
+
+		text = strings.TrimSpace(text)
+		switch {
+		case text == "" && isPDF:
+			b.WriteString("(no text layer, this is probably a scan: set images to look at the pages)\n")
+		case text == "":
+			b.WriteString("(no text)\n")
+		case len(text) > maxReadChars:
+			b.WriteString(text[:maxReadChars])
+			b.WriteString("\n\n[Truncated: read further with pages]\n")
+		default:
+			b.WriteString(text)
+			b.WriteString("\n")
+		}
+
+		if params.Images {
+			if !CanAttach(ctx) {
+				b.WriteString("\n(page images skipped: the current model can't view images)")
+				return b.String(), nil
+			}
+			imageLast := min(last, first+maxPageImages-1)
+			images, err := documents.PDFPageImages(ctx, data, first, imageLast, pageImageDPI)
+			if err != nil {
+				return "", fmt.Errorf("render pages: %w", err)
+			}
+			for _, img := range images {
+				Attach(ctx, llm.MediaContent{Type: llm.MediaTypeImage, Data: img, MimeType: "image/png"})
+			}
+			fmt.Fprintf(&b, "\n(pages %d-%d attached as images)", first, first+len(images)-1)
+		}
+
+		return b.String(), nil
+	})
+
+	if client == nil {
+		return
+	}
+
+	indexTool := llm.Tool{
+		Name:        "index_document",
+		Description: "Add a PDF, Word (.docx) or text file from storage to the user's document library so it can be searched with search_documents.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"space": map[string]any{
+					"type":        "string",
+					"enum":        []string{"user", "agent"},
+					"description": "Storage space: 'user' for user files, 'agent' for agent files",
+				},
+				"path": map[string]any{
+					"type":        "string",
+					"description": "Path to the file in storage",
+				},
+			},
+			"required": []string{"space", "path"},
+		},
+	}
+
+	registry.Register(indexTool, func(ctx context.Context, args string) (string, error) {
+		var params IndexDocumentArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		name := path.Base(params.Path)
+		if !documents.Supported("", name) {
+			return "", fmt.Errorf("%s is not a PDF, Word or text file", name)
+		}
+
+		bucket := client.UserBucket()
+		if params.Space == "agent" {
+			bucket = client.AgentBucket()
+		}
+
+		data, err := client.Download(ctx, bucket, params.Path)
+		if err != nil {
+			return "", fmt.Errorf("download document: %w", err)
+		}
+
+		text, err := documents.ExtractText(ctx, data, "", name)
+		if err != nil {
+			return "", fmt.Errorf("extract text: %w", err)
+		}
+
+		chunks := documents.Chunk(text)
+		if len(chunks) == 0 {
+			return "", fmt.Errorf("%s has no text to index (scanned PDFs aren't supported)", name)
+		}
+
+		ownerID, err := documentOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+		doc, err := memory.AddDocument(ctx, ownerID, name, "storage:"+params.Space+"/"+params.Path, chunks)
+		if err != nil {
+			return "", fmt.Errorf("index document: %w", err)
+		}
+
+		return fmt.Sprintf("Indexed %s as document #%d (%d parts).", name, doc.ID, doc.ChunkCount), nil
+	})
+
+	registry.Invalidates("index_document", "search_documents")
+}
+
+// documentOwner is the user entity that owns documents in this context. An
+// owner of 0 would make a document everyone's, so a missing entity is an error.
+func documentOwner(ctx context.Context, memory *sheldonmem.Store) (int64, error) {
+	entity, err := memory.FindEntityByName(UserEntityName(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("no user to file documents under: %w", err)
+	}
+	return entity.ID, nil
+}
+
+// loadDocument fetches a document from storage or from the current message's attachments
+func loadDocument(ctx context.Context, client *storage.Client, params ReadDocumentArgs) (data []byte, mimeType, name string, err error) {
+	if params.Path != "" {
+		if client == nil {
+			return nil, "", "", fmt.Errorf("storage is not enabled, send the document in chat instead")
+		}
+		bucket := client.UserBucket()
+		if params.Space == "agent" {
+			bucket = client.AgentBucket()
+		}
+		data, err := client.Download(ctx, bucket, params.Path)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("download document: %w", err)
+		}
+		return data, "", path.Base(params.Path), nil
+	}
+
+	media := MediaFromContext(ctx)
+	if params.MediaIndex != nil {
+		if *params.MediaIndex < 0 || *params.MediaIndex >= len(media) {
+			return nil, "", "", fmt.Errorf("media index %d out of range (have %d items)", *params.MediaIndex, len(media))
+		}
+		m := media[*params.MediaIndex]
+		return m.Data, m.MimeType, attachmentName(m), nil
+	}
+	for _, m := range media {
+		if m.Type == llm.MediaTypePDF || m.Type == llm.MediaTypeDocument {
+			return m.Data, m.MimeType, attachmentName(m), nil
+		}
+	}
+	return nil, "", "", fmt.Errorf("no document attached to the current message, pass a storage path")
+}
+
+func attachmentName(m llm.MediaContent) string {
+	if m.Filename != "" {
+		return m.Filename
+	}
+	return "attachment"
+}
+
+// parsePageRange reads "3" or "2-5"; an empty range means the whole document
+func parsePageRange(pages string) (first, last int, err error) {
+	pages = strings.TrimSpace(pages)
+	if pages == "" {
+		return 0, 0, nil
+	}
+
+	from, to, isRange := strings.Cut(pages, "-")
+	if first, err = strconv.Atoi(strings.TrimSpace(from)); err != nil || first < 1 {
+		return 0, 0, fmt.Errorf("invalid pages %q: use e.g. 3 or 2-5", pages)
+	}
+	last = first
+	if isRange {
+		if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || last < first {
+			return 0, 0, fmt.Errorf("invalid pages %q: use e.g. 3 or 2-5", pages)
+		}
+	}
+	return first, last, nil
+}
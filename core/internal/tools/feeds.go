@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/feeds"
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+// defaultFeedDigestTime is when new items are sent if the user doesn't pick a time
+const defaultFeedDigestTime = "07:00"
+
+type SubscribeFeedArgs struct {
+	URL        string `json:"url"`
+	DigestTime string `json:"digest_time,omitempty"`
+}
+
+type UnsubscribeFeedArgs struct {
+	FeedID int64 `json:"feed_id"`
+}
+
+// RegisterFeedTools registers subscribe_feed, list_feeds and unsubscribe_feed.
+// New items are collected by the poller and delivered by a daily feed-digest cron.
+func RegisterFeedTools(registry *Registry, store *feeds.Store, poller *feeds.Poller, cronStore *cron.Store) {
+	subscribeTool := llm.Tool{
+		Name:        "subscribe_feed",
+		Description: "Subscribe the user to an RSS or Atom feed. New items are collected in the background and sent as a daily digest, e.g. for a morning briefing. Existing items aren't sent, only ones published after subscribing.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url": map[string]any{
+					"type":        "string",
+					"description": "Feed URL (RSS or Atom)",
+				},
+				"digest_time": map[string]any{
+					"type":        "string",
+					"description": "Time of day to send new items as HH:MM in the user's timezone (default: 07:00). Applies to all of the user's feeds.",
+				},
+			},
+			"required": []string{"url"},
+		},
+	}
+
+	registry.Register(subscribeTool, func(ctx context.Context, args string) (string, error) {
+		var params SubscribeFeedArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		feedURL := strings.TrimSpace(params.URL)
+		if u, err := url.Parse(feedURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("invalid feed URL %q", params.URL)
+		}
+
+		schedule, err := feedDigestSchedule(params.DigestTime)
+		if err != nil {
+			return "", err
+		}
+
+		title, items, err := poller.Fetch(ctx, feedURL)
+		if err != nil {
+			return "", err
+		}
+
+		feed, err := store.Subscribe(chatID, feedURL, title)
+		if errors.Is(err, feeds.ErrAlreadySubscribed) {
+			return fmt.Sprintf("Already subscribed to %s.", feedURL), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("subscribe: %w", err)
+		}
+
+		// the current backlog counts as seen so the first digest only has new items
+		if _, err := store.AddItems(feed.ID, items, true); err != nil {
+			return "", fmt.Errorf("store feed items: %w", err)
+		}
+		if err := store.RecordPoll(feed.ID, title, nil); err != nil {
+			return "", fmt.Errorf("record poll: %w", err)
+		}
+
+		digestTime, err := ensureFeedDigest(cronStore, chatID, schedule, params.DigestTime != "")
+		if err != nil {
+			return "", fmt.Errorf("schedule feed digest: %w", err)
+		}
+
+		name := title
+		if name == "" {
+			name = feedURL
+		}
+		return fmt.Sprintf("Subscribed to %s (feed %d, %d items right now). New items will be sent daily at %s.", name, feed.ID, len(items), digestTime), nil
+	})
+
+	listTool := llm.Tool{
+		Name:        "list_feeds",
+		Description: "List the user's feed subscriptions with their IDs and when they were last checked",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(listTool, func(ctx context.Context, args string) (string, error) {
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		list, err := store.List(chatID)
+		if err != nil {
+			return "", fmt.Errorf("list feeds: %w", err)
+		}
+		if len(list) == 0 {
+			return "No feed subscriptions.", nil
+		}
+
+		var sb strings.Builder
+		for _, f := range list {
+			name := f.Title
+			if name == "" {
+				name = f.URL
+			}
+			fmt.Fprintf(&sb, "- [%d] %s (%s)", f.ID, name, f.URL)
+			if f.LastError != "" {
+				fmt.Fprintf(&sb, " - last check failed: %s", f.LastError)
+			} else if f.LastPolled != nil {
+				fmt.Fprintf(&sb, " - checked %s ago", time.Since(*f.LastPolled).Round(time.Minute))
+			}
+			sb.WriteString("\n")
+		}
+
+		if c, err := cronStore.GetByKeyword(feeds.DigestKeyword, chatID); err == nil && c != nil {
+			fmt.Fprintf(&sb, "\nDigest schedule: %s", c.Schedule)
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	unsubscribeTool := llm.Tool{
+		Name:        "unsubscribe_feed",
+		Description: "Stop following a feed. Get the feed_id from list_feeds.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"feed_id": map[string]any{
+					"type":        "integer",
+					"description": "ID from list_feeds",
+				},
+			},
+			"required": []string{"feed_id"},
+		},
+	}
+
+	registry.Register(unsubscribeTool, func(ctx context.Context, args string) (string, error) {
+		var params UnsubscribeFeedArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		feed, err := store.Unsubscribe(chatID, params.FeedID)
+		if errors.Is(err, feeds.ErrFeedNotFound) {
+			return "", fmt.Errorf("no feed with id %d", params.FeedID)
+		}
+		if err != nil {
+			return "", fmt.Errorf("unsubscribe: %w", err)
+		}
+
+		remaining, err := store.List(chatID)
+		if err == nil && len(remaining) == 0 {
+			if err := cronStore.DeleteByKeyword(feeds.DigestKeyword, chatID); err != nil {
+				return "", fmt.Errorf("remove feed digest: %w", err)
+			}
+		}
+
+		name := feed.Title
+		if name == "" {
+			name = feed.URL
+		}
+		return fmt.Sprintf("Unsubscribed from %s.", name), nil
+	})
+
+	registry.Cacheable("list_feeds", 2*time.Minute)
+	registry.Invalidates("subscribe_feed", "list_feeds")
+	registry.Invalidates("unsubscribe_feed", "list_feeds")
+}
+
+// feedDigestSchedule turns HH:MM into a daily cron expression
+func feedDigestSchedule(at string) (string, error) {
+	if at == "" {
+		at = defaultFeedDigestTime
+	}
+	t, err := time.Parse("15:04", strings.TrimSpace(at))
+	if err != nil {
+		return "", fmt.Errorf("invalid digest_time %q: use HH:MM", at)
+	}
+	return fmt.Sprintf("0 %d %d * * *", t.Minute(), t.Hour()), nil
+}
+
+// ensureFeedDigest makes sure the chat has a digest cron and returns its time of day.
+// An existing schedule is only replaced when the user asked for a specific time.
+func ensureFeedDigest(cronStore *cron.Store, chatID int64, schedule string, replace bool) (string, error) {
+	existing, err := cronStore.GetByKeyword(feeds.DigestKeyword, chatID)
+	if err != nil {
+		return "", err
+	}
+
+	if existing != nil && (!replace || existing.Schedule == schedule) {
+		return scheduleTimeOfDay(existing.Schedule), nil
+	}
+	if existing != nil {
+		if err := cronStore.Delete(existing.ID); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := cronStore.Create(feeds.DigestKeyword, schedule, chatID, nil); err != nil {
+		return "", err
+	}
+	return scheduleTimeOfDay(schedule), nil
+}
+
+func scheduleTimeOfDay(schedule string) string {
+	var minute, hour int
+	if _, err := fmt.Sscanf(schedule, "0 %d %d", &minute, &hour); err != nil {
+		return schedule
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
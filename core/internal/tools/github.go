@@ -1,17 +1,20 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/config"
 	"github.com/bowerhall/sheldon/internal/llm"
 )
 
-// RegisterGitHubTools registers GitHub-related tools (PR, repo management)
+// RegisterGitHubTools registers GitHub-related tools (PRs, issues, Actions, repo management)
 func RegisterGitHubTools(registry *Registry, cfg *config.GitConfig) {
 	if cfg.Token == "" {
 		return // no git token, skip registration
@@ -240,6 +243,460 @@ func RegisterGitHubTools(registry *Registry, cfg *config.GitConfig) {
 
 		return fmt.Sprintf("Repository created: %s/%s", org, params.Name), nil
 	})
+
+	registerIssueTools(registry, cfg)
+	registerActionsTools(registry, cfg)
+}
+
+// registerIssueTools registers list_issues, open_issue and comment_issue
+func registerIssueTools(registry *Registry, cfg *config.GitConfig) {
+	listIssuesTool := llm.Tool{
+		Name:        "list_issues",
+		Description: "List issues on a repository",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"repo": map[string]any{
+					"type":        "string",
+					"description": "Repository name (e.g., 'sheldon')",
+				},
+				"state": map[string]any{
+					"type":        "string",
+					"enum":        []string{"open", "closed", "all"},
+					"description": "Issue state filter (default: 'open')",
+				},
+				"label": map[string]any{
+					"type":        "string",
+					"description": "Only issues with this label",
+				},
+			},
+			"required": []string{"repo"},
+		},
+	}
+
+	registry.Register(listIssuesTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Repo  string `json:"repo"`
+			State string `json:"state"`
+			Label string `json:"label"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if params.State == "" {
+			params.State = "open"
+		}
+
+		fullRepo, err := githubRepo(cfg, params.Repo)
+		if err != nil {
+			return "", err
+		}
+
+		cmdArgs := []string{"issue", "list",
+			"--repo", fullRepo,
+			"--state", params.State,
+			"--limit", "30",
+			"--json", "number,title,state,author,labels,url",
+		}
+		if params.Label != "" {
+			cmdArgs = append(cmdArgs, "--label", params.Label)
+		}
+
+		output, err := runGH(ctx, cfg, cmdArgs...)
+		if err != nil {
+			return "", fmt.Errorf("failed to list issues: %w", err)
+		}
+
+		var issues []struct {
+			Number int    `json:"number"`
+			Title  string `json:"title"`
+			Author struct {
+				Login string `json:"login"`
+			} `json:"author"`
+			Labels []struct {
+				Name string `json:"name"`
+			} `json:"labels"`
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(output, &issues); err != nil {
+			return string(output), nil
+		}
+
+		if len(issues) == 0 {
+			return fmt.Sprintf("No %s issues in %s", params.State, params.Repo), nil
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Issues in %s (%s):\n", params.Repo, params.State)
+		for _, issue := range issues {
+			fmt.Fprintf(&sb, "- #%d: %s (by %s)", issue.Number, issue.Title, issue.Author.Login)
+			if len(issue.Labels) > 0 {
+				names := make([]string, len(issue.Labels))
+				for i, l := range issue.Labels {
+					names[i] = l.Name
+				}
+				fmt.Fprintf(&sb, " [%s]", strings.Join(names, ", "))
+			}
+			fmt.Fprintf(&sb, "\n  %s\n", issue.URL)
+		}
+
+		return sb.String(), nil
+	})
+
+	openIssueTool := llm.Tool{
+		Name:        "open_issue",
+		Description: "Open an issue on a repository, e.g. to track a bug or follow-up work",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"repo": map[string]any{
+					"type":        "string",
+					"description": "Repository name (e.g., 'sheldon')",
+				},
+				"title": map[string]any{
+					"type":        "string",
+					"description": "Issue title",
+				},
+				"body": map[string]any{
+					"type":        "string",
+					"description": "Issue description",
+				},
+				"labels": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Labels to add (must already exist on the repo)",
+				},
+			},
+			"required": []string{"repo", "title"},
+		},
+	}
+
+	registry.Register(openIssueTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Repo   string   `json:"repo"`
+			Title  string   `json:"title"`
+			Body   string   `json:"body"`
+			Labels []string `json:"labels"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		fullRepo, err := githubRepo(cfg, params.Repo)
+		if err != nil {
+			return "", err
+		}
+
+		cmdArgs := []string{"issue", "create",
+			"--repo", fullRepo,
+			"--title", params.Title,
+			"--body", params.Body,
+		}
+		for _, label := range params.Labels {
+			cmdArgs = append(cmdArgs, "--label", label)
+		}
+
+		output, err := runGH(ctx, cfg, cmdArgs...)
+		if err != nil {
+			return "", fmt.Errorf("failed to create issue: %w", err)
+		}
+
+		return fmt.Sprintf("Issue created: %s", strings.TrimSpace(string(output))), nil
+	})
+
+	commentTool := llm.Tool{
+		Name:        "comment_issue",
+		Description: "Comment on an issue or pull request",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"repo": map[string]any{
+					"type":        "string",
+					"description": "Repository name (e.g., 'sheldon')",
+				},
+				"number": map[string]any{
+					"type":        "integer",
+					"description": "Issue or PR number",
+				},
+				"body": map[string]any{
+					"type":        "string",
+					"description": "Comment text (markdown)",
+				},
+			},
+			"required": []string{"repo", "number", "body"},
+		},
+	}
+
+	registry.Register(commentTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Repo   string `json:"repo"`
+			Number int    `json:"number"`
+			Body   string `json:"body"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if strings.TrimSpace(params.Body) == "" {
+			return "", fmt.Errorf("comment body is required")
+		}
+
+		fullRepo, err := githubRepo(cfg, params.Repo)
+		if err != nil {
+			return "", err
+		}
+
+		// gh issue comment works for PRs too, they share numbering
+		output, err := runGH(ctx, cfg, "issue", "comment", strconv.Itoa(params.Number),
+			"--repo", fullRepo,
+			"--body", params.Body,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to comment: %w", err)
+		}
+
+		return fmt.Sprintf("Comment added: %s", strings.TrimSpace(string(output))), nil
+	})
+
+	registry.Cacheable("list_issues", time.Minute)
+	registry.Invalidates("open_issue", "list_issues")
+}
+
+// registerActionsTools registers ci_status and rerun_workflow for following PRs to green
+func registerActionsTools(registry *Registry, cfg *config.GitConfig) {
+	ciStatusTool := llm.Tool{
+		Name:        "ci_status",
+		Description: "Check GitHub Actions status for a repository. Give a PR number to see its checks, or a branch to see recent workflow runs. Use this after open_pr to follow a PR until CI passes.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"repo": map[string]any{
+					"type":        "string",
+					"description": "Repository name (e.g., 'sheldon')",
+				},
+				"pr": map[string]any{
+					"type":        "integer",
+					"description": "PR number to show checks for",
+				},
+				"branch": map[string]any{
+					"type":        "string",
+					"description": "Branch to show workflow runs for (default: all branches)",
+				},
+			},
+			"required": []string{"repo"},
+		},
+	}
+
+	registry.Register(ciStatusTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Repo   string `json:"repo"`
+			PR     int    `json:"pr"`
+			Branch string `json:"branch"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		fullRepo, err := githubRepo(cfg, params.Repo)
+		if err != nil {
+			return "", err
+		}
+
+		if params.PR > 0 {
+			return prChecks(ctx, cfg, fullRepo, params.PR)
+		}
+
+		cmdArgs := []string{"run", "list",
+			"--repo", fullRepo,
+			"--limit", "10",
+			"--json", "databaseId,workflowName,headBranch,event,status,conclusion,createdAt,url",
+		}
+		if params.Branch != "" {
+			cmdArgs = append(cmdArgs, "--branch", params.Branch)
+		}
+
+		output, err := runGH(ctx, cfg, cmdArgs...)
+		if err != nil {
+			return "", fmt.Errorf("failed to list workflow runs: %w", err)
+		}
+
+		var runs []struct {
+			ID           int64     `json:"databaseId"`
+			WorkflowName string    `json:"workflowName"`
+			HeadBranch   string    `json:"headBranch"`
+			Event        string    `json:"event"`
+			Status       string    `json:"status"`
+			Conclusion   string    `json:"conclusion"`
+			CreatedAt    time.Time `json:"createdAt"`
+			URL          string    `json:"url"`
+		}
+		if err := json.Unmarshal(output, &runs); err != nil {
+			return string(output), nil
+		}
+
+		if len(runs) == 0 {
+			return fmt.Sprintf("No workflow runs in %s", params.Repo), nil
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Workflow runs in %s:\n", params.Repo)
+		for _, run := range runs {
+			result := run.Status
+			if run.Status == "completed" {
+				result = run.Conclusion
+			}
+			fmt.Fprintf(&sb, "- %s on %s (%s): %s, %s ago [run %d]\n  %s\n",
+				run.WorkflowName, run.HeadBranch, run.Event, result,
+				time.Since(run.CreatedAt).Round(time.Minute), run.ID, run.URL)
+		}
+
+		return sb.String(), nil
+	})
+
+	rerunTool := llm.Tool{
+		Name:        "rerun_workflow",
+		Description: "Re-run a GitHub Actions workflow run, e.g. after a flaky failure. Get the run ID from ci_status.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"repo": map[string]any{
+					"type":        "string",
+					"description": "Repository name (e.g., 'sheldon')",
+				},
+				"run_id": map[string]any{
+					"type":        "integer",
+					"description": "Workflow run ID from ci_status",
+				},
+				"all_jobs": map[string]any{
+					"type":        "boolean",
+					"description": "Re-run every job instead of only the failed ones (default: false)",
+				},
+			},
+			"required": []string{"repo", "run_id"},
+		},
+	}
+
+	registry.Register(rerunTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Repo    string `json:"repo"`
+			RunID   int64  `json:"run_id"`
+			AllJobs bool   `json:"all_jobs"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		fullRepo, err := githubRepo(cfg, params.Repo)
+		if err != nil {
+			return "", err
+		}
+
+		cmdArgs := []string{"run", "rerun", strconv.FormatInt(params.RunID, 10), "--repo", fullRepo}
+		if !params.AllJobs {
+			cmdArgs = append(cmdArgs, "--failed")
+		}
+
+		if _, err := runGH(ctx, cfg, cmdArgs...); err != nil {
+			return "", fmt.Errorf("failed to re-run workflow: %w", err)
+		}
+
+		if params.AllJobs {
+			return fmt.Sprintf("Re-running all jobs of run %d in %s", params.RunID, params.Repo), nil
+		}
+		return fmt.Sprintf("Re-running failed jobs of run %d in %s", params.RunID, params.Repo), nil
+	})
+
+	registry.Cacheable("ci_status", 30*time.Second)
+	registry.Invalidates("rerun_workflow", "ci_status")
+}
+
+// prChecks summarizes the status checks on a PR, listing failures first
+func prChecks(ctx context.Context, cfg *config.GitConfig, fullRepo string, pr int) (string, error) {
+	output, err := runGH(ctx, cfg, "pr", "checks", strconv.Itoa(pr),
+		"--repo", fullRepo,
+		"--json", "name,workflow,state,bucket,link",
+	)
+	// gh exits non-zero when checks are failing or pending but still prints them
+	var checks []struct {
+		Name     string `json:"name"`
+		Workflow string `json:"workflow"`
+		State    string `json:"state"`
+		Bucket   string `json:"bucket"`
+		Link     string `json:"link"`
+	}
+	if jsonErr := json.Unmarshal(output, &checks); jsonErr != nil {
+		if err != nil {
+			return "", fmt.Errorf("failed to get PR checks: %w", err)
+		}
+		return string(output), nil
+	}
+
+	if len(checks) == 0 {
+		return fmt.Sprintf("No checks reported on PR #%d", pr), nil
+	}
+
+	counts := map[string]int{}
+	var failing, pending []string
+	for _, c := range checks {
+		counts[c.Bucket]++
+		name := c.Name
+		if c.Workflow != "" {
+			name = c.Workflow + " / " + c.Name
+		}
+		switch c.Bucket {
+		case "fail", "cancel":
+			failing = append(failing, fmt.Sprintf("- %s: %s\n  %s", name, strings.ToLower(c.State), c.Link))
+		case "pending":
+			pending = append(pending, "- "+name)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "PR #%d checks: %d passed, %d failed, %d pending, %d skipped\n",
+		pr, counts["pass"], counts["fail"]+counts["cancel"], counts["pending"], counts["skipping"])
+	if len(failing) > 0 {
+		sb.WriteString("\nFailing:\n" + strings.Join(failing, "\n") + "\n")
+	}
+	if len(pending) > 0 {
+		sb.WriteString("\nPending:\n" + strings.Join(pending, "\n") + "\n")
+	}
+	if len(failing) == 0 && len(pending) == 0 {
+		sb.WriteString("All checks passed.")
+	}
+
+	return sb.String(), nil
+}
+
+// githubRepo resolves a repo name against the configured org
+func githubRepo(cfg *config.GitConfig, repo string) (string, error) {
+	org := extractOrg(cfg.OrgURL)
+	if org == "" {
+		return "", fmt.Errorf("GIT_ORG_URL not configured or invalid")
+	}
+	if repo == "" {
+		return "", fmt.Errorf("repo is required")
+	}
+	return fmt.Sprintf("%s/%s", org, repo), nil
+}
+
+// runGH runs the gh CLI with the configured token, returning stdout.
+// On failure the error carries gh's stderr and stdout is still returned.
+func runGH(ctx context.Context, cfg *config.GitConfig, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Env = append(cmd.Environ(), "GH_TOKEN="+cfg.Token)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return output, fmt.Errorf("%s", msg)
+		}
+		return output, err
+	}
+	return output, nil
 }
 
 // extractOrg extracts org name from URL like "https://github.com/bowerhall" -> "bowerhall"
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/browser"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// maxGraphNodes keeps the rendered image readable
+const maxGraphNodes = 40
+
+// PhotoSender can send images to users
+type PhotoSender interface {
+	SendPhoto(chatID int64, data []byte, caption string) error
+}
+
+type GraphArgs struct {
+	Depth int `json:"depth,omitempty"`
+}
+
+// graphPage renders a mermaid diagram client-side; the browser sandbox screenshots it.
+// Mermaid comes from the jsdelivr CDN, so drawing needs the sandbox to reach it;
+// when it can't, the screenshot shows the diagram source. See docs/security.md.
+const graphPage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8">
+<script src="https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.min.js"></script>
+<style>body{margin:0;padding:24px;background:#fff;font-family:sans-serif}</style>
+</head><body>
+<pre class="mermaid">
+%s
+</pre>
+<script>mermaid.initialize({startOnLoad:true,theme:"neutral"});</script>
+</body></html>`
+
+// RegisterGraphTool registers a tool that draws the user's entity graph and sends it as an image.
+// Without a browser sandbox the mermaid source is returned instead.
+func RegisterGraphTool(registry *Registry, memory *sheldonmem.Store, runner *browser.Runner, sender PhotoSender) {
+	tool := llm.Tool{
+		Name:        "show_memory_graph",
+		Description: "Draw the people, places and organizations you know about the user and how they're connected, and send it to the user as an image. Use when the user asks what you know about their relationships or wants to see their memory graph.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"depth": map[string]any{
+					"type":        "integer",
+					"description": "How many hops from the user to include (1-3). Default: 2.",
+				},
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params GraphArgs
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		depth := params.Depth
+		if depth < 1 {
+			depth = 2
+		}
+		if depth > 3 {
+			depth = 3
+		}
+
+		user, err := memory.FindEntityByName(UserEntityName(ctx))
+		if err != nil {
+			return "I don't have anything in memory about you yet.", nil
+		}
+
+		diagram, nodes, err := buildMemoryGraph(memory, user, depth)
+		if err != nil {
+			return "", fmt.Errorf("build graph: %w", err)
+		}
+		if nodes < 2 {
+			return "I don't know about anyone or anything connected to you yet.", nil
+		}
+
+		if runner == nil {
+			return fmt.Sprintf("Browser sandbox is disabled, so the graph can't be rendered. Mermaid source (%d entities):\n\n```mermaid\n%s\n```", nodes, diagram), nil
+		}
+
+		png, err := runner.RenderHTML(ctx, fmt.Sprintf(graphPage, diagram))
+		if err != nil {
+			return "", fmt.Errorf("render graph: %w", err)
+		}
+
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		if err := sender.SendPhoto(chatID, png, "What I know about your connections"); err != nil {
+			return "", fmt.Errorf("send graph: %w", err)
+		}
+
+		return fmt.Sprintf("Sent memory graph with %d entities", nodes), nil
+	})
+}
+
+// buildMemoryGraph walks edges outward from the user and returns a mermaid flowchart.
+// Entities owned by other users are left out, matching recall scoping.
+func buildMemoryGraph(memory *sheldonmem.Store, user *sheldonmem.Entity, depth int) (string, int, error) {
+	visible := func(e *sheldonmem.Entity) bool {
+		return e.OwnerID == nil || *e.OwnerID == user.ID
+	}
+
+	entities := map[int64]*sheldonmem.Entity{user.ID: user}
+	order := []int64{user.ID}
+	seenEdges := make(map[int64]bool)
+	var edges []*sheldonmem.Edge
+
+	frontier := []int64{user.ID}
+	for d := 0; d < depth && len(frontier) > 0; d++ {
+		var next []int64
+		for _, id := range frontier {
+			from, err := memory.GetEdgesFrom(id)
+			if err != nil {
+				return "", 0, err
+			}
+			to, err := memory.GetEdgesTo(id)
+			if err != nil {
+				return "", 0, err
+			}
+
+			for _, e := range append(from, to...) {
+				if seenEdges[e.ID] {
+					continue
+				}
+
+				other := e.TargetID
+				if other == id {
+					other = e.SourceID
+				}
+
+				if _, ok := entities[other]; !ok {
+					if len(order) >= maxGraphNodes {
+						continue
+					}
+					entity, err := memory.GetEntity(other)
+					if err != nil || !visible(entity) {
+						continue
+					}
+					entities[other] = entity
+					order = append(order, other)
+					next = append(next, other)
+				}
+
+				seenEdges[e.ID] = true
+				edges = append(edges, e)
+			}
+		}
+		frontier = next
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, id := range order {
+		label := entities[id].Name
+		if id == user.ID {
+			label = "You"
+		}
+		fmt.Fprintf(&b, "  n%d[\"%s\"]\n", id, mermaidEscape(label))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  n%d -->|\"%s\"| n%d\n", e.SourceID, mermaidEscape(strings.ReplaceAll(e.Relation, "_", " ")), e.TargetID)
+	}
+
+	return strings.TrimRight(b.String(), "\n"), len(order), nil
+}
+
+// mermaidEscape makes a label safe inside a quoted mermaid node or edge label
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "<", "#lt;")
+	s = strings.ReplaceAll(s, ">", "#gt;")
+	return strings.ReplaceAll(s, "\n", " ")
+}
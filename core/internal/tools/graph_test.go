@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bowerhall/sheldonmem"
+)
+
+func TestMermaidEscape(t *testing.T) {
+	tests := map[string]string{
+		`Acme "Labs"`:         "Acme #quot;Labs#quot;",
+		"<script>":            "#lt;script#gt;",
+		"two\nlines":          "two lines",
+		"Zoë & Jörg's bakery": "Zoë & Jörg's bakery",
+	}
+	for in, want := range tests {
+		if got := mermaidEscape(in); got != want {
+			t.Errorf("mermaidEscape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBuildMemoryGraph(t *testing.T) {
+	memory, err := sheldonmem.Open(filepath.Join(t.TempDir(), "memory.db"))
+	if err != nil {
+		t.Fatalf("open memory: %v", err)
+	}
+	defer memory.Close()
+
+	entity := func(name, kind string, owner *sheldonmem.Entity) *sheldonmem.Entity {
+		e, err := memory.CreateEntity(name, kind, 6, "")
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if owner != nil {
+			if err := memory.SetEntityOwner(e.ID, owner.ID); err != nil {
+				t.Fatalf("own %s: %v", name, err)
+			}
+		}
+		return e
+	}
+	edge := func(from, to *sheldonmem.Entity, relation string) {
+		if _, err := memory.AddEdge(from.ID, to.ID, relation, 1, ""); err != nil {
+			t.Fatalf("edge %s: %v", relation, err)
+		}
+	}
+
+	user := entity("user_telegram_1", "user", nil)
+	memory.SetEntityOwner(user.ID, user.ID)
+	other := entity("user_telegram_2", "user", nil)
+	memory.SetEntityOwner(other.ID, other.ID)
+
+	sarah := entity(`Sarah "Sis"`, "person", user)
+	acme := entity("Acme", "organization", nil)
+	berlin := entity("Berlin", "place", user)
+	secret := entity("Dr. Who", "person", other)
+
+	edge(user, sarah, "sister_of")
+	edge(sarah, acme, "works_at")
+	edge(acme, berlin, "based_in")
+	edge(user, secret, "knows")
+
+	diagram, nodes, err := buildMemoryGraph(memory, user, 2)
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+
+	// two hops reach Sarah and Acme but not Berlin; the other user's entity is hidden
+	if nodes != 3 {
+		t.Errorf("nodes = %d, want 3\n%s", nodes, diagram)
+	}
+	for _, want := range []string{
+		"graph LR",
+		`["You"]`,
+		`["Sarah #quot;Sis#quot;"]`,
+		`["Acme"]`,
+		`-->|"sister of"|`,
+		`-->|"works at"|`,
+	} {
+		if !strings.Contains(diagram, want) {
+			t.Errorf("diagram missing %q:\n%s", want, diagram)
+		}
+	}
+	for _, hidden := range []string{"Berlin", "Dr. Who", "knows", "user_telegram"} {
+		if strings.Contains(diagram, hidden) {
+			t.Errorf("diagram shows %q:\n%s", hidden, diagram)
+		}
+	}
+
+	if _, nodes, _ := buildMemoryGraph(memory, user, 3); nodes != 4 {
+		t.Errorf("depth 3 nodes = %d, want 4 with Berlin", nodes)
+	}
+}
@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/geo"
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+type GeocodeArgs struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+type ReverseGeocodeArgs struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type TravelTimeArgs struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Mode          string `json:"mode,omitempty"`
+	ArriveBy      string `json:"arrive_by,omitempty"`
+	BufferMinutes int    `json:"buffer_minutes,omitempty"`
+	Remind        bool   `json:"remind,omitempty"`
+}
+
+// RegisterLocationTools registers geocode, reverse_geocode and travel_time.
+// With a cron store, travel_time can also schedule a reminder for when to leave.
+func RegisterLocationTools(registry *Registry, client *geo.Client, cronStore *cron.Store, timezone *time.Location) {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+
+	geocodeTool := llm.Tool{
+		Name:        "geocode",
+		Description: "Look up an address or place name and get its full address and coordinates. Include the city to avoid matching a place with the same name elsewhere.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Address or place, e.g. 'British Museum, London' or '221B Baker Street, London'",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Maximum matches to return (default: 3, max: 10)",
+				},
+			},
+			"required": []string{"query"},
+		},
+	}
+
+	registry.Register(geocodeTool, func(ctx context.Context, args string) (string, error) {
+		var params GeocodeArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if strings.TrimSpace(params.Query) == "" {
+			return "", fmt.Errorf("query is required")
+		}
+
+		limit := params.Limit
+		if limit <= 0 {
+			limit = 3
+		}
+		limit = min(limit, 10)
+
+		places, err := client.Search(ctx, params.Query, limit)
+		if errors.Is(err, geo.ErrNotFound) {
+			return fmt.Sprintf("No matches for %q.", params.Query), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var sb strings.Builder
+		for _, p := range places {
+			fmt.Fprintf(&sb, "- %s (%.5f,%.5f)\n", p.Name, p.Lat, p.Lon)
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	reverseTool := llm.Tool{
+		Name:        "reverse_geocode",
+		Description: "Find the address at a latitude/longitude, e.g. when the user shares their location",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"lat": map[string]any{
+					"type":        "number",
+					"description": "Latitude in decimal degrees",
+				},
+				"lon": map[string]any{
+					"type":        "number",
+					"description": "Longitude in decimal degrees",
+				},
+			},
+			"required": []string{"lat", "lon"},
+		},
+	}
+
+	registry.Register(reverseTool, func(ctx context.Context, args string) (string, error) {
+		var params ReverseGeocodeArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		place, err := client.Reverse(ctx, params.Lat, params.Lon)
+		if errors.Is(err, geo.ErrNotFound) {
+			return "No address found at that location.", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return place.Name, nil
+	})
+
+	travelTool := llm.Tool{
+		Name:        "travel_time",
+		Description: "Estimate how long it takes to get from one place to another. Give arrive_by to work out when the user needs to leave, and remind=true to schedule a reminder at that time. Estimates don't include live traffic or public transport, so suggest a buffer for busy routes.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"from": map[string]any{
+					"type":        "string",
+					"description": "Starting address or 'lat,lon'. Use what you know about where the user lives or works if they don't say.",
+				},
+				"to": map[string]any{
+					"type":        "string",
+					"description": "Destination address or 'lat,lon'",
+				},
+				"mode": map[string]any{
+					"type":        "string",
+					"enum":        geo.Profiles,
+					"description": "How the user is travelling (default: driving)",
+				},
+				"arrive_by": map[string]any{
+					"type":        "string",
+					"description": "When the user needs to be there, as YYYY-MM-DDTHH:MM in their timezone",
+				},
+				"buffer_minutes": map[string]any{
+					"type":        "integer",
+					"description": "Extra time to allow on top of the estimate, e.g. for parking or traffic",
+				},
+				"remind": map[string]any{
+					"type":        "boolean",
+					"description": "Schedule a one-time reminder at the leave-by time (needs arrive_by)",
+				},
+			},
+			"required": []string{"from", "to"},
+		},
+	}
+
+	registry.Register(travelTool, func(ctx context.Context, args string) (string, error) {
+		tz := TimezoneFromContext(ctx, timezone)
+		var params TravelTimeArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		mode := params.Mode
+		if mode == "" {
+			mode = "driving"
+		}
+		if !slices.Contains(geo.Profiles, mode) {
+			return "", fmt.Errorf("invalid mode %q: use %s", mode, strings.Join(geo.Profiles, ", "))
+		}
+
+		var arriveBy time.Time
+		if params.ArriveBy != "" {
+			t, err := parseEventTime(params.ArriveBy, tz)
+			if err != nil {
+				return "", err
+			}
+			arriveBy = t
+		}
+		if params.Remind && arriveBy.IsZero() {
+			return "", fmt.Errorf("arrive_by is required to schedule a reminder")
+		}
+
+		from, err := client.Resolve(ctx, params.From)
+		if err != nil {
+			return "", err
+		}
+		to, err := client.Resolve(ctx, params.To)
+		if err != nil {
+			return "", err
+		}
+
+		route, err := client.Route(ctx, *from, *to, mode)
+		if err != nil {
+			return "", err
+		}
+
+		prefs := PrefsFromContext(ctx)
+		result := fmt.Sprintf("From %s to %s (%s): about %s, %s.", from.Name, to.Name, mode, formatTravelDuration(route.Duration), prefs.Distance(route.Distance))
+		if arriveBy.IsZero() {
+			return result, nil
+		}
+
+		buffer := time.Duration(max(params.BufferMinutes, 0)) * time.Minute
+		leaveBy := arriveBy.Add(-route.Duration - buffer).Truncate(time.Minute)
+		result += fmt.Sprintf("\nTo arrive by %s, leave by %s", prefs.Time(arriveBy), prefs.DateTime(leaveBy.In(tz)))
+		if buffer > 0 {
+			result += fmt.Sprintf(" (including %d min buffer)", params.BufferMinutes)
+		}
+		result += "."
+
+		if params.Remind {
+			result += scheduleOneTimeReminder(ctx, cronStore, "leave for "+params.To, leaveBy)
+		}
+		return result, nil
+	})
+
+	// places don't move; cache lookups so repeated questions skip the rate-limited geocoder
+	registry.Cacheable("geocode", 24*time.Hour)
+	registry.Cacheable("reverse_geocode", 24*time.Hour)
+}
+
+func formatTravelDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes < 1 {
+		return "1 min"
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d h", minutes/60)
+	}
+	return fmt.Sprintf("%d h %d min", minutes/60, minutes%60)
+}
@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/storage"
+	"github.com/bowerhall/sheldonmem"
+)
+
+type FindMediaArgs struct {
+	Query  string `json:"query"`
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Send   bool   `json:"send,omitempty"`
+}
+
+// RegisterMediaLibraryTools registers set_media_archive, which opts a chat in
+// to archiving the photos and files it sends, and find_media, which searches
+// the archive by caption, tags and date
+func RegisterMediaLibraryTools(registry *Registry, memory *sheldonmem.Store, rc *config.RuntimeConfig, client *storage.Client, sender MediaSender) {
+	archiveTool := llm.Tool{
+		Name:        "set_media_archive",
+		Description: "Turn the media library on or off for this chat. When on, every photo and file the user sends is stored in their storage under media/, captioned and tagged so find_media can find it later. Use when the user asks you to keep or stop keeping what they send, or asks whether you do.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"enabled": map[string]any{
+					"type":        "boolean",
+					"description": "Whether to archive uploads, or omit to report the current setting",
+				},
+			},
+		},
+	}
+
+	registry.Register(archiveTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		sessionID := SessionIDFromContext(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("no session in context")
+		}
+
+		if params.Enabled == nil {
+			if rc.SessionMediaArchive(sessionID) {
+				return "media library is on: photos and files sent here are archived and searchable with find_media", nil
+			}
+			return "media library is off: photos and files sent here aren't kept", nil
+		}
+		if err := rc.SetSessionMediaArchive(sessionID, *params.Enabled); err != nil {
+			return "", err
+		}
+		if *params.Enabled {
+			return "media library on: photos and files sent from now on are archived under media/ and tagged for find_media", nil
+		}
+		return "media library off: new uploads won't be archived. Files already archived stay in storage.", nil
+	})
+
+	findTool := llm.Tool{
+		Name:        "find_media",
+		Description: "Search the user's media library for photos and files they sent with archiving on, by what they show and when they were sent, e.g. \"boarding pass\" after 2026-03-01 before 2026-04-01. Returns storage paths and captions; set send to deliver the best match to the chat.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "What the photo or file shows, e.g. 'boarding pass' or 'receipt for the couch'. Leave empty to list everything in the date range.",
+				},
+				"after": map[string]any{
+					"type":        "string",
+					"description": "Only files sent on or after this date (YYYY-MM-DD)",
+				},
+				"before": map[string]any{
+					"type":        "string",
+					"description": "Only files sent before this date (YYYY-MM-DD)",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Maximum results (default: 5, max: 20)",
+				},
+				"send": map[string]any{
+					"type":        "boolean",
+					"description": "Send the best match to the user",
+				},
+			},
+		},
+	}
+
+	registry.Register(findTool, func(ctx context.Context, args string) (string, error) {
+		var params FindMediaArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		q := sheldonmem.MediaQuery{Text: params.Query, Limit: min(max(params.Limit, 1), 20)}
+		if params.Limit == 0 {
+			q.Limit = 5
+		}
+		var err error
+		if q.After, err = parseMediaDate(params.After); err != nil {
+			return "", err
+		}
+		if q.Before, err = parseMediaDate(params.Before); err != nil {
+			return "", err
+		}
+
+		ownerID, err := documentOwner(ctx, memory)
+		if err != nil {
+			return "", err
+		}
+		items, err := memory.SearchMedia(ctx, ownerID, q)
+		if err != nil {
+			return "", fmt.Errorf("search media: %w", err)
+		}
+		if len(items) == 0 {
+			if !rc.SessionMediaArchive(SessionIDFromContext(ctx)) {
+				return "No archived media found. The media library is off for this chat; turn it on with set_media_archive to keep future uploads.", nil
+			}
+			return "No archived media matches that.", nil
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Found %d:\n", len(items))
+		for _, item := range items {
+			fmt.Fprintf(&sb, "- user/%s (%s)", item.Path, item.CreatedAt.Format("2006-01-02"))
+			if item.Caption != "" {
+				fmt.Fprintf(&sb, ": %s", item.Caption)
+			}
+			if len(item.Tags) > 0 {
+				fmt.Fprintf(&sb, " [%s]", strings.Join(item.Tags, ", "))
+			}
+			sb.WriteString("\n")
+		}
+
+		if params.Send {
+			if err := sendMedia(ctx, client, sender, items[0]); err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&sb, "Sent %s to the user.", path.Base(items[0].Path))
+		}
+		return sb.String(), nil
+	})
+}
+
+func parseMediaDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// sendMedia delivers an archived file to the current chat the way it was sent
+func sendMedia(ctx context.Context, client *storage.Client, sender MediaSender, item *sheldonmem.MediaItem) error {
+	chatID := ChatIDFromContext(ctx)
+	if chatID == 0 {
+		return fmt.Errorf("no chat ID in context")
+	}
+
+	data, err := client.Download(ctx, client.UserBucket(), item.Path)
+	if err != nil {
+		return fmt.Errorf("download media: %w", err)
+	}
+
+	switch {
+	case strings.HasPrefix(item.MimeType, "image/"):
+		err = sender.SendPhoto(chatID, data, item.Caption)
+	case strings.HasPrefix(item.MimeType, "video/"):
+		err = sender.SendVideo(chatID, data, item.Caption)
+	default:
+		name := item.Filename
+		if name == "" {
+			name = path.Base(item.Path)
+		}
+		err = sender.SendDocument(chatID, data, name, item.Caption)
+	}
+	if err != nil {
+		return fmt.Errorf("send media: %w", err)
+	}
+	return nil
+}
@@ -247,6 +247,14 @@ The user must signal intent to save with words like "remember", "save", "don't f
 			ExcludeSensitive: SafeModeFromContext(ctx),
 		}
 
+		// only recall facts about this user, shared entities, and people they know.
+		// Without the user's entity there's no telling whose facts are whose.
+		user, err := memory.FindEntityByName(UserEntityName(ctx))
+		if err != nil {
+			return "No relevant memories found.", nil
+		}
+		opts.OwnerID = user.ID
+
 		// Apply time filters
 		if params.TimeRange != "" {
 			since, until := parseTimeRange(params.TimeRange)
@@ -387,6 +395,9 @@ The user must signal intent to save with words like "remember", "save", "don't f
 
 		return sb.String(), nil
 	})
+	registry.Cacheable("recall_memory", 2*time.Minute)
+	registry.Invalidates("save_memory", "recall_memory")
+	registry.Invalidates("mark_sensitive", "recall_memory")
 
 	markSensitiveTool := llm.Tool{
 		Name:        "mark_sensitive",
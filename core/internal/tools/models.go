@@ -6,17 +6,28 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/config"
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
 )
 
-func RegisterModelTools(registry *Registry, rc *config.RuntimeConfig, mr *config.ModelRegistry) {
+// probeTimeout allows for a local model loading into memory on first use
+const probeTimeout = 3 * time.Minute
+
+func RegisterModelTools(registry *Registry, rc *config.RuntimeConfig, mr *config.ModelRegistry, factory ModelFactory) {
+	// probes from earlier runs apply to clients created from now on
+	for _, p := range rc.ModelProbes() {
+		llm.RecordProbe(p.Provider, p.Model, llm.ProbeResult{Chat: p.Chat, ToolUse: p.Tools, Vision: p.Vision})
+	}
+
 	registerCurrentModel(registry, rc)
 	registerListProviders(registry, mr)
 	registerListModels(registry, mr)
-	registerSwitchModel(registry, rc, mr)
-	registerPullModel(registry, mr)
+	registerSwitchModel(registry, rc, mr, factory)
+	registerProbeModel(registry, rc, mr, factory)
+	registerPullModel(registry, rc, mr, factory)
 	registerRemoveModel(registry, mr)
 }
 
@@ -38,7 +49,11 @@ func registerCurrentModel(registry *Registry, rc *config.RuntimeConfig) {
 
 		var sb strings.Builder
 		sb.WriteString("current models:\n\n")
-		sb.WriteString(fmt.Sprintf("  chat (llm): %s/%s\n", llmProvider, llmModel))
+		if override, ok := rc.SessionModel(SessionIDFromContext(ctx)); ok {
+			sb.WriteString(fmt.Sprintf("  chat (llm): %s/%s (this chat only, everyone else: %s/%s)\n", override.Provider, override.Model, llmProvider, llmModel))
+		} else {
+			sb.WriteString(fmt.Sprintf("  chat (llm): %s/%s\n", llmProvider, llmModel))
+		}
 		sb.WriteString(fmt.Sprintf("  coder: %s/%s\n", coderProvider, coderModel))
 
 		return sb.String(), nil
@@ -90,8 +105,8 @@ func registerListModels(registry *Registry, mr *config.ModelRegistry) {
 			"properties": map[string]any{
 				"provider": map[string]any{
 					"type":        "string",
-					"description": "Filter by provider (kimi, claude, openai, ollama). Leave empty for all models.",
-					"enum":        []string{"kimi", "claude", "openai", "ollama"},
+					"description": "Filter by provider (kimi, claude, openai, openrouter, ollama). Leave empty for all models.",
+					"enum":        []string{"kimi", "claude", "openai", "openrouter", "ollama"},
 				},
 			},
 		},
@@ -158,9 +173,12 @@ func registerListModels(registry *Registry, mr *config.ModelRegistry) {
 
 		return sb.String(), nil
 	})
+	registry.Cacheable("list_models", 10*time.Minute)
+	registry.Invalidates("pull_model", "list_models")
+	registry.Invalidates("remove_model", "list_models")
 }
 
-func registerSwitchModel(registry *Registry, rc *config.RuntimeConfig, mr *config.ModelRegistry) {
+func registerSwitchModel(registry *Registry, rc *config.RuntimeConfig, mr *config.ModelRegistry, factory ModelFactory) {
 	tool := llm.Tool{
 		Name: "switch_model",
 		Description: `Switch the model used for a specific purpose. IMPORTANT: Before switching, you MUST:
@@ -171,9 +189,14 @@ func registerSwitchModel(registry *Registry, rc *config.RuntimeConfig, mr *confi
 
 Never assume which model the user wants. Always show options and get explicit confirmation first.
 
+Use scope 'session' to switch the chat model for this conversation only, e.g. to try a local
+model without affecting other chats. Call with scope 'session' and reset true to go back to the
+global model.
+
 NOTE: Only 'llm' and 'coder' can be switched. Embedder is core infrastructure -
 changing it would break vector compatibility with existing memories. If user asks to change
-embedder, explain why it's not supported and suggest they modify the server config instead.`,
+embedder, explain that memory must be re-embedded first with the admin API's POST /memory/reindex,
+then the server config updated.`,
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -184,14 +207,23 @@ embedder, explain why it's not supported and suggest they modify the server conf
 				},
 				"provider": map[string]any{
 					"type":        "string",
-					"description": "Provider to use (kimi, claude, openai, ollama). If omitted, will be inferred from model name.",
+					"description": "Provider to use (kimi, claude, openai, openrouter, ollama). If omitted, will be inferred from model name.",
 				},
 				"model": map[string]any{
 					"type":        "string",
 					"description": "Model ID to use (e.g., kimi-k2-0711-preview, claude-sonnet-4-20250514, gpt-4o)",
 				},
+				"scope": map[string]any{
+					"type":        "string",
+					"description": "global (default) changes the model for every chat, session only for this one. Only llm can be switched per session.",
+					"enum":        []string{"global", "session"},
+				},
+				"reset": map[string]any{
+					"type":        "boolean",
+					"description": "With scope session: drop this chat's override and use the global model again",
+				},
 			},
-			"required": []string{"purpose", "model"},
+			"required": []string{"purpose"},
 		},
 	}
 
@@ -200,11 +232,39 @@ embedder, explain why it's not supported and suggest they modify the server conf
 			Purpose  string `json:"purpose"`
 			Provider string `json:"provider"`
 			Model    string `json:"model"`
+			Scope    string `json:"scope"`
+			Reset    bool   `json:"reset"`
 		}
 		if err := json.Unmarshal([]byte(args), &params); err != nil {
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
+		sessionScope := params.Scope == "session"
+		if sessionScope && params.Purpose != "llm" {
+			return "", fmt.Errorf("only the llm can be switched per session, %s is shared", params.Purpose)
+		}
+		sessionID := SessionIDFromContext(ctx)
+		if sessionScope && sessionID == "" {
+			return "", fmt.Errorf("no session in context")
+		}
+
+		if params.Reset {
+			if !sessionScope {
+				return "", fmt.Errorf("reset only applies to scope session, switch to a model to change the global one")
+			}
+			cleared, err := rc.ClearSessionModel(sessionID)
+			if err != nil {
+				return "", fmt.Errorf("failed to clear session model: %w", err)
+			}
+			if !cleared {
+				return fmt.Sprintf("This chat already uses the global model (%s/%s)", rc.Get("llm_provider"), rc.Get("llm_model")), nil
+			}
+			return fmt.Sprintf("This chat is back on the global model (%s/%s)", rc.Get("llm_provider"), rc.Get("llm_model")), nil
+		}
+		if params.Model == "" {
+			return "", fmt.Errorf("model is required")
+		}
+
 		// infer provider from model if not specified
 		provider := params.Provider
 		if provider == "" {
@@ -227,6 +287,9 @@ embedder, explain why it's not supported and suggest they modify the server conf
 			if requiredCap != "" && !modelHasCapability(params.Model, requiredCap, mr) {
 				return "", fmt.Errorf("model %q does not support %s (required for %s purpose)", params.Model, requiredCap, params.Purpose)
 			}
+			if err := checkChatModel(ctx, registry, rc, mr, factory, provider, params.Model); err != nil {
+				return "", err
+			}
 		}
 
 		// validate purpose
@@ -244,6 +307,13 @@ embedder, explain why it's not supported and suggest they modify the server conf
 			return "", fmt.Errorf("invalid purpose %q, must be one of: llm, coder", params.Purpose)
 		}
 
+		if sessionScope {
+			if err := rc.SetSessionModel(sessionID, config.ModelOverride{Provider: provider, Model: params.Model}); err != nil {
+				return "", fmt.Errorf("failed to set session model: %w", err)
+			}
+			return fmt.Sprintf("Switched this chat to %s/%s, other chats keep %s/%s", provider, params.Model, rc.Get("llm_provider"), rc.Get("llm_model")), nil
+		}
+
 		// set both provider and model
 		if err := rc.Set(providerKey, provider); err != nil {
 			return "", fmt.Errorf("failed to set provider: %w", err)
@@ -272,6 +342,9 @@ func inferProvider(model string, mr *config.ModelRegistry) string {
 		return "claude"
 	case strings.HasPrefix(model, "gpt-"):
 		return "openai"
+	case strings.Contains(model, "/") && providerConfigured("openrouter"):
+		// vendor/model IDs (anthropic/claude-sonnet-4) are OpenRouter routes
+		return "openrouter"
 	case strings.Contains(model, ":"):
 		return "ollama"
 	}
@@ -306,6 +379,127 @@ func modelHasCapability(modelID, capability string, mr *config.ModelRegistry) bo
 	return true
 }
 
+// checkChatModel probes models without a known capability list (local and
+// routed models) before they become the main chat model, which needs tools
+func checkChatModel(ctx context.Context, registry *Registry, rc *config.RuntimeConfig, mr *config.ModelRegistry, factory ModelFactory, provider, model string) error {
+	for _, m := range mr.CloudModels() {
+		if m.ID == model {
+			return nil
+		}
+	}
+	if factory == nil {
+		return nil
+	}
+
+	probe, ok := rc.ModelProbe(provider, model)
+	if !ok {
+		registry.Notify(ctx, fmt.Sprintf("testing what %s can do...", model))
+		var err error
+		if probe, err = probeModel(ctx, rc, factory, provider, model); err != nil {
+			return fmt.Errorf("could not test %s/%s: %w", provider, model, err)
+		}
+	}
+
+	switch {
+	case !probe.Chat:
+		return fmt.Errorf("model %q did not answer a chat message in testing, it can't be the main model", model)
+	case !probe.Tools:
+		return fmt.Errorf("model %q did not call a tool when asked in testing. The main model needs tools for memory, reminders and everything else, pick another or use it as coder", model)
+	}
+	return nil
+}
+
+// probeModel runs a live capability test and caches the result
+func probeModel(ctx context.Context, rc *config.RuntimeConfig, factory ModelFactory, provider, model string) (config.ModelProbe, error) {
+	client, err := factory(provider, model)
+	if err != nil {
+		return config.ModelProbe{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	result, err := llm.Probe(ctx, client)
+	if err != nil {
+		return config.ModelProbe{}, err
+	}
+	llm.RecordProbe(provider, model, result)
+
+	probe := config.ModelProbe{
+		Provider: provider,
+		Model:    model,
+		Chat:     result.Chat,
+		Tools:    result.ToolUse,
+		Vision:   result.Vision,
+		ProbedAt: time.Now().UTC(),
+	}
+	if err := rc.SetModelProbe(probe); err != nil {
+		logger.Warn("failed to cache model probe", "model", model, "error", err)
+	}
+	return probe, nil
+}
+
+func formatProbe(p config.ModelProbe) string {
+	mark := func(ok bool) string {
+		if ok {
+			return "✓"
+		}
+		return "✗"
+	}
+	return fmt.Sprintf("chat %s, tools %s, vision %s", mark(p.Chat), mark(p.Tools), mark(p.Vision))
+}
+
+func registerProbeModel(registry *Registry, rc *config.RuntimeConfig, mr *config.ModelRegistry, factory ModelFactory) {
+	tool := llm.Tool{
+		Name:        "probe_model",
+		Description: "Test what a model can actually do (chat, tool calls, images) with a few short live requests, and remember the result. Use for local or new models whose capabilities are unknown, or when a model behaves differently than expected.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"provider": map[string]any{
+					"type":        "string",
+					"description": "Provider (ollama, openrouter, ...). Inferred from the model if omitted.",
+				},
+				"model": map[string]any{
+					"type":        "string",
+					"description": "Model ID to test, e.g. qwen3:8b or llava:7b",
+				},
+			},
+			"required": []string{"model"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Provider string `json:"provider"`
+			Model    string `json:"model"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		provider := params.Provider
+		if provider == "" {
+			provider = inferProvider(params.Model, mr)
+			if provider == "" {
+				return "", fmt.Errorf("could not infer provider for model %q, please specify provider explicitly", params.Model)
+			}
+		}
+		if !providerConfigured(provider) {
+			return "", fmt.Errorf("cannot test %s: %s not configured", provider, config.EnvKeyForProvider(provider))
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("testing %s...", params.Model))
+		probe, err := probeModel(ctx, rc, factory, provider, params.Model)
+		if err != nil {
+			return "", fmt.Errorf("probe failed: %w", err)
+		}
+
+		return fmt.Sprintf("🔬 %s/%s: %s", provider, params.Model, formatProbe(probe)), nil
+	})
+	registry.Invalidates("probe_model", "list_models")
+}
+
 func providerConfigured(provider string) bool {
 	if provider == "ollama" {
 		return true
@@ -317,7 +511,7 @@ func providerConfigured(provider string) bool {
 	return os.Getenv(envKey) != ""
 }
 
-func registerPullModel(registry *Registry, mr *config.ModelRegistry) {
+func registerPullModel(registry *Registry, rc *config.RuntimeConfig, mr *config.ModelRegistry, factory ModelFactory) {
 	tool := llm.Tool{
 		Name: "pull_model",
 		Description: `Download a model from ollama. IMPORTANT: Before pulling, you MUST:
@@ -355,9 +549,19 @@ If disk space is critically low (<2GB available), refuse to pull and suggest rem
 			return "", fmt.Errorf("failed to pull model: %w", err)
 		}
 
-		registry.Notify(ctx, fmt.Sprintf("model %s pulled successfully", params.Model))
+		registry.Notify(ctx, fmt.Sprintf("model %s pulled successfully, testing what it can do...", params.Model))
+
+		// a fresh pull is the moment to learn its capabilities, ollama doesn't list them
+		capabilities := "capabilities unknown, use probe_model to test them"
+		if factory != nil {
+			if probe, err := probeModel(ctx, rc, factory, "ollama", params.Model); err != nil {
+				logger.Warn("failed to probe pulled model", "model", params.Model, "error", err)
+			} else {
+				capabilities = formatProbe(probe)
+			}
+		}
 
-		return fmt.Sprintf("successfully pulled %s\n\nuse switch_model to activate it", params.Model), nil
+		return fmt.Sprintf("successfully pulled %s (%s)\n\nuse switch_model to activate it", params.Model, capabilities), nil
 	})
 }
 
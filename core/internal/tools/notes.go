@@ -52,7 +52,9 @@ Use notes for:
 - Project status (current state of something)
 - Any "current state" that doesn't fit long-term memory
 
-Notes are key-value: the key identifies the note, content can be text, markdown, or JSON.`,
+Notes are key-value: the key identifies the note, content can be text, markdown, or JSON.
+
+Notes are shared with everyone who talks to you. Keep anything personal or sensitive in memory (save_memory) instead.`,
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/persona"
+)
+
+// RegisterPersonaTools registers switch_persona. Personas are read from the
+// essence directory on every call so new files show up without a reload.
+func RegisterPersonaTools(registry *Registry, rc *config.RuntimeConfig, essencePath string) {
+	tool := llm.Tool{
+		Name:        "switch_persona",
+		Description: "Switch this chat to a named persona (its own personality prompt, tone and default model), e.g. a focused work assistant in one chat and a casual one in another. Other chats are not affected. Call without persona to list the available ones; use 'default' to go back to the standard personality.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"persona": map[string]any{
+					"type":        "string",
+					"description": "Persona name, or 'default'. Omit to list personas.",
+				},
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Persona string `json:"persona"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		personas, err := persona.Load(essencePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to load personas: %w", err)
+		}
+
+		sessionID := SessionIDFromContext(ctx)
+		current, _ := rc.SessionPersona(sessionID)
+
+		name := strings.ToLower(strings.TrimSpace(params.Persona))
+		if name == "" {
+			return formatPersonas(personas, current), nil
+		}
+		if sessionID == "" {
+			return "", fmt.Errorf("no session in context")
+		}
+
+		// a model the previous persona picked goes with it
+		if prev, ok := personas[current]; ok && prev.Model != "" {
+			if override, ok := rc.SessionModel(sessionID); ok && override.Provider == prev.Provider && override.Model == prev.Model {
+				if _, err := rc.ClearSessionModel(sessionID); err != nil {
+					return "", fmt.Errorf("failed to clear persona model: %w", err)
+				}
+			}
+		}
+
+		if name == persona.Default {
+			if _, err := rc.ClearSessionPersona(sessionID); err != nil {
+				return "", fmt.Errorf("failed to save: %w", err)
+			}
+			return "🎭 this chat is back to the default persona", nil
+		}
+
+		p, ok := personas[name]
+		if !ok {
+			return "", fmt.Errorf("unknown persona %q, available: %s", name, strings.Join(append([]string{persona.Default}, persona.Names(personas)...), ", "))
+		}
+		if err := rc.SetSessionPersona(sessionID, name); err != nil {
+			return "", fmt.Errorf("failed to save: %w", err)
+		}
+
+		result := fmt.Sprintf("🎭 this chat now uses the %s persona", name)
+		if p.Model != "" {
+			if !providerConfigured(p.Provider) {
+				return result + fmt.Sprintf("\n\n⚠️ its default model %s/%s was not applied: %s not configured", p.Provider, p.Model, config.EnvKeyForProvider(p.Provider)), nil
+			}
+			if err := rc.SetSessionModel(sessionID, config.ModelOverride{Provider: p.Provider, Model: p.Model}); err != nil {
+				return "", fmt.Errorf("failed to set persona model: %w", err)
+			}
+			result += fmt.Sprintf(" with %s/%s", p.Provider, p.Model)
+		}
+		return result + ", starting with the next message", nil
+	})
+}
+
+func formatPersonas(personas map[string]*persona.Persona, current string) string {
+	if current == "" {
+		current = persona.Default
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🎭 Personas (this chat: %s)\n\n", current)
+	fmt.Fprintf(&sb, "- %s: standard personality from SOUL.md\n", persona.Default)
+	for _, name := range persona.Names(personas) {
+		p := personas[name]
+		desc := p.Description
+		if desc == "" {
+			desc = "no description"
+		}
+		fmt.Fprintf(&sb, "- %s: %s", name, desc)
+		if p.Model != "" {
+			fmt.Fprintf(&sb, " (%s/%s)", p.Provider, p.Model)
+		}
+		sb.WriteString("\n")
+	}
+	if len(personas) == 0 {
+		fmt.Fprintf(&sb, "\nAdd personas as %s/<name>.md in the essence directory.", persona.Dir)
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+// RegisterProactiveTools registers set_proactivity, which controls how eagerly
+// Sheldon schedules its own check-ins around events and goals it knows about
+func RegisterProactiveTools(registry *Registry, rc *config.RuntimeConfig) {
+	tool := llm.Tool{
+		Name:        "set_proactivity",
+		Description: "Set how often you check in on your own about upcoming deadlines, events, birthdays and goals from memory. off: never. low: only dated things in the next 3 days, one at a time. normal: the next week, including goal progress. high: the next two weeks, more often. Use when the user says you're too pushy, too quiet, or asks what the setting is.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"level": map[string]any{
+					"type":        "string",
+					"enum":        config.ProactivityLevels,
+					"description": "New level, or omit to report the current one",
+				},
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Level string `json:"level"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		sessionID := SessionIDFromContext(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("no session in context")
+		}
+
+		if params.Level == "" {
+			return fmt.Sprintf("🔔 proactive check-ins are set to %s (levels: %s)", rc.SessionProactivity(sessionID), strings.Join(config.ProactivityLevels, ", ")), nil
+		}
+		if err := rc.SetSessionProactivity(sessionID, params.Level); err != nil {
+			return "", err
+		}
+		if params.Level == "off" {
+			return "🔕 proactive check-ins are off. Ones already scheduled stay until they fire; delete them with delete_cron.", nil
+		}
+		return fmt.Sprintf("🔔 proactive check-ins set to %s", params.Level), nil
+	})
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// PromptAddendumField is the user fact whose value is appended to the system prompt
+const PromptAddendumField = "prompt_addendum"
+
+const maxAddendumChars = 2000
+
+// RegisterPromptTools registers reload_prompt, which re-reads the essence
+// directory, and set_prompt_addendum for per-user standing instructions
+func RegisterPromptTools(registry *Registry, memory *sheldonmem.Store, reload func() []string) {
+	reloadTool := llm.Tool{
+		Name:        "reload_prompt",
+		Description: "Reload the system prompt from the essence directory (SOUL.md, TOOLS.md, prompt.d/ fragments, skills/ overrides) after the files were edited, and list the layers in use.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(reloadTool, func(ctx context.Context, args string) (string, error) {
+		layers := reload()
+		if len(layers) == 0 {
+			return "⚠️ reloaded, but no prompt layers were found. Check that SOUL.md exists in the essence directory", nil
+		}
+		return fmt.Sprintf("🔄 system prompt reloaded, applies from the next message\n\nlayers: %s", strings.Join(layers, ", ")), nil
+	})
+
+	addendumTool := llm.Tool{
+		Name:        "set_prompt_addendum",
+		Description: "Save standing instructions from the current user that should shape every reply to them, e.g. 'always answer in Spanish' or 'keep replies under 3 sentences'. Replaces any previous instructions; include everything that should stay. Only use when the user explicitly asks for a lasting change in how you behave.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"instructions": map[string]any{
+					"type":        "string",
+					"description": "The full set of standing instructions, written as instructions to yourself",
+				},
+				"clear": map[string]any{
+					"type":        "boolean",
+					"description": "Remove the user's standing instructions instead",
+				},
+			},
+		},
+	}
+
+	registry.Register(addendumTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Instructions string `json:"instructions"`
+			Clear        bool   `json:"clear"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		entity, err := memory.FindEntityByName(UserEntityName(ctx))
+		if err != nil {
+			return "", fmt.Errorf("could not find user entity: %w", err)
+		}
+
+		if params.Clear {
+			facts, err := memory.GetFactsByEntity(entity.ID)
+			if err != nil {
+				return "", fmt.Errorf("could not get facts: %w", err)
+			}
+			for _, f := range facts {
+				if f.Field == PromptAddendumField {
+					if err := memory.ForgetFact(f.ID); err != nil {
+						return "", fmt.Errorf("failed to clear: %w", err)
+					}
+					return "🧹 standing instructions cleared", nil
+				}
+			}
+			return "No standing instructions were set", nil
+		}
+
+		instructions := strings.TrimSpace(params.Instructions)
+		if instructions == "" {
+			return "", fmt.Errorf("instructions are required (or set clear)")
+		}
+		if len(instructions) > maxAddendumChars {
+			return "", fmt.Errorf("instructions are too long (%d chars, max %d), keep them to the essentials", len(instructions), maxAddendumChars)
+		}
+
+		domainID := sheldonmem.DomainSlugToID["preferences"]
+		if _, err := memory.AddFactWithContext(ctx, &entity.ID, domainID, PromptAddendumField, instructions, 1.0, false); err != nil {
+			return "", fmt.Errorf("failed to save: %w", err)
+		}
+
+		return fmt.Sprintf("📌 standing instructions saved, they apply from the next message:\n%s", instructions), nil
+	})
+	registry.Invalidates("set_prompt_addendum", "recall_memory")
+}
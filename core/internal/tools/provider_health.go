@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+// ActiveModelFunc returns the provider and model currently answering messages
+type ActiveModelFunc func() (provider, model string)
+
+// RegisterProviderHealthTool registers provider_health, which shows failover
+// state and lets the user clear a provider's cooldown
+func RegisterProviderHealthTool(registry *Registry, tracker *llm.HealthTracker, active ActiveModelFunc) {
+	tool := llm.Tool{
+		Name:        "provider_health",
+		Description: "Show LLM provider health: which providers failed recently (quota, rate limits, overload), how long they cool down before being retried, and which model is answering right now. Use when the user asks why a different model replied or whether a provider is down. Pass reset to retry a provider on the next message.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"reset": map[string]any{
+					"type":        "string",
+					"description": "Provider whose cooldown to clear, e.g. after topping up credits",
+				},
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Reset string `json:"reset"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		var sb strings.Builder
+		if params.Reset != "" {
+			if !tracker.Reset(params.Reset) {
+				return "", fmt.Errorf("no failures recorded for %s", params.Reset)
+			}
+			fmt.Fprintf(&sb, "🔄 cleared %s cooldown, it will be tried on the next message\n\n", params.Reset)
+		}
+
+		provider, model := active()
+		fmt.Fprintf(&sb, "🩺 Active: %s/%s\n", provider, model)
+
+		providers := tracker.Snapshot()
+		if len(providers) == 0 {
+			sb.WriteString("\nNo provider failures recorded since startup.")
+			return sb.String(), nil
+		}
+
+		now := time.Now()
+		for _, h := range providers {
+			sb.WriteString("\n")
+			switch {
+			case h.CoolingDown(now):
+				fmt.Fprintf(&sb, "⏳ %s: cooling down, retry in %s (%d failures in a row)", h.Provider, h.CooldownUntil.Sub(now).Round(time.Second), h.Failures)
+			case h.Failures > 0:
+				fmt.Fprintf(&sb, "⚠️ %s: cooldown over, will be retried (%d failures in a row)", h.Provider, h.Failures)
+			default:
+				fmt.Fprintf(&sb, "✅ %s: healthy", h.Provider)
+			}
+			if !h.LastSuccess.IsZero() {
+				fmt.Fprintf(&sb, "\n   last success: %s ago", now.Sub(h.LastSuccess).Round(time.Second))
+			}
+			if h.TotalFailures > 0 {
+				fmt.Fprintf(&sb, "\n   failures since startup: %d, last %s ago: %s", h.TotalFailures, now.Sub(h.LastFailure).Round(time.Second), truncateError(h.LastError))
+			}
+		}
+
+		return sb.String(), nil
+	})
+}
+
+func truncateError(msg string) string {
+	if len(msg) > 200 {
+		return msg[:200] + "..."
+	}
+	return msg
+}
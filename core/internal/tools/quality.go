@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/quality"
+)
+
+// RegisterQualityTools registers response_quality, which reports how the
+// evaluator scored replies, by model or by day, with the worst turns
+func RegisterQualityTools(registry *Registry, store *quality.Store, timezone *time.Location) {
+	if store == nil {
+		return
+	}
+
+	qualityTool := llm.Tool{
+		Name:        "response_quality",
+		Description: "Report how replies were scored by the quality evaluator: instruction-following (1-5, higher is better) and hallucination risk (1-5, higher is worse), averaged per model or per day and model, plus the worst-scored turns. Use when the user asks how well a model has been answering or wants to audit answer quality.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"by": map[string]any{
+					"type":        "string",
+					"enum":        []string{"model", "day"},
+					"description": "Average per model (default) or per day and model to see trends",
+				},
+				"period": map[string]any{
+					"type":        "string",
+					"enum":        []string{"today", "week", "month", "custom"},
+					"description": "Time period (default: week)",
+				},
+				"from": map[string]any{
+					"type":        "string",
+					"description": "Start date for custom period (YYYY-MM-DD)",
+				},
+				"to": map[string]any{
+					"type":        "string",
+					"description": "End date for custom period (YYYY-MM-DD)",
+				},
+			},
+		},
+	}
+
+	registry.Register(qualityTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			By     string `json:"by"`
+			Period string `json:"period"`
+			From   string `json:"from"`
+			To     string `json:"to"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if params.Period == "" {
+			params.Period = "week"
+		}
+
+		from, to, periodLabel, err := usagePeriod(params.Period, params.From, params.To, timezone)
+		if err != nil {
+			return "", err
+		}
+
+		var result strings.Builder
+		switch params.By {
+		case "day":
+			days, err := store.ByDay(from, to)
+			if err != nil {
+				return "", err
+			}
+			if len(days) == 0 {
+				return fmt.Sprintf("No scored replies for %s.", periodLabel), nil
+			}
+			fmt.Fprintf(&result, "Reply quality by day for %s:\n", periodLabel)
+			for _, d := range days {
+				fmt.Fprintf(&result, "- %s %s/%s: instructions %.1f, hallucination risk %.1f (%d replies)\n",
+					d.Date, d.Provider, d.Model, d.InstructionFollowing, d.HallucinationRisk, d.Turns)
+			}
+		case "", "model":
+			models, err := store.ByModel(from, to)
+			if err != nil {
+				return "", err
+			}
+			if len(models) == 0 {
+				return fmt.Sprintf("No scored replies for %s.", periodLabel), nil
+			}
+			fmt.Fprintf(&result, "Reply quality by model for %s:\n", periodLabel)
+			for _, m := range models {
+				fmt.Fprintf(&result, "- %s/%s: instructions %.1f, hallucination risk %.1f, %d of %d flagged\n",
+					m.Provider, m.Model, m.InstructionFollowing, m.HallucinationRisk, m.Flagged, m.Turns)
+			}
+		default:
+			return "", fmt.Errorf("invalid by: %s", params.By)
+		}
+
+		flagged, err := store.Flagged(from, to, 5)
+		if err != nil {
+			return "", err
+		}
+		if len(flagged) > 0 {
+			result.WriteString("\nWorst recent replies:\n")
+			for _, s := range flagged {
+				fmt.Fprintf(&result, "- %s %s/%s in %s: instructions %d, hallucination risk %d",
+					s.Time.In(timezone).Format("2006-01-02 15:04"), s.Provider, s.Model, s.SessionID, s.InstructionFollowing, s.HallucinationRisk)
+				if s.Notes != "" {
+					fmt.Fprintf(&result, ": %s", s.Notes)
+				}
+				result.WriteString("\n")
+			}
+		}
+
+		return result.String(), nil
+	})
+}
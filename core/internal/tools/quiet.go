@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+// RegisterQuietTools registers set_quiet_hours and vacation_until, which hold
+// back every scheduled trigger and proactive message at once instead of
+// pausing crons one by one
+func RegisterQuietTools(registry *Registry, rc *config.RuntimeConfig, timezone *time.Location) {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+
+	quietTool := llm.Tool{
+		Name:        "set_quiet_hours",
+		Description: "Set a daily window when scheduled triggers, reminders and check-ins don't fire. Anything due in the window is sent when it ends. Use when the user asks not to be messaged at night or during certain hours. Pass 'off' as start to remove the window.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"start": map[string]any{
+					"type":        "string",
+					"description": "Start of the window as HH:MM in 24h time, e.g. '22:00', or 'off'",
+				},
+				"end": map[string]any{
+					"type":        "string",
+					"description": "End of the window as HH:MM, e.g. '07:30'. May be earlier than start to wrap past midnight.",
+				},
+			},
+			"required": []string{"start"},
+		},
+	}
+
+	registry.Register(quietTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if strings.EqualFold(params.Start, "off") {
+			if err := rc.ClearQuietHours(); err != nil {
+				return "", fmt.Errorf("failed to save: %w", err)
+			}
+			return "🔔 quiet hours removed", nil
+		}
+
+		if err := rc.SetQuietHours(params.Start, params.End); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("🌙 quiet hours set: %s to %s every day. Triggers due in between are sent at %s.", params.Start, params.End, params.End), nil
+	})
+
+	vacationTool := llm.Tool{
+		Name:        "vacation_until",
+		Description: "Turn on vacation mode: no scheduled triggers, reminders or check-ins until the given date. One-time reminders are delivered on return; recurring ones skip the days away. If the user mentions a trip or holiday, check their calendar for the dates and offer this. Pass 'off' to end it early.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"until": map[string]any{
+					"type":        "string",
+					"description": "When the user is back: '2026-08-24', '2026-08-24 09:00', 'monday', '2 weeks', or 'off'",
+				},
+			},
+			"required": []string{"until"},
+		},
+	}
+
+	registry.Register(vacationTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Until string `json:"until"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if strings.EqualFold(strings.TrimSpace(params.Until), "off") {
+			if err := rc.SetVacationUntil(time.Time{}); err != nil {
+				return "", fmt.Errorf("failed to save: %w", err)
+			}
+			return "🏠 vacation mode off, scheduled triggers are running again", nil
+		}
+
+		tz := TimezoneFromContext(ctx, timezone)
+		until, err := parseReturnDate(params.Until, tz)
+		if err != nil {
+			return "", err
+		}
+		if err := rc.SetVacationUntil(until); err != nil {
+			return "", fmt.Errorf("failed to save: %w", err)
+		}
+		return fmt.Sprintf("🏖️ vacation mode on until %s. Nothing scheduled will fire before then.", PrefsFromContext(ctx).DateTime(until.In(tz))), nil
+	})
+}
+
+// parseReturnDate reads a return date in the user's timezone. A bare date
+// means the start of that day.
+func parseReturnDate(s string, timezone *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, format := range []string{"2006-01-02", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(format, s, timezone); err == nil {
+			if !t.After(time.Now()) {
+				return time.Time{}, fmt.Errorf("%s is in the past", s)
+			}
+			return t, nil
+		}
+	}
+	if t := parseExpiry(s, timezone); t != nil && t.After(time.Now()) {
+		return *t, nil
+	}
+	return time.Time{}, fmt.Errorf("could not parse date: %s", s)
+}
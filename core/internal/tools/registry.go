@@ -2,14 +2,22 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/llm"
 )
 
 func NewRegistry() *Registry {
 	return &Registry{
-		handlers: make(map[string]Handler),
+		handlers:    make(map[string]Handler),
+		cache:       newResultCache(maxCachedResults),
+		cacheTTL:    make(map[string]time.Duration),
+		invalidates: make(map[string][]string),
+		preparers:   make(map[string]Handler),
+
+		approvalChecks: make(map[string]func(args string) bool),
 	}
 }
 
@@ -18,16 +26,138 @@ func (r *Registry) Register(tool llm.Tool, handler Handler) {
 	r.handlers[tool.Name] = handler
 }
 
+// Tools returns the registered tools except disabled ones
 func (r *Registry) Tools() []llm.Tool {
-	return r.tools
+	r.disabledMu.RLock()
+	defer r.disabledMu.RUnlock()
+
+	if len(r.disabled) == 0 {
+		return r.tools
+	}
+	tools := make([]llm.Tool, 0, len(r.tools))
+	for _, t := range r.tools {
+		if !r.disabled[t.Name] {
+			tools = append(tools, t)
+		}
+	}
+	return tools
+}
+
+// SetDisabled replaces the set of tools hidden from the model. It can be
+// called at any time, e.g. when the config is reloaded.
+func (r *Registry) SetDisabled(names []string) {
+	disabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		disabled[name] = true
+	}
+
+	r.disabledMu.Lock()
+	defer r.disabledMu.Unlock()
+	r.disabled = disabled
+}
+
+func (r *Registry) isDisabled(name string) bool {
+	r.disabledMu.RLock()
+	defer r.disabledMu.RUnlock()
+	return r.disabled[name]
+}
+
+// Cacheable marks a read-only tool whose results can be reused for identical
+// calls within ttl. Only successful results are cached.
+func (r *Registry) Cacheable(name string, ttl time.Duration) {
+	r.cacheTTL[name] = ttl
+}
+
+// Invalidates clears cached results of the given tools whenever name succeeds,
+// e.g. saving a memory makes earlier recalls stale
+func (r *Registry) Invalidates(name string, cached ...string) {
+	r.invalidates[name] = append(r.invalidates[name], cached...)
+}
+
+// ConditionallyDangerous decides per call whether name needs approval, for
+// tools where only some arguments do (run_remote_command asks unless the
+// command is read-only)
+func (r *Registry) ConditionallyDangerous(name string, check func(args string) bool) {
+	r.approvalChecks[name] = check
+}
+
+// Prepares registers fn to fill in a tool's default arguments before the
+// call is approved and run, so the approval prompt shows what will actually
+// happen, e.g. the address a reply goes to
+func (r *Registry) Prepares(name string, fn Handler) {
+	r.preparers[name] = fn
+}
+
+// Prepare returns args with the tool's defaults filled in, or args unchanged
+// if it has no preparer
+func (r *Registry) Prepare(ctx context.Context, name, args string) (string, error) {
+	prepare, ok := r.preparers[name]
+	if !ok {
+		return args, nil
+	}
+	return prepare(ctx, args)
+}
+
+// Invalidate drops cached results of the given tools, for changes made
+// outside a tool call such as a document indexed from an upload
+func (r *Registry) Invalidate(cached ...string) {
+	for _, name := range cached {
+		r.cache.purge(name)
+	}
+}
+
+// SetRedactor scrubs every tool result and error before the model sees it,
+// e.g. app secrets echoed back in logs
+func (r *Registry) SetRedactor(fn func(string) string) {
+	r.redact = fn
 }
 
 func (r *Registry) Execute(ctx context.Context, name, args string) (string, error) {
+	result, err := r.execute(ctx, name, args)
+	if r.redact == nil {
+		return result, err
+	}
+	if err != nil {
+		if scrubbed := r.redact(err.Error()); scrubbed != err.Error() {
+			err = errors.New(scrubbed)
+		}
+	}
+	return r.redact(result), err
+}
+
+func (r *Registry) execute(ctx context.Context, name, args string) (string, error) {
 	handler, ok := r.handlers[name]
 	if !ok {
 		return "", fmt.Errorf("unknown tool: %s", name)
 	}
-	return handler(ctx, args)
+	if r.isDisabled(name) {
+		return "", fmt.Errorf("tool %s is disabled", name)
+	}
+	if !ToolAllowed(ctx, name) {
+		return "", fmt.Errorf("tool %s is not allowed here", name)
+	}
+
+	ttl, cacheable := r.cacheTTL[name]
+	if !cacheable {
+		result, err := handler(ctx, args)
+		if err == nil {
+			for _, stale := range r.invalidates[name] {
+				r.cache.purge(stale)
+			}
+		}
+		return result, err
+	}
+
+	key := cacheKey(name, SessionIDFromContext(ctx), UserEntityName(ctx), SafeModeFromContext(ctx), args)
+	if result, ok := r.cache.get(key); ok {
+		return result, nil
+	}
+
+	result, err := handler(ctx, args)
+	if err == nil {
+		r.cache.put(key, result, ttl)
+	}
+	return result, err
 }
 
 func (r *Registry) SetNotify(fn NotifyFunc) {
@@ -38,6 +168,9 @@ func (r *Registry) Notify(ctx context.Context, message string) {
 	if r.notify == nil {
 		return
 	}
+	if r.redact != nil {
+		message = r.redact(message)
+	}
 	chatID := ChatIDFromContext(ctx)
 	if chatID != 0 {
 		r.notify(chatID, message)
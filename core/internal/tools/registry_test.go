@@ -3,7 +3,9 @@ package tools
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/llm"
 )
@@ -48,6 +50,89 @@ func TestRegistryExecuteUnknownTool(t *testing.T) {
 	}
 }
 
+func TestRegistryDisabledTools(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range []string{"a", "b"} {
+		r.Register(llm.Tool{Name: name}, func(ctx context.Context, args string) (string, error) {
+			return "ok", nil
+		})
+	}
+
+	r.SetDisabled([]string{"b"})
+	if tools := r.Tools(); len(tools) != 1 || tools[0].Name != "a" {
+		t.Errorf("expected only a, got %v", tools)
+	}
+	if _, err := r.Execute(context.Background(), "b", ""); err == nil {
+		t.Error("expected error executing a disabled tool")
+	}
+
+	r.SetDisabled(nil)
+	if len(r.Tools()) != 2 {
+		t.Errorf("expected both tools after re-enabling, got %d", len(r.Tools()))
+	}
+}
+
+func TestRestrictTools(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range []string{"search_recipes", "deploy_app", "save_memory"} {
+		r.Register(llm.Tool{Name: name}, func(ctx context.Context, args string) (string, error) {
+			return "ok", nil
+		})
+	}
+
+	ctx := WithToolScope(context.Background())
+	if !ToolAllowed(ctx, "deploy_app") {
+		t.Fatal("unrestricted scope blocked deploy_app")
+	}
+
+	RestrictTools(ctx, []string{"search_recipes", "save_memory"})
+	if _, err := r.Execute(ctx, "deploy_app", "{}"); err == nil {
+		t.Error("deploy_app ran under a skill that didn't declare it")
+	}
+	if _, err := r.Execute(ctx, "search_recipes", "{}"); err != nil {
+		t.Errorf("search_recipes: %v", err)
+	}
+
+	// a second skill can't widen the first one's list
+	RestrictTools(WithToolScope(ctx), []string{"deploy_app", "save_memory"})
+	if ToolAllowed(ctx, "deploy_app") || ToolAllowed(ctx, "search_recipes") || !ToolAllowed(ctx, "save_memory") {
+		t.Error("second restriction did not intersect with the first")
+	}
+}
+
+func TestSkillToolAllowlist(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"---\nname: recipes\ndescription: Find recipes\ntools: [search_web, save_memory]\n---\n# Recipes", []string{"search_web", "save_memory"}},
+		{"---\nname: recipes\nallowed-tools: search_web, browse\n---\n# Recipes", []string{"search_web", "browse"}},
+		{"---\nname: recipes\n---\n# Recipes", nil},
+		{"# Recipes\ntools: deploy_app", nil},
+	}
+
+	for _, tt := range tests {
+		got := SkillToolAllowlist(tt.content)
+		if tt.want == nil {
+			if got != nil {
+				t.Errorf("SkillToolAllowlist(%q) = %v, want nil", tt.content, got)
+			}
+			continue
+		}
+		want := append(tt.want, skillBaseTools...)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("SkillToolAllowlist(%q) = %v, want %v", tt.content, got, want)
+		}
+	}
+
+	if desc := extractDescription("---\nname: recipes\ndescription: Find recipes\n---\n# Recipes"); desc != "Find recipes" {
+		t.Errorf("description = %q, want frontmatter description", desc)
+	}
+	if desc := extractDescription("---\nname: recipes\n---\n# Recipes\nCook from the fridge"); desc != "Cook from the fridge" {
+		t.Errorf("description = %q, want first body line", desc)
+	}
+}
+
 func TestRegistryExecuteWithError(t *testing.T) {
 	r := NewRegistry()
 
@@ -178,6 +263,15 @@ func TestUserEntityName(t *testing.T) {
 	}
 }
 
+func TestUserEntityNameSpeakerOverride(t *testing.T) {
+	ctx := context.WithValue(context.Background(), SessionIDKey, "telegram:-100123")
+	ctx = context.WithValue(ctx, UserEntityKey, "user_telegram_42")
+
+	if got := UserEntityName(ctx); got != "user_telegram_42" {
+		t.Errorf("UserEntityName = %s, want user_telegram_42", got)
+	}
+}
+
 func TestSafeModeFromContext(t *testing.T) {
 	ctx := context.WithValue(context.Background(), SafeModeKey, true)
 	if !SafeModeFromContext(ctx) {
@@ -188,3 +282,164 @@ func TestSafeModeFromContext(t *testing.T) {
 		t.Error("expected safe mode false when not set")
 	}
 }
+
+func TestRegistryCachesResults(t *testing.T) {
+	r := NewRegistry()
+
+	calls := 0
+	r.Register(llm.Tool{Name: "lookup"}, func(ctx context.Context, args string) (string, error) {
+		calls++
+		return "result", nil
+	})
+	r.Cacheable("lookup", time.Minute)
+
+	ctx := context.WithValue(context.Background(), SessionIDKey, "telegram:1")
+	r.Execute(ctx, "lookup", `{"q":"go","limit":5}`)
+	r.Execute(ctx, "lookup", `{"limit": 5, "q": "go"}`)
+	if calls != 1 {
+		t.Errorf("expected 1 call for identical args, got %d", calls)
+	}
+
+	r.Execute(ctx, "lookup", `{"q":"rust"}`)
+	if calls != 2 {
+		t.Errorf("expected different args to miss cache, got %d calls", calls)
+	}
+
+	other := context.WithValue(context.Background(), SessionIDKey, "telegram:2")
+	r.Execute(other, "lookup", `{"q":"go","limit":5}`)
+	if calls != 3 {
+		t.Errorf("expected cache to be scoped per session, got %d calls", calls)
+	}
+
+	speaker := context.WithValue(ctx, UserEntityKey, "user_telegram_42")
+	r.Execute(speaker, "lookup", `{"q":"go","limit":5}`)
+	if calls != 4 {
+		t.Errorf("expected cache to be scoped per group member, got %d calls", calls)
+	}
+}
+
+func TestRegistryPrepare(t *testing.T) {
+	r := NewRegistry()
+	r.Prepares("send", func(ctx context.Context, args string) (string, error) {
+		return `{"to":"alice@example.com"}`, nil
+	})
+
+	args, err := r.Prepare(context.Background(), "send", `{}`)
+	if err != nil || args != `{"to":"alice@example.com"}` {
+		t.Errorf("Prepare = %q, %v; want the preparer's arguments", args, err)
+	}
+
+	args, err = r.Prepare(context.Background(), "other", `{"q":"go"}`)
+	if err != nil || args != `{"q":"go"}` {
+		t.Errorf("Prepare = %q, %v; want arguments unchanged without a preparer", args, err)
+	}
+}
+
+func TestRegistryRequiresApproval(t *testing.T) {
+	r := NewRegistry()
+	r.ConditionallyDangerous("run", func(args string) bool {
+		return args != `{"command":"uptime"}`
+	})
+
+	if r.RequiresApproval("run", `{"command":"uptime"}`) {
+		t.Error("read-only call should run without approval")
+	}
+	if !r.RequiresApproval("run", `{"command":"reboot"}`) {
+		t.Error("other calls should need approval")
+	}
+	if !r.RequiresApproval("deploy_app", "{}") {
+		t.Error("DangerousTools should always need approval")
+	}
+	if NewRegistry().RequiresApproval("run", `{"command":"reboot"}`) {
+		t.Error("a check registered on one registry leaked into another")
+	}
+}
+
+func TestRegistryDoesNotCacheErrors(t *testing.T) {
+	r := NewRegistry()
+
+	calls := 0
+	r.Register(llm.Tool{Name: "flaky"}, func(ctx context.Context, args string) (string, error) {
+		calls++
+		return "", errors.New("rate limited")
+	})
+	r.Cacheable("flaky", time.Minute)
+
+	r.Execute(context.Background(), "flaky", "{}")
+	r.Execute(context.Background(), "flaky", "{}")
+	if calls != 2 {
+		t.Errorf("expected errors to bypass cache, got %d calls", calls)
+	}
+}
+
+func TestRegistryCacheInvalidation(t *testing.T) {
+	r := NewRegistry()
+
+	calls := 0
+	r.Register(llm.Tool{Name: "recall"}, func(ctx context.Context, args string) (string, error) {
+		calls++
+		return "facts", nil
+	})
+	r.Register(llm.Tool{Name: "save"}, func(ctx context.Context, args string) (string, error) {
+		return "saved", nil
+	})
+	r.Cacheable("recall", time.Minute)
+	r.Invalidates("save", "recall")
+
+	r.Execute(context.Background(), "recall", "{}")
+	r.Execute(context.Background(), "save", "{}")
+	r.Execute(context.Background(), "recall", "{}")
+	if calls != 2 {
+		t.Errorf("expected save to invalidate recall, got %d calls", calls)
+	}
+
+	r.Invalidate("recall")
+	r.Execute(context.Background(), "recall", "{}")
+	if calls != 3 {
+		t.Errorf("expected Invalidate to clear recall, got %d calls", calls)
+	}
+}
+
+func TestResultCacheExpiryAndEviction(t *testing.T) {
+	c := newResultCache(2)
+
+	c.put("a", "1", -time.Second)
+	if _, ok := c.get("a"); ok {
+		t.Error("expected expired entry to miss")
+	}
+
+	c.put("a", "1", time.Minute)
+	c.put("b", "2", time.Minute)
+	c.get("a")
+	c.put("c", "3", time.Minute)
+
+	if _, ok := c.get("b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if v, ok := c.get("a"); !ok || v != "1" {
+		t.Error("expected recently used entry to survive")
+	}
+}
+
+func TestRegistryRedactsResults(t *testing.T) {
+	r := NewRegistry()
+	r.Register(llm.Tool{Name: "leaky"}, func(ctx context.Context, args string) (string, error) {
+		if args == "fail" {
+			return "", errors.New("auth failed with hunter2")
+		}
+		return "token=hunter2", nil
+	})
+	r.SetRedactor(func(s string) string {
+		return strings.ReplaceAll(s, "hunter2", "[secret]")
+	})
+
+	result, err := r.Execute(context.Background(), "leaky", "")
+	if err != nil || result != "token=[secret]" {
+		t.Errorf("got %q, %v", result, err)
+	}
+
+	_, err = r.Execute(context.Background(), "leaky", "fail")
+	if err == nil || err.Error() != "auth failed with [secret]" {
+		t.Errorf("error not redacted: %v", err)
+	}
+}
@@ -2,52 +2,114 @@ package tools
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/bowerhall/sheldon/internal/config"
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
 )
 
 type RemoteClient struct {
 	runtimeConfig *config.RuntimeConfig
 	client        *http.Client
+	token         string
+	https         bool
+	envHosts      map[string]string
 }
 
-func NewRemoteClient(rc *config.RuntimeConfig) *RemoteClient {
+func NewRemoteClient(rc *config.RuntimeConfig, agentCfg config.HomelabAgentConfig) (*RemoteClient, error) {
+	transport, err := agentTransport(agentCfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &RemoteClient{
 		runtimeConfig: rc,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
+		token:    agentCfg.Token,
+		https:    agentCfg.CAFile != "",
+		envHosts: agentCfg.Hosts,
+	}, nil
+}
+
+// agentTransport trusts the agents' CA and presents a client cert when configured;
+// nil keeps the default transport for plain HTTP
+func agentTransport(cfg config.HomelabAgentConfig) (http.RoundTripper, error) {
+	if cfg.CAFile == "" && cfg.CertFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read agent CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load agent client cert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
 }
 
-func (h *RemoteClient) agentURL() string {
+// ollamaAgentURL is the agent next to a remote Ollama, empty when Ollama is local
+func (h *RemoteClient) ollamaAgentURL() string {
 	ollamaHost := h.runtimeConfig.Get("ollama_host")
 	// homelab-agent runs on port 8080, ollama on 11434
 	// if ollama_host is http://gpu-monster:11434, agent is http://gpu-monster:8080
-	if strings.Contains(ollamaHost, ":11434") {
-		return strings.Replace(ollamaHost, ":11434", ":8080", 1)
+	if !strings.Contains(ollamaHost, ":11434") || strings.Contains(ollamaHost, "://ollama:") ||
+		strings.Contains(ollamaHost, "localhost") || strings.Contains(ollamaHost, "127.0.0.1") {
+		return ""
+	}
+	agentURL := strings.Replace(ollamaHost, ":11434", ":8080", 1)
+	if h.https {
+		agentURL = strings.Replace(agentURL, "http://", "https://", 1)
 	}
-	// default to localhost
-	return "http://localhost:8080"
+	return strings.TrimRight(agentURL, "/")
 }
 
-func (h *RemoteClient) isLocalhost() bool {
-	url := h.agentURL()
-	return strings.Contains(url, "localhost") || strings.Contains(url, "127.0.0.1")
+// do sends a request to the agent with the shared bearer token
+func (h *RemoteClient) do(req *http.Request) (*http.Response, error) {
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+	return h.client.Do(req)
 }
 
-func RegisterRemoteTools(registry *Registry, rc *config.RuntimeConfig) {
-	client := NewRemoteClient(rc)
+func RegisterRemoteTools(registry *Registry, rc *config.RuntimeConfig, agentCfg config.HomelabAgentConfig) {
+	client, err := NewRemoteClient(rc, agentCfg)
+	if err != nil {
+		logger.Error("remote tools disabled", "error", err)
+		return
+	}
 
-	// Don't register remote tools if ollama is local (no homelab-agent)
-	if client.isLocalhost() || strings.Contains(client.agentURL(), "://ollama:") {
+	// without any agent (ollama is local and no hosts were added) only host management is offered
+	agentToolsEnabled := len(client.Hosts()) > 0
+	registerRemoteHostTools(registry, client, agentToolsEnabled)
+	if !agentToolsEnabled {
 		return
 	}
 
@@ -58,38 +120,58 @@ func RegisterRemoteTools(registry *Registry, rc *config.RuntimeConfig) {
 	registerContainerStop(registry, client)
 	registerContainerStart(registry, client)
 	registerContainerLogs(registry, client)
+	registerListServices(registry, client)
+	registerServiceStatus(registry, client)
+	registerServiceRestart(registry, client)
+	registerServiceLogs(registry, client)
+	registerDockerDiskUsage(registry, client)
+	registerLargestImages(registry, client)
+	registerPruneDocker(registry, client)
+	registerRemoteProcesses(registry, client)
 }
 
 func registerRemoteStatus(registry *Registry, client *RemoteClient) {
 	tool := llm.Tool{
 		Name:        "remote_status",
-		Description: "Get system status of the current Ollama host machine (CPU, memory, disk usage). Works on remote machines connected via Tailscale.",
+		Description: "Get system status of a remote machine running homelab-agent (CPU, memory, disk usage), the Ollama host by default. Works on machines connected via Tailscale.",
 		Parameters: map[string]any{
-			"type":       "object",
-			"properties": map[string]any{},
+			"type": "object",
+			"properties": map[string]any{
+				"host": hostParam,
+			},
 		},
 	}
 
 	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
-		if client.isLocalhost() {
-			return "remote_status only works on remote machines. Current ollama_host is localhost.", nil
+		var params struct {
+			Host string `json:"host"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
 		}
 
-		url := client.agentURL() + "/status"
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		url := host.URL + "/status"
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return "", fmt.Errorf("create request: %w", err)
 		}
 
-		resp, err := client.client.Do(req)
+		resp, err := client.do(req)
 		if err != nil {
-			return "", fmt.Errorf("remote host unreachable: %w", err)
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return "", fmt.Errorf("remote host returned %d: %s", resp.StatusCode, string(body))
+			return "", fmt.Errorf("%s returned %d: %s", host.Name, resp.StatusCode, string(body))
 		}
 
 		var status struct {
@@ -132,31 +214,43 @@ func registerListContainers(registry *Registry, client *RemoteClient) {
 		Name:        "list_containers",
 		Description: "List all Docker containers on the remote host. Shows name, image, status, and whether running.",
 		Parameters: map[string]any{
-			"type":       "object",
-			"properties": map[string]any{},
+			"type": "object",
+			"properties": map[string]any{
+				"host": hostParam,
+			},
 		},
 	}
 
 	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
-		if client.isLocalhost() {
-			return "list_containers only works on remote machines. Current ollama_host is localhost.", nil
+		var params struct {
+			Host string `json:"host"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
 		}
 
-		url := client.agentURL() + "/containers"
+		url := host.URL + "/containers"
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return "", fmt.Errorf("create request: %w", err)
 		}
 
-		resp, err := client.client.Do(req)
+		resp, err := client.do(req)
 		if err != nil {
-			return "", fmt.Errorf("remote host unreachable: %w", err)
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return "", fmt.Errorf("remote host returned %d: %s", resp.StatusCode, string(body))
+			return "", fmt.Errorf("%s returned %d: %s", host.Name, resp.StatusCode, string(body))
 		}
 
 		var containers []struct {
@@ -171,11 +265,11 @@ func registerListContainers(registry *Registry, client *RemoteClient) {
 		}
 
 		if len(containers) == 0 {
-			return "no containers found on remote host", nil
+			return fmt.Sprintf("no containers found on %s", host.Name), nil
 		}
 
 		var sb strings.Builder
-		sb.WriteString("containers on remote host:\n\n")
+		sb.WriteString(fmt.Sprintf("containers on %s:\n\n", host.Name))
 		for _, c := range containers {
 			state := "stopped"
 			if c.Running {
@@ -200,32 +294,35 @@ func registerContainerStatus(registry *Registry, client *RemoteClient) {
 					"type":        "string",
 					"description": "Container name (e.g., 'ollama', 'sheldon', 'minio')",
 				},
+				"host": hostParam,
 			},
 			"required": []string{"name"},
 		},
 	}
 
 	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
-		if client.isLocalhost() {
-			return "container_status only works on remote machines.", nil
-		}
-
 		var params struct {
 			Name string `json:"name"`
+			Host string `json:"host"`
 		}
 		if err := json.Unmarshal([]byte(args), &params); err != nil {
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
-		url := client.agentURL() + "/containers/" + params.Name
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		url := host.URL + "/containers/" + params.Name
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return "", fmt.Errorf("create request: %w", err)
 		}
 
-		resp, err := client.client.Do(req)
+		resp, err := client.do(req)
 		if err != nil {
-			return "", fmt.Errorf("remote host unreachable: %w", err)
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
 		}
 		defer resp.Body.Close()
 
@@ -262,34 +359,37 @@ func registerContainerRestart(registry *Registry, client *RemoteClient) {
 					"type":        "string",
 					"description": "Container name to restart (e.g., 'ollama')",
 				},
+				"host": hostParam,
 			},
 			"required": []string{"name"},
 		},
 	}
 
 	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
-		if client.isLocalhost() {
-			return "restart_container only works on remote machines. Ask the user to restart it manually.", nil
-		}
-
 		var params struct {
 			Name string `json:"name"`
+			Host string `json:"host"`
 		}
 		if err := json.Unmarshal([]byte(args), &params); err != nil {
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
-		registry.Notify(ctx, fmt.Sprintf("restarting %s on remote host...", params.Name))
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("restarting %s on %s...", params.Name, host.Name))
 
-		url := client.agentURL() + "/containers/" + params.Name + "/restart"
+		url := host.URL + "/containers/" + params.Name + "/restart"
 		req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 		if err != nil {
 			return "", fmt.Errorf("create request: %w", err)
 		}
 
-		resp, err := client.client.Do(req)
+		resp, err := client.do(req)
 		if err != nil {
-			return "", fmt.Errorf("remote host unreachable: %w", err)
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
 		}
 		defer resp.Body.Close()
 
@@ -314,34 +414,37 @@ func registerContainerStop(registry *Registry, client *RemoteClient) {
 					"type":        "string",
 					"description": "Container name to stop",
 				},
+				"host": hostParam,
 			},
 			"required": []string{"name"},
 		},
 	}
 
 	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
-		if client.isLocalhost() {
-			return "stop_container only works on remote machines.", nil
-		}
-
 		var params struct {
 			Name string `json:"name"`
+			Host string `json:"host"`
 		}
 		if err := json.Unmarshal([]byte(args), &params); err != nil {
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
-		registry.Notify(ctx, fmt.Sprintf("stopping %s on remote host...", params.Name))
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("stopping %s on %s...", params.Name, host.Name))
 
-		url := client.agentURL() + "/containers/" + params.Name + "/stop"
+		url := host.URL + "/containers/" + params.Name + "/stop"
 		req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 		if err != nil {
 			return "", fmt.Errorf("create request: %w", err)
 		}
 
-		resp, err := client.client.Do(req)
+		resp, err := client.do(req)
 		if err != nil {
-			return "", fmt.Errorf("remote host unreachable: %w", err)
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
 		}
 		defer resp.Body.Close()
 
@@ -365,34 +468,37 @@ func registerContainerStart(registry *Registry, client *RemoteClient) {
 					"type":        "string",
 					"description": "Container name to start",
 				},
+				"host": hostParam,
 			},
 			"required": []string{"name"},
 		},
 	}
 
 	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
-		if client.isLocalhost() {
-			return "start_container only works on remote machines.", nil
-		}
-
 		var params struct {
 			Name string `json:"name"`
+			Host string `json:"host"`
 		}
 		if err := json.Unmarshal([]byte(args), &params); err != nil {
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
-		registry.Notify(ctx, fmt.Sprintf("starting %s on remote host...", params.Name))
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
 
-		url := client.agentURL() + "/containers/" + params.Name + "/start"
+		registry.Notify(ctx, fmt.Sprintf("starting %s on %s...", params.Name, host.Name))
+
+		url := host.URL + "/containers/" + params.Name + "/start"
 		req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 		if err != nil {
 			return "", fmt.Errorf("create request: %w", err)
 		}
 
-		resp, err := client.client.Do(req)
+		resp, err := client.do(req)
 		if err != nil {
-			return "", fmt.Errorf("remote host unreachable: %w", err)
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
 		}
 		defer resp.Body.Close()
 
@@ -420,36 +526,40 @@ func registerContainerLogs(registry *Registry, client *RemoteClient) {
 					"type":        "integer",
 					"description": "Number of log lines to retrieve (default: 50)",
 				},
+				"host": hostParam,
 			},
 			"required": []string{"name"},
 		},
 	}
 
 	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
-		if client.isLocalhost() {
-			return "container_logs only works on remote machines.", nil
-		}
-
 		var params struct {
 			Name  string `json:"name"`
 			Lines int    `json:"lines"`
+			Host  string `json:"host"`
 		}
 		if err := json.Unmarshal([]byte(args), &params); err != nil {
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
 		if params.Lines == 0 {
 			params.Lines = 50
 		}
 
-		url := fmt.Sprintf("%s/containers/%s/logs?lines=%d", client.agentURL(), params.Name, params.Lines)
+		url := fmt.Sprintf("%s/containers/%s/logs?lines=%d", host.URL, params.Name, params.Lines)
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return "", fmt.Errorf("create request: %w", err)
 		}
 
-		resp, err := client.client.Do(req)
+		resp, err := client.do(req)
 		if err != nil {
-			return "", fmt.Errorf("remote host unreachable: %w", err)
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
 		}
 		defer resp.Body.Close()
 
@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+func registerDockerDiskUsage(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "docker_disk_usage",
+		Description: "Show how much disk Docker uses on a remote host (images, containers, volumes, build cache) and how much is reclaimable. Check this first when a host runs low on disk.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"host": hostParam,
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Host string `json:"host"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		body, err := client.get(ctx, host, "/docker/df")
+		if err != nil {
+			return "", err
+		}
+
+		var usage []struct {
+			Type        string `json:"type"`
+			Total       int    `json:"total"`
+			Active      int    `json:"active"`
+			Size        string `json:"size"`
+			Reclaimable string `json:"reclaimable"`
+		}
+		if err := json.Unmarshal(body, &usage); err != nil {
+			return "", fmt.Errorf("decode response: %w", err)
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("💾 docker disk usage on %s:\n\n", host.Name))
+		for _, u := range usage {
+			sb.WriteString(fmt.Sprintf("  %s: %s (%d total, %d active), reclaimable %s\n", u.Type, u.Size, u.Total, u.Active, u.Reclaimable))
+		}
+		return sb.String(), nil
+	})
+}
+
+func registerLargestImages(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "largest_images",
+		Description: "List the largest Docker images on a remote host, biggest first. <none> images are dangling and safe to prune.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "How many images to show (default: 10)",
+				},
+				"host": hostParam,
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Limit int    `json:"limit"`
+			Host  string `json:"host"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		if params.Limit <= 0 {
+			params.Limit = 10
+		}
+
+		body, err := client.get(ctx, host, fmt.Sprintf("/docker/images?limit=%d", params.Limit))
+		if err != nil {
+			return "", err
+		}
+
+		var images []struct {
+			Repository string `json:"repository"`
+			Tag        string `json:"tag"`
+			ID         string `json:"id"`
+			Size       string `json:"size"`
+			Created    string `json:"created"`
+		}
+		if err := json.Unmarshal(body, &images); err != nil {
+			return "", fmt.Errorf("decode response: %w", err)
+		}
+
+		if len(images) == 0 {
+			return fmt.Sprintf("no images on %s", host.Name), nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("largest images on %s:\n\n", host.Name))
+		for _, img := range images {
+			sb.WriteString(fmt.Sprintf("  %s  %s:%s (%s, created %s)\n", img.Size, img.Repository, img.Tag, img.ID, img.Created))
+		}
+		return sb.String(), nil
+	})
+}
+
+func registerPruneDocker(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "prune_docker",
+		Description: "Free disk on a remote host by removing dangling Docker images and, only if the user agrees, unused volumes. Reports how much space was reclaimed. Volumes may hold data, so never prune them unasked.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"images": map[string]any{
+					"type":        "boolean",
+					"description": "Remove dangling (<none>) images (default: true)",
+				},
+				"volumes": map[string]any{
+					"type":        "boolean",
+					"description": "Remove volumes no container uses (default: false)",
+				},
+				"host": hostParam,
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		params := struct {
+			Images  *bool  `json:"images"`
+			Volumes bool   `json:"volumes"`
+			Host    string `json:"host"`
+		}{}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		images := params.Images == nil || *params.Images
+		if !images && !params.Volumes {
+			return "nothing to prune, set images and/or volumes", nil
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("pruning docker on %s...", host.Name))
+
+		payload, _ := json.Marshal(map[string]bool{"images": images, "volumes": params.Volumes})
+		req, err := http.NewRequestWithContext(ctx, "POST", host.URL+"/docker/prune", bytes.NewReader(payload))
+		if err != nil {
+			return "", fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.do(req)
+		if err != nil {
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("prune failed: %s", strings.TrimSpace(string(body)))
+		}
+
+		var results []struct {
+			Kind      string `json:"kind"`
+			Deleted   int    `json:"deleted"`
+			Reclaimed string `json:"reclaimed"`
+		}
+		if err := json.Unmarshal(body, &results); err != nil {
+			return "", fmt.Errorf("decode response: %w", err)
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("🧹 pruned docker on %s:\n", host.Name))
+		for _, r := range results {
+			sb.WriteString(fmt.Sprintf("  %s: %d removed, %s reclaimed\n", r.Kind, r.Deleted, r.Reclaimed))
+		}
+		return sb.String(), nil
+	})
+}
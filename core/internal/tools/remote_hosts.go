@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+var validHostName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// RemoteHost is a machine running homelab-agent
+type RemoteHost struct {
+	Name   string
+	URL    string
+	Source string // ollama_host, HOMELAB_HOSTS or added
+}
+
+// hostParam is the optional host argument shared by every remote tool
+var hostParam = map[string]any{
+	"type":        "string",
+	"description": "Remote host name from list_remote_hosts (default: the Ollama host, or the only host)",
+}
+
+// Hosts lists every known agent: the one next to Ollama first, then HOMELAB_HOSTS, then hosts added at runtime
+func (h *RemoteClient) Hosts() []RemoteHost {
+	var hosts []RemoteHost
+	seen := make(map[string]bool)
+
+	if agentURL := h.ollamaAgentURL(); agentURL != "" {
+		name := "ollama"
+		if u, err := url.Parse(agentURL); err == nil && validHostName.MatchString(strings.ToLower(u.Hostname())) {
+			name = strings.ToLower(u.Hostname())
+		}
+		hosts = append(hosts, RemoteHost{Name: name, URL: agentURL, Source: "ollama_host"})
+		seen[name] = true
+	}
+
+	add := func(named map[string]string, source string) {
+		names := make([]string, 0, len(named))
+		for name := range named {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			agentURL, err := h.normalizeAgentURL(named[name])
+			if err != nil {
+				continue
+			}
+			hosts = append(hosts, RemoteHost{Name: name, URL: agentURL, Source: source})
+			seen[name] = true
+		}
+	}
+	add(h.envHosts, "HOMELAB_HOSTS")
+	add(h.runtimeConfig.RemoteHosts(), "added")
+
+	return hosts
+}
+
+// host resolves a host argument; empty picks the Ollama host or the only host there is
+func (h *RemoteClient) host(name string) (RemoteHost, error) {
+	hosts := h.Hosts()
+	if len(hosts) == 0 {
+		return RemoteHost{}, fmt.Errorf("no remote hosts configured (ollama_host is local), add one with add_remote_host")
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		if hosts[0].Source == "ollama_host" || len(hosts) == 1 {
+			return hosts[0], nil
+		}
+		return RemoteHost{}, fmt.Errorf("several remote hosts are configured, pass host: %s", hostNames(hosts))
+	}
+
+	for _, host := range hosts {
+		if host.Name == name {
+			return host, nil
+		}
+	}
+	return RemoteHost{}, fmt.Errorf("unknown remote host %q, known hosts: %s", name, hostNames(hosts))
+}
+
+func hostNames(hosts []RemoteHost) string {
+	names := make([]string, len(hosts))
+	for i, host := range hosts {
+		names[i] = host.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// normalizeAgentURL accepts "nas", "nas:8080" or a full URL and fills in the scheme and agent port
+func (h *RemoteClient) normalizeAgentURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		scheme := "http://"
+		if h.https {
+			scheme = "https://"
+		}
+		raw = scheme + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid agent address %q", raw)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("agent address must be http or https")
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), "8080")
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+func registerRemoteHostTools(registry *Registry, client *RemoteClient, agentToolsEnabled bool) {
+	registry.Register(llm.Tool{
+		Name:        "list_remote_hosts",
+		Description: "List the machines running homelab-agent that the remote tools (remote_status, list_containers, restart_service, ...) can manage. Pass a name as their host argument.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, func(ctx context.Context, args string) (string, error) {
+		hosts := client.Hosts()
+		if len(hosts) == 0 {
+			return "no remote hosts configured. Add one with add_remote_host.", nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString("🖥️ remote hosts:\n\n")
+		for i, host := range hosts {
+			note := ""
+			if i == 0 && (host.Source == "ollama_host" || len(hosts) == 1) {
+				note = ", default"
+			}
+			sb.WriteString(fmt.Sprintf("  %s: %s (%s%s)\n", host.Name, host.URL, host.Source, note))
+		}
+		return sb.String(), nil
+	})
+
+	registry.Register(llm.Tool{
+		Name:        "add_remote_host",
+		Description: "Register another machine running homelab-agent so the remote tools can manage it. Sheldon sends it the agent token, so only add machines the user owns.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Short name to refer to the host by (e.g., 'nas', 'pi')",
+				},
+				"address": map[string]any{
+					"type":        "string",
+					"description": "Agent address: hostname, host:port or URL (port defaults to 8080), e.g. 'nas' or 'http://100.64.0.5:8080'",
+				},
+			},
+			"required": []string{"name", "address"},
+		},
+	}, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Name    string `json:"name"`
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		name := strings.ToLower(strings.TrimSpace(params.Name))
+		if !validHostName.MatchString(name) {
+			return "", fmt.Errorf("host name must be lowercase letters, digits and dashes")
+		}
+		for _, host := range client.Hosts() {
+			if host.Name == name && host.Source != "added" {
+				return "", fmt.Errorf("%q is already configured via %s", name, host.Source)
+			}
+		}
+
+		agentURL, err := client.normalizeAgentURL(params.Address)
+		if err != nil {
+			return "", err
+		}
+		if err := client.runtimeConfig.SetRemoteHost(name, agentURL); err != nil {
+			return "", fmt.Errorf("save host: %w", err)
+		}
+
+		result := fmt.Sprintf("✅ added remote host %s (%s)", name, agentURL)
+		if _, err := client.get(ctx, RemoteHost{Name: name, URL: agentURL}, "/status"); err != nil {
+			result += fmt.Sprintf("\n⚠️ the agent didn't answer yet: %v", err)
+		}
+		if !agentToolsEnabled {
+			result += "\nrestart Sheldon to enable the container and service tools"
+		}
+		return result, nil
+	})
+
+	registry.Register(llm.Tool{
+		Name:        "remove_remote_host",
+		Description: "Forget a remote host added with add_remote_host.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Host name from list_remote_hosts",
+				},
+			},
+			"required": []string{"name"},
+		},
+	}, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		name := strings.ToLower(strings.TrimSpace(params.Name))
+		removed, err := client.runtimeConfig.RemoveRemoteHost(name)
+		if err != nil {
+			return "", fmt.Errorf("remove host: %w", err)
+		}
+		if !removed {
+			return fmt.Sprintf("%q wasn't added with add_remote_host (hosts from ollama_host or HOMELAB_HOSTS are configured in the environment)", name), nil
+		}
+		return fmt.Sprintf("🗑️ removed remote host %s", name), nil
+	})
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bowerhall/sheldon/internal/config"
+)
+
+func testRemoteClient(t *testing.T, ollamaHost string, envHosts map[string]string) *RemoteClient {
+	t.Helper()
+	t.Setenv("OLLAMA_HOST", ollamaHost)
+
+	rc, err := config.NewRuntimeConfig(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewRemoteClient(rc, config.HomelabAgentConfig{Hosts: envHosts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRemoteHostsOrderAndDefault(t *testing.T) {
+	client := testRemoteClient(t, "http://gpu-monster:11434", map[string]string{"pi": "pi.lan", "nas": "http://nas:9090"})
+	if err := client.runtimeConfig.SetRemoteHost("backup", "http://backup:8080"); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := client.Hosts()
+	var got []string
+	for _, h := range hosts {
+		got = append(got, h.Name+"="+h.URL)
+	}
+	want := "gpu-monster=http://gpu-monster:8080 nas=http://nas:9090 pi=http://pi.lan:8080 backup=http://backup:8080"
+	if strings.Join(got, " ") != want {
+		t.Errorf("hosts = %v, want %s", got, want)
+	}
+
+	host, err := client.host("")
+	if err != nil || host.Name != "gpu-monster" {
+		t.Errorf("default host = %v, %v, want the ollama host", host, err)
+	}
+	if host, err := client.host("NAS"); err != nil || host.URL != "http://nas:9090" {
+		t.Errorf("host(NAS) = %v, %v", host, err)
+	}
+	if _, err := client.host("fridge"); err == nil || !strings.Contains(err.Error(), "known hosts: gpu-monster, nas, pi, backup") {
+		t.Errorf("unknown host error = %v", err)
+	}
+}
+
+func TestRemoteHostDefaultNeedsOllamaOrSingleHost(t *testing.T) {
+	client := testRemoteClient(t, "http://localhost:11434", nil)
+	if _, err := client.host(""); err == nil {
+		t.Error("expected an error with no hosts")
+	}
+
+	client.runtimeConfig.SetRemoteHost("nas", "http://nas:8080")
+	if host, err := client.host(""); err != nil || host.Name != "nas" {
+		t.Errorf("single host should be the default, got %v, %v", host, err)
+	}
+
+	client.runtimeConfig.SetRemoteHost("pi", "http://pi:8080")
+	if _, err := client.host(""); err == nil || !strings.Contains(err.Error(), "pass host") {
+		t.Errorf("expected ambiguity error, got %v", err)
+	}
+}
+
+func TestNormalizeAgentURL(t *testing.T) {
+	client := testRemoteClient(t, "", nil)
+
+	tests := map[string]string{
+		"nas":                      "http://nas:8080",
+		"nas:9000":                 "http://nas:9000",
+		"http://100.64.0.5":        "http://100.64.0.5:8080",
+		"https://nas.lan:8443/x/y": "https://nas.lan:8443",
+	}
+	for raw, want := range tests {
+		if got, err := client.normalizeAgentURL(raw); err != nil || got != want {
+			t.Errorf("normalizeAgentURL(%q) = %q, %v, want %q", raw, got, err, want)
+		}
+	}
+
+	if _, err := client.normalizeAgentURL("ftp://nas"); err == nil {
+		t.Error("expected an error for a non-http scheme")
+	}
+
+	client.https = true
+	if got, _ := client.normalizeAgentURL("nas"); got != "https://nas:8080" {
+		t.Errorf("with a CA configured bare hosts should use https, got %q", got)
+	}
+}
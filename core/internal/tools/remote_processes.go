@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+func registerRemoteProcesses(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "remote_processes",
+		Description: "Show the top processes on a remote host by CPU or memory, and optionally its listening ports. Use it to find out why a machine is slow or what is holding a port.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"sort": map[string]any{
+					"type":        "string",
+					"enum":        []string{"cpu", "memory"},
+					"description": "Order by current CPU use or resident memory (default: cpu)",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "How many processes to show (default: 15)",
+				},
+				"ports": map[string]any{
+					"type":        "boolean",
+					"description": "Also list listening TCP/UDP ports and the process on each",
+				},
+				"host": hostParam,
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Sort  string `json:"sort"`
+			Limit int    `json:"limit"`
+			Ports bool   `json:"ports"`
+			Host  string `json:"host"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		if params.Sort != "memory" {
+			params.Sort = "cpu"
+		}
+		if params.Limit <= 0 {
+			params.Limit = 15
+		}
+
+		body, err := client.get(ctx, host, fmt.Sprintf("/processes?sort=%s&limit=%d", params.Sort, params.Limit))
+		if err != nil {
+			return "", err
+		}
+
+		var procs []struct {
+			PID     int32   `json:"pid"`
+			Name    string  `json:"name"`
+			User    string  `json:"user"`
+			CPU     float64 `json:"cpu_percent"`
+			Memory  float32 `json:"mem_percent"`
+			RSS     uint64  `json:"rss_bytes"`
+			Command string  `json:"command"`
+		}
+		if err := json.Unmarshal(body, &procs); err != nil {
+			return "", fmt.Errorf("decode response: %w", err)
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("top processes on %s by %s:\n\n", host.Name, params.Sort))
+		for _, p := range procs {
+			sb.WriteString(fmt.Sprintf("  %6d %-16s cpu %5.1f%%  mem %4.1f%% (%.0f MB)  %s\n",
+				p.PID, p.Name, p.CPU, p.Memory, float64(p.RSS)/1e6, p.User))
+			if p.Command != "" && p.Command != p.Name {
+				sb.WriteString(fmt.Sprintf("         %s\n", p.Command))
+			}
+		}
+
+		if !params.Ports {
+			return sb.String(), nil
+		}
+
+		body, err = client.get(ctx, host, "/ports")
+		if err != nil {
+			return "", err
+		}
+
+		var ports []struct {
+			Proto   string `json:"proto"`
+			Address string `json:"address"`
+			Port    uint32 `json:"port"`
+			PID     int32  `json:"pid"`
+			Process string `json:"process"`
+		}
+		if err := json.Unmarshal(body, &ports); err != nil {
+			return "", fmt.Errorf("decode response: %w", err)
+		}
+
+		sb.WriteString(fmt.Sprintf("\nlistening ports on %s:\n\n", host.Name))
+		for _, p := range ports {
+			owner := p.Process
+			if owner == "" {
+				owner = "unknown"
+			}
+			if p.PID > 0 {
+				owner = fmt.Sprintf("%s (%d)", owner, p.PID)
+			}
+			sb.WriteString(fmt.Sprintf("  %-5s %s:%d  %s\n", p.Proto, p.Address, p.Port, owner))
+		}
+		return sb.String(), nil
+	})
+}
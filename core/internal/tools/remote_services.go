@@ -0,0 +1,263 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+type remoteService struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	LoadState   string `json:"load_state"`
+	ActiveState string `json:"active_state"`
+	SubState    string `json:"sub_state"`
+	Running     bool   `json:"running"`
+	MainPID     int    `json:"main_pid"`
+	Since       string `json:"since"`
+	Restarts    int    `json:"restarts"`
+}
+
+func registerListServices(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "list_services",
+		Description: "List the systemd services on the remote host that the homelab-agent is allowed to manage, with their state. For things not running in Docker.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"host": hostParam,
+			},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Host string `json:"host"`
+		}
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		body, err := client.get(ctx, host, "/services")
+		if err != nil {
+			return "", err
+		}
+
+		var services []remoteService
+		if err := json.Unmarshal(body, &services); err != nil {
+			return "", fmt.Errorf("decode response: %w", err)
+		}
+
+		if len(services) == 0 {
+			return fmt.Sprintf("no allowlisted services are loaded on %s", host.Name), nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("services on %s:\n\n", host.Name))
+		for _, s := range services {
+			sb.WriteString(fmt.Sprintf("  %s [%s/%s]\n    %s\n\n", s.Name, s.ActiveState, s.SubState, s.Description))
+		}
+		return sb.String(), nil
+	})
+}
+
+func registerServiceStatus(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "service_status",
+		Description: "Get the status of a systemd service on the remote host.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Service name (e.g., 'nginx' or 'jellyfin.service')",
+				},
+				"host": hostParam,
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Name string `json:"name"`
+			Host string `json:"host"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		body, err := client.get(ctx, host, "/services/"+url.PathEscape(params.Name))
+		if err != nil {
+			return "", err
+		}
+
+		var s remoteService
+		if err := json.Unmarshal(body, &s); err != nil {
+			return "", fmt.Errorf("decode response: %w", err)
+		}
+
+		result := fmt.Sprintf("service %s: %s/%s\n  description: %s\n  loaded: %s", s.Name, s.ActiveState, s.SubState, s.Description, s.LoadState)
+		if s.MainPID > 0 {
+			result += fmt.Sprintf("\n  pid: %d", s.MainPID)
+		}
+		if s.Since != "" {
+			result += fmt.Sprintf("\n  since: %s", s.Since)
+		}
+		if s.Restarts > 0 {
+			result += fmt.Sprintf("\n  restarts: %d", s.Restarts)
+		}
+		return result, nil
+	})
+}
+
+func registerServiceRestart(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "restart_service",
+		Description: "Restart a systemd service on the remote host. Only services in the agent's SERVICES_ALLOWLIST can be restarted.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Service name to restart",
+				},
+				"host": hostParam,
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Name string `json:"name"`
+			Host string `json:"host"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("restarting %s on %s...", params.Name, host.Name))
+
+		reqURL := host.URL + "/services/" + url.PathEscape(params.Name) + "/restart"
+		req, err := http.NewRequestWithContext(ctx, "POST", reqURL, nil)
+		if err != nil {
+			return "", fmt.Errorf("create request: %w", err)
+		}
+
+		resp, err := client.do(req)
+		if err != nil {
+			return "", fmt.Errorf("%s unreachable: %w", host.Name, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return "", fmt.Errorf("restart failed: %s", strings.TrimSpace(string(body)))
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("%s restarted successfully", params.Name))
+		return fmt.Sprintf("service %q restarted successfully", params.Name), nil
+	})
+}
+
+func registerServiceLogs(registry *Registry, client *RemoteClient) {
+	tool := llm.Tool{
+		Name:        "service_logs",
+		Description: "Get the recent journal of a systemd service on the remote host. Useful for debugging services that won't start.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Service name",
+				},
+				"lines": map[string]any{
+					"type":        "integer",
+					"description": "Number of journal lines to retrieve (default: 50)",
+				},
+				"host": hostParam,
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Name  string `json:"name"`
+			Lines int    `json:"lines"`
+			Host  string `json:"host"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		host, err := client.host(params.Host)
+		if err != nil {
+			return "", err
+		}
+
+		if params.Lines == 0 {
+			params.Lines = 50
+		}
+
+		body, err := client.get(ctx, host, fmt.Sprintf("/services/%s/journal?lines=%d", url.PathEscape(params.Name), params.Lines))
+		if err != nil {
+			return "", err
+		}
+
+		logs := string(body)
+		if len(logs) > 4000 {
+			logs = logs[len(logs)-4000:]
+			logs = "... (truncated)\n" + logs
+		}
+
+		return fmt.Sprintf("%s journal (last %d lines):\n\n%s", params.Name, params.Lines, logs), nil
+	})
+}
+
+// get fetches an agent endpoint and returns the body, or an error carrying the agent's message
+func (h *RemoteClient) get(ctx context.Context, host RemoteHost, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", host.URL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := h.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s unreachable: %w", host.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d: %s", host.Name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldonmem"
+)
+
+// ForgetWindow is how long a forgotten fact can be restored before it is purged
+const ForgetWindow = 24 * time.Hour
+
+// reviewPageSize is how many facts review_memory sends at a time
+const reviewPageSize = 5
+
+// ReviewSender sends a fact to the user with forget/keep buttons. ctx carries
+// who asked for the review, which is the only user the buttons work for.
+type ReviewSender func(ctx context.Context, chatID int64, factID int64, text string) error
+
+type ReviewArgs struct {
+	Domain int `json:"domain,omitempty"`
+	Page   int `json:"page,omitempty"`
+}
+
+type FactIDArgs struct {
+	FactID int64 `json:"fact_id"`
+}
+
+// RegisterReviewTools registers review_memory, forget_fact and restore_fact
+func RegisterReviewTools(registry *Registry, memory *sheldonmem.Store, sender ReviewSender) {
+	reviewTool := llm.Tool{
+		Name:        "review_memory",
+		Description: "Walk the user through what you remember about them, one page of facts at a time. Each fact is sent with Forget/Keep buttons. Use when the user wants to review, audit or clean up their memory.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"domain": map[string]any{
+					"type":        "integer",
+					"description": "Optional domain ID to review (1=Identity, 2=Health, 3=Emotions, 4=Beliefs, 5=Skills, 6=Relationships, 7=Work, 8=Finances, 9=Location, 10=Goals, 11=Preferences, 12=Routines, 13=Events, 14=Patterns). Omit to review all.",
+				},
+				"page": map[string]any{
+					"type":        "integer",
+					"description": "Page number, starting at 1. Default: 1.",
+				},
+			},
+		},
+	}
+
+	registry.Register(reviewTool, func(ctx context.Context, args string) (string, error) {
+		var params ReviewArgs
+		if args != "" {
+			if err := json.Unmarshal([]byte(args), &params); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+		if params.Domain < 0 || params.Domain > 14 {
+			return "", fmt.Errorf("invalid domain ID %d: must be between 1 and 14", params.Domain)
+		}
+		page := max(params.Page, 1)
+
+		user, err := memory.FindEntityByName(UserEntityName(ctx))
+		if err != nil {
+			return "I don't have anything in memory about you yet.", nil
+		}
+
+		facts, err := memory.GetOwnedFacts(user.ID, params.Domain)
+		if err != nil {
+			return "", fmt.Errorf("load facts: %w", err)
+		}
+		if SafeModeFromContext(ctx) {
+			facts = withoutSensitive(facts)
+		}
+
+		start := (page - 1) * reviewPageSize
+		if start >= len(facts) {
+			return fmt.Sprintf("Nothing left to review (%d facts in total).", len(facts)), nil
+		}
+		end := min(start+reviewPageSize, len(facts))
+
+		chatID := ChatIDFromContext(ctx)
+		var lines []string
+		for _, f := range facts[start:end] {
+			text := describeFact(memory, f)
+			if sender != nil && chatID != 0 {
+				if err := sender(ctx, chatID, f.ID, text); err != nil {
+					return "", fmt.Errorf("send fact for review: %w", err)
+				}
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("#%d %s", f.ID, text))
+		}
+
+		summary := fmt.Sprintf("Showing facts %d-%d of %d.", start+1, end, len(facts))
+		if end < len(facts) {
+			summary += fmt.Sprintf(" Call review_memory with page %d for more.", page+1)
+		}
+		if len(lines) > 0 {
+			return summary + " Use forget_fact with an ID to forget one.\n\n" + strings.Join(lines, "\n"), nil
+		}
+		return "Sent for review. " + summary, nil
+	})
+
+	forgetTool := llm.Tool{
+		Name:        "forget_fact",
+		Description: fmt.Sprintf("Forget a fact about the user by its ID. Only use when the user asks you to forget something. It can be restored with restore_fact for %s, then it is deleted permanently.", ForgetWindow),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"fact_id": map[string]any{
+					"type":        "integer",
+					"description": "ID of the fact to forget",
+				},
+			},
+			"required": []string{"fact_id"},
+		},
+	}
+
+	registry.Register(forgetTool, func(ctx context.Context, args string) (string, error) {
+		var params FactIDArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		fact, err := ownedFact(ctx, memory, params.FactID)
+		if err != nil {
+			return "", err
+		}
+
+		if err := memory.ForgetFact(fact.ID); err != nil {
+			return "", fmt.Errorf("forget fact: %w", err)
+		}
+
+		return fmt.Sprintf("Forgot #%d (%s). It can be restored with restore_fact for the next %s.", fact.ID, fact.Field, ForgetWindow), nil
+	})
+
+	restoreTool := llm.Tool{
+		Name:        "restore_fact",
+		Description: fmt.Sprintf("Undo forget_fact, restoring a fact forgotten within the last %s.", ForgetWindow),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"fact_id": map[string]any{
+					"type":        "integer",
+					"description": "ID of the forgotten fact",
+				},
+			},
+			"required": []string{"fact_id"},
+		},
+	}
+
+	registry.Register(restoreTool, func(ctx context.Context, args string) (string, error) {
+		var params FactIDArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		fact, err := ownedFact(ctx, memory, params.FactID)
+		if err != nil {
+			return "", err
+		}
+
+		if err := memory.RestoreFact(ctx, fact.ID, ForgetWindow); err != nil {
+			if errors.Is(err, sheldonmem.ErrFactNotFound) {
+				return "", fmt.Errorf("fact #%d wasn't forgotten in the last %s", fact.ID, ForgetWindow)
+			}
+			return "", fmt.Errorf("restore fact: %w", err)
+		}
+
+		return fmt.Sprintf("Restored #%d (%s).", fact.ID, fact.Field), nil
+	})
+
+	registry.Invalidates("forget_fact", "recall_memory")
+	registry.Invalidates("restore_fact", "recall_memory")
+}
+
+// ForgetReviewed forgets a fact from a review button press, if it still
+// belongs to owner, the user entity the review was sent for
+func ForgetReviewed(memory *sheldonmem.Store, owner string, factID int64) error {
+	ctx := context.WithValue(context.Background(), UserEntityKey, owner)
+	fact, err := ownedFact(ctx, memory, factID)
+	if err != nil {
+		return err
+	}
+	return memory.ForgetFact(fact.ID)
+}
+
+// ResolveReviewedConflict applies the answer to a contradiction prompt if
+// both facts still belong to owner, the user entity it was sent for
+func ResolveReviewedConflict(memory *sheldonmem.Store, owner string, conflictID int64, resolution sheldonmem.ConflictResolution) error {
+	conflict, err := memory.GetConflict(conflictID)
+	if err != nil {
+		return err
+	}
+	ctx := context.WithValue(context.Background(), UserEntityKey, owner)
+	for _, f := range []*sheldonmem.Fact{conflict.A, conflict.B} {
+		if _, err := ownedFact(ctx, memory, f.ID); err != nil {
+			return err
+		}
+	}
+	return memory.ResolveConflict(conflictID, resolution)
+}
+
+// ownedFact loads a fact and checks it belongs to the current user
+func ownedFact(ctx context.Context, memory *sheldonmem.Store, factID int64) (*sheldonmem.Fact, error) {
+	fact, err := memory.GetFact(factID)
+	if err != nil {
+		return nil, fmt.Errorf("fact #%d not found", factID)
+	}
+
+	user, err := memory.FindEntityByName(UserEntityName(ctx))
+	if err != nil || fact.EntityID == nil {
+		return nil, fmt.Errorf("fact #%d not found", factID)
+	}
+
+	entity, err := memory.GetEntity(*fact.EntityID)
+	if err != nil || entity.OwnerID == nil || *entity.OwnerID != user.ID {
+		return nil, fmt.Errorf("fact #%d not found", factID)
+	}
+
+	return fact, nil
+}
+
+// describeFact formats a fact for review, naming the entity when it isn't the user
+func describeFact(memory *sheldonmem.Store, f *sheldonmem.Fact) string {
+	subject := ""
+	if f.EntityID != nil {
+		if entity, err := memory.GetEntity(*f.EntityID); err == nil && entity.EntityType != "user" {
+			subject = entity.Name + " - "
+		}
+	}
+
+	domain := ""
+	if d, err := memory.GetDomain(f.DomainID); err == nil {
+		domain = "[" + d.Name + "] "
+	}
+
+	return fmt.Sprintf("%s%s%s: %s", domain, subject, f.Field, f.Value)
+}
+
+func withoutSensitive(facts []*sheldonmem.Fact) []*sheldonmem.Fact {
+	var out []*sheldonmem.Fact
+	for _, f := range facts {
+		if !f.Sensitive {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/deployer"
+	"github.com/bowerhall/sheldon/internal/llm"
+)
+
+type AppDomainArgs struct {
+	App    string `json:"app"`
+	Domain string `json:"domain"`
+}
+
+type AppBasicAuthArgs struct {
+	App      string `json:"app"`
+	Enabled  bool   `json:"enabled"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// RegisterRouteTools registers list_routes, add_app_domain, remove_app_domain and
+// set_app_basic_auth for deploy targets routed through Traefik labels
+func RegisterRouteTools(registry *Registry, routes deployer.RouteManager) {
+	listTool := llm.Tool{
+		Name:        "list_routes",
+		Description: "List how each deployed app is reached: its domains, HTTPS, the container port Traefik forwards to and whether basic auth is on.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(listTool, func(ctx context.Context, args string) (string, error) {
+		all, err := routes.Routes()
+		if err != nil {
+			return "", err
+		}
+
+		if len(all) == 0 {
+			return "No apps deployed yet.", nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString("App routes:\n")
+		for _, r := range all {
+			sb.WriteString("\n" + formatRoute(r))
+		}
+		return sb.String(), nil
+	})
+
+	addTool := llm.Tool{
+		Name:        "add_app_domain",
+		Description: "Serve a deployed app on an extra domain besides name.yourdomain.com, e.g. a custom domain the user owns. The domain's DNS must point at this server; HTTPS certificates are issued automatically.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name",
+				},
+				"domain": map[string]any{
+					"type":        "string",
+					"description": "Full host name, e.g. weather.example.org",
+				},
+			},
+			"required": []string{"app", "domain"},
+		},
+	}
+
+	registry.Register(addTool, func(ctx context.Context, args string) (string, error) {
+		var params AppDomainArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		route, err := routes.AddDomain(ctx, params.App, params.Domain)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("✅ %s now also serves %s. Make sure its DNS points at this server.\n\n%s", params.App, params.Domain, formatRoute(*route)), nil
+	})
+
+	removeTool := llm.Tool{
+		Name:        "remove_app_domain",
+		Description: "Stop serving a deployed app on a custom domain added with add_app_domain.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name",
+				},
+				"domain": map[string]any{
+					"type":        "string",
+					"description": "Custom domain to remove",
+				},
+			},
+			"required": []string{"app", "domain"},
+		},
+	}
+
+	registry.Register(removeTool, func(ctx context.Context, args string) (string, error) {
+		var params AppDomainArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		route, err := routes.RemoveDomain(ctx, params.App, params.Domain)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("✅ %s no longer serves %s.\n\n%s", params.App, params.Domain, formatRoute(*route)), nil
+	})
+
+	authTool := llm.Tool{
+		Name:        "set_app_basic_auth",
+		Description: "Turn password protection (HTTP basic auth) on or off for a deployed app. Turning it on replaces any previous login. Leave password empty to generate one.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name",
+				},
+				"enabled": map[string]any{
+					"type":        "boolean",
+					"description": "true to require a login, false to make the app public again",
+				},
+				"username": map[string]any{
+					"type":        "string",
+					"description": "Login name (required when enabling)",
+				},
+				"password": map[string]any{
+					"type":        "string",
+					"description": "Password, at least 8 characters (default: generated)",
+				},
+			},
+			"required": []string{"app", "enabled"},
+		},
+	}
+
+	registry.Register(authTool, func(ctx context.Context, args string) (string, error) {
+		var params AppBasicAuthArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if !params.Enabled {
+			if _, err := routes.SetBasicAuth(ctx, params.App, nil); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("🔓 Basic auth removed, %s is public again.", params.App), nil
+		}
+
+		if params.Username == "" {
+			return "", fmt.Errorf("username is required to enable basic auth")
+		}
+
+		password, generated := params.Password, false
+		if password == "" {
+			var err error
+			if password, err = generatePassword(); err != nil {
+				return "", err
+			}
+			generated = true
+		}
+
+		entry, err := deployer.HTPasswd(params.Username, password)
+		if err != nil {
+			return "", err
+		}
+		if _, err := routes.SetBasicAuth(ctx, params.App, []string{entry}); err != nil {
+			return "", err
+		}
+
+		output := fmt.Sprintf("🔒 %s now requires a login as %s.", params.App, params.Username)
+		if generated {
+			output += fmt.Sprintf("\nGenerated password: %s\nIt isn't stored in plain text, so pass it on now.", password)
+		}
+		return output, nil
+	})
+}
+
+func formatRoute(r deployer.Route) string {
+	var sb strings.Builder
+	if len(r.Hosts) == 0 {
+		if r.HostPort > 0 {
+			sb.WriteString(fmt.Sprintf("%s: port %d on the server's IP (no Traefik)\n", r.App, r.HostPort))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s: not routed\n", r.App))
+		}
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("%s: %s\n", r.App, r.URL()))
+	if len(r.Hosts) > 1 {
+		sb.WriteString(fmt.Sprintf("  custom domains: %s\n", strings.Join(r.Hosts[1:], ", ")))
+	}
+	if r.TLS {
+		sb.WriteString("  https: yes (letsencrypt)\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("  https: no (entrypoint %s)\n", r.EntryPoint))
+	}
+	if r.Port > 0 {
+		sb.WriteString(fmt.Sprintf("  container port: %d\n", r.Port))
+	}
+	if len(r.AuthUsers) > 0 {
+		sb.WriteString(fmt.Sprintf("  basic auth: on (%s)\n", strings.Join(r.AuthUserNames(), ", ")))
+	} else {
+		sb.WriteString("  basic auth: off\n")
+	}
+	return sb.String()
+}
+
+func generatePassword() (string, error) {
+	buf := make([]byte, 15)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
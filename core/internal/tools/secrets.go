@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/secrets"
+)
+
+type SetAppSecretArgs struct {
+	App   string `json:"app"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type AppSecretArgs struct {
+	App  string `json:"app"`
+	Name string `json:"name,omitempty"`
+}
+
+// RegisterSecretTools registers set_app_secret, delete_app_secret and list_app_secrets.
+// Values are injected by deploy_app and never returned.
+func RegisterSecretTools(registry *Registry, store *secrets.Store) {
+	setTool := llm.Tool{
+		Name:        "set_app_secret",
+		Description: "Store a secret (API key, token, password) for a deployed app. It's encrypted at rest and passed to the app as an environment variable on its next deploy_app. Never write secrets into code or repos; use this instead.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name as used with deploy_app",
+				},
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Environment variable name, e.g. OPENWEATHER_API_KEY",
+				},
+				"value": map[string]any{
+					"type":        "string",
+					"description": "The secret value",
+				},
+			},
+			"required": []string{"app", "name", "value"},
+		},
+	}
+
+	registry.Register(setTool, func(ctx context.Context, args string) (string, error) {
+		var params SetAppSecretArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if err := store.Set(params.App, params.Name, params.Value); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Secret %s saved for %s. Redeploy %s with deploy_app to apply it.", params.Name, params.App, params.App), nil
+	})
+
+	deleteTool := llm.Tool{
+		Name:        "delete_app_secret",
+		Description: "Remove a secret from a deployed app. Takes effect on the app's next deploy_app.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name",
+				},
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Environment variable name to remove",
+				},
+			},
+			"required": []string{"app", "name"},
+		},
+	}
+
+	registry.Register(deleteTool, func(ctx context.Context, args string) (string, error) {
+		var params AppSecretArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		if err := store.Delete(params.App, params.Name); err != nil {
+			if errors.Is(err, secrets.ErrNotFound) {
+				return fmt.Sprintf("%s has no secret named %s", params.App, params.Name), nil
+			}
+			return "", err
+		}
+
+		return fmt.Sprintf("Secret %s removed from %s. Redeploy to apply.", params.Name, params.App), nil
+	})
+
+	listTool := llm.Tool{
+		Name:        "list_app_secrets",
+		Description: "List the names of an app's secrets. Values are never shown.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"app": map[string]any{
+					"type":        "string",
+					"description": "App name",
+				},
+			},
+			"required": []string{"app"},
+		},
+	}
+
+	registry.Register(listTool, func(ctx context.Context, args string) (string, error) {
+		var params AppSecretArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		names := store.Names(params.App)
+		if len(names) == 0 {
+			return fmt.Sprintf("%s has no secrets.", params.App), nil
+		}
+
+		return fmt.Sprintf("Secrets for %s:\n- %s", params.App, strings.Join(names, "\n- ")), nil
+	})
+}
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SkillArgument is one parameter a skill declares in its frontmatter, passed
+// as /apartment-hunter city=Berlin budget=1500
+type SkillArgument struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"` // string (default), number, integer or boolean
+	Description string   `yaml:"description"`
+	Required    bool     `yaml:"required"`
+	Default     string   `yaml:"default"`
+	Enum        []string `yaml:"enum"`
+}
+
+// Usage shows how to invoke a skill with its arguments, e.g.
+// "/apartment-hunter city=<string> [budget=<number>]"
+func (meta SkillMeta) Usage(name string) string {
+	parts := []string{"/" + name}
+	for _, arg := range meta.Arguments {
+		typ := arg.Type
+		if typ == "" {
+			typ = "string"
+		}
+		if len(arg.Enum) > 0 {
+			typ = strings.Join(arg.Enum, "|")
+		}
+		part := fmt.Sprintf("%s=<%s>", arg.Name, typ)
+		if !arg.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// skillUsage is the usage line list_skills shows, empty for skills without
+// arguments
+func skillUsage(name, content string) string {
+	meta := ParseSkillMeta(content)
+	if len(meta.Arguments) == 0 {
+		return ""
+	}
+	return meta.Usage(strings.ToLower(name))
+}
+
+// SplitSkillArgs separates key=value pairs from the free text after a skill
+// command. Values can be quoted: city="New York".
+func SplitSkillArgs(text string) (map[string]string, string) {
+	args := make(map[string]string)
+	var free []string
+
+	for _, token := range splitQuoted(text) {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \"'") {
+			free = append(free, token)
+			continue
+		}
+		args[key] = strings.Trim(value, `"'`)
+	}
+	return args, strings.Join(free, " ")
+}
+
+// splitQuoted splits on spaces outside of quotes
+func splitQuoted(text string) []string {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+
+	for _, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n':
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// BindArguments checks raw values against the declared arguments, fills in
+// defaults and converts them to their types. Every problem is reported at
+// once so the user can fix them together.
+func (meta SkillMeta) BindArguments(raw map[string]string) (map[string]any, error) {
+	bound := make(map[string]any)
+	var problems []string
+
+	for key := range raw {
+		if !slices.ContainsFunc(meta.Arguments, func(a SkillArgument) bool { return a.Name == key }) {
+			problems = append(problems, fmt.Sprintf("unknown argument %q", key))
+		}
+	}
+
+	for _, arg := range meta.Arguments {
+		value, ok := raw[arg.Name]
+		if !ok {
+			if arg.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", arg.Name))
+				continue
+			}
+			if arg.Default == "" {
+				continue
+			}
+			value = arg.Default
+		}
+
+		if len(arg.Enum) > 0 && !slices.Contains(arg.Enum, value) {
+			problems = append(problems, fmt.Sprintf("%s must be one of %s", arg.Name, strings.Join(arg.Enum, ", ")))
+			continue
+		}
+
+		v, err := convertArgument(arg.Type, value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", arg.Name, err))
+			continue
+		}
+		bound[arg.Name] = v
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return bound, nil
+}
+
+func convertArgument(typ, value string) (any, error) {
+	switch typ {
+	case "", "string":
+		return value, nil
+	case "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", value)
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", value)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", typ)
+	}
+}
+
+// FormatArguments lists bound arguments for the model in declaration
+// order
+func (meta SkillMeta) FormatArguments(bound map[string]any) string {
+	var sb strings.Builder
+	sb.WriteString("Arguments:\n")
+	for _, arg := range meta.Arguments {
+		if v, ok := bound[arg.Name]; ok {
+			fmt.Fprintf(&sb, "- %s: %v\n", arg.Name, v)
+		}
+	}
+	return sb.String()
+}
+
+// ActivateSkill prepares a skill's content for the model with the arguments
+// it was called with checked against its frontmatter. Invalid arguments are
+// spelled out so the model asks the user instead of guessing.
+func ActivateSkill(name, content string, raw map[string]string) string {
+	meta := ParseSkillMeta(content)
+	if len(meta.Arguments) == 0 {
+		return content
+	}
+
+	bound, err := meta.BindArguments(raw)
+	if err != nil {
+		return fmt.Sprintf("%s\n\n[Invalid arguments: %s. Usage: %s. Ask the user for what's missing or wrong before following the skill.]", content, err, meta.Usage(name))
+	}
+	if len(bound) == 0 {
+		return content
+	}
+	return content + "\n\n" + meta.FormatArguments(bound)
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+const apartmentSkill = `---
+name: apartment-hunter
+description: Find apartments to rent
+arguments:
+  - name: city
+    required: true
+  - name: budget
+    type: number
+    default: 1500
+  - name: furnished
+    type: boolean
+  - name: size
+    enum: [studio, 1br, 2br]
+---
+# Apartment Hunter
+`
+
+func TestSplitSkillArgs(t *testing.T) {
+	args, free := SplitSkillArgs(`city="New York" budget=2000 near the park`)
+	if args["city"] != "New York" || args["budget"] != "2000" || len(args) != 2 {
+		t.Errorf("args = %v", args)
+	}
+	if free != "near the park" {
+		t.Errorf("free = %q, want %q", free, "near the park")
+	}
+}
+
+func TestBindArguments(t *testing.T) {
+	meta := ParseSkillMeta(apartmentSkill)
+	if len(meta.Arguments) != 4 {
+		t.Fatalf("arguments = %+v, want 4", meta.Arguments)
+	}
+
+	bound, err := meta.BindArguments(map[string]string{"city": "Berlin", "furnished": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound["city"] != "Berlin" || bound["budget"] != 1500.0 || bound["furnished"] != true {
+		t.Errorf("bound = %v", bound)
+	}
+	if _, ok := bound["size"]; ok {
+		t.Errorf("size bound without a value or default")
+	}
+
+	_, err = meta.BindArguments(map[string]string{"budget": "cheap", "size": "castle", "pets": "yes"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"city is required", `"cheap" is not a number`, "size must be one of", `unknown argument "pets"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+
+	if got, want := meta.Usage("apartment-hunter"), "/apartment-hunter city=<string> [budget=<number>] [furnished=<boolean>] [size=<studio|1br|2br>]"; got != want {
+		t.Errorf("usage = %q, want %q", got, want)
+	}
+}
+
+func TestActivateSkill(t *testing.T) {
+	out := ActivateSkill("apartment-hunter", apartmentSkill, map[string]string{"city": "Berlin"})
+	if !strings.Contains(out, "- city: Berlin\n- budget: 1500\n") {
+		t.Errorf("missing bound arguments:\n%s", out)
+	}
+
+	out = ActivateSkill("apartment-hunter", apartmentSkill, nil)
+	if !strings.Contains(out, "[Invalid arguments: city is required. Usage: /apartment-hunter") {
+		t.Errorf("missing usage note:\n%s", out)
+	}
+
+	plain := "# Weather\nForecasts"
+	if out := ActivateSkill("weather", plain, map[string]string{"city": "Berlin"}); out != plain {
+		t.Errorf("skill without arguments changed: %q", out)
+	}
+}
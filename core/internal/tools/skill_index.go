@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// indexCacheTTL is how long fetched skills indexes are searched before
+// they're downloaded again
+const indexCacheTTL = time.Hour
+
+const maxIndexBytes = 5 << 20
+
+// SkillIndexEntry is one community skill listed in a skills index
+type SkillIndexEntry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"` // what install_skill installs from
+	Tags        []string `json:"tags,omitempty"`
+	Author      string   `json:"author,omitempty"`
+}
+
+// SetIndexes sets the skills index files search_skills looks in
+func (m *SkillsManager) SetIndexes(urls []string) {
+	m.indexMu.Lock()
+	defer m.indexMu.Unlock()
+
+	m.indexURLs = urls
+	m.indexEntries = nil
+	m.indexFetchedAt = time.Time{}
+}
+
+// indexedSkills returns every skill in the configured indexes, fetching them
+// again once the cache is stale. A failing index is skipped as long as
+// another one loads.
+func (m *SkillsManager) indexedSkills(ctx context.Context) ([]SkillIndexEntry, error) {
+	m.indexMu.Lock()
+	defer m.indexMu.Unlock()
+
+	if len(m.indexURLs) == 0 {
+		return nil, fmt.Errorf("no skills index configured (set SKILLS_INDEX_URLS)")
+	}
+	if m.indexEntries != nil && time.Since(m.indexFetchedAt) < indexCacheTTL {
+		return m.indexEntries, nil
+	}
+
+	var entries []SkillIndexEntry
+	var errs []error
+	loaded := 0
+	for _, indexURL := range m.indexURLs {
+		skills, err := fetchSkillIndex(ctx, indexURL)
+		if err != nil {
+			logger.Warn("failed to fetch skills index", "url", indexURL, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", indexURL, err))
+			continue
+		}
+		loaded++
+		for _, s := range skills {
+			// earlier indexes win when two list the same name
+			if s.Name == "" || s.URL == "" || slices.ContainsFunc(entries, func(e SkillIndexEntry) bool { return strings.EqualFold(e.Name, s.Name) }) {
+				continue
+			}
+			entries = append(entries, s)
+		}
+	}
+	if loaded == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	m.indexEntries = entries
+	m.indexFetchedAt = time.Now()
+	return entries, nil
+}
+
+func fetchSkillIndex(ctx context.Context, indexURL string) ([]SkillIndexEntry, error) {
+	parsed, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "https" {
+		return nil, fmt.Errorf("skills index must be served over https")
+	}
+	if err := validateExternalURL(indexURL); err != nil {
+		return nil, fmt.Errorf("URL blocked: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch failed: %s", resp.Status)
+	}
+
+	var index struct {
+		Skills []SkillIndexEntry `json:"skills"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexBytes)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("parse index: %w", err)
+	}
+	return index.Skills, nil
+}
+
+// SearchIndex finds skills in the indexes matching any word of query, best
+// first. An empty query lists everything.
+func (m *SkillsManager) SearchIndex(ctx context.Context, query string, limit int) ([]SkillIndexEntry, error) {
+	entries, err := m.indexedSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rankSkills(entries, query, limit), nil
+}
+
+// FindInIndex looks up a skill in the indexes by exact name
+func (m *SkillsManager) FindInIndex(ctx context.Context, name string) (*SkillIndexEntry, error) {
+	entries, err := m.indexedSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if strings.EqualFold(entries[i].Name, name) {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("skill '%s' is not in any skills index", name)
+}
+
+func rankSkills(entries []SkillIndexEntry, query string, limit int) []SkillIndexEntry {
+	terms := strings.Fields(strings.ToLower(query))
+
+	type scored struct {
+		entry SkillIndexEntry
+		score int
+	}
+	var matches []scored
+	for _, e := range entries {
+		name := strings.ToLower(e.Name)
+		desc := strings.ToLower(e.Description)
+
+		score := 0
+		if len(terms) == 0 {
+			score = 1
+		}
+		for _, term := range terms {
+			switch {
+			case name == term:
+				score += 10
+			case strings.Contains(name, term):
+				score += 5
+			}
+			if slices.ContainsFunc(e.Tags, func(tag string) bool { return strings.EqualFold(tag, term) }) {
+				score += 3
+			}
+			if strings.Contains(desc, term) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{e, score})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b scored) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return cmp.Compare(a.entry.Name, b.entry.Name)
+	})
+
+	var results []SkillIndexEntry
+	for _, m := range matches {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, m.entry)
+	}
+	return results
+}
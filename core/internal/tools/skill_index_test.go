@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRankSkills(t *testing.T) {
+	entries := []SkillIndexEntry{
+		{Name: "apartment-hunter", Description: "Find flats and write applications", Tags: []string{"housing"}},
+		{Name: "pdf-tools", Description: "Merge and split PDF files"},
+		{Name: "budget", Description: "Track spending against a monthly budget", Tags: []string{"finances"}},
+		{Name: "flat-share", Description: "Split household costs with flatmates", Tags: []string{"housing", "finances"}},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"housing", []string{"apartment-hunter", "flat-share"}},
+		{"budget", []string{"budget"}},
+		{"pdf split", []string{"pdf-tools", "flat-share"}},
+		{"", []string{"apartment-hunter", "budget"}},
+		{"astrology", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, e := range rankSkills(entries, tt.query, 2) {
+			got = append(got, e.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("rankSkills(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
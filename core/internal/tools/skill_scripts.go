@@ -0,0 +1,278 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/config"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/bowerhall/sheldon/internal/wasm"
+)
+
+// scriptManifest is the tools.json a multi-file skill ships next to its
+// SKILL.md to declare WASM tool scripts
+const scriptManifest = "tools.json"
+
+// maxScriptTimeout caps the timeout a skill can ask for in its manifest
+const maxScriptTimeout = time.Minute
+
+// SkillScript is one tool script declared in a skill's tools.json
+type SkillScript struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Module       string          `json:"module"` // .wasm path relative to the skill directory
+	Parameters   json.RawMessage `json:"parameters,omitempty"`
+	Capabilities []string        `json:"capabilities,omitempty"`
+	Timeout      string          `json:"timeout,omitempty"`
+}
+
+// Scripts returns the tool scripts a skill declares, none for skills without
+// a manifest
+func (m *SkillsManager) Scripts(skill string) ([]SkillScript, error) {
+	if err := validateSkillName(skill); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.skillsDir, strings.ToLower(skill), scriptManifest))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", scriptManifest, err)
+	}
+
+	var manifest struct {
+		Tools []SkillScript `json:"tools"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", scriptManifest, err)
+	}
+	for _, s := range manifest.Tools {
+		for _, c := range s.Capabilities {
+			if !slices.Contains(wasm.Capabilities, wasm.Capability(c)) {
+				return nil, fmt.Errorf("script %s asks for unknown capability %q", s.Name, c)
+			}
+		}
+	}
+	return manifest.Tools, nil
+}
+
+// Script looks up one of a skill's tool scripts by name
+func (m *SkillsManager) Script(skill, name string) (*SkillScript, error) {
+	scripts, err := m.Scripts(skill)
+	if err != nil {
+		return nil, err
+	}
+	for i := range scripts {
+		if scripts[i].Name == name {
+			return &scripts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("skill '%s' has no script named '%s'", skill, name)
+}
+
+// loadModule reads a script's .wasm file, which must stay inside the skill
+func (m *SkillsManager) loadModule(skill string, s *SkillScript) ([]byte, error) {
+	dir := filepath.Join(m.skillsDir, strings.ToLower(skill))
+	path := filepath.Join(dir, s.Module)
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path traversal not allowed")
+	}
+	if filepath.Ext(path) != ".wasm" {
+		return nil, fmt.Errorf("script module must be a .wasm file")
+	}
+	return os.ReadFile(path)
+}
+
+// scriptDataDir is where a skill's scripts keep files when granted storage
+func (m *SkillsManager) scriptDataDir(skill string) string {
+	return filepath.Join(m.skillsDir, strings.ToLower(skill), "data")
+}
+
+// describeScripts lists a skill's scripts for the model after use_skill
+func describeScripts(scripts []SkillScript) string {
+	var sb strings.Builder
+	sb.WriteString("This skill has tool scripts. Run them with run_skill_script:\n")
+	for _, s := range scripts {
+		fmt.Fprintf(&sb, "- %s: %s\n", s.Name, s.Description)
+		if len(s.Parameters) > 0 {
+			fmt.Fprintf(&sb, "  input: %s\n", s.Parameters)
+		}
+		if len(s.Capabilities) > 0 {
+			fmt.Fprintf(&sb, "  needs: %s\n", strings.Join(s.Capabilities, ", "))
+		}
+	}
+	return sb.String()
+}
+
+// NewScriptRuntime starts the sandbox skill scripts run in. Their requests get
+// the same SSRF checks as the fetch tools.
+func NewScriptRuntime(ctx context.Context) (*wasm.Runtime, error) {
+	return wasm.NewRuntime(ctx, validateExternalURL)
+}
+
+// RegisterSkillScriptTools registers run_skill_script, which runs a skill's
+// WASM tool scripts in a sandbox, and grant_skill_access, which lets the user
+// give a skill's scripts network or storage access
+func RegisterSkillScriptTools(registry *Registry, manager *SkillsManager, runtime *wasm.Runtime, rc *config.RuntimeConfig) {
+	runTool := llm.Tool{
+		Name:        "run_skill_script",
+		Description: "Run a tool script shipped with a skill. Scripts run sandboxed and can only reach the network or keep files if the user granted that skill access. use_skill lists a skill's scripts and their input.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"skill": map[string]any{
+					"type":        "string",
+					"description": "Name of the skill",
+				},
+				"script": map[string]any{
+					"type":        "string",
+					"description": "Name of the script in the skill",
+				},
+				"input": map[string]any{
+					"type":        "object",
+					"description": "Input for the script, matching what use_skill listed for it",
+				},
+			},
+			"required": []string{"skill", "script"},
+		},
+	}
+
+	registry.Register(runTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Skill  string          `json:"skill"`
+			Script string          `json:"script"`
+			Input  json.RawMessage `json:"input"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		script, err := manager.Script(params.Skill, params.Script)
+		if err != nil {
+			return "", err
+		}
+
+		granted := rc.SkillGrants(strings.ToLower(params.Skill))
+		var grants []wasm.Capability
+		for _, c := range script.Capabilities {
+			if !slices.Contains(granted, c) {
+				return fmt.Sprintf("Script %s needs %s access, which the user hasn't granted skill %s. Ask the user, then call grant_skill_access.", script.Name, c, params.Skill), nil
+			}
+			grants = append(grants, wasm.Capability(c))
+		}
+
+		module, err := manager.loadModule(params.Skill, script)
+		if err != nil {
+			return "", fmt.Errorf("load script: %w", err)
+		}
+
+		timeout, _ := time.ParseDuration(script.Timeout)
+		if timeout > maxScriptTimeout {
+			timeout = maxScriptTimeout
+		}
+
+		input := string(params.Input)
+		if input == "" {
+			input = "{}"
+		}
+
+		logger.Info("running skill script", "skill", params.Skill, "script", script.Name, "grants", grants)
+		out, err := runtime.Run(ctx, wasm.Script{
+			Name:    script.Name,
+			Module:  module,
+			Input:   input,
+			Grants:  grants,
+			DataDir: manager.scriptDataDir(params.Skill),
+			Timeout: timeout,
+		})
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(out) == "" {
+			return "Script finished with no output.", nil
+		}
+		return out, nil
+	})
+
+	// a new or removed version of a skill starts without grants
+	manager.OnChange(func(name string) {
+		if len(rc.SkillGrants(name)) == 0 {
+			return
+		}
+		if err := rc.SetSkillGrants(name, nil); err != nil {
+			logger.Warn("failed to revoke skill grants", "skill", name, "error", err)
+		}
+	})
+
+	grantTool := llm.Tool{
+		Name:        "grant_skill_access",
+		Description: "Grant or revoke the capabilities a skill's scripts run with: network (HTTP requests) and storage (files kept between runs). Replaces the skill's current grants; pass an empty list to revoke all. Only capabilities the skill's scripts ask for can be granted. Requires the user's approval.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"skill": map[string]any{
+					"type":        "string",
+					"description": "Name of the skill",
+				},
+				"capabilities": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string", "enum": []string{string(wasm.CapNetwork), string(wasm.CapStorage)}},
+					"description": "Capabilities to grant",
+				},
+			},
+			"required": []string{"skill", "capabilities"},
+		},
+	}
+
+	registry.Register(grantTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Skill        string   `json:"skill"`
+			Capabilities []string `json:"capabilities"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		scripts, err := manager.Scripts(params.Skill)
+		if err != nil {
+			return "", err
+		}
+		if len(scripts) == 0 {
+			return "", fmt.Errorf("skill '%s' has no tool scripts", params.Skill)
+		}
+
+		var requested []string
+		for _, s := range scripts {
+			requested = append(requested, s.Capabilities...)
+		}
+		var caps []string
+		for _, c := range params.Capabilities {
+			if !slices.Contains(requested, c) {
+				return "", fmt.Errorf("no script in skill '%s' asks for %s", params.Skill, c)
+			}
+			if !slices.Contains(caps, c) {
+				caps = append(caps, c)
+			}
+		}
+
+		if err := rc.SetSkillGrants(strings.ToLower(params.Skill), caps); err != nil {
+			return "", fmt.Errorf("failed to save: %w", err)
+		}
+		logger.Info("skill grants changed", "skill", params.Skill, "capabilities", caps)
+
+		if len(caps) == 0 {
+			return fmt.Sprintf("🔒 skill %s's scripts now run without network or storage", params.Skill), nil
+		}
+		return fmt.Sprintf("🔓 skill %s's scripts may now use: %s", params.Skill, strings.Join(caps, ", ")), nil
+	})
+}
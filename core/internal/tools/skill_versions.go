@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+// skillSourcesFile records where each installed skill came from. The leading
+// dot keeps List from taking it for a skill.
+const skillSourcesFile = ".sources.json"
+
+// updateCheckInterval is how long a remote version check is trusted before
+// list_skills asks again
+const updateCheckInterval = 6 * time.Hour
+
+// SkillSource is where an installed skill came from and which version it is
+type SkillSource struct {
+	URL         string    `json:"url"`
+	Version     string    `json:"version,omitempty"` // commit SHA on GitHub, else ETag or Last-Modified
+	InstalledAt time.Time `json:"installed_at"`
+	Latest      string    `json:"latest,omitempty"` // remote version at the last check
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// UpdateAvailable reports whether the last check found a newer version
+func (s SkillSource) UpdateAvailable() bool {
+	return s.Latest != "" && s.Latest != s.Version
+}
+
+// shortVersion trims commit SHAs the way git does
+func shortVersion(v string) string {
+	if len(v) == 40 && !strings.ContainsAny(v, `"/ `) {
+		return v[:7]
+	}
+	return v
+}
+
+func (m *SkillsManager) loadSources() (map[string]SkillSource, error) {
+	sources := make(map[string]SkillSource)
+	data, err := os.ReadFile(filepath.Join(m.skillsDir, skillSourcesFile))
+	if os.IsNotExist(err) {
+		return sources, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", skillSourcesFile, err)
+	}
+	return sources, nil
+}
+
+func (m *SkillsManager) saveSources(sources map[string]SkillSource) error {
+	data, err := json.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.skillsDir, skillSourcesFile), data, 0644)
+}
+
+// Sources returns every skill installed from a URL, by lowercase name
+func (m *SkillsManager) Sources() (map[string]SkillSource, error) {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	return m.loadSources()
+}
+
+func (m *SkillsManager) setSource(name string, src *SkillSource) {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+
+	sources, err := m.loadSources()
+	if err != nil {
+		logger.Warn("failed to load skill sources", "error", err)
+		return
+	}
+	if src == nil {
+		if _, ok := sources[strings.ToLower(name)]; !ok {
+			return
+		}
+		delete(sources, strings.ToLower(name))
+	} else {
+		sources[strings.ToLower(name)] = *src
+	}
+	if err := m.saveSources(sources); err != nil {
+		logger.Warn("failed to save skill sources", "error", err)
+	}
+}
+
+// forgetSource detaches a skill from where it was installed from, e.g. once
+// it's edited locally and an update would overwrite the edits
+func (m *SkillsManager) forgetSource(name string) {
+	m.setSource(name, nil)
+}
+
+// CheckUpdates refreshes the remote version of every skill not checked within
+// updateCheckInterval and returns the sources
+func (m *SkillsManager) CheckUpdates(ctx context.Context) (map[string]SkillSource, error) {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+
+	sources, err := m.loadSources()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for name, src := range sources {
+		if time.Since(src.CheckedAt) < updateCheckInterval {
+			continue
+		}
+		latest, err := remoteVersion(ctx, src.URL)
+		if err != nil {
+			logger.Warn("skill update check failed", "skill", name, "error", err)
+			continue
+		}
+		src.Latest = latest
+		src.CheckedAt = time.Now()
+		sources[name] = src
+		changed = true
+	}
+
+	if changed {
+		if err := m.saveSources(sources); err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+// Update reinstalls a skill from its source if a newer version is out. The
+// installed copy is kept until the new one is in place, and script data
+// carries over.
+func (m *SkillsManager) Update(ctx context.Context, name string) (SkillSource, bool, error) {
+	if err := validateSkillName(name); err != nil {
+		return SkillSource{}, false, err
+	}
+	name = strings.ToLower(name)
+
+	sources, err := m.Sources()
+	if err != nil {
+		return SkillSource{}, false, err
+	}
+	src, ok := sources[name]
+	if !ok {
+		return SkillSource{}, false, fmt.Errorf("skill '%s' wasn't installed from a URL", name)
+	}
+
+	latest, err := remoteVersion(ctx, src.URL)
+	if err != nil {
+		return src, false, fmt.Errorf("check for updates: %w", err)
+	}
+	if latest != "" && latest == src.Version {
+		src.Latest = latest
+		src.CheckedAt = time.Now()
+		m.setSource(name, &src)
+		return src, false, nil
+	}
+
+	dir := filepath.Join(m.skillsDir, name)
+	backup := filepath.Join(m.skillsDir, "."+name+".old")
+	info, err := os.Stat(dir)
+	isDir := err == nil && info.IsDir()
+	if isDir {
+		os.RemoveAll(backup)
+		if err := os.Rename(dir, backup); err != nil {
+			return src, false, fmt.Errorf("back up skill: %w", err)
+		}
+	}
+
+	if _, _, err := m.InstallFromURL(ctx, src.URL, name); err != nil {
+		if isDir {
+			os.RemoveAll(dir)
+			os.Rename(backup, dir)
+		}
+		return src, false, err
+	}
+
+	if isDir {
+		if err := os.Rename(filepath.Join(backup, "data"), m.scriptDataDir(name)); err != nil && !os.IsNotExist(err) {
+			logger.Warn("failed to keep skill script data", "skill", name, "error", err)
+		}
+		os.RemoveAll(backup)
+	}
+
+	sources, err = m.Sources()
+	if err != nil {
+		return src, true, err
+	}
+	return sources[name], true, nil
+}
+
+// remoteVersion asks where a skill came from for its current version: the
+// last commit touching its path on GitHub, else the ETag or Last-Modified
+func remoteVersion(ctx context.Context, rawURL string) (string, error) {
+	if gh, ok := parseGitHubURL(rawURL); ok {
+		apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits?sha=%s&path=%s&per_page=1",
+			gh.Owner, gh.Repo, url.QueryEscape(gh.Branch), url.QueryEscape(gh.Path))
+
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("GitHub API error: %s", resp.Status)
+		}
+
+		var commits []struct {
+			SHA string `json:"sha"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&commits); err != nil {
+			return "", err
+		}
+		if len(commits) == 0 {
+			return "", fmt.Errorf("no commits found for %s", gh.Path)
+		}
+		return commits[0].SHA, nil
+	}
+
+	if err := validateExternalURL(rawURL); err != nil {
+		return "", fmt.Errorf("URL blocked: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("version check failed: %s", resp.Status)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return etag, nil
+	}
+	return resp.Header.Get("Last-Modified"), nil
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSkillSources(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewSkillsManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.Save("weather", "# Weather\nForecasts for the user's city"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".transit.old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manager.setSource("Weather", &SkillSource{URL: "https://example.com/weather.md", Version: `"v1"`, Latest: `"v2"`, CheckedAt: time.Now()})
+
+	skills, err := manager.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 1 || skills[0].Name != "WEATHER" {
+		t.Fatalf("skills = %+v, want only WEATHER", skills)
+	}
+
+	sources, err := manager.Sources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, ok := sources["weather"]
+	if !ok || !src.UpdateAvailable() {
+		t.Errorf("weather source = %+v, %v, want an available update", src, ok)
+	}
+
+	if err := manager.Remove("weather"); err != nil {
+		t.Fatal(err)
+	}
+	sources, err = manager.Sources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 0 {
+		t.Errorf("sources after remove = %v, want none", sources)
+	}
+}
+
+func TestShortVersion(t *testing.T) {
+	tests := map[string]string{
+		"3f786850e387550fdab836ed7e6dc881de23001b":   "3f78685",
+		`"33a64df551425fcc55e4d42a148795d9f25f89d4"`: `"33a64df551425fcc55e4d42a148795d9f25f89d4"`,
+		"Wed, 21 Oct 2015 07:28:00 GMT":              "Wed, 21 Oct 2015 07:28:00 GMT",
+	}
+	for in, want := range tests {
+		if got := shortVersion(in); got != want {
+			t.Errorf("shortVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
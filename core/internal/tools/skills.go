@@ -10,12 +10,26 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/logger"
+	"gopkg.in/yaml.v3"
 )
 
+const maxSkillSearchResults = 10
+
 type SkillsManager struct {
 	skillsDir string
+	changed   []func(name string) // called after a skill is installed, saved or removed
+
+	sourcesMu sync.Mutex // guards the sources file
+
+	indexMu        sync.Mutex
+	indexURLs      []string // skills index files from SKILLS_INDEX_URLS
+	indexEntries   []SkillIndexEntry
+	indexFetchedAt time.Time
 }
 
 // validateSkillName checks if a skill name is safe (no path traversal)
@@ -61,6 +75,10 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 					"type":        "string",
 					"description": "Name of the skill to activate",
 				},
+				"arguments": map[string]any{
+					"type":        "object",
+					"description": "Arguments for skills that declare them (list_skills shows usage), e.g. {\"city\": \"Berlin\", \"budget\": 1500}",
+				},
 			},
 			"required": []string{"name"},
 		},
@@ -68,7 +86,8 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 
 	registry.Register(useTool, func(ctx context.Context, args string) (string, error) {
 		var params struct {
-			Name string `json:"name"`
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
 		}
 		if err := json.Unmarshal([]byte(args), &params); err != nil {
 			return "", fmt.Errorf("invalid arguments: %w", err)
@@ -79,6 +98,23 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 			return "", fmt.Errorf("skill not found: %s", params.Name)
 		}
 
+		raw := make(map[string]string, len(params.Arguments))
+		for k, v := range params.Arguments {
+			raw[k] = fmt.Sprint(v)
+		}
+		content = ActivateSkill(params.Name, content, raw)
+
+		if allowed := SkillToolAllowlist(content); allowed != nil && RestrictTools(ctx, allowed) {
+			logger.Info("tools restricted by skill", "skill", params.Name, "tools", allowed)
+			content += "\n\nFor the rest of this turn only these tools are available: " + strings.Join(allowed, ", ")
+		}
+
+		if scripts, err := manager.Scripts(params.Name); err != nil {
+			content += "\n\n(tool scripts unavailable: " + err.Error() + ")"
+		} else if len(scripts) > 0 {
+			content += "\n\n" + describeScripts(scripts)
+		}
+
 		return fmt.Sprintf("=== SKILL ACTIVATED: %s ===\n\n%s\n\n=== END SKILL ===\n\nFollow the instructions above to complete the task.", params.Name, content), nil
 	})
 
@@ -121,20 +157,19 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 
 	installTool := llm.Tool{
 		Name:        "install_skill",
-		Description: "Install a skill from a URL. Supports single .md files or GitHub directories containing multiple files. GitHub directories are installed as skill folders with all subfiles preserved.",
+		Description: "Install a skill from a URL, or by name from the skills index (see search_skills). Supports single .md files or GitHub directories containing multiple files. GitHub directories are installed as skill folders with all subfiles preserved.",
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
 				"url": map[string]any{
 					"type":        "string",
-					"description": "URL to the skill. Can be: GitHub directory (github.com/owner/repo/tree/branch/path), GitHub file (github.com/owner/repo/blob/branch/path.md), or raw URL to .md file",
+					"description": "URL to the skill. Can be: GitHub directory (github.com/owner/repo/tree/branch/path), GitHub file (github.com/owner/repo/blob/branch/path.md), or raw URL to .md file. Leave empty to install the skills index entry called name.",
 				},
 				"name": map[string]any{
 					"type":        "string",
 					"description": "Name for the skill. If not provided, extracted from URL.",
 				},
 			},
-			"required": []string{"url"},
 		},
 	}
 
@@ -147,6 +182,17 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
+		if params.URL == "" {
+			if params.Name == "" {
+				return "", fmt.Errorf("url or name is required")
+			}
+			entry, err := manager.FindInIndex(ctx, params.Name)
+			if err != nil {
+				return "", err
+			}
+			params.URL = entry.URL
+		}
+
 		name := params.Name
 		if name == "" {
 			name = extractSkillName(params.URL)
@@ -168,6 +214,58 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 		return fmt.Sprintf("Skill installed: %s\nPath: %s", name, path), nil
 	})
 
+	searchTool := llm.Tool{
+		Name:        "search_skills",
+		Description: "Search the community skills index for skills to install, by name, description or tag. Install a result with install_skill using its name.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "What the skill should do, e.g. 'apartment hunting' or 'pdf'. Empty lists everything.",
+				},
+			},
+		},
+	}
+
+	registry.Register(searchTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		results, err := manager.SearchIndex(ctx, params.Query, maxSkillSearchResults)
+		if err != nil {
+			return "", err
+		}
+		if len(results) == 0 {
+			return fmt.Sprintf("No skills found for '%s'.", params.Query), nil
+		}
+
+		installed := make(map[string]bool)
+		if skills, err := manager.List(); err == nil {
+			for _, skill := range skills {
+				installed[strings.ToLower(skill.Name)] = true
+			}
+		}
+
+		var sb strings.Builder
+		sb.WriteString("Skills in the index:\n")
+		for _, r := range results {
+			fmt.Fprintf(&sb, "- %s: %s", r.Name, r.Description)
+			if r.Author != "" {
+				fmt.Fprintf(&sb, " (by %s)", r.Author)
+			}
+			if installed[strings.ToLower(r.Name)] {
+				sb.WriteString(" [installed]")
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+	})
+
 	listTool := llm.Tool{
 		Name:        "list_skills",
 		Description: "List all installed skills. Shows skill names and descriptions.",
@@ -187,14 +285,35 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 			return "No skills installed.", nil
 		}
 
+		sources, err := manager.CheckUpdates(ctx)
+		if err != nil {
+			logger.Warn("failed to check skill updates", "error", err)
+		}
+
 		var sb strings.Builder
+		updates := 0
 		sb.WriteString("Installed skills:\n")
 		for _, skill := range skills {
+			name := skill.Name
 			if skill.IsDir {
-				fmt.Fprintf(&sb, "- %s (multi-file): %s\n", skill.Name, skill.Description)
-			} else {
-				fmt.Fprintf(&sb, "- %s: %s\n", skill.Name, skill.Description)
+				name += " (multi-file)"
+			}
+			if src, ok := sources[strings.ToLower(skill.Name)]; ok {
+				if src.Version != "" {
+					name += " @ " + shortVersion(src.Version)
+				}
+				if src.UpdateAvailable() {
+					name += " [update available]"
+					updates++
+				}
 			}
+			fmt.Fprintf(&sb, "- %s: %s\n", name, skill.Description)
+			if skill.Usage != "" {
+				fmt.Fprintf(&sb, "  usage: %s\n", skill.Usage)
+			}
+		}
+		if updates > 0 {
+			fmt.Fprintf(&sb, "\n%d update(s) available. Use update_skill or update_all_skills.\n", updates)
 		}
 		return sb.String(), nil
 	})
@@ -229,6 +348,84 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 		return fmt.Sprintf("Skill removed: %s", params.Name), nil
 	})
 
+	updateTool := llm.Tool{
+		Name:        "update_skill",
+		Description: "Update an installed skill to the latest version from the URL it was installed from. list_skills shows which skills have updates. Updating replaces local edits and resets access granted to the skill's scripts.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Name of the skill to update",
+				},
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	registry.Register(updateTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		src, updated, err := manager.Update(ctx, params.Name)
+		if err != nil {
+			return "", err
+		}
+		if !updated {
+			return fmt.Sprintf("Skill %s is up to date (%s)", params.Name, shortVersion(src.Version)), nil
+		}
+
+		registry.Notify(ctx, fmt.Sprintf("✅ Skill updated: %s", params.Name))
+		return fmt.Sprintf("Skill updated: %s\nVersion: %s\nSource: %s", params.Name, shortVersion(src.Version), src.URL), nil
+	})
+
+	updateAllTool := llm.Tool{
+		Name:        "update_all_skills",
+		Description: "Update every skill installed from a URL that has a newer version. Updating replaces local edits and resets access granted to the skills' scripts.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(updateAllTool, func(ctx context.Context, args string) (string, error) {
+		sources, err := manager.Sources()
+		if err != nil {
+			return "", err
+		}
+		if len(sources) == 0 {
+			return "No skills were installed from a URL.", nil
+		}
+
+		var updated, failed []string
+		for name := range sources {
+			src, ok, err := manager.Update(ctx, name)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s (%v)", name, err))
+				continue
+			}
+			if ok {
+				updated = append(updated, fmt.Sprintf("%s @ %s", name, shortVersion(src.Version)))
+			}
+		}
+
+		var sb strings.Builder
+		if len(updated) == 0 {
+			sb.WriteString("All skills are up to date.\n")
+		} else {
+			registry.Notify(ctx, fmt.Sprintf("✅ Updated %d skill(s)", len(updated)))
+			fmt.Fprintf(&sb, "Updated: %s\n", strings.Join(updated, ", "))
+		}
+		if len(failed) > 0 {
+			fmt.Fprintf(&sb, "Failed: %s\n", strings.Join(failed, "; "))
+		}
+		return sb.String(), nil
+	})
+
 	readTool := llm.Tool{
 		Name:        "read_skill",
 		Description: "Read the main content of an installed skill. For multi-file skills, reads the main SKILL.md file.",
@@ -292,6 +489,8 @@ func RegisterSkillsTools(registry *Registry, manager *SkillsManager) {
 		if err != nil {
 			return "", err
 		}
+		// a local edit would be lost on update, so it no longer tracks its source
+		manager.forgetSource(params.Name)
 
 		return fmt.Sprintf("Skill saved: %s\nPath: %s", params.Name, path), nil
 	})
@@ -381,14 +580,35 @@ func downloadFile(ctx context.Context, url string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
-// InstallFromURL installs a skill from a URL. Returns path, file count, and error.
+// InstallFromURL installs a skill from a URL and records its source and
+// version for updates. Returns path, file count, and error.
 func (m *SkillsManager) InstallFromURL(ctx context.Context, url, name string) (string, int, error) {
+	version, err := remoteVersion(ctx, url)
+	if err != nil {
+		logger.Warn("could not read skill version", "url", url, "error", err)
+	}
+
+	path, fileCount, err := m.install(ctx, url, name)
+	if err != nil {
+		return "", 0, err
+	}
+
+	now := time.Now()
+	m.setSource(name, &SkillSource{URL: url, Version: version, InstalledAt: now, Latest: version, CheckedAt: now})
+	return path, fileCount, nil
+}
+
+func (m *SkillsManager) install(ctx context.Context, url, name string) (string, int, error) {
 	// Check if it's a GitHub URL
 	ghInfo, isGitHub := parseGitHubURL(url)
 
 	if isGitHub && ghInfo.IsDir {
 		// GitHub directory - install as multi-file skill
-		return m.installGitHubDir(ctx, ghInfo, name)
+		path, fileCount, err := m.installGitHubDir(ctx, ghInfo, name)
+		if err == nil {
+			m.notifyChanged(name)
+		}
+		return path, fileCount, err
 	}
 
 	// Single file install
@@ -479,6 +699,7 @@ func (m *SkillsManager) Save(name, content string) (string, error) {
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("write skill: %w", err)
 	}
+	m.notifyChanged(name)
 
 	return path, nil
 }
@@ -565,6 +786,8 @@ func (m *SkillsManager) Remove(name string) error {
 	// Check for directory-based skill first
 	dirPath := filepath.Join(m.skillsDir, strings.ToLower(name))
 	if info, err := os.Stat(dirPath); err == nil && info.IsDir() {
+		m.notifyChanged(name)
+		m.forgetSource(name)
 		return os.RemoveAll(dirPath)
 	}
 
@@ -575,7 +798,24 @@ func (m *SkillsManager) Remove(name string) error {
 	}
 
 	path := filepath.Join(m.skillsDir, filename)
-	return os.Remove(path)
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	m.notifyChanged(name)
+	m.forgetSource(name)
+	return nil
+}
+
+// OnChange registers fn to run whenever a skill is installed, saved or
+// removed, e.g. to revoke what the previous version was granted
+func (m *SkillsManager) OnChange(fn func(name string)) {
+	m.changed = append(m.changed, fn)
+}
+
+func (m *SkillsManager) notifyChanged(name string) {
+	for _, fn := range m.changed {
+		fn(strings.ToLower(name))
+	}
 }
 
 type SkillInfo struct {
@@ -583,6 +823,7 @@ type SkillInfo struct {
 	Description string
 	Path        string
 	IsDir       bool
+	Usage       string // set for skills that take arguments
 }
 
 func (m *SkillsManager) List() ([]SkillInfo, error) {
@@ -596,6 +837,11 @@ func (m *SkillsManager) List() ([]SkillInfo, error) {
 		name := entry.Name()
 		path := filepath.Join(m.skillsDir, name)
 
+		// bookkeeping, e.g. the sources file or a skill mid-update
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
 		if entry.IsDir() {
 			// Multi-file skill - look for SKILL.md
 			skillFile := filepath.Join(path, "SKILL.md")
@@ -619,6 +865,7 @@ func (m *SkillsManager) List() ([]SkillInfo, error) {
 				Description: desc,
 				Path:        path,
 				IsDir:       true,
+				Usage:       skillUsage(name, string(content)),
 			})
 		} else if strings.HasSuffix(strings.ToLower(name), ".md") {
 			content, err := os.ReadFile(path)
@@ -635,6 +882,7 @@ func (m *SkillsManager) List() ([]SkillInfo, error) {
 				Description: desc,
 				Path:        path,
 				IsDir:       false,
+				Usage:       skillUsage(skillName, string(content)),
 			})
 		}
 	}
@@ -672,6 +920,12 @@ func extractSkillName(url string) string {
 }
 
 func extractDescription(content string) string {
+	if desc := ParseSkillMeta(content).Description; desc != "" {
+		content = desc
+	} else if _, body, ok := splitFrontmatter(content); ok {
+		content = body
+	}
+
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -685,3 +939,82 @@ func extractDescription(content string) string {
 	}
 	return "No description"
 }
+
+// SkillMeta is what a skill declares in its YAML frontmatter
+type SkillMeta struct {
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description"`
+	Tools        toolList `yaml:"tools"`
+	AllowedTools toolList `yaml:"allowed-tools"` // spelling used by Agent Skills
+
+	Arguments []SkillArgument `yaml:"arguments"`
+}
+
+// toolList accepts a YAML list or a comma separated string
+type toolList []string
+
+func (l *toolList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		for _, name := range strings.FieldsFunc(value.Value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			*l = append(*l, name)
+		}
+		return nil
+	}
+	var names []string
+	if err := value.Decode(&names); err != nil {
+		return err
+	}
+	*l = names
+	return nil
+}
+
+// skillBaseTools stay available under a skill's tool list so it can still
+// read its own files and run its scripts
+var skillBaseTools = []string{"use_skill", "read_skill_file", "run_skill_script"}
+
+// ParseSkillMeta reads a skill's frontmatter; skills without one get a zero
+// SkillMeta
+func ParseSkillMeta(content string) SkillMeta {
+	var meta SkillMeta
+	front, _, ok := splitFrontmatter(content)
+	if !ok {
+		return meta
+	}
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		logger.Warn("invalid skill frontmatter", "error", err)
+		return SkillMeta{}
+	}
+	return meta
+}
+
+// splitFrontmatter separates the YAML between the leading --- lines from the
+// rest of a skill
+func splitFrontmatter(content string) (front, body string, ok bool) {
+	content = strings.TrimLeft(content, "\uFEFF \t\r\n")
+	if !strings.HasPrefix(content, "---") {
+		return "", content, false
+	}
+	rest := content[3:]
+	end := strings.Index(rest, "\n---")
+	if end == -1 {
+		return "", content, false
+	}
+	body = rest[end+4:]
+	if nl := strings.IndexByte(body, '\n'); nl != -1 {
+		body = body[nl+1:]
+	} else {
+		body = ""
+	}
+	return rest[:end], body, true
+}
+
+// SkillToolAllowlist returns the tools a turn is limited to while the skill is
+// active, nil for skills that don't declare any
+func SkillToolAllowlist(content string) []string {
+	meta := ParseSkillMeta(content)
+	declared := append(meta.Tools, meta.AllowedTools...)
+	if len(declared) == 0 {
+		return nil
+	}
+	return append(declared, skillBaseTools...)
+}
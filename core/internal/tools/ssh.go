@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/sshexec"
+)
+
+type RemoteCommandArgs struct {
+	Host    string `json:"host"`
+	Command string `json:"command"`
+}
+
+// RegisterSSHTools adds run_remote_command for machines that can't run homelab-agent
+// (routers, NAS appliances). Commands must match the host's allowlist in SSH_HOSTS_FILE.
+func RegisterSSHTools(registry *Registry, executor *sshexec.Executor) {
+	// read-only commands run straight away, everything else the allowlist permits needs approval
+	registry.ConditionallyDangerous("run_remote_command", func(args string) bool {
+		var params RemoteCommandArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return true
+		}
+		readOnly, err := executor.Check(params.Host, params.Command)
+		// refused commands fail in the handler without bothering the user
+		return err == nil && !readOnly
+	})
+
+	registry.Register(llm.Tool{
+		Name:        "list_ssh_hosts",
+		Description: "List the machines reachable over SSH (routers, NAS appliances and other hosts without homelab-agent) and the commands allowed on each.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, func(ctx context.Context, args string) (string, error) {
+		hosts := executor.Hosts()
+		if len(hosts) == 0 {
+			return "no ssh hosts configured", nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString("🔑 ssh hosts:\n")
+		for _, name := range hosts {
+			readOnly, allow := executor.Allowlist(name)
+			sb.WriteString(fmt.Sprintf("\n%s\n", name))
+			if len(readOnly) > 0 {
+				sb.WriteString(fmt.Sprintf("  read-only: %s\n", strings.Join(readOnly, ", ")))
+			}
+			if len(allow) > 0 {
+				sb.WriteString(fmt.Sprintf("  with approval: %s\n", strings.Join(allow, ", ")))
+			}
+		}
+		sb.WriteString("\npatterns are regexes matched against the whole command")
+		return sb.String(), nil
+	})
+
+	registry.Register(llm.Tool{
+		Name:        "run_remote_command",
+		Description: "Run a single allowlisted command over SSH on a host from list_ssh_hosts. No pipes, redirects, ; or $(...). Read-only commands run immediately; others ask the user first.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"host": map[string]any{
+					"type":        "string",
+					"description": "SSH host name from list_ssh_hosts",
+				},
+				"command": map[string]any{
+					"type":        "string",
+					"description": "The command, e.g. 'uptime' or 'df -h'",
+				},
+			},
+			"required": []string{"host", "command"},
+		},
+	}, func(ctx context.Context, args string) (string, error) {
+		var params RemoteCommandArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		result, err := executor.Run(ctx, params.Host, params.Command)
+		if err != nil {
+			return "", err
+		}
+
+		status := "✅"
+		if result.ExitCode != 0 {
+			status = fmt.Sprintf("❌ exit %d", result.ExitCode)
+		}
+		output := strings.TrimRight(result.Output, "\n")
+		if output == "" {
+			output = "(no output)"
+		}
+		if result.Truncated {
+			output += "\n... (output truncated)"
+		}
+		return fmt.Sprintf("%s %s on %s (%s)\n\n%s", status, params.Command, params.Host, result.Duration.Round(time.Millisecond), output), nil
+	})
+}
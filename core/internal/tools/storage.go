@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -16,12 +17,20 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bowerhall/sheldon/internal/encryption"
 	"github.com/bowerhall/sheldon/internal/llm"
 	"github.com/bowerhall/sheldon/internal/storage"
 )
 
 // shared HTTP client for URL fetching (reuses connections)
-var fetchHTTPClient = &http.Client{Timeout: 5 * time.Minute}
+var fetchHTTPClient = &http.Client{Timeout: 30 * time.Minute}
+
+const (
+	// maxFetchSize caps fetch_url, which streams to storage without buffering
+	maxFetchSize = 10 << 30
+	// maxDownloadSize caps download_file, whose content is returned to the model
+	maxDownloadSize = 10 << 20
+)
 
 // RegisterStorageTools registers MinIO file storage tools
 func RegisterStorageTools(registry *Registry, client *storage.Client) {
@@ -124,10 +133,21 @@ func RegisterStorageTools(registry *Registry, client *storage.Client) {
 			bucket = client.AgentBucket()
 		}
 
-		data, err := client.Download(ctx, bucket, params.Path)
+		obj, size, err := client.DownloadStream(ctx, bucket, params.Path)
 		if err != nil {
 			return "", err
 		}
+		defer obj.Close()
+
+		// the content goes into the conversation, so big files are left in storage
+		if size > maxDownloadSize {
+			return "", fmt.Errorf("%w: %s is %s, too big to read into the conversation (limit %s). Use share_link to give the user a download link instead", storage.ErrTooLarge, params.Path, formatBytes(uint64(size)), formatBytes(maxDownloadSize))
+		}
+
+		data, err := io.ReadAll(obj)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", params.Path, err)
+		}
 
 		// check if binary
 		if isBinary(data) {
@@ -236,6 +256,55 @@ func RegisterStorageTools(registry *Registry, client *storage.Client) {
 		return fmt.Sprintf("deleted %s from %s", params.Path, params.Space), nil
 	})
 
+	// storage usage tool
+	usageTool := llm.Tool{
+		Name:        "storage_usage",
+		Description: "Show how much each storage space holds, by top-level folder, against its quota. Use when the user asks what's taking up space or before a large fetch_url download.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"space": map[string]any{
+					"type":        "string",
+					"enum":        []string{"user", "agent", "backups"},
+					"description": "Only report this space (default: all)",
+				},
+			},
+		},
+	}
+
+	registry.Register(usageTool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Space string `json:"space"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		spaces := []struct {
+			name   string
+			bucket string
+		}{
+			{"user", client.UserBucket()},
+			{"agent", client.AgentBucket()},
+			{"backups", client.BackupBucket()},
+		}
+
+		var sb strings.Builder
+		for _, space := range spaces {
+			if params.Space != "" && params.Space != space.name {
+				continue
+			}
+
+			usage, err := client.Usage(ctx, space.bucket)
+			if err != nil {
+				fmt.Fprintf(&sb, "%s: %v\n\n", space.name, err)
+				continue
+			}
+			sb.WriteString(describeUsage(space.name, space.bucket, usage, client))
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
 	// share link tool
 	shareTool := llm.Tool{
 		Name:        "share_link",
@@ -365,11 +434,8 @@ func RegisterStorageTools(registry *Registry, client *storage.Client) {
 			return "", fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
 		}
 
-		// limit to 100MB
-		const maxSize = 100 * 1024 * 1024
-		data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
-		if err != nil {
-			return "", fmt.Errorf("read response: %w", err)
+		if resp.ContentLength > maxFetchSize {
+			return "", fmt.Errorf("%w: %s is %s, the limit is %s", storage.ErrTooLarge, params.URL, formatBytes(uint64(resp.ContentLength)), formatBytes(maxFetchSize))
 		}
 
 		contentType := resp.Header.Get("Content-Type")
@@ -377,26 +443,79 @@ func RegisterStorageTools(registry *Registry, client *storage.Client) {
 			contentType = guessContentType(params.Path)
 		}
 
-		if err := client.Upload(ctx, bucket, params.Path, data, contentType); err != nil {
+		// streamed straight into storage so large files never sit in memory
+		size, err := client.UploadStream(ctx, bucket, params.Path, storage.LimitReader(resp.Body, maxFetchSize), resp.ContentLength, contentType)
+		if err != nil {
+			if errors.Is(err, storage.ErrTooLarge) {
+				return "", fmt.Errorf("%w: %s is over the %s limit", storage.ErrTooLarge, params.URL, formatBytes(maxFetchSize))
+			}
 			return "", err
 		}
 
-		return fmt.Sprintf("downloaded %s to %s/%s (%d bytes)", params.URL, params.Space, params.Path, len(data)), nil
+		return fmt.Sprintf("downloaded %s to %s/%s (%s)", params.URL, params.Space, params.Path, formatBytes(uint64(size))), nil
 	})
 }
 
+// maxUsagePrefixes caps how many folders storage_usage lists per space
+const maxUsagePrefixes = 10
+
+func describeUsage(space, bucket string, usage []storage.PrefixUsage, client *storage.Client) string {
+	var total, files int64
+	for _, u := range usage {
+		total += u.Size
+		files += u.Files
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s in %d files", space, formatBytes(uint64(total)), files)
+	if quota, ok := client.Quota(bucket); ok {
+		fmt.Fprintf(&sb, " of %s (%.0f%%)", formatBytes(uint64(quota.MaxBytes)), float64(total)/float64(quota.MaxBytes)*100)
+		if quota.PruneOldest {
+			sb.WriteString(", oldest files pruned when full")
+		} else {
+			sb.WriteString(", uploads refused when full")
+		}
+	}
+	sb.WriteString("\n")
+
+	for i, u := range usage {
+		if i == maxUsagePrefixes {
+			fmt.Fprintf(&sb, "  ... %d more folders\n", len(usage)-maxUsagePrefixes)
+			break
+		}
+		prefix := u.Prefix
+		if prefix == "" {
+			prefix = "(top level)"
+		}
+		fmt.Fprintf(&sb, "  %s: %s (%d files)\n", prefix, formatBytes(uint64(u.Size)), u.Files)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
 // DocumentSender can send documents to users
 type DocumentSender interface {
 	SendDocument(chatID int64, data []byte, filename, caption string) error
 }
 
-// RegisterBackupTool registers the memory backup tool (requires memory path)
-func RegisterBackupTool(registry *Registry, client *storage.Client, memoryPath string, sender DocumentSender) {
+// FileSizeLimiter is a sender that knows the largest file each chat's
+// provider accepts, 0 for no limit
+type FileSizeLimiter interface {
+	MaxFileSize(chatID int64) int64
+}
+
+// backupLinkExpiry is how long the download link for a backup too big to
+// attach stays valid
+const backupLinkExpiry = time.Hour
+
+// RegisterBackupTool registers the memory backup tool (requires memory path).
+// With a key the zip is encrypted so a leaked backup doesn't expose memory.
+func RegisterBackupTool(registry *Registry, client *storage.Client, memoryPath string, key *encryption.Key, sender DocumentSender) {
 	tool := llm.Tool{
 		Name: "backup_memory",
 		Description: `Create a backup of Sheldon's memory database and send it directly to you.
 
-The backup is a zip file containing the SQLite database. It's also stored in the backups bucket for redundancy.
+The backup is a zip file containing the SQLite database, encrypted when MEMORY_ENCRYPTION_KEY is set. It's also stored in the backups bucket for redundancy.
 
 IMPORTANT: Only use this when the user explicitly asks for a backup with words like "backup", "export memory", "download my data". Do NOT use proactively.`,
 		Parameters: map[string]any{
@@ -428,11 +547,21 @@ IMPORTANT: Only use this when the user explicitly asks for a backup with words l
 		}
 
 		zipName := fmt.Sprintf("sheldon_backup_%s.zip", timestamp)
+		contentType := "application/zip"
+		if key != nil {
+			if zipData, err = key.SealExport(zipData); err != nil {
+				return "", fmt.Errorf("encrypt backup: %w", err)
+			}
+			zipName += ".enc"
+			contentType = "application/octet-stream"
+		}
 
 		// store in backup bucket for redundancy
-		if err := client.Upload(ctx, client.BackupBucket(), zipName, zipData, "application/zip"); err != nil {
+		stored := true
+		if err := client.Upload(ctx, client.BackupBucket(), zipName, zipData, contentType); err != nil {
 			// non-fatal, continue to send to user
 			fmt.Printf("backup storage failed (non-fatal): %s\n", err.Error())
+			stored = false
 		}
 
 		// send directly to user - no URL exposed
@@ -441,6 +570,20 @@ IMPORTANT: Only use this when the user explicitly asks for a backup with words l
 			return "", fmt.Errorf("no chat ID in context")
 		}
 
+		// too big for this chat's provider (Discord takes 10 MB), so fall back to a short-lived link
+		if limiter, ok := sender.(FileSizeLimiter); ok {
+			if limit := limiter.MaxFileSize(chatID); limit > 0 && int64(len(zipData)) > limit {
+				if !stored {
+					return "", fmt.Errorf("backup is %s, over this chat's %s attachment limit, and storing it failed", formatBytes(uint64(len(zipData))), formatBytes(uint64(limit)))
+				}
+				link, err := client.PublicPresignedURL(ctx, client.BackupBucket(), zipName, backupLinkExpiry)
+				if err != nil {
+					return "", fmt.Errorf("backup %s is stored but too big to attach, and the link failed: %w", zipName, err)
+				}
+				return fmt.Sprintf("Backup %s is %s, too big to attach in this chat. Give the user this download link, valid for 1 hour: %s", zipName, formatBytes(uint64(len(zipData))), link), nil
+			}
+		}
+
 		if err := sender.SendDocument(chatID, zipData, zipName, "Memory backup"); err != nil {
 			return "", fmt.Errorf("send backup: %w", err)
 		}
@@ -56,6 +56,7 @@ func RegisterExtractionTool(registry *Registry, extractor ExtractorFunc) {
 		}
 		return "Extraction complete. Pending messages from previous days processed into long-term memory.", nil
 	})
+	registry.Invalidates("force_extraction", "recall_memory")
 }
 
 func registerSystemStatus(registry *Registry, memoryPath string, storageClient *storage.Client) {
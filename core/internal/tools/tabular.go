@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/storage"
+	"github.com/bowerhall/sheldon/internal/tabular"
+)
+
+type AnalyzeCSVArgs struct {
+	Space string `json:"space"`
+	Path  string `json:"path"`
+	Sheet string `json:"sheet,omitempty"`
+	Query string `json:"query,omitempty"`
+}
+
+const (
+	maxTabularFileSize = 50 << 20
+	maxTabularRows     = 100
+	previewRows        = 5
+	tabularTimeout     = 30 * time.Second
+)
+
+// RegisterTabularTools registers analyze_csv, which queries CSV and XLSX files
+// from storage with SQL in an in-memory database instead of a coder task
+func RegisterTabularTools(registry *Registry, client *storage.Client) {
+	analyzeTool := llm.Tool{
+		Name:        "analyze_csv",
+		Description: "Analyze a CSV or Excel (.xlsx) file from storage with SQL. The file is loaded as a table named 'data'. Call without a query first to see the columns, their types and a few rows, then run SELECT queries with GROUP BY, SUM, AVG, COUNT, ORDER BY etc. to answer questions about the data. Quote column names with spaces in double quotes.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"space": map[string]any{
+					"type":        "string",
+					"enum":        []string{"user", "agent"},
+					"description": "Storage space: 'user' for the user's files, 'agent' for your own",
+				},
+				"path": map[string]any{
+					"type":        "string",
+					"description": "File path in storage, e.g. 'finance/transactions.csv'",
+				},
+				"sheet": map[string]any{
+					"type":        "string",
+					"description": "Sheet name for Excel files (default: first sheet)",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "A single SELECT over the table 'data', e.g. 'SELECT category, SUM(amount) FROM data GROUP BY category'. Omit to describe the file.",
+				},
+			},
+			"required": []string{"space", "path"},
+		},
+	}
+
+	registry.Register(analyzeTool, func(ctx context.Context, args string) (string, error) {
+		var params AnalyzeCSVArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if params.Path == "" {
+			return "", fmt.Errorf("path is required")
+		}
+
+		bucket := client.UserBucket()
+		if params.Space == "agent" {
+			bucket = client.AgentBucket()
+		}
+
+		data, err := client.Download(ctx, bucket, params.Path)
+		if err != nil {
+			return "", fmt.Errorf("download file: %w", err)
+		}
+		if len(data) > maxTabularFileSize {
+			return "", fmt.Errorf("file is %d MB, too large to analyze (max %d MB)", len(data)>>20, maxTabularFileSize>>20)
+		}
+
+		table, err := tabular.Load(data, path.Base(params.Path), params.Sheet)
+		if err != nil {
+			return "", err
+		}
+
+		db, err := tabular.Open(table)
+		if err != nil {
+			return "", err
+		}
+		defer db.Close()
+
+		queryCtx, cancel := context.WithTimeout(ctx, tabularTimeout)
+		defer cancel()
+
+		if strings.TrimSpace(params.Query) == "" {
+			return describeTable(queryCtx, db, path.Base(params.Path), table.Sheet)
+		}
+
+		result, err := db.Query(queryCtx, params.Query, maxTabularRows)
+		if err != nil {
+			return "", err
+		}
+		if len(result.Rows) == 0 {
+			return "📊 Query returned no rows.", nil
+		}
+
+		out := fmt.Sprintf("📊 %d row(s):\n\n%s", len(result.Rows), result)
+		if result.Truncated {
+			out += fmt.Sprintf("\n\n(showing the first %d rows, aggregate or add a LIMIT to see the rest)", maxTabularRows)
+		}
+		return out, nil
+	})
+}
+
+// describeTable lists the columns and a preview so the model can write queries
+func describeTable(ctx context.Context, db *tabular.DB, name, sheet string) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📊 %s", name)
+	if sheet != "" {
+		fmt.Fprintf(&sb, " (sheet %q)", sheet)
+	}
+	fmt.Fprintf(&sb, ": %d rows, loaded as table %q\n\nColumns:\n", db.RowCount(), tabular.TableName)
+	for _, c := range db.Columns() {
+		fmt.Fprintf(&sb, "- %q %s\n", c.Name, c.Type)
+	}
+
+	preview, err := db.Query(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT %d", tabular.TableName, previewRows), previewRows)
+	if err != nil {
+		return "", err
+	}
+	if len(preview.Rows) > 0 {
+		fmt.Fprintf(&sb, "\nFirst rows:\n%s", preview)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
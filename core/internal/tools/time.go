@@ -2,9 +2,12 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/bowerhall/sheldon/internal/cron"
 	"github.com/bowerhall/sheldon/internal/llm"
 )
 
@@ -23,7 +26,8 @@ func RegisterTimeTools(registry *Registry, timezone *time.Location) {
 	}
 
 	registry.Register(timeTool, func(ctx context.Context, args string) (string, error) {
-		now := time.Now().In(timezone)
+		tz := TimezoneFromContext(ctx, timezone)
+		now := time.Now().In(tz)
 		_, week := now.ISOWeek()
 
 		return fmt.Sprintf(`Current time: %s
@@ -35,7 +39,62 @@ Timezone: %s`,
 			now.Format("2006-01-02"),
 			now.Format("Monday"),
 			week,
-			timezone.String(),
+			tz.String(),
 		), nil
 	})
 }
+
+// RegisterTimezoneTool lets each user set the timezone they live in, which
+// their reminders, check-ins and dates then follow instead of the configured
+// one. save saves it for the session.
+func RegisterTimezoneTool(registry *Registry, cronStore *cron.Store, save func(ctx context.Context, sessionID string, loc *time.Location) error) {
+	tool := llm.Tool{
+		Name:        "set_timezone",
+		Description: "Set the timezone the user lives in, e.g. when they mention where they live or that their reminders come at the wrong hour. Their reminders, check-ins and times follow it from then on; recurring reminders keep their time of day in the new zone.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"timezone": map[string]any{
+					"type":        "string",
+					"description": "IANA timezone name, e.g. 'Europe/Berlin' or 'America/New_York'",
+				},
+			},
+			"required": []string{"timezone"},
+		},
+	}
+
+	registry.Register(tool, func(ctx context.Context, args string) (string, error) {
+		var params struct {
+			Timezone string `json:"timezone"`
+		}
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		name := strings.TrimSpace(params.Timezone)
+		loc, err := time.LoadLocation(name)
+		if err != nil || name == "" || name == "Local" {
+			return "", fmt.Errorf("unknown timezone %q: use an IANA name like Europe/Berlin", params.Timezone)
+		}
+
+		sessionID := SessionIDFromContext(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("no session context available")
+		}
+		if err := save(ctx, sessionID, loc); err != nil {
+			return "", err
+		}
+
+		result := fmt.Sprintf("Timezone set to %s. It's %s there now.", loc, time.Now().In(loc).Format("Mon 15:04"))
+		if chatID := ChatIDFromContext(ctx); chatID != 0 && cronStore != nil {
+			n, err := cronStore.Reschedule(chatID)
+			if err != nil {
+				return result + fmt.Sprintf(" (Rescheduling reminders failed: %v)", err), nil
+			}
+			if n > 0 {
+				result += fmt.Sprintf(" %d recurring reminders moved to local time.", n)
+			}
+		}
+		return result, nil
+	})
+}
@@ -1,11 +1,15 @@
 package tools
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/locale"
 )
 
 type Handler func(ctx context.Context, args string) (string, error)
@@ -13,9 +17,54 @@ type Handler func(ctx context.Context, args string) (string, error)
 type NotifyFunc func(chatID int64, message string)
 
 type Registry struct {
-	tools    []llm.Tool
-	handlers map[string]Handler
-	notify   NotifyFunc
+	tools       []llm.Tool
+	handlers    map[string]Handler
+	notify      NotifyFunc
+	cache       *resultCache
+	cacheTTL    map[string]time.Duration
+	invalidates map[string][]string
+	preparers   map[string]Handler
+	redact      func(string) string
+
+	approvalChecks map[string]func(args string) bool // see ConditionallyDangerous
+
+	disabledMu sync.RWMutex
+	disabled   map[string]bool // hidden from the model, set from DISABLED_TOOLS
+}
+
+// resultCache is an LRU of tool results with per-entry expiry
+type resultCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+// Attachments collects images tools want the model to look at alongside their
+// text result. It's only set when the current model has vision.
+type Attachments struct {
+	mu    sync.Mutex
+	media []llm.MediaContent
+}
+
+// ToolScope narrows the tools a turn may use while it runs, e.g. once a skill
+// that declares its tools is activated. Restrictions only ever narrow.
+type ToolScope struct {
+	mu      sync.RWMutex
+	allowed map[string]bool // nil until restricted
+}
+
+// browsedPages remembers the last URL each session opened with browse,
+// so browse_screenshot can capture "the current page"
+type browsedPages struct {
+	mu   sync.Mutex
+	urls map[string]string
 }
 
 type ctxKey string
@@ -25,6 +74,18 @@ const UserIDKey ctxKey = "userID"
 const MediaKey ctxKey = "media"
 const SafeModeKey ctxKey = "safeMode"
 const SessionIDKey ctxKey = "sessionID"
+const UserEntityKey ctxKey = "userEntity"
+const AttachmentsKey ctxKey = "attachments"
+const ProgressKey ctxKey = "progress"
+const AllowedToolsKey ctxKey = "allowedTools"
+const ToolScopeKey ctxKey = "toolScope"
+const LanguageKey ctxKey = "language"
+const TimezoneKey ctxKey = "timezone"
+const PrefsKey ctxKey = "prefs"
+
+// ProgressFunc shows the user a short status line while a turn is running,
+// e.g. "building image"
+type ProgressFunc func(status string)
 
 func ChatIDFromContext(ctx context.Context) int64 {
 	if id, ok := ctx.Value(ChatIDKey).(int64); ok {
@@ -47,8 +108,101 @@ func SessionIDFromContext(ctx context.Context) string {
 	return ""
 }
 
+// LanguageFromContext is the chat's language code, empty when it isn't
+// known and output should stay in English
+func LanguageFromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(LanguageKey).(string); ok {
+		return lang
+	}
+	return ""
+}
+
+// PrefsFromContext is how the chat likes dates, units and money written
+func PrefsFromContext(ctx context.Context) locale.Prefs {
+	prefs, _ := ctx.Value(PrefsKey).(locale.Prefs)
+	prefs.Lang = LanguageFromContext(ctx)
+	return prefs
+}
+
+// TimezoneFromContext is the chat's own timezone, or fallback when it hasn't
+// set one
+func TimezoneFromContext(ctx context.Context, fallback *time.Location) *time.Location {
+	if loc, ok := ctx.Value(TimezoneKey).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return fallback
+}
+
+// ReportProgress updates the user on a long operation. It does nothing when
+// the channel has no way to show progress.
+func ReportProgress(ctx context.Context, status string) {
+	if fn, ok := ctx.Value(ProgressKey).(ProgressFunc); ok && fn != nil {
+		fn(status)
+	}
+}
+
+// WithAllowedTools limits a turn to the named tools, for triggers that should
+// only do one job. An empty list leaves every tool available.
+func WithAllowedTools(ctx context.Context, names []string) context.Context {
+	if len(names) == 0 {
+		return ctx
+	}
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	return context.WithValue(ctx, AllowedToolsKey, allowed)
+}
+
+// ToolAllowed reports whether a turn limited by WithAllowedTools or
+// RestrictTools may use name
+func ToolAllowed(ctx context.Context, name string) bool {
+	if allowed, ok := ctx.Value(AllowedToolsKey).(map[string]bool); ok && !allowed[name] {
+		return false
+	}
+	if scope, ok := ctx.Value(ToolScopeKey).(*ToolScope); ok {
+		scope.mu.RLock()
+		defer scope.mu.RUnlock()
+		return scope.allowed == nil || scope.allowed[name]
+	}
+	return true
+}
+
+// WithToolScope returns a context whose tools RestrictTools can narrow for the
+// rest of the turn. A context that already has a scope is returned as is.
+func WithToolScope(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(ToolScopeKey).(*ToolScope); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, ToolScopeKey, &ToolScope{})
+}
+
+// RestrictTools limits the rest of the turn to names, on top of any earlier
+// restriction. It reports false when the context has no scope to restrict.
+func RestrictTools(ctx context.Context, names []string) bool {
+	scope, ok := ctx.Value(ToolScopeKey).(*ToolScope)
+	if !ok {
+		return false
+	}
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if scope.allowed == nil || scope.allowed[name] {
+			allowed[name] = true
+		}
+	}
+	scope.allowed = allowed
+	return true
+}
+
 // UserEntityName returns the entity name for the current user based on session
 func UserEntityName(ctx context.Context) string {
+	// group chats attribute memory to the speaker rather than the shared session
+	if name, ok := ctx.Value(UserEntityKey).(string); ok && name != "" {
+		return name
+	}
 	sessionID := SessionIDFromContext(ctx)
 	if sessionID == "" {
 		// fallback to chatID-based name
@@ -75,3 +229,34 @@ func SafeModeFromContext(ctx context.Context) bool {
 	}
 	return false
 }
+
+// WithAttachments returns a context tools can attach images to
+func WithAttachments(ctx context.Context) (context.Context, *Attachments) {
+	a := &Attachments{}
+	return context.WithValue(ctx, AttachmentsKey, a), a
+}
+
+// Attach adds images for the model and reports whether it can see them
+func Attach(ctx context.Context, media ...llm.MediaContent) bool {
+	a, ok := ctx.Value(AttachmentsKey).(*Attachments)
+	if !ok {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.media = append(a.media, media...)
+	return true
+}
+
+// CanAttach reports whether images attached in this context reach the model
+func CanAttach(ctx context.Context) bool {
+	_, ok := ctx.Value(AttachmentsKey).(*Attachments)
+	return ok
+}
+
+// Media returns everything attached so far
+func (a *Attachments) Media() []llm.MediaContent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.media
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/bowerhall/sheldon/internal/budget"
 	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/locale"
 )
 
 func RegisterUsageTools(registry *Registry, store *budget.Store, timezone *time.Location) {
@@ -18,7 +19,7 @@ func RegisterUsageTools(registry *Registry, store *budget.Store, timezone *time.
 
 	usageTool := llm.Tool{
 		Name:        "usage_summary",
-		Description: "Get a summary of API usage and costs. Shows total requests, tokens, and cost in USD for a time period.",
+		Description: "Get a summary of API usage and costs. Shows total requests, tokens, and spend in USD for a time period, with a per-model cost breakdown.",
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -50,51 +51,50 @@ func RegisterUsageTools(registry *Registry, store *budget.Store, timezone *time.
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
-		var summary *budget.Summary
-		var periodLabel string
-		var err error
-
-		switch params.Period {
-		case "today":
-			summary, err = store.Today()
-			periodLabel = "today"
-		case "week":
-			summary, err = store.ThisWeek()
-			periodLabel = "this week"
-		case "month":
-			summary, err = store.ThisMonth()
-			periodLabel = "this month"
-		case "custom":
-			from, err := time.ParseInLocation("2006-01-02", params.From, timezone)
-			if err != nil {
-				return "", fmt.Errorf("invalid from date: %w", err)
-			}
-			to, err := time.ParseInLocation("2006-01-02", params.To, timezone)
-			if err != nil {
-				return "", fmt.Errorf("invalid to date: %w", err)
-			}
-			to = to.Add(24 * time.Hour) // include the end date
-			summary, err = store.SummaryRange(from, to)
-			if err != nil {
-				return "", err
-			}
-			periodLabel = fmt.Sprintf("%s to %s", params.From, params.To)
-		default:
-			return "", fmt.Errorf("invalid period: %s", params.Period)
+		from, to, periodLabel, err := usagePeriod(params.Period, params.From, params.To, timezone)
+		if err != nil {
+			return "", err
 		}
 
+		summary, err := store.SummaryRange(from, to)
 		if err != nil {
 			return "", err
 		}
 
-		return fmt.Sprintf(
-			"Usage for %s:\n- Requests: %d\n- Input tokens: %d\n- Output tokens: %d\n- Total cost: $%.4f",
+		var result strings.Builder
+		fmt.Fprintf(&result,
+			"Usage for %s:\n- Requests: %d\n- Input tokens: %d\n- Output tokens: %d\n",
 			periodLabel,
 			summary.TotalRequests,
 			summary.TotalInputTokens,
 			summary.TotalOutputTokens,
-			summary.TotalCostUSD,
-		), nil
+		)
+		if summary.TotalCacheTokens > 0 {
+			fmt.Fprintf(&result, "- Cached prompt tokens: %d\n", summary.TotalCacheTokens)
+		}
+		fmt.Fprintf(&result, "- Total cost: %s\n", locale.Money(summary.TotalCostUSD, "USD"))
+
+		breakdown, err := store.BreakdownByModel(from, to)
+		if err != nil {
+			return "", err
+		}
+		if len(breakdown) > 0 {
+			result.WriteString("\nSpend by model:\n")
+			estimated := false
+			for _, b := range breakdown {
+				marker := ""
+				if !budget.HasPricing(b.Provider, b.Model) {
+					marker = " *"
+					estimated = true
+				}
+				fmt.Fprintf(&result, "- %s/%s: %s (%d requests)%s\n", b.Provider, b.Model, locale.Money(b.CostUSD, "USD"), b.Requests, marker)
+			}
+			if estimated {
+				result.WriteString("\n* no published pricing, cost is a conservative estimate\n")
+			}
+		}
+
+		return result.String(), nil
 	})
 
 	breakdownTool := llm.Tool{
@@ -137,36 +137,9 @@ func RegisterUsageTools(registry *Registry, store *budget.Store, timezone *time.
 			return "", fmt.Errorf("invalid arguments: %w", err)
 		}
 
-		now := time.Now().In(timezone)
-		var from, to time.Time
-
-		switch params.Period {
-		case "today":
-			from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, timezone)
-			to = from.Add(24 * time.Hour)
-		case "week":
-			weekday := int(now.Weekday())
-			if weekday == 0 {
-				weekday = 7
-			}
-			from = time.Date(now.Year(), now.Month(), now.Day()-weekday+1, 0, 0, 0, 0, timezone)
-			to = from.Add(7 * 24 * time.Hour)
-		case "month":
-			from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, timezone)
-			to = from.AddDate(0, 1, 0)
-		case "custom":
-			var err error
-			from, err = time.ParseInLocation("2006-01-02", params.From, timezone)
-			if err != nil {
-				return "", fmt.Errorf("invalid from date: %w", err)
-			}
-			to, err = time.ParseInLocation("2006-01-02", params.To, timezone)
-			if err != nil {
-				return "", fmt.Errorf("invalid to date: %w", err)
-			}
-			to = to.Add(24 * time.Hour)
-		default:
-			return "", fmt.Errorf("invalid period: %s", params.Period)
+		from, to, _, err := usagePeriod(params.Period, params.From, params.To, timezone)
+		if err != nil {
+			return "", err
 		}
 
 		var result strings.Builder
@@ -180,11 +153,15 @@ func RegisterUsageTools(registry *Registry, store *budget.Store, timezone *time.
 			if len(breakdown) == 0 {
 				return "No usage data for this period.", nil
 			}
-			result.WriteString("| Model | Requests | Input Tokens | Output Tokens | Cost |\n")
-			result.WriteString("|-------|----------|--------------|---------------|------|\n")
+			result.WriteString("| Provider | Model | Requests | Input Tokens | Output Tokens | Cost |\n")
+			result.WriteString("|----------|-------|----------|--------------|---------------|------|\n")
 			for _, b := range breakdown {
-				result.WriteString(fmt.Sprintf("| %s | %d | %d | %d | $%.4f |\n",
-					b.Model, b.Requests, b.InputTokens, b.OutputTokens, b.CostUSD))
+				cost := locale.Money(b.CostUSD, "USD")
+				if !budget.HasPricing(b.Provider, b.Model) {
+					cost += " (est.)"
+				}
+				result.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %d | %s |\n",
+					b.Provider, b.Model, b.Requests, b.InputTokens, b.OutputTokens, cost))
 			}
 		case "day":
 			breakdown, err := store.BreakdownByDay(from, to)
@@ -197,8 +174,8 @@ func RegisterUsageTools(registry *Registry, store *budget.Store, timezone *time.
 			result.WriteString("| Date | Requests | Input Tokens | Output Tokens | Cost |\n")
 			result.WriteString("|------|----------|--------------|---------------|------|\n")
 			for _, b := range breakdown {
-				result.WriteString(fmt.Sprintf("| %s | %d | %d | %d | $%.4f |\n",
-					b.Date, b.Requests, b.InputTokens, b.OutputTokens, b.CostUSD))
+				result.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s |\n",
+					b.Date, b.Requests, b.InputTokens, b.OutputTokens, locale.Money(b.CostUSD, "USD")))
 			}
 		default:
 			return "", fmt.Errorf("invalid breakdown type: %s", params.By)
@@ -207,3 +184,37 @@ func RegisterUsageTools(registry *Registry, store *budget.Store, timezone *time.
 		return result.String(), nil
 	})
 }
+
+// usagePeriod resolves a named period to a [from, to) range and a label
+func usagePeriod(period, fromDate, toDate string, timezone *time.Location) (time.Time, time.Time, string, error) {
+	now := time.Now().In(timezone)
+
+	switch period {
+	case "today":
+		from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, timezone)
+		return from, from.Add(24 * time.Hour), "today", nil
+	case "week":
+		weekday := int(now.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		from := time.Date(now.Year(), now.Month(), now.Day()-weekday+1, 0, 0, 0, 0, timezone)
+		return from, from.Add(7 * 24 * time.Hour), "this week", nil
+	case "month":
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, timezone)
+		return from, from.AddDate(0, 1, 0), "this month", nil
+	case "custom":
+		from, err := time.ParseInLocation("2006-01-02", fromDate, timezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("invalid from date: %w", err)
+		}
+		to, err := time.ParseInLocation("2006-01-02", toDate, timezone)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("invalid to date: %w", err)
+		}
+		// include the end date
+		return from, to.Add(24 * time.Hour), fmt.Sprintf("%s to %s", fromDate, toDate), nil
+	default:
+		return time.Time{}, time.Time{}, "", fmt.Errorf("invalid period: %s", period)
+	}
+}
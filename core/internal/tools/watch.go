@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/cron"
+	"github.com/bowerhall/sheldon/internal/llm"
+	"github.com/bowerhall/sheldon/internal/watch"
+)
+
+const (
+	defaultWatchInterval = time.Hour
+	// floor keeps Sheldon polite to the sites it watches
+	minWatchInterval  = 15 * time.Minute
+	maxWatchesPerChat = 20
+)
+
+type WatchPageArgs struct {
+	URL      string `json:"url"`
+	Selector string `json:"selector,omitempty"`
+	Label    string `json:"label,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+type UnwatchPageArgs struct {
+	WatchID int64 `json:"watch_id"`
+}
+
+// RegisterWatchTools registers watch_page, list_watches and unwatch_page.
+// Each watch has its own watch-page cron that fetches the page and reports changes.
+func RegisterWatchTools(registry *Registry, store *watch.Store, fetcher *watch.Fetcher, cronStore *cron.Store) {
+	watchTool := llm.Tool{
+		Name:        "watch_page",
+		Description: "Check a web page periodically and tell the user when it changes, e.g. price drops, restocks or new releases. Use selector to watch only part of the page (a price, a stock label, a release list) so ads and timestamps don't cause false alarms.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"url": map[string]any{
+					"type":        "string",
+					"description": "Page URL (http or https)",
+				},
+				"selector": map[string]any{
+					"type":        "string",
+					"description": "Optional CSS selector for the part to watch, e.g. '#price', '.stock-status', 'table.releases td'. Tags, #id, .class and descendants only.",
+				},
+				"label": map[string]any{
+					"type":        "string",
+					"description": "Short name for notifications, e.g. 'Trail shoe price'",
+				},
+				"interval": map[string]any{
+					"type":        "string",
+					"description": "How often to check, e.g. '30m', '6h' (default: 1h, minimum: 15m)",
+				},
+			},
+			"required": []string{"url"},
+		},
+	}
+
+	registry.Register(watchTool, func(ctx context.Context, args string) (string, error) {
+		var params WatchPageArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		pageURL := strings.TrimSpace(params.URL)
+		if err := validateExternalURL(pageURL); err != nil {
+			return "", fmt.Errorf("URL blocked: %w", err)
+		}
+
+		interval := defaultWatchInterval
+		if params.Interval != "" {
+			d, err := time.ParseDuration(strings.TrimSpace(params.Interval))
+			if err != nil {
+				return "", fmt.Errorf("invalid interval %q: use e.g. 30m or 6h", params.Interval)
+			}
+			interval = d
+		}
+		if interval < minWatchInterval {
+			interval = minWatchInterval
+		}
+
+		existing, err := store.List(chatID)
+		if err != nil {
+			return "", fmt.Errorf("list watches: %w", err)
+		}
+		if len(existing) >= maxWatchesPerChat {
+			return "", fmt.Errorf("already watching %d pages, remove one with unwatch_page first", len(existing))
+		}
+
+		// the first fetch checks the selector works and becomes the baseline
+		snapshot, err := fetcher.Fetch(ctx, pageURL, strings.TrimSpace(params.Selector))
+		if err != nil {
+			return "", err
+		}
+
+		w, err := store.Add(&watch.Watch{
+			ChatID:   chatID,
+			URL:      pageURL,
+			Selector: strings.TrimSpace(params.Selector),
+			Label:    strings.TrimSpace(params.Label),
+			Interval: interval,
+			Snapshot: snapshot,
+		})
+		if errors.Is(err, watch.ErrAlreadyWatching) {
+			return fmt.Sprintf("Already watching %s.", pageURL), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("add watch: %w", err)
+		}
+
+		if _, err := cronStore.Create(watch.Keyword(w.ID), "@every "+interval.String(), chatID, nil); err != nil {
+			store.Remove(chatID, w.ID)
+			return "", fmt.Errorf("schedule watch: %w", err)
+		}
+
+		return fmt.Sprintf("👀 Watching %s (watch %d), checking every %s. Right now it reads:\n\n%s",
+			w.Name(), w.ID, interval, wrapUntrustedContent(truncateSnapshot(snapshot))), nil
+	})
+
+	listTool := llm.Tool{
+		Name:        "list_watches",
+		Description: "List the pages being watched for changes, with their IDs and when they last changed",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}
+
+	registry.Register(listTool, func(ctx context.Context, args string) (string, error) {
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		list, err := store.List(chatID)
+		if err != nil {
+			return "", fmt.Errorf("list watches: %w", err)
+		}
+		if len(list) == 0 {
+			return "Not watching any pages.", nil
+		}
+
+		var sb strings.Builder
+		for _, w := range list {
+			fmt.Fprintf(&sb, "- [%d] %s (%s", w.ID, w.Name(), w.URL)
+			if w.Selector != "" {
+				fmt.Fprintf(&sb, ", %s", w.Selector)
+			}
+			fmt.Fprintf(&sb, ") every %s", w.Interval)
+			if w.LastError != "" {
+				fmt.Fprintf(&sb, " - last check failed: %s", w.LastError)
+			} else if w.LastChanged != nil {
+				fmt.Fprintf(&sb, " - last changed %s ago", time.Since(*w.LastChanged).Round(time.Minute))
+			} else {
+				sb.WriteString(" - no changes yet")
+			}
+			sb.WriteString("\n")
+		}
+		return strings.TrimSpace(sb.String()), nil
+	})
+
+	unwatchTool := llm.Tool{
+		Name:        "unwatch_page",
+		Description: "Stop watching a page. Get the watch_id from list_watches.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"watch_id": map[string]any{
+					"type":        "integer",
+					"description": "ID from list_watches",
+				},
+			},
+			"required": []string{"watch_id"},
+		},
+	}
+
+	registry.Register(unwatchTool, func(ctx context.Context, args string) (string, error) {
+		var params UnwatchPageArgs
+		if err := json.Unmarshal([]byte(args), &params); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		chatID := ChatIDFromContext(ctx)
+		if chatID == 0 {
+			return "", fmt.Errorf("no chat ID in context")
+		}
+
+		w, err := store.Remove(chatID, params.WatchID)
+		if errors.Is(err, watch.ErrWatchNotFound) {
+			return "", fmt.Errorf("no watch with id %d", params.WatchID)
+		}
+		if err != nil {
+			return "", fmt.Errorf("unwatch: %w", err)
+		}
+		if err := cronStore.DeleteByKeyword(watch.Keyword(w.ID), chatID); err != nil {
+			return "", fmt.Errorf("remove watch schedule: %w", err)
+		}
+
+		return fmt.Sprintf("Stopped watching %s.", w.Name()), nil
+	})
+
+	registry.Cacheable("list_watches", 2*time.Minute)
+	registry.Invalidates("watch_page", "list_watches")
+	registry.Invalidates("unwatch_page", "list_watches")
+}
+
+// truncateSnapshot keeps the confirmation short for whole-page watches
+func truncateSnapshot(s string) string {
+	if len(s) <= 500 {
+		return s
+	}
+	return s[:500] + "\n..."
+}
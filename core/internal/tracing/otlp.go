@@ -0,0 +1,149 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+)
+
+const (
+	spanKindInternal = 1
+	statusCodeOK     = 1
+	statusCodeError  = 2
+)
+
+func (t *Tracer) run() {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.export()
+		case <-t.flush:
+			t.export()
+		case <-t.stop:
+			t.export()
+			return
+		}
+	}
+}
+
+func (t *Tracer) export() {
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	for len(spans) > 0 {
+		n := min(len(spans), maxBatch)
+		if err := t.send(spans[:n]); err != nil {
+			logger.Warn("failed to export traces", "spans", n, "error", err)
+		}
+		spans = spans[n:]
+	}
+}
+
+func (t *Tracer) send(spans []*Span) error {
+	batch := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		batch = append(batch, s.toOTLP())
+	}
+
+	body, err := json.Marshal(otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: []otlpKeyValue{keyValue("service.name", t.service)}},
+			ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "sheldon"}, Spans: batch}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("collector returned %d: %s", resp.StatusCode, string(msg))
+	}
+
+	return nil
+}
+
+func (s *Span) toOTLP() otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: statusCodeOK},
+	}
+	if s.parentID != [8]byte{} {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.err != nil {
+		span.Status = otlpStatus{Code: statusCodeError, Message: s.err.Error()}
+	}
+
+	for _, a := range s.attrs {
+		span.Attributes = append(span.Attributes, keyValue(a.key, a.value))
+	}
+
+	return span
+}
+
+func keyValue(key string, value any) otlpKeyValue {
+	var v otlpAnyValue
+	switch val := value.(type) {
+	case string:
+		v.StringValue = &val
+	case bool:
+		v.BoolValue = &val
+	case int:
+		s := strconv.Itoa(val)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(val, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &val
+	case time.Duration:
+		s := strconv.FormatInt(val.Milliseconds(), 10)
+		v.IntValue = &s
+	default:
+		s := fmt.Sprint(val)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
@@ -0,0 +1,217 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a fake OTLP/HTTP endpoint that keeps every request it gets
+type collector struct {
+	mu       sync.Mutex
+	requests []otlpRequest
+	headers  []http.Header
+	paths    []string
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("collector got invalid JSON: %v", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		c.requests = append(c.requests, req)
+		c.headers = append(c.headers, r.Header.Clone())
+		c.paths = append(c.paths, r.URL.Path)
+		c.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+// spans returns every exported span by name
+func (c *collector) spans() map[string]otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	spans := make(map[string]otlpSpan)
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					spans[s.Name] = s
+				}
+			}
+		}
+	}
+	return spans
+}
+
+func attr(s otlpSpan, key string) (otlpAnyValue, bool) {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return otlpAnyValue{}, false
+}
+
+func TestExportOTLP(t *testing.T) {
+	c, srv := newCollector(t)
+	tracer := New(Config{Endpoint: srv.URL + "/", ServiceName: "sheldon-test", Headers: map[string]string{"Authorization": "Bearer secret"}})
+
+	before := time.Now()
+	ctx, parent := tracer.Start(context.Background(), "agent.process", "session", "telegram:1")
+	_, child := tracer.Start(ctx, "tool.recall_memory", "tool.name", "recall_memory")
+	child.SetAttributes("cached", true, "results", 3, "bytes", int64(2048), "score", 0.5, "took", 1500*time.Millisecond, "model", struct{ Name string }{"kimi"})
+	child.RecordError(errors.New("memory unavailable"))
+	child.End()
+	parent.End()
+	parent.End() // ending twice exports once
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tracer.Shutdown(shutdown)
+	after := time.Now()
+
+	if len(c.requests) != 1 {
+		t.Fatalf("collector got %d requests, want 1", len(c.requests))
+	}
+	if c.paths[0] != "/v1/traces" {
+		t.Errorf("posted to %q, want /v1/traces", c.paths[0])
+	}
+	if got := c.headers[0].Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the configured header", got)
+	}
+	if got := c.headers[0].Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	resource := c.requests[0].ResourceSpans[0].Resource
+	if len(resource.Attributes) != 1 || resource.Attributes[0].Key != "service.name" || *resource.Attributes[0].Value.StringValue != "sheldon-test" {
+		t.Errorf("resource attributes = %+v, want service.name sheldon-test", resource.Attributes)
+	}
+
+	spans := c.spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	p, ch := spans["agent.process"], spans["tool.recall_memory"]
+
+	// IDs are lowercase hex of 16 and 8 bytes, and the child shares the trace
+	if len(p.TraceID) != 32 || len(p.SpanID) != 16 {
+		t.Errorf("parent IDs %q/%q, want 32 and 16 hex characters", p.TraceID, p.SpanID)
+	}
+	if ch.TraceID != p.TraceID {
+		t.Errorf("child trace %q, want the parent's %q", ch.TraceID, p.TraceID)
+	}
+	if ch.ParentSpanID != p.SpanID || p.ParentSpanID != "" {
+		t.Errorf("parentSpanId = %q (root %q), want the parent's span ID %q", ch.ParentSpanID, p.ParentSpanID, p.SpanID)
+	}
+	if ch.SpanID == p.SpanID {
+		t.Error("child and parent share a span ID")
+	}
+	if p.Kind != spanKindInternal {
+		t.Errorf("kind = %d, want internal", p.Kind)
+	}
+
+	if p.Status.Code != statusCodeOK {
+		t.Errorf("parent status %+v, want OK", p.Status)
+	}
+	if ch.Status.Code != statusCodeError || ch.Status.Message != "memory unavailable" {
+		t.Errorf("child status %+v, want the recorded error", ch.Status)
+	}
+
+	// timestamps are nanoseconds since the epoch, encoded as strings
+	for _, s := range []otlpSpan{p, ch} {
+		start, err1 := strconv.ParseInt(s.StartTimeUnixNano, 10, 64)
+		end, err2 := strconv.ParseInt(s.EndTimeUnixNano, 10, 64)
+		if err1 != nil || err2 != nil {
+			t.Fatalf("%s timestamps %q/%q are not integers", s.Name, s.StartTimeUnixNano, s.EndTimeUnixNano)
+		}
+		if start < before.UnixNano() || end > after.UnixNano() || end < start {
+			t.Errorf("%s ran %d..%d, want within %d..%d", s.Name, start, end, before.UnixNano(), after.UnixNano())
+		}
+	}
+
+	checks := []struct {
+		key  string
+		want func(otlpAnyValue) bool
+	}{
+		{"tool.name", func(v otlpAnyValue) bool { return v.StringValue != nil && *v.StringValue == "recall_memory" }},
+		{"cached", func(v otlpAnyValue) bool { return v.BoolValue != nil && *v.BoolValue }},
+		{"results", func(v otlpAnyValue) bool { return v.IntValue != nil && *v.IntValue == "3" }},
+		{"bytes", func(v otlpAnyValue) bool { return v.IntValue != nil && *v.IntValue == "2048" }},
+		{"score", func(v otlpAnyValue) bool { return v.DoubleValue != nil && *v.DoubleValue == 0.5 }},
+		{"took", func(v otlpAnyValue) bool { return v.IntValue != nil && *v.IntValue == "1500" }},
+		{"model", func(v otlpAnyValue) bool { return v.StringValue != nil && *v.StringValue == "{kimi}" }},
+	}
+	for _, check := range checks {
+		v, ok := attr(ch, check.key)
+		if !ok || !check.want(v) {
+			t.Errorf("attribute %s = %+v (found %v)", check.key, v, ok)
+		}
+	}
+	if v, ok := attr(p, "session"); !ok || *v.StringValue != "telegram:1" {
+		t.Errorf("parent session attribute = %+v", v)
+	}
+}
+
+func TestExportBatches(t *testing.T) {
+	c, srv := newCollector(t)
+	tracer := New(Config{Endpoint: srv.URL + "/v1/traces"})
+
+	for i := 0; i < maxBatch+10; i++ {
+		_, span := tracer.Start(context.Background(), "span"+strconv.Itoa(i))
+		span.End()
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tracer.Shutdown(shutdown)
+
+	total := 0
+	for i, req := range c.requests {
+		if c.paths[i] != "/v1/traces" {
+			t.Errorf("posted to %q, want the full traces URL unchanged", c.paths[i])
+		}
+		n := len(req.ResourceSpans[0].ScopeSpans[0].Spans)
+		if n > maxBatch {
+			t.Errorf("request %d carried %d spans, want at most %d", i, n, maxBatch)
+		}
+		if got := *req.ResourceSpans[0].Resource.Attributes[0].Value.StringValue; got != "sheldon" {
+			t.Errorf("service.name = %q, want the default", got)
+		}
+		total += n
+	}
+	if total != maxBatch+10 {
+		t.Errorf("exported %d spans, want %d", total, maxBatch+10)
+	}
+}
+
+func TestDisabledTracer(t *testing.T) {
+	tracer := New(Config{})
+	if tracer != nil {
+		t.Fatal("expected no tracer without an endpoint")
+	}
+
+	// a nil tracer and its spans are safe to use
+	ctx, span := tracer.Start(context.Background(), "noop", "k", "v")
+	span.SetAttributes("a", 1)
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	tracer.Shutdown(ctx)
+	if span != nil {
+		t.Error("expected a nil span from a nil tracer")
+	}
+}
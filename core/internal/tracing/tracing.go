@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	flushInterval = 5 * time.Second
+	maxBatch      = 512
+	maxPending    = 4096 // drop spans rather than grow without bound when the collector is down
+)
+
+type ctxKey struct{}
+
+// New creates a tracer exporting to cfg.Endpoint. Returns nil when tracing is disabled.
+func New(cfg Config) *Tracer {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+
+	service := cfg.ServiceName
+	if service == "" {
+		service = "sheldon"
+	}
+
+	// accept both the base endpoint and the full traces URL
+	url := strings.TrimSuffix(cfg.Endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+
+	t := &Tracer{
+		url:     url,
+		service: service,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		flush:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go t.run()
+
+	return t
+}
+
+// Start begins a span as a child of the span in ctx, if any.
+// Attributes are given as alternating key-value pairs, like the logger.
+func (t *Tracer) Start(ctx context.Context, name string, kv ...any) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: t, name: name, start: time.Now()}
+	if parent, ok := ctx.Value(ctxKey{}).(*Span); ok && parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	span.SetAttributes(kv...)
+
+	return context.WithValue(ctx, ctxKey{}, span), span
+}
+
+// SetAttributes adds alternating key-value pairs to the span
+func (s *Span) SetAttributes(kv ...any) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		s.attrs = append(s.attrs, attribute{key: fmt.Sprint(kv[i]), value: kv[i+1]})
+	}
+}
+
+// RecordError marks the span as failed
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	s.tracer.enqueue(s)
+}
+
+func (t *Tracer) enqueue(s *Span) {
+	t.mu.Lock()
+	if len(t.pending) < maxPending {
+		t.pending = append(t.pending, s)
+	}
+	full := len(t.pending) >= maxBatch
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Shutdown exports any queued spans and stops the background exporter
+func (t *Tracer) Shutdown(ctx context.Context) {
+	if t == nil {
+		return
+	}
+
+	t.once.Do(func() { close(t.stop) })
+
+	select {
+	case <-t.stopped:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Endpoint    string            // OTLP/HTTP collector base URL, empty disables tracing
+	ServiceName string            // resource service.name (default: sheldon)
+	Headers     map[string]string // extra headers, e.g. auth for hosted collectors
+}
+
+// Tracer records spans and exports them in batches over OTLP/HTTP.
+// A nil *Tracer is valid and records nothing.
+type Tracer struct {
+	url     string
+	service string
+	headers map[string]string
+	client  *http.Client
+
+	mu      sync.Mutex
+	pending []*Span
+
+	flush   chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// Span is one timed operation. A nil *Span is valid and ignores all calls.
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+
+	mu    sync.Mutex
+	end   time.Time
+	attrs []attribute
+	err   error
+	ended bool
+}
+
+type attribute struct {
+	key   string
+	value any
+}
+
+// OTLP/JSON wire format (opentelemetry-proto, trace/v1)
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
@@ -1,77 +0,0 @@
-package transcribe
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
-	"os"
-	"time"
-)
-
-type whisperResponse struct {
-	Text string `json:"text"`
-}
-
-// Transcribe converts audio to text using OpenAI Whisper API
-func Transcribe(audioData []byte, mimeType string) (string, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("OPENAI_API_KEY not set")
-	}
-
-	ext := ".ogg"
-	if mimeType == "audio/mpeg" {
-		ext = ".mp3"
-	} else if mimeType == "audio/wav" {
-		ext = ".wav"
-	}
-
-	var buf bytes.Buffer
-	w := multipart.NewWriter(&buf)
-
-	fw, err := w.CreateFormFile("file", "audio"+ext)
-	if err != nil {
-		return "", err
-	}
-	if _, err := fw.Write(audioData); err != nil {
-		return "", err
-	}
-
-	if err := w.WriteField("model", "whisper-1"); err != nil {
-		return "", err
-	}
-	w.Close()
-
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/audio/transcriptions", &buf)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", w.FormDataContentType())
-
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("whisper API error: %s", string(body))
-	}
-
-	var result whisperResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", err
-	}
-
-	return result.Text, nil
-}
@@ -0,0 +1,226 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bowerhall/sheldon/internal/logger"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	defaultTimeout   = 10 * time.Second
+	fetchTimeout     = 30 * time.Second
+	memoryLimitPages = 1024 // 64 MiB
+	maxOutputBytes   = 64 * 1024
+	maxStderrBytes   = 4 * 1024
+	maxFetchBytes    = 1 << 20
+)
+
+// results of sheldon.fetch other than a body length; HTTP errors return the
+// negated status code
+const (
+	fetchDenied int32 = -1
+	fetchFailed int32 = -2
+)
+
+// NewRuntime starts a runtime for scripts. checkURL vets every URL a script
+// fetches, including redirects.
+func NewRuntime(ctx context.Context, checkURL func(string) error) (*Runtime, error) {
+	cache := wazero.NewCompilationCache()
+	config := wazero.NewRuntimeConfig().
+		WithCompilationCache(cache).
+		WithMemoryLimitPages(memoryLimitPages).
+		WithCloseOnContextDone(true)
+
+	r := &Runtime{
+		runtime:  wazero.NewRuntimeWithConfig(ctx, config),
+		cache:    cache,
+		checkURL: checkURL,
+	}
+	r.client = &http.Client{
+		Timeout: fetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("too many redirects")
+			}
+			return r.check(req.URL.String())
+		},
+	}
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r.runtime); err != nil {
+		r.Close(ctx)
+		return nil, fmt.Errorf("instantiate wasi: %w", err)
+	}
+
+	_, err := r.runtime.NewHostModuleBuilder("sheldon").
+		NewFunctionBuilder().WithFunc(r.fetch).Export("fetch").
+		Instantiate(ctx)
+	if err != nil {
+		r.Close(ctx)
+		return nil, fmt.Errorf("instantiate host module: %w", err)
+	}
+
+	return r, nil
+}
+
+// Close releases the runtime and its compiled modules
+func (r *Runtime) Close(ctx context.Context) error {
+	err := r.runtime.Close(ctx)
+	r.cache.Close(ctx)
+	return err
+}
+
+// Run executes a script to completion and returns what it wrote to stdout
+func (r *Runtime) Run(ctx context.Context, s Script) (string, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	compiled, err := r.runtime.CompileModule(ctx, s.Module)
+	if err != nil {
+		return "", fmt.Errorf("compile %s: %w", s.Name, err)
+	}
+	defer compiled.Close(ctx)
+
+	stdout := &limitedBuffer{max: maxOutputBytes}
+	stderr := &limitedBuffer{max: maxStderrBytes}
+
+	// every instance is anonymous so the same script can run concurrently
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(s.Name).
+		WithStdin(strings.NewReader(s.Input)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithSysNanosleep().
+		WithRandSource(rand.Reader)
+
+	if slices.Contains(s.Grants, CapStorage) {
+		if s.DataDir == "" {
+			return "", fmt.Errorf("%s has storage but no data directory", s.Name)
+		}
+		if err := os.MkdirAll(s.DataDir, 0755); err != nil {
+			return "", fmt.Errorf("create data dir: %w", err)
+		}
+		config = config.WithFSConfig(wazero.NewFSConfig().WithDirMount(s.DataDir, "/data"))
+	}
+
+	ctx = context.WithValue(ctx, ctxKey{}, s.Grants)
+	mod, err := r.runtime.InstantiateModule(ctx, compiled, config)
+	if mod != nil {
+		mod.Close(ctx)
+	}
+	if err != nil {
+		var exit *sys.ExitError
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return "", fmt.Errorf("%s timed out after %s", s.Name, timeout)
+		case errors.As(err, &exit) && exit.ExitCode() == 0:
+			// proc_exit(0) is a normal finish
+		case errors.As(err, &exit):
+			return "", fmt.Errorf("%s exited with code %d: %s", s.Name, exit.ExitCode(), strings.TrimSpace(stderr.String()))
+		default:
+			return "", fmt.Errorf("run %s: %w", s.Name, err)
+		}
+	}
+
+	out := stdout.String()
+	if stdout.truncated {
+		out += "\n[output truncated]"
+	}
+	return out, nil
+}
+
+// fetch is the sheldon.fetch import. The script passes a JSON fetchRequest
+// and a buffer; the response body is copied into the buffer and its full
+// length returned, so a script can retry with a bigger buffer.
+func (r *Runtime) fetch(ctx context.Context, m api.Module, reqPtr, reqLen, bufPtr, bufCap uint32) int32 {
+	grants, _ := ctx.Value(ctxKey{}).([]Capability)
+	if !slices.Contains(grants, CapNetwork) {
+		return fetchDenied
+	}
+
+	raw, ok := m.Memory().Read(reqPtr, reqLen)
+	if !ok {
+		return fetchFailed
+	}
+	var req fetchRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return fetchFailed
+	}
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	if err := r.check(req.URL); err != nil {
+		logger.Warn("script fetch blocked", "url", req.URL, "error", err)
+		return fetchFailed
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, strings.NewReader(req.Body))
+	if err != nil {
+		return fetchFailed
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		logger.Warn("script fetch failed", "url", req.URL, "error", err)
+		return fetchFailed
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return -int32(resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes))
+	if err != nil {
+		return fetchFailed
+	}
+
+	if !m.Memory().Write(bufPtr, body[:min(len(body), int(bufCap))]) {
+		return fetchFailed
+	}
+	return int32(len(body))
+}
+
+func (r *Runtime) check(url string) error {
+	if r.checkURL == nil {
+		return nil
+	}
+	return r.checkURL(url)
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.max - len(b.buf); room < len(p) {
+		b.truncated = true
+		p = p[:max(room, 0)]
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+func (b *limitedBuffer) String() string {
+	return string(bytes.ToValidUTF8(b.buf, nil))
+}
@@ -0,0 +1,122 @@
+package wasm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// module assembles a binary module from its sections. Every size here stays
+// under 128 so the LEB128 lengths fit in one byte.
+func module(sections ...[]byte) []byte {
+	out := []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+	for _, s := range sections {
+		out = append(out, s...)
+	}
+	return out
+}
+
+func section(id byte, items ...[]byte) []byte {
+	body := []byte{byte(len(items))}
+	for _, item := range items {
+		body = append(body, item...)
+	}
+	return append([]byte{id, byte(len(body))}, body...)
+}
+
+func name(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func code(instructions ...byte) []byte {
+	body := append([]byte{0x00}, instructions...) // no locals
+	return append([]byte{byte(len(body))}, body...)
+}
+
+var (
+	voidType  = []byte{0x60, 0x00, 0x00}
+	quadToI32 = []byte{0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f}
+	i32ToVoid = []byte{0x60, 0x01, 0x7f, 0x00}
+)
+
+// helloModule writes "hello" to stdout with fd_write
+func helloModule() []byte {
+	data := concat([]byte{0x08, 0, 0, 0, 0x05, 0, 0, 0}, []byte("hello"))
+	return module(
+		section(1, quadToI32, voidType),
+		section(2, concat(name("wasi_snapshot_preview1"), name("fd_write"), []byte{0x00, 0x00})),
+		section(3, []byte{0x01}),
+		section(5, []byte{0x00, 0x01}),
+		section(7, concat(name("memory"), []byte{0x02, 0x00}), concat(name("_start"), []byte{0x00, 0x01})),
+		section(10, code(0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x41, 0x20, 0x10, 0x00, 0x1a, 0x0b)),
+		section(11, concat([]byte{0x00, 0x41, 0x00, 0x0b, byte(len(data))}, data)),
+	)
+}
+
+// fetchModule calls sheldon.fetch and exits with the negated result, so a
+// denied request exits with code 1
+func fetchModule() []byte {
+	request := []byte(`{"url":"https://example.com"}`)
+	return module(
+		section(1, quadToI32, i32ToVoid, voidType),
+		section(2,
+			concat(name("sheldon"), name("fetch"), []byte{0x00, 0x00}),
+			concat(name("wasi_snapshot_preview1"), name("proc_exit"), []byte{0x00, 0x01}),
+		),
+		section(3, []byte{0x02}),
+		section(5, []byte{0x00, 0x01}),
+		section(7, concat(name("memory"), []byte{0x02, 0x00}), concat(name("_start"), []byte{0x00, 0x02})),
+		section(10, code(0x41, 0x00, 0x41, 0x00, 0x41, byte(len(request)), 0x41, 0x30, 0x41, 0x10, 0x10, 0x00, 0x6b, 0x10, 0x01, 0x0b)),
+		section(11, concat([]byte{0x00, 0x41, 0x00, 0x0b, byte(len(request))}, request)),
+	)
+}
+
+// loopModule never returns
+func loopModule() []byte {
+	return module(
+		section(1, voidType),
+		section(3, []byte{0x00}),
+		section(7, concat(name("_start"), []byte{0x00, 0x00})),
+		section(10, code(0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b)),
+	)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	r, err := NewRuntime(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close(ctx)
+
+	out, err := r.Run(ctx, Script{Name: "hello", Module: helloModule()})
+	if err != nil {
+		t.Fatalf("hello: %v", err)
+	}
+	if out != "hello" {
+		t.Errorf("hello wrote %q, want %q", out, "hello")
+	}
+
+	_, err = r.Run(ctx, Script{Name: "fetch", Module: fetchModule()})
+	if err == nil || !strings.Contains(err.Error(), "exited with code 1") {
+		t.Errorf("fetch without network: got %v, want exit code 1", err)
+	}
+
+	_, err = r.Run(ctx, Script{Name: "loop", Module: loopModule(), Timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("loop: got %v, want timeout", err)
+	}
+
+	if _, err := r.Run(ctx, Script{Name: "junk", Module: []byte("not wasm")}); err == nil {
+		t.Error("junk module ran")
+	}
+}
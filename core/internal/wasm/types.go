@@ -0,0 +1,57 @@
+package wasm
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// Capability is something a script can only do when the user granted it.
+// Without any, a script reads its input and writes its result, nothing else.
+type Capability string
+
+const (
+	// CapNetwork allows HTTP requests through the sheldon.fetch import
+	CapNetwork Capability = "network"
+	// CapStorage mounts a directory that persists between runs at /data
+	CapStorage Capability = "storage"
+)
+
+// Capabilities are all the grants a script can ask for
+var Capabilities = []Capability{CapNetwork, CapStorage}
+
+// Runtime runs WASI scripts in a shared wazero runtime
+type Runtime struct {
+	runtime  wazero.Runtime
+	cache    wazero.CompilationCache
+	client   *http.Client
+	checkURL func(string) error
+}
+
+// Script is one run of a module
+type Script struct {
+	Name    string        // argv[0], shown in errors
+	Module  []byte        // WASI command module exporting _start
+	Input   string        // passed on stdin
+	Grants  []Capability  // already limited to what the user allowed
+	DataDir string        // host directory mounted at /data with CapStorage
+	Timeout time.Duration // 0 uses defaultTimeout
+}
+
+// fetchRequest is what a script passes to sheldon.fetch as JSON
+type fetchRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// limitedBuffer keeps the first max bytes written and drops the rest
+type limitedBuffer struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+type ctxKey struct{}
@@ -0,0 +1,290 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxPageSize = 5 << 20
+	// snapshots are stored per check, so keep them to the text that matters
+	maxSnapshotSize = 64 << 10
+)
+
+// skipped elements never contribute text
+var skipped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true, atom.Svg: true, atom.Head: true,
+}
+
+// blocks start a new line so a diff shows which row or paragraph changed
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Li: true, atom.Tr: true, atom.Br: true, atom.Section: true, atom.Article: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Td: true, atom.Th: true, atom.Dt: true, atom.Dd: true, atom.Pre: true, atom.Blockquote: true, atom.Option: true,
+}
+
+// NewFetcher creates a fetcher with a browser-like user agent, since shops
+// often turn away obvious bots
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Fetch downloads a page and returns its visible text, limited to elements
+// matching sel when it isn't empty
+func (f *Fetcher) Fetch(ctx context.Context, url, sel string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch page: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return "", fmt.Errorf("read page: %w", err)
+	}
+
+	return Extract(data, sel)
+}
+
+// Extract returns the text of an HTML document, one line per block element.
+// sel supports tags, #ids, .classes, descendants and comma-separated groups.
+func Extract(data []byte, sel string) (string, error) {
+	groups, err := parseSelector(sel)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("parse page: %w", err)
+	}
+
+	var b strings.Builder
+	if len(groups) == 0 {
+		writeText(&b, doc)
+	} else {
+		var visit func(n *html.Node)
+		visit = func(n *html.Node) {
+			if n.Type == html.ElementNode && matchesAny(n, groups) {
+				writeText(&b, n)
+				b.WriteString("\n")
+				return
+			}
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				visit(c)
+			}
+		}
+		visit(doc)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		if len(groups) > 0 {
+			return "", fmt.Errorf("nothing on the page matches %q", sel)
+		}
+		return "", fmt.Errorf("page has no text")
+	}
+
+	text := strings.Join(lines, "\n")
+	if len(text) > maxSnapshotSize {
+		text = text[:maxSnapshotSize]
+	}
+	return text, nil
+}
+
+func writeText(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if skipped[n.DataAtom] {
+			return
+		}
+		if blocks[n.DataAtom] {
+			b.WriteString("\n")
+			defer b.WriteString("\n")
+		} else {
+			// inline elements still separate words, e.g. <span>12</span><span>EUR</span>
+			defer b.WriteString(" ")
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(b, c)
+	}
+}
+
+// parseSelector splits a selector into groups of descendant steps
+func parseSelector(sel string) ([][]selector, error) {
+	var groups [][]selector
+	for _, group := range strings.Split(sel, ",") {
+		fields := strings.Fields(group)
+		if len(fields) == 0 {
+			continue
+		}
+		steps := make([]selector, 0, len(fields))
+		for _, field := range fields {
+			step, err := parseStep(field)
+			if err != nil {
+				return nil, fmt.Errorf("selector %q: %w", sel, err)
+			}
+			steps = append(steps, step)
+		}
+		groups = append(groups, steps)
+	}
+	return groups, nil
+}
+
+func parseStep(s string) (selector, error) {
+	if strings.ContainsAny(s, ">+~[]:*") {
+		return selector{}, fmt.Errorf("only tags, #id, .class and descendants are supported")
+	}
+
+	var step selector
+	for i := 0; i < len(s); {
+		j := i + 1
+		for j < len(s) && s[j] != '#' && s[j] != '.' {
+			j++
+		}
+		part := s[i:j]
+		switch {
+		case part[0] == '#' && len(part) > 1:
+			step.id = part[1:]
+		case part[0] == '.' && len(part) > 1:
+			step.classes = append(step.classes, part[1:])
+		case i == 0 && part[0] != '#' && part[0] != '.':
+			step.tag = strings.ToLower(part)
+		default:
+			return selector{}, fmt.Errorf("invalid step %q", s)
+		}
+		i = j
+	}
+	return step, nil
+}
+
+func matchesAny(n *html.Node, groups [][]selector) bool {
+	for _, steps := range groups {
+		if matches(n, steps) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks the last step against n, then earlier steps against its ancestors
+func matches(n *html.Node, steps []selector) bool {
+	last := len(steps) - 1
+	if !steps[last].match(n) {
+		return false
+	}
+	i := last - 1
+	for p := n.Parent; p != nil && i >= 0; p = p.Parent {
+		if p.Type == html.ElementNode && steps[i].match(p) {
+			i--
+		}
+	}
+	return i < 0
+}
+
+func (s selector) match(n *html.Node) bool {
+	if s.tag != "" && n.Data != s.tag {
+		return false
+	}
+
+	var id, class string
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "id":
+			id = a.Val
+		case "class":
+			class = a.Val
+		}
+	}
+	if s.id != "" && id != s.id {
+		return false
+	}
+	have := strings.Fields(class)
+	for _, want := range s.classes {
+		found := false
+		for _, c := range have {
+			if c == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff compares two snapshots line by line. Repeated lines are counted, so a
+// row that appears once more than before is reported as added.
+func Diff(before, after string) Change {
+	remaining := make(map[string]int)
+	for _, line := range lines(after) {
+		remaining[line]++
+	}
+
+	var change Change
+	for _, line := range lines(before) {
+		if remaining[line] > 0 {
+			remaining[line]--
+			continue
+		}
+		change.Removed = append(change.Removed, line)
+	}
+
+	seen := make(map[string]int)
+	for _, line := range lines(before) {
+		seen[line]++
+	}
+	for _, line := range lines(after) {
+		if seen[line] > 0 {
+			seen[line]--
+			continue
+		}
+		change.Added = append(change.Added, line)
+	}
+	return change
+}
+
+// Empty reports whether the snapshots had the same lines
+func (c Change) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,90 @@
+package watch
+
+import (
+	"strings"
+	"testing"
+)
+
+const samplePage = `<!DOCTYPE html>
+<html><head><title>Shop</title><style>.price{color:red}</style></head>
+<body>
+  <nav><a href="/">Home</a> <a href="/cart">Cart (0)</a></nav>
+  <div id="product">
+    <h1>Trail Shoe</h1>
+    <p class="price sale"><span>89.99</span><span>EUR</span></p>
+    <p class="stock">Out of stock</p>
+  </div>
+  <div class="related"><p class="price">120.00 EUR</p></div>
+  <script>track("view")</script>
+</body></html>`
+
+func TestExtractWholePage(t *testing.T) {
+	text, err := Extract([]byte(samplePage), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Home Cart (0)\nTrail Shoe\n89.99 EUR\nOut of stock\n120.00 EUR"
+	if text != want {
+		t.Errorf("text =\n%s\nwant\n%s", text, want)
+	}
+}
+
+func TestExtractSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"#product .price", "89.99 EUR"},
+		{"p.price", "89.99 EUR\n120.00 EUR"},
+		{"p.price.sale", "89.99 EUR"},
+		{"div#product h1, .stock", "Trail Shoe\nOut of stock"},
+		{"#product", "Trail Shoe\n89.99 EUR\nOut of stock"},
+	}
+
+	for _, tt := range tests {
+		text, err := Extract([]byte(samplePage), tt.selector)
+		if err != nil {
+			t.Errorf("Extract(%q): %v", tt.selector, err)
+			continue
+		}
+		if text != tt.want {
+			t.Errorf("Extract(%q) = %q, want %q", tt.selector, text, tt.want)
+		}
+	}
+}
+
+func TestExtractSelectorErrors(t *testing.T) {
+	if _, err := Extract([]byte(samplePage), ".missing"); err == nil || !strings.Contains(err.Error(), "nothing on the page matches") {
+		t.Errorf("missing selector error = %v", err)
+	}
+	if _, err := Extract([]byte(samplePage), "div > p"); err == nil || !strings.Contains(err.Error(), "only tags") {
+		t.Errorf("unsupported selector error = %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := "Trail Shoe\n89.99 EUR\nOut of stock\nReview\nReview"
+	after := "Trail Shoe\n74.99 EUR\nIn stock\nReview\nReview\nReview"
+
+	change := Diff(before, after)
+	if strings.Join(change.Removed, "|") != "89.99 EUR|Out of stock" {
+		t.Errorf("removed = %q", change.Removed)
+	}
+	if strings.Join(change.Added, "|") != "74.99 EUR|In stock|Review" {
+		t.Errorf("added = %q", change.Added)
+	}
+
+	if !Diff("a\nb", "b\na").Empty() {
+		t.Error("reordered lines counted as a change")
+	}
+}
+
+func TestParseKeyword(t *testing.T) {
+	if id, ok := ParseKeyword(Keyword(42)); !ok || id != 42 {
+		t.Errorf("ParseKeyword(Keyword(42)) = %d, %v", id, ok)
+	}
+	if _, ok := ParseKeyword("feed-digest"); ok {
+		t.Error("feed-digest parsed as a watch")
+	}
+}
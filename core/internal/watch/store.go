@@ -0,0 +1,199 @@
+package watch
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAlreadyWatching = errors.New("already watching this page")
+	ErrWatchNotFound   = errors.New("watch not found")
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS page_watches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    selector TEXT NOT NULL DEFAULT '',
+    label TEXT,
+    interval_seconds INTEGER NOT NULL,
+    snapshot TEXT NOT NULL DEFAULT '',
+    last_checked DATETIME,
+    last_changed DATETIME,
+    last_error TEXT,
+    created_at DATETIME DEFAULT (datetime('now')),
+    UNIQUE(chat_id, url, selector)
+);
+`
+
+const sqliteTime = "2006-01-02 15:04:05"
+
+// NewStore creates a watch store using the provided database connection
+func NewStore(db *sql.DB) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Keyword is the cron keyword that checks a watch
+func Keyword(id int64) string {
+	return KeywordPrefix + strconv.FormatInt(id, 10)
+}
+
+// ParseKeyword returns the watch ID from a cron keyword, if it is one
+func ParseKeyword(keyword string) (int64, bool) {
+	rest, ok := strings.CutPrefix(keyword, KeywordPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	return id, err == nil
+}
+
+// Add stores a new watch with its first snapshot
+func (s *Store) Add(w *Watch) (*Watch, error) {
+	var exists int
+	s.db.QueryRow(`SELECT COUNT(*) FROM page_watches WHERE chat_id = ? AND url = ? AND selector = ?`, w.ChatID, w.URL, w.Selector).Scan(&exists)
+	if exists > 0 {
+		return nil, ErrAlreadyWatching
+	}
+
+	now := time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO page_watches (chat_id, url, selector, label, interval_seconds, snapshot, last_checked)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		w.ChatID, w.URL, w.Selector, w.Label, int64(w.Interval/time.Second), w.Snapshot, now.Format(sqliteTime))
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	added := *w
+	added.ID = id
+	added.LastChecked = &now
+	added.CreatedAt = now
+	return &added, nil
+}
+
+// Get returns a watch by ID
+func (s *Store) Get(id int64) (*Watch, error) {
+	watches, err := s.query(`WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(watches) == 0 {
+		return nil, ErrWatchNotFound
+	}
+	return watches[0], nil
+}
+
+// List returns a chat's watches
+func (s *Store) List(chatID int64) ([]*Watch, error) {
+	return s.query(`WHERE chat_id = ? ORDER BY id`, chatID)
+}
+
+// Remove deletes one of a chat's watches
+func (s *Store) Remove(chatID, id int64) (*Watch, error) {
+	w, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if w.ChatID != chatID {
+		return nil, ErrWatchNotFound
+	}
+	if _, err := s.db.Exec(`DELETE FROM page_watches WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// RecordCheck stores the outcome of a check. A failed check keeps the previous
+// snapshot so the next successful one is compared against it.
+func (s *Store) RecordCheck(id int64, snapshot string, changed bool, checkErr error) error {
+	now := time.Now().UTC().Format(sqliteTime)
+
+	if checkErr != nil {
+		_, err := s.db.Exec(`UPDATE page_watches SET last_checked = ?, last_error = ? WHERE id = ?`, now, checkErr.Error(), id)
+		return err
+	}
+
+	var lastChanged *string
+	if changed {
+		lastChanged = &now
+	}
+	_, err := s.db.Exec(`
+		UPDATE page_watches SET snapshot = ?, last_checked = ?, last_error = NULL, last_changed = COALESCE(?, last_changed)
+		WHERE id = ?`,
+		snapshot, now, lastChanged, id)
+	return err
+}
+
+func (s *Store) query(where string, args ...any) ([]*Watch, error) {
+	rows, err := s.db.Query(`
+		SELECT id, chat_id, url, selector, label, interval_seconds, snapshot, last_checked, last_changed, last_error, created_at
+		FROM page_watches `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watches []*Watch
+	for rows.Next() {
+		var w Watch
+		var seconds int64
+		var label, lastChecked, lastChanged, lastError, createdAt *string
+		if err := rows.Scan(&w.ID, &w.ChatID, &w.URL, &w.Selector, &label, &seconds, &w.Snapshot, &lastChecked, &lastChanged, &lastError, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan watch: %w", err)
+		}
+		w.Interval = time.Duration(seconds) * time.Second
+		if label != nil {
+			w.Label = *label
+		}
+		if lastChecked != nil {
+			t := parseTime(*lastChecked)
+			w.LastChecked = &t
+		}
+		if lastChanged != nil {
+			t := parseTime(*lastChanged)
+			w.LastChanged = &t
+		}
+		if lastError != nil {
+			w.LastError = *lastError
+		}
+		if createdAt != nil {
+			w.CreatedAt = parseTime(*createdAt)
+		}
+		watches = append(watches, &w)
+	}
+
+	return watches, rows.Err()
+}
+
+// Name is the label if the user gave one, otherwise the URL
+func (w *Watch) Name() string {
+	if w.Label != "" {
+		return w.Label
+	}
+	return w.URL
+}
+
+// parseTime tries multiple formats to parse SQLite datetime strings
+func parseTime(s string) time.Time {
+	formats := []string{
+		time.RFC3339,
+		sqliteTime,
+		"2006-01-02T15:04:05",
+	}
+	for _, f := range formats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
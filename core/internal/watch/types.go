@@ -0,0 +1,48 @@
+package watch
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+// KeywordPrefix marks the cron that checks a watch; the watch ID follows it
+const KeywordPrefix = "watch-page:"
+
+// Watch is a page (or part of one) that a chat wants to hear about when it changes
+type Watch struct {
+	ID          int64
+	ChatID      int64
+	URL         string
+	Selector    string // optional, limits the snapshot to matching elements
+	Label       string
+	Interval    time.Duration
+	Snapshot    string // text of the last successful check
+	LastChecked *time.Time
+	LastChanged *time.Time
+	LastError   string
+	CreatedAt   time.Time
+}
+
+// Change is what differs between two snapshots, line by line
+type Change struct {
+	Added   []string
+	Removed []string
+}
+
+// Store persists watches and their latest snapshot
+type Store struct {
+	db *sql.DB
+}
+
+// Fetcher downloads a page and reduces it to comparable text
+type Fetcher struct {
+	client *http.Client
+}
+
+// selector is one compound step of a CSS selector, e.g. div#main.price
+type selector struct {
+	tag     string
+	id      string
+	classes []string
+}
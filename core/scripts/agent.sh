@@ -17,12 +17,36 @@ fi
 # Stop existing container
 docker rm -f homelab-agent 2>/dev/null || true
 
+# systemd control (SERVICES_ALLOWLIST=nginx,jellyfin) needs the host's PID namespace
+SERVICE_ARGS=()
+if [ -n "$SERVICES_ALLOWLIST" ]; then
+    SERVICE_ARGS=(--pid=host --privileged -e SERVICES_NSENTER=true -e SERVICES_ALLOWLIST="$SERVICES_ALLOWLIST")
+fi
+
+# remote_processes needs the host's processes and sockets, not the container's
+if [ "$HOST_INSPECT" = "true" ]; then
+    [ -z "$SERVICES_ALLOWLIST" ] && SERVICE_ARGS+=(--pid=host)
+    SERVICE_ARGS+=(--network=host)
+fi
+
+# shared secret with Sheldon's HOMELAB_AGENT_TOKEN
+if [ -n "$AGENT_TOKEN" ]; then
+    SERVICE_ARGS+=(-e AGENT_TOKEN="$AGENT_TOKEN")
+fi
+
+# threshold alerts pushed to Sheldon; the host's root is mounted so disk usage is the host's
+if [ -n "$ALERT_WEBHOOK_URL" ]; then
+    SERVICE_ARGS+=(-v /:/host:ro -e ALERT_WEBHOOK_URL="$ALERT_WEBHOOK_URL" -e AGENT_NAME="$(hostname)"
+        -e ALERT_DISK_PATHS="${ALERT_DISK_PATHS:-/host}")
+fi
+
 # Run agent
 docker run -d \
     --name homelab-agent \
     --restart unless-stopped \
     -p 8080:8080 \
     -v /var/run/docker.sock:/var/run/docker.sock \
+    "${SERVICE_ARGS[@]}" \
     "$IMAGE"
 
 echo ""
@@ -139,12 +139,19 @@ start_agent() {
     # Stop existing container if running
     docker rm -f homelab-agent 2>/dev/null || true
 
+    # shared secret with Sheldon's HOMELAB_AGENT_TOKEN
+    AGENT_ARGS=()
+    if [ -n "$AGENT_TOKEN" ]; then
+        AGENT_ARGS=(-e AGENT_TOKEN="$AGENT_TOKEN")
+    fi
+
     # Run the agent
     docker run -d \
         --name homelab-agent \
         --restart unless-stopped \
         -p 8080:8080 \
         -v /var/run/docker.sock:/var/run/docker.sock \
+        "${AGENT_ARGS[@]}" \
         "$AGENT_IMAGE"
 
     print_success "homelab-agent started on port 8080"
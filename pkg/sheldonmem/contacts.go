@@ -0,0 +1,202 @@
+package sheldonmem
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Contact is structured contact info attached to a person entity
+type Contact struct {
+	EntityID  int64
+	Name      string
+	Phone     string
+	Email     string
+	Birthday  string // YYYY-MM-DD, or --MM-DD when the year is unknown
+	UpdatedAt time.Time
+}
+
+// Birthday is a contact's next birthday on or after a given day
+type Birthday struct {
+	Contact *Contact
+	Date    time.Time
+	Age     int // age they turn, 0 if the birth year is unknown
+}
+
+var ErrContactNotFound = errors.New("contact not found")
+
+// SaveContact creates or updates the contact card for a person in ownerID's
+// namespace, creating the person entity if needed. Empty fields keep their
+// previous values. Phone and email are encrypted when a key is set.
+func (s *Store) SaveContact(ownerID int64, c Contact) (*Contact, error) {
+	if c.Birthday != "" {
+		if _, _, _, err := ParseBirthday(c.Birthday); err != nil {
+			return nil, err
+		}
+	}
+
+	entity, err := s.FindOwnedEntityByName(c.Name, ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		entity, err = s.CreateOwnedEntity(c.Name, "person", 6, "", ownerID)
+		if err == nil && ownerID != 0 {
+			_, err = s.AddEdge(ownerID, entity.ID, "knows", 1.0, "")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	phone, email := c.Phone, c.Email
+	if phone != "" {
+		if phone, err = s.sealValue(phone); err != nil {
+			return nil, err
+		}
+	}
+	if email != "" {
+		if email, err = s.sealValue(email); err != nil {
+			return nil, err
+		}
+	}
+
+	var owner *int64
+	if ownerID != 0 {
+		owner = &ownerID
+	}
+	if _, err := s.db.Exec(queryUpsertContact, entity.ID, owner, phone, email, c.Birthday); err != nil {
+		return nil, err
+	}
+
+	return s.getContact(entity.ID)
+}
+
+// GetContact finds a contact by the person's name within ownerID's namespace
+func (s *Store) GetContact(ownerID int64, name string) (*Contact, error) {
+	entity, err := s.FindOwnedEntityByName(name, ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrContactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getContact(entity.ID)
+}
+
+// ListContacts returns ownerID's contacts sorted by name
+func (s *Store) ListContacts(ownerID int64) ([]*Contact, error) {
+	var owner *int64
+	if ownerID != 0 {
+		owner = &ownerID
+	}
+
+	rows, err := s.db.Query(queryListContacts, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []*Contact
+	for rows.Next() {
+		c, err := s.scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, c)
+	}
+
+	return contacts, rows.Err()
+}
+
+// UpcomingBirthdays returns ownerID's contacts whose birthday falls within
+// days of from, soonest first
+func (s *Store) UpcomingBirthdays(ownerID int64, from time.Time, days int) ([]Birthday, error) {
+	contacts, err := s.ListContacts(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := start.AddDate(0, 0, days)
+
+	var upcoming []Birthday
+	for _, c := range contacts {
+		if c.Birthday == "" {
+			continue
+		}
+		year, month, day, err := ParseBirthday(c.Birthday)
+		if err != nil {
+			continue
+		}
+
+		next := birthdayIn(start.Year(), month, day, start.Location())
+		if next.Before(start) {
+			next = birthdayIn(start.Year()+1, month, day, start.Location())
+		}
+		if !next.Before(end) {
+			continue
+		}
+
+		b := Birthday{Contact: c, Date: next}
+		if year != 0 {
+			b.Age = next.Year() - year
+		}
+		upcoming = append(upcoming, b)
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Date.Before(upcoming[j].Date)
+	})
+	return upcoming, nil
+}
+
+// ParseBirthday reads YYYY-MM-DD or --MM-DD (vCard style, year unknown).
+// The year is 0 when unknown.
+func ParseBirthday(s string) (int, time.Month, int, error) {
+	if rest, ok := strings.CutPrefix(s, "--"); ok {
+		// parse against a leap year so Feb 29 is accepted
+		t, err := time.Parse("2006-01-02", "2000-"+rest)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid birthday %q: use YYYY-MM-DD or --MM-DD", s)
+		}
+		return 0, t.Month(), t.Day(), nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid birthday %q: use YYYY-MM-DD or --MM-DD", s)
+	}
+	return t.Year(), t.Month(), t.Day(), nil
+}
+
+// birthdayIn places a birthday in a given year, moving Feb 29 to Feb 28 in common years
+func birthdayIn(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if t.Month() != month {
+		t = time.Date(year, month, day-1, 0, 0, 0, 0, loc)
+	}
+	return t
+}
+
+func (s *Store) getContact(entityID int64) (*Contact, error) {
+	c, err := s.scanContact(s.db.QueryRow(queryGetContact, entityID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrContactNotFound
+	}
+	return c, err
+}
+
+func (s *Store) scanContact(row interface{ Scan(...any) error }) (*Contact, error) {
+	var c Contact
+	var phone, email, birthday sql.NullString
+	if err := row.Scan(&c.EntityID, &c.Name, &phone, &email, &birthday, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	c.Phone = s.openValue(phone.String)
+	c.Email = s.openValue(email.String)
+	c.Birthday = birthday.String
+	return &c, nil
+}
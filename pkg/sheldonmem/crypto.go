@@ -0,0 +1,216 @@
+package sheldonmem
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// sealedPrefix marks a value sealed with the store's Sealer
+const sealedPrefix = "enc:v2:"
+
+// legacySealedPrefix marks a value sealed under a key derived without the
+// database's salt; ResealLegacy moves these to sealedPrefix
+const legacySealedPrefix = "enc:v1:"
+
+// sealedPlaceholder is returned for encrypted values when no key is configured
+const sealedPlaceholder = "[encrypted]"
+
+// saltSize is the length of the random salt kept for key derivation
+const saltSize = 16
+
+// Sealer encrypts and decrypts values at rest. The store only calls it; the
+// caller owns the cipher and the key, derived with Salt.
+//
+// Only sensitive fact values and contact details are sealed. The rest of the
+// database, including the vector index, is stored in plaintext.
+type Sealer interface {
+	Seal(data []byte) ([]byte, error)
+	Open(data []byte) ([]byte, error)
+}
+
+// Salt returns the database's random salt for deriving its encryption key,
+// creating it on first use. It isn't secret, but it must survive as long as
+// the sealed values do, so it lives in the database itself.
+func (s *Store) Salt() ([]byte, error) {
+	var salt []byte
+	err := s.db.QueryRow(`SELECT value FROM store_meta WHERE key = 'salt'`).Scan(&salt)
+	if err == nil {
+		return salt, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	salt = make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	// a concurrent first call may have won; read back whichever salt was kept
+	if _, err := s.db.Exec(`INSERT OR IGNORE INTO store_meta (key, value) VALUES ('salt', ?)`, salt); err != nil {
+		return nil, err
+	}
+	err = s.db.QueryRow(`SELECT value FROM store_meta WHERE key = 'salt'`).Scan(&salt)
+	return salt, err
+}
+
+// SetSealer enables encryption of sensitive fact values and contact details.
+// Sensitive facts stored in plaintext before it was set are sealed in place.
+func (s *Store) SetSealer(sealer Sealer) error {
+	s.sealer = sealer
+	return s.sealSensitiveFacts()
+}
+
+// Encrypted reports whether sensitive fact values are encrypted at rest
+func (s *Store) Encrypted() bool {
+	return s.sealer != nil
+}
+
+func (s *Store) sealValue(value string) (string, error) {
+	if s.sealer == nil || isSealed(value) {
+		return value, nil
+	}
+
+	sealed, err := s.sealer.Seal([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openValue decrypts a stored value, passing plaintext values through unchanged
+func (s *Store) openValue(stored string) string {
+	if strings.HasPrefix(stored, legacySealedPrefix) {
+		return sealedPlaceholder
+	}
+	encoded, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return stored
+	}
+	if s.sealer == nil {
+		return sealedPlaceholder
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return sealedPlaceholder
+	}
+	plain, err := s.sealer.Open(data)
+	if err != nil {
+		return sealedPlaceholder
+	}
+	return string(plain)
+}
+
+// isSealed reports whether a stored value is encrypted, under either key
+func isSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix) || strings.HasPrefix(value, legacySealedPrefix)
+}
+
+// embeddingText is what gets embedded for a fact. Encrypted values are left
+// out so the vector index can't be used to reconstruct them.
+func embeddingText(field, value string, sealed bool) string {
+	if sealed {
+		return field
+	}
+	return field + ": " + value
+}
+
+func (s *Store) sealSensitiveFacts() error {
+	rows, err := s.db.Query(`SELECT id, value FROM facts WHERE sensitive = 1 AND value NOT LIKE 'enc:v_:%'`)
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		id    int64
+		value string
+	}
+	var plain []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.value); err != nil {
+			rows.Close()
+			return err
+		}
+		plain = append(plain, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range plain {
+		sealed, err := s.sealValue(p.value)
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(`UPDATE facts SET value = ? WHERE id = ?`, sealed, p.id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResealLegacy re-encrypts values sealed under an older key, which old must
+// open, with the current Sealer. It returns how many values it moved; ones
+// old can't open are left as they are.
+func (s *Store) ResealLegacy(old Sealer) (int, error) {
+	if s.sealer == nil {
+		return 0, errors.New("no sealer set")
+	}
+
+	moved := 0
+	for _, column := range []struct{ table, key, value string }{
+		{"facts", "id", "value"},
+		{"contacts", "entity_id", "phone"},
+		{"contacts", "entity_id", "email"},
+	} {
+		rows, err := s.db.Query(`SELECT `+column.key+`, `+column.value+` FROM `+column.table+` WHERE `+column.value+` LIKE ?`, legacySealedPrefix+"%")
+		if err != nil {
+			return moved, err
+		}
+
+		type pending struct {
+			id    int64
+			value string
+		}
+		var legacy []pending
+		for rows.Next() {
+			var p pending
+			if err := rows.Scan(&p.id, &p.value); err != nil {
+				rows.Close()
+				return moved, err
+			}
+			legacy = append(legacy, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return moved, err
+		}
+
+		for _, p := range legacy {
+			data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(p.value, legacySealedPrefix))
+			if err != nil {
+				continue
+			}
+			plain, err := old.Open(data)
+			if err != nil {
+				continue
+			}
+			sealed, err := s.sealValue(string(plain))
+			if err != nil {
+				return moved, err
+			}
+			if _, err := s.db.Exec(`UPDATE `+column.table+` SET `+column.value+` = ? WHERE `+column.key+` = ?`, sealed, p.id); err != nil {
+				return moved, err
+			}
+			moved++
+		}
+	}
+
+	return moved, nil
+}
@@ -210,10 +210,30 @@ func (s *Store) DeleteMessagesForDate(sessionID, date string) error {
 type EntityResolver interface {
 	GetOrCreateUserEntity(sessionID string) int64
 	GetSheldonEntityID() int64
-	GetOrCreateNamedEntity(name, entityType string) int64
+	GetOrCreateNamedEntity(name, entityType string, ownerID int64) int64
 	ResolveEntityID(name, sessionID string, userID, sheldonID int64) int64
 }
 
+// SpeakerResolver is optionally implemented by an EntityResolver to attribute
+// facts in group chats to the participant who stated them
+type SpeakerResolver interface {
+	ResolveSpeaker(sessionID, name string) int64
+}
+
+// subjectEntityID picks the entity a fact is about: Sheldon, a named group member, or the session's user
+func subjectEntityID(resolver EntityResolver, sessionID, subject string, userID, sheldonID int64) int64 {
+	lower := strings.ToLower(subject)
+	if lower == "sheldon" || lower == "assistant" {
+		return sheldonID
+	}
+	if sr, ok := resolver.(SpeakerResolver); ok && lower != "user" && lower != "me" {
+		if id := sr.ResolveSpeaker(sessionID, subject); id != 0 {
+			return id
+		}
+	}
+	return userID
+}
+
 const endOfDayPrompt = `Analyze this day's conversations and provide two things:
 
 1. EXTRACTION: Extract facts and relationships worth remembering long-term.
@@ -238,6 +258,7 @@ Rules:
 - Only extract explicitly stated facts, never infer
 - If no facts worth extracting, use empty arrays
 - Summary should focus on: key topics, decisions made, plans mentioned, important information shared
+- In group chats each message starts with [Name]: - use that person's name as subject/source instead of "user"
 - If conversations had contradictions (e.g., "going to Portland" then "actually Seattle"), extract only the final/corrected value`
 
 // LLM interface for end-of-day processing
@@ -367,13 +388,7 @@ func (s *Store) processSessionEndOfDay(ctx context.Context, llm LLM, resolver En
 			domainID = 1
 		}
 
-		var entityID int64
-		subject := strings.ToLower(fact.Subject)
-		if subject == "sheldon" || subject == "assistant" {
-			entityID = sheldonEntityID
-		} else {
-			entityID = userEntityID
-		}
+		entityID := subjectEntityID(resolver, sessionID, fact.Subject, userEntityID, sheldonEntityID)
 
 		if entityID == 0 {
 			continue
@@ -388,7 +403,7 @@ func (s *Store) processSessionEndOfDay(ctx context.Context, llm LLM, resolver En
 	// Store extracted relationships
 	for _, rel := range result.Relationships {
 		sourceID := resolver.ResolveEntityID(rel.Source, sessionID, userEntityID, sheldonEntityID)
-		targetID := resolver.GetOrCreateNamedEntity(rel.Target, rel.TargetType)
+		targetID := resolver.GetOrCreateNamedEntity(rel.Target, rel.TargetType, userEntityID)
 
 		if sourceID == 0 || targetID == 0 {
 			continue
@@ -440,13 +455,7 @@ func (s *Store) ProcessEndOfDayForSession(ctx context.Context, llm LLM, resolver
 			domainID = 1
 		}
 
-		var entityID int64
-		subject := strings.ToLower(fact.Subject)
-		if subject == "sheldon" || subject == "assistant" {
-			entityID = sheldonEntityID
-		} else {
-			entityID = userEntityID
-		}
+		entityID := subjectEntityID(resolver, sessionID, fact.Subject, userEntityID, sheldonEntityID)
 
 		if entityID == 0 {
 			continue
@@ -458,7 +467,7 @@ func (s *Store) ProcessEndOfDayForSession(ctx context.Context, llm LLM, resolver
 	// Store extracted relationships
 	for _, rel := range result.Relationships {
 		sourceID := resolver.ResolveEntityID(rel.Source, sessionID, userEntityID, sheldonEntityID)
-		targetID := resolver.GetOrCreateNamedEntity(rel.Target, rel.TargetType)
+		targetID := resolver.GetOrCreateNamedEntity(rel.Target, rel.TargetType, userEntityID)
 
 		if sourceID == 0 || targetID == 0 {
 			continue
@@ -67,6 +67,9 @@ func (s *Store) Decay(cfg DecayConfig) (int64, error) {
 		conditions = append(conditions, fmt.Sprintf("created_at < datetime('now', '-%d days')", defaultDays))
 	}
 
+	// Pinned facts are kept however stale they get
+	conditions = append(conditions, "pinned = 0")
+
 	// Salience threshold condition
 	conditions = append(conditions, fmt.Sprintf("%s < ?", salienceSQL))
 	args = append(args, cfg.SalienceThreshold)
@@ -0,0 +1,91 @@
+package sheldonmem
+
+import (
+	"time"
+)
+
+// Digest collects what changed in memory for one user over a period
+type Digest struct {
+	Since         time.Time
+	Facts         []*Fact
+	Relationships []DigestRelation
+	Notes         []NoteInfo
+	Summaries     []DailySummary
+}
+
+// DigestRelation is an edge between two entities, by name
+type DigestRelation struct {
+	Source   string
+	Relation string
+	Target   string
+}
+
+// Empty reports whether nothing happened in the period
+func (d *Digest) Empty() bool {
+	return len(d.Facts) == 0 && len(d.Relationships) == 0 && len(d.Notes) == 0 && len(d.Summaries) == 0
+}
+
+// Digest gathers facts, relationships, notes and daily summaries added since the
+// given time. Facts and relationships are limited to entities owned by ownerID,
+// summaries to the session. Sensitive facts are left out since digests are pushed.
+func (s *Store) Digest(sessionID string, ownerID int64, since time.Time) (*Digest, error) {
+	cutoff := since.UTC().Format("2006-01-02 15:04:05")
+	d := &Digest{Since: since}
+
+	rows, err := s.db.Query(queryDigestFacts, ownerID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var f Fact
+		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		d.Facts = append(d.Facts, &f)
+	}
+	rows.Close()
+
+	rows, err = s.db.Query(queryDigestEdges, ownerID, ownerID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var r DigestRelation
+		if err := rows.Scan(&r.Source, &r.Relation, &r.Target); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		d.Relationships = append(d.Relationships, r)
+	}
+	rows.Close()
+
+	rows, err = s.db.Query(queryDigestNotes, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var n NoteInfo
+		if err := rows.Scan(&n.Key, &n.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		d.Notes = append(d.Notes, n)
+	}
+	rows.Close()
+
+	rows, err = s.db.Query(queryDigestSummaries, sessionID, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ds DailySummary
+		if err := rows.Scan(&ds.ID, &ds.SessionID, &ds.SummaryDate, &ds.Summary, &ds.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Summaries = append(d.Summaries, ds)
+	}
+
+	return d, rows.Err()
+}
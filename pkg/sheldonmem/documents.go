@@ -0,0 +1,181 @@
+package sheldonmem
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/ncruces"
+)
+
+// Document is a file indexed for search, such as an uploaded PDF
+type Document struct {
+	ID         int64
+	OwnerID    *int64
+	Name       string
+	Source     string // where it came from, e.g. "chat" or "storage:user/path.pdf"
+	ChunkCount int
+	CreatedAt  time.Time
+}
+
+// DocumentMatch is a chunk of a document that matched a search
+type DocumentMatch struct {
+	DocumentID   int64
+	DocumentName string
+	Seq          int
+	Content      string
+	Distance     float32
+}
+
+// AddDocument stores a document's text chunks and embeds them for search.
+// An ownerID of 0 makes the document visible to everyone.
+func (s *Store) AddDocument(ctx context.Context, ownerID int64, name, source string, chunks []string) (*Document, error) {
+	var owner any
+	if ownerID != 0 {
+		owner = ownerID
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(queryInsertDocument, owner, name, source, len(chunks))
+	if err != nil {
+		return nil, err
+	}
+	docID, _ := result.LastInsertId()
+
+	chunkIDs := make([]int64, len(chunks))
+	for i, chunk := range chunks {
+		res, err := tx.Exec(queryInsertDocumentChunk, docID, i, chunk)
+		if err != nil {
+			return nil, err
+		}
+		chunkIDs[i], _ = res.LastInsertId()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// embeddings go in after commit so a slow embedder doesn't hold the write lock
+	if embedder := s.getEmbedder(); embedder != nil {
+		for i, chunk := range chunks {
+			if err := s.embedChunk(ctx, embedder, chunkIDs[i], chunk); err != nil {
+				return nil, fmt.Errorf("embed chunk %d: %w", i, err)
+			}
+		}
+	}
+
+	doc := &Document{ID: docID, Name: name, Source: source, ChunkCount: len(chunks), CreatedAt: time.Now()}
+	if ownerID != 0 {
+		doc.OwnerID = &ownerID
+	}
+	return doc, nil
+}
+
+func (s *Store) embedChunk(ctx context.Context, embedder Embedder, chunkID int64, content string) error {
+	embedding, err := embedder.Embed(ctx, content)
+	if err != nil {
+		return err
+	}
+
+	blob, err := sqlite_vec.SerializeFloat32(embedding)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(queryInsertVecDocumentChunk, chunkID, blob)
+	return err
+}
+
+// ListDocuments returns the documents visible to an owner, newest first
+func (s *Store) ListDocuments(ownerID int64) ([]*Document, error) {
+	rows, err := s.db.Query(queryListDocuments, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var docs []*Document
+
+	for rows.Next() {
+		var d Document
+		var source sql.NullString
+		if err := rows.Scan(&d.ID, &d.OwnerID, &d.Name, &source, &d.ChunkCount, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Source = source.String
+		docs = append(docs, &d)
+	}
+
+	return docs, rows.Err()
+}
+
+// DeleteDocument removes a document, its chunks and their embeddings
+func (s *Store) DeleteDocument(id, ownerID int64) error {
+	if _, err := s.db.Exec(queryDeleteVecDocumentChunks, id, ownerID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(queryDeleteDocumentChunks, id, ownerID); err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(queryDeleteDocument, id, ownerID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("document %d not found", id)
+	}
+	return nil
+}
+
+// SearchDocuments finds the chunks most relevant to a query among documents
+// visible to the owner. Without an embedder it falls back to keyword matching.
+func (s *Store) SearchDocuments(ctx context.Context, ownerID int64, query string, limit int) ([]*DocumentMatch, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+
+	embedder := s.getEmbedder()
+	if embedder == nil {
+		return s.scanDocumentMatches(s.db.QueryContext(ctx, querySearchDocumentsKeyword, ownerID, "%"+query+"%", limit))
+	}
+
+	embedding, err := embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := sqlite_vec.SerializeFloat32(embedding)
+	if err != nil {
+		return nil, err
+	}
+
+	// over-fetch since knn runs before the owner filter
+	return s.scanDocumentMatches(s.db.QueryContext(ctx, querySearchDocumentsVec, blob, limit*4, ownerID, limit))
+}
+
+func (s *Store) scanDocumentMatches(rows *sql.Rows, err error) ([]*DocumentMatch, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var matches []*DocumentMatch
+
+	for rows.Next() {
+		var m DocumentMatch
+		if err := rows.Scan(&m.DocumentID, &m.DocumentName, &m.Seq, &m.Content, &m.Distance); err != nil {
+			return nil, err
+		}
+		matches = append(matches, &m)
+	}
+
+	return matches, rows.Err()
+}
@@ -17,11 +17,42 @@ func (s *Store) CreateEntity(name, entityType string, domainID int, metadata str
 	}, nil
 }
 
+// CreateOwnedEntity creates an entity private to ownerID, typically a user entity.
+// Facts on owned entities are hidden from other users' recall. An ownerID of 0 creates a shared entity.
+func (s *Store) CreateOwnedEntity(name, entityType string, domainID int, metadata string, ownerID int64) (*Entity, error) {
+	var owner *int64
+	if ownerID != 0 {
+		owner = &ownerID
+	}
+
+	result, err := s.db.Exec(queryInsertOwnedEntity, name, entityType, domainID, metadata, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+
+	return &Entity{
+		ID:         id,
+		Name:       name,
+		EntityType: entityType,
+		DomainID:   domainID,
+		Metadata:   metadata,
+		OwnerID:    owner,
+	}, nil
+}
+
+// SetEntityOwner scopes an entity to ownerID. A user entity owns itself.
+func (s *Store) SetEntityOwner(entityID, ownerID int64) error {
+	_, err := s.db.Exec(querySetEntityOwner, ownerID, entityID)
+	return err
+}
+
 func (s *Store) GetEntity(id int64) (*Entity, error) {
 	var e Entity
 	row := s.db.QueryRow(queryGetEntity, id)
 
-	err := row.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.CreatedAt, &e.UpdatedAt)
+	err := row.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.OwnerID, &e.CreatedAt, &e.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +64,7 @@ func (s *Store) FindEntityByName(name string) (*Entity, error) {
 	var e Entity
 	row := s.db.QueryRow(queryGetEntityByName, name)
 
-	err := row.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.CreatedAt, &e.UpdatedAt)
+	err := row.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.OwnerID, &e.CreatedAt, &e.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +72,41 @@ func (s *Store) FindEntityByName(name string) (*Entity, error) {
 	return &e, nil
 }
 
+// FindOwnedEntityByName finds an entity by name within ownerID's namespace,
+// preferring the owner's own entity over a shared one of the same name
+func (s *Store) FindOwnedEntityByName(name string, ownerID int64) (*Entity, error) {
+	var e Entity
+	row := s.db.QueryRow(queryGetOwnedEntity, name, ownerID)
+
+	err := row.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.OwnerID, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// foreignEntityIDs returns entities owned by someone other than ownerID
+func (s *Store) foreignEntityIDs(ownerID int64) (map[int64]bool, error) {
+	rows, err := s.db.Query(queryForeignEntities, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	foreign := make(map[int64]bool)
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		foreign[id] = true
+	}
+
+	return foreign, rows.Err()
+}
+
 func (s *Store) FindEntitiesByType(entityType string) ([]*Entity, error) {
 	rows, err := s.db.Query(queryGetEntitiesByType, entityType)
 	if err != nil {
@@ -52,7 +118,7 @@ func (s *Store) FindEntitiesByType(entityType string) ([]*Entity, error) {
 
 	for rows.Next() {
 		var e Entity
-		if err := rows.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.OwnerID, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, err
 		}
 		entities = append(entities, &e)
@@ -72,7 +138,7 @@ func (s *Store) SearchEntities(query string) ([]*Entity, error) {
 
 	for rows.Next() {
 		var e Entity
-		if err := rows.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Name, &e.EntityType, &e.DomainID, &e.Metadata, &e.OwnerID, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, err
 		}
 		entities = append(entities, &e)
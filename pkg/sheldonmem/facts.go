@@ -20,6 +20,7 @@ func (s *Store) AddFactWithContext(ctx context.Context, entityID *int64, domainI
 	var existingValue string
 
 	err := s.db.QueryRow(queryGetExistingFact, domainID, field, entityID).Scan(&existingID, &existingValue)
+	existingValue = s.openValue(existingValue)
 
 	if err == nil && existingValue != value {
 		// Same field, different value → supersede
@@ -43,8 +44,8 @@ func (s *Store) AddFactWithContext(ctx context.Context, entityID *int64, domainI
 	}
 
 	// 2. Check semantic similarity (different field, same meaning)
-	if s.embedder != nil && entityID != nil {
-		similar, err := s.findSimilarFact(ctx, *entityID, domainID, field, value)
+	if embedder := s.getEmbedder(); embedder != nil && entityID != nil {
+		similar, err := s.findSimilarFact(ctx, embedder, *entityID, domainID, field, value)
 		if err == nil && similar != nil {
 			if similar.Value == value {
 				// Same meaning, same value → touch existing
@@ -75,16 +76,37 @@ func (s *Store) AddFactWithContext(ctx context.Context, entityID *int64, domainI
 	return &FactResult{Fact: fact}, nil
 }
 
+// PinFact stores value word for word as a new fact. Pinned facts are never
+// superseded by a similar extracted fact and are exempt from decay, so what
+// the user pinned stays as they pinned it.
+func (s *Store) PinFact(ctx context.Context, entityID int64, domainID int, field, value string) (*Fact, error) {
+	fact, err := s.insertFact(ctx, &entityID, domainID, field, value, 1.0, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec(queryPinFact, fact.ID); err != nil {
+		return nil, err
+	}
+	return fact, nil
+}
+
 func (s *Store) insertFact(ctx context.Context, entityID *int64, domainID int, field, value string, confidence float64, supersedes *int64, sensitive bool) (*Fact, error) {
-	result, err := s.db.Exec(queryInsertFact, entityID, domainID, field, value, confidence, supersedes, sensitive)
+	stored := value
+	if sensitive {
+		var err error
+		if stored, err = s.sealValue(value); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.db.Exec(queryInsertFact, entityID, domainID, field, stored, confidence, supersedes, sensitive)
 	if err != nil {
 		return nil, err
 	}
 
 	id, _ := result.LastInsertId()
 
-	text := field + ": " + value
-	s.EmbedFact(ctx, id, text)
+	s.EmbedFact(ctx, id, embeddingText(field, value, stored != value))
 
 	return &Fact{
 		ID:         id,
@@ -99,12 +121,54 @@ func (s *Store) insertFact(ctx context.Context, entityID *int64, domainID int, f
 	}, nil
 }
 
-// MarkSensitive marks a fact as sensitive or not
+// MarkSensitive marks a fact as sensitive or not, encrypting or decrypting
+// its value when an encryption key is set
 func (s *Store) MarkSensitive(factID int64, sensitive bool) error {
-	_, err := s.db.Exec(queryMarkSensitive, sensitive, factID)
+	if s.sealer == nil {
+		_, err := s.db.Exec(queryMarkSensitive, sensitive, factID)
+		return err
+	}
+
+	var stored string
+	if err := s.db.QueryRow(queryGetFactValue, factID).Scan(&stored); err != nil {
+		return err
+	}
+
+	value := s.openValue(stored)
+	if sensitive {
+		var err error
+		if value, err = s.sealValue(value); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.db.Exec(queryMarkSensitiveValue, sensitive, value, factID)
 	return err
 }
 
+// SensitiveValues returns the plaintext values of active sensitive facts, so
+// callers can keep them out of logs
+func (s *Store) SensitiveValues() ([]string, error) {
+	rows, err := s.db.Query(querySensitiveValues)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var stored string
+		if err := rows.Scan(&stored); err != nil {
+			return nil, err
+		}
+		if value := s.openValue(stored); value != sealedPlaceholder {
+			values = append(values, value)
+		}
+	}
+
+	return values, rows.Err()
+}
+
 func (s *Store) GetFactsByDomain(domainID int) ([]*Fact, error) {
 	rows, err := s.db.Query(queryGetFactsByDomain, domainID)
 	if err != nil {
@@ -119,6 +183,7 @@ func (s *Store) GetFactsByDomain(domainID int) ([]*Fact, error) {
 		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt); err != nil {
 			return nil, err
 		}
+		f.Value = s.openValue(f.Value)
 
 		facts = append(facts, &f)
 	}
@@ -144,6 +209,7 @@ func (s *Store) GetFactsByEntity(entityID int64) ([]*Fact, error) {
 		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt); err != nil {
 			return nil, err
 		}
+		f.Value = s.openValue(f.Value)
 
 		facts = append(facts, &f)
 	}
@@ -157,9 +223,9 @@ func (s *Store) GetFactsByEntity(entityID int64) ([]*Fact, error) {
 
 const similarityThreshold = 0.15 // cosine distance, lower = more similar
 
-func (s *Store) findSimilarFact(ctx context.Context, entityID int64, domainID int, field, value string) (*Fact, error) {
+func (s *Store) findSimilarFact(ctx context.Context, embedder Embedder, entityID int64, domainID int, field, value string) (*Fact, error) {
 	text := field + ": " + value
-	embedding, err := s.embedder.Embed(ctx, text)
+	embedding, err := embedder.Embed(ctx, text)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +242,7 @@ func (s *Store) findSimilarFact(ctx context.Context, entityID int64, domainID in
 		FROM vec_facts v
 		JOIN facts f ON v.fact_id = f.id
 		WHERE f.active = 1
+		  AND f.pinned = 0
 		  AND f.entity_id = ?
 		  AND f.domain_id = ?
 		  AND v.embedding MATCH ?
@@ -191,6 +258,7 @@ func (s *Store) findSimilarFact(ctx context.Context, entityID int64, domainID in
 	if err != nil {
 		return nil, err
 	}
+	f.Value = s.openValue(f.Value)
 
 	if distance > similarityThreshold {
 		return nil, nil // not similar enough
@@ -213,6 +281,7 @@ func (s *Store) GetSupersededFacts(field string, entityID *int64) ([]*Fact, erro
 		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.CreatedAt); err != nil {
 			return nil, err
 		}
+		f.Value = s.openValue(f.Value)
 		facts = append(facts, &f)
 	}
 
@@ -244,6 +313,7 @@ func (s *Store) GetFactsByTimeRange(since, until string, excludeSensitive bool)
 		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt); err != nil {
 			return nil, err
 		}
+		f.Value = s.openValue(f.Value)
 		facts = append(facts, &f)
 	}
 	return facts, rows.Err()
@@ -288,6 +358,7 @@ func (s *Store) searchFacts(query string, domainIDs []int, excludeSensitive bool
 		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt); err != nil {
 			return nil, err
 		}
+		f.Value = s.openValue(f.Value)
 		facts = append(facts, &f)
 	}
 
@@ -0,0 +1,105 @@
+package sheldonmem
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrFactNotFound = errors.New("fact not found")
+
+// GetFact returns a single fact by ID, active or not
+func (s *Store) GetFact(id int64) (*Fact, error) {
+	var f Fact
+	err := s.db.QueryRow(queryGetFact, id).Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrFactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.Value = s.openValue(f.Value)
+	return &f, nil
+}
+
+// GetOwnedFacts returns active facts about entities owned by ownerID, including
+// the owner's own entity. A domainID of 0 returns every domain.
+func (s *Store) GetOwnedFacts(ownerID int64, domainID int) ([]*Fact, error) {
+	rows, err := s.db.Query(queryGetOwnedFacts, ownerID, domainID, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var facts []*Fact
+
+	for rows.Next() {
+		var f Fact
+		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		f.Value = s.openValue(f.Value)
+		facts = append(facts, &f)
+	}
+
+	return facts, rows.Err()
+}
+
+// ForgetFact soft-deletes a fact. It stops showing up in recall immediately
+// and can be restored until PurgeForgotten removes it for good.
+func (s *Store) ForgetFact(id int64) error {
+	result, err := s.db.Exec(queryForgetFact, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrFactNotFound
+	}
+
+	s.DeleteFactEmbedding(id)
+	return nil
+}
+
+// RestoreFact undoes ForgetFact if the fact was forgotten within window
+func (s *Store) RestoreFact(ctx context.Context, id int64, window time.Duration) error {
+	result, err := s.db.Exec(queryRestoreFact, id, sqliteCutoff(window))
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrFactNotFound
+	}
+
+	var field, value string
+	if err := s.db.QueryRow(`SELECT field, value FROM facts WHERE id = ?`, id).Scan(&field, &value); err != nil {
+		return err
+	}
+	sealed := isSealed(value)
+	s.EmbedFact(ctx, id, embeddingText(field, s.openValue(value), sealed))
+
+	return nil
+}
+
+// PurgeForgotten permanently deletes facts forgotten longer than window ago
+func (s *Store) PurgeForgotten(window time.Duration) (int64, error) {
+	cutoff := sqliteCutoff(window)
+
+	// keep supersede chains valid before the rows disappear
+	if _, err := s.db.Exec(queryUnlinkForgotten, cutoff); err != nil {
+		return 0, fmt.Errorf("unlink forgotten facts: %w", err)
+	}
+
+	result, err := s.db.Exec(queryPurgeForgotten, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// sqliteCutoff formats now-window the way datetime('now') stores timestamps
+func sqliteCutoff(window time.Duration) string {
+	return time.Now().UTC().Add(-window).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,277 @@
+package sheldonmem
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/ncruces"
+)
+
+// MediaItem is an archived photo or file with the caption and tags it was
+// indexed under
+type MediaItem struct {
+	ID        int64
+	OwnerID   *int64
+	Path      string // object name in the user's storage bucket
+	Filename  string
+	MimeType  string
+	Caption   string
+	Tags      []string
+	CreatedAt time.Time
+	Distance  float32 // set by SearchMedia when matched semantically
+}
+
+// MediaQuery narrows a media search. Zero times leave that end open.
+type MediaQuery struct {
+	Text   string
+	After  time.Time
+	Before time.Time
+	Limit  int
+}
+
+var vecDimsPattern = regexp.MustCompile(`FLOAT\[(\d+)\]`)
+
+// migrateMediaVectors creates vec_media at the same dimension as the other
+// vector tables, which may have been rebuilt by Reindex for another embedder
+func (s *Store) migrateMediaVectors() error {
+	dims := "768"
+	var create string
+	if err := s.db.QueryRow(queryVecFactsSQL).Scan(&create); err == nil {
+		if m := vecDimsPattern.FindStringSubmatch(create); m != nil {
+			dims = m[1]
+		}
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS vec_media USING vec0(media_id INTEGER PRIMARY KEY, embedding FLOAT[%s])", dims))
+	return err
+}
+
+// AddMedia records an archived file and embeds its caption and tags for
+// search. An ownerID of 0 makes it visible to everyone.
+func (s *Store) AddMedia(ctx context.Context, ownerID int64, path, filename, mimeType, caption string, tags []string) (*MediaItem, error) {
+	var owner any
+	if ownerID != 0 {
+		owner = ownerID
+	}
+
+	tags = normalizeTags(tags)
+	result, err := s.db.ExecContext(ctx, queryInsertMedia, owner, path, filename, mimeType, caption, strings.Join(tags, ","))
+	if err != nil {
+		return nil, err
+	}
+	id, _ := result.LastInsertId()
+
+	if embedder := s.getEmbedder(); embedder != nil {
+		if err := s.embedMedia(ctx, embedder, id, mediaText(caption, tags)); err != nil {
+			return nil, fmt.Errorf("embed media: %w", err)
+		}
+	}
+
+	item := &MediaItem{ID: id, Path: path, Filename: filename, MimeType: mimeType, Caption: caption, Tags: tags, CreatedAt: time.Now()}
+	if ownerID != 0 {
+		item.OwnerID = &ownerID
+	}
+	return item, nil
+}
+
+func (s *Store) embedMedia(ctx context.Context, embedder Embedder, id int64, text string) error {
+	embedding, err := embedder.Embed(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	blob, err := sqlite_vec.SerializeFloat32(embedding)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(queryInsertVecMedia, id, blob)
+	return err
+}
+
+// GetMedia returns one of the owner's archived files
+func (s *Store) GetMedia(id, ownerID int64) (*MediaItem, error) {
+	items, err := s.scanMedia(s.db.Query(queryGetMedia, id, ownerOrNil(ownerID)))
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("media %d not found", id)
+	}
+	return items[0], nil
+}
+
+// SearchMedia finds the owner's archived files that best match a query
+// within a date range. With an embedder the caption and tags are matched
+// semantically, otherwise by the query's words. An empty query lists the
+// range newest first.
+func (s *Store) SearchMedia(ctx context.Context, ownerID int64, q MediaQuery) ([]*MediaItem, error) {
+	if q.Limit <= 0 {
+		q.Limit = 5
+	}
+
+	after, before := mediaRange(q.After, q.Before)
+	owner := ownerOrNil(ownerID)
+
+	embedder := s.getEmbedder()
+	if embedder == nil || strings.TrimSpace(q.Text) == "" {
+		items, err := s.scanMedia(s.db.QueryContext(ctx, queryListMediaBetween, owner, after, before))
+		if err != nil {
+			return nil, err
+		}
+		return rankMediaByWords(items, q.Text, q.Limit), nil
+	}
+
+	embedding, err := embedder.Embed(ctx, q.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := sqlite_vec.SerializeFloat32(embedding)
+	if err != nil {
+		return nil, err
+	}
+
+	// over-fetch since knn runs before the owner and date filters
+	rows, err := s.db.QueryContext(ctx, querySearchMediaVec, blob, q.Limit*10, owner, after, before, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	var distances []float32
+	for rows.Next() {
+		var id int64
+		var distance float32
+		if err := rows.Scan(&id, &distance); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		distances = append(distances, distance)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	items := make([]*MediaItem, 0, len(ids))
+	for i, id := range ids {
+		item, err := s.GetMedia(id, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		item.Distance = distances[i]
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *Store) scanMedia(rows *sql.Rows, err error) ([]*MediaItem, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var items []*MediaItem
+
+	for rows.Next() {
+		var m MediaItem
+		var filename, mimeType, caption, tags sql.NullString
+		if err := rows.Scan(&m.ID, &m.OwnerID, &m.Path, &filename, &mimeType, &caption, &tags, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.Filename, m.MimeType, m.Caption = filename.String, mimeType.String, caption.String
+		if tags.String != "" {
+			m.Tags = strings.Split(tags.String, ",")
+		}
+		items = append(items, &m)
+	}
+
+	return items, rows.Err()
+}
+
+// rankMediaByWords orders items by how many of the query's words appear in
+// their caption, tags or filename, dropping items that match none
+func rankMediaByWords(items []*MediaItem, query string, limit int) []*MediaItem {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		if len(items) > limit {
+			items = items[:limit]
+		}
+		return items
+	}
+
+	type scored struct {
+		item  *MediaItem
+		score int
+	}
+	var matches []scored
+	for _, item := range items {
+		text := strings.ToLower(mediaText(item.Caption, item.Tags) + " " + item.Filename)
+		score := 0
+		for _, w := range words {
+			if strings.Contains(text, w) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{item, score})
+		}
+	}
+
+	// stable keeps newest first among equal scores
+	slices.SortStableFunc(matches, func(a, b scored) int { return b.score - a.score })
+
+	ranked := make([]*MediaItem, 0, min(limit, len(matches)))
+	for _, m := range matches {
+		if len(ranked) == limit {
+			break
+		}
+		ranked = append(ranked, m.item)
+	}
+	return ranked
+}
+
+// mediaRange turns an open-ended range into bounds SQLite's datetime text
+// compares against
+func mediaRange(after, before time.Time) (string, string) {
+	const layout = "2006-01-02 15:04:05"
+	lo, hi := "0000-01-01 00:00:00", "9999-12-31 23:59:59"
+	if !after.IsZero() {
+		lo = after.UTC().Format(layout)
+	}
+	if !before.IsZero() {
+		hi = before.UTC().Format(layout)
+	}
+	return lo, hi
+}
+
+func mediaText(caption string, tags []string) string {
+	return strings.TrimSpace(caption + " " + strings.Join(tags, " "))
+}
+
+// normalizeTags lowercases tags and drops blanks and duplicates; commas are
+// the stored separator so they can't appear inside a tag
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, ",", " ")))
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+func ownerOrNil(ownerID int64) any {
+	if ownerID == 0 {
+		return nil
+	}
+	return ownerID
+}
@@ -13,7 +13,8 @@ const (
 	TierArchive = "archive"
 )
 
-// SaveNote creates or updates a working note
+// SaveNote creates or updates a working note. Notes have no owner: every
+// user sees the same ones, unlike facts, which recall scopes per user.
 func (s *Store) SaveNote(key, content string) error {
 	_, err := s.db.Exec(`
 		INSERT INTO notes (key, content, tier, updated_at)
@@ -67,7 +68,8 @@ func (s *Store) ListNotes() ([]string, error) {
 	return keys, rows.Err()
 }
 
-// ListNotesWithAge returns working note keys with age for system prompt
+// ListNotesWithAge returns working note keys with age for system prompt,
+// the same for every user
 func (s *Store) ListNotesWithAge() ([]NoteInfo, error) {
 	rows, err := s.db.Query(`SELECT key, updated_at FROM notes WHERE tier = 'working' ORDER BY updated_at DESC`)
 	if err != nil {
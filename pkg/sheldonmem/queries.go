@@ -8,24 +8,75 @@ const (
 	queryGetDomainBySlug = `SELECT id, name, slug, layer FROM domains WHERE slug = ?`
 
 	queryInsertEntity       = `INSERT INTO entities (name, entity_type, domain_id, metadata) VALUES (?, ?, ?, ?)`
-	queryGetEntity          = `SELECT id, name, entity_type, domain_id, metadata, created_at, updated_at FROM entities WHERE id = ?`
-	queryGetEntityByName    = `SELECT id, name, entity_type, domain_id, metadata, created_at, updated_at FROM entities WHERE name = ?`
-	queryGetEntitiesByType  = `SELECT id, name, entity_type, domain_id, metadata, created_at, updated_at FROM entities WHERE entity_type = ?`
-	querySearchEntities     = `SELECT id, name, entity_type, domain_id, metadata, created_at, updated_at FROM entities WHERE name LIKE ? LIMIT 10`
+	queryGetEntity          = `SELECT id, name, entity_type, domain_id, metadata, owner_id, created_at, updated_at FROM entities WHERE id = ?`
+	queryGetEntityByName    = `SELECT id, name, entity_type, domain_id, metadata, owner_id, created_at, updated_at FROM entities WHERE name = ?`
+	queryGetEntitiesByType  = `SELECT id, name, entity_type, domain_id, metadata, owner_id, created_at, updated_at FROM entities WHERE entity_type = ?`
+	querySearchEntities     = `SELECT id, name, entity_type, domain_id, metadata, owner_id, created_at, updated_at FROM entities WHERE name LIKE ? LIMIT 10`
+	queryGetOwnedEntity     = `SELECT id, name, entity_type, domain_id, metadata, owner_id, created_at, updated_at FROM entities WHERE name = ? AND (owner_id = ? OR owner_id IS NULL) ORDER BY owner_id IS NULL LIMIT 1`
+	queryInsertOwnedEntity  = `INSERT INTO entities (name, entity_type, domain_id, metadata, owner_id) VALUES (?, ?, ?, ?, ?)`
+	querySetEntityOwner     = `UPDATE entities SET owner_id = ?, updated_at = datetime('now') WHERE id = ?`
+	queryForeignEntities    = `SELECT id FROM entities WHERE owner_id IS NOT NULL AND owner_id != ?`
 	queryGetConnectedFromTo = `SELECT id, source_id, target_id, relation, strength, metadata, created_at FROM edges WHERE source_id = ? OR target_id = ? ORDER BY strength DESC`
 
 	queryGetExistingFact   = `SELECT id, value FROM facts WHERE domain_id = ? AND field = ? AND entity_id IS ? AND active = 1`
 	queryDeactivateFact    = `UPDATE facts SET active = 0 WHERE id = ?`
 	queryTouchFact         = `UPDATE facts SET access_count = access_count + 1, last_accessed = datetime('now') WHERE id = ?`
+	queryPinFact           = `UPDATE facts SET pinned = 1 WHERE id = ?`
 	queryInsertFact        = `INSERT INTO facts (entity_id, domain_id, field, value, confidence, supersedes, sensitive) VALUES (?, ?, ?, ?, ?, ?, ?)`
 	queryMarkSensitive     = `UPDATE facts SET sensitive = ? WHERE id = ?`
+	queryMarkSensitiveValue = `UPDATE facts SET sensitive = ?, value = ? WHERE id = ?`
+	querySensitiveValues    = `SELECT value FROM facts WHERE sensitive = 1 AND active = 1`
+	queryGetFactValue       = `SELECT value FROM facts WHERE id = ?`
 	queryGetFactsByDomain  = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, sensitive, created_at FROM facts WHERE domain_id = ? AND active = 1`
 	queryGetFactsByEntity  = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, sensitive, created_at FROM facts WHERE entity_id = ? AND active = 1`
 	querySearchFactsPrefix = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, sensitive, created_at FROM facts WHERE active = 1 AND (value LIKE ? OR field LIKE ?) AND domain_id IN (`
 	querySearchFactsSuffix = `) ORDER BY (confidence * 0.7 + (1.0 / (julianday('now') - julianday(COALESCE(last_accessed, created_at)) + 1)) * 0.3) DESC LIMIT 20`
 	querySearchFactsSafePrefix = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, sensitive, created_at FROM facts WHERE active = 1 AND sensitive = 0 AND (value LIKE ? OR field LIKE ?) AND domain_id IN (`
 
-	queryGetSupersededFacts = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, created_at FROM facts WHERE active = 0 AND field = ? AND entity_id IS ? ORDER BY created_at DESC LIMIT 3`
+	queryGetFact         = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, sensitive, created_at FROM facts WHERE id = ?`
+	queryGetOwnedFacts   = `SELECT f.id, f.entity_id, f.domain_id, f.field, f.value, f.confidence, f.access_count, f.active, f.sensitive, f.created_at FROM facts f JOIN entities e ON f.entity_id = e.id WHERE f.active = 1 AND e.owner_id = ? AND (? = 0 OR f.domain_id = ?) ORDER BY f.domain_id, f.created_at DESC`
+	queryForgetFact      = `UPDATE facts SET active = 0, forgotten_at = datetime('now') WHERE id = ? AND active = 1`
+	queryRestoreFact     = `UPDATE facts SET active = 1, forgotten_at = NULL WHERE id = ? AND forgotten_at >= ?`
+	queryUnlinkForgotten = `UPDATE facts SET supersedes = NULL WHERE supersedes IN (SELECT id FROM facts WHERE forgotten_at < ?)`
+	queryPurgeForgotten  = `DELETE FROM facts WHERE forgotten_at < ?`
+
+	queryDigestFacts     = `SELECT f.id, f.entity_id, f.domain_id, f.field, f.value, f.confidence, f.access_count, f.active, f.sensitive, f.created_at FROM facts f JOIN entities e ON f.entity_id = e.id WHERE f.active = 1 AND f.sensitive = 0 AND e.owner_id = ? AND f.created_at >= ? ORDER BY f.domain_id, f.created_at`
+	queryDigestEdges     = `SELECT s.name, ed.relation, t.name FROM edges ed JOIN entities s ON ed.source_id = s.id JOIN entities t ON ed.target_id = t.id WHERE (s.owner_id = ? OR t.owner_id = ?) AND ed.created_at >= ? ORDER BY ed.created_at`
+	queryDigestNotes     = `SELECT key, updated_at FROM notes WHERE tier = 'working' AND updated_at >= ? ORDER BY updated_at DESC`
+	queryDigestSummaries = `SELECT id, session_id, summary_date, summary, created_at FROM daily_summaries WHERE session_id = ? AND summary_date >= ? ORDER BY summary_date`
+
+	queryInsertConflict  = `INSERT OR IGNORE INTO fact_conflicts (fact_a, fact_b, reason) VALUES (?, ?, ?)`
+	queryGetConflict     = `SELECT fact_a, fact_b, status FROM fact_conflicts WHERE id = ?`
+	queryResolveConflict = `UPDATE fact_conflicts SET status = ?, resolved_at = datetime('now') WHERE id = ?`
+	querySupersedeFact   = `UPDATE facts SET supersedes = ? WHERE id = ? AND supersedes IS NULL`
+
+	queryInsertDocument          = `INSERT INTO documents (owner_id, name, source, chunk_count) VALUES (?, ?, ?, ?)`
+	queryInsertDocumentChunk     = `INSERT INTO document_chunks (document_id, seq, content) VALUES (?, ?, ?)`
+	queryInsertVecDocumentChunk  = `INSERT INTO vec_document_chunks (chunk_id, embedding) VALUES (?, ?)`
+	queryListDocuments           = `SELECT id, owner_id, name, source, chunk_count, created_at FROM documents WHERE owner_id = ? OR owner_id IS NULL ORDER BY created_at DESC`
+	queryDeleteVecDocumentChunks = `DELETE FROM vec_document_chunks WHERE chunk_id IN (SELECT c.id FROM document_chunks c JOIN documents d ON c.document_id = d.id WHERE d.id = ? AND d.owner_id IS ?)`
+	queryDeleteDocumentChunks    = `DELETE FROM document_chunks WHERE document_id IN (SELECT id FROM documents WHERE id = ? AND owner_id IS ?)`
+	queryDeleteDocument          = `DELETE FROM documents WHERE id = ? AND owner_id IS ?`
+	querySearchDocumentsKeyword  = `SELECT d.id, d.name, c.seq, c.content, 0.0 FROM document_chunks c JOIN documents d ON c.document_id = d.id WHERE (d.owner_id = ? OR d.owner_id IS NULL) AND c.content LIKE ? ORDER BY d.created_at DESC, c.seq LIMIT ?`
+	querySearchDocumentsVec      = `SELECT d.id, d.name, c.seq, c.content, v.distance FROM (SELECT chunk_id, distance FROM vec_document_chunks WHERE embedding MATCH ? AND k = ?) v JOIN document_chunks c ON c.id = v.chunk_id JOIN documents d ON c.document_id = d.id WHERE d.owner_id = ? OR d.owner_id IS NULL ORDER BY v.distance LIMIT ?`
+
+	queryInsertMedia       = `INSERT INTO media (owner_id, path, filename, mime_type, caption, tags) VALUES (?, ?, ?, ?, ?, ?)`
+	queryInsertVecMedia    = `INSERT INTO vec_media (media_id, embedding) VALUES (?, ?)`
+	queryGetMedia          = `SELECT id, owner_id, path, filename, mime_type, caption, tags, created_at FROM media WHERE id = ? AND owner_id IS ?`
+	queryListMediaBetween  = `SELECT id, owner_id, path, filename, mime_type, caption, tags, created_at FROM media WHERE owner_id IS ? AND created_at >= ? AND created_at < ? ORDER BY created_at DESC`
+	querySearchMediaVec    = `SELECT m.id, v.distance FROM (SELECT media_id, distance FROM vec_media WHERE embedding MATCH ? AND k = ?) v JOIN media m ON m.id = v.media_id WHERE m.owner_id IS ? AND m.created_at >= ? AND m.created_at < ? ORDER BY v.distance LIMIT ?`
+	queryVecFactsSQL       = `SELECT sql FROM sqlite_master WHERE name = 'vec_facts'`
+
+	queryUpsertContact = `INSERT INTO contacts (entity_id, owner_id, phone, email, birthday) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(entity_id) DO UPDATE SET
+			phone = COALESCE(NULLIF(excluded.phone, ''), phone),
+			email = COALESCE(NULLIF(excluded.email, ''), email),
+			birthday = COALESCE(NULLIF(excluded.birthday, ''), birthday),
+			updated_at = datetime('now')`
+	queryGetContact   = `SELECT c.entity_id, e.name, c.phone, c.email, c.birthday, c.updated_at FROM contacts c JOIN entities e ON e.id = c.entity_id WHERE c.entity_id = ?`
+	queryListContacts = `SELECT c.entity_id, e.name, c.phone, c.email, c.birthday, c.updated_at FROM contacts c JOIN entities e ON e.id = c.entity_id WHERE c.owner_id IS ? ORDER BY e.name COLLATE NOCASE`
+
+	queryGetSupersededFacts = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, created_at FROM facts WHERE active = 0 AND forgotten_at IS NULL AND field = ? AND entity_id IS ? ORDER BY created_at DESC LIMIT 3`
 
 	queryGetFactsByTimeRange     = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, sensitive, created_at FROM facts WHERE active = 1 AND created_at >= ? AND created_at < ? ORDER BY created_at DESC LIMIT 50`
 	queryGetFactsByTimeRangeSafe = `SELECT id, entity_id, domain_id, field, value, confidence, access_count, active, sensitive, created_at FROM facts WHERE active = 1 AND sensitive = 0 AND created_at >= ? AND created_at < ? ORDER BY created_at DESC LIMIT 50`
@@ -40,3 +91,24 @@ const (
 
 	queryTouchFacts = `UPDATE facts SET access_count = access_count + 1, last_accessed = datetime('now') WHERE id IN (%s)`
 )
+
+// conversation search: summaries and messages ranked together by bm25,
+// lowest first. Dates are compared as YYYY-MM-DD text, before is exclusive.
+const querySearchConversations = `
+SELECT 'summary', ds.session_id, '', date(ds.summary_date), datetime(ds.created_at),
+       snippet(fts_summaries, 0, '**', '**', '…', 24), bm25(fts_summaries)
+FROM fts_summaries
+JOIN daily_summaries ds ON ds.id = fts_summaries.rowid
+WHERE fts_summaries MATCH ?
+  AND (? = '' OR ds.session_id = ?)
+  AND date(ds.summary_date) >= ? AND date(ds.summary_date) < ?
+UNION ALL
+SELECT 'message', m.session_id, m.role, m.date, datetime(m.created_at),
+       snippet(fts_messages, 0, '**', '**', '…', 24), bm25(fts_messages)
+FROM fts_messages
+JOIN daily_messages m ON m.id = fts_messages.rowid
+WHERE fts_messages MATCH ?
+  AND (? = '' OR m.session_id = ?)
+  AND m.date >= ? AND m.date < ?
+ORDER BY 7
+LIMIT ?`
@@ -15,6 +15,7 @@ type RecallOptions struct {
 	ExcludeSensitive bool       // if true, exclude sensitive facts from results
 	Since            *time.Time // only facts created after this time
 	Until            *time.Time // only facts created before this time
+	OwnerID          int64      // if set, hide facts and entities private to other users
 }
 
 func (s *Store) Recall(ctx context.Context, query string, domainIDs []int, limit int) (*RecallResult, error) {
@@ -82,6 +83,15 @@ func (s *Store) RecallWithOptions(ctx context.Context, query string, domainIDs [
 			facts = filtered
 		}
 	}
+
+	var foreign map[int64]bool
+	if opts.OwnerID != 0 {
+		foreign, err = s.foreignEntityIDs(opts.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		facts = withoutForeign(facts, foreign)
+	}
 	result.Facts = facts
 
 	// Track salience: increment access_count for recalled facts
@@ -102,7 +112,7 @@ func (s *Store) RecallWithOptions(ctx context.Context, query string, domainIDs [
 	// 3. Traverse from each found entity with specified depth
 	seen := make(map[int64]bool)
 	for _, entity := range entities {
-		if seen[entity.ID] {
+		if seen[entity.ID] || foreign[entity.ID] {
 			continue
 		}
 
@@ -112,6 +122,9 @@ func (s *Store) RecallWithOptions(ctx context.Context, query string, domainIDs [
 		}
 
 		for _, t := range traversal {
+			if foreign[t.Entity.ID] {
+				continue
+			}
 			if !seen[t.Entity.ID] {
 				seen[t.Entity.ID] = true
 				result.Entities = append(result.Entities, t)
@@ -121,3 +134,21 @@ func (s *Store) RecallWithOptions(ctx context.Context, query string, domainIDs [
 
 	return result, nil
 }
+
+// withoutForeign drops facts attached to entities owned by other users. Facts
+// with no entity belong to nobody, so once there is more than one user they
+// are dropped too rather than shown to everyone.
+func withoutForeign(facts []*Fact, foreign map[int64]bool) []*Fact {
+	if len(foreign) == 0 {
+		return facts
+	}
+
+	scoped := make([]*Fact, 0, len(facts))
+	for _, f := range facts {
+		if f.EntityID == nil || foreign[*f.EntityID] {
+			continue
+		}
+		scoped = append(scoped, f)
+	}
+	return scoped
+}
@@ -0,0 +1,186 @@
+package sheldonmem
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Conflict is a pair of active facts about the same entity that can't both be true
+type Conflict struct {
+	ID     int64
+	A      *Fact
+	B      *Fact
+	Reason string
+}
+
+// ConflictResolution says which side of a conflict to keep
+type ConflictResolution int
+
+const (
+	KeepBoth ConflictResolution = iota
+	KeepA
+	KeepB
+)
+
+var ErrConflictResolved = errors.New("conflict already resolved")
+
+const contradictionPrompt = `You check a person's memory for contradictions.
+
+You get facts as lines of "id | field | value". Two facts contradict when they can't both be true right now, even though their fields differ (e.g. "employer: Acme" and "works_at: Globex", or "city: Berlin" and "lives_in: Lisbon").
+Facts that merely overlap or add detail are NOT contradictions.
+
+Respond with JSON only:
+{"conflicts": [{"a": <id>, "b": <id>, "reason": "<short explanation>"}]}
+
+If there are none, respond with {"conflicts": []}`
+
+// FindContradictions asks the LLM to compare an entity's active facts within each
+// domain and records new conflicts. Pairs already recorded are not returned again.
+func (s *Store) FindContradictions(ctx context.Context, llm LLM, entityID int64) ([]*Conflict, error) {
+	facts, err := s.GetFactsByEntity(entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	byDomain := make(map[int][]*Fact)
+	for _, f := range facts {
+		if !f.Sensitive {
+			byDomain[f.DomainID] = append(byDomain[f.DomainID], f)
+		}
+	}
+
+	var conflicts []*Conflict
+	for _, group := range byDomain {
+		if len(group) < 2 {
+			continue
+		}
+
+		found, err := s.findDomainContradictions(ctx, llm, group)
+		if err != nil {
+			return conflicts, err
+		}
+		conflicts = append(conflicts, found...)
+	}
+
+	return conflicts, nil
+}
+
+func (s *Store) findDomainContradictions(ctx context.Context, llm LLM, facts []*Fact) ([]*Conflict, error) {
+	byID := make(map[int64]*Fact, len(facts))
+	var lines []string
+	for _, f := range facts {
+		byID[f.ID] = f
+		lines = append(lines, fmt.Sprintf("%d | %s | %s", f.ID, f.Field, f.Value))
+	}
+
+	response, err := llm.Chat(ctx, contradictionPrompt, []LLMMessage{{Role: "user", Content: strings.Join(lines, "\n")}})
+	if err != nil {
+		return nil, fmt.Errorf("contradiction check: %w", err)
+	}
+
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object found")
+	}
+
+	var result struct {
+		Conflicts []struct {
+			A      int64  `json:"a"`
+			B      int64  `json:"b"`
+			Reason string `json:"reason"`
+		} `json:"conflicts"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &result); err != nil {
+		return nil, err
+	}
+
+	var conflicts []*Conflict
+	for _, c := range result.Conflicts {
+		a, b := byID[c.A], byID[c.B]
+		if a == nil || b == nil || a.ID == b.ID {
+			continue // ignore IDs the model made up
+		}
+		if a.ID > b.ID {
+			a, b = b, a
+		}
+
+		res, err := s.db.Exec(queryInsertConflict, a.ID, b.ID, c.Reason)
+		if err != nil {
+			return conflicts, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue // already asked about this pair
+		}
+
+		id, _ := res.LastInsertId()
+		conflicts = append(conflicts, &Conflict{ID: id, A: a, B: b, Reason: c.Reason})
+	}
+
+	return conflicts, nil
+}
+
+// GetConflict loads a conflict and the two facts it is about
+func (s *Store) GetConflict(conflictID int64) (*Conflict, error) {
+	var factA, factB int64
+	var status string
+	err := s.db.QueryRow(queryGetConflict, conflictID).Scan(&factA, &factB, &status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("conflict %d not found", conflictID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.GetFact(factA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.GetFact(factB)
+	if err != nil {
+		return nil, err
+	}
+	return &Conflict{ID: conflictID, A: a, B: b}, nil
+}
+
+// ResolveConflict applies the user's answer. The dropped fact is deactivated and
+// the kept one is marked as superseding it, like a field update would.
+func (s *Store) ResolveConflict(conflictID int64, resolution ConflictResolution) error {
+	var factA, factB int64
+	var status string
+	err := s.db.QueryRow(queryGetConflict, conflictID).Scan(&factA, &factB, &status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("conflict %d not found", conflictID)
+	}
+	if err != nil {
+		return err
+	}
+	if status != "pending" {
+		return ErrConflictResolved
+	}
+
+	keep, drop := factA, factB
+	switch resolution {
+	case KeepB:
+		keep, drop = factB, factA
+	case KeepBoth:
+		_, err := s.db.Exec(queryResolveConflict, "dismissed", conflictID)
+		return err
+	}
+
+	if _, err := s.db.Exec(queryDeactivateFact, drop); err != nil {
+		return err
+	}
+	s.DeleteFactEmbedding(drop)
+
+	if _, err := s.db.Exec(querySupersedeFact, drop, keep); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(queryResolveConflict, "resolved", conflictID)
+	return err
+}
@@ -0,0 +1,174 @@
+package sheldonmem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/ncruces"
+)
+
+var ErrNoEmbedder = errors.New("no embedder configured")
+
+// reindexSource is one vector table and the rows that feed it
+type reindexSource struct {
+	stage  string
+	table  string
+	key    string
+	query  string // selects id, field, text for rows with id > ?
+	isFact bool
+}
+
+var reindexSources = []reindexSource{
+	{stage: "facts", table: "vec_facts", key: "fact_id", isFact: true,
+		query: `SELECT id, field, value FROM facts WHERE active = 1 AND id > ? ORDER BY id`},
+	{stage: "summaries", table: "vec_summaries", key: "summary_id",
+		query: `SELECT id, '', summary FROM daily_summaries WHERE id > ? ORDER BY id`},
+	{stage: "documents", table: "vec_document_chunks", key: "chunk_id",
+		query: `SELECT id, '', content FROM document_chunks WHERE id > ? ORDER BY id`},
+	{stage: "media", table: "vec_media", key: "media_id",
+		query: `SELECT id, '', COALESCE(caption, '') || ' ' || COALESCE(tags, '') FROM media WHERE id > ? ORDER BY id`},
+}
+
+type reindexItem struct {
+	id   int64
+	text string
+}
+
+type reindexVector struct {
+	id   int64
+	blob []byte
+}
+
+// Reindex re-embeds every fact, summary, document chunk and media caption with a
+// new embedder, rebuilds the vector tables at its dimension and switches the
+// store over to it.
+// Searches keep using the old vectors until everything is embedded, so it can run
+// in the background; on error the old index is left untouched.
+func (s *Store) Reindex(ctx context.Context, embedder Embedder, progress func(ReindexProgress)) error {
+	if embedder == nil {
+		return ErrNoEmbedder
+	}
+	if progress == nil {
+		progress = func(ReindexProgress) {}
+	}
+
+	probe, err := embedder.Embed(ctx, "dimension probe")
+	if err != nil {
+		return fmt.Errorf("probe embedder: %w", err)
+	}
+	if len(probe) == 0 {
+		return fmt.Errorf("embedder returned an empty vector")
+	}
+
+	vectors := make([][]reindexVector, len(reindexSources))
+	lastIDs := make([]int64, len(reindexSources))
+
+	for i, src := range reindexSources {
+		items, err := s.reindexItems(src, 0)
+		if err != nil {
+			return fmt.Errorf("load %s: %w", src.stage, err)
+		}
+
+		progress(ReindexProgress{Stage: src.stage, Total: len(items)})
+		for n, item := range items {
+			v, err := embedForReindex(ctx, embedder, item, len(probe))
+			if err != nil {
+				return fmt.Errorf("embed %s %d: %w", src.stage, item.id, err)
+			}
+			vectors[i] = append(vectors[i], v)
+			lastIDs[i] = item.id
+			progress(ReindexProgress{Stage: src.stage, Done: n + 1, Total: len(items)})
+		}
+	}
+
+	// pick up anything written while the first pass was running
+	for i, src := range reindexSources {
+		items, err := s.reindexItems(src, lastIDs[i])
+		if err != nil {
+			return fmt.Errorf("load %s: %w", src.stage, err)
+		}
+		for _, item := range items {
+			v, err := embedForReindex(ctx, embedder, item, len(probe))
+			if err != nil {
+				return fmt.Errorf("embed %s %d: %w", src.stage, item.id, err)
+			}
+			vectors[i] = append(vectors[i], v)
+		}
+	}
+
+	if err := s.replaceVectors(ctx, len(probe), vectors); err != nil {
+		return fmt.Errorf("replace vectors: %w", err)
+	}
+
+	s.SetEmbedder(embedder)
+	return nil
+}
+
+func (s *Store) reindexItems(src reindexSource, afterID int64) ([]reindexItem, error) {
+	rows, err := s.db.Query(src.query, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []reindexItem
+	for rows.Next() {
+		var item reindexItem
+		var field string
+		if err := rows.Scan(&item.id, &field, &item.text); err != nil {
+			return nil, err
+		}
+		if src.isFact {
+			item.text = embeddingText(field, item.text, isSealed(item.text))
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func embedForReindex(ctx context.Context, embedder Embedder, item reindexItem, dims int) (reindexVector, error) {
+	embedding, err := embedder.Embed(ctx, item.text)
+	if err != nil {
+		return reindexVector{}, err
+	}
+	if len(embedding) != dims {
+		return reindexVector{}, fmt.Errorf("got %d dimensions, expected %d", len(embedding), dims)
+	}
+
+	blob, err := sqlite_vec.SerializeFloat32(embedding)
+	if err != nil {
+		return reindexVector{}, err
+	}
+	return reindexVector{id: item.id, blob: blob}, nil
+}
+
+// replaceVectors recreates every vector table at the new dimension in one transaction
+func (s *Store) replaceVectors(ctx context.Context, dims int, vectors [][]reindexVector) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, src := range reindexSources {
+		if _, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+src.table); err != nil {
+			return err
+		}
+
+		create := fmt.Sprintf("CREATE VIRTUAL TABLE %s USING vec0(%s INTEGER PRIMARY KEY, embedding FLOAT[%d])", src.table, src.key, dims)
+		if _, err := tx.ExecContext(ctx, create); err != nil {
+			return err
+		}
+
+		insert := fmt.Sprintf("INSERT INTO %s (%s, embedding) VALUES (?, ?)", src.table, src.key)
+		for _, v := range vectors[i] {
+			if _, err := tx.ExecContext(ctx, insert, v.id, v.blob); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
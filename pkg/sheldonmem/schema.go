@@ -96,6 +96,67 @@ CREATE TABLE IF NOT EXISTS daily_messages (
 );
 
 CREATE INDEX IF NOT EXISTS idx_daily_messages_session_date ON daily_messages(session_id, date);
+
+CREATE TABLE IF NOT EXISTS fact_conflicts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    fact_a INTEGER NOT NULL REFERENCES facts(id),
+    fact_b INTEGER NOT NULL REFERENCES facts(id),
+    reason TEXT,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at DATETIME DEFAULT (datetime('now')),
+    resolved_at DATETIME,
+    UNIQUE(fact_a, fact_b)
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER REFERENCES entities(id),
+    name TEXT NOT NULL,
+    source TEXT,
+    chunk_count INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_owner ON documents(owner_id);
+
+CREATE TABLE IF NOT EXISTS document_chunks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    document_id INTEGER NOT NULL REFERENCES documents(id),
+    seq INTEGER NOT NULL,
+    content TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_chunks_doc ON document_chunks(document_id, seq);
+
+CREATE TABLE IF NOT EXISTS contacts (
+    entity_id INTEGER PRIMARY KEY REFERENCES entities(id),
+    owner_id INTEGER REFERENCES entities(id),
+    phone TEXT,
+    email TEXT,
+    birthday TEXT,
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_owner ON contacts(owner_id);
+
+CREATE TABLE IF NOT EXISTS media (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER REFERENCES entities(id),
+    path TEXT NOT NULL,
+    filename TEXT,
+    mime_type TEXT,
+    caption TEXT,
+    tags TEXT,
+    created_at DATETIME DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_owner ON media(owner_id, created_at);
+
+-- store-wide settings, such as the salt the encryption key is derived with
+CREATE TABLE IF NOT EXISTS store_meta (
+    key TEXT PRIMARY KEY,
+    value BLOB NOT NULL
+);
 `
 
 const vecSchema = `
@@ -108,4 +169,54 @@ CREATE VIRTUAL TABLE IF NOT EXISTS vec_summaries USING vec0(
     summary_id INTEGER PRIMARY KEY,
     embedding FLOAT[768]
 );
+
+CREATE VIRTUAL TABLE IF NOT EXISTS vec_document_chunks USING vec0(
+    chunk_id INTEGER PRIMARY KEY,
+    embedding FLOAT[768]
+);
+`
+
+// ftsSchema indexes conversation history for search_conversations. The
+// indexes hold no copy of the text; triggers keep them in step with the
+// tables they index.
+const ftsSchema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS fts_messages USING fts5(
+    content,
+    content='daily_messages',
+    content_rowid='id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS daily_messages_fts_insert AFTER INSERT ON daily_messages BEGIN
+    INSERT INTO fts_messages(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS daily_messages_fts_delete AFTER DELETE ON daily_messages BEGIN
+    INSERT INTO fts_messages(fts_messages, rowid, content) VALUES ('delete', old.id, old.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS daily_messages_fts_update AFTER UPDATE OF content ON daily_messages BEGIN
+    INSERT INTO fts_messages(fts_messages, rowid, content) VALUES ('delete', old.id, old.content);
+    INSERT INTO fts_messages(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS fts_summaries USING fts5(
+    summary,
+    content='daily_summaries',
+    content_rowid='id',
+    tokenize='porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS daily_summaries_fts_insert AFTER INSERT ON daily_summaries BEGIN
+    INSERT INTO fts_summaries(rowid, summary) VALUES (new.id, new.summary);
+END;
+
+CREATE TRIGGER IF NOT EXISTS daily_summaries_fts_delete AFTER DELETE ON daily_summaries BEGIN
+    INSERT INTO fts_summaries(fts_summaries, rowid, summary) VALUES ('delete', old.id, old.summary);
+END;
+
+CREATE TRIGGER IF NOT EXISTS daily_summaries_fts_update AFTER UPDATE OF summary ON daily_summaries BEGIN
+    INSERT INTO fts_summaries(fts_summaries, rowid, summary) VALUES ('delete', old.id, old.summary);
+    INSERT INTO fts_summaries(rowid, summary) VALUES (new.id, new.summary);
+END;
 `
@@ -0,0 +1,121 @@
+package sheldonmem
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ConversationHit is a past message or daily summary that matched a
+// conversation search
+type ConversationHit struct {
+	Kind      string // "summary" or "message"
+	SessionID string
+	Role      string // user or assistant, for messages
+	Date      string // YYYY-MM-DD the conversation took place
+	CreatedAt time.Time
+	Snippet   string // matching passage, matched words in **bold**
+}
+
+// ConversationQuery narrows a conversation search. An empty SessionID
+// searches every chat; zero times leave that end of the range open.
+type ConversationQuery struct {
+	Text      string
+	SessionID string
+	After     time.Time
+	Before    time.Time
+	Limit     int
+}
+
+// migrateConversationSearch creates the full-text indexes and, the first
+// time, fills them from history already stored
+func (s *Store) migrateConversationSearch() error {
+	var existing int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'fts_messages'`).Scan(&existing); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(ftsSchema); err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	for _, rebuild := range []string{
+		`INSERT INTO fts_messages(fts_messages) VALUES ('rebuild')`,
+		`INSERT INTO fts_summaries(fts_summaries) VALUES ('rebuild')`,
+	} {
+		if _, err := s.db.Exec(rebuild); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchConversations finds past messages and daily summaries containing
+// the query's words, best matches first. Words match on their stem, so
+// "remodeling" finds "remodel".
+func (s *Store) SearchConversations(ctx context.Context, q ConversationQuery) ([]ConversationHit, error) {
+	match := ftsQuery(q.Text)
+	if match == "" {
+		return nil, nil
+	}
+	if q.Limit <= 0 {
+		q.Limit = 10
+	}
+
+	after, before := "0000-01-01", "9999-12-31"
+	if !q.After.IsZero() {
+		after = q.After.Format("2006-01-02")
+	}
+	if !q.Before.IsZero() {
+		before = q.Before.Format("2006-01-02")
+	}
+
+	rows, err := s.db.QueryContext(ctx, querySearchConversations,
+		match, q.SessionID, q.SessionID, after, before,
+		match, q.SessionID, q.SessionID, after, before,
+		q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []ConversationHit
+	for rows.Next() {
+		var h ConversationHit
+		var createdAt string
+		var rank float64
+		if err := rows.Scan(&h.Kind, &h.SessionID, &h.Role, &h.Date, &createdAt, &h.Snippet, &rank); err != nil {
+			return nil, err
+		}
+		// SQLite stores datetime('now') as UTC
+		if t, err := time.Parse("2006-01-02 15:04:05", createdAt); err == nil {
+			h.CreatedAt = t.In(time.Local)
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// ftsQuery turns free text into an FTS5 query matching any of its words.
+// Each word is quoted so punctuation and FTS operators in the text can't
+// break the query; bm25 ranks passages with more of the words higher.
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range words {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, `"`+w+`"`)
+	}
+	return strings.Join(terms, " OR ")
+}
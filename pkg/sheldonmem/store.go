@@ -3,8 +3,10 @@ package sheldonmem
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	_ "github.com/asg017/sqlite-vec-go-bindings/ncruces"
+	"github.com/ncruces/go-sqlite3"
 	_ "github.com/ncruces/go-sqlite3/driver"
 )
 
@@ -47,6 +49,27 @@ func (s *Store) migrate() error {
 	s.db.Exec("ALTER TABLE daily_messages ADD COLUMN processed_at DATETIME")
 	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_daily_messages_pending ON daily_messages(processed_at, created_at)")
 
+	// Scope entities per user so family deployments don't share recall.
+	// Existing user entities own themselves; everything else stays shared.
+	s.db.Exec("ALTER TABLE entities ADD COLUMN owner_id INTEGER REFERENCES entities(id)")
+	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_entities_owner ON entities(owner_id)")
+	s.db.Exec("UPDATE entities SET owner_id = id WHERE entity_type = 'user' AND owner_id IS NULL")
+
+	// Soft delete for forgotten facts so they can be restored during the undo window
+	s.db.Exec("ALTER TABLE facts ADD COLUMN forgotten_at DATETIME")
+	s.db.Exec("CREATE INDEX IF NOT EXISTS idx_facts_forgotten ON facts(forgotten_at)")
+
+	// Pinned facts are messages the user asked to keep word for word
+	s.db.Exec("ALTER TABLE facts ADD COLUMN pinned INTEGER DEFAULT 0")
+
+	if err := s.migrateMediaVectors(); err != nil {
+		return err
+	}
+
+	if err := s.migrateConversationSearch(); err != nil {
+		return err
+	}
+
 	if err := s.seedDomains(); err != nil {
 		return err
 	}
@@ -59,11 +82,21 @@ func (s *Store) migrate() error {
 }
 
 func (s *Store) SetEmbedder(e Embedder) {
+	s.embedderMu.Lock()
+	defer s.embedderMu.Unlock()
 	s.embedder = e
 }
 
 func (s *Store) HasEmbedder() bool {
-	return s.embedder != nil
+	return s.getEmbedder() != nil
+}
+
+// getEmbedder returns the current embedder. Callers keep the result for the
+// whole operation so a query and its vectors come from the same model.
+func (s *Store) getEmbedder() Embedder {
+	s.embedderMu.RLock()
+	defer s.embedderMu.RUnlock()
+	return s.embedder
 }
 
 func (s *Store) seedSheldonEntity() error {
@@ -99,3 +132,22 @@ func (s *Store) HealthCheck(ctx context.Context) error {
 func (s *Store) DB() *sql.DB {
 	return s.db
 }
+
+// Backup writes a consistent copy of the database to path with SQLite's
+// online backup API, so it's safe while the store is in use and nothing in
+// the WAL is missed
+func (s *Store) Backup(ctx context.Context, path string) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(interface{ Raw() *sqlite3.Conn })
+		if !ok {
+			return fmt.Errorf("driver doesn't support backups")
+		}
+		return c.Raw().Backup("main", path)
+	})
+}
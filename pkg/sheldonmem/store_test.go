@@ -1,7 +1,16 @@
 package sheldonmem
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestOpenAndClose(t *testing.T) {
@@ -280,3 +289,647 @@ func TestDecay(t *testing.T) {
 		t.Errorf("expected high_conf to remain, got %s", facts[0].Field)
 	}
 }
+
+func TestRecallScopedToOwner(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	alice, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	bob, _ := store.CreateEntity("user_telegram_2", "user", 1, "")
+	store.SetEntityOwner(alice.ID, alice.ID)
+	store.SetEntityOwner(bob.ID, bob.ID)
+
+	store.AddFact(&alice.ID, 2, "condition", "asthma", 0.9)
+	store.AddFact(&bob.ID, 2, "condition", "diabetes", 0.9)
+	store.AddFact(nil, 2, "condition", "migraine", 0.9) // no entity, so nobody's
+
+	result, err := store.RecallWithOptions(context.Background(), "condition", []int{2}, 10, RecallOptions{OwnerID: alice.ID})
+	if err != nil {
+		t.Fatalf("recall failed: %v", err)
+	}
+
+	if len(result.Facts) != 1 || result.Facts[0].Value != "asthma" {
+		t.Errorf("expected only alice's fact, got %d facts", len(result.Facts))
+	}
+}
+
+func TestWithoutForeign(t *testing.T) {
+	alice, bob := int64(1), int64(2)
+	facts := []*Fact{
+		{ID: 1, EntityID: &alice},
+		{ID: 2, EntityID: &bob},
+		{ID: 3},
+	}
+
+	scoped := withoutForeign(facts, map[int64]bool{bob: true})
+	if len(scoped) != 1 || scoped[0].ID != 1 {
+		t.Errorf("expected only alice's fact once bob exists, got %d facts", len(scoped))
+	}
+
+	// with a single user nothing is foreign and unowned facts stay
+	if got := withoutForeign(facts, nil); len(got) != 3 {
+		t.Errorf("expected all facts for a single user, got %d", len(got))
+	}
+}
+
+func TestFindOwnedEntityByName(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	alice, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	bob, _ := store.CreateEntity("user_telegram_2", "user", 1, "")
+
+	aliceMom, _ := store.CreateOwnedEntity("Mom", "person", 6, "", alice.ID)
+	bobMom, _ := store.CreateOwnedEntity("Mom", "person", 6, "", bob.ID)
+
+	found, err := store.FindOwnedEntityByName("Mom", bob.ID)
+	if err != nil {
+		t.Fatalf("failed to find entity: %v", err)
+	}
+	if found.ID != bobMom.ID || found.ID == aliceMom.ID {
+		t.Errorf("expected bob's Mom (%d), got %d", bobMom.ID, found.ID)
+	}
+
+	if _, err := store.FindOwnedEntityByName("Sheldon", bob.ID); err != nil {
+		t.Errorf("expected shared Sheldon entity to be visible: %v", err)
+	}
+}
+
+// testSealer stands in for a real cipher: it only has to change the bytes
+// and reject data it didn't seal
+type testSealer struct{}
+
+func (testSealer) Seal(data []byte) ([]byte, error) {
+	sealed := []byte("sealed:")
+	for _, b := range data {
+		sealed = append(sealed, b^0x5a)
+	}
+	return sealed, nil
+}
+
+func (testSealer) Open(data []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte("sealed:"))
+	if !ok {
+		return nil, errors.New("not sealed by this key")
+	}
+	plain := make([]byte, len(rest))
+	for i, b := range rest {
+		plain[i] = b ^ 0x5a
+	}
+	return plain, nil
+}
+
+func TestSalt(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	salt, err := store.Salt()
+	if err != nil || len(salt) != saltSize {
+		t.Fatalf("expected a %d-byte salt, got %x, %v", saltSize, salt, err)
+	}
+	again, _ := store.Salt()
+	if !bytes.Equal(salt, again) {
+		t.Error("expected the salt to stay the same once created")
+	}
+
+	other, _ := Open(":memory:")
+	defer other.Close()
+	if otherSalt, _ := other.Salt(); bytes.Equal(salt, otherSalt) {
+		t.Error("expected each database to get its own salt")
+	}
+}
+
+func TestResealLegacy(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	entity, _ := store.CreateEntity("Kadet", "person", 1, "")
+	result, err := store.AddSensitiveFact(&entity.ID, 5, "salary", "90000", 0.9)
+	if err != nil {
+		t.Fatalf("failed to add fact: %v", err)
+	}
+
+	// what an older version stored: sealed under the legacy key with the v1 prefix
+	legacy, _ := testSealer{}.Seal([]byte("90000"))
+	store.db.Exec(`UPDATE facts SET value = ? WHERE id = ?`, legacySealedPrefix+base64.StdEncoding.EncodeToString(legacy), result.Fact.ID)
+
+	if err := store.SetSealer(reversedSealer{}); err != nil {
+		t.Fatalf("failed to set sealer: %v", err)
+	}
+	moved, err := store.ResealLegacy(testSealer{})
+	if err != nil || moved != 1 {
+		t.Fatalf("expected 1 value resealed, got %d, %v", moved, err)
+	}
+
+	var stored string
+	store.db.QueryRow(`SELECT value FROM facts WHERE id = ?`, result.Fact.ID).Scan(&stored)
+	if !strings.HasPrefix(stored, sealedPrefix) {
+		t.Errorf("expected value resealed under the new key, got %q", stored)
+	}
+	facts, _ := store.GetFactsByEntity(entity.ID)
+	if len(facts) != 1 || facts[0].Value != "90000" {
+		t.Errorf("expected decrypted value, got %+v", facts)
+	}
+}
+
+// reversedSealer is a second key for TestResealLegacy
+type reversedSealer struct{}
+
+func (reversedSealer) Seal(data []byte) ([]byte, error) {
+	sealed := slices.Clone(data)
+	slices.Reverse(sealed)
+	return append([]byte("reversed:"), sealed...), nil
+}
+
+func (reversedSealer) Open(data []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte("reversed:"))
+	if !ok {
+		return nil, errors.New("not sealed by this key")
+	}
+	plain := slices.Clone(rest)
+	slices.Reverse(plain)
+	return plain, nil
+}
+
+func TestSensitiveFactEncrypted(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SetSealer(testSealer{}); err != nil {
+		t.Fatalf("failed to set sealer: %v", err)
+	}
+
+	entity, _ := store.CreateEntity("Kadet", "person", 1, "")
+	result, err := store.AddSensitiveFact(&entity.ID, 5, "salary", "90000", 0.9)
+	if err != nil {
+		t.Fatalf("failed to add fact: %v", err)
+	}
+
+	var stored string
+	store.db.QueryRow(`SELECT value FROM facts WHERE id = ?`, result.Fact.ID).Scan(&stored)
+	if stored == "90000" {
+		t.Error("expected sensitive value to be encrypted at rest")
+	}
+
+	facts, _ := store.GetFactsByEntity(entity.ID)
+	if len(facts) != 1 || facts[0].Value != "90000" {
+		t.Errorf("expected decrypted value, got %+v", facts)
+	}
+
+	store.sealer = nil
+	facts, _ = store.GetFactsByEntity(entity.ID)
+	if len(facts) != 1 || facts[0].Value != sealedPlaceholder {
+		t.Errorf("expected placeholder without key, got %+v", facts)
+	}
+}
+
+func TestSensitiveValues(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SetSealer(testSealer{}); err != nil {
+		t.Fatalf("failed to set sealer: %v", err)
+	}
+
+	entity, _ := store.CreateEntity("Kadet", "person", 1, "")
+	store.AddSensitiveFact(&entity.ID, 5, "salary", "90000", 0.9)
+	store.AddFact(&entity.ID, 1, "city", "Berlin", 0.9)
+
+	values, err := store.SensitiveValues()
+	if err != nil {
+		t.Fatalf("failed to list sensitive values: %v", err)
+	}
+	if len(values) != 1 || values[0] != "90000" {
+		t.Errorf("expected only the decrypted sensitive value, got %v", values)
+	}
+}
+
+func TestForgetAndRestoreFact(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	user, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	store.SetEntityOwner(user.ID, user.ID)
+	result, _ := store.AddFact(&user.ID, 11, "favorite_color", "green", 0.9)
+
+	if err := store.ForgetFact(result.Fact.ID); err != nil {
+		t.Fatalf("failed to forget fact: %v", err)
+	}
+	if facts, _ := store.GetOwnedFacts(user.ID, 0); len(facts) != 0 {
+		t.Errorf("expected forgotten fact hidden, got %d facts", len(facts))
+	}
+	if err := store.ForgetFact(result.Fact.ID); err != ErrFactNotFound {
+		t.Errorf("expected ErrFactNotFound forgetting twice, got %v", err)
+	}
+
+	if err := store.RestoreFact(context.Background(), result.Fact.ID, time.Hour); err != nil {
+		t.Fatalf("failed to restore fact: %v", err)
+	}
+	if facts, _ := store.GetOwnedFacts(user.ID, 11); len(facts) != 1 {
+		t.Errorf("expected restored fact, got %d facts", len(facts))
+	}
+
+	store.ForgetFact(result.Fact.ID)
+	purged, err := store.PurgeForgotten(-time.Minute)
+	if err != nil || purged != 1 {
+		t.Errorf("expected 1 purged fact, got %d (%v)", purged, err)
+	}
+	if _, err := store.GetFact(result.Fact.ID); err != ErrFactNotFound {
+		t.Errorf("expected purged fact gone, got %v", err)
+	}
+}
+
+type stubLLM struct {
+	response string
+}
+
+func (l *stubLLM) Chat(ctx context.Context, systemPrompt string, messages []LLMMessage) (string, error) {
+	return l.response, nil
+}
+
+func TestFindAndResolveContradiction(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	user, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	a, _ := store.AddFact(&user.ID, 7, "employer", "Acme", 0.9)
+	b, _ := store.AddFact(&user.ID, 7, "works_at", "Globex", 0.9)
+
+	llm := &stubLLM{response: fmt.Sprintf(`{"conflicts": [{"a": %d, "b": %d, "reason": "two employers"}, {"a": 999, "b": %d}]}`, b.Fact.ID, a.Fact.ID, a.Fact.ID)}
+	conflicts, err := store.FindContradictions(context.Background(), llm, user.ID)
+	if err != nil {
+		t.Fatalf("failed to find contradictions: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].A.ID != a.Fact.ID {
+		t.Fatalf("expected one conflict ordered by ID, got %+v", conflicts)
+	}
+
+	again, _ := store.FindContradictions(context.Background(), llm, user.ID)
+	if len(again) != 0 {
+		t.Errorf("expected known conflict not to be reported again, got %d", len(again))
+	}
+
+	if err := store.ResolveConflict(conflicts[0].ID, KeepB); err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	facts, _ := store.GetFactsByEntity(user.ID)
+	if len(facts) != 1 || facts[0].Value != "Globex" {
+		t.Errorf("expected only Globex to remain, got %+v", facts)
+	}
+	if err := store.ResolveConflict(conflicts[0].ID, KeepA); err != ErrConflictResolved {
+		t.Errorf("expected ErrConflictResolved, got %v", err)
+	}
+}
+
+func TestDocumentsScopedToOwner(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	alice, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	bob, _ := store.CreateEntity("user_telegram_2", "user", 1, "")
+
+	doc, err := store.AddDocument(context.Background(), alice.ID, "lease.pdf", "chat", []string{"Rent is due on the first", "Deposit is two months"})
+	if err != nil {
+		t.Fatalf("failed to add document: %v", err)
+	}
+
+	matches, _ := store.SearchDocuments(context.Background(), alice.ID, "Deposit", 5)
+	if len(matches) != 1 || matches[0].Seq != 1 || matches[0].DocumentName != "lease.pdf" {
+		t.Errorf("expected deposit chunk, got %+v", matches)
+	}
+	if matches, _ := store.SearchDocuments(context.Background(), bob.ID, "Deposit", 5); len(matches) != 0 {
+		t.Errorf("expected no matches for another user, got %d", len(matches))
+	}
+
+	if err := store.DeleteDocument(doc.ID, bob.ID); err == nil {
+		t.Error("expected another user's delete to fail")
+	}
+	if err := store.DeleteDocument(doc.ID, alice.ID); err != nil {
+		t.Fatalf("failed to delete document: %v", err)
+	}
+	if docs, _ := store.ListDocuments(alice.ID); len(docs) != 0 {
+		t.Errorf("expected no documents after delete, got %d", len(docs))
+	}
+}
+
+// stubEmbedder maps text onto a small vector keyed by its first letter
+type stubEmbedder struct {
+	dims int
+}
+
+func (e *stubEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	v := make([]float32, e.dims)
+	if text != "" {
+		v[int(text[0])%e.dims] = 1
+	}
+	return v, nil
+}
+
+func TestReindexSwitchesEmbedder(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	user, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	store.AddFact(&user.ID, 7, "employer", "Acme", 0.9)
+	store.AddFact(&user.ID, 11, "drink", "coffee", 0.9)
+
+	var last ReindexProgress
+	if err := store.Reindex(context.Background(), &stubEmbedder{dims: 8}, func(p ReindexProgress) {
+		if p.Stage == "facts" {
+			last = p
+		}
+	}); err != nil {
+		t.Fatalf("failed to reindex: %v", err)
+	}
+	if last.Done != 2 || last.Total != 2 {
+		t.Errorf("expected facts progress 2/2, got %+v", last)
+	}
+	if !store.HasEmbedder() {
+		t.Error("expected store to use the new embedder")
+	}
+
+	results, err := store.SemanticSearch(context.Background(), "employer", []int{7, 11}, 1)
+	if err != nil {
+		t.Fatalf("failed to search with new vectors: %v", err)
+	}
+	if len(results) != 1 || results[0].Fact.Field != "employer" {
+		t.Errorf("expected employer fact, got %+v", results)
+	}
+}
+
+// TestReindexWhileSearching is meant for go test -race: Reindex swaps the
+// embedder while other goroutines are searching with it
+func TestReindexWhileSearching(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "mem.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	store.SetEmbedder(&stubEmbedder{dims: 4})
+	user, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	store.AddFact(&user.ID, 7, "employer", "Acme", 0.9)
+
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			store.HasEmbedder()
+			store.SemanticSearch(ctx, "employer", []int{7}, 1)
+		}
+	}()
+
+	if err := store.Reindex(ctx, &stubEmbedder{dims: 8}, nil); err != nil {
+		t.Fatalf("failed to reindex: %v", err)
+	}
+	<-done
+
+	results, err := store.SemanticSearch(ctx, "employer", []int{7}, 1)
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected the fact from the new index, got %+v (%v)", results, err)
+	}
+}
+
+func TestSaveContactMergesFields(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	user, _ := store.CreateEntity("Alice", "user", 1, "")
+	store.SetEntityOwner(user.ID, user.ID)
+
+	if _, err := store.SaveContact(user.ID, Contact{Name: "Sarah", Phone: "+44 7700 900123", Birthday: "1990-03-14"}); err != nil {
+		t.Fatalf("save contact: %v", err)
+	}
+	c, err := store.SaveContact(user.ID, Contact{Name: "Sarah", Email: "sarah@example.com"})
+	if err != nil {
+		t.Fatalf("update contact: %v", err)
+	}
+	if c.Phone != "+44 7700 900123" || c.Email != "sarah@example.com" || c.Birthday != "1990-03-14" {
+		t.Errorf("expected fields merged, got %+v", c)
+	}
+
+	if _, err := store.GetContact(user.ID+100, "Sarah"); err != ErrContactNotFound {
+		t.Errorf("expected contact hidden from other owners, got %v", err)
+	}
+	if _, err := store.SaveContact(user.ID, Contact{Name: "Tom", Birthday: "March 3rd"}); err == nil {
+		t.Error("expected invalid birthday to be rejected")
+	}
+}
+
+func TestUpcomingBirthdays(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	user, _ := store.CreateEntity("Alice", "user", 1, "")
+	store.SaveContact(user.ID, Contact{Name: "Sarah", Birthday: "1990-03-14"})
+	store.SaveContact(user.ID, Contact{Name: "Tom", Birthday: "--01-02"})
+	store.SaveContact(user.ID, Contact{Name: "Leap", Birthday: "2000-02-29"})
+	store.SaveContact(user.ID, Contact{Name: "Far", Birthday: "--07-01"})
+
+	from := time.Date(2025, 12, 20, 15, 0, 0, 0, time.UTC)
+	upcoming, err := store.UpcomingBirthdays(user.ID, from, 90)
+	if err != nil {
+		t.Fatalf("upcoming birthdays: %v", err)
+	}
+
+	var names []string
+	for _, b := range upcoming {
+		names = append(names, b.Contact.Name)
+	}
+	if fmt.Sprint(names) != "[Tom Leap Sarah]" {
+		t.Fatalf("expected Tom, Leap, Sarah in order, got %v", names)
+	}
+	if upcoming[1].Date.Day() != 28 || upcoming[1].Age != 26 {
+		t.Errorf("expected Feb 29 birthday on Feb 28 turning 26, got %v age %d", upcoming[1].Date, upcoming[1].Age)
+	}
+	if upcoming[0].Age != 0 {
+		t.Errorf("expected unknown age for birthday without year, got %d", upcoming[0].Age)
+	}
+}
+
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(filepath.Join(dir, "memory.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.CreateEntity("Berlin", "place", 9, ""); err != nil {
+		t.Fatalf("failed to create entity: %v", err)
+	}
+
+	backupPath := filepath.Join(dir, "backup.db")
+	if err := store.Backup(context.Background(), backupPath); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	copied, err := Open(backupPath)
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer copied.Close()
+
+	if _, err := copied.FindEntityByName("Berlin"); err != nil {
+		t.Errorf("entity missing from backup: %v", err)
+	}
+}
+
+func TestSearchMedia(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	alice, _ := store.CreateEntity("user_telegram_1", "user", 1, "")
+	bob, _ := store.CreateEntity("user_telegram_2", "user", 1, "")
+
+	pass, err := store.AddMedia(ctx, alice.ID, "media/2026/03/pass.jpg", "pass.jpg", "image/jpeg", "Boarding pass for LH 123 to Lisbon", []string{"Travel", "boarding pass", "travel"})
+	if err != nil {
+		t.Fatalf("failed to add media: %v", err)
+	}
+	store.AddMedia(ctx, alice.ID, "media/2026/03/beach.jpg", "beach.jpg", "image/jpeg", "Sunset at the beach", []string{"sunset"})
+
+	if len(pass.Tags) != 2 || pass.Tags[0] != "travel" {
+		t.Errorf("expected deduplicated lowercase tags, got %v", pass.Tags)
+	}
+
+	items, err := store.SearchMedia(ctx, alice.ID, MediaQuery{Text: "boarding pass"})
+	if err != nil {
+		t.Fatalf("failed to search media: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != pass.ID || items[0].Path != pass.Path {
+		t.Errorf("expected the boarding pass, got %+v", items)
+	}
+	if items, _ := store.SearchMedia(ctx, bob.ID, MediaQuery{Text: "boarding pass"}); len(items) != 0 {
+		t.Errorf("expected no media for another user, got %d", len(items))
+	}
+	if items, _ := store.SearchMedia(ctx, alice.ID, MediaQuery{Text: "boarding", Before: time.Now().Add(-time.Hour)}); len(items) != 0 {
+		t.Errorf("expected nothing before the upload, got %d", len(items))
+	}
+	if items, _ := store.SearchMedia(ctx, alice.ID, MediaQuery{After: time.Now().Add(-time.Hour)}); len(items) != 2 {
+		t.Errorf("expected an empty query to list both, got %d", len(items))
+	}
+
+	if err := store.Reindex(ctx, &stubEmbedder{dims: 8}, nil); err != nil {
+		t.Fatalf("failed to reindex: %v", err)
+	}
+	items, err = store.SearchMedia(ctx, alice.ID, MediaQuery{Text: "boarding", Limit: 1})
+	if err != nil {
+		t.Fatalf("failed to search media semantically: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != pass.ID {
+		t.Errorf("expected the boarding pass from vector search, got %+v", items)
+	}
+}
+
+func TestSearchConversations(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	store.AddDailyMessage("telegram:1", "user", "Should we go with the oak cabinets for the kitchen remodel?")
+	store.AddDailyMessage("telegram:1", "assistant", "Oak fits the budget you mentioned.")
+	store.AddDailyMessage("telegram:2", "user", "The kitchen remodel at work starts Monday")
+	if err := store.SaveDailySummary(ctx, "telegram:1", time.Date(2026, 6, 12, 0, 0, 0, 0, time.UTC), "Decided on oak cabinets for the kitchen remodeling."); err != nil {
+		t.Fatalf("failed to save summary: %v", err)
+	}
+
+	hits, err := store.SearchConversations(ctx, ConversationQuery{Text: "kitchen remodel?", SessionID: "telegram:1"})
+	if err != nil {
+		t.Fatalf("failed to search conversations: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("expected the summary and one message, got %+v", hits)
+	}
+	for _, h := range hits {
+		if h.SessionID != "telegram:1" || !strings.Contains(h.Snippet, "**") {
+			t.Errorf("unexpected hit %+v", h)
+		}
+	}
+
+	june := ConversationQuery{Text: "cabinets", SessionID: "telegram:1", After: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), Before: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)}
+	if hits, _ := store.SearchConversations(ctx, june); len(hits) != 1 || hits[0].Kind != "summary" || hits[0].Date != "2026-06-12" {
+		t.Errorf("expected only the June summary, got %+v", hits)
+	}
+
+	store.SaveDailySummary(ctx, "telegram:1", time.Date(2026, 6, 12, 0, 0, 0, 0, time.UTC), "Went with walnut in the end.")
+	if hits, _ := store.SearchConversations(ctx, june); len(hits) != 0 {
+		t.Errorf("expected the rewritten summary to be reindexed, got %+v", hits)
+	}
+	if hits, _ := store.SearchConversations(ctx, ConversationQuery{Text: `"AND" (`}); len(hits) != 0 {
+		t.Errorf("expected FTS syntax in the query to be treated as words, got %+v", hits)
+	}
+}
+
+func TestPinnedFactSurvivesDecay(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	entity, _ := store.CreateEntity("Test", "person", 1, "")
+
+	pinned, err := store.PinFact(context.Background(), entity.ID, 5, "pinned_2025-01-01", "Gate code is 4512, the buzzer is broken")
+	if err != nil {
+		t.Fatalf("failed to pin fact: %v", err)
+	}
+	store.AddFact(&entity.ID, 5, "wifi_name", "homenet", 1.0)
+	store.DB().Exec(`UPDATE facts SET created_at = datetime('now', '-1 year')`)
+
+	deleted, err := store.Decay(DecayConfig{MaxAge: 30 * 24 * time.Hour, SalienceThreshold: 0.5})
+	if err != nil {
+		t.Fatalf("decay failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected only the unpinned fact deleted, got %d", deleted)
+	}
+
+	facts, _ := store.GetFactsByEntity(entity.ID)
+	if len(facts) != 1 || facts[0].ID != pinned.ID || facts[0].Value != pinned.Value {
+		t.Errorf("expected the pinned fact to remain verbatim, got %+v", facts)
+	}
+}
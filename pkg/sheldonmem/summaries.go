@@ -118,8 +118,8 @@ func (s *Store) SaveDailySummary(ctx context.Context, sessionID string, date tim
 	}
 
 	// Generate and store embedding if embedder is available
-	if s.embedder != nil {
-		embedding, err := s.embedder.Embed(ctx, summary)
+	if embedder := s.getEmbedder(); embedder != nil {
+		embedding, err := embedder.Embed(ctx, summary)
 		if err == nil && len(embedding) > 0 {
 			blob, err := sqlite_vec.SerializeFloat32(embedding)
 			if err == nil {
@@ -165,7 +165,8 @@ func (s *Store) GetDailySummary(sessionID string, date time.Time) (*DailySummary
 
 // SearchSummaries finds relevant summaries using semantic search
 func (s *Store) SearchSummaries(ctx context.Context, sessionID string, query string, limit int) ([]DailySummary, error) {
-	if s.embedder == nil {
+	embedder := s.getEmbedder()
+	if embedder == nil {
 		// Fall back to recent summaries if no embedder
 		return s.GetRecentSummaries(sessionID, limit)
 	}
@@ -174,7 +175,7 @@ func (s *Store) SearchSummaries(ctx context.Context, sessionID string, query str
 		limit = 5
 	}
 
-	embedding, err := s.embedder.Embed(ctx, query)
+	embedding, err := embedder.Embed(ctx, query)
 	if err != nil {
 		// Fall back to recent summaries on embedding error
 		return s.GetRecentSummaries(sessionID, limit)
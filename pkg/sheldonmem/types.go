@@ -3,6 +3,7 @@ package sheldonmem
 import (
 	"context"
 	"database/sql"
+	"sync"
 	"time"
 )
 
@@ -11,8 +12,19 @@ type Embedder interface {
 }
 
 type Store struct {
-	db       *sql.DB
-	embedder Embedder
+	db     *sql.DB
+	sealer Sealer // encrypts sensitive fact values; nil when no key is set
+
+	// embedderMu guards embedder, which Reindex swaps while searches run
+	embedderMu sync.RWMutex
+	embedder   Embedder
+}
+
+// ReindexProgress reports how far Reindex has got through one kind of item
+type ReindexProgress struct {
+	Stage string // facts, summaries, documents or media
+	Done  int
+	Total int
 }
 
 type DecayConfig struct {
@@ -46,6 +58,7 @@ type Entity struct {
 	EntityType string
 	DomainID   int
 	Metadata   string
+	OwnerID    *int64 // nil for shared entities such as Sheldon
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
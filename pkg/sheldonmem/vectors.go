@@ -12,11 +12,12 @@ func serializeEmbedding(embedding []float32) ([]byte, error) {
 }
 
 func (s *Store) EmbedFact(ctx context.Context, factID int64, text string) error {
-	if s.embedder == nil {
+	embedder := s.getEmbedder()
+	if embedder == nil {
 		return nil
 	}
 
-	embedding, err := s.embedder.Embed(ctx, text)
+	embedding, err := embedder.Embed(ctx, text)
 	if err != nil {
 		return err
 	}
@@ -41,7 +42,8 @@ type ScoredFact struct {
 }
 
 func (s *Store) SemanticSearch(ctx context.Context, query string, domainIDs []int, limit int) ([]*ScoredFact, error) {
-	if s.embedder == nil {
+	embedder := s.getEmbedder()
+	if embedder == nil {
 		return nil, nil
 	}
 
@@ -49,7 +51,7 @@ func (s *Store) SemanticSearch(ctx context.Context, query string, domainIDs []in
 		return nil, nil
 	}
 
-	embedding, err := s.embedder.Embed(ctx, query)
+	embedding, err := embedder.Embed(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +98,7 @@ func (s *Store) SemanticSearch(ctx context.Context, query string, domainIDs []in
 		if err := rows.Scan(&f.ID, &f.EntityID, &f.DomainID, &f.Field, &f.Value, &f.Confidence, &f.AccessCount, &f.Active, &f.Sensitive, &f.CreatedAt, &distance); err != nil {
 			return nil, err
 		}
+		f.Value = s.openValue(f.Value)
 		results = append(results, &ScoredFact{Fact: &f, Distance: distance})
 	}
 
@@ -123,7 +126,7 @@ func (s *Store) hybridSearch(ctx context.Context, query string, domainIDs []int,
 		return nil, err
 	}
 
-	if s.embedder == nil {
+	if !s.HasEmbedder() {
 		if len(keywordFacts) > limit {
 			keywordFacts = keywordFacts[:limit]
 		}
@@ -167,7 +170,7 @@ func (s *Store) hybridSearch(ctx context.Context, query string, domainIDs []int,
 }
 
 func (s *Store) ReindexEmbeddings(ctx context.Context) error {
-	if s.embedder == nil {
+	if !s.HasEmbedder() {
 		return nil
 	}
 
@@ -184,7 +187,7 @@ func (s *Store) ReindexEmbeddings(ctx context.Context) error {
 			return err
 		}
 
-		text := field + ": " + value
+		text := embeddingText(field, value, isSealed(value))
 		if err := s.EmbedFact(ctx, id, text); err != nil {
 			return err
 		}